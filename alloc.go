@@ -0,0 +1,42 @@
+package litecrate
+
+// Panicked (as *AllocError) when a length counter read from a crate would allocate
+// more than the limit set with SetMaxReadAlloc()
+type AllocError struct {
+	Length       uint64 // Bytes the read would have allocated (at least, for slices and maps)
+	MaxReadAlloc uint64
+}
+
+func (e *AllocError) Error() string {
+	return "LiteCrate: read would allocate " + intStr(e.Length) + " bytes, over max read allocation of " + intStr(e.MaxReadAlloc)
+}
+
+/**************
+	ALLOC
+***************/
+
+// Limit how many bytes a single read may allocate for a []byte, string, slice or map,
+// so a corrupt or malicious length counter cannot make the reader allocate gigabytes
+// before finding out the data is not there. Slices and maps count their elements' in-memory size
+// (the size of K plus the size of V for maps). Exceeding the limit panics with an *AllocError
+// before anything is allocated. 0 = no limit (default)
+func (c *Crate) SetMaxReadAlloc(n uint64) {
+	c.maxAlloc = n
+}
+
+// Returns the limit set with SetMaxReadAlloc(), 0 = no limit
+func (c *Crate) MaxReadAlloc() uint64 {
+	return c.maxAlloc
+}
+
+// Called before allocating count elements of elemSize bytes each for a read
+func (c *Crate) checkAlloc(count uint64, elemSize uint64) {
+	if c.maxAlloc == 0 || elemSize == 0 || count <= c.maxAlloc/elemSize {
+		return
+	}
+	length := count * elemSize
+	if length/elemSize != count {
+		length = ^uint64(0)
+	}
+	panic(&AllocError{Length: length, MaxReadAlloc: c.maxAlloc})
+}
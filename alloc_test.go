@@ -0,0 +1,65 @@
+package litecrate_test
+
+import (
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+func TestMaxReadAlloc(t *testing.T) {
+	crate := lite.NewCrate(16, lite.FlagAutoDouble)
+	crate.WriteBytesWithCounter(make([]byte, 64))
+	crate.WriteStringWithCounter(string(make([]byte, 65)))
+	crate.SetMaxReadAlloc(64)
+	if crate.MaxReadAlloc() != 64 {
+		t.Errorf("MaxReadAlloc() - FAIL: %d != 64", crate.MaxReadAlloc())
+	}
+	if val := crate.ReadBytesWithCounter(); len(val) != 64 {
+		t.Errorf("ReadBytesWithCounter() - FAIL: read %d bytes at the limit", len(val))
+	}
+	err := catchPanic[*lite.AllocError](func() { crate.ReadStringWithCounter() })
+	if err == nil || err.Length != 65 || err.MaxReadAlloc != 64 || err.Error() == "" {
+		t.Errorf("ReadStringWithCounter() - FAIL: 65 byte string did not panic with *AllocError: %v", err)
+	}
+
+	// A counter claiming far more elements than the crate holds must panic before allocating
+	crate.Reset()
+	crate.WriteLengthOrNil(1<<40, false)
+	var slice []uint64
+	if err := catchPanic[*lite.AllocError](func() { lite.UseSlice(crate, lite.Read, &slice, crate.UseU64) }); err == nil || err.Length != 8<<40 {
+		t.Errorf("UseSlice() - FAIL: huge counter did not panic with *AllocError: %v", err)
+	}
+	crate.ResetReadIndex()
+	var m map[uint32]uint32
+	if err := catchPanic[*lite.AllocError](func() { lite.UseMap(crate, lite.Read, &m, crate.UseU32, crate.UseU32) }); err == nil || err.Length != 8<<40 {
+		t.Errorf("UseMap() - FAIL: huge counter did not panic with *AllocError: %v", err)
+	}
+	crate.ResetReadIndex()
+	var bytes []byte
+	if err := catchPanic[*lite.AllocError](func() { crate.UseAny(&bytes, lite.Read) }); err == nil {
+		t.Errorf("UseAny() - FAIL: huge counter did not panic with *AllocError")
+	}
+	crate.ResetReadIndex()
+	var ints []int32
+	if err := catchPanic[*lite.AllocError](func() { crate.UseAny(&ints, lite.Read) }); err == nil || err.Length != 4<<40 {
+		t.Errorf("UseAny() - FAIL: huge slice counter did not panic with *AllocError: %v", err)
+	}
+	crate.Reset()
+	crate.WriteLengthOrNil(^uint64(0)>>1, false)
+	if err := catchPanic[*lite.AllocError](func() { lite.UseSlice(crate, lite.Read, &slice, crate.UseU64) }); err == nil || err.Length != ^uint64(0) {
+		t.Errorf("UseSlice() - FAIL: overflowing length not saturated: %v", err)
+	}
+
+	// Within the limit UseAny() allocates the slice, but not a task for every element the counter claims
+	crate.Reset()
+	crate.WriteLengthOrNil(1<<16, false)
+	crate.SetMaxReadAlloc(1 << 16)
+	var small []int8
+	read := func() {
+		crate.ResetReadIndex()
+		catchPanic[error](func() { crate.UseAny(&small, lite.Read) })
+	}
+	if allocs := testing.AllocsPerRun(10, read); allocs > 8 {
+		t.Errorf("UseAny() - FAIL: %v allocs reading a counter of 65536 elements", allocs)
+	}
+}
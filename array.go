@@ -0,0 +1,162 @@
+package litecrate
+
+/**************
+	UUID
+***************/
+
+// Discard next 16 unread bytes in crate
+func (c *Crate) DiscardUUID() {
+	c.DiscardN(16)
+}
+
+// Return byte slice the next unread UUID occupies
+func (c *Crate) SliceUUID() (slice []byte) {
+	c.CheckRead(16)
+	return c.data[c.read : c.read+16 : c.read+16]
+}
+
+// Write 16 byte UUID to crate (without a length counter)
+func (c *Crate) WriteUUID(val [16]byte) {
+	c.CheckWrite(16)
+	copy(c.data[c.write:], val[:])
+	c.write += 16
+}
+
+// Read next 16 bytes from crate as UUID
+func (c *Crate) ReadUUID() (val [16]byte) {
+	val = c.PeekUUID()
+	c.read += 16
+	return val
+}
+
+// Read next 16 bytes from crate as UUID without advancing read index
+func (c *Crate) PeekUUID() (val [16]byte) {
+	c.CheckRead(16)
+	copy(val[:], c.data[c.read:])
+	return val
+}
+
+// Use the UUID pointed to by val according to mode:
+// Write = 'write val into crate', Read = 'read from crate into val',
+// Peek = 'read from crate into val without advancing index'
+// Slice = 'Return the slice the next unread val occupies without altering val'
+func (c *Crate) UseUUID(val *[16]byte, mode UseMode) (sliceModeData []byte) {
+	switch mode {
+	case Write:
+		c.WriteUUID(*val)
+	case Read:
+		*val = c.ReadUUID()
+	case Peek:
+		*val = c.PeekUUID()
+	case Discard:
+		c.DiscardUUID()
+	case Slice:
+		sliceModeData = c.SliceUUID()
+	default:
+		c.useCustomMode(val, mode, "UseUUID")
+	}
+	return sliceModeData
+}
+
+/**************
+	BYTES32
+***************/
+
+// Discard next 32 unread bytes in crate
+func (c *Crate) DiscardBytes32() {
+	c.DiscardN(32)
+}
+
+// Return byte slice the next unread [32]byte occupies
+func (c *Crate) SliceBytes32() (slice []byte) {
+	c.CheckRead(32)
+	return c.data[c.read : c.read+32 : c.read+32]
+}
+
+// Write [32]byte (such as a SHA-256 hash) to crate without a length counter
+func (c *Crate) WriteBytes32(val [32]byte) {
+	c.CheckWrite(32)
+	copy(c.data[c.write:], val[:])
+	c.write += 32
+}
+
+// Read next 32 bytes from crate as [32]byte
+func (c *Crate) ReadBytes32() (val [32]byte) {
+	val = c.PeekBytes32()
+	c.read += 32
+	return val
+}
+
+// Read next 32 bytes from crate as [32]byte without advancing read index
+func (c *Crate) PeekBytes32() (val [32]byte) {
+	c.CheckRead(32)
+	copy(val[:], c.data[c.read:])
+	return val
+}
+
+// Use the [32]byte pointed to by val according to mode:
+// Write = 'write val into crate', Read = 'read from crate into val',
+// Peek = 'read from crate into val without advancing index'
+// Slice = 'Return the slice the next unread val occupies without altering val'
+func (c *Crate) UseBytes32(val *[32]byte, mode UseMode) (sliceModeData []byte) {
+	switch mode {
+	case Write:
+		c.WriteBytes32(*val)
+	case Read:
+		*val = c.ReadBytes32()
+	case Peek:
+		*val = c.PeekBytes32()
+	case Discard:
+		c.DiscardBytes32()
+	case Slice:
+		sliceModeData = c.SliceBytes32()
+	default:
+		c.useCustomMode(val, mode, "UseBytes32")
+	}
+	return sliceModeData
+}
+
+/**************
+	ARRAY
+***************/
+
+// Use every element of a fixed size array according to mode, without a length counter
+// (the reader must already know the length). Pass the array as a slice of itself.
+//
+// Write = 'write elements into crate', Read = 'read from crate into elements',
+// Peek = 'read from crate into elements without advancing index'
+// Slice = 'Return the slice the elements occupy without altering them'
+//
+// Example:
+//
+//	var myPoint [3]float64
+//	UseArray(myCrate, Write, myPoint[:], myCrate.UseF64)
+func UseArray[T any](crate *Crate, mode UseMode, array []T, useElementFunc UseFunc[T]) (sliceModeData []byte) {
+	switch mode {
+	case Write, Read, Discard:
+		for i := range array {
+			useElementFunc(&array[i], mode)
+		}
+	case Peek:
+		idx := crate.read
+		for i := range array {
+			useElementFunc(&array[i], Read)
+		}
+		crate.read = idx
+	case Slice:
+		idx := crate.read
+		for range array {
+			var elem T
+			useElementFunc(&elem, Discard)
+		}
+		end := crate.read
+		crate.read = idx
+		return crate.data[idx:end:end]
+	default:
+		crate.useCustomMode(&array, mode, "UseArray")
+		for i := range array {
+			useElementFunc(&array[i], mode)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package litecrate_test
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+func TestUUIDBytes32(t *testing.T) {
+	crate := lite.NewCrate(8, lite.FlagAutoDouble)
+	id := [16]byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00}
+	hash := sha256.Sum256([]byte("LiteCrate"))
+	crate.UseUUID(&id, lite.Write)
+	crate.UseBytes32(&hash, lite.Write)
+	if crate.WriteIndex() != 48 {
+		t.Errorf("WriteUUID/WriteBytes32 - FAIL: wrote %d bytes != 48", crate.WriteIndex())
+	}
+	var gotID [16]byte
+	var gotHash [32]byte
+	crate.UseUUID(&gotID, lite.Peek)
+	if gotID != id || crate.ReadIndex() != 0 || len(crate.UseUUID(&gotID, lite.Slice)) != 16 {
+		t.Errorf("PeekUUID/SliceUUID - FAIL: %x", gotID)
+	}
+	crate.UseUUID(&gotID, lite.Read)
+	if len(crate.UseBytes32(&gotHash, lite.Slice)) != 32 || crate.PeekBytes32() != hash {
+		t.Errorf("PeekBytes32/SliceBytes32 - FAIL")
+	}
+	crate.UseBytes32(&gotHash, lite.Read)
+	if gotID != id || gotHash != hash || crate.ReadsLeft() != 0 {
+		t.Errorf("ReadUUID/ReadBytes32 - FAIL: %x, %x", gotID, gotHash)
+	}
+	crate.ResetReadIndex()
+	crate.UseUUID(&gotID, lite.Discard)
+	crate.UseBytes32(&gotHash, lite.Discard)
+	if crate.ReadsLeft() != 0 {
+		t.Errorf("DiscardUUID/DiscardBytes32 - FAIL: %d bytes left", crate.ReadsLeft())
+	}
+	if catchPanic[error](func() { crate.ReadUUID() }) == nil || catchPanic[error](func() { crate.UseBytes32(&gotHash, lite.UseMode(255)) }) == nil {
+		t.Errorf("UseUUID/UseBytes32 - FAIL: did not panic")
+	}
+}
+
+func TestUseArray(t *testing.T) {
+	crate := lite.NewCrate(8, lite.FlagAutoDouble)
+	point := [3]float64{1.5, -2, 3e10}
+	names := [2]string{"a", "bc"}
+	lite.UseArray(crate, lite.Write, point[:], crate.UseF64)
+	lite.UseArray(crate, lite.Write, names[:], crate.UseStringWithCounter)
+	if crate.WriteIndex() != 29 {
+		t.Errorf("UseArray(Write) - FAIL: wrote %d bytes != 29", crate.WriteIndex())
+	}
+	var gotPoint [3]float64
+	var gotNames [2]string
+	lite.UseArray(crate, lite.Peek, gotPoint[:], crate.UseF64)
+	if gotPoint != point || crate.ReadIndex() != 0 {
+		t.Errorf("UseArray(Peek) - FAIL: %v", gotPoint)
+	}
+	lite.UseArray(crate, lite.Discard, gotPoint[:], crate.UseF64)
+	if slice := lite.UseArray(crate, lite.Slice, gotNames[:], crate.UseStringWithCounter); len(slice) != 5 || gotNames[0] != "" {
+		t.Errorf("UseArray(Slice) - FAIL: %v", slice)
+	}
+	lite.UseArray(crate, lite.Read, gotNames[:], crate.UseStringWithCounter)
+	if gotNames != names || crate.ReadsLeft() != 0 {
+		t.Errorf("UseArray(Read) - FAIL: %v", gotNames)
+	}
+}
@@ -0,0 +1,196 @@
+package litecrate
+
+import (
+	"math/big"
+)
+
+const (
+	bigNegative uint8 = 1 // sign byte bit set for negative big.Int and big.Float values
+	bigInfinite uint8 = 2 // sign byte bit set for infinite big.Float values
+)
+
+/**************
+	BIG INT
+***************/
+
+// Discard next unread big.Int in crate
+func (c *Crate) DiscardBigInt() {
+	c.DiscardU8()
+	c.DiscardBytesWithCounter()
+}
+
+// Return byte slice the next unread big.Int occupies
+func (c *Crate) SliceBigInt() (slice []byte) {
+	start := c.read
+	c.DiscardBigInt()
+	end := c.read
+	c.read = start
+	return c.data[start:end:end]
+}
+
+// Write big.Int to crate as a sign byte (0 = positive or zero, 1 = negative)
+// followed by its big-endian magnitude with a length-or-nil counter
+func (c *Crate) WriteBigInt(val *big.Int) {
+	var sign uint8
+	if val.Sign() < 0 {
+		sign = bigNegative
+	}
+	c.WriteU8(sign)
+	c.WriteBytesWithCounter(val.Bytes())
+}
+
+// Read next big.Int from crate
+func (c *Crate) ReadBigInt() (val *big.Int) {
+	sign := c.ReadU8()
+	if sign > bigNegative {
+		panic("LiteCrate: big.Int has invalid sign byte " + intStr(sign))
+	}
+	val = new(big.Int).SetBytes(c.ReadBytesWithCounter())
+	if sign == bigNegative {
+		val.Neg(val)
+	}
+	return val
+}
+
+// Read next big.Int from crate without advancing read index
+func (c *Crate) PeekBigInt() (val *big.Int) {
+	idx := c.read
+	val = c.ReadBigInt()
+	c.read = idx
+	return val
+}
+
+// Use the big.Int pointed to by val according to mode:
+// Write = 'write val into crate', Read = 'read from crate into val',
+// Peek = 'read from crate into val without advancing index'
+// Slice = 'Return the slice the next unread val occupies without altering val'
+func (c *Crate) UseBigInt(val *big.Int, mode UseMode) (sliceModeData []byte) {
+	switch mode {
+	case Write:
+		c.WriteBigInt(val)
+	case Read:
+		val.Set(c.ReadBigInt())
+	case Peek:
+		val.Set(c.PeekBigInt())
+	case Discard:
+		c.DiscardBigInt()
+	case Slice:
+		sliceModeData = c.SliceBigInt()
+	default:
+		c.useCustomMode(val, mode, "UseBigInt")
+	}
+	return sliceModeData
+}
+
+/**************
+	BIG FLOAT
+***************/
+
+// Discard next unread big.Float in crate
+func (c *Crate) DiscardBigFloat() {
+	c.DiscardU8()
+	c.DiscardUVarint()
+	c.DiscardU8()
+	c.DiscardVarint()
+	c.DiscardBytesWithCounter()
+}
+
+// Return byte slice the next unread big.Float occupies
+func (c *Crate) SliceBigFloat() (slice []byte) {
+	start := c.read
+	c.DiscardBigFloat()
+	end := c.read
+	c.read = start
+	return c.data[start:end:end]
+}
+
+// Write big.Float to crate as a sign byte (bit 0 = negative, bit 1 = infinite), a UVarint precision,
+// a U8 rounding mode, then the value as a Varint binary exponent and the big-endian magnitude of
+// its integer mantissa with a length-or-nil counter, so that it reads back exactly
+// (the accuracy is not written, values are always read with big.Exact)
+func (c *Crate) WriteBigFloat(val *big.Float) {
+	var sign uint8
+	if val.Signbit() {
+		sign |= bigNegative
+	}
+	if val.IsInf() {
+		sign |= bigInfinite
+	}
+	c.WriteU8(sign)
+	c.WriteUVarint(uint64(val.Prec()))
+	c.WriteU8(uint8(val.Mode()))
+	if val.IsInf() || val.Sign() == 0 {
+		c.WriteVarint(0)
+		c.WriteBytesWithCounter(nil)
+		return
+	}
+	mant := new(big.Float)
+	exp := val.MantExp(mant)
+	mantInt, _ := mant.SetMantExp(mant, int(val.Prec())).Int(nil)
+	exp -= int(val.Prec())
+	zeros := mantInt.TrailingZeroBits()
+	mantInt.Rsh(mantInt, zeros)
+	c.WriteVarint(int64(exp) + int64(zeros))
+	c.WriteBytesWithCounter(mantInt.Bytes())
+}
+
+// Read next big.Float from crate
+func (c *Crate) ReadBigFloat() (val *big.Float) {
+	sign := c.ReadU8()
+	if sign > bigNegative|bigInfinite {
+		panic("LiteCrate: big.Float has invalid sign byte " + intStr(sign))
+	}
+	prec, _ := c.ReadUVarint()
+	if prec > big.MaxPrec {
+		panic("LiteCrate: big.Float precision " + intStr(prec) + " exceeds big.MaxPrec")
+	}
+	mode := c.ReadU8()
+	if mode > uint8(big.ToPositiveInf) {
+		panic("LiteCrate: big.Float has invalid rounding mode " + intStr(mode))
+	}
+	exp, _ := c.ReadVarint()
+	mant := c.ReadBytesWithCounter()
+	val = new(big.Float).SetPrec(uint(prec)).SetMode(big.RoundingMode(mode))
+	switch {
+	case sign&bigInfinite == bigInfinite:
+		val.SetInf(sign&bigNegative == bigNegative)
+		return val
+	case len(mant) > 0:
+		val.SetInt(new(big.Int).SetBytes(mant))
+		val.SetMantExp(val, int(exp))
+	}
+	if sign&bigNegative == bigNegative {
+		val.Neg(val)
+	}
+	return val
+}
+
+// Read next big.Float from crate without advancing read index
+func (c *Crate) PeekBigFloat() (val *big.Float) {
+	idx := c.read
+	val = c.ReadBigFloat()
+	c.read = idx
+	return val
+}
+
+// Use the big.Float pointed to by val according to mode:
+// Write = 'write val into crate', Read = 'read from crate into val' (including its precision and rounding mode),
+// Peek = 'read from crate into val without advancing index'
+// Slice = 'Return the slice the next unread val occupies without altering val'
+func (c *Crate) UseBigFloat(val *big.Float, mode UseMode) (sliceModeData []byte) {
+	switch mode {
+	case Write:
+		c.WriteBigFloat(val)
+	case Read:
+		val.Copy(c.ReadBigFloat())
+	case Peek:
+		val.Copy(c.PeekBigFloat())
+	case Discard:
+		c.DiscardBigFloat()
+	case Slice:
+		sliceModeData = c.SliceBigFloat()
+	default:
+		c.useCustomMode(val, mode, "UseBigFloat")
+	}
+	return sliceModeData
+}
@@ -0,0 +1,102 @@
+package litecrate_test
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+func TestBigInt(t *testing.T) {
+	huge, _ := new(big.Int).SetString("-123456789012345678901234567890123456789", 10)
+	crate := lite.NewCrate(8, lite.FlagAutoDouble)
+	for _, val := range []*big.Int{big.NewInt(0), big.NewInt(1), big.NewInt(-1), big.NewInt(math.MinInt64), huge, new(big.Int).Lsh(big.NewInt(1), 1000)} {
+		crate.Reset()
+		crate.UseBigInt(val, lite.Write)
+		crate.WriteU8(42)
+		peeked, read := big.NewInt(7), big.NewInt(7)
+		crate.UseBigInt(peeked, lite.Peek)
+		slice := crate.UseBigInt(read, lite.Slice)
+		crate.UseBigInt(read, lite.Read)
+		if read.Cmp(val) != 0 || peeked.Cmp(val) != 0 || uint64(len(slice)) != crate.WriteIndex()-1 || crate.ReadU8() != 42 {
+			t.Errorf("ReadBigInt(%v) - FAIL: read %v, peeked %v", val, read, peeked)
+		}
+		crate.ResetReadIndex()
+		crate.UseBigInt(read, lite.Discard)
+		if crate.ReadU8() != 42 {
+			t.Errorf("DiscardBigInt(%v) - FAIL: did not skip value", val)
+		}
+	}
+	crate.Reset()
+	crate.WriteU8(2)
+	crate.WriteBytesWithCounter([]byte{1})
+	if catchPanic[error](func() { crate.ReadBigInt() }) == nil {
+		t.Errorf("ReadBigInt() - FAIL: invalid sign byte did not panic")
+	}
+	if catchPanic[error](func() { crate.UseBigInt(new(big.Int), lite.UseMode(255)) }) == nil {
+		t.Errorf("UseBigInt - FAIL: invalid mode did not panic")
+	}
+}
+
+func TestBigFloat(t *testing.T) {
+	third := new(big.Float).SetPrec(500).Quo(big.NewFloat(1), new(big.Float).SetPrec(500).SetInt64(3))
+	tiny := new(big.Float).SetMantExp(big.NewFloat(1), -100000)
+	negZero := new(big.Float).Neg(new(big.Float))
+	values := []*big.Float{
+		new(big.Float),
+		negZero,
+		big.NewFloat(1.5),
+		big.NewFloat(-0.1),
+		big.NewFloat(math.MaxFloat64),
+		new(big.Float).SetInf(false),
+		new(big.Float).SetInf(true),
+		third,
+		new(big.Float).SetMode(big.ToZero).Neg(third),
+		tiny,
+		new(big.Float).SetPrec(8).SetInt64(1 << 40),
+	}
+	crate := lite.NewCrate(8, lite.FlagAutoDouble)
+	for _, val := range values {
+		crate.Reset()
+		crate.UseBigFloat(val, lite.Write)
+		crate.WriteU8(42)
+		peeked, read := big.NewFloat(7), big.NewFloat(7)
+		crate.UseBigFloat(peeked, lite.Peek)
+		slice := crate.UseBigFloat(read, lite.Slice)
+		crate.UseBigFloat(read, lite.Read)
+		for _, got := range []*big.Float{read, peeked} {
+			if got.Cmp(val) != 0 || got.Signbit() != val.Signbit() || got.Prec() != val.Prec() || got.Mode() != val.Mode() {
+				t.Errorf("ReadBigFloat(%v) - FAIL: got %v (prec %d, mode %v)", val, got, got.Prec(), got.Mode())
+			}
+		}
+		if uint64(len(slice)) != crate.WriteIndex()-1 || crate.ReadU8() != 42 {
+			t.Errorf("SliceBigFloat(%v) - FAIL: slice of %d bytes", val, len(slice))
+		}
+		crate.ResetReadIndex()
+		crate.UseBigFloat(read, lite.Discard)
+		if crate.ReadU8() != 42 {
+			t.Errorf("DiscardBigFloat(%v) - FAIL: did not skip value", val)
+		}
+	}
+	crate.Reset()
+	crate.WriteBigFloat(new(big.Float).SetPrec(1000).SetInt64(1 << 20))
+	if crate.WriteIndex() > 8 {
+		t.Errorf("WriteBigFloat() - FAIL: power of two took %d bytes", crate.WriteIndex())
+	}
+	crate.Reset()
+	crate.WriteU8(4)
+	if catchPanic[error](func() { crate.ReadBigFloat() }) == nil {
+		t.Errorf("ReadBigFloat() - FAIL: invalid sign byte did not panic")
+	}
+	crate.Reset()
+	crate.WriteU8(0)
+	crate.WriteUVarint(64)
+	crate.WriteU8(7)
+	if catchPanic[error](func() { crate.ReadBigFloat() }) == nil {
+		t.Errorf("ReadBigFloat() - FAIL: invalid rounding mode did not panic")
+	}
+	if catchPanic[error](func() { crate.UseBigFloat(new(big.Float), lite.UseMode(255)) }) == nil {
+		t.Errorf("UseBigFloat - FAIL: invalid mode did not panic")
+	}
+}
@@ -0,0 +1,146 @@
+package litecrate
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+)
+
+// A reference to Length bytes stored at Offset somewhere other than the crate it is written to
+// (another crate, a section of a file, etc.), optionally with a hash of those bytes so
+// the resolved data can be verified and identical blobs can be shared between records
+type BlobRef struct {
+	Offset uint64
+	Length uint64
+	Hash   []byte
+}
+
+// Returns the bytes a BlobRef refers to
+type BlobResolver func(ref BlobRef) (data []byte, err error)
+
+// Returns the SHA-256 hash of data, for use as BlobRef.Hash
+func HashBlob(data []byte) []byte {
+	sum := sha256.Sum256(data)
+	return sum[:]
+}
+
+// Returns whether data matches ref's hash (always true if ref has no hash)
+func (ref BlobRef) Verify(data []byte) bool {
+	if ref.Hash == nil {
+		return true
+	}
+	return len64(data) == ref.Length && bytes.Equal(HashBlob(data), ref.Hash)
+}
+
+// Write data to the end of crate, and return a BlobRef referring to it
+// (including its hash if withHash is true), to be written into a different crate
+func (c *Crate) WriteBlob(data []byte, withHash bool) (ref BlobRef) {
+	ref = BlobRef{Offset: c.write, Length: len64(data)}
+	if withHash {
+		ref.Hash = HashBlob(data)
+	}
+	c.WriteBytes(data)
+	return ref
+}
+
+// Returns a BlobResolver that resolves BlobRefs against the written data of store,
+// verifying the hash of the resolved data if the BlobRef has one.
+// The returned slices alias store's data
+func CrateBlobResolver(store *Crate) BlobResolver {
+	return func(ref BlobRef) (data []byte, err error) {
+		if ref.Offset > store.write || ref.Length > store.write-ref.Offset {
+			return nil, errors.New("LiteCrate: blob ref (offset: " + intStr(ref.Offset) + ", length: " + intStr(ref.Length) + ") exceeds blob store length " + intStr(store.write))
+		}
+		end := ref.Offset + ref.Length
+		data = store.data[ref.Offset:end:end]
+		if !ref.Verify(data) {
+			return nil, errors.New("LiteCrate: blob at offset " + intStr(ref.Offset) + " does not match its hash")
+		}
+		return data, nil
+	}
+}
+
+/**************
+	BLOB REF
+***************/
+
+// Set the BlobResolver used by ResolveBlobRef() and ReadBlob()
+func (c *Crate) SetBlobResolver(resolver BlobResolver) {
+	c.resolver = resolver
+}
+
+// Return the bytes ref refers to using the crate's BlobResolver
+func (c *Crate) ResolveBlobRef(ref BlobRef) (data []byte, err error) {
+	if c.resolver == nil {
+		return nil, errors.New("LiteCrate: no BlobResolver set on crate")
+	}
+	return c.resolver(ref)
+}
+
+// Discard next BlobRef in crate
+func (c *Crate) DiscardBlobRef() {
+	c.DiscardUVarint()
+	c.DiscardUVarint()
+	c.DiscardBytesWithCounter()
+}
+
+// Return byte slice the next unread BlobRef occupies
+func (c *Crate) SliceBlobRef() (slice []byte) {
+	start := c.read
+	c.DiscardBlobRef()
+	end := c.read
+	c.read = start
+	return c.data[start:end:end]
+}
+
+// Write BlobRef to crate as UVarint offset, UVarint length, and hash with preceding length-or-nil counter
+func (c *Crate) WriteBlobRef(ref BlobRef) {
+	c.WriteUVarint(ref.Offset)
+	c.WriteUVarint(ref.Length)
+	c.WriteBytesWithCounter(ref.Hash)
+}
+
+// Read next BlobRef from crate
+func (c *Crate) ReadBlobRef() (ref BlobRef) {
+	ref.Offset, _ = c.ReadUVarint()
+	ref.Length, _ = c.ReadUVarint()
+	ref.Hash = c.ReadBytesWithCounter()
+	return ref
+}
+
+// Read next BlobRef from crate without advancing read index
+func (c *Crate) PeekBlobRef() (ref BlobRef) {
+	idx := c.read
+	ref = c.ReadBlobRef()
+	c.read = idx
+	return ref
+}
+
+// Use BlobRef according to mode
+// Write = 'write val into crate', Read = 'read from crate into val',
+// Peek = 'read from crate into val without advancing index'
+// Slice = 'Return the slice the next unread val occupies without altering val'
+func (c *Crate) UseBlobRef(val *BlobRef, mode UseMode) (sliceModeData []byte) {
+	switch mode {
+	case Write:
+		c.WriteBlobRef(*val)
+	case Read:
+		*val = c.ReadBlobRef()
+	case Peek:
+		*val = c.PeekBlobRef()
+	case Discard:
+		c.DiscardBlobRef()
+	case Slice:
+		sliceModeData = c.SliceBlobRef()
+	default:
+		c.useCustomMode(val, mode, "UseBlobRef")
+	}
+	return sliceModeData
+}
+
+// Read next BlobRef from crate and return the bytes it refers to using the crate's BlobResolver
+func (c *Crate) ReadBlob() (data []byte, ref BlobRef, err error) {
+	ref = c.ReadBlobRef()
+	data, err = c.ResolveBlobRef(ref)
+	return data, ref, err
+}
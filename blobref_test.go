@@ -0,0 +1,84 @@
+package litecrate_test
+
+import (
+	"bytes"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+type blobRecord struct {
+	Name  string
+	Image lite.BlobRef
+}
+
+func (r *blobRecord) UseSelf(crate *lite.Crate, mode lite.UseMode) {
+	crate.UseStringWithCounter(&r.Name, mode)
+	crate.UseBlobRef(&r.Image, mode)
+}
+
+func TestBlobRef(t *testing.T) {
+	store := lite.NewCrate(16, lite.FlagAutoDouble)
+	image := bytes.Repeat([]byte{0xAB, 0xCD}, 100)
+	store.WriteBytes([]byte("header"))
+	records := []blobRecord{
+		{Name: "hashed", Image: store.WriteBlob(image, true)},
+		{Name: "unhashed", Image: store.WriteBlob([]byte("plain"), false)},
+	}
+	if records[0].Image.Offset != 6 || records[0].Image.Length != 200 || len(records[0].Image.Hash) != 32 || records[1].Image.Hash != nil {
+		t.Errorf("WriteBlob() - FAIL: unexpected refs %+v, %+v", records[0].Image, records[1].Image)
+	}
+
+	crate := lite.NewCrate(16, lite.FlagAutoDouble)
+	for i := range records {
+		crate.WriteSelfSerializer(&records[i])
+	}
+	if crate.WriteIndex() > 64 {
+		t.Errorf("WriteBlobRef() - FAIL: record crate is %d bytes", crate.WriteIndex())
+	}
+
+	var ref lite.BlobRef
+	crate.DiscardStringWithCounter()
+	if crate.UseBlobRef(&ref, lite.Peek); !ref.Verify(image) || crate.ReadIndex() != 7 {
+		t.Errorf("PeekBlobRef() - FAIL: %+v, index %d", ref, crate.ReadIndex())
+	}
+	slice := crate.UseBlobRef(&ref, lite.Slice)
+	crate.UseBlobRef(&ref, lite.Discard)
+	if uint64(len(slice)) != crate.ReadIndex()-7 {
+		t.Errorf("SliceBlobRef() - FAIL: length %d != %d", len(slice), crate.ReadIndex()-7)
+	}
+
+	crate.ResetReadIndex()
+	crate.DiscardStringWithCounter()
+	if _, _, err := crate.ReadBlob(); err == nil {
+		t.Errorf("ReadBlob() - FAIL: no error without resolver")
+	}
+	crate.ResetReadIndex()
+	crate.SetBlobResolver(lite.CrateBlobResolver(store))
+	for i := range records {
+		var got blobRecord
+		crate.ReadSelfSerializer(&got)
+		data, err := crate.ResolveBlobRef(got.Image)
+		if err != nil || got.Name != records[i].Name {
+			t.Errorf("ResolveBlobRef() - FAIL: record %d: %v", i, err)
+		}
+		want := store.Data()[records[i].Image.Offset : records[i].Image.Offset+records[i].Image.Length]
+		if !bytes.Equal(data, want) {
+			t.Errorf("ResolveBlobRef() - FAIL: record %d resolved to wrong bytes", i)
+		}
+	}
+
+	store.Data()[10] ^= 0xFF
+	if _, err := crate.ResolveBlobRef(records[0].Image); err == nil {
+		t.Errorf("CrateBlobResolver() - FAIL: corrupted blob passed hash check")
+	}
+	if _, err := crate.ResolveBlobRef(lite.BlobRef{Offset: store.WriteIndex(), Length: 1}); err == nil {
+		t.Errorf("CrateBlobResolver() - FAIL: out of range ref resolved")
+	}
+	if _, err := crate.ResolveBlobRef(lite.BlobRef{Offset: ^uint64(0), Length: 2}); err == nil {
+		t.Errorf("CrateBlobResolver() - FAIL: overflowing ref resolved")
+	}
+	if catchPanic[error](func() { crate.UseBlobRef(&ref, lite.UseMode(255)) }) == nil {
+		t.Errorf("UseBlobRef - FAIL: invalid mode did not panic")
+	}
+}
@@ -0,0 +1,218 @@
+package litecrate_test
+
+import (
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+// A single integer width under test. Values are passed around as the raw
+// bits of a uint64 (signed values sign-extended) so every width shares one harness
+type widthCase struct {
+	name   string
+	bits   uint
+	signed bool
+	size   func(val uint64) uint64
+	use    func(crate *lite.Crate, val *uint64, mode lite.UseMode) []byte
+}
+
+func fixedSize(n uint64) func(val uint64) uint64 {
+	return func(val uint64) uint64 { return n }
+}
+
+var widthCases = []widthCase{
+	{"U8", 8, false, fixedSize(1), func(c *lite.Crate, v *uint64, m lite.UseMode) []byte {
+		x := uint8(*v)
+		s := c.UseU8(&x, m)
+		*v = uint64(x)
+		return s
+	}},
+	{"I8", 8, true, fixedSize(1), func(c *lite.Crate, v *uint64, m lite.UseMode) []byte {
+		x := int8(*v)
+		s := c.UseI8(&x, m)
+		*v = uint64(int64(x))
+		return s
+	}},
+	{"U16", 16, false, fixedSize(2), func(c *lite.Crate, v *uint64, m lite.UseMode) []byte {
+		x := uint16(*v)
+		s := c.UseU16(&x, m)
+		*v = uint64(x)
+		return s
+	}},
+	{"I16", 16, true, fixedSize(2), func(c *lite.Crate, v *uint64, m lite.UseMode) []byte {
+		x := int16(*v)
+		s := c.UseI16(&x, m)
+		*v = uint64(int64(x))
+		return s
+	}},
+	{"U24", 24, false, fixedSize(3), func(c *lite.Crate, v *uint64, m lite.UseMode) []byte {
+		x := uint32(*v)
+		s := c.UseU24(&x, m)
+		*v = uint64(x)
+		return s
+	}},
+	{"I24", 24, true, fixedSize(3), func(c *lite.Crate, v *uint64, m lite.UseMode) []byte {
+		x := int32(*v)
+		s := c.UseI24(&x, m)
+		*v = uint64(int64(x))
+		return s
+	}},
+	{"U32", 32, false, fixedSize(4), func(c *lite.Crate, v *uint64, m lite.UseMode) []byte {
+		x := uint32(*v)
+		s := c.UseU32(&x, m)
+		*v = uint64(x)
+		return s
+	}},
+	{"I32", 32, true, fixedSize(4), func(c *lite.Crate, v *uint64, m lite.UseMode) []byte {
+		x := int32(*v)
+		s := c.UseI32(&x, m)
+		*v = uint64(int64(x))
+		return s
+	}},
+	{"U40", 40, false, fixedSize(5), func(c *lite.Crate, v *uint64, m lite.UseMode) []byte {
+		return c.UseU40(v, m)
+	}},
+	{"I40", 40, true, fixedSize(5), func(c *lite.Crate, v *uint64, m lite.UseMode) []byte {
+		x := int64(*v)
+		s := c.UseI40(&x, m)
+		*v = uint64(x)
+		return s
+	}},
+	{"U48", 48, false, fixedSize(6), func(c *lite.Crate, v *uint64, m lite.UseMode) []byte {
+		return c.UseU48(v, m)
+	}},
+	{"I48", 48, true, fixedSize(6), func(c *lite.Crate, v *uint64, m lite.UseMode) []byte {
+		x := int64(*v)
+		s := c.UseI48(&x, m)
+		*v = uint64(x)
+		return s
+	}},
+	{"U56", 56, false, fixedSize(7), func(c *lite.Crate, v *uint64, m lite.UseMode) []byte {
+		return c.UseU56(v, m)
+	}},
+	{"I56", 56, true, fixedSize(7), func(c *lite.Crate, v *uint64, m lite.UseMode) []byte {
+		x := int64(*v)
+		s := c.UseI56(&x, m)
+		*v = uint64(x)
+		return s
+	}},
+	{"U64", 64, false, fixedSize(8), func(c *lite.Crate, v *uint64, m lite.UseMode) []byte {
+		return c.UseU64(v, m)
+	}},
+	{"I64", 64, true, fixedSize(8), func(c *lite.Crate, v *uint64, m lite.UseMode) []byte {
+		x := int64(*v)
+		s := c.UseI64(&x, m)
+		*v = uint64(x)
+		return s
+	}},
+	{"Int", 64, true, fixedSize(8), func(c *lite.Crate, v *uint64, m lite.UseMode) []byte {
+		x := int(*v)
+		s := c.UseInt(&x, m)
+		*v = uint64(x)
+		return s
+	}},
+	{"Uint", 64, false, fixedSize(8), func(c *lite.Crate, v *uint64, m lite.UseMode) []byte {
+		x := uint(*v)
+		s := c.UseUint(&x, m)
+		*v = uint64(x)
+		return s
+	}},
+	{"UVarint", 64, false, findUVarintBytesFromValue, func(c *lite.Crate, v *uint64, m lite.UseMode) []byte {
+		_, s := c.UseUVarint(v, m)
+		return s
+	}},
+	{"Varint", 64, true, func(val uint64) uint64 { return findVarintBytesFromValue(int64(val)) }, func(c *lite.Crate, v *uint64, m lite.UseMode) []byte {
+		x := int64(*v)
+		_, s := c.UseVarint(&x, m)
+		*v = uint64(x)
+		return s
+	}},
+}
+
+// Returns what a value of the given width reads back as after its excess high bits are dropped
+func truncateToWidth(val uint64, bits uint, signed bool) uint64 {
+	if bits == 64 {
+		return val
+	}
+	if signed {
+		return uint64(int64(val<<(64-bits)) >> (64 - bits))
+	}
+	return val & (1<<bits - 1)
+}
+
+// Generates min, max, +-1 around both, zero, sign bit and alternating bit patterns for a width,
+// plus (for widths under 64 bits) values just outside the representable range
+func boundaryValues(bits uint, signed bool) (inRange []uint64, outOfRange []uint64) {
+	var min, max uint64
+	if signed {
+		min = uint64(int64(-1) << (bits - 1))
+		max = 1<<(bits-1) - 1
+	} else {
+		max = ^uint64(0) >> (64 - bits)
+	}
+	patterns := []uint64{0x5555555555555555, 0xAAAAAAAAAAAAAAAA, 1 << (bits - 1), 1<<(bits-1) - 1}
+	inRange = append([]uint64{min, min + 1, max - 1, max, 0, 1, ^uint64(0)}, patterns...)
+	for i := range inRange {
+		inRange[i] = truncateToWidth(inRange[i], bits, signed)
+	}
+	if bits < 64 {
+		outOfRange = []uint64{max + 1, max + 2, min - 1, 1 << bits, ^uint64(0) << bits}
+		if !signed {
+			outOfRange = outOfRange[:2]
+		}
+	}
+	return inRange, outOfRange
+}
+
+func TestBoundaryMatrix(t *testing.T) {
+	crate := lite.NewCrate(8, lite.FlagAutoDouble)
+	for _, wc := range widthCases {
+		inRange, outOfRange := boundaryValues(wc.bits, wc.signed)
+		for _, val := range inRange {
+			checkAllModes(t, crate, wc, val, val)
+		}
+		for _, val := range outOfRange {
+			expect := truncateToWidth(val, wc.bits, wc.signed)
+			if expect == val {
+				t.Errorf("%s misuse - FAIL: %#x is not out of range", wc.name, val)
+			}
+			checkAllModes(t, crate, wc, val, expect)
+		}
+	}
+}
+
+func checkAllModes(t *testing.T, crate *lite.Crate, wc widthCase, val uint64, expect uint64) {
+	t.Helper()
+	crate.Reset()
+	size := wc.size(expect)
+	written := val
+	wc.use(crate, &written, lite.Write)
+	wc.use(crate, &written, lite.Write)
+	if crate.WriteIndex() != size*2 {
+		t.Errorf("Write%s(%#x) - FAIL: index %d != %d", wc.name, val, crate.WriteIndex(), size*2)
+		return
+	}
+	var peeked uint64
+	wc.use(crate, &peeked, lite.Peek)
+	if peeked != expect || crate.ReadIndex() != 0 {
+		t.Errorf("Peek%s(%#x) - FAIL: %#x != %#x or index %d != 0", wc.name, val, peeked, expect, crate.ReadIndex())
+	}
+	var discarded uint64
+	wc.use(crate, &discarded, lite.Discard)
+	if discarded != 0 || crate.ReadIndex() != size {
+		t.Errorf("Discard%s(%#x) - FAIL: value touched or index %d != %d", wc.name, val, crate.ReadIndex(), size)
+	}
+	var sliced uint64
+	slice := wc.use(crate, &sliced, lite.Slice)
+	if uint64(len(slice)) != size || uint64(cap(slice)) != size || sliced != 0 || crate.ReadIndex() != size {
+		t.Errorf("Slice%s(%#x) - FAIL: len(%d)/cap(%d) != %d or value touched", wc.name, val, len(slice), cap(slice), size)
+	}
+	var read uint64
+	wc.use(crate, &read, lite.Read)
+	if read != expect || crate.ReadIndex() != size*2 {
+		t.Errorf("Read%s(%#x) - FAIL: %#x != %#x or index %d != %d", wc.name, val, read, expect, crate.ReadIndex(), size*2)
+	}
+	if catchPanic[error](func() { wc.use(crate, &read, lite.UseMode(255)) }) == nil {
+		t.Errorf("Use%s - FAIL: invalid mode did not panic", wc.name)
+	}
+}
@@ -0,0 +1,105 @@
+package litecrate
+
+import (
+	"strconv"
+	"unicode/utf8"
+)
+
+/**************
+	BUILDER
+***************/
+
+// A Builder writes text straight into a crate, instead of building a string with strings.Builder
+// and copying it in with WriteStringWithCounter(). The text is written as a section (see BeginSection()),
+// which has the same layout as a string with a length-or-nil counter, so it is read back with
+// ReadStringWithCounter() and can be framed or compressed along with the rest of the crate.
+//
+// A Builder implements io.Writer, io.StringWriter and io.ByteWriter, so fmt.Fprintf() can write to it.
+// Nothing else may be written to the crate between BeginText() and Finish(), or it becomes part of the text
+//
+// Example:
+//
+//	text := myCrate.BeginText()
+//	text.WriteString("order ")
+//	text.AppendUint(orderID)
+//	fmt.Fprintf(text, " shipped to %s", city)
+//	text.Finish()
+type Builder struct {
+	crate *Crate
+	start uint64
+}
+
+// Begin writing text to the crate with a Builder
+func (c *Crate) BeginText() *Builder {
+	c.BeginSection()
+	return &Builder{crate: c, start: c.write}
+}
+
+// Implements io.Writer
+func (b *Builder) Write(p []byte) (n int, err error) {
+	b.crate.WriteBytes(p)
+	return len(p), nil
+}
+
+// Implements io.StringWriter
+func (b *Builder) WriteString(s string) (n int, err error) {
+	b.crate.WriteString(s)
+	return len(s), nil
+}
+
+// Implements io.ByteWriter
+func (b *Builder) WriteByte(c byte) error {
+	b.crate.WriteU8(c)
+	return nil
+}
+
+// Write r encoded as UTF-8, returning the number of bytes written
+func (b *Builder) WriteRune(r rune) (n int, err error) {
+	var buf [utf8.UTFMax]byte
+	n = utf8.EncodeRune(buf[:], r)
+	b.crate.WriteBytes(buf[:n])
+	return n, nil
+}
+
+// Write val in base 10, without going through fmt
+func (b *Builder) AppendInt(val int64) {
+	var buf [20]byte
+	b.crate.WriteBytes(strconv.AppendInt(buf[:0], val, 10))
+}
+
+// Write val in base 10, without going through fmt
+func (b *Builder) AppendUint(val uint64) {
+	var buf [20]byte
+	b.crate.WriteBytes(strconv.AppendUint(buf[:0], val, 10))
+}
+
+// Write val formatted like strconv.FormatFloat(val, format, precision, 64), without going through fmt
+func (b *Builder) AppendFloat(val float64, format byte, precision int) {
+	var buf [32]byte
+	b.crate.WriteBytes(strconv.AppendFloat(buf[:0], val, format, precision, 64))
+}
+
+// Write val as "true" or "false"
+func (b *Builder) AppendBool(val bool) {
+	var buf [5]byte
+	b.crate.WriteBytes(strconv.AppendBool(buf[:0], val))
+}
+
+// Returns the number of bytes of text written so far
+func (b *Builder) Len() int {
+	return int(b.crate.write - b.start)
+}
+
+// Returns the text written so far. The slice is only valid until the crate is next written to
+func (b *Builder) Bytes() []byte {
+	return b.crate.data[b.start:b.crate.write:b.crate.write]
+}
+
+// Finish the text, writing its length before it.
+// Panics if a section begun after BeginText() has not been ended, or if the text was already finished
+func (b *Builder) Finish() {
+	if last := len(b.crate.sections) - 1; last < 0 || b.crate.sections[last] != b.start {
+		panic("LiteCrate: Builder.Finish() called while its text is not the innermost open section")
+	}
+	b.crate.EndSection()
+}
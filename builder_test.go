@@ -0,0 +1,78 @@
+package litecrate_test
+
+import (
+	"fmt"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+func TestBuilder(t *testing.T) {
+	crate := lite.NewCrate(4, lite.FlagAutoDouble)
+	crate.WriteU8(7)
+	text := crate.BeginText()
+	text.WriteString("order ")
+	text.AppendUint(1234)
+	text.WriteByte(' ')
+	text.AppendInt(-5)
+	text.WriteRune('→')
+	text.AppendFloat(2.5, 'f', 2)
+	text.WriteByte(' ')
+	text.AppendBool(true)
+	fmt.Fprintf(text, " to %s", "Zürich")
+	want := "order 1234 -5→2.50 true to Zürich"
+	if text.Len() != len(want) || string(text.Bytes()) != want {
+		t.Errorf("Builder.Bytes() - FAIL: %q, want %q", text.Bytes(), want)
+	}
+	text.Finish()
+	crate.WriteU8(9)
+
+	if crate.ReadU8() != 7 {
+		t.Fatalf("Builder - FAIL: wrote before its own text")
+	}
+	if got := crate.ReadStringWithCounter(); got != want {
+		t.Errorf("Builder.Finish() - FAIL: read %q, want %q", got, want)
+	}
+	if crate.ReadU8() != 9 {
+		t.Errorf("Builder.Finish() - FAIL: wrong length written")
+	}
+	if catchPanic[error](func() { text.Finish() }) == nil {
+		t.Errorf("Builder.Finish() - FAIL: finishing twice did not panic")
+	}
+	empty := crate.BeginText()
+	empty.Finish()
+	if crate.ReadStringWithCounter() != "" || crate.ReadsLeft() != 0 {
+		t.Errorf("Builder.Finish() - FAIL: empty text not read back as empty string")
+	}
+}
+
+func TestBuilderNested(t *testing.T) {
+	crate := lite.NewCrate(16, lite.FlagAutoDouble)
+	crate.BeginSection()
+	text := crate.BeginText()
+	text.WriteString("inner")
+	crate.BeginSection()
+	if catchPanic[error](func() { text.Finish() }) == nil {
+		t.Errorf("Builder.Finish() - FAIL: did not panic with a later section still open")
+	}
+	crate.EndSection()
+	text.Finish()
+	crate.EndSection()
+	crate.DiscardSection()
+	if crate.ReadsLeft() != 0 {
+		t.Errorf("Builder - FAIL: %d bytes left after outer section", crate.ReadsLeft())
+	}
+}
+
+func BenchmarkBuilder(b *testing.B) {
+	crate := lite.NewCrate(256, lite.FlagAutoDouble)
+	for i := 0; i < b.N; i += 1 {
+		crate.Reset()
+		text := crate.BeginText()
+		text.WriteString("user ")
+		text.AppendUint(uint64(i))
+		text.WriteString(" logged in from ")
+		text.WriteString("10.0.0.1")
+		text.Finish()
+	}
+}
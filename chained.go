@@ -0,0 +1,184 @@
+package litecrate
+
+import (
+	"io"
+)
+
+// Segment size used by NewChainedCrate() when segmentSize is 0
+const DefaultSegmentSize = 1 << 20
+
+/**************
+	CHAINED CRATE
+***************/
+
+// A ChainedCrate holds its data in a chain of fixed size segments instead of one buffer, so growing
+// adds a segment rather than copying everything written so far, which matters for crates of hundreds of megabytes.
+//
+// Values are written with WriteValue(), which passes the current segment's crate to a function that
+// uses the ordinary Use____() API, and read back the same way with ReadValue(). A value is never split
+// between segments: if it does not fit in the space left, it is rolled back and written to a new segment
+// (a value larger than a whole segment gets a segment of its own). The segments joined together hold exactly
+// the bytes one crate would hold had every value been written to it, which Coalesce() returns as one crate.
+//
+// Because each segment is its own crate, values must be read in the same groups they were written in,
+// and UseRef() and UseBytesDedup() must not refer back across WriteValue() calls
+type ChainedCrate struct {
+	segSize  uint64
+	flags    uint8
+	segments []*Crate
+	writeSeg int // Index of the segment being written
+	readSeg  int // Index of the segment being read
+}
+
+// Returns an empty ChainedCrate that adds segments of segmentSize bytes (0 = DefaultSegmentSize).
+// The growth flags are ignored, other flags (FlagTaggedFields, FlagSortedMaps...) apply to every segment
+func NewChainedCrate(segmentSize uint64, flags uint8) *ChainedCrate {
+	if segmentSize == 0 {
+		segmentSize = DefaultSegmentSize
+	}
+	c := &ChainedCrate{segSize: segmentSize, flags: flags &^ (FlagManualGrow | FlagGrowExact | FlagNoGrow)}
+	c.segments = []*Crate{NewCrate(segmentSize, c.flags|FlagNoGrow)}
+	return c
+}
+
+// Write a value to the chain by calling write with the crate of the segment it should go in
+//
+// Example:
+//
+//	chain.WriteValue(func(crate *Crate) {
+//		crate.WriteStringWithCounter(name)
+//		crate.WriteU64(id)
+//	})
+func (c *ChainedCrate) WriteValue(write func(crate *Crate)) {
+	seg := c.segments[c.writeSeg]
+	if seg.TryWrite(func() { write(seg) }) == nil {
+		return
+	}
+	if seg.write > 0 {
+		seg = c.nextSegment()
+		if seg.TryWrite(func() { write(seg) }) == nil {
+			return
+		}
+	}
+	// Larger than a whole segment, so let this segment grow to fit it
+	seg.flags = c.flags
+	defer func() { seg.flags = c.flags | FlagNoGrow }()
+	write(seg)
+}
+
+// Read a value from the chain by calling read with the crate of the segment it is in.
+// Panics if every byte has been read
+func (c *ChainedCrate) ReadValue(read func(crate *Crate)) {
+	for c.readSeg < c.writeSeg && c.segments[c.readSeg].ReadsLeft() == 0 {
+		c.readSeg += 1
+	}
+	seg := c.segments[c.readSeg]
+	if seg.ReadsLeft() == 0 {
+		panic(readPastEndError("value, no unread bytes left in ChainedCrate"))
+	}
+	read(seg)
+}
+
+// Write val to the chain as one value
+func (c *ChainedCrate) WriteSelfSerializer(val SelfSerializer) {
+	c.WriteValue(func(crate *Crate) { crate.WriteSelfSerializer(val) })
+}
+
+// Read val from the chain as one value
+func (c *ChainedCrate) ReadSelfSerializer(val SelfSerializer) {
+	c.ReadValue(func(crate *Crate) { crate.ReadSelfSerializer(val) })
+}
+
+// Returns the total number of bytes written
+func (c *ChainedCrate) Len() uint64 {
+	var total uint64
+	for _, seg := range c.segments[:c.writeSeg+1] {
+		total += seg.write
+	}
+	return total
+}
+
+// Returns the number of bytes written but not yet read
+func (c *ChainedCrate) ReadsLeft() uint64 {
+	var total uint64
+	for _, seg := range c.segments[c.readSeg : c.writeSeg+1] {
+		total += seg.ReadsLeft()
+	}
+	return total
+}
+
+// Returns the written data of each segment in order, without copying (for use with net.Buffers, for example).
+// The slices are only valid until the chain is next written to or reset
+func (c *ChainedCrate) Segments() [][]byte {
+	segments := make([][]byte, 0, c.writeSeg+1)
+	for _, seg := range c.segments[:c.writeSeg+1] {
+		if seg.write > 0 {
+			segments = append(segments, seg.Data())
+		}
+	}
+	return segments
+}
+
+// Returns a new crate with the given flags holding every byte written to the chain in one buffer,
+// with its read index set to the number of bytes read from the chain so far
+func (c *ChainedCrate) Coalesce(flags uint8) *Crate {
+	crate := NewCrate(c.Len(), flags)
+	for i, seg := range c.segments[:c.writeSeg+1] {
+		if i == c.readSeg {
+			crate.read = crate.write + seg.read
+		} else if i < c.readSeg {
+			crate.read = crate.write + seg.write
+		}
+		crate.WriteBytes(seg.Data())
+	}
+	return crate
+}
+
+// Implements io.WriterTo.
+// Writes every unread byte to w and advances the read index by the number of bytes written.
+// The segments are passed to w with writeVectored(), so when w is a network connection that supports
+// vectored I/O (such as a *net.TCPConn) they are sent with a single writev call, without being flattened
+func (c *ChainedCrate) WriteTo(w io.Writer) (n int64, err error) {
+	buffers := make([][]byte, 0, c.writeSeg-c.readSeg+1)
+	for _, seg := range c.segments[c.readSeg : c.writeSeg+1] {
+		if seg.ReadsLeft() > 0 {
+			buffers = append(buffers, seg.data[seg.read:seg.write])
+		}
+	}
+	n, err = writeVectored(w, buffers)
+	c.advance(uint64(n))
+	return n, err
+}
+
+// Mark the next n unread bytes as read
+func (c *ChainedCrate) advance(n uint64) {
+	for n > 0 {
+		seg := c.segments[c.readSeg]
+		step := seg.ReadsLeft()
+		if step > n {
+			step = n
+		}
+		seg.read += step
+		n -= step
+		if seg.ReadsLeft() == 0 && c.readSeg < c.writeSeg {
+			c.readSeg += 1
+		}
+	}
+}
+
+// Discard everything written, keeping the segments to be written again
+func (c *ChainedCrate) Reset() {
+	for _, seg := range c.segments[:c.writeSeg+1] {
+		seg.Reset()
+	}
+	c.writeSeg, c.readSeg = 0, 0
+}
+
+// Move writing to the next segment, reusing one kept by Reset() if there is one
+func (c *ChainedCrate) nextSegment() *Crate {
+	c.writeSeg += 1
+	if c.writeSeg == len(c.segments) {
+		c.segments = append(c.segments, NewCrate(c.segSize, c.flags|FlagNoGrow))
+	}
+	return c.segments[c.writeSeg]
+}
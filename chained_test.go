@@ -0,0 +1,113 @@
+package litecrate_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+func TestChainedCrate(t *testing.T) {
+	chain := lite.NewChainedCrate(32, lite.FlagDefault)
+	flat := lite.NewCrate(32, lite.FlagDefault)
+	var sent []streamMessage
+	for i := 0; i < 40; i += 1 {
+		msg := streamMessage{ID: uint32(i), Body: "body", Tags: make([]string, i%5)}
+		if i == 20 {
+			msg.Body = string(make([]byte, 100)) // Larger than a whole segment
+		}
+		chain.WriteSelfSerializer(&msg)
+		flat.WriteSelfSerializer(&msg)
+		sent = append(sent, msg)
+	}
+	if chain.Len() != uint64(flat.Len()) || chain.ReadsLeft() != chain.Len() {
+		t.Fatalf("ChainedCrate.Len() - FAIL: %d, flat crate %d", chain.Len(), flat.Len())
+	}
+	segments := chain.Segments()
+	if len(segments) < 10 || !bytes.Equal(bytes.Join(segments, nil), flat.Data()) {
+		t.Fatalf("ChainedCrate.Segments() - FAIL: %d segments do not join to the flat crate's data", len(segments))
+	}
+	for i := 0; i < 25; i += 1 {
+		var msg streamMessage
+		chain.ReadSelfSerializer(&msg)
+		if msg.ID != sent[i].ID || msg.Body != sent[i].Body || len(msg.Tags) != len(sent[i].Tags) {
+			t.Fatalf("ChainedCrate.ReadSelfSerializer() - FAIL: value %d = %+v", i, msg)
+		}
+	}
+	coalesced := chain.Coalesce(lite.FlagDefault)
+	if !bytes.Equal(coalesced.Data(), flat.Data()) || coalesced.ReadsLeft() != chain.ReadsLeft() {
+		t.Fatalf("ChainedCrate.Coalesce() - FAIL: %d unread, want %d", coalesced.ReadsLeft(), chain.ReadsLeft())
+	}
+	var msg streamMessage
+	coalesced.ReadSelfSerializer(&msg)
+	if msg.ID != 25 {
+		t.Errorf("ChainedCrate.Coalesce() - FAIL: next value read has ID %d, want 25", msg.ID)
+	}
+	unread := flat.Data()[chain.Len()-chain.ReadsLeft():]
+	var rest bytes.Buffer
+	if n, err := chain.WriteTo(&rest); err != nil || n != int64(len(unread)) || !bytes.Equal(rest.Bytes(), unread) {
+		t.Errorf("ChainedCrate.WriteTo() - FAIL: wrote %d bytes, want %d, %v", n, len(unread), err)
+	}
+	if chain.ReadsLeft() != 0 || catchPanic[error](func() { chain.ReadSelfSerializer(&msg) }) == nil {
+		t.Errorf("ChainedCrate.WriteTo() - FAIL: %d bytes left unread", chain.ReadsLeft())
+	}
+}
+
+func TestChainedCrateReset(t *testing.T) {
+	chain := lite.NewChainedCrate(16, lite.FlagDefault)
+	// Running out of space inside a slice element rolls back the whole value
+	nums := []uint32{1, 2, 3}
+	for i := 0; i < 4; i += 1 {
+		chain.WriteValue(func(crate *lite.Crate) { lite.UseSlice(crate, lite.Write, &nums, crate.UseU32) })
+	}
+	if segments := chain.Segments(); len(segments) != 4 || len(segments[0]) != 13 {
+		t.Fatalf("ChainedCrate.WriteValue() - FAIL: %d segments", len(segments))
+	}
+	chain.Reset()
+	if chain.Len() != 0 || len(chain.Segments()) != 0 {
+		t.Fatalf("ChainedCrate.Reset() - FAIL: %d bytes left", chain.Len())
+	}
+	chain.WriteValue(func(crate *lite.Crate) { crate.WriteU64(7) })
+	var got uint64
+	chain.ReadValue(func(crate *lite.Crate) { got = crate.ReadU64() })
+	if got != 7 || chain.ReadsLeft() != 0 {
+		t.Errorf("ChainedCrate.ReadValue() - FAIL: read %d after reset", got)
+	}
+}
+
+// Accepts limit bytes, then fails
+type failingWriter struct {
+	limit int
+	out   []byte
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.limit-len(w.out) {
+		n := w.limit - len(w.out)
+		w.out = append(w.out, p[:n]...)
+		return n, io.ErrClosedPipe
+	}
+	w.out = append(w.out, p...)
+	return len(p), nil
+}
+
+func TestChainedCrateWriteTo(t *testing.T) {
+	chain := lite.NewChainedCrate(16, lite.FlagDefault)
+	for i := 0; i < 20; i += 1 {
+		chain.WriteValue(func(crate *lite.Crate) { crate.WriteU64(uint64(i)) })
+	}
+	flat := chain.Coalesce(lite.FlagDefault)
+
+	failing := &failingWriter{limit: 37}
+	if n, err := chain.WriteTo(failing); n != 37 || err == nil || chain.ReadsLeft() != chain.Len()-37 {
+		t.Fatalf("ChainedCrate.WriteTo() - FAIL: wrote %d, %v, %d left", n, err, chain.ReadsLeft())
+	}
+	var rest bytes.Buffer
+	if n, err := chain.WriteTo(&rest); err != nil || n != int64(len(flat.Data())-37) || !bytes.Equal(rest.Bytes(), flat.Data()[37:]) {
+		t.Errorf("ChainedCrate.WriteTo() - FAIL: wrote %d, %v", n, err)
+	}
+	if chain.ReadsLeft() != 0 {
+		t.Errorf("ChainedCrate.WriteTo() - FAIL: %d bytes left unread", chain.ReadsLeft())
+	}
+}
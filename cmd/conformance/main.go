@@ -0,0 +1,288 @@
+// Command conformance checks that an implementation of the LiteCrate format
+// (a code generator's output, or a port to another language) encodes and decodes
+// the same bytes as this Go reference implementation.
+//
+//	conformance -vectors dir command [args...]
+//
+// runs command and checks it against every *.json vector file in dir (see Vector).
+// The command must speak this line based protocol on its stdin and stdout, one JSON object per line:
+//
+//	{"op":"encode","values":[{"kind":"U24","value":5}]}          -> {"bytes":"050000"}
+//	{"op":"decode","kinds":["U24"],"bytes":"050000"}            -> {"values":[{"kind":"U24","value":5}]}
+//
+// replying {"error":"..."} if it cannot. Also:
+//
+//	conformance -gen dir       writes the standard vectors to dir
+//	conformance -reference     speaks the protocol with the Go reference implementation
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// A line of the protocol sent to the implementation
+type Request struct {
+	Op     string   `json:"op"`
+	Values []Value  `json:"values,omitempty"`
+	Kinds  []string `json:"kinds,omitempty"`
+	Bytes  string   `json:"bytes,omitempty"`
+}
+
+// A line of the protocol received from the implementation
+type Response struct {
+	Bytes  string  `json:"bytes,omitempty"`
+	Values []Value `json:"values,omitempty"`
+	Error  string  `json:"error,omitempty"`
+}
+
+func main() {
+	vectors := flag.String("vectors", "", "directory of vector files to check the command against")
+	gen := flag.String("gen", "", "write the standard vectors to this directory and exit")
+	reference := flag.Bool("reference", false, "speak the protocol on stdin/stdout with the Go reference implementation")
+	flag.Parse()
+	switch {
+	case *gen != "":
+		if err := WriteVectors(*gen, StandardVectors()); err != nil {
+			fail(err)
+		}
+	case *reference:
+		if err := Serve(os.Stdin, os.Stdout); err != nil {
+			fail(err)
+		}
+	case *vectors != "" && flag.NArg() > 0:
+		loaded, err := LoadVectors(*vectors)
+		if err != nil {
+			fail(err)
+		}
+		cmd := exec.Command(flag.Arg(0), flag.Args()[1:]...)
+		cmd.Stderr = os.Stderr
+		stdin, _ := cmd.StdinPipe()
+		stdout, _ := cmd.StdoutPipe()
+		if err := cmd.Start(); err != nil {
+			fail(err)
+		}
+		failures := Check(loaded, stdin, stdout, os.Stdout)
+		stdin.Close()
+		cmd.Wait()
+		fmt.Printf("%d vectors, %d failures\n", len(loaded), failures)
+		if failures > 0 {
+			os.Exit(1)
+		}
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
+
+// Answer protocol requests from r on w using the Go reference implementation, until r ends
+func Serve(r io.Reader, w io.Writer) error {
+	return serve(r, w, reference)
+}
+
+func serve(r io.Reader, w io.Writer, handle func(req Request) (Response, error)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 64<<20)
+	encoder := json.NewEncoder(w)
+	for scanner.Scan() {
+		var req Request
+		var resp Response
+		err := json.Unmarshal(scanner.Bytes(), &req)
+		if err == nil {
+			resp, err = handle(req)
+		}
+		if err != nil {
+			resp = Response{Error: err.Error()}
+		}
+		if err := encoder.Encode(resp); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func reference(req Request) (resp Response, err error) {
+	switch req.Op {
+	case "encode":
+		data, err := encodeValues(req.Values)
+		return Response{Bytes: hex.EncodeToString(data)}, err
+	case "decode":
+		data, err := hex.DecodeString(req.Bytes)
+		if err != nil {
+			return resp, err
+		}
+		values, err := decodeValues(req.Kinds, data)
+		return Response{Values: values}, err
+	}
+	return resp, errors.New("conformance: unknown op " + req.Op)
+}
+
+// Check every vector against the implementation reached through w and r,
+// reporting each failure to report. Returns the number of vectors that failed
+func Check(vectors []Vector, w io.Writer, r io.Reader, report io.Writer) (failures int) {
+	encoder := json.NewEncoder(w)
+	decoder := json.NewDecoder(r)
+	call := func(req Request) (resp Response, err error) {
+		if err = encoder.Encode(req); err != nil {
+			return resp, err
+		}
+		if err = decoder.Decode(&resp); err != nil {
+			return resp, err
+		}
+		if resp.Error != "" {
+			err = errors.New(resp.Error)
+		}
+		return resp, err
+	}
+	for _, vec := range vectors {
+		if err := checkVector(vec, call); err != nil {
+			fmt.Fprintf(report, "FAIL %s: %v\n", vec.Name, err)
+			failures += 1
+		}
+	}
+	return failures
+}
+
+func checkVector(vec Vector, call func(req Request) (Response, error)) error {
+	resp, err := call(Request{Op: "encode", Values: vec.Values})
+	if err != nil {
+		return fmt.Errorf("encode: %w", err)
+	}
+	if !strings.EqualFold(resp.Bytes, vec.Bytes) {
+		return fmt.Errorf("encode: got %s, expected %s", resp.Bytes, vec.Bytes)
+	}
+	kinds := make([]string, len(vec.Values))
+	for i, val := range vec.Values {
+		kinds[i] = val.Kind
+	}
+	resp, err = call(Request{Op: "decode", Kinds: kinds, Bytes: vec.Bytes})
+	if err != nil {
+		return fmt.Errorf("decode: %w", err)
+	}
+	// Decoded values are compared by encoding them with the reference implementation,
+	// so equivalent spellings of a value (1e3 and 1000) are accepted
+	data, err := encodeValues(resp.Values)
+	if err != nil || len(resp.Values) != len(vec.Values) || hex.EncodeToString(data) != strings.ToLower(vec.Bytes) {
+		return fmt.Errorf("decode: got %s", valuesString(resp.Values))
+	}
+	for i := range resp.Values {
+		if resp.Values[i].Kind != vec.Values[i].Kind {
+			return fmt.Errorf("decode: value %d is %s, expected %s", i, resp.Values[i].Kind, vec.Values[i].Kind)
+		}
+	}
+	return nil
+}
+
+func valuesString(values []Value) string {
+	data, _ := json.Marshal(values)
+	return string(data)
+}
+
+// Load every *.json vector file in dir, each holding a JSON array of vectors
+func LoadVectors(dir string) (vectors []Vector, err error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var file []Vector
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		vectors = append(vectors, file...)
+	}
+	return vectors, nil
+}
+
+// Write vectors to dir as one vector file per kind
+func WriteVectors(dir string, vectors []Vector) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	files := make(map[string][]Vector)
+	for _, vec := range vectors {
+		name := strings.ToLower(vec.Values[0].Kind)
+		files[name] = append(files[name], vec)
+	}
+	for name, file := range files {
+		data, err := json.MarshalIndent(file, "", "\t")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, name+".json"), append(data, '\n'), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns the standard vectors: edge values of every scalar kind, strings and bytes,
+// with expected bytes from the reference implementation
+func StandardVectors() (vectors []Vector) {
+	add := func(kind string, values ...string) {
+		for _, raw := range values {
+			vec := Vector{Name: kind + " " + raw, Values: []Value{{Kind: kind, Value: json.RawMessage(raw)}}}
+			data, err := encodeValues(vec.Values)
+			if err != nil {
+				panic(err)
+			}
+			vec.Bytes = hex.EncodeToString(data)
+			vectors = append(vectors, vec)
+		}
+	}
+	add("Bool", "false", "true")
+	add("U8", "0", "255")
+	add("I8", "-128", "127")
+	add("U16", "0", "65535")
+	add("I16", "-32768", "32767")
+	add("U24", "0", "16777215")
+	add("I24", "-8388608", "8388607", "-1")
+	add("U32", "0", "4294967295")
+	add("I32", "-2147483648", "2147483647")
+	add("U40", "0", "1099511627775")
+	add("I40", "-549755813888", "549755813887", "-1")
+	add("U48", "0", "281474976710655")
+	add("I48", "-140737488355328", "140737488355327")
+	add("U56", "0", "72057594037927935")
+	add("I56", "-36028797018963968", "36028797018963967")
+	add("U64", "0", "18446744073709551615")
+	add("I64", "-9223372036854775808", "9223372036854775807")
+	add("F32", "0", "-1.5", "3.4028235e+38", "1e-45")
+	add("F64", "0", "-1.5", "1.7976931348623157e+308", "5e-324")
+	add("C64", "[0,0]", "[1.5,-2.5]")
+	add("C128", "[0,0]", "[1.5,-2.5]")
+	add("UVarint", "0", "127", "128", "16383", "16384", "72057594037927935", "18446744073709551615")
+	add("Varint", "0", "-1", "1", "-64", "64", "-9223372036854775808", "9223372036854775807")
+	add("String", `""`, `"LiteCrate"`, `"日本語 ✓"`)
+	add("Bytes", "null", `""`, `"00ff10"`)
+	mixed := Vector{Name: "mixed record", Values: []Value{
+		{Kind: "String", Value: json.RawMessage(`"Derek"`)},
+		{Kind: "U8", Value: json.RawMessage(`30`)},
+		{Kind: "I24", Value: json.RawMessage(`-1000`)},
+		{Kind: "UVarint", Value: json.RawMessage(`300`)},
+		{Kind: "Bytes", Value: json.RawMessage(`"deadbeef"`)},
+	}}
+	data, _ := encodeValues(mixed.Values)
+	mixed.Bytes = hex.EncodeToString(data)
+	return append(vectors, mixed)
+}
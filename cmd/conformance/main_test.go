@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+// Runs Check against Serve() connected by pipes, as if Serve() were another implementation
+func checkAgainst(vectors []Vector, serve func(r io.Reader, w io.Writer) error) (int, string) {
+	reqR, reqW := io.Pipe()
+	respR, respW := io.Pipe()
+	go func() {
+		serve(reqR, respW)
+		respW.Close()
+	}()
+	var report bytes.Buffer
+	failures := Check(vectors, reqW, respR, &report)
+	reqW.Close()
+	return failures, report.String()
+}
+
+func TestConformance(t *testing.T) {
+	dir := t.TempDir()
+	if err := WriteVectors(dir, StandardVectors()); err != nil {
+		t.Fatalf("WriteVectors() - FAIL: %v", err)
+	}
+	vectors, err := LoadVectors(dir)
+	if err != nil || len(vectors) != len(StandardVectors()) {
+		t.Fatalf("LoadVectors() - FAIL: loaded %d vectors, %v", len(vectors), err)
+	}
+	if failures, report := checkAgainst(vectors, Serve); failures != 0 {
+		t.Errorf("Check(reference) - FAIL: %d failures\n%s", failures, report)
+	}
+
+	// An implementation that writes I24 as 4 bytes must fail exactly the I24 vectors and the mixed record
+	broken := func(r io.Reader, w io.Writer) error {
+		return serve(r, w, func(req Request) (Response, error) {
+			resp, err := reference(req)
+			for _, val := range req.Values {
+				if val.Kind == "I24" {
+					resp.Bytes += "00"
+				}
+			}
+			return resp, err
+		})
+	}
+	failures, report := checkAgainst(vectors, broken)
+	if failures != 4 || !strings.Contains(report, "FAIL I24 -1:") || !strings.Contains(report, "FAIL mixed record:") {
+		t.Errorf("Check(broken) - FAIL: %d failures\n%s", failures, report)
+	}
+}
+
+func TestVectorValues(t *testing.T) {
+	for _, vec := range StandardVectors() {
+		kinds := []string{}
+		for _, val := range vec.Values {
+			kinds = append(kinds, val.Kind)
+		}
+		data, _ := encodeValues(vec.Values)
+		values, err := decodeValues(kinds, data)
+		if err != nil {
+			t.Errorf("decodeValues(%s) - FAIL: %v", vec.Name, err)
+			continue
+		}
+		again, _ := encodeValues(values)
+		if !bytes.Equal(again, data) {
+			t.Errorf("decodeValues(%s) - FAIL: round trip gave %s", vec.Name, valuesString(values))
+		}
+	}
+	if _, err := decodeValues([]string{"U32"}, []byte{1, 2}); err == nil {
+		t.Errorf("decodeValues() - FAIL: short input did not error")
+	}
+	if _, err := decodeValues([]string{"U8"}, []byte{1, 2}); err == nil {
+		t.Errorf("decodeValues() - FAIL: leftover input did not error")
+	}
+	if _, err := encodeValues([]Value{{Kind: "U128", Value: json.RawMessage("1")}}); err == nil {
+		t.Errorf("encodeValues() - FAIL: unknown kind did not error")
+	}
+	if _, err := encodeValues([]Value{{Kind: "U8", Value: json.RawMessage("256")}}); err == nil {
+		t.Errorf("encodeValues() - FAIL: out of range value did not error")
+	}
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+// One value in a vector, Kind is a FieldKind name ("U24", "String"...).
+//
+// Integers are JSON numbers, floats are JSON numbers, complex numbers are [real, imaginary],
+// strings are JSON strings and bytes are hex strings (null for nil)
+type Value struct {
+	Kind  string          `json:"kind"`
+	Value json.RawMessage `json:"value"`
+}
+
+// A conformance test vector: the values, written one after the other, must encode to Bytes (hex)
+type Vector struct {
+	Name   string  `json:"name"`
+	Values []Value `json:"values"`
+	Bytes  string  `json:"bytes"`
+}
+
+var kindsByName = func() map[string]lite.FieldKind {
+	kinds := make(map[string]lite.FieldKind)
+	for k := lite.KindBool; k <= lite.KindBytes; k += 1 {
+		kinds[k.String()] = k
+	}
+	return kinds
+}()
+
+// Encode values with the Go reference implementation
+func encodeValues(values []Value) (data []byte, err error) {
+	crate := lite.NewCrate(64, lite.FlagAutoDouble)
+	for _, val := range values {
+		if err = writeValue(crate, val); err != nil {
+			return nil, err
+		}
+	}
+	return crate.Data(), nil
+}
+
+// Decode values of the given kinds with the Go reference implementation
+func decodeValues(kinds []string, data []byte) (values []Value, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("conformance: cannot decode: " + toString(r))
+		}
+	}()
+	crate := lite.OpenCrate(data, lite.FlagStatic)
+	for _, name := range kinds {
+		val, err := readValue(crate, name)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, val)
+	}
+	if crate.ReadsLeft() != 0 {
+		return nil, errors.New("conformance: " + strconv.FormatUint(crate.ReadsLeft(), 10) + " bytes left after decoding")
+	}
+	return values, nil
+}
+
+func toString(r any) string {
+	if err, ok := r.(error); ok {
+		return err.Error()
+	}
+	if str, ok := r.(string); ok {
+		return str
+	}
+	return "unknown panic"
+}
+
+func writeValue(crate *lite.Crate, val Value) error {
+	kind, ok := kindsByName[val.Kind]
+	if !ok {
+		return errors.New("conformance: unknown kind " + strconv.Quote(val.Kind))
+	}
+	raw := string(val.Value)
+	var err error
+	switch kind {
+	case lite.KindBool:
+		var b bool
+		err = json.Unmarshal(val.Value, &b)
+		crate.WriteBool(b)
+	case lite.KindU8, lite.KindU16, lite.KindU24, lite.KindU32, lite.KindU40, lite.KindU48, lite.KindU56, lite.KindU64, lite.KindUVarint:
+		var u uint64
+		u, err = strconv.ParseUint(raw, 10, kindBits(kind))
+		writeUint(crate, kind, u)
+	case lite.KindI8, lite.KindI16, lite.KindI24, lite.KindI32, lite.KindI40, lite.KindI48, lite.KindI56, lite.KindI64, lite.KindVarint:
+		var i int64
+		i, err = strconv.ParseInt(raw, 10, kindBits(kind))
+		writeInt(crate, kind, i)
+	case lite.KindF32:
+		var f float64
+		f, err = strconv.ParseFloat(raw, 32)
+		crate.WriteF32(float32(f))
+	case lite.KindF64:
+		var f float64
+		f, err = strconv.ParseFloat(raw, 64)
+		crate.WriteF64(f)
+	case lite.KindC64, lite.KindC128:
+		var parts [2]json.Number
+		if err = json.Unmarshal(val.Value, &parts); err != nil {
+			break
+		}
+		bits := 64
+		if kind == lite.KindC64 {
+			bits = 32
+		}
+		var re, im float64
+		if re, err = strconv.ParseFloat(string(parts[0]), bits); err != nil {
+			break
+		}
+		if im, err = strconv.ParseFloat(string(parts[1]), bits); err != nil {
+			break
+		}
+		if kind == lite.KindC64 {
+			crate.WriteC64(complex(float32(re), float32(im)))
+		} else {
+			crate.WriteC128(complex(re, im))
+		}
+	case lite.KindString:
+		var str string
+		err = json.Unmarshal(val.Value, &str)
+		crate.WriteStringWithCounter(str)
+	case lite.KindBytes:
+		var b []byte
+		if raw != "null" {
+			var str string
+			if err = json.Unmarshal(val.Value, &str); err != nil {
+				break
+			}
+			if b, err = hex.DecodeString(str); err != nil {
+				break
+			}
+			if b == nil {
+				b = []byte{}
+			}
+		}
+		crate.WriteBytesWithCounter(b)
+	}
+	if err != nil {
+		return errors.New("conformance: invalid " + val.Kind + " value " + raw + ": " + err.Error())
+	}
+	return nil
+}
+
+func readValue(crate *lite.Crate, name string) (val Value, err error) {
+	kind, ok := kindsByName[name]
+	if !ok {
+		return val, errors.New("conformance: unknown kind " + strconv.Quote(name))
+	}
+	var raw string
+	switch kind {
+	case lite.KindBool:
+		raw = strconv.FormatBool(crate.ReadBool())
+	case lite.KindU8, lite.KindU16, lite.KindU24, lite.KindU32, lite.KindU40, lite.KindU48, lite.KindU56, lite.KindU64, lite.KindUVarint:
+		raw = strconv.FormatUint(readUint(crate, kind), 10)
+	case lite.KindI8, lite.KindI16, lite.KindI24, lite.KindI32, lite.KindI40, lite.KindI48, lite.KindI56, lite.KindI64, lite.KindVarint:
+		raw = strconv.FormatInt(readInt(crate, kind), 10)
+	case lite.KindF32:
+		raw = strconv.FormatFloat(float64(crate.ReadF32()), 'g', -1, 32)
+	case lite.KindF64:
+		raw = strconv.FormatFloat(crate.ReadF64(), 'g', -1, 64)
+	case lite.KindC64:
+		c := crate.ReadC64()
+		raw = "[" + strconv.FormatFloat(float64(real(c)), 'g', -1, 32) + "," + strconv.FormatFloat(float64(imag(c)), 'g', -1, 32) + "]"
+	case lite.KindC128:
+		c := crate.ReadC128()
+		raw = "[" + strconv.FormatFloat(real(c), 'g', -1, 64) + "," + strconv.FormatFloat(imag(c), 'g', -1, 64) + "]"
+	case lite.KindString:
+		quoted, _ := json.Marshal(crate.ReadStringWithCounter())
+		raw = string(quoted)
+	case lite.KindBytes:
+		b := crate.ReadBytesWithCounter()
+		raw = "null"
+		if b != nil {
+			raw = `"` + hex.EncodeToString(b) + `"`
+		}
+	}
+	return Value{Kind: name, Value: json.RawMessage(raw)}, nil
+}
+
+func kindBits(kind lite.FieldKind) int {
+	switch kind {
+	case lite.KindU8, lite.KindI8:
+		return 8
+	case lite.KindU16, lite.KindI16:
+		return 16
+	case lite.KindU24, lite.KindI24:
+		return 24
+	case lite.KindU32, lite.KindI32:
+		return 32
+	case lite.KindU40, lite.KindI40:
+		return 40
+	case lite.KindU48, lite.KindI48:
+		return 48
+	case lite.KindU56, lite.KindI56:
+		return 56
+	}
+	return 64
+}
+
+func writeUint(crate *lite.Crate, kind lite.FieldKind, u uint64) {
+	switch kind {
+	case lite.KindU8:
+		crate.WriteU8(uint8(u))
+	case lite.KindU16:
+		crate.WriteU16(uint16(u))
+	case lite.KindU24:
+		crate.WriteU24(uint32(u))
+	case lite.KindU32:
+		crate.WriteU32(uint32(u))
+	case lite.KindU40:
+		crate.WriteU40(u)
+	case lite.KindU48:
+		crate.WriteU48(u)
+	case lite.KindU56:
+		crate.WriteU56(u)
+	case lite.KindU64:
+		crate.WriteU64(u)
+	case lite.KindUVarint:
+		crate.WriteUVarint(u)
+	}
+}
+
+func readUint(crate *lite.Crate, kind lite.FieldKind) uint64 {
+	switch kind {
+	case lite.KindU8:
+		return uint64(crate.ReadU8())
+	case lite.KindU16:
+		return uint64(crate.ReadU16())
+	case lite.KindU24:
+		return uint64(crate.ReadU24())
+	case lite.KindU32:
+		return uint64(crate.ReadU32())
+	case lite.KindU40:
+		return crate.ReadU40()
+	case lite.KindU48:
+		return crate.ReadU48()
+	case lite.KindU56:
+		return crate.ReadU56()
+	case lite.KindU64:
+		return crate.ReadU64()
+	}
+	u, _ := crate.ReadUVarint()
+	return u
+}
+
+func writeInt(crate *lite.Crate, kind lite.FieldKind, i int64) {
+	switch kind {
+	case lite.KindI8:
+		crate.WriteI8(int8(i))
+	case lite.KindI16:
+		crate.WriteI16(int16(i))
+	case lite.KindI24:
+		crate.WriteI24(int32(i))
+	case lite.KindI32:
+		crate.WriteI32(int32(i))
+	case lite.KindI40:
+		crate.WriteI40(i)
+	case lite.KindI48:
+		crate.WriteI48(i)
+	case lite.KindI56:
+		crate.WriteI56(i)
+	case lite.KindI64:
+		crate.WriteI64(i)
+	case lite.KindVarint:
+		crate.WriteVarint(i)
+	}
+}
+
+func readInt(crate *lite.Crate, kind lite.FieldKind) int64 {
+	switch kind {
+	case lite.KindI8:
+		return int64(crate.ReadI8())
+	case lite.KindI16:
+		return int64(crate.ReadI16())
+	case lite.KindI24:
+		return int64(crate.ReadI24())
+	case lite.KindI32:
+		return int64(crate.ReadI32())
+	case lite.KindI40:
+		return crate.ReadI40()
+	case lite.KindI48:
+		return crate.ReadI48()
+	case lite.KindI56:
+		return crate.ReadI56()
+	case lite.KindI64:
+		return crate.ReadI64()
+	}
+	i, _ := crate.ReadVarint()
+	return i
+}
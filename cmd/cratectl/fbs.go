@@ -0,0 +1,271 @@
+//go:build !litecrate_core && !litecrate_notools
+
+package main
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+// Kinds used for FlatBuffers scalar types, which all keep their width
+var fbsScalars = map[string]lite.FieldKind{
+	"bool":    lite.KindBool,
+	"byte":    lite.KindI8,
+	"int8":    lite.KindI8,
+	"ubyte":   lite.KindU8,
+	"uint8":   lite.KindU8,
+	"short":   lite.KindI16,
+	"int16":   lite.KindI16,
+	"ushort":  lite.KindU16,
+	"uint16":  lite.KindU16,
+	"int":     lite.KindI32,
+	"int32":   lite.KindI32,
+	"uint":    lite.KindU32,
+	"uint32":  lite.KindU32,
+	"long":    lite.KindI64,
+	"int64":   lite.KindI64,
+	"ulong":   lite.KindU64,
+	"uint64":  lite.KindU64,
+	"float":   lite.KindF32,
+	"float32": lite.KindF32,
+	"double":  lite.KindF64,
+	"float64": lite.KindF64,
+	"string":  lite.KindString,
+	"[ubyte]": lite.KindBytes, // The type parseType() gives vectors of ubyte or uint8
+}
+
+/**************
+	FLATBUFFERS
+***************/
+
+// Read the tables and structs of a FlatBuffers schema (.fbs) file as schemas, one per table or struct,
+// with fields in declaration order (or id order, if every field of a table has an id attribute).
+//
+// Enums take the kind of their underlying type, vectors become KindSlice ([ubyte] becomes KindBytes)
+// and table or struct typed fields KindStruct, with the fields written inline. Deprecated fields
+// are left out. Unions, fixed length arrays and tables that contain themselves are not supported
+func ParseFBS(file string, src string) ([]*lite.Schema, error) {
+	p := &fbsParser{
+		s:        newIDLScanner(file, src),
+		resolver: idlResolver{scalars: fbsScalars, enums: map[string]lite.FieldKind{}, messages: map[string]*idlMessage{}, visiting: map[string]bool{}},
+	}
+	if err := p.parseFile(); err != nil {
+		return nil, err
+	}
+	schemas := make([]*lite.Schema, 0, len(p.order))
+	for _, msg := range p.order {
+		schema, err := p.resolver.schema(msg)
+		if err != nil {
+			return nil, err
+		}
+		schemas = append(schemas, schema)
+	}
+	return schemas, nil
+}
+
+type fbsParser struct {
+	s        *idlScanner
+	resolver idlResolver
+	order    []*idlMessage
+	unions   map[string]bool
+}
+
+func (p *fbsParser) parseFile() error {
+	for {
+		token, err := p.s.next()
+		if err != nil {
+			return err
+		}
+		switch token {
+		case "":
+			return p.checkUnions()
+		case ";":
+		case "include", "attribute", "root_type", "file_identifier", "file_extension":
+			err = p.s.skipStatement()
+		case "namespace":
+			var namespace string
+			if namespace, err = p.s.ident(); err == nil && p.resolver.pkg == "" {
+				p.resolver.pkg = namespace
+			}
+			if err == nil {
+				err = p.s.expect(";")
+			}
+		case "table", "struct":
+			err = p.parseTable()
+		case "enum":
+			err = p.parseEnum()
+		case "union":
+			var name string
+			if name, err = p.s.ident(); err == nil {
+				if p.unions == nil {
+					p.unions = map[string]bool{}
+				}
+				p.unions[name] = true
+				err = p.s.skipBlock()
+			}
+		case "rpc_service":
+			if _, err = p.s.ident(); err == nil {
+				err = p.s.skipBlock()
+			}
+		default:
+			return p.s.errorf("unexpected " + quoteToken(token))
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (p *fbsParser) parseTable() error {
+	name, err := p.s.ident()
+	if err != nil {
+		return err
+	}
+	msg := &idlMessage{name: name}
+	p.resolver.messages[name] = msg
+	p.order = append(p.order, msg)
+	if _, _, err := p.parseAttributes(); err != nil {
+		return err
+	}
+	if err := p.s.expect("{"); err != nil {
+		return err
+	}
+	allIDs := true
+	for {
+		token, err := p.s.next()
+		if err != nil {
+			return err
+		}
+		if token == "}" {
+			break
+		}
+		f := idlField{name: token, number: len(msg.fields)}
+		if err := p.s.expect(":"); err != nil {
+			return err
+		}
+		if err := p.parseType(&f); err != nil {
+			return err
+		}
+		if next, err := p.s.peek(); err == nil && next == "=" {
+			p.s.next()
+			if _, err := p.s.next(); err != nil {
+				return err
+			}
+		}
+		id, deprecated, err := p.parseAttributes()
+		if err != nil {
+			return err
+		}
+		if err := p.s.expect(";"); err != nil {
+			return err
+		}
+		if id < 0 {
+			allIDs = false
+		} else {
+			f.number = id
+		}
+		if !deprecated {
+			msg.fields = append(msg.fields, f)
+		}
+	}
+	if allIDs {
+		sort.SliceStable(msg.fields, func(i, j int) bool { return msg.fields[i].number < msg.fields[j].number })
+	}
+	return nil
+}
+
+// Parse 'T', '[T]' or '[T:N]' into f
+func (p *fbsParser) parseType(f *idlField) (err error) {
+	token, err := p.s.next()
+	if err != nil {
+		return err
+	}
+	if token != "[" {
+		f.typ = token
+		return nil
+	}
+	if f.typ, err = p.s.ident(); err != nil {
+		return err
+	}
+	if next, _ := p.s.peek(); next == ":" {
+		return p.s.errorf("fixed length arrays are not supported")
+	}
+	if f.typ == "ubyte" || f.typ == "uint8" {
+		f.typ = "[ubyte]"
+	} else {
+		f.repeated = true
+	}
+	return p.s.expect("]")
+}
+
+// Parse an optional '(name: value, ...)' attribute list, returning the id attribute (-1 if missing)
+// and whether the deprecated attribute was present
+func (p *fbsParser) parseAttributes() (id int, deprecated bool, err error) {
+	id = -1
+	if next, err := p.s.peek(); err != nil || next != "(" {
+		return id, false, err
+	}
+	p.s.next()
+	for {
+		token, err := p.s.next()
+		switch {
+		case err != nil:
+			return id, deprecated, err
+		case token == "":
+			return id, deprecated, p.s.errorf("unexpected end of file")
+		case token == ")":
+			return id, deprecated, nil
+		case token == "deprecated":
+			deprecated = true
+		case token == "id":
+			if err := p.s.expect(":"); err != nil {
+				return id, deprecated, err
+			}
+			value, err := p.s.next()
+			if err != nil {
+				return id, deprecated, err
+			}
+			if id, err = strconv.Atoi(value); err != nil {
+				return id, deprecated, p.s.errorf("invalid id " + quoteToken(value))
+			}
+		}
+	}
+}
+
+func (p *fbsParser) parseEnum() error {
+	name, err := p.s.ident()
+	if err != nil {
+		return err
+	}
+	if err := p.s.expect(":"); err != nil {
+		return err
+	}
+	underlying, err := p.s.ident()
+	if err != nil {
+		return err
+	}
+	kind, ok := fbsScalars[underlying]
+	if !ok || kind == lite.KindString || kind == lite.KindF32 || kind == lite.KindF64 {
+		return p.s.errorf("enum " + name + " has invalid type " + underlying)
+	}
+	p.resolver.enums[name] = kind
+	if _, _, err := p.parseAttributes(); err != nil {
+		return err
+	}
+	return p.s.skipBlock()
+}
+
+// Fail if any field has a union type, since crates have no way to say which member was written
+func (p *fbsParser) checkUnions() error {
+	for _, msg := range p.order {
+		for _, f := range msg.fields {
+			if p.unions[f.typ] {
+				return errors.New("cratectl: field " + f.name + " of " + msg.name + " is a union, which is not supported")
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+//go:build !litecrate_core && !litecrate_notools
+
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+const testFBS = `
+include "common.fbs";
+namespace game.v1;
+
+attribute "priority";
+
+enum Color : ubyte (bit_flags) { Red, Green = 2, Blue }
+
+struct Vec3 { x: float; y: float; z: float; }
+
+// Fields with ids are written in id order
+table Monster (priority: 1) {
+  mana: short = 150 (id: 1);
+  pos: Vec3 (id: 0);
+  hp: short = 100 (id: 2, deprecated);
+  name: string (id: 3);
+  inventory: [ubyte] (id: 4);
+  color: Color = Blue (id: 5);
+  path: [game.v1.Vec3] (id: 6);
+}
+
+root_type Monster;
+`
+
+func TestParseFBS(t *testing.T) {
+	schemas, err := ParseFBS("game.fbs", testFBS)
+	if err != nil {
+		t.Fatalf("ParseFBS() - FAIL: %v", err)
+	}
+	if len(schemas) != 2 || schemas[0].Name != "Vec3" || schemas[1].Name != "Monster" {
+		t.Fatalf("ParseFBS() - FAIL: %d schemas", len(schemas))
+	}
+	vec3 := []lite.Field{{Name: "x", Kind: lite.KindF32}, {Name: "y", Kind: lite.KindF32}, {Name: "z", Kind: lite.KindF32}}
+	expected := []lite.Field{
+		{Name: "pos", Kind: lite.KindStruct, Fields: vec3},
+		{Name: "mana", Kind: lite.KindI16},
+		{Name: "name", Kind: lite.KindString},
+		{Name: "inventory", Kind: lite.KindBytes},
+		{Name: "color", Kind: lite.KindU8},
+		{Name: "path", Kind: lite.KindSlice, Elem: &lite.Field{Name: "path", Kind: lite.KindStruct, Fields: vec3}},
+	}
+	got, _ := json.Marshal(schemas[1].Fields)
+	want, _ := json.Marshal(expected)
+	if string(got) != string(want) {
+		t.Errorf("ParseFBS() - FAIL:\n%s\n%s", got, want)
+	}
+
+	for _, bad := range []struct{ src, err string }{
+		{`union Any { A } table A { x: int; } table B { any: Any; }`, "union, which is not supported"},
+		{`struct A { xs: [int:3]; }`, "fixed length arrays are not supported"},
+		{`enum E : string { A }`, "enum E has invalid type string"},
+		{`table A { b: B; }`, "unknown type B"},
+		{`table A { x int; }`, "game.fbs:1: expected \":\""},
+	} {
+		if _, err := ParseFBS("game.fbs", bad.src); err == nil || !strings.Contains(err.Error(), bad.err) {
+			t.Errorf("ParseFBS(%s) - FAIL: %v", bad.src, err)
+		}
+	}
+}
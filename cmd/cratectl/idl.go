@@ -0,0 +1,288 @@
+//go:build !litecrate_core && !litecrate_notools
+
+package main
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+// Splits .proto and .fbs sources into identifiers (including dotted names), numbers,
+// quoted strings and single punctuation characters, dropping comments
+type idlScanner struct {
+	src  string
+	pos  int
+	line int
+	file string
+}
+
+func newIDLScanner(file string, src string) *idlScanner {
+	return &idlScanner{src: src, line: 1, file: file}
+}
+
+// Returns the next token, "" at the end of the source
+func (s *idlScanner) next() (string, error) {
+	if err := s.skipSpace(); err != nil {
+		return "", err
+	}
+	if s.pos >= len(s.src) {
+		return "", nil
+	}
+	start := s.pos
+	switch c := s.src[s.pos]; {
+	case c == '"' || c == '\'':
+		for s.pos += 1; s.pos < len(s.src) && s.src[s.pos] != c; s.pos += 1 {
+			if s.src[s.pos] == '\\' {
+				s.pos += 1
+			}
+		}
+		if s.pos >= len(s.src) {
+			return "", s.errorf("unterminated string")
+		}
+		s.pos += 1
+	case isIdentByte(c) || c == '-' || c == '+':
+		for s.pos += 1; s.pos < len(s.src) && isIdentByte(s.src[s.pos]); s.pos += 1 {
+		}
+	default:
+		s.pos += 1
+	}
+	return s.src[start:s.pos], nil
+}
+
+// Returns the next token without consuming it
+func (s *idlScanner) peek() (string, error) {
+	pos, line := s.pos, s.line
+	token, err := s.next()
+	s.pos, s.line = pos, line
+	return token, err
+}
+
+// Consumes the next token, which must be want
+func (s *idlScanner) expect(want string) error {
+	token, err := s.next()
+	if err != nil {
+		return err
+	}
+	if token != want {
+		return s.errorf("expected " + strconv.Quote(want) + ", found " + quoteToken(token))
+	}
+	return nil
+}
+
+// Consumes the next token, which must be an identifier
+func (s *idlScanner) ident() (string, error) {
+	token, err := s.next()
+	if err != nil {
+		return "", err
+	}
+	if token == "" || !isIdentByte(token[0]) || unicode.IsDigit(rune(token[0])) {
+		return "", s.errorf("expected a name, found " + quoteToken(token))
+	}
+	return token, nil
+}
+
+// Consumes tokens up to and including the next ';' that is not inside brackets
+func (s *idlScanner) skipStatement() error {
+	depth := 0
+	for {
+		token, err := s.next()
+		switch {
+		case err != nil:
+			return err
+		case token == "":
+			return s.errorf("unexpected end of file")
+		case token == "{" || token == "[" || token == "(" || token == "<":
+			depth += 1
+		case token == "}" || token == "]" || token == ")" || token == ">":
+			depth -= 1
+		case token == ";" && depth <= 0:
+			return nil
+		}
+		if depth < 0 {
+			return s.errorf("unbalanced " + token)
+		}
+	}
+}
+
+// Consumes a '{' ... '}' block, including any blocks nested inside it
+func (s *idlScanner) skipBlock() error {
+	if err := s.expect("{"); err != nil {
+		return err
+	}
+	for depth := 1; depth > 0; {
+		token, err := s.next()
+		switch {
+		case err != nil:
+			return err
+		case token == "":
+			return s.errorf("unexpected end of file")
+		case token == "{":
+			depth += 1
+		case token == "}":
+			depth -= 1
+		}
+	}
+	return nil
+}
+
+func (s *idlScanner) skipSpace() error {
+	for s.pos < len(s.src) {
+		switch {
+		case s.src[s.pos] == '\n':
+			s.line += 1
+			s.pos += 1
+		case s.src[s.pos] <= ' ':
+			s.pos += 1
+		case strings.HasPrefix(s.src[s.pos:], "//"):
+			for s.pos < len(s.src) && s.src[s.pos] != '\n' {
+				s.pos += 1
+			}
+		case strings.HasPrefix(s.src[s.pos:], "/*"):
+			end := strings.Index(s.src[s.pos+2:], "*/")
+			if end < 0 {
+				return s.errorf("unterminated comment")
+			}
+			s.line += strings.Count(s.src[s.pos:s.pos+2+end], "\n")
+			s.pos += end + 4
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+func (s *idlScanner) errorf(msg string) error {
+	return errors.New(s.file + ":" + strconv.Itoa(s.line) + ": " + msg)
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '.' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func quoteToken(token string) string {
+	if token == "" {
+		return "end of file"
+	}
+	return strconv.Quote(token)
+}
+
+/**************
+	RESOLVING
+***************/
+
+// A message, table or struct read from an IDL file, before its field types are resolved
+type idlMessage struct {
+	name   string // Full name, nested names joined with '.' and without the package
+	fields []idlField
+}
+
+type idlField struct {
+	name     string
+	number   int
+	typ      string // Scalar type name or the name of another message or enum, as written
+	repeated bool
+	mapKey   string // Key type of a map<K, V> field, whose value type is typ
+}
+
+// Converts the messages read from an IDL file into schemas, inlining message typed fields as
+// KindStruct. scalars maps the IDL's scalar type names to kinds, and enums maps full enum names to
+// the kind of their values
+type idlResolver struct {
+	pkg      string
+	scalars  map[string]lite.FieldKind
+	enums    map[string]lite.FieldKind
+	messages map[string]*idlMessage
+	visiting map[string]bool
+}
+
+func (r *idlResolver) schema(msg *idlMessage) (*lite.Schema, error) {
+	fields, err := r.fields(msg)
+	if err != nil {
+		return nil, err
+	}
+	return &lite.Schema{Name: msg.name, Fields: fields}, nil
+}
+
+func (r *idlResolver) fields(msg *idlMessage) ([]lite.Field, error) {
+	if r.visiting[msg.name] {
+		return nil, errors.New("cratectl: " + msg.name + " contains itself and cannot be written inline")
+	}
+	r.visiting[msg.name] = true
+	defer delete(r.visiting, msg.name)
+	fields := make([]lite.Field, 0, len(msg.fields))
+	for _, f := range msg.fields {
+		elem, err := r.field(f.name, f.typ, msg.name)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case f.mapKey != "":
+			key, err := r.field("key", f.mapKey, msg.name)
+			if err != nil {
+				return nil, err
+			}
+			elem.Name = "value"
+			fields = append(fields, lite.Field{Name: f.name, Kind: lite.KindMap, Key: &key, Elem: &elem})
+		case f.repeated:
+			fields = append(fields, lite.Field{Name: f.name, Kind: lite.KindSlice, Elem: &elem})
+		default:
+			fields = append(fields, elem)
+		}
+	}
+	return fields, nil
+}
+
+func (r *idlResolver) field(name string, typ string, scope string) (lite.Field, error) {
+	if kind, ok := r.scalars[typ]; ok {
+		return lite.Field{Name: name, Kind: kind}, nil
+	}
+	full, ok := r.lookup(typ, scope)
+	if !ok {
+		return lite.Field{}, errors.New("cratectl: unknown type " + typ + " in " + scope)
+	}
+	if kind, ok := r.enums[full]; ok {
+		return lite.Field{Name: name, Kind: kind}, nil
+	}
+	fields, err := r.fields(r.messages[full])
+	if err != nil {
+		return lite.Field{}, err
+	}
+	return lite.Field{Name: name, Kind: lite.KindStruct, Fields: fields}, nil
+}
+
+// Finds the full name typ refers to from inside scope, searching the innermost scope first
+func (r *idlResolver) lookup(typ string, scope string) (string, bool) {
+	if strings.HasPrefix(typ, ".") {
+		typ = strings.TrimPrefix(typ[1:], r.pkg+".")
+		return typ, r.exists(typ)
+	}
+	if r.pkg != "" && strings.HasPrefix(typ, r.pkg+".") && r.exists(typ[len(r.pkg)+1:]) {
+		return typ[len(r.pkg)+1:], true
+	}
+	for {
+		if full := joinName(scope, typ); r.exists(full) {
+			return full, true
+		}
+		if scope == "" {
+			return "", false
+		}
+		scope = scope[:strings.LastIndexByte(scope, '.')+1]
+		scope = strings.TrimSuffix(scope, ".")
+	}
+}
+
+func (r *idlResolver) exists(name string) bool {
+	_, isEnum := r.enums[name]
+	return isEnum || r.messages[name] != nil
+}
+
+func joinName(scope string, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
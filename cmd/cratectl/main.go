@@ -0,0 +1,152 @@
+//go:build !litecrate_core && !litecrate_notools
+
+// Command cratectl works with LiteCrate schemas.
+//
+//	cratectl doc [-html] schema...
+//
+// writes Markdown (or with -html, HTML) documentation of each schema's wire layout to stdout,
+// so protocol documentation can be regenerated whenever the schemas change (see litecrate.GenerateDocs).
+//
+//	cratectl import [-o dir] file.proto|file.fbs...
+//
+// converts every message in protobuf files, and every table and struct in FlatBuffers files,
+// into a schema file named after it in dir (default "."), so teams with existing IDL files
+// can start from them (see ParseProto and ParseFBS for how types map to kinds).
+//
+// A schema file holds one litecrate.Schema as JSON, with kinds written by name:
+//
+//	{"Name": "Point", "Fields": [{"Name": "x", "Kind": "I32"}, {"Name": "y", "Kind": "I32"}]}
+//
+// doc also accepts .proto and .fbs files directly, documenting every schema they convert to.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+const usage = "usage: cratectl doc [-html] schema...\n       cratectl import [-o dir] file.proto|file.fbs..."
+
+func main() {
+	if len(os.Args) < 2 {
+		fail(errors.New(usage))
+	}
+	var err error
+	switch os.Args[1] {
+	case "doc":
+		err = doc(os.Args[2:], os.Stdout)
+	case "import":
+		err = importIDL(os.Args[2:])
+	default:
+		err = errors.New(usage)
+	}
+	if err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
+
+// Write the documentation of every schema file named in args to w
+func doc(args []string, w io.Writer) error {
+	flags := flag.NewFlagSet("doc", flag.ContinueOnError)
+	asHTML := flags.Bool("html", false, "write an HTML fragment instead of Markdown")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return errors.New(usage)
+	}
+	format := lite.DocMarkdown
+	if *asHTML {
+		format = lite.DocHTML
+	}
+	first := true
+	for _, path := range flags.Args() {
+		schemas, err := LoadSchemas(path)
+		if err != nil {
+			return err
+		}
+		for _, schema := range schemas {
+			if !first && format == lite.DocMarkdown {
+				io.WriteString(w, "\n")
+			}
+			first = false
+			if _, err := w.Write(lite.GenerateDocs(schema, format)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Convert every IDL file named in args into schema files
+func importIDL(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	dir := flags.String("o", ".", "directory to write the schema files to")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return errors.New(usage)
+	}
+	for _, path := range flags.Args() {
+		schemas, err := LoadSchemas(path)
+		if err != nil {
+			return err
+		}
+		for _, schema := range schemas {
+			data, err := json.MarshalIndent(schema, "", "\t")
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(filepath.Join(*dir, schema.Name+".json"), append(data, '\n'), 0o644); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Read the schemas in a .proto file, a .fbs file, or a JSON schema file (any other extension)
+func LoadSchemas(path string) ([]*lite.Schema, error) {
+	switch filepath.Ext(path) {
+	case ".proto", ".fbs":
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if filepath.Ext(path) == ".proto" {
+			return ParseProto(path, string(src))
+		}
+		return ParseFBS(path, string(src))
+	}
+	schema, err := LoadSchema(path)
+	if err != nil {
+		return nil, err
+	}
+	return []*lite.Schema{schema}, nil
+}
+
+// Read a schema from a JSON file
+func LoadSchema(path string) (*lite.Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var schema lite.Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &schema, nil
+}
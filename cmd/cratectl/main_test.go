@@ -0,0 +1,67 @@
+//go:build !litecrate_core && !litecrate_notools
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDoc(t *testing.T) {
+	dir := t.TempDir()
+	point := filepath.Join(dir, "point.json")
+	os.WriteFile(point, []byte(`{"Name": "Point", "Fields": [{"Name": "x", "Kind": "I32"}, {"Name": "y", "Kind": "I32"}]}`), 0o644)
+	named := filepath.Join(dir, "named.json")
+	os.WriteFile(named, []byte(`{"Name": "Named", "Fields": [{"Name": "name", "Kind": "String"}]}`), 0o644)
+
+	var out bytes.Buffer
+	if err := doc([]string{point, named}, &out); err != nil {
+		t.Fatalf("doc() - FAIL: %v", err)
+	}
+	if !strings.Contains(out.String(), "| 4 | `y` | I32 | 4 |") || !strings.Contains(out.String(), "\n\n## Named\n") {
+		t.Errorf("doc() - FAIL:\n%s", out.String())
+	}
+	out.Reset()
+	if err := doc([]string{"-html", point}, &out); err != nil || !strings.HasPrefix(out.String(), "<h2>Point</h2>") {
+		t.Errorf("doc(-html) - FAIL: %v\n%s", err, out.String())
+	}
+
+	bad := filepath.Join(dir, "bad.json")
+	os.WriteFile(bad, []byte(`{"Name": "Bad", "Fields": [{"Name": "x", "Kind": "I12"}]}`), 0o644)
+	for _, args := range [][]string{nil, {bad}, {filepath.Join(dir, "missing.json")}} {
+		if err := doc(args, &out); err == nil {
+			t.Errorf("doc(%v) - FAIL: did not error", args)
+		}
+	}
+
+	os.WriteFile(filepath.Join(dir, "shop.proto"), []byte(testProto), 0o644)
+	out.Reset()
+	if err := doc([]string{filepath.Join(dir, "shop.proto")}, &out); err != nil || !strings.Contains(out.String(), "## Order.Line\n") {
+		t.Errorf("doc(.proto) - FAIL: %v\n%s", err, out.String())
+	}
+}
+
+func TestImport(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "shop.proto"), []byte(testProto), 0o644)
+	os.WriteFile(filepath.Join(dir, "game.fbs"), []byte(testFBS), 0o644)
+	if err := importIDL([]string{"-o", dir, filepath.Join(dir, "shop.proto"), filepath.Join(dir, "game.fbs")}); err != nil {
+		t.Fatalf("importIDL() - FAIL: %v", err)
+	}
+	for _, name := range []string{"Order", "Order.Line", "Owner", "Vec3", "Monster"} {
+		schema, err := LoadSchema(filepath.Join(dir, name+".json"))
+		if err != nil || schema.Name != name {
+			t.Errorf("importIDL() - FAIL: %s.json: %v", name, err)
+		}
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "Vec3.json"))
+	if !strings.Contains(string(data), `"Kind": "F32"`) || strings.Contains(string(data), "null") {
+		t.Errorf("importIDL() - FAIL: Vec3.json:\n%s", data)
+	}
+	if err := importIDL([]string{"-o", dir}); err == nil {
+		t.Errorf("importIDL() - FAIL: no files did not error")
+	}
+}
@@ -0,0 +1,227 @@
+//go:build !litecrate_core && !litecrate_notools
+
+package main
+
+import (
+	"sort"
+	"strconv"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+// Kinds used for protobuf scalar types. Types protobuf writes as varints stay varints
+// (zig-zag for the signed ones), fixed width types keep their width
+var protoScalars = map[string]lite.FieldKind{
+	"double":   lite.KindF64,
+	"float":    lite.KindF32,
+	"int32":    lite.KindVarint,
+	"int64":    lite.KindVarint,
+	"sint32":   lite.KindVarint,
+	"sint64":   lite.KindVarint,
+	"uint32":   lite.KindUVarint,
+	"uint64":   lite.KindUVarint,
+	"fixed32":  lite.KindU32,
+	"fixed64":  lite.KindU64,
+	"sfixed32": lite.KindI32,
+	"sfixed64": lite.KindI64,
+	"bool":     lite.KindBool,
+	"string":   lite.KindString,
+	"bytes":    lite.KindBytes,
+}
+
+/**************
+	PROTO
+***************/
+
+// Read the messages of a proto2 or proto3 file as schemas, one per message (nested messages
+// included, named "Outer.Inner"), with fields in field number order.
+//
+// Enums become KindVarint, repeated fields KindSlice, map<K, V> fields KindMap and message
+// typed fields KindStruct, with the message's fields written inline. Fields inside a oneof are
+// written like any other field. Messages that contain themselves, groups and extensions are not supported
+func ParseProto(file string, src string) ([]*lite.Schema, error) {
+	p := &protoParser{
+		s:        newIDLScanner(file, src),
+		resolver: idlResolver{scalars: protoScalars, enums: map[string]lite.FieldKind{}, messages: map[string]*idlMessage{}, visiting: map[string]bool{}},
+	}
+	if err := p.parseFile(); err != nil {
+		return nil, err
+	}
+	for _, msg := range p.order {
+		sort.SliceStable(msg.fields, func(i, j int) bool { return msg.fields[i].number < msg.fields[j].number })
+	}
+	schemas := make([]*lite.Schema, 0, len(p.order))
+	for _, msg := range p.order {
+		schema, err := p.resolver.schema(msg)
+		if err != nil {
+			return nil, err
+		}
+		schemas = append(schemas, schema)
+	}
+	return schemas, nil
+}
+
+type protoParser struct {
+	s        *idlScanner
+	resolver idlResolver
+	order    []*idlMessage
+}
+
+func (p *protoParser) parseFile() error {
+	for {
+		token, err := p.s.next()
+		if err != nil {
+			return err
+		}
+		switch token {
+		case "":
+			return nil
+		case ";":
+		case "syntax", "edition", "import", "option":
+			err = p.s.skipStatement()
+		case "package":
+			if p.resolver.pkg, err = p.s.ident(); err == nil {
+				err = p.s.expect(";")
+			}
+		case "message":
+			err = p.parseMessage("")
+		case "enum":
+			err = p.parseEnum("")
+		case "service", "extend":
+			if _, err = p.s.ident(); err == nil {
+				err = p.s.skipBlock()
+			}
+		default:
+			return p.s.errorf("unexpected " + quoteToken(token))
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (p *protoParser) parseMessage(scope string) error {
+	name, err := p.s.ident()
+	if err != nil {
+		return err
+	}
+	msg := &idlMessage{name: joinName(scope, name)}
+	p.resolver.messages[msg.name] = msg
+	p.order = append(p.order, msg)
+	if err := p.s.expect("{"); err != nil {
+		return err
+	}
+	return p.parseBody(msg)
+}
+
+// Parse the fields and nested definitions of msg up to its closing '}' (or a oneof's)
+func (p *protoParser) parseBody(msg *idlMessage) error {
+	for {
+		token, err := p.s.next()
+		if err != nil {
+			return err
+		}
+		switch token {
+		case "":
+			return p.s.errorf("unexpected end of file in " + msg.name)
+		case "}":
+			return nil
+		case ";":
+		case "option", "reserved", "extensions":
+			err = p.s.skipStatement()
+		case "message":
+			err = p.parseMessage(msg.name)
+		case "enum":
+			err = p.parseEnum(msg.name)
+		case "extend":
+			if _, err = p.s.ident(); err == nil {
+				err = p.s.skipBlock()
+			}
+		case "oneof":
+			if _, err = p.s.ident(); err == nil {
+				if err = p.s.expect("{"); err == nil {
+					err = p.parseBody(msg)
+				}
+			}
+		case "group":
+			return p.s.errorf("groups are not supported")
+		case "map":
+			err = p.parseMapField(msg)
+		default:
+			err = p.parseField(msg, token)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Parse '[label] type name = number [options];' where token is the label or type
+func (p *protoParser) parseField(msg *idlMessage, token string) error {
+	var f idlField
+	switch token {
+	case "repeated":
+		f.repeated = true
+		fallthrough
+	case "optional", "required":
+		var err error
+		if token, err = p.s.ident(); err != nil {
+			return err
+		}
+	}
+	if token == "group" {
+		return p.s.errorf("groups are not supported")
+	}
+	f.typ = token
+	return p.parseFieldEnd(msg, f)
+}
+
+// Parse 'map<K, V> name = number [options];' after 'map'
+func (p *protoParser) parseMapField(msg *idlMessage) error {
+	var f idlField
+	var err error
+	if err = p.s.expect("<"); err != nil {
+		return err
+	}
+	if f.mapKey, err = p.s.ident(); err != nil {
+		return err
+	}
+	if err = p.s.expect(","); err != nil {
+		return err
+	}
+	if f.typ, err = p.s.ident(); err != nil {
+		return err
+	}
+	if err = p.s.expect(">"); err != nil {
+		return err
+	}
+	return p.parseFieldEnd(msg, f)
+}
+
+func (p *protoParser) parseFieldEnd(msg *idlMessage, f idlField) error {
+	var err error
+	if f.name, err = p.s.ident(); err != nil {
+		return err
+	}
+	if err = p.s.expect("="); err != nil {
+		return err
+	}
+	number, err := p.s.next()
+	if err != nil {
+		return err
+	}
+	if f.number, err = strconv.Atoi(number); err != nil {
+		return p.s.errorf("field " + f.name + " has invalid number " + quoteToken(number))
+	}
+	msg.fields = append(msg.fields, f)
+	return p.s.skipStatement()
+}
+
+func (p *protoParser) parseEnum(scope string) error {
+	name, err := p.s.ident()
+	if err != nil {
+		return err
+	}
+	p.resolver.enums[joinName(scope, name)] = lite.KindVarint
+	return p.s.skipBlock()
+}
@@ -0,0 +1,90 @@
+//go:build !litecrate_core && !litecrate_notools
+
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+const testProto = `
+syntax = "proto3";
+package shop.v1;
+
+import "google/protobuf/timestamp.proto";
+option go_package = "example.com/shop";
+
+/* An order, with its
+   lines and owner */
+message Order {
+  reserved 4, 8 to 10;
+  uint64 id = 1;
+  sint32 delta = 3 [deprecated = true];
+  Status status = 2;
+  repeated Line lines = 5;
+  map<string, int64> stock = 6;
+  oneof payment {
+    string card = 7;
+    .shop.v1.Owner owner = 11;
+  }
+  message Line {
+    fixed32 sku = 1;
+    double price = 2;
+    bytes note = 3;
+  }
+  enum Status { UNKNOWN = 0; PAID = 1; }
+}
+
+message Owner { string name = 1; optional bool admin = 2; }
+
+service Shop { rpc Get(Order) returns (Order); }
+`
+
+func TestParseProto(t *testing.T) {
+	schemas, err := ParseProto("shop.proto", testProto)
+	if err != nil {
+		t.Fatalf("ParseProto() - FAIL: %v", err)
+	}
+	if len(schemas) != 3 || schemas[0].Name != "Order" || schemas[1].Name != "Order.Line" || schemas[2].Name != "Owner" {
+		t.Fatalf("ParseProto() - FAIL: %d schemas", len(schemas))
+	}
+	line := lite.Field{Name: "lines", Kind: lite.KindStruct, Fields: []lite.Field{
+		{Name: "sku", Kind: lite.KindU32},
+		{Name: "price", Kind: lite.KindF64},
+		{Name: "note", Kind: lite.KindBytes},
+	}}
+	expected := []lite.Field{
+		{Name: "id", Kind: lite.KindUVarint},
+		{Name: "status", Kind: lite.KindVarint},
+		{Name: "delta", Kind: lite.KindVarint},
+		{Name: "lines", Kind: lite.KindSlice, Elem: &line},
+		{Name: "stock", Kind: lite.KindMap, Key: &lite.Field{Name: "key", Kind: lite.KindString}, Elem: &lite.Field{Name: "value", Kind: lite.KindVarint}},
+		{Name: "card", Kind: lite.KindString},
+		{Name: "owner", Kind: lite.KindStruct, Fields: []lite.Field{
+			{Name: "name", Kind: lite.KindString},
+			{Name: "admin", Kind: lite.KindBool},
+		}},
+	}
+	got, _ := json.Marshal(schemas[0].Fields)
+	want, _ := json.Marshal(expected)
+	if string(got) != string(want) {
+		t.Errorf("ParseProto() - FAIL:\n%s\n%s", got, want)
+	}
+
+	for _, bad := range []struct{ src, err string }{
+		{`message A { B b = 1; }`, "unknown type B"},
+		{`message A { A next = 1; }`, "contains itself"},
+		{`message A { repeated group G = 1 {} }`, "groups are not supported"},
+		{`message A { int32 a = x; }`, "shop.proto:1: field a has invalid number"},
+		{"message A {\n int32 a = 1;", "shop.proto:2: unexpected end of file"},
+		{`message A { /* int32 a = 1; }`, "unterminated comment"},
+		{`int32 a = 1;`, "unexpected \"int32\""},
+	} {
+		if _, err := ParseProto("shop.proto", bad.src); err == nil || !strings.Contains(err.Error(), bad.err) {
+			t.Errorf("ParseProto(%s) - FAIL: %v", bad.src, err)
+		}
+	}
+}
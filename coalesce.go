@@ -0,0 +1,121 @@
+package litecrate
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultCoalesceSize  = 1400             // Batch size used when Coalescer.MaxSize is 0, fits one Ethernet packet after IP and TCP/UDP headers
+	DefaultCoalesceDelay = time.Millisecond // Delay used when Coalescer.MaxDelay is 0
+)
+
+/**************
+	COALESCER
+***************/
+
+// A Coalescer sends crates over Conn as frames in the same format as Framer.WriteFrame()
+// (so they are read with Framer.ReadFrame()), but collects small frames into batches that are written
+// with a single Conn.Write(), reducing syscall and packet header overhead for chatty protocols.
+//
+// A batch is written when the next frame would take it past MaxSize, or MaxDelay after its first
+// frame was added, whichever comes first. Frames larger than MaxSize are written on their own.
+// Set Conn before the first call. All methods are safe to call from multiple goroutines
+type Coalescer struct {
+	Conn     io.Writer     // Where batches are written
+	MaxSize  int           // Largest batch in bytes (including frame headers), 0 = DefaultCoalesceSize
+	MaxDelay time.Duration // Longest a frame waits for others to join its batch, 0 = DefaultCoalesceDelay
+	mutex    sync.Mutex
+	batch    []byte
+	timer    *time.Timer
+	batchID  uint64
+	err      error
+	stats    CoalescerStats
+}
+
+// Counters describing how well a Coalescer is batching frames
+type CoalescerStats struct {
+	Frames uint64 // Total frames passed to WriteFrame()
+	Writes uint64 // Calls made to Conn.Write()
+}
+
+// Add the crate's written data to the current batch as one frame.
+// Returns any error from writing an earlier batch, after which the Coalescer stops writing,
+// or the crate's error without adding it if it failed under FlagNoPanic
+func (w *Coalescer) WriteFrame(crate *Crate) error {
+	if err := crate.Err(); err != nil {
+		return err
+	}
+	var header [9]byte
+	headerCrate := Crate{data: header[:], flags: FlagStatic}
+	headerCrate.WriteUVarint(crate.write)
+	size := int(headerCrate.write + crate.write)
+	maxSize := w.MaxSize
+	if maxSize == 0 {
+		maxSize = DefaultCoalesceSize
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.err != nil {
+		return w.err
+	}
+	w.stats.Frames += 1
+	if len(w.batch) > 0 && len(w.batch)+size > maxSize {
+		w.flush()
+	}
+	w.batch = append(w.batch, headerCrate.Data()...)
+	w.batch = append(w.batch, crate.Data()...)
+	if len(w.batch) >= maxSize {
+		w.flush()
+	} else if w.timer == nil {
+		delay := w.MaxDelay
+		if delay == 0 {
+			delay = DefaultCoalesceDelay
+		}
+		batchID := w.batchID
+		w.timer = time.AfterFunc(delay, func() { w.timerFlush(batchID) })
+	}
+	return w.err
+}
+
+// Write the current batch now, without waiting for MaxDelay
+func (w *Coalescer) Flush() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.err == nil {
+		w.flush()
+	}
+	return w.err
+}
+
+// Returns a snapshot of the Coalescer's counters
+func (w *Coalescer) Stats() CoalescerStats {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.stats
+}
+
+// Flushes the batch the timer was started for, unless it has already been written
+func (w *Coalescer) timerFlush(batchID uint64) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if batchID == w.batchID && w.err == nil {
+		w.flush()
+	}
+}
+
+// Writes the batch, must be called with mutex held
+func (w *Coalescer) flush() {
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	if len(w.batch) == 0 {
+		return
+	}
+	w.batchID += 1
+	w.stats.Writes += 1
+	_, w.err = w.Conn.Write(w.batch)
+	w.batch = w.batch[:0]
+}
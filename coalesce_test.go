@@ -0,0 +1,119 @@
+package litecrate_test
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+// Records each Write() call separately
+type coalesceConn struct {
+	mutex  sync.Mutex
+	writes [][]byte
+	err    error
+}
+
+func (c *coalesceConn) Write(p []byte) (int, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.writes = append(c.writes, append([]byte(nil), p...))
+	return len(p), c.err
+}
+
+func (c *coalesceConn) stream() *bytes.Buffer {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return bytes.NewBuffer(bytes.Join(c.writes, nil))
+}
+
+func TestCoalescer(t *testing.T) {
+	conn := &coalesceConn{}
+	w := lite.Coalescer{Conn: conn, MaxSize: 100, MaxDelay: time.Hour}
+	sizes := []int{10, 20, 30, 35, 1, 250, 5}
+	for _, size := range sizes {
+		crate := lite.NewCrate(8, lite.FlagAutoDouble)
+		crate.WriteBytes(bytes.Repeat([]byte{byte(size)}, size))
+		if err := w.WriteFrame(crate); err != nil {
+			t.Fatalf("Coalescer.WriteFrame() - FAIL: %v", err)
+		}
+	}
+	// 11+21+31+36 = 99 bytes, then 2 bytes, then the 252 byte frame alone, then 6 bytes still waiting
+	if len(conn.writes) != 3 || len(conn.writes[0]) != 99 || len(conn.writes[1]) != 2 || len(conn.writes[2]) != 252 {
+		t.Errorf("Coalescer.WriteFrame() - FAIL: %d writes", len(conn.writes))
+	}
+	w.Flush()
+	if stats := w.Stats(); stats.Frames != 7 || stats.Writes != 4 {
+		t.Errorf("Coalescer.Stats() - FAIL: %+v", stats)
+	}
+	var framer lite.Framer
+	stream := conn.stream()
+	for _, size := range sizes {
+		crate, err := framer.ReadFrame(stream)
+		if err != nil || !bytes.Equal(crate.Data(), bytes.Repeat([]byte{byte(size)}, size)) {
+			t.Errorf("Framer.ReadFrame() - FAIL: coalesced frame of %d bytes: %v", size, err)
+		}
+	}
+
+	timed := lite.Coalescer{Conn: conn, MaxDelay: 5 * time.Millisecond}
+	crate := lite.NewCrate(8, lite.FlagAutoDouble)
+	crate.WriteU64(42)
+	timed.WriteFrame(crate)
+	timed.WriteFrame(crate)
+	deadline := time.Now().Add(5 * time.Second)
+	for timed.Stats().Writes == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if stats := timed.Stats(); stats.Writes != 1 || stats.Frames != 2 {
+		t.Errorf("Coalescer(MaxDelay) - FAIL: %+v", stats)
+	}
+
+	failing := lite.Coalescer{Conn: &coalesceConn{err: errors.New("broken")}, MaxSize: 9}
+	if err := failing.WriteFrame(crate); err == nil {
+		t.Errorf("Coalescer.WriteFrame() - FAIL: write error not returned")
+	}
+	if err := failing.WriteFrame(crate); err == nil || failing.Stats().Writes != 1 {
+		t.Errorf("Coalescer.WriteFrame() - FAIL: wrote again after error")
+	}
+}
+
+func TestCoalescerConcurrent(t *testing.T) {
+	conn := &coalesceConn{}
+	w := lite.Coalescer{Conn: conn, MaxDelay: 100 * time.Microsecond}
+	const writers, messages = 4, 200
+	var wg sync.WaitGroup
+	for g := 0; g < writers; g += 1 {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < messages; i += 1 {
+				crate := lite.NewCrate(8, lite.FlagAutoDouble)
+				crate.WriteU16(uint16(g))
+				crate.WriteU16(uint16(i))
+				w.WriteFrame(crate)
+			}
+		}(g)
+	}
+	wg.Wait()
+	w.Flush()
+	var framer lite.Framer
+	stream := conn.stream()
+	next := make([]uint16, writers)
+	for i := 0; i < writers*messages; i += 1 {
+		crate, err := framer.ReadFrame(stream)
+		if err != nil {
+			t.Fatalf("Coalescer - FAIL: frame %d: %v", i, err)
+		}
+		g, n := crate.ReadU16(), crate.ReadU16()
+		if n != next[g] {
+			t.Fatalf("Coalescer - FAIL: writer %d sent %d, expected %d", g, n, next[g])
+		}
+		next[g] += 1
+	}
+	if stats := w.Stats(); stats.Writes >= writers*messages {
+		t.Errorf("Coalescer - FAIL: %d frames took %d writes", stats.Frames, stats.Writes)
+	}
+}
@@ -0,0 +1,46 @@
+package litecrate
+
+// Adapts an ordinary function (or a method value such as header.UseSelf) to the SelfSerializer interface
+type SelfSerializerFunc func(crate *Crate, mode UseMode)
+
+func (f SelfSerializerFunc) UseSelf(crate *Crate, mode UseMode) {
+	f(crate, mode)
+}
+
+/**************
+	COMPOSE
+***************/
+
+// Returns a SelfSerializer that uses each part in order with the same crate and mode,
+// so that shared groups of fields (audit headers, pagination envelopes...) are defined once
+// as a SelfSerializer and reused by every message that embeds them.
+//
+// Example:
+//
+//	type Audit struct {
+//		User string
+//		Time time.Time
+//	}
+//
+//	func (a *Audit) UseSelf(crate *Crate, mode UseMode) {
+//		crate.UseStringWithCounter(&a.User, mode)
+//		crate.UseTime(&a.Time, mode)
+//	}
+//
+//	type DeleteOrder struct {
+//		Audit
+//		OrderID uint64
+//	}
+//
+//	func (d *DeleteOrder) UseSelf(crate *Crate, mode UseMode) {
+//		ComposeSerializers(d.Audit.UseSelf, func(crate *Crate, mode UseMode) {
+//			crate.UseU64(&d.OrderID, mode)
+//		}).UseSelf(crate, mode)
+//	}
+func ComposeSerializers(parts ...func(crate *Crate, mode UseMode)) SelfSerializer {
+	return SelfSerializerFunc(func(crate *Crate, mode UseMode) {
+		for _, part := range parts {
+			part(crate, mode)
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package litecrate_test
+
+import (
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+type composeAudit struct {
+	User string
+	Rev  uint32
+}
+
+func (a *composeAudit) UseSelf(crate *lite.Crate, mode lite.UseMode) {
+	crate.UseStringWithCounter(&a.User, mode)
+	crate.UseU32(&a.Rev, mode)
+}
+
+type composePage struct {
+	Offset uint16
+	Limit  uint16
+}
+
+func (p *composePage) UseSelf(crate *lite.Crate, mode lite.UseMode) {
+	crate.UseU16(&p.Offset, mode)
+	crate.UseU16(&p.Limit, mode)
+}
+
+type composeList struct {
+	composeAudit
+	composePage
+	Items []string
+}
+
+func (l *composeList) UseSelf(crate *lite.Crate, mode lite.UseMode) {
+	lite.ComposeSerializers(l.composeAudit.UseSelf, l.composePage.UseSelf, func(crate *lite.Crate, mode lite.UseMode) {
+		lite.UseSlice(crate, mode, &l.Items, crate.UseStringWithCounter)
+	}).UseSelf(crate, mode)
+}
+
+type composeDelete struct {
+	composeAudit
+	ID uint64
+}
+
+func (d *composeDelete) UseSelf(crate *lite.Crate, mode lite.UseMode) {
+	lite.ComposeSerializers(d.composeAudit.UseSelf, func(crate *lite.Crate, mode lite.UseMode) {
+		crate.UseU64(&d.ID, mode)
+	}).UseSelf(crate, mode)
+}
+
+func TestComposeSerializers(t *testing.T) {
+	crate := lite.NewCrate(8, lite.FlagAutoDouble)
+	list := composeList{composeAudit{"ann", 3}, composePage{10, 20}, []string{"a", "b"}}
+	del := composeDelete{composeAudit{"bob", 4}, 99}
+	crate.WriteSelfSerializer(&list)
+	crate.WriteSelfSerializer(&del)
+	crate.WriteU8(42)
+
+	var peeked, readList composeList
+	var readDel composeDelete
+	crate.PeekSelfSerializer(&peeked)
+	slice := crate.SliceSelfAcecessor(&readList)
+	crate.ReadSelfSerializer(&readList)
+	if peeked.User != "ann" || readList.User != "ann" || readList.Rev != 3 || readList.Limit != 20 || len(readList.Items) != 2 || readList.Items[1] != "b" || uint64(len(slice)) != crate.ReadIndex() {
+		t.Errorf("ComposeSerializers(Read) - FAIL: read %+v, peeked %+v", readList, peeked)
+	}
+	crate.UseSelfSerializer(&readDel, lite.Discard)
+	if crate.ReadU8() != 42 {
+		t.Errorf("ComposeSerializers(Discard) - FAIL: did not skip message")
+	}
+
+	crate.ResetReadIndex()
+	crate.DiscardSelfSerializer(&readList)
+	crate.ReadSelfSerializer(&readDel)
+	if readDel != del {
+		t.Errorf("ComposeSerializers(Read) - FAIL: read %+v, expected %+v", readDel, del)
+	}
+
+	var names []string
+	lite.Visit(&del, lite.VisitorFunc(func(val any, name string) { names = append(names, name) }))
+	if len(names) != 4 || names[1] != "UseStringWithCounter" || names[3] != "UseU64" {
+		t.Errorf("ComposeSerializers(Visit) - FAIL: visited %v", names)
+	}
+}
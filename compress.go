@@ -0,0 +1,159 @@
+//go:build !litecrate_core && !litecrate_nocompress
+
+package litecrate
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+)
+
+// A Compressor compresses and decompresses whole byte slices.
+// Implement it to use compression algorithms other than the ones provided
+type Compressor interface {
+	Compress(src []byte) (compressed []byte, err error)
+	Decompress(src []byte) (decompressed []byte, err error)
+}
+
+var (
+	Gzip   Compressor = GzipCompressor{Level: gzip.DefaultCompression} // gzip (RFC 1952) with default compression level
+	Zlib   Compressor = ZlibCompressor{Level: zlib.DefaultCompression} // zlib (RFC 1950) with default compression level
+	Snappy Compressor = SnappyCompressor{}                             // snappy block format
+)
+
+// Compresses with compress/gzip at the given compression level
+type GzipCompressor struct {
+	Level int
+}
+
+func (g GzipCompressor) Compress(src []byte) (compressed []byte, err error) {
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, g.Level)
+	if err != nil {
+		return nil, err
+	}
+	return finishCompress(&buf, w, src)
+}
+
+func (g GzipCompressor) Decompress(src []byte) (decompressed []byte, err error) {
+	r, err := gzip.NewReader(bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+	return finishDecompress(r)
+}
+
+// Compresses with compress/zlib at the given compression level
+type ZlibCompressor struct {
+	Level int
+}
+
+func (z ZlibCompressor) Compress(src []byte) (compressed []byte, err error) {
+	var buf bytes.Buffer
+	w, err := zlib.NewWriterLevel(&buf, z.Level)
+	if err != nil {
+		return nil, err
+	}
+	return finishCompress(&buf, w, src)
+}
+
+func (z ZlibCompressor) Decompress(src []byte) (decompressed []byte, err error) {
+	r, err := zlib.NewReader(bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+	return finishDecompress(r)
+}
+
+func finishCompress(buf *bytes.Buffer, w io.WriteCloser, src []byte) (compressed []byte, err error) {
+	if _, err = w.Write(src); err != nil {
+		return nil, err
+	}
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func finishDecompress(r io.ReadCloser) (decompressed []byte, err error) {
+	decompressed, err = io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return decompressed, r.Close()
+}
+
+/**************
+	COMPRESSION
+***************/
+
+// Replace the crate's written data with its compressed form, resetting the read index.
+// Returns the crate's error without compressing if it has failed under FlagNoPanic
+func (c *Crate) Compress(comp Compressor) error {
+	if c.failed != nil {
+		return c.failed.err
+	}
+	compressed, err := comp.Compress(c.data[:c.write])
+	if err != nil {
+		return err
+	}
+	c.replaceData(compressed)
+	return nil
+}
+
+// Replace the crate's written data with its decompressed form, resetting the read index.
+// Returns the crate's error without decompressing if it has failed under FlagNoPanic
+func (c *Crate) Decompress(comp Compressor) error {
+	if c.failed != nil {
+		return c.failed.err
+	}
+	decompressed, err := comp.Decompress(c.data[:c.write])
+	if err != nil {
+		return err
+	}
+	c.replaceData(decompressed)
+	return nil
+}
+
+// A SelfSerializer that stores Crate's written data compressed with Compressor
+// (preceded by a length-or-nil counter), for embedding large crates inside other crates.
+//
+// When read, Crate is replaced with a new crate holding the decompressed data (flagged with FlagDefault),
+// or if Crate is not nil, its contents are replaced instead. Panics if compression fails
+type CompressedCrate struct {
+	Crate      *Crate
+	Compressor Compressor
+}
+
+func (cc *CompressedCrate) UseSelf(crate *Crate, mode UseMode) {
+	switch mode {
+	case Write:
+		compressed, err := cc.Compressor.Compress(cc.Crate.Data())
+		if err != nil {
+			panic("LiteCrate: CompressedCrate failed to compress: " + err.Error())
+		}
+		crate.WriteBytesWithCounter(compressed)
+	case Read, Peek:
+		idx := crate.read
+		decompressed, err := cc.Compressor.Decompress(crate.SliceBytesWithCounter())
+		if err != nil {
+			panic("LiteCrate: CompressedCrate failed to decompress: " + err.Error())
+		}
+		crate.DiscardBytesWithCounter()
+		if mode == Peek {
+			crate.read = idx
+		}
+		if cc.Crate == nil {
+			cc.Crate = OpenCrate(decompressed, FlagDefault)
+			return
+		}
+		cc.Crate.replaceData(decompressed)
+	case Discard:
+		crate.DiscardBytesWithCounter()
+	case Slice:
+		// Nothing to use, the caller measures the slice using Read
+	default:
+		crate.useCustomMode(cc, mode, "CompressedCrate.UseSelf")
+	}
+}
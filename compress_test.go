@@ -0,0 +1,150 @@
+//go:build !litecrate_core && !litecrate_nocompress
+
+package litecrate_test
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+var compressors = map[string]lite.Compressor{
+	"Gzip":   lite.Gzip,
+	"Zlib":   lite.Zlib,
+	"Snappy": lite.Snappy,
+}
+
+func compressInputs() [][]byte {
+	random := make([]byte, 5000)
+	rand.New(rand.NewSource(1)).Read(random)
+	long := bytes.Repeat([]byte("abcdefgh"), 10000)
+	return [][]byte{
+		{},
+		{1},
+		[]byte("abc"),
+		bytes.Repeat([]byte{'a'}, 200),
+		random,
+		append(append(append([]byte{}, random[:3000]...), long...), random[:3000]...),
+	}
+}
+
+func TestCompress(t *testing.T) {
+	for name, comp := range compressors {
+		for i, input := range compressInputs() {
+			crate := lite.NewCrate(8, lite.FlagAutoDouble)
+			crate.WriteBytes(input)
+			crate.Read(make([]byte, 1))
+			if err := crate.Compress(comp); err != nil {
+				t.Errorf("%s.Compress() - FAIL: input %d: %v", name, i, err)
+				continue
+			}
+			if len(input) > 1000 && i != 4 && crate.WriteIndex() > uint64(len(input))/4 {
+				t.Errorf("%s.Compress() - FAIL: input %d only compressed to %d of %d bytes", name, i, crate.WriteIndex(), len(input))
+			}
+			if err := crate.Decompress(comp); err != nil {
+				t.Errorf("%s.Decompress() - FAIL: input %d: %v", name, i, err)
+				continue
+			}
+			if !bytes.Equal(crate.Data(), input) || crate.ReadIndex() != 0 {
+				t.Errorf("%s.Decompress() - FAIL: input %d did not round trip", name, i)
+			}
+		}
+		if _, err := comp.Decompress([]byte{0xFF, 0xFF, 0xFF}); err == nil {
+			t.Errorf("%s.Decompress() - FAIL: garbage did not return error", name)
+		}
+	}
+}
+
+func TestSnappyFormat(t *testing.T) {
+	// Hand-assembled blocks: literal "a" + 1-byte-offset copy, and literal + 2-byte-offset copy
+	blocks := map[string][]byte{
+		"aaaaaaaaaa":             {10, 0 << 2, 'a', (9-4)<<2 | 1, 1},
+		"abcabcabcabcabcabcabca": {22, 2 << 2, 'a', 'b', 'c', (19-1)<<2 | 2, 3, 0},
+	}
+	for want, block := range blocks {
+		got, err := lite.Snappy.Decompress(block)
+		if err != nil || string(got) != want {
+			t.Errorf("Snappy.Decompress() - FAIL: %q != %q (%v)", got, want, err)
+		}
+	}
+	compressed, _ := lite.Snappy.Compress([]byte("aaaaaaaaaa"))
+	if !bytes.Equal(compressed, blocks["aaaaaaaaaa"]) {
+		t.Errorf("Snappy.Compress() - FAIL: %v != %v", compressed, blocks["aaaaaaaaaa"])
+	}
+	corrupt := [][]byte{
+		{},
+		{5, 0 << 2, 'a'},
+		{1, 1, 1},
+		{4, 0 << 2, 'a', (4-4)<<2 | 1, 2},
+		{100, 0 << 2, 'a'},
+		{2, 61 << 2, 0xFF},
+	}
+	for _, block := range corrupt {
+		if _, err := lite.Snappy.Decompress(block); err == nil {
+			t.Errorf("Snappy.Decompress() - FAIL: corrupt block %v did not return error", block)
+		}
+	}
+}
+
+func TestCompressedCrate(t *testing.T) {
+	inner := lite.NewCrate(8, lite.FlagAutoDouble)
+	for i := 0; i < 1000; i += 1 {
+		inner.WriteStringWithCounter("repeated")
+	}
+	outer := lite.NewCrate(8, lite.FlagAutoDouble)
+	outer.WriteSelfSerializer(&lite.CompressedCrate{Crate: inner, Compressor: lite.Snappy})
+	outer.WriteU8(42)
+	if outer.WriteIndex() > inner.WriteIndex()/4 {
+		t.Errorf("CompressedCrate(Write) - FAIL: %d bytes is not much smaller than %d", outer.WriteIndex(), inner.WriteIndex())
+	}
+	peeked := lite.CompressedCrate{Compressor: lite.Snappy}
+	outer.PeekSelfSerializer(&peeked)
+	if outer.ReadIndex() != 0 || !bytes.Equal(peeked.Crate.Data(), inner.Data()) {
+		t.Errorf("CompressedCrate(Peek) - FAIL: index %d or data mismatch", outer.ReadIndex())
+	}
+	existing := lite.NewCrate(4, lite.FlagStatic)
+	read := lite.CompressedCrate{Crate: existing, Compressor: lite.Snappy}
+	outer.ReadSelfSerializer(&read)
+	if read.Crate != existing || !bytes.Equal(existing.Data(), inner.Data()) || outer.ReadU8() != 42 {
+		t.Errorf("CompressedCrate(Read) - FAIL: existing crate not refilled")
+	}
+	outer.ResetReadIndex()
+	outer.DiscardSelfSerializer(&read)
+	if outer.ReadU8() != 42 {
+		t.Errorf("CompressedCrate(Discard) - FAIL: did not skip compressed data")
+	}
+}
+
+func FuzzSnappy(f *testing.F) {
+	for _, input := range compressInputs()[:4] {
+		f.Add(input)
+	}
+	f.Fuzz(func(t *testing.T, input []byte) {
+		compressed, err := lite.Snappy.Compress(input)
+		if err != nil {
+			t.Fatalf("Snappy.Compress() - FAIL: %v", err)
+		}
+		decompressed, err := lite.Snappy.Decompress(compressed)
+		if err != nil || !bytes.Equal(decompressed, input) {
+			t.Errorf("Snappy.Decompress() - FAIL: did not round trip (%v)", err)
+		}
+		lite.Snappy.Decompress(input)
+	})
+}
+
+func TestCompressNoPanic(t *testing.T) {
+	for name, comp := range compressors {
+		crate := failedCrate()
+		if err := crate.Compress(comp); err != crate.Err() {
+			t.Errorf("%s.Compress() - FAIL: failed crate gave %v", name, err)
+		}
+		if err := crate.Decompress(comp); err != crate.Err() {
+			t.Errorf("%s.Decompress() - FAIL: failed crate gave %v", name, err)
+		}
+		if crate.ClearErr(); !bytes.Equal(crate.Data(), []byte{7}) {
+			t.Errorf("%s.Compress() - FAIL: failed crate compressed into %v", name, crate.Data())
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package litecrate
+
+/**************
+	CONDITIONAL
+***************/
+
+// Use a group of values that is only present when *cond is true, according to mode.
+//
+// *cond is written first as a Bool, and useGuarded is only called (with the same mode)
+// when it is true. When reading, *cond is set to the flag that was read, and the guarded values
+// keep whatever they held before if it is false. Discard and Slice skip the guarded values
+// only when they were written, so the flag and values always stay in step.
+//
+// Write = 'write the flag and guarded values into crate', Read = 'read the flag and guarded values from crate',
+// Peek = 'read the flag and guarded values without advancing index'
+// Slice = 'Return the slice the flag and guarded values occupy'
+//
+// Example:
+//
+//	crate.UseIf(&msg.HasLocation, func(mode UseMode) {
+//		crate.UseF64(&msg.Lat, mode)
+//		crate.UseF64(&msg.Lon, mode)
+//	}, mode)
+func (c *Crate) UseIf(cond *bool, useGuarded func(mode UseMode), mode UseMode) (sliceModeData []byte) {
+	switch mode {
+	case Write:
+		c.WriteBool(*cond)
+		if *cond {
+			useGuarded(Write)
+		}
+	case Read:
+		*cond = c.ReadBool()
+		if *cond {
+			useGuarded(Read)
+		}
+	case Peek:
+		idx := c.read
+		*cond = c.ReadBool()
+		if *cond {
+			useGuarded(Read)
+		}
+		c.read = idx
+	case Discard:
+		if c.ReadBool() {
+			useGuarded(Discard)
+		}
+	case Slice:
+		idx := c.read
+		if c.ReadBool() {
+			useGuarded(Discard)
+		}
+		end := c.read
+		c.read = idx
+		return c.data[idx:end:end]
+	default:
+		c.useCustomMode(cond, mode, "UseIf")
+		if *cond {
+			useGuarded(mode)
+		}
+	}
+	return nil
+}
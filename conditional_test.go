@@ -0,0 +1,70 @@
+package litecrate_test
+
+import (
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+type conditionalPlace struct {
+	Name        string
+	HasLocation bool
+	Lat, Lon    float64
+}
+
+func (p *conditionalPlace) UseSelf(crate *lite.Crate, mode lite.UseMode) {
+	crate.UseStringWithCounter(&p.Name, mode)
+	crate.UseIf(&p.HasLocation, func(mode lite.UseMode) {
+		crate.UseF64(&p.Lat, mode)
+		crate.UseF64(&p.Lon, mode)
+	}, mode)
+}
+
+func TestUseIf(t *testing.T) {
+	crate := lite.NewCrate(16, lite.FlagAutoDouble)
+	sent := []conditionalPlace{
+		{Name: "home", HasLocation: true, Lat: 51.5, Lon: -0.1},
+		{Name: "nowhere", Lat: 1, Lon: 2},
+		{Name: "", HasLocation: true},
+	}
+	for i := range sent {
+		crate.WriteSelfSerializer(&sent[i])
+	}
+	if crate.WriteIndex() != 22+9+18 {
+		t.Errorf("UseIf(Write) - FAIL: wrote %d bytes", crate.WriteIndex())
+	}
+	var peeked conditionalPlace
+	crate.PeekSelfSerializer(&peeked)
+	if peeked != sent[0] || crate.ReadIndex() != 0 {
+		t.Errorf("UseIf(Peek) - FAIL: %+v", peeked)
+	}
+	for i := range sent {
+		got := conditionalPlace{Lat: 7, Lon: 7}
+		crate.ReadSelfSerializer(&got)
+		expected := sent[i]
+		if !expected.HasLocation {
+			expected.Lat, expected.Lon = 7, 7
+		}
+		if got != expected {
+			t.Errorf("UseIf(Read) - FAIL: place %d: %+v != %+v", i, got, expected)
+		}
+	}
+	crate.ResetReadIndex()
+	crate.DiscardStringWithCounter()
+	if slice := crate.UseIf(&peeked.HasLocation, func(mode lite.UseMode) { crate.UseF64(&peeked.Lat, mode); crate.UseF64(&peeked.Lon, mode) }, lite.Slice); len(slice) != 17 {
+		t.Errorf("UseIf(Slice) - FAIL: len %d != 17", len(slice))
+	}
+	crate.ResetReadIndex()
+	for range sent {
+		crate.DiscardSelfSerializer(&peeked)
+	}
+	if crate.ReadsLeft() != 0 {
+		t.Errorf("UseIf(Discard) - FAIL: %d bytes left", crate.ReadsLeft())
+	}
+
+	var visited []string
+	lite.Visit(&sent[1], lite.VisitorFunc(func(val any, name string) { visited = append(visited, name) }))
+	if len(visited) != 3 || visited[2] != "UseIf" {
+		t.Errorf("UseIf(Visit) - FAIL: visited %v", visited)
+	}
+}
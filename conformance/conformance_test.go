@@ -0,0 +1,203 @@
+package conformance_test
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+	"github.com/gabe-lee/litecrate/conformance"
+)
+
+var intWidths = map[lite.FieldKind]int{
+	lite.KindU8: 1, lite.KindI8: 1, lite.KindU16: 2, lite.KindI16: 2, lite.KindU24: 3, lite.KindI24: 3,
+	lite.KindU32: 4, lite.KindI32: 4, lite.KindU40: 5, lite.KindI40: 5, lite.KindU48: 6, lite.KindI48: 6,
+	lite.KindU56: 7, lite.KindI56: 7, lite.KindU64: 8, lite.KindI64: 8,
+}
+
+// Encode a vector's value with the specification's encoders
+func specEncode(v conformance.Vector) []byte {
+	switch val := v.Value.(type) {
+	case bool:
+		if val {
+			return []byte{1}
+		}
+		return []byte{0}
+	case uint64:
+		if v.Kind == lite.KindUVarint {
+			return conformance.AppendUVarint(nil, val)
+		}
+		return conformance.AppendUint(nil, val, intWidths[v.Kind])
+	case int64:
+		if v.Kind == lite.KindVarint {
+			return conformance.AppendVarint(nil, val)
+		}
+		return conformance.AppendInt(nil, val, intWidths[v.Kind])
+	case float32:
+		return conformance.AppendF32(nil, val)
+	case float64:
+		return conformance.AppendF64(nil, val)
+	case complex64:
+		return conformance.AppendF32(conformance.AppendF32(nil, real(val)), imag(val))
+	case complex128:
+		return conformance.AppendF64(conformance.AppendF64(nil, real(val)), imag(val))
+	case string:
+		return conformance.AppendString(nil, val)
+	case []byte:
+		return conformance.AppendBytes(nil, val)
+	}
+	panic(fmt.Sprintf("unknown value type %T", v.Value))
+}
+
+// Encode a vector's value with the reference implementation
+func crateEncode(v conformance.Vector) []byte {
+	crate := lite.NewCrate(16, lite.FlagDefault)
+	switch v.Kind {
+	case lite.KindBool:
+		crate.WriteBool(v.Value.(bool))
+	case lite.KindU8:
+		crate.WriteU8(uint8(v.Value.(uint64)))
+	case lite.KindU16:
+		crate.WriteU16(uint16(v.Value.(uint64)))
+	case lite.KindU24:
+		crate.WriteU24(uint32(v.Value.(uint64)))
+	case lite.KindU32:
+		crate.WriteU32(uint32(v.Value.(uint64)))
+	case lite.KindU40:
+		crate.WriteU40(v.Value.(uint64))
+	case lite.KindU48:
+		crate.WriteU48(v.Value.(uint64))
+	case lite.KindU56:
+		crate.WriteU56(v.Value.(uint64))
+	case lite.KindU64:
+		crate.WriteU64(v.Value.(uint64))
+	case lite.KindI8:
+		crate.WriteI8(int8(v.Value.(int64)))
+	case lite.KindI16:
+		crate.WriteI16(int16(v.Value.(int64)))
+	case lite.KindI24:
+		crate.WriteI24(int32(v.Value.(int64)))
+	case lite.KindI32:
+		crate.WriteI32(int32(v.Value.(int64)))
+	case lite.KindI40:
+		crate.WriteI40(v.Value.(int64))
+	case lite.KindI48:
+		crate.WriteI48(v.Value.(int64))
+	case lite.KindI56:
+		crate.WriteI56(v.Value.(int64))
+	case lite.KindI64:
+		crate.WriteI64(v.Value.(int64))
+	case lite.KindF32:
+		crate.WriteF32(v.Value.(float32))
+	case lite.KindF64:
+		crate.WriteF64(v.Value.(float64))
+	case lite.KindC64:
+		crate.WriteC64(v.Value.(complex64))
+	case lite.KindC128:
+		crate.WriteC128(v.Value.(complex128))
+	case lite.KindUVarint:
+		crate.WriteUVarint(v.Value.(uint64))
+	case lite.KindVarint:
+		crate.WriteVarint(v.Value.(int64))
+	case lite.KindString:
+		crate.WriteStringWithCounter(v.Value.(string))
+	case lite.KindBytes:
+		crate.WriteBytesWithCounter(v.Value.([]byte))
+	}
+	return crate.Data()
+}
+
+func TestVectors(t *testing.T) {
+	for _, v := range conformance.Vectors {
+		if spec := specEncode(v); !bytes.Equal(spec, v.Bytes) {
+			t.Errorf("Vectors[%s] - FAIL: specification encodes %x", v.Name, spec)
+		}
+		if ref := crateEncode(v); !bytes.Equal(ref, v.Bytes) {
+			t.Errorf("Vectors[%s] - FAIL: reference encodes %x", v.Name, ref)
+		}
+	}
+}
+
+func TestSpecMatchesReference(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 5000; i += 1 {
+		u := random.Uint64() >> random.Intn(64)
+		n := int64(u)
+		if random.Intn(2) == 0 {
+			n = -n
+		}
+		width := 1 + random.Intn(8)
+		shift := 64 - 8*width
+		narrow := n << shift >> shift
+		for _, v := range []conformance.Vector{
+			{Kind: lite.KindUVarint, Value: u},
+			{Kind: lite.KindVarint, Value: n},
+			{Kind: lite.FieldKind(width*2 - 1), Value: u & (math.MaxUint64 >> shift)},
+			{Kind: lite.FieldKind(width * 2), Value: narrow},
+		} {
+			spec, ref := specEncode(v), crateEncode(v)
+			if !bytes.Equal(spec, ref) {
+				t.Fatalf("%s(%v) - FAIL: specification %x, reference %x", v.Kind, v.Value, spec, ref)
+			}
+		}
+		data := conformance.AppendVarint(conformance.AppendUVarint(nil, u), n)
+		gotU, size, err := conformance.ReadUVarint(data)
+		if err != nil || gotU != u {
+			t.Fatalf("ReadUVarint(%x) - FAIL: %d, %v", data, gotU, err)
+		}
+		zigzag, _, err := conformance.ReadUVarint(data[size:])
+		if err != nil || conformance.UnZigZag(zigzag) != n {
+			t.Fatalf("UnZigZag(%d) - FAIL: %d", zigzag, conformance.UnZigZag(zigzag))
+		}
+		if got, err := conformance.ReadInt(conformance.AppendInt(nil, narrow, width), width); err != nil || got != narrow {
+			t.Fatalf("ReadInt(%d, %d) - FAIL: %d, %v", narrow, width, got, err)
+		}
+	}
+	if _, _, err := conformance.ReadUVarint([]byte{0x80, 0x80}); err != conformance.ErrTruncated {
+		t.Errorf("ReadUVarint(truncated) - FAIL: %v", err)
+	}
+	if _, err := conformance.ReadUint([]byte{1}, 2); err != conformance.ErrTruncated {
+		t.Errorf("ReadUint(truncated) - FAIL: %v", err)
+	}
+}
+
+type point struct {
+	X, Y int8
+	Tag  string
+}
+
+func (p *point) UseSelf(crate *lite.Crate, mode lite.UseMode) {
+	crate.UseI8(&p.X, mode)
+	crate.UseI8(&p.Y, mode)
+	crate.UseStringWithCounter(&p.Tag, mode)
+}
+
+// Records failures instead of failing the test
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func TestVerify(t *testing.T) {
+	if !conformance.Verify(t, &point{X: 1, Y: -1, Tag: "a"}, []byte{0x01, 0xFF, 0x02, 'a'}) {
+		t.Errorf("Verify() - FAIL: matching golden bytes failed")
+	}
+	for _, golden := range [][]byte{
+		{0x01, 0xFE, 0x02, 'a'},       // wrong value
+		{0x01, 0xFF, 0x02, 'a', 0x00}, // trailing byte
+		{0x01, 0xFF},                  // truncated
+	} {
+		rec := &recorder{TB: t}
+		if conformance.Verify(rec, &point{X: 1, Y: -1, Tag: "a"}, golden) || len(rec.failures) == 0 {
+			t.Errorf("Verify(%x) - FAIL: mismatch not reported", golden)
+		}
+	}
+}
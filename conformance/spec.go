@@ -0,0 +1,145 @@
+// Package conformance is the executable specification of the LiteCrate wire format, with golden
+// byte vectors and a Verify() helper, so that implementations in other languages (and custom
+// SelfSerializers in Go) can be checked against the Go reference implementation.
+//
+// The encoders in this file are written from the specification, independently of litecrate.Crate,
+// and are tested against it. Every multi-byte value is little-endian, and values are written one after
+// the other with no padding, alignment or type information:
+//
+//	Bool            1 byte, 0 = false, 1 = true
+//	U8..U64         the low 1, 2, 3, 4, 5, 6, 7 or 8 bytes of the unsigned value
+//	I8..I64         the low 1-8 bytes of the two's complement value (the value must fit in that many bytes)
+//	F32, F64        the IEEE 754 bits as U32 or U64
+//	C64, C128       the real part then the imaginary part, each as F32 or F64
+//	UVarint         see AppendUVarint()
+//	Varint          the zig-zag encoding of the value (see ZigZag()) as a UVarint
+//	length-or-nil   see AppendLengthOrNil()
+//	String, Bytes   a length-or-nil counter followed by the bytes (strings are never nil)
+package conformance
+
+import (
+	"errors"
+	"math"
+)
+
+const (
+	MaxUVarintLen = 9 // Most bytes a UVarint occupies
+
+	uvarintContinue = 0x80 // bit set in every byte of a UVarint except the last
+	uvarintPayload  = 0x7F // bits of a UVarint byte holding the value (all 8 bits in the 9th byte)
+)
+
+// Returned by the Read____() functions when data ends before the value does
+var ErrTruncated = errors.New("conformance: data ends in the middle of a value")
+
+// Append the low width bytes (1-8) of val to dst, least significant first
+func AppendUint(dst []byte, val uint64, width int) []byte {
+	for i := 0; i < width; i += 1 {
+		dst = append(dst, byte(val>>(8*i)))
+	}
+	return dst
+}
+
+// Append the low width bytes (1-8) of val's two's complement representation to dst
+func AppendInt(dst []byte, val int64, width int) []byte {
+	return AppendUint(dst, uint64(val), width)
+}
+
+// Read a width byte (1-8) unsigned integer from the start of data
+func ReadUint(data []byte, width int) (val uint64, err error) {
+	if len(data) < width {
+		return 0, ErrTruncated
+	}
+	for i := 0; i < width; i += 1 {
+		val |= uint64(data[i]) << (8 * i)
+	}
+	return val, nil
+}
+
+// Read a width byte (1-8) two's complement integer from the start of data, extending its sign bit
+func ReadInt(data []byte, width int) (val int64, err error) {
+	u, err := ReadUint(data, width)
+	shift := 64 - 8*width
+	return int64(u<<shift) >> shift, err
+}
+
+// Append val to dst as an MSB UVarint: the value is split into 7 bit groups, least significant first,
+// and each group is written as a byte with its top bit set if more bytes follow.
+// The 9th byte, if reached, holds the remaining 8 bits of the value and has no continue bit,
+// so every uint64 fits in at most 9 bytes. Zero is one 0x00 byte, and encodings are always minimal
+func AppendUVarint(dst []byte, val uint64) []byte {
+	for i := 0; i < MaxUVarintLen-1; i += 1 {
+		if val <= uvarintPayload {
+			return append(dst, byte(val))
+		}
+		dst = append(dst, byte(val)&uvarintPayload|uvarintContinue)
+		val >>= 7
+	}
+	return append(dst, byte(val))
+}
+
+// Read an MSB UVarint from the start of data, returning it and the number of bytes it occupies
+func ReadUVarint(data []byte) (val uint64, n int, err error) {
+	for n < MaxUVarintLen {
+		if n >= len(data) {
+			return 0, 0, ErrTruncated
+		}
+		b := data[n]
+		if n == MaxUVarintLen-1 {
+			return val | uint64(b)<<56, n + 1, nil
+		}
+		val |= uint64(b&uvarintPayload) << (7 * n)
+		n += 1
+		if b&uvarintContinue == 0 {
+			break
+		}
+	}
+	return val, n, nil
+}
+
+// Returns the zig-zag encoding of val, which maps signed values to unsigned ones so that values near zero stay small:
+// 0 => 0, -1 => 1, 1 => 2, -2 => 3, 2 => 4...
+func ZigZag(val int64) uint64 {
+	return uint64(val<<1) ^ uint64(val>>63)
+}
+
+// Returns the signed value of a zig-zag encoded val
+func UnZigZag(val uint64) int64 {
+	return int64(val>>1) ^ -int64(val&1)
+}
+
+// Append val to dst as a Varint (its zig-zag encoding as a UVarint)
+func AppendVarint(dst []byte, val int64) []byte {
+	return AppendUVarint(dst, ZigZag(val))
+}
+
+// Append a length-or-nil counter to dst: a UVarint that is 0 for nil, otherwise length + 1
+// (so 1 means empty but not nil). Precedes strings, byte slices, slices and maps
+func AppendLengthOrNil(dst []byte, length uint64, isNil bool) []byte {
+	if isNil {
+		return AppendUVarint(dst, 0)
+	}
+	return AppendUVarint(dst, length+1)
+}
+
+// Append val to dst as F32
+func AppendF32(dst []byte, val float32) []byte {
+	return AppendUint(dst, uint64(math.Float32bits(val)), 4)
+}
+
+// Append val to dst as F64
+func AppendF64(dst []byte, val float64) []byte {
+	return AppendUint(dst, math.Float64bits(val), 8)
+}
+
+// Append val to dst with a length-or-nil counter, a nil val is written as nil
+func AppendBytes(dst []byte, val []byte) []byte {
+	dst = AppendLengthOrNil(dst, uint64(len(val)), val == nil)
+	return append(dst, val...)
+}
+
+// Append val to dst with a length-or-nil counter (strings are never nil)
+func AppendString(dst []byte, val string) []byte {
+	dst = AppendLengthOrNil(dst, uint64(len(val)), false)
+	return append(dst, val...)
+}
@@ -0,0 +1,82 @@
+package conformance
+
+import (
+	"math"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+// A golden test vector: Value of the given Kind must encode to exactly Bytes, and Bytes must decode to Value.
+//
+// Value is a bool, uint64 (unsigned kinds and UVarint), int64 (signed kinds and Varint), float32, float64,
+// complex64, complex128, string or []byte
+type Vector struct {
+	Name  string
+	Kind  lite.FieldKind
+	Value any
+	Bytes []byte
+}
+
+// Golden vectors for every primitive kind, concentrating on the edges of each encoding:
+// sign extension of the odd-width integers, every UVarint length, zig-zag, and nil versus empty counters
+var Vectors = []Vector{
+	{"false", lite.KindBool, false, []byte{0x00}},
+	{"true", lite.KindBool, true, []byte{0x01}},
+
+	{"u8 max", lite.KindU8, uint64(0xFF), []byte{0xFF}},
+	{"u16 little-endian", lite.KindU16, uint64(0x0102), []byte{0x02, 0x01}},
+	{"u24 little-endian", lite.KindU24, uint64(0x010203), []byte{0x03, 0x02, 0x01}},
+	{"u24 max", lite.KindU24, uint64(0xFFFFFF), []byte{0xFF, 0xFF, 0xFF}},
+	{"u32 little-endian", lite.KindU32, uint64(0x01020304), []byte{0x04, 0x03, 0x02, 0x01}},
+	{"u40 little-endian", lite.KindU40, uint64(0x0102030405), []byte{0x05, 0x04, 0x03, 0x02, 0x01}},
+	{"u48 little-endian", lite.KindU48, uint64(0x010203040506), []byte{0x06, 0x05, 0x04, 0x03, 0x02, 0x01}},
+	{"u56 max", lite.KindU56, uint64(1<<56 - 1), []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}},
+	{"u64 max", lite.KindU64, uint64(math.MaxUint64), []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}},
+
+	{"i8 -1", lite.KindI8, int64(-1), []byte{0xFF}},
+	{"i8 min", lite.KindI8, int64(math.MinInt8), []byte{0x80}},
+	{"i16 -2", lite.KindI16, int64(-2), []byte{0xFE, 0xFF}},
+	{"i24 -1", lite.KindI24, int64(-1), []byte{0xFF, 0xFF, 0xFF}},
+	{"i24 min", lite.KindI24, int64(-1 << 23), []byte{0x00, 0x00, 0x80}},
+	{"i24 max", lite.KindI24, int64(1<<23 - 1), []byte{0xFF, 0xFF, 0x7F}},
+	{"i32 min", lite.KindI32, int64(math.MinInt32), []byte{0x00, 0x00, 0x00, 0x80}},
+	{"i40 min", lite.KindI40, int64(-1 << 39), []byte{0x00, 0x00, 0x00, 0x00, 0x80}},
+	{"i40 -2", lite.KindI40, int64(-2), []byte{0xFE, 0xFF, 0xFF, 0xFF, 0xFF}},
+	{"i48 min", lite.KindI48, int64(-1 << 47), []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x80}},
+	{"i48 max", lite.KindI48, int64(1<<47 - 1), []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x7F}},
+	{"i56 min", lite.KindI56, int64(-1 << 55), []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x80}},
+	{"i56 -256", lite.KindI56, int64(-256), []byte{0x00, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}},
+	{"i64 min", lite.KindI64, int64(math.MinInt64), []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x80}},
+
+	{"f32 1.5", lite.KindF32, float32(1.5), []byte{0x00, 0x00, 0xC0, 0x3F}},
+	{"f32 -0", lite.KindF32, float32(math.Copysign(0, -1)), []byte{0x00, 0x00, 0x00, 0x80}},
+	{"f64 1.5", lite.KindF64, float64(1.5), []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xF8, 0x3F}},
+	{"f64 +inf", lite.KindF64, math.Inf(1), []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xF0, 0x7F}},
+	{"c64 1.5-2i", lite.KindC64, complex64(complex(1.5, -2)), []byte{0x00, 0x00, 0xC0, 0x3F, 0x00, 0x00, 0x00, 0xC0}},
+	{"c128 0+1i", lite.KindC128, complex(0, 1), []byte{
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xF0, 0x3F}},
+
+	{"uvarint 0", lite.KindUVarint, uint64(0), []byte{0x00}},
+	{"uvarint 1 byte max", lite.KindUVarint, uint64(127), []byte{0x7F}},
+	{"uvarint 2 byte min", lite.KindUVarint, uint64(128), []byte{0x80, 0x01}},
+	{"uvarint 300", lite.KindUVarint, uint64(300), []byte{0xAC, 0x02}},
+	{"uvarint 2 byte max", lite.KindUVarint, uint64(1<<14 - 1), []byte{0xFF, 0x7F}},
+	{"uvarint 3 byte min", lite.KindUVarint, uint64(1 << 14), []byte{0x80, 0x80, 0x01}},
+	{"uvarint 8 byte max", lite.KindUVarint, uint64(1<<56 - 1), []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x7F}},
+	{"uvarint 9 byte min", lite.KindUVarint, uint64(1 << 56), []byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x01}},
+	{"uvarint max", lite.KindUVarint, uint64(math.MaxUint64), []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}},
+
+	{"varint 0", lite.KindVarint, int64(0), []byte{0x00}},
+	{"varint -1", lite.KindVarint, int64(-1), []byte{0x01}},
+	{"varint 1", lite.KindVarint, int64(1), []byte{0x02}},
+	{"varint -64", lite.KindVarint, int64(-64), []byte{0x7F}},
+	{"varint 64", lite.KindVarint, int64(64), []byte{0x80, 0x01}},
+	{"varint max", lite.KindVarint, int64(math.MaxInt64), []byte{0xFE, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}},
+	{"varint min", lite.KindVarint, int64(math.MinInt64), []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}},
+
+	{"string empty", lite.KindString, "", []byte{0x01}},
+	{"string utf-8", lite.KindString, "hé", []byte{0x04, 'h', 0xC3, 0xA9}},
+	{"bytes nil", lite.KindBytes, []byte(nil), []byte{0x00}},
+	{"bytes empty", lite.KindBytes, []byte{}, []byte{0x01}},
+	{"bytes 3", lite.KindBytes, []byte{7, 8, 9}, []byte{0x04, 7, 8, 9}},
+}
@@ -0,0 +1,103 @@
+package conformance
+
+import (
+	"bytes"
+	"encoding/hex"
+	"reflect"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+// Check, reporting failures to t, that val encodes to exactly golden, that golden decodes
+// (into a new value of val's type) without leaving bytes unread and encodes back to golden,
+// and that discarding it skips exactly len(golden) bytes. Returns whether every check passed.
+//
+// Example:
+//
+//	func TestPointWireFormat(t *testing.T) {
+//		conformance.Verify(t, &Point{X: 1, Y: -1}, []byte{0x02, 0x01})
+//	}
+func Verify(t testing.TB, val lite.SelfSerializer, golden []byte) (ok bool) {
+	t.Helper()
+	name := reflect.TypeOf(val).String()
+	encoded, err := encode(val)
+	if err != nil {
+		t.Errorf("conformance: %s cannot be written: %v", name, err)
+		return false
+	}
+	ok = true
+	if !bytes.Equal(encoded, golden) {
+		t.Errorf("conformance: %s encodes to\n\t%s\nexpected\n\t%s\n(first difference at byte %d)",
+			name, hex.EncodeToString(encoded), hex.EncodeToString(golden), firstDifference(encoded, golden))
+		ok = false
+	}
+	decoded := newLike(val)
+	crate := lite.OpenCrate(golden, lite.FlagStatic)
+	if err := catch(func() { crate.ReadSelfSerializer(decoded) }); err != nil {
+		t.Errorf("conformance: golden bytes cannot be read as %s: %v", name, err)
+		return false
+	}
+	if left := crate.ReadsLeft(); left != 0 {
+		t.Errorf("conformance: reading %s left %d of %d golden bytes unread", name, left, len(golden))
+		ok = false
+	}
+	if reencoded, err := encode(decoded); err != nil || !bytes.Equal(reencoded, golden) {
+		t.Errorf("conformance: %s read from golden bytes encodes to\n\t%s\n(error: %v)", name, hex.EncodeToString(reencoded), err)
+		ok = false
+	}
+	crate.ResetReadIndex()
+	if err := catch(func() { crate.DiscardSelfSerializer(newLike(val)) }); err != nil || crate.ReadIndex() != uint64(len(golden)) {
+		t.Errorf("conformance: discarding %s skipped %d of %d golden bytes (error: %v)", name, crate.ReadIndex(), len(golden), err)
+		ok = false
+	}
+	return ok
+}
+
+func encode(val lite.SelfSerializer) (data []byte, err error) {
+	crate := lite.NewCrate(64, lite.FlagDefault)
+	err = catch(func() { crate.WriteSelfSerializer(val) })
+	return crate.Data(), err
+}
+
+// Returns a pointer to a new zero value of the type val points to, or val itself if it is not a pointer
+func newLike(val lite.SelfSerializer) lite.SelfSerializer {
+	if rv := reflect.ValueOf(val); rv.Kind() == reflect.Pointer {
+		if fresh, ok := reflect.New(rv.Type().Elem()).Interface().(lite.SelfSerializer); ok {
+			return fresh
+		}
+	}
+	return val
+}
+
+func firstDifference(a []byte, b []byte) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i += 1
+	}
+	return i
+}
+
+type panicError struct {
+	val any
+}
+
+func (p panicError) Error() string {
+	switch val := p.val.(type) {
+	case string:
+		return val
+	case error:
+		return val.Error()
+	}
+	return "panic"
+}
+
+func catch(fn func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicError{r}
+		}
+	}()
+	fn()
+	return nil
+}
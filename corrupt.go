@@ -0,0 +1,188 @@
+//go:build !litecrate_core && !litecrate_notools
+
+package litecrate
+
+import (
+	"errors"
+	"math/rand"
+)
+
+// Length claimed by the second inflated counter Corrupter.Samples() makes for each counter,
+// large enough that a decoder trusting it would try to allocate terabytes
+const corruptHugeLength = 1 << 40
+
+// Kind of damage a Corrupter did to a CorruptSample
+type Corruption uint8
+
+const (
+	CorruptBitFlip  Corruption = 0 // one bit of the field flipped
+	CorruptTruncate Corruption = 1 // data cut off where the field begins
+	CorruptCounter  Corruption = 2 // the field's length-or-nil counter replaced with one claiming more than follows it
+)
+
+var corruptionNames = [...]string{"BitFlip", "Truncate", "Counter"}
+
+func (k Corruption) String() string {
+	if int(k) < len(corruptionNames) {
+		return corruptionNames[k]
+	}
+	return "Corruption(" + intStr(k) + ")"
+}
+
+// Encodes the corruption as its name, so samples stored as JSON are readable
+func (k Corruption) MarshalText() ([]byte, error) {
+	if int(k) >= len(corruptionNames) {
+		return nil, errors.New("LiteCrate: unknown corruption " + intStr(k))
+	}
+	return []byte(corruptionNames[k]), nil
+}
+
+// A damaged copy of a crate's data, labelled with what was done to it
+type CorruptSample struct {
+	Kind   Corruption
+	Field  string // Path of the damaged field: schema field names joined by '.', with [i] for slice elements and [i].key or [i].value for map entries
+	Offset uint64 // Index of the first damaged byte (for CorruptTruncate, the length of Data)
+	Data   []byte
+}
+
+/**************
+	CORRUPTER
+***************/
+
+// Produces corrupted copies of data laid out according to Schema, for checking that
+// consumers reject or resync past bad input instead of crashing or misreading it.
+//
+// If Rng is nil bit flips always flip the top bit of a field's first byte, otherwise they flip
+// a random bit of the field. Sample() requires Rng
+type Corrupter struct {
+	Schema *Schema
+	Rng    *rand.Rand
+}
+
+// Where a value described by a schema field lies in the data
+type fieldSpan struct {
+	path    string
+	kind    FieldKind
+	offset  uint64 // index of the value's first byte
+	counter uint64 // size of its length-or-nil counter, 0 if it has none
+	end     uint64 // index after the value's last byte
+}
+
+// Returns every corruption of data the Corrupter knows, in field order: for each field
+// a bit flip and a truncation where it begins, and for each string, bytes, slice or map field
+// two inflated counters, one claiming a single element more than the bytes left after it and one claiming 1<<40.
+// Struct fields are only damaged through the fields inside them, and bytes fields are treated as opaque.
+// Returns an error if data does not match the schema
+func (c *Corrupter) Samples(data []byte) (samples []CorruptSample, err error) {
+	spans, err := c.locate(data)
+	if err != nil {
+		return nil, err
+	}
+	for _, span := range spans {
+		samples = append(samples, c.flipBit(data, span))
+		samples = append(samples, CorruptSample{Kind: CorruptTruncate, Field: span.path, Offset: span.offset, Data: append([]byte(nil), data[:span.offset]...)})
+		if span.counter > 0 {
+			rest := len64(data) - span.offset - span.counter
+			samples = append(samples, inflateCounter(data, span, rest+1), inflateCounter(data, span, corruptHugeLength))
+		}
+	}
+	return samples, nil
+}
+
+// Returns one corruption of data chosen at random from those Samples() returns
+func (c *Corrupter) Sample(data []byte) (sample CorruptSample, err error) {
+	if c.Rng == nil {
+		return sample, errors.New("LiteCrate: Corrupter.Sample() requires Rng")
+	}
+	samples, err := c.Samples(data)
+	if err != nil {
+		return sample, err
+	}
+	return samples[c.Rng.Intn(len(samples))], nil
+}
+
+func (c *Corrupter) flipBit(data []byte, span fieldSpan) CorruptSample {
+	offset, bit := span.offset, uint(7)
+	if c.Rng != nil {
+		// Containers are only flipped in their counter, their contents have spans of their own
+		size := span.end - span.offset
+		if span.kind == KindSlice || span.kind == KindMap {
+			size = span.counter
+		}
+		offset += uint64(c.Rng.Int63n(int64(size)))
+		bit = uint(c.Rng.Intn(8))
+	}
+	damaged := append([]byte(nil), data...)
+	damaged[offset] ^= 1 << bit
+	return CorruptSample{Kind: CorruptBitFlip, Field: span.path, Offset: offset, Data: damaged}
+}
+
+func inflateCounter(data []byte, span fieldSpan, length uint64) CorruptSample {
+	crate := NewCrate(len64(data)+9, FlagStatic)
+	crate.WriteBytes(data[:span.offset])
+	crate.WriteLengthOrNil(length, false)
+	crate.WriteBytes(data[span.offset+span.counter:])
+	return CorruptSample{Kind: CorruptCounter, Field: span.path, Offset: span.offset, Data: crate.Data()}
+}
+
+func (c *Corrupter) locate(data []byte) (spans []fieldSpan, err error) {
+	defer func() {
+		switch r := recover().(type) {
+		case nil:
+		case string:
+			spans, err = nil, errors.New(r)
+		case error:
+			spans, err = nil, r
+		default:
+			panic(r)
+		}
+	}()
+	crate := OpenCrate(data, FlagStatic)
+	for i := range c.Schema.Fields {
+		c.Schema.Fields[i].locate(crate, c.Schema.Fields[i].Name, &spans)
+	}
+	if crate.ReadsLeft() > 0 {
+		return nil, errors.New("LiteCrate: " + intStr(crate.ReadsLeft()) + " bytes left after schema " + c.Schema.Name)
+	}
+	return spans, nil
+}
+
+func (f *Field) locate(crate *Crate, path string, spans *[]fieldSpan) {
+	if f.Kind == KindStruct {
+		for i := range f.Fields {
+			f.Fields[i].locate(crate, path+"."+f.Fields[i].Name, spans)
+		}
+		return
+	}
+	index := len(*spans)
+	*spans = append(*spans, fieldSpan{path: path, kind: f.Kind, offset: crate.read})
+	switch {
+	case kindWidth(f.Kind) > 0:
+		crate.SliceBytes(kindWidth(f.Kind))
+		crate.read += kindWidth(f.Kind)
+	case f.Kind == KindUVarint, f.Kind == KindVarint:
+		crate.ReadUVarint()
+	case f.Kind == KindString, f.Kind == KindBytes:
+		length, _, n := crate.ReadLengthOrNil()
+		crate.SliceBytes(length)
+		crate.read += length
+		(*spans)[index].counter = n
+	case f.Kind == KindSlice, f.Kind == KindMap:
+		length, _, n := crate.ReadLengthOrNil()
+		(*spans)[index].counter = n
+		if length > 0 {
+			f.checkContainer()
+		}
+		for i := uint64(0); i < length; i += 1 {
+			element := path + "[" + intStr(i) + "]"
+			if f.Kind == KindMap {
+				f.Key.locate(crate, element+".key", spans)
+				element += ".value"
+			}
+			f.Elem.locate(crate, element, spans)
+		}
+	default:
+		panic("LiteCrate: field " + f.Name + " has unknown kind " + f.Kind.String())
+	}
+	(*spans)[index].end = crate.read
+}
@@ -0,0 +1,98 @@
+//go:build !litecrate_core && !litecrate_notools
+
+package litecrate_test
+
+import (
+	"bytes"
+	"math/bits"
+	"math/rand"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+var corruptSchema = &lite.Schema{Name: "Order", Fields: []lite.Field{
+	{Name: "id", Kind: lite.KindU16},
+	{Name: "note", Kind: lite.KindString},
+	{Name: "items", Kind: lite.KindSlice, Elem: &lite.Field{Kind: lite.KindStruct, Fields: []lite.Field{
+		{Name: "sku", Kind: lite.KindUVarint},
+		{Name: "qty", Kind: lite.KindI8},
+	}}},
+	{Name: "tags", Kind: lite.KindMap, Key: &lite.Field{Kind: lite.KindString}, Elem: &lite.Field{Kind: lite.KindU32}},
+}}
+
+func TestCorrupter(t *testing.T) {
+	crate := lite.NewCrate(32, lite.FlagAutoDouble)
+	crate.WriteU16(7)
+	crate.WriteStringWithCounter("rush")
+	crate.WriteLengthOrNil(2, false)
+	crate.WriteUVarint(300)
+	crate.WriteI8(-1)
+	crate.WriteUVarint(5)
+	crate.WriteI8(2)
+	crate.WriteLengthOrNil(1, false)
+	crate.WriteStringWithCounter("gift")
+	crate.WriteU32(1)
+	data := crate.Data()
+
+	corrupter := lite.Corrupter{Schema: corruptSchema}
+	samples, err := corrupter.Samples(data)
+	if err != nil {
+		t.Fatalf("Corrupter.Samples() - FAIL: %v", err)
+	}
+	// 10 fields, each flipped and truncated, plus 2 inflated counters for each of note, items, tags and tags[0].key
+	if len(samples) != 10*2+4*2 {
+		t.Fatalf("Corrupter.Samples() - FAIL: %d samples", len(samples))
+	}
+	fields := make(map[string]bool)
+	for _, sample := range samples {
+		fields[sample.Field] = true
+		_, err := lite.OpenCrate(sample.Data, lite.FlagStatic).ToJSON(corruptSchema)
+		switch sample.Kind {
+		case lite.CorruptBitFlip:
+			diff := 0
+			for i := range data {
+				diff += bits.OnesCount8(data[i] ^ sample.Data[i])
+			}
+			if diff != 1 || len(sample.Data) != len(data) || sample.Data[sample.Offset] != data[sample.Offset]^0x80 {
+				t.Errorf("Corrupter.Samples() - FAIL: %s bit flip changed %d bits", sample.Field, diff)
+			}
+		case lite.CorruptTruncate:
+			if !bytes.Equal(sample.Data, data[:sample.Offset]) || err == nil {
+				t.Errorf("Corrupter.Samples() - FAIL: %s truncated to %d bytes still decoded", sample.Field, len(sample.Data))
+			}
+		case lite.CorruptCounter:
+			if err == nil {
+				t.Errorf("Corrupter.Samples() - FAIL: %s inflated counter still decoded", sample.Field)
+			}
+		}
+	}
+	for _, field := range []string{"id", "note", "items", "items[0].sku", "items[1].qty", "tags", "tags[0].key", "tags[0].value"} {
+		if !fields[field] {
+			t.Errorf("Corrupter.Samples() - FAIL: no samples for %s", field)
+		}
+	}
+	if samples[3].Kind != lite.CorruptTruncate || samples[3].Field != "note" || samples[3].Offset != 2 {
+		t.Errorf("Corrupter.Samples() - FAIL: samples out of order: %+v", samples[3])
+	}
+	if text, err := lite.CorruptCounter.MarshalText(); err != nil || string(text) != "Counter" {
+		t.Errorf("Corruption.MarshalText() - FAIL: %q, %v", text, err)
+	}
+
+	if _, err := corrupter.Sample(data); err == nil {
+		t.Errorf("Corrupter.Sample() - FAIL: no error without Rng")
+	}
+	corrupter.Rng = rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i += 1 {
+		sample, err := corrupter.Sample(data)
+		if err != nil || bytes.Equal(sample.Data, data) {
+			t.Fatalf("Corrupter.Sample() - FAIL: %+v, %v", sample, err)
+		}
+	}
+	if _, err := corrupter.Samples(data[:len(data)-1]); err == nil {
+		t.Errorf("Corrupter.Samples() - FAIL: no error for data not matching schema")
+	}
+	if _, err := corrupter.Samples(append(data, 0)); err == nil {
+		t.Errorf("Corrupter.Samples() - FAIL: no error for data longer than schema")
+	}
+}
@@ -0,0 +1,544 @@
+//go:build !litecrate_core && !litecrate_noos
+
+package litecrate
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	logPlain     uint8 = 0 // record holds only its data
+	logKeyed     uint8 = 1 // record holds a key with counter, then its data
+	logTombstone uint8 = 2 // record holds a key with counter, marking the key deleted
+)
+
+var errCursorGroup = errors.New("LiteCrate: cursor group must be 1-64 letters, digits, '-' or '_'")
+
+// One record of a CrateLog
+type LogRecord struct {
+	Crate     *Crate // The record's data (empty for tombstones)
+	Key       string // The record's key, if it has one
+	Keyed     bool   // Whether the record has a key
+	Tombstone bool   // Whether the record marks Key as deleted
+}
+
+/**************
+	CRATE LOG
+***************/
+
+// A CrateLog is an append-only file of crates, each stored as a frame in the same format as
+// Framer.WriteFrame() holding a record type byte, the record's key (if it has one) and its data.
+// Records are identified by their byte offset in the file.
+//
+// Records may carry a key, in which case Compact() keeps only the latest record for each key,
+// and Delete() appends a tombstone that removes the key at the next compaction,
+// so the log can serve as a simple durable key-value store.
+// All methods are safe to call from multiple goroutines
+type CrateLog struct {
+	path  string
+	file  *os.File
+	flags uint8
+	mutex sync.RWMutex
+	size  int64
+}
+
+// Open the log at path, creating it if it does not exist. Crates read from the log are flagged with flags.
+// A record left incomplete at the end of the file (by a crash during Append()) is removed,
+// and a Compact() interrupted by a crash is finished or undone (see Compact())
+func OpenCrateLog(path string, flags uint8) (*CrateLog, error) {
+	log := &CrateLog{path: path, flags: flags}
+	if err := log.recoverCompact(); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	log.file = file
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	log.size = info.Size()
+	end := int64(0)
+	for end < log.size {
+		_, next, err := log.ReadRecordAt(end)
+		if err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		end = next
+	}
+	if end < log.size {
+		if err := file.Truncate(end); err != nil {
+			file.Close()
+			return nil, err
+		}
+		log.size = end
+	}
+	return log, nil
+}
+
+// Append the crate's written data to the log as one record without a key, returning its offset.
+// The record is not durable until Sync() is called. Returns the crate's error if it failed under FlagNoPanic
+func (l *CrateLog) Append(crate *Crate) (offset int64, err error) {
+	if err := crate.Err(); err != nil {
+		return 0, err
+	}
+	return l.appendRecord(logPlain, "", crate.Data())
+}
+
+// Append the crate's written data to the log as the latest record for key, returning its offset.
+// The record is not durable until Sync() is called. Returns the crate's error if it failed under FlagNoPanic
+func (l *CrateLog) AppendKeyed(key string, crate *Crate) (offset int64, err error) {
+	if err := crate.Err(); err != nil {
+		return 0, err
+	}
+	return l.appendRecord(logKeyed, key, crate.Data())
+}
+
+// Append a tombstone marking key as deleted, returning its offset.
+// The next Compact() removes the tombstone and every record for key before it
+func (l *CrateLog) Delete(key string) (offset int64, err error) {
+	return l.appendRecord(logTombstone, key, nil)
+}
+
+func (l *CrateLog) appendRecord(kind uint8, key string, data []byte) (offset int64, err error) {
+	record := encodeLogRecord(kind, key, data)
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if _, err := l.file.WriteAt(record, l.size); err != nil {
+		return 0, err
+	}
+	offset = l.size
+	l.size += int64(len(record))
+	return offset, nil
+}
+
+// Returns the record as a frame
+func encodeLogRecord(kind uint8, key string, data []byte) []byte {
+	payload := NewCrate(uint64(len(key)+len(data))+10, FlagStatic)
+	payload.WriteU8(kind)
+	if kind != logPlain {
+		payload.WriteStringWithCounter(key)
+	}
+	payload.WriteBytes(data)
+	record := NewCrate(payload.write+9, FlagStatic)
+	record.WriteUVarint(payload.write)
+	record.WriteBytes(payload.Data())
+	return record.Data()
+}
+
+// Read the data of the record at offset into a new crate (empty for tombstones),
+// returning it and the offset of the record after it. Returns io.EOF if offset is the end of the log
+func (l *CrateLog) ReadAt(offset int64) (crate *Crate, next int64, err error) {
+	record, next, err := l.ReadRecordAt(offset)
+	return record.Crate, next, err
+}
+
+// Read the record at offset, returning it and the offset of the record after it.
+// Returns io.EOF if offset is the end of the log
+func (l *CrateLog) ReadRecordAt(offset int64) (record LogRecord, next int64, err error) {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	return readLogRecord(l.file, offset, l.size, l.flags)
+}
+
+func readLogRecord(file io.ReaderAt, offset int64, size int64, flags uint8) (record LogRecord, next int64, err error) {
+	if offset == size {
+		return record, offset, io.EOF
+	}
+	if offset < 0 || offset > size {
+		return record, offset, errors.New("LiteCrate: log offset " + intStr(offset) + " is outside the log")
+	}
+	reader := io.NewSectionReader(file, offset, size-offset)
+	length, err := readFrameHeader(reader)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return record, offset, err
+	}
+	headerLen, _ := reader.Seek(0, io.SeekCurrent)
+	if length > uint64(size-offset-headerLen) {
+		return record, offset, io.ErrUnexpectedEOF
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		return record, offset, err
+	}
+	if record, err = decodeLogRecord(payload, flags); err != nil {
+		return record, offset, err
+	}
+	return record, offset + headerLen + int64(length), nil
+}
+
+func decodeLogRecord(payload []byte, flags uint8) (record LogRecord, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("LiteCrate: log record is corrupt")
+		}
+	}()
+	crate := OpenCrate(payload, FlagStatic)
+	switch kind := crate.ReadU8(); kind {
+	case logPlain:
+	case logKeyed, logTombstone:
+		record.Key, record.Keyed, record.Tombstone = crate.ReadStringWithCounter(), true, kind == logTombstone
+	default:
+		return record, errors.New("LiteCrate: log record has unknown type " + intStr(kind))
+	}
+	if record.Tombstone && crate.ReadsLeft() > 0 {
+		return record, errors.New("LiteCrate: log tombstone holds data")
+	}
+	record.Crate = OpenCrate(payload[crate.read:], flags)
+	return record, nil
+}
+
+// Returns the length of the log in bytes, which is the offset the next record will be appended at
+func (l *CrateLog) Size() int64 {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	return l.size
+}
+
+// Commit appended records to stable storage
+func (l *CrateLog) Sync() error {
+	return l.file.Sync()
+}
+
+func (l *CrateLog) Close() error {
+	return l.file.Close()
+}
+
+// Rewrite the log keeping only the latest record for each key, removing keys whose latest record
+// is a tombstone (along with the tombstone). Records without a key are always kept.
+// Kept records stay in their original order, but move to new offsets:
+// the committed offsets of every consumer group are moved to match, while cursors opened
+// before compacting must be opened again with Subscribe().
+//
+// The compacted log is written to "<log path>.compact" and the moved committed offsets to
+// "<cursor path>.compact" files, which replace the log and the cursor files only once they are all
+// complete and synced. The log is replaced first: a crash before that leaves the log and cursors
+// as they were, and a crash after it leaves the new cursor files to be moved into place by
+// the next OpenCrateLog(), so the committed offsets always match the log they were committed in
+func (l *CrateLog) Compact() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	latest := make(map[string]int64)
+	for offset := int64(0); offset < l.size; {
+		record, next, err := readLogRecord(l.file, offset, l.size, FlagStatic)
+		if err != nil {
+			return err
+		}
+		if record.Keyed {
+			latest[record.Key] = offset
+		}
+		offset = next
+	}
+	temp := l.path + ".compact"
+	compacted, err := os.OpenFile(temp, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	var moves []logMove
+	var size int64
+	for offset := int64(0); offset < l.size && err == nil; {
+		var record LogRecord
+		var next int64
+		if record, next, err = readLogRecord(l.file, offset, l.size, FlagStatic); err != nil {
+			break
+		}
+		if !record.Keyed || (latest[record.Key] == offset && !record.Tombstone) {
+			kind := logPlain
+			if record.Keyed {
+				kind = logKeyed
+			}
+			data := encodeLogRecord(kind, record.Key, record.Crate.Data())
+			moves = append(moves, logMove{from: offset, to: size})
+			if _, err = compacted.WriteAt(data, size); err != nil {
+				break
+			}
+			size += int64(len(data))
+		}
+		offset = next
+	}
+	if err == nil {
+		err = compacted.Sync()
+	}
+	if err == nil {
+		err = l.stageCursors(moves, size)
+	}
+	dir := filepath.Dir(l.path)
+	if err == nil {
+		err = syncDir(dir)
+	}
+	if err == nil {
+		err = os.Rename(temp, l.path)
+	}
+	if err != nil {
+		compacted.Close()
+		// The staged cursors must go before the compacted log, or they would be taken for a finished compaction
+		if l.removeStagedCursors() == nil {
+			os.Remove(temp)
+		}
+		return err
+	}
+	l.file.Close()
+	l.file, l.size = compacted, size
+	if err := syncDir(dir); err != nil {
+		return err
+	}
+	return l.commitStagedCursors()
+}
+
+// Where a record was moved by Compact()
+type logMove struct {
+	from int64
+	to   int64
+}
+
+// Write a staged cursor file beside every cursor file of the log, holding the new offset of the first
+// kept record at or after its old offset (or size, the end of the compacted log, if there is none)
+func (l *CrateLog) stageCursors(moves []logMove, size int64) error {
+	paths, err := filepath.Glob(l.path + ".*.cursor")
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		group, offset, partial, err := readCheckpoint(data)
+		if err != nil || path != l.path+"."+group+".cursor" {
+			continue
+		}
+		i := sort.Search(len(moves), func(i int) bool { return moves[i].from >= offset })
+		moved := size
+		if i < len(moves) {
+			moved = moves[i].to
+		}
+		// A partial checkpoint only applies to the record it was taken in, which may not have been kept
+		if i == len(moves) || moves[i].from != offset {
+			partial = nil
+		}
+		if err := writeFileAtomic(path+".compact", encodeCheckpoint(group, moved, partial)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Move every staged cursor file over the cursor file it was written for
+func (l *CrateLog) commitStagedCursors() error {
+	staged, err := filepath.Glob(l.path + ".*.cursor.compact")
+	if err != nil {
+		return err
+	}
+	for _, path := range staged {
+		if err := os.Rename(path, strings.TrimSuffix(path, ".compact")); err != nil {
+			return err
+		}
+	}
+	return syncDir(filepath.Dir(l.path))
+}
+
+// Remove every staged cursor file, leaving the cursor files as they were
+func (l *CrateLog) removeStagedCursors() error {
+	staged, err := filepath.Glob(l.path + ".*.cursor.compact")
+	if err != nil {
+		return err
+	}
+	for _, path := range staged {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Finish or undo a Compact() interrupted by a crash: if the compacted log is still beside the log
+// it never replaced it, so it and any staged cursor files are removed, otherwise the log was replaced
+// and the staged cursor files are moved into place
+func (l *CrateLog) recoverCompact() error {
+	temp := l.path + ".compact"
+	if _, err := os.Stat(temp); err == nil {
+		if err := l.removeStagedCursors(); err != nil {
+			return err
+		}
+		return os.Remove(temp)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	return l.commitStagedCursors()
+}
+
+/**************
+	LOG CURSOR
+***************/
+
+// A LogCursor reads the records of a CrateLog in order on behalf of a named consumer group,
+// and remembers (across restarts) how far the group has processed.
+// Records read with Next() but not yet committed with Commit() are read again by the next cursor
+// opened for the group, so every record is processed at least once.
+// A large record can also be committed part way through with CommitPartial().
+// A LogCursor is not safe for use by multiple goroutines
+type LogCursor struct {
+	log       *CrateLog
+	group     string
+	offset    int64
+	committed int64
+	current   int64             // Offset of the record last returned by Next(), -1 if none
+	partial   *DecodeCheckpoint // How far the record at committed has been processed, nil if not at all
+}
+
+// Returns a cursor for group, starting after the last record the group committed (or at the start of the log).
+// The committed offset is stored as a small crate in a file next to the log, named "<log path>.<group>.cursor"
+func (l *CrateLog) Subscribe(group string) (*LogCursor, error) {
+	if !validCursorGroup(group) {
+		return nil, errCursorGroup
+	}
+	cursor := &LogCursor{log: l, group: group, current: -1}
+	data, err := os.ReadFile(cursor.path())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		storedGroup, offset, partial, err := readCheckpoint(data)
+		if err != nil {
+			return nil, err
+		}
+		if storedGroup != group || offset < 0 || offset > l.Size() {
+			return nil, errors.New("LiteCrate: cursor file for group " + group + " does not match the log")
+		}
+		cursor.offset, cursor.committed, cursor.partial = offset, offset, partial
+	}
+	return cursor, nil
+}
+
+// Read the data of the next record (empty for tombstones), returning io.EOF if the cursor has reached
+// the end of the log (Next() may be called again once more records are appended)
+func (c *LogCursor) Next() (*Crate, error) {
+	record, err := c.NextRecord()
+	return record.Crate, err
+}
+
+// Read the next record, returning io.EOF if the cursor has reached the end of the log
+func (c *LogCursor) NextRecord() (LogRecord, error) {
+	record, next, err := c.log.ReadRecordAt(c.offset)
+	if err != nil {
+		return record, err
+	}
+	c.current, c.offset = c.offset, next
+	return record, nil
+}
+
+// Durably record that every record returned by Next() so far has been processed
+func (c *LogCursor) Commit() error {
+	if c.offset == c.committed {
+		return nil
+	}
+	if err := c.writeCheckpoint(c.offset, nil); err != nil {
+		return err
+	}
+	c.committed, c.partial = c.offset, nil
+	return nil
+}
+
+// Durably record that every record before the one last returned by Next() has been processed,
+// and that one as far as checkpoint (taken from a SliceReader or MapReader reading it).
+// The next cursor opened for the group returns that record from Next() again,
+// and Partial() then returns checkpoint so processing can resume inside it
+func (c *LogCursor) CommitPartial(checkpoint DecodeCheckpoint) error {
+	if c.current < 0 {
+		return errors.New("LiteCrate: CommitPartial() called before Next() returned a record")
+	}
+	if err := c.writeCheckpoint(c.current, &checkpoint); err != nil {
+		return err
+	}
+	c.committed, c.partial = c.current, &checkpoint
+	return nil
+}
+
+// Returns the checkpoint committed with CommitPartial() for the record last returned by Next(),
+// ok is false if that record has not been partly processed
+func (c *LogCursor) Partial() (checkpoint DecodeCheckpoint, ok bool) {
+	if c.partial == nil || c.current != c.committed {
+		return DecodeCheckpoint{}, false
+	}
+	return *c.partial, true
+}
+
+// Store offset and the partial checkpoint (if not nil) in the cursor's file
+func (c *LogCursor) writeCheckpoint(offset int64, partial *DecodeCheckpoint) error {
+	return writeFileAtomic(c.path(), encodeCheckpoint(c.group, offset, partial))
+}
+
+// Returns the contents of a cursor file, read back by readCheckpoint()
+func encodeCheckpoint(group string, offset int64, partial *DecodeCheckpoint) []byte {
+	checkpoint := NewCrate(uint64(len(group))+12, FlagAutoDouble)
+	checkpoint.WriteStringWithCounter(group)
+	checkpoint.WriteVarint(offset)
+	if partial != nil {
+		checkpoint.WriteSelfSerializer(partial)
+	}
+	return checkpoint.Data()
+}
+
+// Returns the offset of the next record Next() will read
+func (c *LogCursor) Offset() int64 {
+	return c.offset
+}
+
+// Returns the offset the group has committed up to
+func (c *LogCursor) Committed() int64 {
+	return c.committed
+}
+
+// Move the cursor back to the committed offset, so uncommitted records are read again
+func (c *LogCursor) Rewind() {
+	c.offset, c.current = c.committed, -1
+}
+
+// Cursor files written before partial commits existed simply end after the offset
+func readCheckpoint(data []byte) (group string, offset int64, partial *DecodeCheckpoint, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("LiteCrate: cursor file is corrupt")
+		}
+	}()
+	checkpoint := OpenCrate(data, FlagStatic)
+	group = checkpoint.ReadStringWithCounter()
+	offset, _ = checkpoint.ReadVarint()
+	if checkpoint.ReadsLeft() > 0 {
+		partial = new(DecodeCheckpoint)
+		checkpoint.ReadSelfSerializer(partial)
+	}
+	return group, offset, partial, nil
+}
+
+func (c *LogCursor) path() string {
+	return c.log.path + "." + c.group + ".cursor"
+}
+
+func validCursorGroup(group string) bool {
+	if len(group) == 0 || len(group) > 64 {
+		return false
+	}
+	for _, r := range group {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
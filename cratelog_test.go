@@ -0,0 +1,303 @@
+//go:build !litecrate_core && !litecrate_noos
+
+package litecrate_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+func appendString(t *testing.T, log *lite.CrateLog, str string) int64 {
+	crate := lite.NewCrate(8, lite.FlagDefault)
+	crate.WriteStringWithCounter(str)
+	offset, err := log.Append(crate)
+	if err != nil {
+		t.Fatalf("CrateLog.Append(%s) - FAIL: %v", str, err)
+	}
+	return offset
+}
+
+func nextString(t *testing.T, cursor *lite.LogCursor) string {
+	crate, err := cursor.Next()
+	if err != nil {
+		t.Fatalf("LogCursor.Next() - FAIL: %v", err)
+	}
+	return crate.ReadStringWithCounter()
+}
+
+func TestCrateLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.log")
+	log, err := lite.OpenCrateLog(path, lite.FlagStatic)
+	if err != nil {
+		t.Fatalf("OpenCrateLog() - FAIL: %v", err)
+	}
+	var offsets []int64
+	for _, str := range []string{"a", "bb", "ccc"} {
+		offsets = append(offsets, appendString(t, log, str))
+	}
+	if offsets[0] != 0 || offsets[1] != 4 || offsets[2] != 9 || log.Size() != 15 {
+		t.Errorf("CrateLog.Append() - FAIL: offsets %v, size %d", offsets, log.Size())
+	}
+	if _, err := log.Append(failedCrate()); err == nil || log.Size() != 15 {
+		t.Errorf("CrateLog.Append() - FAIL: appended failed crate (error %v, size %d)", err, log.Size())
+	}
+	crate, next, err := log.ReadAt(offsets[1])
+	if err != nil || crate.ReadStringWithCounter() != "bb" || next != offsets[2] {
+		t.Errorf("CrateLog.ReadAt() - FAIL: %v, next %d", err, next)
+	}
+	if _, _, err := log.ReadAt(log.Size()); err != io.EOF {
+		t.Errorf("CrateLog.ReadAt(end) - FAIL: %v", err)
+	}
+	if _, _, err := log.ReadAt(100); err == nil {
+		t.Errorf("CrateLog.ReadAt(past end) - FAIL: did not error")
+	}
+	log.Sync()
+	log.Close()
+
+	// A record torn by a crash is dropped when the log is reopened
+	file, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	file.Write([]byte{9, 1, 2})
+	file.Close()
+	log, err = lite.OpenCrateLog(path, lite.FlagStatic)
+	if err != nil || log.Size() != 15 {
+		t.Fatalf("OpenCrateLog(torn) - FAIL: %v, size %d", err, log.Size())
+	}
+	appendString(t, log, "dddd")
+	defer log.Close()
+	if crate, _, err := log.ReadAt(15); err != nil || crate.ReadStringWithCounter() != "dddd" {
+		t.Errorf("CrateLog.Append(after torn) - FAIL: %v", err)
+	}
+}
+
+func TestLogCursor(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.log")
+	log, _ := lite.OpenCrateLog(path, lite.FlagStatic)
+	for _, str := range []string{"a", "bb", "ccc"} {
+		appendString(t, log, str)
+	}
+	if _, err := log.Subscribe("../escape"); err == nil {
+		t.Errorf("CrateLog.Subscribe(invalid) - FAIL: did not error")
+	}
+	cursor, err := log.Subscribe("workers")
+	if err != nil {
+		t.Fatalf("CrateLog.Subscribe() - FAIL: %v", err)
+	}
+	if nextString(t, cursor) != "a" || nextString(t, cursor) != "bb" {
+		t.Fatalf("LogCursor.Next() - FAIL: wrong records")
+	}
+	if err := cursor.Commit(); err != nil || cursor.Committed() != 9 {
+		t.Fatalf("LogCursor.Commit() - FAIL: %v", err)
+	}
+	if nextString(t, cursor) != "ccc" {
+		t.Fatalf("LogCursor.Next() - FAIL: wrong record")
+	}
+	if _, err := cursor.Next(); err != io.EOF {
+		t.Errorf("LogCursor.Next(end) - FAIL: %v", err)
+	}
+	cursor.Rewind()
+	if cursor.Offset() != 9 || nextString(t, cursor) != "ccc" {
+		t.Errorf("LogCursor.Rewind() - FAIL: offset %d", cursor.Offset())
+	}
+	log.Close()
+
+	// After a restart the uncommitted record is delivered again, and other groups start from the beginning
+	log, _ = lite.OpenCrateLog(path, lite.FlagStatic)
+	defer log.Close()
+	resumed, err := log.Subscribe("workers")
+	if err != nil || resumed.Offset() != 9 || nextString(t, resumed) != "ccc" {
+		t.Errorf("CrateLog.Subscribe(resumed) - FAIL: %v", err)
+	}
+	other, _ := log.Subscribe("audit")
+	if other.Offset() != 0 || nextString(t, other) != "a" {
+		t.Errorf("CrateLog.Subscribe(other group) - FAIL: offset %d", other.Offset())
+	}
+	os.WriteFile(path+".audit.cursor", []byte{200}, 0o644)
+	if _, err := log.Subscribe("audit"); err == nil {
+		t.Errorf("CrateLog.Subscribe(corrupt cursor) - FAIL: did not error")
+	}
+}
+
+func TestLogCursorPartial(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "batches.log")
+	log, _ := lite.OpenCrateLog(path, lite.FlagStatic)
+	batch := lite.NewCrate(16, lite.FlagAutoDouble)
+	values := []uint16{10, 20, 30, 40}
+	batch.UseU16Slice(&values, lite.Write)
+	log.Append(batch)
+	appendString(t, log, "after")
+
+	cursor, _ := log.Subscribe("workers")
+	if _, ok := cursor.Partial(); ok {
+		t.Errorf("LogCursor.Partial() - FAIL: fresh cursor has a partial checkpoint")
+	}
+	if err := cursor.CommitPartial(lite.DecodeCheckpoint{}); err == nil {
+		t.Errorf("LogCursor.CommitPartial() - FAIL: did not error before Next()")
+	}
+	crate, _ := cursor.Next()
+	reader := lite.NewSliceReader(crate, crate.UseU16)
+	var val uint16
+	reader.Next(&val)
+	reader.Next(&val)
+	if err := cursor.CommitPartial(reader.Checkpoint()); err != nil || cursor.Committed() != 0 {
+		t.Fatalf("LogCursor.CommitPartial() - FAIL: %v, committed %d", err, cursor.Committed())
+	}
+	// The record is kept by compaction, so its checkpoint is too
+	if err := log.Compact(); err != nil {
+		t.Fatalf("CrateLog.Compact() - FAIL: %v", err)
+	}
+	log.Close()
+
+	// After a restart the same record is delivered again along with how far it was processed
+	log, _ = lite.OpenCrateLog(path, lite.FlagStatic)
+	defer log.Close()
+	cursor, _ = log.Subscribe("workers")
+	crate, _ = cursor.Next()
+	checkpoint, ok := cursor.Partial()
+	if !ok || checkpoint.Index != 2 {
+		t.Fatalf("LogCursor.Partial() - FAIL: %+v, %v", checkpoint, ok)
+	}
+	reader = lite.ResumeSliceReader(crate, checkpoint, crate.UseU16)
+	var rest []uint16
+	for reader.Next(&val) {
+		rest = append(rest, val)
+	}
+	if len(rest) != 2 || rest[0] != 30 || rest[1] != 40 {
+		t.Errorf("LogCursor.Partial() - FAIL: resumed with %v", rest)
+	}
+	if nextString(t, cursor) != "after" {
+		t.Fatalf("LogCursor.Next() - FAIL: wrong record")
+	}
+	if _, ok := cursor.Partial(); ok {
+		t.Errorf("LogCursor.Partial() - FAIL: checkpoint reported for a later record")
+	}
+	cursor.Commit()
+	if cursor, _ = log.Subscribe("workers"); cursor.Offset() != log.Size() {
+		t.Errorf("LogCursor.Commit() - FAIL: offset %d after committing everything", cursor.Offset())
+	}
+}
+
+func TestCrateLogCompact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.log")
+	log, _ := lite.OpenCrateLog(path, lite.FlagStatic)
+	defer func() { log.Close() }()
+	put := func(key string, val string) {
+		crate := lite.NewCrate(8, lite.FlagDefault)
+		crate.WriteStringWithCounter(val)
+		if _, err := log.AppendKeyed(key, crate); err != nil {
+			t.Fatalf("CrateLog.AppendKeyed(%s) - FAIL: %v", key, err)
+		}
+	}
+	put("color", "red")
+	appendString(t, log, "unkeyed")
+	put("size", "L")
+	put("color", "blue")
+	cursor, _ := log.Subscribe("reader")
+	for i := 0; i < 3; i += 1 {
+		cursor.Next()
+	}
+	cursor.Commit() // committed just before the second "color"
+	put("shape", "round")
+	log.Delete("size")
+	if record, _, err := log.ReadRecordAt(0); err != nil || !record.Keyed || record.Key != "color" || record.Crate.ReadStringWithCounter() != "red" {
+		t.Errorf("CrateLog.ReadRecordAt(keyed) - FAIL: %+v, %v", record, err)
+	}
+	before := log.Size()
+	if err := log.Compact(); err != nil {
+		t.Fatalf("CrateLog.Compact() - FAIL: %v", err)
+	}
+	if log.Size() >= before {
+		t.Errorf("CrateLog.Compact() - FAIL: size %d not smaller than %d", log.Size(), before)
+	}
+
+	var kept []string
+	for offset := int64(0); offset < log.Size(); {
+		record, next, err := log.ReadRecordAt(offset)
+		if err != nil {
+			t.Fatalf("CrateLog.ReadRecordAt(compacted) - FAIL: %v", err)
+		}
+		kept = append(kept, record.Key+"="+record.Crate.ReadStringWithCounter())
+		offset = next
+	}
+	if strings.Join(kept, ",") != "=unkeyed,color=blue,shape=round" {
+		t.Errorf("CrateLog.Compact() - FAIL: kept %v", kept)
+	}
+	resumed, err := log.Subscribe("reader")
+	if record, _ := resumed.NextRecord(); err != nil || record.Key != "color" {
+		t.Errorf("CrateLog.Compact() - FAIL: cursor not moved, next record %+v", record)
+	}
+
+	log.Delete("shape")
+	if record, _, _ := log.ReadRecordAt(log.Size() - 8); !record.Tombstone || record.Key != "shape" || record.Crate.ReadsLeft() != 0 {
+		t.Errorf("CrateLog.Delete() - FAIL: %+v", record)
+	}
+	log.Close()
+	log, err = lite.OpenCrateLog(path, lite.FlagStatic)
+	if err != nil {
+		t.Fatalf("OpenCrateLog(compacted) - FAIL: %v", err)
+	}
+	log.Compact()
+	if record, next, _ := log.ReadRecordAt(0); record.Keyed || next == log.Size() {
+		t.Errorf("CrateLog.Compact(reopened) - FAIL: %+v", record)
+	}
+}
+
+func TestCrateLogCompactCrash(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.log")
+	cursorPath := path + ".reader.cursor"
+	log, _ := lite.OpenCrateLog(path, lite.FlagStatic)
+	defer func() { log.Close() }()
+	for _, val := range []string{"red", "blue"} {
+		crate := lite.NewCrate(8, lite.FlagDefault)
+		crate.WriteStringWithCounter(val)
+		log.AppendKeyed("color", crate)
+	}
+	appendString(t, log, "unkeyed")
+	cursor, _ := log.Subscribe("reader")
+	cursor.Next()
+	cursor.Next()
+	cursor.Commit() // committed just before "unkeyed"
+	stale, _ := os.ReadFile(cursorPath)
+	if err := log.Compact(); err != nil {
+		t.Fatalf("CrateLog.Compact() - FAIL: %v", err)
+	}
+	moved, _ := os.ReadFile(cursorPath)
+	log.Close()
+
+	// A crash after the log was replaced but before the cursors were leaves the moved cursor staged
+	os.WriteFile(cursorPath+".compact", moved, 0o644)
+	os.WriteFile(cursorPath, stale, 0o644)
+	log, err := lite.OpenCrateLog(path, lite.FlagStatic)
+	if err != nil {
+		t.Fatalf("OpenCrateLog(crashed after replacing log) - FAIL: %v", err)
+	}
+	resumed, _ := log.Subscribe("reader")
+	if str := nextString(t, resumed); str != "unkeyed" {
+		t.Errorf("OpenCrateLog(crashed after replacing log) - FAIL: cursor resumed at %q", str)
+	}
+	if _, err := os.Stat(cursorPath + ".compact"); !os.IsNotExist(err) {
+		t.Errorf("OpenCrateLog(crashed after replacing log) - FAIL: staged cursor left behind")
+	}
+	log.Close()
+
+	// A crash before the log was replaced leaves the compacted log beside it, and the staged cursor is dropped
+	os.WriteFile(path+".compact", []byte{1, 2, 3}, 0o644)
+	os.WriteFile(cursorPath+".compact", stale, 0o644)
+	log, err = lite.OpenCrateLog(path, lite.FlagStatic)
+	if err != nil {
+		t.Fatalf("OpenCrateLog(crashed before replacing log) - FAIL: %v", err)
+	}
+	resumed, _ = log.Subscribe("reader")
+	if str := nextString(t, resumed); str != "unkeyed" {
+		t.Errorf("OpenCrateLog(crashed before replacing log) - FAIL: cursor resumed at %q", str)
+	}
+	for _, leftover := range []string{path + ".compact", cursorPath + ".compact"} {
+		if _, err := os.Stat(leftover); !os.IsNotExist(err) {
+			t.Errorf("OpenCrateLog(crashed before replacing log) - FAIL: %s left behind", filepath.Base(leftover))
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package litecrate
+
+/**************
+	DEDUP BYTES
+***************/
+
+// Each deduplicated []byte is preceded by a UVarint header:
+//	0 = nil
+//	even = payload of length (header / 2) - 1 follows inline
+//	odd = no payload follows, it is the same as the inline payload whose header begins
+//	      (header / 2) bytes before this header
+// Back-references never reach outside the innermost UseField(), UseFieldGroup() or UseLazy()
+// being written, and distances are relative, so they survive the value being shifted forward
+// when its length counter is inserted.
+
+// Discard next unread deduplicated bytes in crate
+func (c *Crate) DiscardBytesDedup() {
+	header, _ := c.ReadUVarint()
+	if header&1 == 0 && header != 0 {
+		c.DiscardN(header>>1 - 1)
+	}
+}
+
+// Return byte slice the payload of the next unread deduplicated bytes occupies,
+// which is earlier in the crate if it was written as a back-reference
+func (c *Crate) SliceBytesDedup() (slice []byte) {
+	start, length, _, _ := c.peekDedup(c.read)
+	return c.data[start : start+length : start+length]
+}
+
+// Write bytes to crate, writing only a back-reference if an identical payload
+// was already written with WriteBytesDedup() since the crate was last Reset()
+func (c *Crate) WriteBytesDedup(val []byte) {
+	if val == nil {
+		c.WriteUVarint(0)
+		return
+	}
+	if len(val) > 0 {
+		if pos, ok := c.dedup[string(val)]; ok && pos < c.write {
+			c.WriteUVarint((c.write-pos)<<1 | 1)
+			return
+		}
+		if c.dedup == nil {
+			c.dedup = make(map[string]uint64)
+		}
+		c.dedup[string(val)] = c.write
+	}
+	c.WriteUVarint((len64(val) + 1) << 1)
+	c.WriteBytes(val)
+}
+
+// Read next deduplicated bytes from crate
+func (c *Crate) ReadBytesDedup() (val []byte) {
+	start, length, isNil, headerLen := c.peekDedup(c.read)
+	if isNil {
+		c.read += headerLen
+		return nil
+	}
+	c.checkAlloc(length, 1)
+	val = make([]byte, length)
+	copy(val, c.data[start:start+length])
+	if start == c.read+headerLen {
+		c.read = start + length
+	} else {
+		c.read += headerLen
+	}
+	return val
+}
+
+// Read next deduplicated bytes from crate without advancing read index
+func (c *Crate) PeekBytesDedup() (val []byte) {
+	idx := c.read
+	val = c.ReadBytesDedup()
+	c.read = idx
+	return val
+}
+
+// Use the []byte pointed to by val according to mode (deduplicated):
+// Write = 'write val into crate', Read = 'read from crate into val',
+// Peek = 'read from crate into val without advancing index'
+// Slice = 'Return the slice the next unread val's payload occupies without altering val'
+func (c *Crate) UseBytesDedup(val *[]byte, mode UseMode) (sliceModeData []byte) {
+	switch mode {
+	case Write:
+		c.WriteBytesDedup(*val)
+	case Read:
+		*val = c.ReadBytesDedup()
+	case Peek:
+		*val = c.PeekBytesDedup()
+	case Discard:
+		c.DiscardBytesDedup()
+	case Slice:
+		sliceModeData = c.SliceBytesDedup()
+	default:
+		c.useCustomMode(val, mode, "UseBytesDedup")
+	}
+	return sliceModeData
+}
+
+// Finds where the payload of the deduplicated bytes whose header begins at pos is stored,
+// following a back-reference if there is one
+func (c *Crate) peekDedup(pos uint64) (start uint64, length uint64, isNil bool, headerLen uint64) {
+	idx := c.read
+	defer func() { c.read = idx }()
+	c.read = pos
+	header, headerLen := c.ReadUVarint()
+	if header == 0 {
+		return c.read, 0, true, headerLen
+	}
+	if header&1 == 1 {
+		distance := header >> 1
+		if distance > pos {
+			panic("LiteCrate: deduplicated bytes at " + intStr(pos) + " refer back " + intStr(distance) + " bytes, before the start of the crate")
+		}
+		c.read = pos - distance
+		header, _ = c.ReadUVarint()
+		if header&1 == 1 || header == 0 {
+			panic("LiteCrate: deduplicated bytes at " + intStr(pos) + " do not refer back to a payload")
+		}
+	}
+	length = header>>1 - 1
+	c.CheckRead(length)
+	if c.failed != nil {
+		return c.read, 0, true, headerLen
+	}
+	return c.read, length, false, headerLen
+}
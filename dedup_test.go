@@ -0,0 +1,84 @@
+package litecrate_test
+
+import (
+	"bytes"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+func TestBytesDedup(t *testing.T) {
+	blob := bytes.Repeat([]byte("log line "), 20)
+	payloads := [][]byte{blob, []byte("other"), nil, {}, blob, []byte("other"), blob}
+	crate := lite.NewCrate(16, lite.FlagAutoDouble)
+	plain := lite.NewCrate(16, lite.FlagAutoDouble)
+	for _, p := range payloads {
+		crate.WriteBytesDedup(p)
+		plain.WriteBytesWithCounter(p)
+	}
+	crate.WriteU8(42)
+	if crate.WriteIndex() > plain.WriteIndex()/2 {
+		t.Errorf("WriteBytesDedup() - FAIL: %d bytes is not much smaller than %d", crate.WriteIndex(), plain.WriteIndex())
+	}
+
+	for i, want := range payloads {
+		start := crate.ReadIndex()
+		var got []byte
+		crate.UseBytesDedup(&got, lite.Peek)
+		slice := crate.UseBytesDedup(&got, lite.Slice)
+		if crate.ReadIndex() != start || !bytes.Equal(got, want) || !bytes.Equal(slice, want) {
+			t.Errorf("PeekBytesDedup()/SliceBytesDedup() - FAIL: payload %d: %q / %q != %q", i, got, slice, want)
+		}
+		crate.UseBytesDedup(&got, lite.Read)
+		if !bytes.Equal(got, want) || (got == nil) != (want == nil) {
+			t.Errorf("ReadBytesDedup() - FAIL: payload %d: %q != %q", i, got, want)
+		}
+		if got != nil && len(got) > 0 {
+			got[0] ^= 0xFF
+		}
+	}
+	if crate.ReadU8() != 42 {
+		t.Errorf("ReadBytesDedup() - FAIL: did not consume whole crate")
+	}
+	crate.ResetReadIndex()
+	for range payloads {
+		crate.DiscardBytesDedup()
+	}
+	if crate.ReadU8() != 42 {
+		t.Errorf("DiscardBytesDedup() - FAIL: did not skip whole crate")
+	}
+
+	crate.Reset()
+	crate.WriteBytesDedup(blob)
+	crate.Reset()
+	crate.WriteBytesDedup(blob)
+	if crate.WriteIndex() != uint64(len(blob))+2 {
+		t.Errorf("WriteBytesDedup() - FAIL: referred to payload from before Reset()")
+	}
+
+	tagged := lite.NewCrate(16, lite.FlagAutoDouble|lite.FlagTaggedFields)
+	first, second := blob, blob
+	useGroup := func(mode lite.UseMode) {
+		tagged.UseFieldGroup(func(mode lite.UseMode) {
+			tagged.UseField(1, func(mode lite.UseMode) { tagged.UseBytesDedup(&first, mode) }, mode)
+			tagged.UseField(2, func(mode lite.UseMode) { tagged.UseBytesDedup(&second, mode) }, mode)
+		}, mode)
+	}
+	tagged.WriteBytesDedup(blob)
+	useGroup(lite.Write)
+	tagged.WriteBytesDedup(blob)
+	first, second = nil, nil
+	if got := tagged.ReadBytesDedup(); !bytes.Equal(got, blob) {
+		t.Errorf("ReadBytesDedup() - FAIL: wrong payload before field group")
+	}
+	useGroup(lite.Read)
+	if !bytes.Equal(first, blob) || !bytes.Equal(second, blob) {
+		t.Errorf("ReadBytesDedup() - FAIL: wrong payload inside field group")
+	}
+	if got := tagged.ReadBytesDedup(); !bytes.Equal(got, blob) {
+		t.Errorf("ReadBytesDedup() - FAIL: wrong payload after field group")
+	}
+	if catchPanic[error](func() { crate.UseBytesDedup(&first, lite.UseMode(255)) }) == nil {
+		t.Errorf("UseBytesDedup - FAIL: invalid mode did not panic")
+	}
+}
@@ -0,0 +1,41 @@
+package litecrate
+
+// Panicked (as *DepthError) when values are nested deeper than the limit set with SetMaxDepth()
+type DepthError struct {
+	MaxDepth uint64
+}
+
+func (e *DepthError) Error() string {
+	return "LiteCrate: exceeded max nesting depth of " + intStr(e.MaxDepth)
+}
+
+/**************
+	DEPTH
+***************/
+
+// Limit how deeply SelfSerializers, slices, maps, UseRef() and UseAny() values may be nested
+// inside each other, so maliciously deep crates cannot overflow the goroutine stack.
+// Exceeding the limit panics with a *DepthError. 0 = no limit (default)
+func (c *Crate) SetMaxDepth(n uint64) {
+	c.maxDepth = n
+}
+
+// Returns the limit set with SetMaxDepth(), 0 = no limit
+func (c *Crate) MaxDepth() uint64 {
+	return c.maxDepth
+}
+
+// Called before using a nested value, must be followed by leaveDepth() once it is used
+func (c *Crate) enterDepth() {
+	c.depth += 1
+	if c.maxDepth != 0 && c.depth > c.maxDepth {
+		c.depth = 0
+		panic(&DepthError{MaxDepth: c.maxDepth})
+	}
+}
+
+func (c *Crate) leaveDepth() {
+	if c.depth > 0 {
+		c.depth -= 1
+	}
+}
@@ -0,0 +1,125 @@
+package litecrate_test
+
+import (
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+type depthChain struct {
+	Child *depthChain
+}
+
+func (d *depthChain) UseSelf(crate *lite.Crate, mode lite.UseMode) {
+	hasChild := d.Child != nil
+	crate.UseBool(&hasChild, mode)
+	if !hasChild {
+		return
+	}
+	if d.Child == nil {
+		d.Child = &depthChain{}
+	}
+	crate.UseSelfSerializer(d.Child, mode)
+}
+
+type versionedDepthChain struct {
+	Child *versionedDepthChain
+}
+
+func (d *versionedDepthChain) UseSelfVersion(crate *lite.Crate, mode lite.UseMode, version uint32) {
+	hasChild := d.Child != nil
+	crate.UseBool(&hasChild, mode)
+	if !hasChild {
+		return
+	}
+	if d.Child == nil {
+		d.Child = &versionedDepthChain{}
+	}
+	crate.UseVersioned(d.Child, &version, mode)
+}
+
+func makeDepthChain(depth int) *depthChain {
+	root := &depthChain{}
+	for i := 1; i < depth; i += 1 {
+		root = &depthChain{Child: root}
+	}
+	return root
+}
+
+func TestMaxDepth(t *testing.T) {
+	crate := lite.NewCrate(16, lite.FlagAutoDouble)
+	crate.WriteSelfSerializer(makeDepthChain(100))
+	crate.WriteSelfSerializer(makeDepthChain(10))
+	crate.SetMaxDepth(50)
+	if crate.MaxDepth() != 50 {
+		t.Errorf("MaxDepth() - FAIL: %d != 50", crate.MaxDepth())
+	}
+	err := catchPanic[*lite.DepthError](func() { crate.ReadSelfSerializer(&depthChain{}) })
+	if err == nil || err.MaxDepth != 50 || err.Error() == "" {
+		t.Errorf("ReadSelfSerializer() - FAIL: 100 deep chain did not panic with *DepthError")
+	}
+	crate.SetReadIndex(100)
+	if catchPanic[*lite.DepthError](func() { crate.ReadSelfSerializer(&depthChain{}) }) != nil {
+		t.Errorf("ReadSelfSerializer() - FAIL: 10 deep chain panicked after recovering from a deeper one")
+	}
+	if catchPanic[*lite.DepthError](func() { crate.WriteSelfSerializer(makeDepthChain(51)) }) == nil {
+		t.Errorf("WriteSelfSerializer() - FAIL: 51 deep chain did not panic")
+	}
+	crate.Reset()
+	if catchPanic[*lite.DepthError](func() { crate.WriteSelfSerializer(makeDepthChain(50)) }) != nil {
+		t.Errorf("WriteSelfSerializer() - FAIL: 50 deep chain panicked")
+	}
+
+	crate.Reset()
+	nested := [][][]uint8{{{1, 2}, {3}}, nil}
+	useNested := func(mode lite.UseMode) {
+		lite.UseSlice(crate, mode, &nested, func(mid *[][]uint8, mode lite.UseMode) []byte {
+			return lite.UseSlice(crate, mode, mid, func(inner *[]uint8, mode lite.UseMode) []byte {
+				return lite.UseSlice(crate, mode, inner, crate.UseU8)
+			})
+		})
+	}
+	useNested(lite.Write)
+	crate.SetMaxDepth(2)
+	if catchPanic[*lite.DepthError](func() { useNested(lite.Discard) }) == nil {
+		t.Errorf("UseSlice() - FAIL: 3 deep slice did not panic with max depth 2")
+	}
+	crate.SetMaxDepth(3)
+	crate.ResetReadIndex()
+	nested = nil
+	if catchPanic[*lite.DepthError](func() { useNested(lite.Read) }) != nil || len(nested) != 2 || nested[0][0][1] != 2 {
+		t.Errorf("UseSlice() - FAIL: 3 deep slice not read with max depth 3")
+	}
+
+	crate.Reset()
+	crate.SetMaxDepth(5)
+	deepMap := map[string]map[string]uint8{"a": {"b": 1}}
+	if catchPanic[*lite.DepthError](func() { crate.UseAny(&deepMap, lite.Write) }) != nil {
+		t.Errorf("UseAny() - FAIL: shallow map panicked")
+	}
+	crate.SetMaxDepth(2)
+	if catchPanic[*lite.DepthError](func() { crate.UseAny(&deepMap, lite.Read) }) == nil {
+		t.Errorf("UseAny() - FAIL: nested map did not panic with max depth 2")
+	}
+}
+
+func TestMaxDepthVersioned(t *testing.T) {
+	chain := &versionedDepthChain{}
+	for i := 1; i < 100; i += 1 {
+		chain = &versionedDepthChain{Child: chain}
+	}
+	crate := lite.NewCrate(16, lite.FlagAutoDouble)
+	crate.WriteVersioned(chain, 1)
+	crate.SetMaxDepth(50)
+	if err := catchPanic[*lite.DepthError](func() { crate.ReadVersioned(&versionedDepthChain{}) }); err == nil || err.MaxDepth != 50 {
+		t.Errorf("ReadVersioned() - FAIL: 100 deep chain did not panic with *DepthError")
+	}
+	if catchPanic[*lite.DepthError](func() { crate.WriteVersioned(chain, 1) }) == nil {
+		t.Errorf("WriteVersioned() - FAIL: 100 deep chain did not panic with *DepthError")
+	}
+	crate.SetMaxDepth(100)
+	crate.ResetReadIndex()
+	if catchPanic[*lite.DepthError](func() { crate.ReadVersioned(&versionedDepthChain{}) }) != nil {
+		t.Errorf("ReadVersioned() - FAIL: 100 deep chain panicked with max depth 100")
+	}
+}
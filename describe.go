@@ -0,0 +1,77 @@
+//go:build !litecrate_core && !litecrate_notools
+
+package litecrate
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// Describes where one value read by a SelfSerializer lies in a crate, as returned by Describe()
+type FieldInfo struct {
+	Offset uint64 // Index of the value's first byte
+	Length uint64 // Number of bytes the value occupies
+	Type   string // The Use____() (or Read____()) method that read it, without its prefix ("U16", "StringWithCounter", "Slice"...)
+	Caller string // File name and line of the call that read it ("point.go:14")
+}
+
+/**************
+	DESCRIBE
+***************/
+
+// Describe the layout of the next unread val in the crate, without advancing the read index or modifying val:
+// val's UseSelf() method is run in Read mode on a new zero value of the type val points to,
+// and each call it makes to the crate is reported with the bytes it read, in order.
+// Values inside nested SelfSerializers are reported individually, values read by the same line of code
+// one after the other (in a loop, or the elements of a slice) are reported as one FieldInfo.
+//
+// If val cannot be read the fields read before the failure are returned with an error,
+// pointing at where the data stops matching val
+func (c *Crate) Describe(val SelfSerializer) (fields []FieldInfo, err error) {
+	if rv := reflect.ValueOf(val); rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		if scratch, ok := reflect.New(rv.Type().Elem()).Interface().(SelfSerializer); ok {
+			val = scratch
+		}
+	}
+	read, flags, trace, depth := c.read, c.flags, c.trace, c.depth
+	c.flags |= FlagTrace
+	c.trace = nil
+	defer func() {
+		r := recover()
+		fields = describeTrace(c.trace)
+		c.read, c.flags, c.trace, c.depth = read, flags, trace, depth
+		switch r := r.(type) {
+		case nil:
+		case string:
+			err = errors.New(r)
+		case error:
+			err = r
+		default:
+			panic(r)
+		}
+	}()
+	c.ReadSelfSerializer(val)
+	return fields, nil
+}
+
+// Merges the trace entries of each call into one field
+func describeTrace(trace []TraceEntry) (fields []FieldInfo) {
+	var site uintptr
+	for _, entry := range trace {
+		typ := entry.Method
+		for _, prefix := range []string{"Use", "Read", "Peek"} {
+			if strings.HasPrefix(typ, prefix) {
+				typ = typ[len(prefix):]
+				break
+			}
+		}
+		if n := len(fields); n > 0 && entry.site == site && fields[n-1].Type == typ && fields[n-1].Offset+fields[n-1].Length == entry.Offset {
+			fields[n-1].Length += entry.Length
+			continue
+		}
+		site = entry.site
+		fields = append(fields, FieldInfo{Offset: entry.Offset, Length: entry.Length, Type: typ, Caller: entry.Caller})
+	}
+	return fields
+}
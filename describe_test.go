@@ -0,0 +1,57 @@
+//go:build !litecrate_core && !litecrate_notools
+
+package litecrate_test
+
+import (
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+type describedOrder struct {
+	ID    uint16
+	Note  string
+	Where tracedPoint
+	Items []uint8
+}
+
+func (o *describedOrder) UseSelf(crate *lite.Crate, mode lite.UseMode) {
+	crate.UseU16(&o.ID, mode)
+	crate.UseStringWithCounter(&o.Note, mode)
+	crate.UseSelfSerializer(&o.Where, mode)
+	lite.UseSlice(crate, mode, &o.Items, crate.UseU8)
+}
+
+func TestDescribe(t *testing.T) {
+	order := describedOrder{ID: 7, Note: "hi", Where: tracedPoint{X: 1, Y: 2}, Items: []uint8{1, 2, 3}}
+	crate := lite.NewCrate(32, lite.FlagDefault)
+	crate.WriteU8(99)
+	crate.WriteSelfSerializer(&order)
+	crate.ReadU8()
+	var described describedOrder
+	fields, err := crate.Describe(&described)
+	expected := []lite.FieldInfo{
+		{Offset: 1, Length: 2, Type: "U16", Caller: "describe_test.go:19"},
+		{Offset: 3, Length: 3, Type: "StringWithCounter", Caller: "describe_test.go:20"},
+		{Offset: 6, Length: 2, Type: "I16", Caller: "trace_test.go:15"},
+		{Offset: 8, Length: 2, Type: "I16", Caller: "trace_test.go:16"},
+		{Offset: 10, Length: 4, Type: "Slice", Caller: "describe_test.go:22"},
+	}
+	if err != nil || len(fields) != len(expected) {
+		t.Fatalf("Describe() - FAIL: %v\n%v", err, fields)
+	}
+	for i := range expected {
+		if fields[i] != expected[i] {
+			t.Errorf("Describe() - FAIL: field %d is %+v, expected %+v", i, fields[i], expected[i])
+		}
+	}
+	if crate.ReadIndex() != 1 || described.ID != 0 || crate.WillTrace() || len(crate.Trace()) != 0 {
+		t.Errorf("Describe() - FAIL: crate or val modified")
+	}
+
+	truncated := lite.OpenCrate(crate.Data()[1:8], lite.FlagStatic)
+	fields, err = truncated.Describe(&described)
+	if err == nil || len(fields) != 4 || fields[3].Length != 2 {
+		t.Errorf("Describe(truncated) - FAIL: %v\n%v", err, fields)
+	}
+}
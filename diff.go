@@ -0,0 +1,179 @@
+//go:build !litecrate_core && !litecrate_notools
+
+package litecrate
+
+import (
+	"errors"
+	"hash/crc32"
+)
+
+const (
+	patchEnd    uint8 = 0 // no more operations
+	patchCopy   uint8 = 1 // Varint offset from the expected base position, UVarint length: copy bytes from base
+	patchInsert uint8 = 2 // bytes with counter: insert bytes that are not in base
+)
+
+const (
+	diffWindow     = 4  // shortest run of matching bytes Diff() copies from base instead of inserting
+	diffCandidates = 16 // most positions Diff() remembers for each 4 byte window of base
+)
+
+var (
+	ErrPatchBase   = errors.New("LiteCrate: patch was made from a different base") // ApplyPatch() was given a base other than the one passed to Diff()
+	errPatchRange  = errors.New("LiteCrate: patch copies bytes outside of base")
+	errPatchLength = errors.New("LiteCrate: patch does not produce its recorded length")
+	errPatchNil    = errors.New("LiteCrate: nil crate passed to Diff() or ApplyPatch()")
+)
+
+/**************
+	DIFF/PATCH
+***************/
+
+// Returns a patch that turns the written data of from into the written data of to when passed to ApplyPatch().
+// Works on any two byte sequences, but is compact when they are two encodings of similar values:
+// unchanged runs of 4 or more bytes (even if moved) are copied from from, and only the changed bytes are included.
+//
+// The patch holds the length and CRC-32 of from, the length of to, then a list of operations
+// (copies from from and inserted bytes) ending with a zero byte.
+// Returns an error if either crate is nil, or the error of one that failed under FlagNoPanic
+func Diff(from *Crate, to *Crate) (patch *Crate, err error) {
+	if from == nil || to == nil {
+		return nil, errPatchNil
+	}
+	if err := from.Err(); err != nil {
+		return nil, err
+	}
+	if err := to.Err(); err != nil {
+		return nil, err
+	}
+	base, target := from.Data(), to.Data()
+	patch = NewCrate(64, FlagAutoDouble)
+	patch.WriteUVarint(len64(base))
+	patch.WriteU32(crc32.ChecksumIEEE(base))
+	patch.WriteUVarint(len64(target))
+	index := make(map[uint32][]int)
+	for i := 0; i+diffWindow <= len(base); i += 1 {
+		key := diffKey(base[i:])
+		if len(index[key]) < diffCandidates {
+			index[key] = append(index[key], i)
+		}
+	}
+	expected := 0 // where in base the next byte of target is if it is unchanged
+	literal := 0  // start of the bytes of target not yet added to the patch
+	for i := 0; i < len(target); {
+		pos, length := expected, diffMatch(base, expected, target[i:])
+		if length < diffWindow && i+diffWindow <= len(target) {
+			for _, candidate := range index[diffKey(target[i:])] {
+				if n := diffMatch(base, candidate, target[i:]); n > length {
+					pos, length = candidate, n
+				}
+			}
+		}
+		if length < diffWindow {
+			i += 1
+			expected += 1
+			continue
+		}
+		if literal < i {
+			patch.WriteU8(patchInsert)
+			patch.WriteBytesWithCounter(target[literal:i])
+		}
+		patch.WriteU8(patchCopy)
+		patch.WriteVarint(int64(pos - expected))
+		patch.WriteUVarint(uint64(length))
+		i += length
+		expected = pos + length
+		literal = i
+	}
+	if literal < len(target) {
+		patch.WriteU8(patchInsert)
+		patch.WriteBytesWithCounter(target[literal:])
+	}
+	patch.WriteU8(patchEnd)
+	return patch, nil
+}
+
+// Returns a new crate (flagged with FlagDefault) holding the result of applying patch (made by Diff()) to base.
+// Returns ErrPatchBase if base is not the crate the patch was made from, or an error if patch is malformed.
+// The patch is read from its written data, so its read index is left where it was
+func ApplyPatch(base *Crate, patch *Crate) (result *Crate, err error) {
+	if base == nil || patch == nil {
+		return nil, errPatchNil
+	}
+	if err := base.Err(); err != nil {
+		return nil, err
+	}
+	if err := patch.Err(); err != nil {
+		return nil, err
+	}
+	defer func() {
+		switch r := recover().(type) {
+		case nil:
+		case string:
+			result, err = nil, errors.New(r)
+		case error:
+			result, err = nil, r
+		default:
+			panic(r)
+		}
+	}()
+	data := base.Data()
+	patch = OpenCrate(patch.Data(), FlagStatic)
+	baseLen, _ := patch.ReadUVarint()
+	if baseLen != len64(data) || patch.ReadU32() != crc32.ChecksumIEEE(data) {
+		return nil, ErrPatchBase
+	}
+	targetLen, _ := patch.ReadUVarint()
+	// A malformed patch could claim any length, so only trust it as far as the inputs could plausibly reach
+	size := targetLen
+	if limit := len64(data) + patch.ReadsLeft(); size > limit {
+		size = limit
+	}
+	result = NewCrate(size, FlagDefault)
+	expected := int64(0)
+	for {
+		switch op := patch.ReadU8(); op {
+		case patchEnd:
+			if result.write != targetLen {
+				return nil, errPatchLength
+			}
+			return result, nil
+		case patchCopy:
+			offset, _ := patch.ReadVarint()
+			length, _ := patch.ReadUVarint()
+			start := expected + offset
+			if start < 0 || length > len64(data) || uint64(start) > len64(data)-length {
+				return nil, errPatchRange
+			}
+			if length > targetLen-result.write {
+				return nil, errPatchLength
+			}
+			result.WriteBytes(data[start : uint64(start)+length])
+			expected = start + int64(length)
+		case patchInsert:
+			inserted := patch.ReadBytesWithCounter()
+			if len64(inserted) > targetLen-result.write {
+				return nil, errPatchLength
+			}
+			result.WriteBytes(inserted)
+			expected += int64(len(inserted))
+		default:
+			return nil, errors.New("LiteCrate: invalid patch operation " + intStr(op))
+		}
+	}
+}
+
+func diffKey(data []byte) uint32 {
+	return uint32(data[0]) | uint32(data[1])<<8 | uint32(data[2])<<16 | uint32(data[3])<<24
+}
+
+// Returns how many bytes at the start of target match base from pos onward
+func diffMatch(base []byte, pos int, target []byte) (length int) {
+	if pos < 0 || pos >= len(base) {
+		return 0
+	}
+	for length < len(target) && pos+length < len(base) && base[pos+length] == target[length] {
+		length += 1
+	}
+	return length
+}
@@ -0,0 +1,96 @@
+//go:build !litecrate_core && !litecrate_notools
+
+package litecrate_test
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+func diffRoundTrip(t *testing.T, name string, from []byte, to []byte) *lite.Crate {
+	patch, err := lite.Diff(lite.OpenCrate(from, lite.FlagStatic), lite.OpenCrate(to, lite.FlagStatic))
+	if err != nil {
+		t.Fatalf("Diff(%s) - FAIL: %v", name, err)
+	}
+	result, err := lite.ApplyPatch(lite.OpenCrate(from, lite.FlagStatic), patch)
+	if err != nil || !bytes.Equal(result.Data(), to) {
+		t.Errorf("ApplyPatch(%s) - FAIL: %v", name, err)
+	}
+	return patch
+}
+
+func TestDiff(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	random := make([]byte, 2000)
+	rng.Read(random)
+
+	before := lite.NewCrate(64, lite.FlagAutoDouble)
+	after := lite.NewCrate(64, lite.FlagAutoDouble)
+	people := make([]person, 50)
+	for i := range people {
+		people[i] = person{Age: uint8(i), Name: "person", Mood: int64(i) * 1000, Steps: uint32(i)}
+	}
+	lite.UseSlice(before, lite.Write, &people, func(p *person, mode lite.UseMode) []byte { return before.UseSelfSerializer(p, mode) })
+	people[10].Mood = -5
+	people[30].Name = "renamed person"
+	people = append(people, person{Name: "new"})
+	lite.UseSlice(after, lite.Write, &people, func(p *person, mode lite.UseMode) []byte { return after.UseSelfSerializer(p, mode) })
+	patch := diffRoundTrip(t, "edited records", before.Data(), after.Data())
+	if patch.WriteIndex() > 80 {
+		t.Errorf("Diff() - FAIL: patch of %d bytes for 3 edits to %d bytes", patch.WriteIndex(), after.WriteIndex())
+	}
+
+	diffRoundTrip(t, "empty", nil, nil)
+	diffRoundTrip(t, "from empty", nil, random[:100])
+	diffRoundTrip(t, "to empty", random[:100], nil)
+	diffRoundTrip(t, "unrelated", random[:1000], random[1000:])
+	diffRoundTrip(t, "prefix", random, append([]byte("prefix"), random...))
+	diffRoundTrip(t, "moved", random, append(append([]byte{}, random[1000:]...), random[:1000]...))
+	if patch := diffRoundTrip(t, "same", random, random); patch.WriteIndex() > 16 {
+		t.Errorf("Diff() - FAIL: patch of %d bytes for identical data", patch.WriteIndex())
+	}
+	diffRoundTrip(t, "repeated", bytes.Repeat([]byte{0}, 5000), bytes.Repeat([]byte{0}, 6000))
+
+	if _, err := lite.ApplyPatch(after, patch); err != lite.ErrPatchBase {
+		t.Errorf("ApplyPatch() - FAIL: %v != ErrPatchBase for wrong base", err)
+	}
+	patch.SetReadIndex(3)
+	if _, err := lite.ApplyPatch(before, patch); err != nil || patch.ReadIndex() != 3 {
+		t.Errorf("ApplyPatch() - FAIL: %v, moved the patch's read index to %d", err, patch.ReadIndex())
+	}
+	truncated := lite.OpenCrate(patch.Data()[:patch.WriteIndex()-2], lite.FlagStatic)
+	if _, err := lite.ApplyPatch(before, truncated); err == nil {
+		t.Errorf("ApplyPatch() - FAIL: truncated patch did not error")
+	}
+	bad := lite.NewCrate(16, lite.FlagAutoDouble)
+	bad.WriteUVarint(0)
+	bad.WriteU32(0)
+	bad.WriteUVarint(1 << 60)
+	bad.WriteU8(1)
+	bad.WriteVarint(0)
+	bad.WriteUVarint(1)
+	if _, err := lite.ApplyPatch(lite.NewCrate(0, lite.FlagStatic), bad); err == nil {
+		t.Errorf("ApplyPatch() - FAIL: copy outside base did not error")
+	}
+	if patch, err := lite.Diff(nil, after); patch != nil || err == nil {
+		t.Errorf("Diff() - FAIL: nil crate did not error")
+	}
+	if patch, err := lite.Diff(before, failedCrate()); patch != nil || err == nil {
+		t.Errorf("Diff() - FAIL: failed crate did not error")
+	}
+	if result, err := lite.ApplyPatch(before, failedCrate()); result != nil || err == nil {
+		t.Errorf("ApplyPatch() - FAIL: failed patch did not error")
+	}
+}
+
+func FuzzPatch(f *testing.F) {
+	f.Add([]byte("hello world, hello crate"), []byte("hello crate, hello world"))
+	f.Add([]byte{}, []byte{1, 2, 3})
+	f.Fuzz(func(t *testing.T, from []byte, to []byte) {
+		diffRoundTrip(t, "fuzz", from, to)
+		lite.ApplyPatch(lite.OpenCrate(from, lite.FlagStatic), lite.OpenCrate(to, lite.FlagStatic))
+	})
+}
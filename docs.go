@@ -0,0 +1,152 @@
+//go:build !litecrate_core && !litecrate_notools
+
+package litecrate
+
+import (
+	"html"
+	"strings"
+)
+
+// Output format of GenerateDocs()
+type DocFormat uint8
+
+const (
+	DocMarkdown DocFormat = 0 // A Markdown heading and table
+	DocHTML     DocFormat = 1 // An HTML fragment (<h2> and <table>) to embed in a page
+)
+
+/**************
+	DOCS
+***************/
+
+// One row of a generated layout table
+type docRow struct {
+	offset   string
+	name     string
+	kind     string
+	width    string
+	encoding string
+}
+
+// Returns documentation of the wire layout described by schema: one row per field in the order
+// they are written, with its byte offset (while every field before it has a fixed width),
+// its width in bytes and how it is encoded. Nested fields are named after their parent
+// ("owner.name", "tags[i]", "stock[key]" and "stock[value]"), and rows for slice and map elements
+// describe a single element, repeated as many times as the counter before them says.
+//
+// Generating protocol documentation from the same Schema used by ToJSON() and FromJSON()
+// keeps it from drifting from the implementation. Versioned messages (see WriteVersioned())
+// are documented with one schema per version
+func GenerateDocs(schema *Schema, format DocFormat) []byte {
+	var rows []docRow
+	offset, fixed := docFields(&rows, schema.Fields, "", 0, true)
+	size := intStr(offset) + " bytes"
+	if !fixed {
+		size = "variable, at least " + size
+	}
+	var out strings.Builder
+	switch format {
+	case DocHTML:
+		out.WriteString("<h2>" + html.EscapeString(schema.Name) + "</h2>\n<p>Size: " + html.EscapeString(size) + "</p>\n")
+		out.WriteString("<table>\n<tr><th>Offset</th><th>Field</th><th>Type</th><th>Width</th><th>Encoding</th></tr>\n")
+		for _, row := range rows {
+			out.WriteString("<tr>")
+			for _, cell := range [...]string{row.offset, row.name, row.kind, row.width, row.encoding} {
+				out.WriteString("<td>" + html.EscapeString(cell) + "</td>")
+			}
+			out.WriteString("</tr>\n")
+		}
+		out.WriteString("</table>\n")
+	default:
+		out.WriteString("## " + schema.Name + "\n\nSize: " + size + "\n\n")
+		out.WriteString("| Offset | Field | Type | Width | Encoding |\n|---|---|---|---|---|\n")
+		for _, row := range rows {
+			out.WriteString("|")
+			for _, cell := range [...]string{row.offset, "`" + row.name + "`", row.kind, row.width, row.encoding} {
+				out.WriteString(" " + strings.ReplaceAll(cell, "|", "\\|") + " |")
+			}
+			out.WriteString("\n")
+		}
+	}
+	return []byte(out.String())
+}
+
+// Appends the rows for fields, returning the minimum offset after them and whether it is exact
+func docFields(rows *[]docRow, fields []Field, prefix string, offset uint64, fixed bool) (uint64, bool) {
+	for i := range fields {
+		offset, fixed = docField(rows, &fields[i], prefix+fields[i].Name, offset, fixed)
+	}
+	return offset, fixed
+}
+
+func docField(rows *[]docRow, f *Field, name string, offset uint64, fixed bool) (uint64, bool) {
+	row := docRow{offset: "var", name: name, kind: f.Kind.String()}
+	if fixed {
+		row.offset = intStr(offset)
+	}
+	width := kindWidth(f.Kind)
+	switch f.Kind {
+	case KindBool:
+		row.encoding = "0 = false, 1 = true"
+	case KindU8, KindU16, KindU24, KindU32, KindU40, KindU48, KindU56, KindU64:
+		row.encoding = "little-endian unsigned integer"
+	case KindI8, KindI16, KindI24, KindI32, KindI40, KindI48, KindI56, KindI64:
+		row.encoding = "little-endian two's complement integer"
+	case KindF32, KindF64:
+		row.encoding = "little-endian IEEE 754 float"
+	case KindC64, KindC128:
+		row.encoding = "real part then imaginary part, each a little-endian IEEE 754 float"
+	case KindUVarint:
+		row.encoding = "uvarint: 7 bits per byte (least significant first) with the high bit set if another byte follows, the 9th byte holds 8 bits"
+	case KindVarint:
+		row.encoding = "zig-zag encoded signed integer (0, -1, 1, -2... = 0, 1, 2, 3...) written as a uvarint"
+	case KindString:
+		row.encoding = "length-or-nil counter (uvarint: 0 = nil, n+1 = length n) then n bytes of UTF-8"
+	case KindBytes:
+		row.encoding = "length-or-nil counter (uvarint: 0 = nil, n+1 = length n) then n bytes"
+		if len(f.Fields) > 0 {
+			row.encoding += ", holding a nested crate:"
+		}
+	case KindSlice:
+		row.encoding = "length-or-nil counter (uvarint: 0 = nil, n+1 = length n) then n elements:"
+	case KindMap:
+		row.encoding = "length-or-nil counter (uvarint: 0 = nil, n+1 = length n) then n key-value pairs:"
+	case KindStruct:
+		row.encoding = "fields written one after the other:"
+	default:
+		row.encoding = "unknown kind"
+	}
+	switch {
+	case width > 0:
+		row.width = intStr(width)
+	case f.Kind == KindStruct:
+		row.width = "-"
+	default:
+		row.width = "1-9"
+		if f.Kind >= KindString {
+			row.width += " + n"
+		}
+	}
+	*rows = append(*rows, row)
+	if width > 0 {
+		return offset + width, fixed
+	}
+	switch f.Kind {
+	case KindStruct:
+		return docFields(rows, f.Fields, name+".", offset, fixed)
+	case KindBytes:
+		docFields(rows, f.Fields, name+".", 0, true)
+	case KindSlice:
+		if f.Elem != nil {
+			docField(rows, f.Elem, name+"[i]", 0, false)
+		}
+	case KindMap:
+		if f.Key != nil {
+			docField(rows, f.Key, name+"[key]", 0, false)
+		}
+		if f.Elem != nil {
+			docField(rows, f.Elem, name+"[value]", 0, false)
+		}
+	}
+	return offset + 1, false
+}
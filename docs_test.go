@@ -0,0 +1,71 @@
+//go:build !litecrate_core && !litecrate_notools
+
+package litecrate_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+func TestGenerateDocs(t *testing.T) {
+	schema := &lite.Schema{Name: "Order", Fields: []lite.Field{
+		{Name: "id", Kind: lite.KindU40},
+		{Name: "paid", Kind: lite.KindBool},
+		{Name: "at", Kind: lite.KindStruct, Fields: []lite.Field{
+			{Name: "x", Kind: lite.KindF32},
+			{Name: "y", Kind: lite.KindC128},
+		}},
+		{Name: "note", Kind: lite.KindString},
+		{Name: "tags", Kind: lite.KindSlice, Elem: &lite.Field{Name: "tag", Kind: lite.KindString}},
+		{Name: "stock", Kind: lite.KindMap, Key: &lite.Field{Name: "sku", Kind: lite.KindU16}, Elem: &lite.Field{Name: "count", Kind: lite.KindVarint}},
+		{Name: "last", Kind: lite.KindI8},
+	}}
+	doc := string(lite.GenerateDocs(schema, lite.DocMarkdown))
+	for _, row := range []string{
+		"## Order\n\nSize: variable, at least 30 bytes\n",
+		"| 0 | `id` | U40 | 5 | little-endian unsigned integer |",
+		"| 5 | `paid` | Bool | 1 |",
+		"| 6 | `at` | Struct | - |",
+		"| 6 | `at.x` | F32 | 4 |",
+		"| 10 | `at.y` | C128 | 16 |",
+		"| 26 | `note` | String | 1-9 + n |",
+		"| var | `tags` | Slice | 1-9 + n |",
+		"| var | `tags[i]` | String | 1-9 + n |",
+		"| var | `stock[key]` | U16 | 2 |",
+		"| var | `stock[value]` | Varint | 1-9 |",
+		"| var | `last` | I8 | 1 |",
+	} {
+		if !strings.Contains(doc, row) {
+			t.Errorf("GenerateDocs() - FAIL: missing %q in:\n%s", row, doc)
+		}
+	}
+	if strings.Index(doc, "`at.y`") > strings.Index(doc, "`note`") || strings.Index(doc, "`stock[key]`") > strings.Index(doc, "`stock[value]`") {
+		t.Errorf("GenerateDocs() - FAIL: rows out of wire order:\n%s", doc)
+	}
+
+	fixed := &lite.Schema{Name: "<Point>", Fields: []lite.Field{{Name: "x", Kind: lite.KindI32}, {Name: "y", Kind: lite.KindI32}}}
+	page := string(lite.GenerateDocs(fixed, lite.DocHTML))
+	if !strings.HasPrefix(page, "<h2>&lt;Point&gt;</h2>\n<p>Size: 8 bytes</p>\n") || !strings.Contains(page, "<tr><td>4</td><td>y</td><td>I32</td><td>4</td>") {
+		t.Errorf("GenerateDocs(DocHTML) - FAIL:\n%s", page)
+	}
+}
+
+func TestFieldKindText(t *testing.T) {
+	data, err := json.Marshal(lite.Field{Name: "n", Kind: lite.KindUVarint})
+	if err != nil || !strings.Contains(string(data), `"Kind":"UVarint"`) {
+		t.Errorf("FieldKind.MarshalText() - FAIL: %s, %v", data, err)
+	}
+	var field lite.Field
+	if err := json.Unmarshal(data, &field); err != nil || field.Kind != lite.KindUVarint {
+		t.Errorf("FieldKind.UnmarshalText() - FAIL: %v, %v", field.Kind, err)
+	}
+	if err := json.Unmarshal([]byte(`{"Kind":"U12"}`), &field); err == nil {
+		t.Errorf("FieldKind.UnmarshalText() - FAIL: unknown kind did not error")
+	}
+	if _, err := json.Marshal(lite.Field{Kind: lite.FieldKind(200)}); err == nil {
+		t.Errorf("FieldKind.MarshalText() - FAIL: unknown kind did not error")
+	}
+}
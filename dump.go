@@ -0,0 +1,75 @@
+//go:build !litecrate_core && !litecrate_notools
+
+package litecrate
+
+import (
+	"encoding/base64"
+	"io"
+)
+
+// Bytes per line DumpHex() uses when bytesPerLine is 0 or less
+const DefaultDumpWidth = 16
+
+/**************
+	DUMP
+***************/
+
+// Write a hexdump of the crate's written data to w, bytesPerLine bytes to a line
+// (DefaultDumpWidth if 0 or less). Each line holds the offset of its first byte, the bytes in hex,
+// then the bytes as ASCII with unprintable bytes shown as '.'
+//
+// Example:
+//
+//	00000000  06 68 65 6c 6c 6f 2a 00  |.hello*.|
+func (c *Crate) DumpHex(w io.Writer, bytesPerLine int) error {
+	if bytesPerLine <= 0 {
+		bytesPerLine = DefaultDumpWidth
+	}
+	data := c.Data()
+	line := make([]byte, 0, 12+bytesPerLine*4+2)
+	for start := 0; start < len(data); start += bytesPerLine {
+		end := start + bytesPerLine
+		if end > len(data) {
+			end = len(data)
+		}
+		line = line[:0]
+		for shift := 28; shift >= 0; shift -= 4 {
+			line = append(line, hexDigits[(start>>shift)&0xF])
+		}
+		line = append(line, ' ')
+		for i := start; i < start+bytesPerLine; i += 1 {
+			if i < end {
+				line = append(line, ' ', hexDigits[data[i]>>4], hexDigits[data[i]&0xF])
+			} else {
+				line = append(line, "   "...)
+			}
+		}
+		line = append(line, "  |"...)
+		for _, b := range data[start:end] {
+			if b < ' ' || b > '~' {
+				b = '.'
+			}
+			line = append(line, b)
+		}
+		line = append(line, "|\n"...)
+		if _, err := w.Write(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Return the crate's written data as a standard base64 string
+func (c *Crate) EncodeBase64() string {
+	return base64.StdEncoding.EncodeToString(c.Data())
+}
+
+// Open a new crate holding the data decoded from the standard base64 string s
+// (as returned by EncodeBase64(), line breaks are ignored)
+func OpenCrateBase64(s string, flags uint8) (*Crate, error) {
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return OpenCrate(data, flags), nil
+}
@@ -0,0 +1,58 @@
+//go:build !litecrate_core && !litecrate_notools
+
+package litecrate_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+type failWriter struct{}
+
+func (failWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestDumpHex(t *testing.T) {
+	crate := lite.NewCrate(32, lite.FlagStatic)
+	crate.WriteStringWithCounter("hello")
+	crate.WriteU8(42)
+	crate.WriteU8(0)
+	var out strings.Builder
+	if err := crate.DumpHex(&out, 8); err != nil || out.String() != "00000000  06 68 65 6c 6c 6f 2a 00  |.hello*.|\n" {
+		t.Errorf("DumpHex(8) - FAIL: %v\n%s", err, out.String())
+	}
+	out.Reset()
+	crate.DumpHex(&out, 5)
+	expected := "00000000  06 68 65 6c 6c  |.hell|\n" +
+		"00000005  6f 2a 00        |o*.|\n"
+	if out.String() != expected {
+		t.Errorf("DumpHex(5) - FAIL:\n%s", out.String())
+	}
+	out.Reset()
+	lite.OpenCrate(make([]byte, 17), lite.FlagStatic).DumpHex(&out, 0)
+	if lines := strings.Split(out.String(), "\n"); len(lines) != 3 || !strings.HasPrefix(lines[1], "00000010  00 ") {
+		t.Errorf("DumpHex(0) - FAIL: default width not used\n%s", out.String())
+	}
+	if err := crate.DumpHex(failWriter{}, 0); err == nil {
+		t.Errorf("DumpHex() - FAIL: writer error not returned")
+	}
+}
+
+func TestBase64(t *testing.T) {
+	crate := lite.NewCrate(8, lite.FlagStatic)
+	crate.WriteU32(0xDEADBEEF)
+	crate.WriteStringWithCounter("hi")
+	encoded := crate.EncodeBase64()
+	opened, err := lite.OpenCrateBase64(encoded, lite.FlagStatic)
+	if err != nil || !bytes.Equal(opened.Data(), crate.Data()) || opened.ReadU32() != 0xDEADBEEF {
+		t.Errorf("OpenCrateBase64(%s) - FAIL: %v", encoded, err)
+	}
+	if _, err := lite.OpenCrateBase64("not base64!", lite.FlagStatic); err == nil {
+		t.Errorf("OpenCrateBase64() - FAIL: invalid input did not error")
+	}
+}
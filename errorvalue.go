@@ -0,0 +1,109 @@
+package litecrate
+
+import "errors"
+
+/**************
+	ERROR
+***************/
+
+// Implemented by errors that carry a numeric code, which WriteError() writes alongside the message
+type CodedError interface {
+	error
+	ErrorCode() uint64
+}
+
+// Error returned by ReadError(), holding the message and code (0 if it had none)
+// of the error that was written. The original error's type is not preserved
+type RemoteError struct {
+	Message string
+	Code    uint64
+}
+
+func (e *RemoteError) Error() string {
+	return e.Message
+}
+
+func (e *RemoteError) ErrorCode() uint64 {
+	return e.Code
+}
+
+// Reports whether target is a *RemoteError with the same non-zero code,
+// so errors.Is(err, ErrSomething) matches a received error by code alone
+func (e *RemoteError) Is(target error) bool {
+	t, ok := target.(*RemoteError)
+	return ok && t.Code != 0 && t.Code == e.Code
+}
+
+// Discard next unread error in crate
+func (c *Crate) DiscardError() {
+	if c.ReadBool() {
+		c.DiscardStringWithCounter()
+		c.DiscardUVarint()
+	}
+}
+
+// Return byte slice the next unread error occupies (including nil flag)
+func (c *Crate) SliceError() (slice []byte) {
+	idx := c.read
+	c.DiscardError()
+	end := c.read
+	c.read = idx
+	return c.data[idx:end:end]
+}
+
+// Write error to crate as a Bool flag that is false for nil,
+// followed (if not nil) by val.Error() as a string with counter and a UVarint code.
+// The code comes from the first CodedError in val's chain, or is 0 if there is none
+func (c *Crate) WriteError(val error) {
+	c.WriteBool(val != nil)
+	if val == nil {
+		return
+	}
+	var code uint64
+	var coded CodedError
+	if errors.As(val, &coded) {
+		code = coded.ErrorCode()
+	}
+	c.WriteStringWithCounter(val.Error())
+	c.WriteUVarint(code)
+}
+
+// Read next error from crate, either nil or a *RemoteError
+func (c *Crate) ReadError() (val error) {
+	if !c.ReadBool() {
+		return nil
+	}
+	message := c.ReadStringWithCounter()
+	code, _ := c.ReadUVarint()
+	return &RemoteError{Message: message, Code: code}
+}
+
+// Read next error from crate without advancing read index
+func (c *Crate) PeekError() (val error) {
+	idx := c.read
+	val = c.ReadError()
+	c.read = idx
+	return val
+}
+
+// Use the error pointed to by val according to mode:
+// Write = 'write val into crate', Read = 'read from crate into val',
+// Peek = 'read from crate into val without advancing index'
+// Slice = 'Return the slice the next unread val occupies without altering val'
+func (c *Crate) UseError(val *error, mode UseMode) (sliceModeData []byte) {
+	switch mode {
+	case Write:
+		c.WriteError(*val)
+	case Read:
+		*val = c.ReadError()
+	case Peek:
+		*val = c.PeekError()
+	case Discard:
+		c.DiscardError()
+	case Slice:
+		sliceModeData = c.SliceError()
+	default:
+		c.useCustomMode(val, mode, "UseError")
+	}
+	return sliceModeData
+}
@@ -0,0 +1,54 @@
+package litecrate_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+var errNotFound = &lite.RemoteError{Message: "not found", Code: 404}
+
+func TestError(t *testing.T) {
+	crate := lite.NewCrate(16, lite.FlagAutoDouble)
+	values := []error{nil, io.EOF, errNotFound, fmt.Errorf("loading user 7: %w", errNotFound)}
+	codes := []uint64{0, 0, 404, 404}
+	for i, val := range values {
+		crate.Reset()
+		crate.UseError(&val, lite.Write)
+		var peeked, read error
+		crate.UseError(&peeked, lite.Peek)
+		slice := crate.UseError(&read, lite.Slice)
+		crate.UseError(&read, lite.Read)
+		if uint64(len(slice)) != crate.WriteIndex() || crate.ReadsLeft() != 0 {
+			t.Errorf("ReadError(%v) - FAIL: slice of %d bytes, %d left", val, len(slice), crate.ReadsLeft())
+		}
+		if val == nil {
+			if read != nil || peeked != nil || crate.WriteIndex() != 1 {
+				t.Errorf("ReadError(nil) - FAIL: read %v, peeked %v", read, peeked)
+			}
+			continue
+		}
+		var remote *lite.RemoteError
+		if !errors.As(read, &remote) || remote.Message != val.Error() || remote.Code != codes[i] || peeked.Error() != val.Error() {
+			t.Errorf("ReadError(%v) - FAIL: read %#v", val, read)
+		}
+		if errors.Is(read, errNotFound) != (codes[i] == 404) {
+			t.Errorf("RemoteError.Is() - FAIL: errors.Is(%v, errNotFound) != %v", read, codes[i] == 404)
+		}
+		crate.ResetReadIndex()
+		crate.UseError(&read, lite.Discard)
+		if crate.ReadsLeft() != 0 {
+			t.Errorf("DiscardError(%v) - FAIL: %d bytes left", val, crate.ReadsLeft())
+		}
+	}
+	if errors.Is(&lite.RemoteError{Message: "a"}, &lite.RemoteError{Message: "a"}) {
+		t.Errorf("RemoteError.Is() - FAIL: errors without codes matched")
+	}
+	var val error
+	if catchPanic[error](func() { crate.UseError(&val, lite.UseMode(255)) }) == nil {
+		t.Errorf("UseError - FAIL: invalid mode did not panic")
+	}
+}
@@ -0,0 +1,164 @@
+// Command chat is a small chat server: every message a client sends is broadcast
+// to every connected client. Messages are tagged unions sent as frames with a Framer,
+// and the crates they are encoded into are recycled through a CratePool.
+//
+//	go run ./examples/chat
+//
+// starts a server and two demo clients that talk to each other
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"sync"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+const (
+	MsgJoin uint16 = 1
+	MsgChat uint16 = 2
+)
+
+// A message sent between client and server
+type Message struct {
+	Type uint16
+	From string
+	Text string
+}
+
+func (m *Message) UseSelf(crate *lite.Crate, mode lite.UseMode) {
+	crate.UseUnion(&m.Type, map[uint16]func(mode lite.UseMode){
+		MsgJoin: func(mode lite.UseMode) { crate.UseStringWithCounter(&m.From, mode) },
+		MsgChat: func(mode lite.UseMode) {
+			crate.UseStringWithCounter(&m.From, mode)
+			crate.UseStringWithCounter(&m.Text, mode)
+		},
+	}, mode)
+}
+
+var pool = lite.NewCratePool()
+
+// Encode msg into a pooled crate and send it as one frame
+func Send(framer *lite.Framer, conn io.Writer, msg *Message) error {
+	crate := pool.GetLabeled("message", lite.FlagAutoDouble)
+	defer pool.PutLabeled("message", crate)
+	crate.WriteSelfSerializer(msg)
+	return framer.WriteFrame(conn, crate)
+}
+
+// Receive the next frame and decode it into a Message
+func Receive(framer *lite.Framer, conn io.Reader) (msg Message, err error) {
+	crate, err := framer.ReadFrame(conn)
+	if err != nil {
+		return msg, err
+	}
+	defer framer.Pool.Put(crate)
+	crate.ReadSelfSerializer(&msg)
+	return msg, nil
+}
+
+// Broadcasts every message received from a client to all clients
+type Server struct {
+	framer  lite.Framer
+	mutex   sync.Mutex
+	clients map[net.Conn]bool
+}
+
+func NewServer() *Server {
+	return &Server{framer: lite.Framer{Pool: pool, MaxFrameSize: 4096}, clients: make(map[net.Conn]bool)}
+}
+
+// Accept clients from ln until it is closed
+func (s *Server) Serve(ln net.Listener) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+		s.mutex.Lock()
+		s.clients[conn] = true
+		s.mutex.Unlock()
+		go s.handle(conn)
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer func() {
+		s.mutex.Lock()
+		delete(s.clients, conn)
+		s.mutex.Unlock()
+		conn.Close()
+	}()
+	// A Framer reads one frame at a time, so each connection gets its own for reading
+	reader := lite.Framer{Pool: pool, MaxFrameSize: s.framer.MaxFrameSize}
+	for {
+		msg, err := Receive(&reader, conn)
+		if err != nil {
+			return
+		}
+		s.broadcast(&msg)
+	}
+}
+
+func (s *Server) broadcast(msg *Message) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for client := range s.clients {
+		Send(&s.framer, client, msg)
+	}
+}
+
+// Send a join message for name and wait for the server to echo it back,
+// after which conn receives every message broadcast
+func Join(framer *lite.Framer, conn net.Conn, name string) error {
+	if err := Send(framer, conn, &Message{Type: MsgJoin, From: name}); err != nil {
+		return err
+	}
+	for {
+		msg, err := Receive(framer, conn)
+		if err != nil {
+			return err
+		}
+		if msg.Type == MsgJoin && msg.From == name {
+			return nil
+		}
+	}
+}
+
+func main() {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		log.Fatal(err)
+	}
+	go NewServer().Serve(ln)
+
+	framer := lite.Framer{Pool: pool}
+	var conns []net.Conn
+	for _, name := range []string{"alice", "bob"} {
+		conn, err := net.Dial("tcp", ln.Addr().String())
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer conn.Close()
+		if err := Join(&framer, conn, name); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("%s joined\n", name)
+		conns = append(conns, conn)
+	}
+	Send(&framer, conns[0], &Message{Type: MsgChat, From: "alice", Text: "hi bob"})
+	Send(&framer, conns[1], &Message{Type: MsgChat, From: "bob", Text: "hi alice"})
+	for received := 0; received < 2; {
+		msg, err := Receive(&framer, conns[1])
+		if err != nil {
+			log.Fatal(err)
+		}
+		if msg.Type == MsgChat {
+			fmt.Printf("%s: %s\n", msg.From, msg.Text)
+			received += 1
+		}
+	}
+}
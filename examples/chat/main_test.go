@@ -0,0 +1,48 @@
+package main
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+func TestChat(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen on loopback: %v", err)
+	}
+	defer ln.Close()
+	go NewServer().Serve(ln)
+
+	framer := lite.Framer{Pool: pool}
+	var conns []net.Conn
+	for _, name := range []string{"alice", "bob", "carol"} {
+		conn, err := net.Dial("tcp", ln.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		conns = append(conns, conn)
+		if err := Join(&framer, conn, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	Send(&framer, conns[0], &Message{Type: MsgChat, From: "alice", Text: "hello everyone"})
+	for i, conn := range conns {
+		for {
+			msg, err := Receive(&framer, conn)
+			if err != nil {
+				t.Fatalf("client %d: %v", i, err)
+			}
+			if msg.Type == MsgChat {
+				if msg.From != "alice" || msg.Text != "hello everyone" {
+					t.Errorf("client %d received %+v", i, msg)
+				}
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,107 @@
+//go:build !litecrate_core && !litecrate_nocompress
+
+// Command filesync copies a file over a TCP connection. The file is streamed into the frame
+// as a chunked blob without knowing its length in advance, followed by its SHA-256 hash,
+// and the whole frame is compressed with Snappy.
+//
+//	go run ./examples/filesync [file]
+//
+// sends file (or this program's source) to a receiver on the same machine and checks it arrived intact
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+var framer = lite.Framer{MaxFrameSize: 256 << 20}
+
+// Send the contents of r, under name, to conn as one compressed frame
+func SendFile(conn io.Writer, name string, r io.Reader) error {
+	crate := lite.NewCrate(4096, lite.FlagAutoDouble)
+	crate.WriteStringWithCounter(name)
+	hash := sha256.New()
+	stream := crate.BeginBlobStream()
+	if _, err := io.Copy(io.MultiWriter(stream, hash), r); err != nil {
+		return err
+	}
+	stream.Close()
+	var sum [32]byte
+	hash.Sum(sum[:0])
+	crate.WriteBytes32(sum)
+	if err := crate.Compress(lite.Snappy); err != nil {
+		return err
+	}
+	return framer.WriteFrame(conn, crate)
+}
+
+// Receive a file sent with SendFile() from conn, writing its contents to w
+func ReceiveFile(conn io.Reader, w io.Writer) (name string, err error) {
+	crate, err := framer.ReadFrame(conn)
+	if err != nil {
+		return "", err
+	}
+	if err = crate.Decompress(lite.Snappy); err != nil {
+		return "", err
+	}
+	name = crate.ReadStringWithCounter()
+	hash := sha256.New()
+	if _, err = io.Copy(io.MultiWriter(w, hash), crate.BlobStreamReader()); err != nil {
+		return name, err
+	}
+	var sum [32]byte
+	if hash.Sum(sum[:0]); sum != crate.ReadBytes32() {
+		return name, errors.New("filesync: hash of " + name + " does not match")
+	}
+	return name, nil
+}
+
+func main() {
+	path := "examples/filesync/main.go"
+	if len(os.Args) > 1 {
+		path = os.Args[1]
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer ln.Close()
+
+	received := make(chan error, 1)
+	var copied bytes.Buffer
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			received <- err
+			return
+		}
+		defer conn.Close()
+		name, err := ReceiveFile(conn, &copied)
+		fmt.Printf("received %s (%d bytes)\n", name, copied.Len())
+		received <- err
+	}()
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer conn.Close()
+	if err := SendFile(conn, path, file); err != nil {
+		log.Fatal(err)
+	}
+	if err := <-received; err != nil {
+		log.Fatal(err)
+	}
+}
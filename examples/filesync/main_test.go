@@ -0,0 +1,66 @@
+//go:build !litecrate_core && !litecrate_nocompress
+
+package main
+
+import (
+	"bytes"
+	"math/rand"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestFileSync(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen on loopback: %v", err)
+	}
+	defer ln.Close()
+	contents := bytes.Repeat([]byte("some compressible text "), 20000)
+	random := make([]byte, 100000)
+	rand.New(rand.NewSource(1)).Read(random)
+	contents = append(contents, random...)
+
+	type result struct {
+		name string
+		data []byte
+		err  error
+	}
+	received := make(chan result, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			received <- result{err: err}
+			return
+		}
+		defer conn.Close()
+		var out bytes.Buffer
+		name, err := ReceiveFile(conn, &out)
+		received <- result{name, out.Bytes(), err}
+	}()
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	if err := SendFile(conn, "data.bin", bytes.NewReader(contents)); err != nil {
+		t.Fatal(err)
+	}
+	got := <-received
+	if got.err != nil || got.name != "data.bin" || !bytes.Equal(got.data, contents) {
+		t.Errorf("ReceiveFile() - FAIL: %q, %d bytes, err = %v", got.name, len(got.data), got.err)
+	}
+}
+
+func TestFileSyncCorrupt(t *testing.T) {
+	var conn bytes.Buffer
+	if err := SendFile(&conn, "a", bytes.NewReader([]byte("hello"))); err != nil {
+		t.Fatal(err)
+	}
+	data := conn.Bytes()
+	data[len(data)-1] ^= 0xFF
+	if _, err := ReceiveFile(&conn, &bytes.Buffer{}); err == nil {
+		t.Errorf("ReceiveFile() - FAIL: corrupted frame accepted")
+	}
+}
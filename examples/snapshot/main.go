@@ -0,0 +1,146 @@
+// Command snapshot streams game world snapshots from a server to a client.
+// Entities refer to each other with UseRef() so shared targets stay shared,
+// positions are fixed size arrays, and the client decodes snapshots with a
+// StreamDecoder as bytes arrive, however the stream happens to be split.
+//
+//	go run ./examples/snapshot
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+// A player or monster in the world
+type Entity struct {
+	ID       uint32
+	Name     string
+	Position [3]float32
+	Health   uint8
+	Target   *Entity
+}
+
+func (e *Entity) UseSelf(crate *lite.Crate, mode lite.UseMode) {
+	crate.UseU32(&e.ID, mode)
+	crate.UseStringWithCounter(&e.Name, mode)
+	lite.UseArray(crate, mode, e.Position[:], crate.UseF32)
+	crate.UseU8(&e.Health, mode)
+	lite.UseRef(crate, mode, &e.Target, (*Entity).UseSelf)
+}
+
+// The state of the world at one tick
+type Snapshot struct {
+	Tick     uint64
+	Entities []*Entity
+}
+
+func (s *Snapshot) UseSelf(crate *lite.Crate, mode lite.UseMode) {
+	crate.UseUVarint(&s.Tick, mode)
+	lite.UseSlice(crate, mode, &s.Entities, func(entity **Entity, mode lite.UseMode) []byte {
+		return lite.UseRef(crate, mode, entity, (*Entity).UseSelf)
+	})
+}
+
+var pool = lite.NewCratePool()
+
+// Advance the world by one tick: monsters chase their target
+func (s *Snapshot) Step() {
+	s.Tick += 1
+	for _, e := range s.Entities {
+		if e.Target != nil {
+			for i := range e.Position {
+				e.Position[i] += (e.Target.Position[i] - e.Position[i]) / 4
+			}
+		}
+	}
+}
+
+// Write ticks snapshots of world to w, in writes of at most chunk bytes
+func Serve(w io.Writer, world *Snapshot, ticks int, chunk int) error {
+	for i := 0; i < ticks; i += 1 {
+		world.Step()
+		crate := pool.GetLabeled("snapshot", lite.FlagAutoDouble)
+		crate.WriteSelfSerializer(world)
+		for data := crate.Data(); len(data) > 0; {
+			n := chunk
+			if n > len(data) {
+				n = len(data)
+			}
+			if _, err := w.Write(data[:n]); err != nil {
+				return err
+			}
+			data = data[n:]
+		}
+		pool.PutLabeled("snapshot", crate)
+	}
+	return nil
+}
+
+// Read snapshots from r as they arrive, calling handle for each one, until r ends
+func Watch(r io.Reader, handle func(snap *Snapshot)) error {
+	var decoder lite.StreamDecoder
+	buf := make([]byte, 512)
+	for {
+		n, err := r.Read(buf)
+		decoder.Feed(buf[:n])
+		for {
+			var snap Snapshot
+			ok, decodeErr := decoder.Next(&snap)
+			if decodeErr != nil {
+				return decodeErr
+			}
+			if !ok {
+				break
+			}
+			handle(&snap)
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Returns a small world with a player chased by two monsters
+func NewWorld() *Snapshot {
+	player := &Entity{ID: 1, Name: "player", Position: [3]float32{100, 0, 100}, Health: 100}
+	return &Snapshot{Entities: []*Entity{
+		player,
+		{ID: 2, Name: "goblin", Position: [3]float32{0, 0, 0}, Health: 30, Target: player},
+		{ID: 3, Name: "troll", Position: [3]float32{200, 0, 0}, Health: 80, Target: player},
+	}}
+}
+
+func main() {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		Serve(conn, NewWorld(), 5, 7)
+	}()
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer conn.Close()
+	err = Watch(conn, func(snap *Snapshot) {
+		goblin := snap.Entities[1]
+		fmt.Printf("tick %d: goblin at %v chasing %s\n", snap.Tick, goblin.Position, goblin.Target.Name)
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
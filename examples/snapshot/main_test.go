@@ -0,0 +1,56 @@
+package main
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestSnapshot(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen on loopback: %v", err)
+	}
+	defer ln.Close()
+	for _, chunk := range []int{1, 13, 4096} {
+		served := make(chan error, 1)
+		go func() {
+			conn, err := ln.Accept()
+			if err != nil {
+				served <- err
+				return
+			}
+			defer conn.Close()
+			served <- Serve(conn, NewWorld(), 20, chunk)
+		}()
+		conn, err := net.Dial("tcp", ln.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn.SetDeadline(time.Now().Add(10 * time.Second))
+		expected := NewWorld()
+		ticks := 0
+		err = Watch(conn, func(snap *Snapshot) {
+			expected.Step()
+			ticks += 1
+			if snap.Tick != expected.Tick || len(snap.Entities) != 3 {
+				t.Fatalf("chunk %d: tick %d != %d", chunk, snap.Tick, expected.Tick)
+			}
+			for i, e := range snap.Entities {
+				if e.Name != expected.Entities[i].Name || e.Position != expected.Entities[i].Position {
+					t.Errorf("chunk %d, tick %d: entity %+v != %+v", chunk, snap.Tick, e, expected.Entities[i])
+				}
+			}
+			if snap.Entities[1].Target != snap.Entities[0] || snap.Entities[2].Target != snap.Entities[0] {
+				t.Errorf("chunk %d, tick %d: targets are not shared with the player entity", chunk, snap.Tick)
+			}
+		})
+		conn.Close()
+		if err != nil || ticks != 20 {
+			t.Errorf("chunk %d: Watch() returned %v after %d ticks", chunk, err, ticks)
+		}
+		if err := <-served; err != nil {
+			t.Errorf("chunk %d: Serve() returned %v", chunk, err)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package litecrate
+
+// Bounds of the field group currently being read from a crate flagged with FlagTaggedFields
+type fieldGroup struct {
+	start  uint64
+	end    uint64
+	active bool
+}
+
+/**************
+	FIELDS
+***************/
+
+// Use a single struct field identified by id according to mode.
+//
+// If the crate is not flagged with FlagTaggedFields, this simply calls useValue(mode)
+// and nothing extra is written. Otherwise the id (2 bytes) and a length counter are written
+// before the value, and when reading the field is located by its id. Inside UseFieldGroup()
+// fields may be read in any order, fields the reader does not ask for are skipped,
+// and fields that were never written are reported with found = false (useValue is not called,
+// so the value keeps whatever default it had).
+//
+// Write = 'write the field into crate', Read = 'find the field and read it',
+// Peek = 'find the field and read it without advancing index'
+// Slice = 'Return the slice the field's value occupies'
+func (c *Crate) UseField(id uint16, useValue func(mode UseMode), mode UseMode) (found bool, sliceModeData []byte) {
+	if mode >= firstCustomMode {
+		c.useCustomMode(&id, mode, "UseField")
+		useValue(mode)
+		return true, nil
+	}
+	if !c.WillTagFields() {
+		return true, c.useUntagged(useValue, mode)
+	}
+	switch mode {
+	case Write:
+		c.WriteU16(id)
+		start := c.beginLength()
+		useValue(Write)
+		c.insertLength(start)
+	case Read, Peek, Slice:
+		valStart, valEnd, found := c.findField(id)
+		if !found || c.failed != nil {
+			return false, nil
+		}
+		if mode == Slice {
+			return true, c.data[valStart:valEnd:valEnd]
+		}
+		idx := c.read
+		c.read = valStart
+		useValue(Read)
+		if c.read != valEnd {
+			panic("LiteCrate: field " + intStr(id) + " used " + intStr(c.read-valStart) + " bytes but was written with " + intStr(valEnd-valStart))
+		}
+		if mode == Peek || c.group.active {
+			c.read = idx
+		}
+	case Discard:
+		if !c.group.active {
+			c.DiscardU16()
+			length, _ := c.ReadLength()
+			c.DiscardN(length)
+		}
+	default:
+		panic("LiteCrate: Invalid mode passed to UseField()")
+	}
+	return true, nil
+}
+
+// Use a group of fields (usually all the fields of one struct) according to mode.
+//
+// If the crate is not flagged with FlagTaggedFields, this simply calls useFields(mode)
+// and nothing extra is written. Otherwise a length counter is written before the group,
+// allowing the UseField() calls inside useFields to read fields in any order and
+// to skip fields they do not know about. Every value inside a group must be used through UseField()
+//
+// Write = 'write the group into crate', Read = 'read the group',
+// Peek = 'read the group without advancing index'
+// Slice = 'Return the slice the group occupies (not including counter)'
+func (c *Crate) UseFieldGroup(useFields func(mode UseMode), mode UseMode) (sliceModeData []byte) {
+	if mode >= firstCustomMode {
+		c.useCustomMode(nil, mode, "UseFieldGroup")
+		useFields(mode)
+		return nil
+	}
+	if !c.WillTagFields() {
+		return c.useUntagged(useFields, mode)
+	}
+	switch mode {
+	case Write:
+		start := c.beginLength()
+		useFields(Write)
+		c.insertLength(start)
+	case Read, Peek:
+		idx := c.read
+		length, _ := c.ReadLength()
+		c.CheckRead(length)
+		if c.failed != nil {
+			return nil
+		}
+		outer := c.group
+		c.group = fieldGroup{start: c.read, end: c.read + length, active: true}
+		useFields(Read)
+		c.read = c.group.end
+		c.group = outer
+		if mode == Peek {
+			c.read = idx
+		}
+	case Discard:
+		length, _ := c.ReadLength()
+		c.DiscardN(length)
+	case Slice:
+		length, n := c.PeekLength()
+		c.CheckRead(n + length)
+		if c.failed != nil {
+			return nil
+		}
+		return c.data[c.read+n : c.read+n+length : c.read+n+length]
+	default:
+		panic("LiteCrate: Invalid mode passed to UseFieldGroup()")
+	}
+	return nil
+}
+
+func (c *Crate) useUntagged(use func(mode UseMode), mode UseMode) (sliceModeData []byte) {
+	if mode != Slice {
+		use(mode)
+		return nil
+	}
+	start := c.read
+	use(Discard)
+	end := c.read
+	c.read = start
+	return c.data[start:end:end]
+}
+
+// Finds where the value of field id begins and ends, searching the whole group if
+// inside one, or only the next unread field if not
+func (c *Crate) findField(id uint16) (valStart uint64, valEnd uint64, found bool) {
+	if !c.group.active {
+		fieldID, valStart, valEnd := c.peekFieldHeader(c.read)
+		if fieldID != id {
+			panic("LiteCrate: expected field " + intStr(id) + " but found field " + intStr(fieldID) + " (read fields inside UseFieldGroup() to use them out of order)")
+		}
+		return valStart, valEnd, true
+	}
+	for pos := c.group.start; pos < c.group.end; pos = valEnd {
+		var fieldID uint16
+		fieldID, valStart, valEnd = c.peekFieldHeader(pos)
+		if valEnd > c.group.end {
+			panic("LiteCrate: field " + intStr(fieldID) + " extends past the end of its group")
+		}
+		if fieldID == id {
+			return valStart, valEnd, true
+		}
+	}
+	return 0, 0, false
+}
+
+func (c *Crate) peekFieldHeader(pos uint64) (id uint16, valStart uint64, valEnd uint64) {
+	idx := c.read
+	c.read = pos
+	id = c.ReadU16()
+	length, _ := c.ReadLength()
+	c.CheckRead(length)
+	valStart = c.read
+	c.read = idx
+	return id, valStart, valStart + length
+}
+
+// Returns the write index to pass to insertLength() once the value is written,
+// and stops WriteBytesDedup() from referring back to payloads written before it,
+// as back-references cannot reach across the counter insertLength() inserts
+func (c *Crate) beginLength() (start uint64) {
+	c.dedup = nil
+	return c.write
+}
+
+// Moves everything written since start forward to make room for,
+// then writes, a length counter at start holding its byte length
+func (c *Crate) insertLength(start uint64) {
+	length := c.write - start
+	n := findUVarintBytesFromValue(length + 1)
+	if !c.makeRoom(start, n) {
+		return
+	}
+	end := c.write
+	c.write = start
+	c.WriteLength(length)
+	c.write = end
+}
+
+// Moves everything written since start forward n bytes, advancing the write index past it, and invalidates
+// everything that referred to the moved bytes (slices, the cached key and deduplicated payloads).
+// Returns false without moving anything if the crate has failed under FlagNoPanic, or fails making room
+func (c *Crate) makeRoom(start uint64, n uint64) (ok bool) {
+	if c.failed != nil {
+		return false
+	}
+	c.CheckWrite(n)
+	if c.failed != nil {
+		return false
+	}
+	copy(c.data[start+n:c.write+n], c.data[start:c.write])
+	c.write += n
+	c.gen += 1
+	c.keyOK = false
+	c.dedup = nil
+	return true
+}
+
+// Use the space a removed field of size bytes used to occupy, so a struct's UseSelf()
+// stays compatible with crates written before the field was removed:
+// Write = 'write size zero bytes (as field id if flagged with FlagTaggedFields) for older readers',
+// Read/Peek/Discard = 'skip the removed field's bytes', Slice = 'Return the slice the removed field occupies'.
+//
+// For removed fields of variable size, use UseField() with the field's original Use____() method
+// and a throwaway variable instead
+func (c *Crate) UseDeprecated(id uint16, size uint64, mode UseMode) (sliceModeData []byte) {
+	_, sliceModeData = c.UseField(id, func(mode UseMode) {
+		switch mode {
+		case Write:
+			c.CheckWrite(size)
+			if c.failed != nil {
+				return
+			}
+			zeros := c.data[c.write : c.write+size]
+			for i := range zeros {
+				zeros[i] = 0
+			}
+			c.write += size
+		case Read, Discard:
+			c.CheckRead(size)
+			c.read += size
+		case Peek:
+			c.CheckRead(size)
+		}
+	}, mode)
+	return sliceModeData
+}
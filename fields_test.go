@@ -0,0 +1,134 @@
+package litecrate_test
+
+import (
+	"bytes"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+type accountV1 struct {
+	ID   uint64
+	Name string
+}
+
+func (a *accountV1) UseSelf(crate *lite.Crate, mode lite.UseMode) {
+	crate.UseFieldGroup(func(mode lite.UseMode) {
+		crate.UseField(2, func(mode lite.UseMode) { crate.UseStringWithCounter(&a.Name, mode) }, mode)
+		crate.UseField(1, func(mode lite.UseMode) { crate.UseUVarint(&a.ID, mode) }, mode)
+	}, mode)
+}
+
+type accountV2 struct {
+	ID      uint64
+	Name    string
+	Balance int64
+	Friends []accountV1
+}
+
+func (a *accountV2) UseSelf(crate *lite.Crate, mode lite.UseMode) {
+	crate.UseFieldGroup(func(mode lite.UseMode) {
+		crate.UseField(1, func(mode lite.UseMode) { crate.UseUVarint(&a.ID, mode) }, mode)
+		crate.UseField(2, func(mode lite.UseMode) { crate.UseStringWithCounter(&a.Name, mode) }, mode)
+		crate.UseField(3, func(mode lite.UseMode) { crate.UseVarint(&a.Balance, mode) }, mode)
+		crate.UseField(4, func(mode lite.UseMode) {
+			lite.UseSlice(crate, mode, &a.Friends, func(friend *accountV1, mode lite.UseMode) []byte {
+				return crate.UseSelfSerializer(friend, mode)
+			})
+		}, mode)
+	}, mode)
+}
+
+func TestTaggedFields(t *testing.T) {
+	newer := accountV2{ID: 300, Name: "Derek", Balance: -5000, Friends: []accountV1{{1, "Chris"}, {2, "Brent"}}}
+	crate := lite.NewCrate(8, lite.FlagAutoDouble|lite.FlagTaggedFields)
+	crate.WriteSelfSerializer(&newer)
+	crate.WriteU8(99)
+	older := accountV1{}
+	crate.PeekSelfSerializer(&older)
+	if crate.ReadIndex() != 0 {
+		t.Error("PeekField - FAIL: index was increased")
+	}
+	if older.ID != newer.ID || older.Name != newer.Name {
+		t.Errorf("Read newer as older - FAIL: %#v", older)
+	}
+	crate.DiscardSelfSerializer(&older)
+	if crate.ReadU8() != 99 {
+		t.Error("DiscardFieldGroup - FAIL: did not skip whole group")
+	}
+	crate.ResetReadIndex()
+	roundTrip := accountV2{}
+	crate.ReadSelfSerializer(&roundTrip)
+	if roundTrip.Balance != newer.Balance || len(roundTrip.Friends) != 2 || roundTrip.Friends[1].Name != "Brent" {
+		t.Errorf("Read/Write Fields - FAIL: %#v", roundTrip)
+	}
+
+	oldCrate := lite.NewCrate(8, lite.FlagAutoDouble|lite.FlagTaggedFields)
+	oldCrate.WriteSelfSerializer(&older)
+	upgraded := accountV2{Balance: 7}
+	oldCrate.ReadSelfSerializer(&upgraded)
+	if upgraded.Name != "Derek" || upgraded.Balance != 7 || upgraded.Friends != nil {
+		t.Errorf("Read older as newer - FAIL: %#v", upgraded)
+	}
+	oldCrate.ResetReadIndex()
+	var found bool
+	var slice []byte
+	oldCrate.UseFieldGroup(func(mode lite.UseMode) {
+		found, slice = oldCrate.UseField(2, nil, lite.Slice)
+	}, lite.Read)
+	if !found || string(slice) != "\x06Derek" {
+		t.Errorf("SliceField - FAIL: %v %q", found, slice)
+	}
+
+	plain := lite.NewCrate(8, lite.FlagAutoDouble)
+	plain.WriteSelfSerializer(&older)
+	manual := lite.NewCrate(8, lite.FlagAutoDouble)
+	manual.WriteStringWithCounter(older.Name)
+	manual.WriteUVarint(older.ID)
+	if !bytes.Equal(plain.Data(), manual.Data()) {
+		t.Errorf("Untagged Fields - FAIL: %v != %v", plain.Data(), manual.Data())
+	}
+}
+
+type legacyRecord struct {
+	Before uint16
+	After  uint16
+}
+
+func (r *legacyRecord) UseSelf(crate *lite.Crate, mode lite.UseMode) {
+	crate.UseFieldGroup(func(mode lite.UseMode) {
+		crate.UseField(1, func(mode lite.UseMode) { crate.UseU16(&r.Before, mode) }, mode)
+		crate.UseDeprecated(2, 4, mode) // removed uint32
+		crate.UseField(3, func(mode lite.UseMode) { crate.UseU16(&r.After, mode) }, mode)
+	}, mode)
+}
+
+func TestDeprecated(t *testing.T) {
+	for _, flags := range []uint8{lite.FlagAutoDouble, lite.FlagAutoDouble | lite.FlagTaggedFields} {
+		old := lite.NewCrate(8, flags)
+		old.UseFieldGroup(func(mode lite.UseMode) {
+			old.UseField(1, func(mode lite.UseMode) { old.WriteU16(10) }, mode)
+			old.UseField(2, func(mode lite.UseMode) { old.WriteU32(123456) }, mode)
+			old.UseField(3, func(mode lite.UseMode) { old.WriteU16(30) }, mode)
+		}, lite.Write)
+		record := legacyRecord{}
+		old.ReadSelfSerializer(&record)
+		if record.Before != 10 || record.After != 30 || old.ReadsLeft() != 0 {
+			t.Errorf("Read Deprecated (flags %d) - FAIL: %#v", flags, record)
+		}
+		crate := lite.NewCrate(8, flags)
+		crate.WriteSelfSerializer(&record)
+		if crate.Len() != old.Len() {
+			t.Errorf("Write Deprecated (flags %d) - FAIL: len %d != %d", flags, crate.Len(), old.Len())
+		}
+		var removed uint32 = 1
+		crate.UseFieldGroup(func(mode lite.UseMode) {
+			crate.UseDeprecated(1, 2, mode)
+			crate.UseField(2, func(mode lite.UseMode) { crate.UseU32(&removed, mode) }, mode)
+			crate.UseDeprecated(3, 2, mode)
+		}, lite.Read)
+		if removed != 0 {
+			t.Errorf("Write Deprecated (flags %d) - FAIL: padding %d != 0", flags, removed)
+		}
+	}
+}
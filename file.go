@@ -0,0 +1,115 @@
+//go:build !litecrate_core && !litecrate_noos
+
+package litecrate
+
+import (
+	"errors"
+	"hash/crc32"
+	"os"
+)
+
+const (
+	crateFileMagic   = "LCRT" // First 4 bytes of every file written by SaveFile()
+	crateFileVersion = 1      // Version of the file header layout
+	crateFileHeader  = 18     // Bytes before the payload: magic, file version, wire version, length (U64), checksum (U32)
+)
+
+// Returned by LoadCrateFile() when the file does not begin with the crate file magic number
+var ErrNotCrateFile = errors.New("LiteCrate: file is not a crate file")
+
+// Returned by LoadCrateFile() when the file's length or checksum does not match its payload
+var ErrCorruptCrateFile = errors.New("LiteCrate: crate file is corrupt")
+
+var crateFileTable = crc32.MakeTable(crc32.Castagnoli)
+
+/**************
+	FILES
+***************/
+
+// Save the crate's written data to the file at path, behind a header holding a magic number,
+// the file and wire format versions, the payload's length and its CRC-32C checksum,
+// so LoadCrateFile() can tell crate files apart from other files and detect corruption.
+//
+// The file is written to a temporary file beside it and renamed over path once synced,
+// so a crash leaves either the old file or the new one, never a mix.
+// Returns the crate's error without saving anything if it failed under FlagNoPanic
+func (c *Crate) SaveFile(path string) error {
+	if c.failed != nil {
+		return c.failed.err
+	}
+	file := NewCrate(crateFileHeader+c.write, FlagStatic)
+	file.WriteString(crateFileMagic)
+	file.WriteU8(crateFileVersion)
+	file.WriteWireVersion()
+	file.WriteU64(c.write)
+	file.WriteU32(crc32.Checksum(c.Data(), crateFileTable))
+	file.WriteBytes(c.Data())
+	return writeFileAtomic(path, file.Data())
+}
+
+// Load a file written by SaveFile() into a new crate with the given flags, containing the saved data.
+// Returns ErrNotCrateFile if it is not a crate file, ErrCorruptCrateFile if it is truncated or fails its checksum,
+// and a *WireVersionError if it was written with a different version of the wire format
+func LoadCrateFile(path string, flags uint8) (*Crate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < len(crateFileMagic) || string(data[:len(crateFileMagic)]) != crateFileMagic {
+		return nil, ErrNotCrateFile
+	}
+	if len(data) < crateFileHeader {
+		return nil, ErrCorruptCrateFile
+	}
+	header := OpenCrate(data[:crateFileHeader], FlagStatic)
+	header.DiscardN(len64str(crateFileMagic))
+	if version := header.ReadU8(); version != crateFileVersion {
+		return nil, errors.New("LiteCrate: unsupported crate file version " + intStr(version))
+	}
+	if version := header.ReadU8(); !(WirePolicy{}).Accepts(version) {
+		return nil, &WireVersionError{Version: version}
+	}
+	length, checksum := header.ReadU64(), header.ReadU32()
+	payload := data[crateFileHeader:]
+	if length != len64(payload) || checksum != crc32.Checksum(payload, crateFileTable) {
+		return nil, ErrCorruptCrateFile
+	}
+	return OpenCrate(payload, flags), nil
+}
+
+// Commit the creation, removal and renaming of the files in dir to stable storage
+func syncDir(dir string) error {
+	file, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = file.Sync()
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Write data to a temporary file beside path and rename it over path once synced,
+// so a crash leaves either the old file or the new one
+func writeFileAtomic(path string, data []byte) error {
+	temp := path + ".tmp"
+	file, err := os.OpenFile(temp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(temp, path)
+	}
+	if err != nil {
+		os.Remove(temp)
+	}
+	return err
+}
@@ -0,0 +1,113 @@
+//go:build !litecrate_core && !litecrate_noos
+
+package litecrate_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+func saveTestFile(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "state.crate")
+	crate := lite.NewCrate(32, lite.FlagDefault)
+	crate.WriteStringWithCounter("hello")
+	crate.WriteU32(12345)
+	if err := crate.SaveFile(path); err != nil {
+		t.Fatalf("SaveFile() - FAIL: %v", err)
+	}
+	return path
+}
+
+func TestFileRoundTrip(t *testing.T) {
+	path := saveTestFile(t)
+	crate, err := lite.LoadCrateFile(path, lite.FlagDefault)
+	if err != nil {
+		t.Fatalf("LoadCrateFile() - FAIL: %v", err)
+	}
+	if str, num := crate.ReadStringWithCounter(), crate.ReadU32(); str != "hello" || num != 12345 {
+		t.Errorf("LoadCrateFile() - FAIL: read (%q, %d), want (\"hello\", 12345)", str, num)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("SaveFile() - FAIL: temporary file left behind")
+	}
+}
+
+func TestFileOverwrite(t *testing.T) {
+	path := saveTestFile(t)
+	crate := lite.NewCrate(8, lite.FlagDefault)
+	crate.WriteU8(7)
+	if err := crate.SaveFile(path); err != nil {
+		t.Fatalf("SaveFile() - FAIL: %v", err)
+	}
+	loaded, err := lite.LoadCrateFile(path, lite.FlagDefault)
+	if err != nil {
+		t.Fatalf("LoadCrateFile() - FAIL: %v", err)
+	}
+	if loaded.Len() != 1 || loaded.ReadU8() != 7 {
+		t.Errorf("SaveFile() - FAIL: overwritten file does not hold the new crate")
+	}
+}
+
+func TestFileCorruption(t *testing.T) {
+	path := saveTestFile(t)
+	data, _ := os.ReadFile(path)
+	cases := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"flipped payload byte", flipByte(data, len(data)-1), lite.ErrCorruptCrateFile},
+		{"flipped length byte", flipByte(data, 6), lite.ErrCorruptCrateFile},
+		{"truncated payload", data[:len(data)-2], lite.ErrCorruptCrateFile},
+		{"truncated header", data[:10], lite.ErrCorruptCrateFile},
+		{"appended byte", append(append([]byte{}, data...), 0), lite.ErrCorruptCrateFile},
+		{"bad magic", flipByte(data, 0), lite.ErrNotCrateFile},
+		{"empty file", nil, lite.ErrNotCrateFile},
+	}
+	for _, c := range cases {
+		os.WriteFile(path, c.data, 0o644)
+		if _, err := lite.LoadCrateFile(path, lite.FlagDefault); err != c.want {
+			t.Errorf("LoadCrateFile() - FAIL: %s returned %v, want %v", c.name, err, c.want)
+		}
+	}
+}
+
+func TestFileVersions(t *testing.T) {
+	path := saveTestFile(t)
+	data, _ := os.ReadFile(path)
+	os.WriteFile(path, flipByte(data, 4), 0o644)
+	if _, err := lite.LoadCrateFile(path, lite.FlagDefault); err == nil {
+		t.Errorf("LoadCrateFile() - FAIL: accepted unsupported file version")
+	}
+	os.WriteFile(path, flipByte(data, 5), 0o644)
+	var versionErr *lite.WireVersionError
+	if _, err := lite.LoadCrateFile(path, lite.FlagDefault); !errors.As(err, &versionErr) {
+		t.Errorf("LoadCrateFile() - FAIL: wire version mismatch returned %v, want *WireVersionError", err)
+	}
+}
+
+func TestFileNoPanic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "failed.crate")
+	if err := failedCrate().SaveFile(path); err == nil {
+		t.Error("SaveFile() - FAIL: saved failed crate")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("SaveFile() - FAIL: failed crate left a file behind")
+	}
+}
+
+func TestFileMissing(t *testing.T) {
+	if _, err := lite.LoadCrateFile(filepath.Join(t.TempDir(), "missing"), lite.FlagDefault); !os.IsNotExist(err) {
+		t.Errorf("LoadCrateFile() - FAIL: missing file returned %v, want not exist", err)
+	}
+}
+
+func flipByte(data []byte, i int) []byte {
+	flipped := append([]byte{}, data...)
+	flipped[i] ^= 0xFF
+	return flipped
+}
@@ -0,0 +1,162 @@
+package litecrate
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// Largest frame ReadFrame() accepts when Framer.MaxFrameSize is 0
+const DefaultMaxFrameSize = 64 << 20
+
+// Returned by ReadFrame() when a frame's length prefix exceeds Framer.MaxFrameSize
+var ErrFrameTooLarge = errors.New("LiteCrate: frame exceeds max frame size")
+
+/**************
+	FRAMER
+***************/
+
+// A Framer sends crates over a stream (TCP connection, pipe, file) by prefixing
+// each one's written data with its length as a UVarint, so the reader knows
+// where each crate ends. The zero value is ready to use.
+//
+// With WireHeader set each frame also carries the WireVersion it was written with, so a reader
+// running a different version of the wire format rejects the frame instead of misreading it.
+// Both ends must agree on WireHeader.
+//
+// WriteFrame() and ReadFrame() are each safe to call from multiple goroutines:
+// concurrent writes never interleave their frames, and concurrent reads each receive whole frames
+type Framer struct {
+	MaxFrameSize uint64            // Frames longer than this are rejected by ReadFrame(), 0 = DefaultMaxFrameSize
+	Flags        uint8             // Flags for crates returned by ReadFrame()
+	Pool         *CratePool        // If not nil, crates returned by ReadFrame() are taken from Pool
+	WriteLatency *LatencyHistogram // If not nil, times each WriteFrame()
+	ReadLatency  *LatencyHistogram // If not nil, times each ReadFrame() from the end of its length prefix (not including time waiting for a frame to begin)
+	WireHeader   bool              // If true, each frame begins with a 1 byte wire version before its length prefix
+	WirePolicy   WirePolicy        // Wire versions ReadFrame() accepts when WireHeader is true
+	writeMutex   sync.Mutex
+	readMutex    sync.Mutex
+}
+
+// Write the crate's written data to conn as one frame.
+// The header and data are passed to conn with writeVectored(), so connections that support vectored I/O
+// (such as a *net.TCPConn) send them with a single writev call.
+// Returns the crate's error without writing anything if it failed under FlagNoPanic
+func (f *Framer) WriteFrame(conn io.Writer, crate *Crate) error {
+	if err := crate.Err(); err != nil {
+		return err
+	}
+	defer f.WriteLatency.ObserveSince(time.Now())
+	var header [10]byte
+	buffers := [][]byte{f.frameHeader(header[:], crate.write), crate.Data()}
+	f.writeMutex.Lock()
+	defer f.writeMutex.Unlock()
+	_, err := writeVectored(conn, buffers)
+	return err
+}
+
+// Write the chain's written data to conn as one frame (read back with ReadFrame() like any other),
+// passing the header and every segment to conn with writeVectored() so the chain is never flattened
+func (f *Framer) WriteChainedFrame(conn io.Writer, chain *ChainedCrate) error {
+	defer f.WriteLatency.ObserveSince(time.Now())
+	var header [10]byte
+	buffers := append([][]byte{f.frameHeader(header[:], chain.Len())}, chain.Segments()...)
+	f.writeMutex.Lock()
+	defer f.writeMutex.Unlock()
+	_, err := writeVectored(conn, buffers)
+	return err
+}
+
+// Writes the header of a frame of length bytes into buf, returning the part used
+func (f *Framer) frameHeader(buf []byte, length uint64) []byte {
+	headerCrate := Crate{data: buf, flags: FlagStatic}
+	if f.WireHeader {
+		headerCrate.WriteWireVersion()
+	}
+	headerCrate.WriteUVarint(length)
+	return headerCrate.Data()
+}
+
+// Read the next frame from conn into a new crate.
+// Returns io.EOF if conn ended cleanly between frames, io.ErrUnexpectedEOF if it ended mid-frame
+// and ErrFrameTooLarge (without reading the frame) if the frame is longer than MaxFrameSize.
+// With WireHeader set, returns a *WireVersionError (without reading the frame) if WirePolicy
+// does not accept the frame's wire version
+func (f *Framer) ReadFrame(conn io.Reader) (*Crate, error) {
+	f.readMutex.Lock()
+	defer f.readMutex.Unlock()
+	if f.WireHeader {
+		if err := readWireHeader(conn, f.WirePolicy); err != nil {
+			return nil, err
+		}
+	}
+	length, err := readFrameHeader(conn)
+	if err != nil {
+		if f.WireHeader {
+			err = unexpectedEOF(err)
+		}
+		return nil, err
+	}
+	defer f.ReadLatency.ObserveSince(time.Now())
+	maxSize := f.MaxFrameSize
+	if maxSize == 0 {
+		maxSize = DefaultMaxFrameSize
+	}
+	if length > maxSize {
+		return nil, ErrFrameTooLarge
+	}
+	var crate *Crate
+	if f.Pool != nil {
+		crate = f.Pool.Get(length, f.Flags)
+	} else {
+		crate = NewCrate(length, f.Flags)
+	}
+	if _, err = io.ReadFull(conn, crate.data[:length]); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if f.Pool != nil {
+			f.Pool.Put(crate)
+		}
+		return nil, err
+	}
+	crate.write = length
+	return crate, nil
+}
+
+func readWireHeader(conn io.Reader, policy WirePolicy) error {
+	var version [1]byte
+	if _, err := io.ReadFull(conn, version[:]); err != nil {
+		return err
+	}
+	if !policy.Accepts(version[0]) {
+		return &WireVersionError{Version: version[0], Policy: policy}
+	}
+	return nil
+}
+
+func readFrameHeader(conn io.Reader) (length uint64, err error) {
+	var header [9]byte
+	n := 0
+	for longer := true; longer && n < 9; n += 1 {
+		if _, err = io.ReadFull(conn, header[n:n+1]); err != nil {
+			if err == io.EOF && n > 0 {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+		longer = header[n]&continueMask == continueMask
+	}
+	headerCrate := OpenCrate(header[:n], FlagStatic)
+	length, _ = headerCrate.ReadUVarint()
+	return length, nil
+}
+
+// Converts io.EOF to io.ErrUnexpectedEOF, for reads that stop partway through a frame
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
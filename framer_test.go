@@ -0,0 +1,121 @@
+package litecrate_test
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"sync"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+func TestFramer(t *testing.T) {
+	var framer lite.Framer
+	var stream bytes.Buffer
+	sizes := []int{0, 1, 127, 128, 5000}
+	for _, size := range sizes {
+		crate := lite.NewCrate(8, lite.FlagAutoDouble)
+		crate.WriteBytes(bytes.Repeat([]byte{byte(size)}, size))
+		if err := framer.WriteFrame(&stream, crate); err != nil {
+			t.Fatalf("Framer.WriteFrame() - FAIL: %v", err)
+		}
+	}
+	whole := stream.Bytes()
+	for _, size := range sizes {
+		crate, err := framer.ReadFrame(&stream)
+		if err != nil || !bytes.Equal(crate.Data(), bytes.Repeat([]byte{byte(size)}, size)) {
+			t.Errorf("Framer.ReadFrame() - FAIL: frame of %d bytes: %v", size, err)
+		}
+	}
+	if _, err := framer.ReadFrame(&stream); err != io.EOF {
+		t.Errorf("Framer.ReadFrame() - FAIL: %v != io.EOF at end of stream", err)
+	}
+	if _, err := framer.ReadFrame(bytes.NewReader(whole[:len(whole)-1])); err != nil {
+		t.Errorf("Framer.ReadFrame() - FAIL: first frame of truncated stream: %v", err)
+	}
+	if _, err := framer.ReadFrame(bytes.NewReader([]byte{0x80})); err != io.ErrUnexpectedEOF {
+		t.Errorf("Framer.ReadFrame() - FAIL: %v != io.ErrUnexpectedEOF inside header", err)
+	}
+	if _, err := framer.ReadFrame(bytes.NewReader([]byte{0x10, 1, 2})); err != io.ErrUnexpectedEOF {
+		t.Errorf("Framer.ReadFrame() - FAIL: %v != io.ErrUnexpectedEOF inside body", err)
+	}
+	limited := lite.Framer{MaxFrameSize: 100}
+	if _, err := limited.ReadFrame(bytes.NewReader(whole)); err != nil {
+		t.Errorf("Framer.ReadFrame() - FAIL: small frame rejected: %v", err)
+	}
+	big := lite.NewCrate(101, lite.FlagAutoDouble)
+	big.WriteBytes(make([]byte, 101))
+	stream.Reset()
+	limited.WriteFrame(&stream, big)
+	if _, err := limited.ReadFrame(&stream); err != lite.ErrFrameTooLarge {
+		t.Errorf("Framer.ReadFrame() - FAIL: %v != ErrFrameTooLarge", err)
+	}
+}
+
+func TestFramerChained(t *testing.T) {
+	framer := lite.Framer{WireHeader: true}
+	chain := lite.NewChainedCrate(8, lite.FlagDefault)
+	for i := 0; i < 10; i += 1 {
+		chain.WriteValue(func(crate *lite.Crate) { crate.WriteU32(uint32(i)) })
+	}
+	var stream bytes.Buffer
+	if err := framer.WriteChainedFrame(&stream, chain); err != nil {
+		t.Fatalf("Framer.WriteChainedFrame() - FAIL: %v", err)
+	}
+	crate, err := framer.ReadFrame(&stream)
+	if err != nil || !bytes.Equal(crate.Data(), chain.Coalesce(lite.FlagDefault).Data()) {
+		t.Errorf("Framer.WriteChainedFrame() - FAIL: frame does not hold the chain's data: %v", err)
+	}
+	if stream.Len() != 0 {
+		t.Errorf("Framer.WriteChainedFrame() - FAIL: %d bytes after frame", stream.Len())
+	}
+}
+
+func TestFramerConcurrent(t *testing.T) {
+	client, server := net.Pipe()
+	framer := lite.Framer{Pool: lite.NewCratePool()}
+	const writers, messages = 4, 200
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w += 1 {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			crate := lite.NewCrate(8, lite.FlagAutoDouble)
+			for i := 0; i < messages; i += 1 {
+				crate.Reset()
+				crate.WriteU8(uint8(w))
+				crate.WriteStringWithCounter(string(bytes.Repeat([]byte{'x'}, i)))
+				if err := framer.WriteFrame(client, crate); err != nil {
+					t.Errorf("Framer.WriteFrame() - FAIL: %v", err)
+					return
+				}
+			}
+		}(w)
+	}
+	go func() {
+		wg.Wait()
+		client.Close()
+	}()
+	next := make([]int, writers)
+	for {
+		crate, err := framer.ReadFrame(server)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Framer.ReadFrame() - FAIL: %v", err)
+		}
+		w := crate.ReadU8()
+		if got := len(crate.ReadStringWithCounter()); got != next[w] || crate.ReadsLeft() != 0 {
+			t.Fatalf("Framer.ReadFrame() - FAIL: writer %d message %d had length %d", w, next[w], got)
+		}
+		next[w] += 1
+		framer.Pool.Put(crate)
+	}
+	for w, n := range next {
+		if n != messages {
+			t.Errorf("Framer - FAIL: received %d of %d messages from writer %d", n, messages, w)
+		}
+	}
+}
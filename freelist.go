@@ -0,0 +1,199 @@
+package litecrate
+
+import (
+	"math/bits"
+	"reflect"
+	"sync"
+	"unsafe"
+)
+
+// Most arrays FreeLists keeps for each element type and size class when MaxPerClass is 0
+const DefaultMaxPerClass = 64
+
+// Supplies the memory for slices and maps of plain data (types holding no pointers, such as []uint32,
+// []byte or map[uint16]float64) decoded by a crate given it with SetAllocator(), and takes it back
+// from ReleaseSlice() and ReleaseMap(), so servers that continuously decode and discard values of the
+// same shapes stop allocating once warmed up. FreeLists is a ready to use implementation
+type Allocator interface {
+	// Returns memory for at least n elements of elem and how many elements it holds,
+	// or a nil pointer to have the crate allocate normally. The memory does not need to be zeroed
+	AllocSlice(elem reflect.Type, n uint64) (ptr unsafe.Pointer, capacity uint64)
+	// Takes back memory holding capacity elements of elem, which may not have come from AllocSlice()
+	FreeSlice(elem reflect.Type, ptr unsafe.Pointer, capacity uint64)
+	// Returns an empty map of type t with room for about n entries, or nil to have the crate allocate normally
+	AllocMap(t reflect.Type, n uint64) any
+	// Takes back an empty map of type t, which may not have come from AllocMap()
+	FreeMap(t reflect.Type, m any)
+}
+
+/**************
+	ALLOCATOR
+***************/
+
+// Decode slices and maps of plain data into memory from alloc (nil = allocate normally, the default)
+func (c *Crate) SetAllocator(alloc Allocator) {
+	c.alloc = alloc
+}
+
+// Returns the allocator set with SetAllocator(), nil if none
+func (c *Crate) Allocator() Allocator {
+	return c.alloc
+}
+
+// Return the memory of a slice decoded by the crate to its allocator, for reuse by later decodes.
+// Does nothing if the crate has no allocator or T holds pointers. Neither slice nor any slice
+// sharing its memory may be used afterwards
+func ReleaseSlice[T any](crate *Crate, slice []T) {
+	if crate.alloc == nil || cap(slice) == 0 {
+		return
+	}
+	if elem := reflect.TypeOf((*T)(nil)).Elem(); isPlainData(elem) {
+		slice = slice[:cap(slice)]
+		crate.alloc.FreeSlice(elem, unsafe.Pointer(&slice[0]), cap64(slice))
+	}
+}
+
+// Clear a map decoded by the crate and return it to the crate's allocator, for reuse by later decodes.
+// Does nothing if the crate has no allocator or K or V hold pointers. The map may not be used afterwards
+func ReleaseMap[K comparable, V any](crate *Crate, m map[K]V) {
+	if crate.alloc == nil || m == nil {
+		return
+	}
+	if t := reflect.TypeOf(m); isPlainData(t.Key()) && isPlainData(t.Elem()) {
+		for key := range m {
+			delete(m, key)
+		}
+		crate.alloc.FreeMap(t, m)
+	}
+}
+
+// Returns a slice of length n for a read, from the crate's allocator if it has one that will supply it
+func makeSlice[T any](c *Crate, n uint64) []T {
+	if c.alloc != nil && n > 0 {
+		if elem := reflect.TypeOf((*T)(nil)).Elem(); isPlainData(elem) {
+			if ptr, capacity := c.alloc.AllocSlice(elem, n); ptr != nil && capacity >= n {
+				return unsafe.Slice((*T)(ptr), capacity)[:n]
+			}
+		}
+	}
+	return make([]T, n)
+}
+
+// Returns an empty map for a read, from the crate's allocator if it has one that will supply it
+func makeMap[K comparable, V any](c *Crate, n uint64) map[K]V {
+	if c.alloc != nil {
+		if t := reflect.TypeOf((map[K]V)(nil)); isPlainData(t.Key()) && isPlainData(t.Elem()) {
+			if m, ok := c.alloc.AllocMap(t, n).(map[K]V); ok && m != nil {
+				return m
+			}
+		}
+	}
+	return make(map[K]V, n)
+}
+
+// Whether values of type t hold no pointers, so their memory can be handed out again without the garbage
+// collector needing to know what was in it
+func isPlainData(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	case reflect.Array:
+		return isPlainData(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i += 1 {
+			if !isPlainData(t.Field(i).Type) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+/**************
+	FREE LISTS
+***************/
+
+// An Allocator that keeps released slice memory in free lists by element type and power of two size class,
+// and released maps by type. Slices are handed out with the capacity of their size class, so a slice of
+// 100 elements reuses the memory of any released slice of 128 to 255 elements. The zero value is ready to use,
+// and a FreeLists is safe to share between crates used by multiple goroutines
+type FreeLists struct {
+	MaxPerClass int // Most arrays kept per element type and size class (and maps per type), 0 = DefaultMaxPerClass
+	mutex       sync.Mutex
+	slices      map[freeListKey][]unsafe.Pointer
+	maps        map[reflect.Type][]any
+}
+
+type freeListKey struct {
+	elem  reflect.Type
+	class uint8 // The arrays in the list hold at least 1 << class elements
+}
+
+func (f *FreeLists) AllocSlice(elem reflect.Type, n uint64) (ptr unsafe.Pointer, capacity uint64) {
+	class := uint8(bits.Len64(n - 1))
+	if class >= 63 {
+		return nil, 0
+	}
+	key := freeListKey{elem: elem, class: class}
+	f.mutex.Lock()
+	if list := f.slices[key]; len(list) > 0 {
+		ptr = list[len(list)-1]
+		list[len(list)-1] = nil
+		f.slices[key] = list[:len(list)-1]
+	}
+	f.mutex.Unlock()
+	if ptr == nil {
+		ptr = reflect.New(reflect.ArrayOf(1<<class, elem)).UnsafePointer()
+	}
+	return ptr, 1 << class
+}
+
+func (f *FreeLists) FreeSlice(elem reflect.Type, ptr unsafe.Pointer, capacity uint64) {
+	if capacity == 0 {
+		return
+	}
+	// Memory that is not a whole size class is filed under the largest class it can hold
+	key := freeListKey{elem: elem, class: uint8(bits.Len64(capacity) - 1)}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.slices == nil {
+		f.slices = make(map[freeListKey][]unsafe.Pointer)
+	}
+	if list := f.slices[key]; len(list) < f.maxPerClass() {
+		f.slices[key] = append(list, ptr)
+	}
+}
+
+func (f *FreeLists) AllocMap(t reflect.Type, n uint64) any {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	list := f.maps[t]
+	if len(list) == 0 {
+		return nil
+	}
+	m := list[len(list)-1]
+	list[len(list)-1] = nil
+	f.maps[t] = list[:len(list)-1]
+	return m
+}
+
+func (f *FreeLists) FreeMap(t reflect.Type, m any) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.maps == nil {
+		f.maps = make(map[reflect.Type][]any)
+	}
+	if list := f.maps[t]; len(list) < f.maxPerClass() {
+		f.maps[t] = append(list, m)
+	}
+}
+
+func (f *FreeLists) maxPerClass() int {
+	if f.MaxPerClass == 0 {
+		return DefaultMaxPerClass
+	}
+	return f.MaxPerClass
+}
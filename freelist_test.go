@@ -0,0 +1,118 @@
+package litecrate_test
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+type countingAllocator struct {
+	lite.FreeLists
+	slices int
+	maps   int
+}
+
+func (a *countingAllocator) AllocSlice(elem reflect.Type, n uint64) (ptr unsafe.Pointer, capacity uint64) {
+	a.slices += 1
+	return a.FreeLists.AllocSlice(elem, n)
+}
+
+func (a *countingAllocator) AllocMap(t reflect.Type, n uint64) any {
+	a.maps += 1
+	return a.FreeLists.AllocMap(t, n)
+}
+
+func TestFreeListsReuse(t *testing.T) {
+	crate := lite.NewCrate(64, lite.FlagAutoDouble)
+	nums := []uint32{1, 2, 3, 4, 5}
+	crate.UseU32Slice(&nums, lite.Write)
+	lite.UseSlice(crate, lite.Write, &nums, crate.UseU32)
+	crate.WriteBytesWithCounter([]byte("hello"))
+
+	var lists lite.FreeLists
+	crate.SetAllocator(&lists)
+	if crate.Allocator() != &lists {
+		t.Fatalf("Allocator() - FAIL: did not return allocator")
+	}
+	first := crate.ReadU32Slice()
+	if len(first) != 5 || cap(first) != 8 || first[4] != 5 {
+		t.Fatalf("ReadU32Slice() - FAIL: %v, cap %d", first, cap(first))
+	}
+	lite.ReleaseSlice(crate, first)
+	var second []uint32
+	lite.UseSlice(crate, lite.Read, &second, crate.UseU32)
+	if &second[0] != &first[:1][0] || len(second) != 5 || second[4] != 5 {
+		t.Errorf("UseSlice() - FAIL: released memory was not reused: %v", second)
+	}
+	if bytes := crate.ReadBytesWithCounter(); string(bytes) != "hello" || cap(bytes) != 8 {
+		t.Errorf("ReadBytesWithCounter() - FAIL: %q, cap %d", bytes, cap(bytes))
+	}
+}
+
+func TestFreeListsSteadyState(t *testing.T) {
+	crate := lite.NewCrate(256, lite.FlagAutoDouble)
+	nums := make([]float64, 20)
+	counts := map[uint16]uint32{1: 10, 2: 20, 3: 30}
+	crate.UseF64Slice(&nums, lite.Write)
+	lite.UseMap(crate, lite.Write, &counts, crate.UseU16, crate.UseU32)
+	crate.SetAllocator(&lite.FreeLists{})
+	var readCounts map[uint16]uint32
+	decode := func() {
+		crate.ResetReadIndex()
+		readNums := crate.ReadF64Slice()
+		readCounts = nil
+		lite.UseMap(crate, lite.Read, &readCounts, crate.UseU16, crate.UseU32)
+		if len(readNums) != 20 || readCounts[3] != 30 {
+			t.Fatalf("UseMap() - FAIL: decoded %d numbers, %v", len(readNums), readCounts)
+		}
+		lite.ReleaseSlice(crate, readNums)
+		lite.ReleaseMap(crate, readCounts)
+	}
+	decode()
+	// The only allocation left is UseMap()'s key variable, which escapes through useKeyFunc
+	if allocs := testing.AllocsPerRun(100, decode); allocs > 1 {
+		t.Errorf("FreeLists - FAIL: %v allocations per decode once warmed up", allocs)
+	}
+}
+
+func TestFreeListsPointerTypes(t *testing.T) {
+	crate := lite.NewCrate(64, lite.FlagAutoDouble)
+	strs := []string{"a", "b"}
+	lite.UseSlice(crate, lite.Write, &strs, crate.UseStringWithCounter)
+	lists := &countingAllocator{}
+	crate.SetAllocator(lists)
+	var read []string
+	lite.UseSlice(crate, lite.Read, &read, crate.UseStringWithCounter)
+	lite.ReleaseSlice(crate, read)
+	if lists.slices != 0 || len(read) != 2 {
+		t.Errorf("UseSlice() - FAIL: slice of strings was taken from the allocator")
+	}
+}
+
+func TestFreeListsLimits(t *testing.T) {
+	lists := lite.FreeLists{MaxPerClass: 1}
+	elem := reflect.TypeOf(uint16(0))
+	a, capA := lists.AllocSlice(elem, 3)
+	b, _ := lists.AllocSlice(elem, 4)
+	if capA != 4 || a == b {
+		t.Fatalf("FreeLists.AllocSlice() - FAIL: capacity %d", capA)
+	}
+	lists.FreeSlice(elem, a, 4)
+	lists.FreeSlice(elem, b, 4) // Over MaxPerClass, dropped
+	if again, _ := lists.AllocSlice(elem, 4); again != a {
+		t.Errorf("FreeLists.AllocSlice() - FAIL: did not reuse freed memory")
+	}
+	if again, _ := lists.AllocSlice(elem, 4); again == b {
+		t.Errorf("FreeLists.FreeSlice() - FAIL: kept more than MaxPerClass arrays")
+	}
+	// Memory that is not a whole size class serves the largest class it can hold
+	lists.FreeSlice(elem, b, 6)
+	if again, capacity := lists.AllocSlice(elem, 3); again != b || capacity != 4 {
+		t.Errorf("FreeLists.FreeSlice() - FAIL: odd capacity not filed under its class")
+	}
+	if m := lists.AllocMap(reflect.TypeOf(map[int]int(nil)), 4); m != nil {
+		t.Errorf("FreeLists.AllocMap() - FAIL: returned a map before any were freed")
+	}
+}
@@ -0,0 +1,123 @@
+package litecrate
+
+// Identities of the objects written, read or visited by a custom mode through UseRef() since the crate was last Reset()
+type graphState struct {
+	written map[any]uint64
+	read    []any
+	visited map[any]bool
+}
+
+/**************
+	GRAPH REFS
+***************/
+
+// Helper func for selectively reading/writing a pointer that may be shared by several
+// objects in a graph (DAGs, doubly linked lists, parent pointers), dependant on mode.
+//
+// The first time a pointer is written its value follows, every later time only its id is written,
+// so shared objects are encoded once and cycles don't recurse forever. When reading,
+// every reference to the same id is set to the same pointer, so the graph round-trips with its shape intact.
+//
+// Ids are assigned in the order objects are written and read, so a crate must be read
+// in the same order it was written, and the ids are forgotten by Reset() or ResetGraph()
+//
+// Example:
+//
+//	type node struct {
+//		Name string
+//		Next *node
+//		Prev *node
+//	}
+//
+//	func (n *node) UseSelf(crate *Crate, mode UseMode) {
+//		crate.UseStringWithCounter(&n.Name, mode)
+//		UseRef(crate, mode, &n.Next, (*node).UseSelf)
+//		UseRef(crate, mode, &n.Prev, (*node).UseSelf)
+//	}
+func UseRef[T any](crate *Crate, mode UseMode, ref **T, useValue func(val *T, crate *Crate, mode UseMode)) (sliceModeData []byte) {
+	switch mode {
+	case Write:
+		writeRef(crate, *ref, useValue)
+	case Read:
+		*ref = readRef(crate, useValue)
+	case Peek:
+		idx := crate.read
+		objects := len(crate.graph.read)
+		*ref = readRef(crate, useValue)
+		crate.read = idx
+		crate.graph.read = crate.graph.read[:objects]
+	case Discard:
+		readRef(crate, useValue)
+	case Slice:
+		idx := crate.read
+		objects := len(crate.graph.read)
+		readRef(crate, useValue)
+		end := crate.read
+		crate.read = idx
+		crate.graph.read = crate.graph.read[:objects]
+		return crate.data[idx:end:end]
+	default:
+		crate.useCustomMode(ref, mode, "UseRef")
+		if *ref == nil || crate.graph.visited[*ref] {
+			return nil
+		}
+		if crate.graph.visited == nil {
+			crate.graph.visited = make(map[any]bool)
+		}
+		crate.graph.visited[*ref] = true
+		crate.enterDepth()
+		defer crate.leaveDepth()
+		useValue(*ref, crate, mode)
+	}
+	return nil
+}
+
+// Forget the identities of all objects written or read with UseRef(), so the next object
+// will be written or read in full even if it was written or read before
+func (c *Crate) ResetGraph() {
+	c.graph = graphState{}
+}
+
+// Each ref is preceded by a UVarint header: 0 = nil, 1 = new object follows, n = object with id n-2
+func writeRef[T any](crate *Crate, ptr *T, useValue func(val *T, crate *Crate, mode UseMode)) {
+	crate.enterDepth()
+	defer crate.leaveDepth()
+	if ptr == nil {
+		crate.WriteUVarint(0)
+		return
+	}
+	if id, ok := crate.graph.written[ptr]; ok {
+		crate.WriteUVarint(id + 2)
+		return
+	}
+	if crate.graph.written == nil {
+		crate.graph.written = make(map[any]uint64)
+	}
+	crate.graph.written[ptr] = uint64(len(crate.graph.written))
+	crate.WriteUVarint(1)
+	useValue(ptr, crate, Write)
+}
+
+func readRef[T any](crate *Crate, useValue func(val *T, crate *Crate, mode UseMode)) (ptr *T) {
+	crate.enterDepth()
+	defer crate.leaveDepth()
+	header, _ := crate.ReadUVarint()
+	switch header {
+	case 0:
+		return nil
+	case 1:
+		ptr = new(T)
+		crate.graph.read = append(crate.graph.read, ptr)
+		useValue(ptr, crate, Read)
+		return ptr
+	}
+	id := header - 2
+	if id >= len64(crate.graph.read) {
+		panic("LiteCrate: ref to object " + intStr(id) + " but only " + intStr(len(crate.graph.read)) + " objects have been read")
+	}
+	ptr, ok := crate.graph.read[id].(*T)
+	if !ok {
+		panic("LiteCrate: ref to object " + intStr(id) + " is not of the requested type")
+	}
+	return ptr
+}
@@ -0,0 +1,94 @@
+package litecrate_test
+
+import (
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+type graphNode struct {
+	Name string
+	Next *graphNode
+	Prev *graphNode
+}
+
+func (n *graphNode) UseSelf(crate *lite.Crate, mode lite.UseMode) {
+	crate.UseStringWithCounter(&n.Name, mode)
+	lite.UseRef(crate, mode, &n.Next, (*graphNode).UseSelf)
+	lite.UseRef(crate, mode, &n.Prev, (*graphNode).UseSelf)
+}
+
+type graphDAG struct {
+	Left   *graphNode
+	Right  *graphNode
+	Absent *graphNode
+}
+
+func (d *graphDAG) UseSelf(crate *lite.Crate, mode lite.UseMode) {
+	lite.UseRef(crate, mode, &d.Left, (*graphNode).UseSelf)
+	lite.UseRef(crate, mode, &d.Right, (*graphNode).UseSelf)
+	lite.UseRef(crate, mode, &d.Absent, (*graphNode).UseSelf)
+}
+
+func TestUseRef(t *testing.T) {
+	a, b, c := &graphNode{Name: "a"}, &graphNode{Name: "b"}, &graphNode{Name: "c"}
+	a.Next, b.Next, c.Next = b, c, a
+	a.Prev, b.Prev, c.Prev = c, a, b
+	crate := lite.NewCrate(16, lite.FlagAutoDouble)
+	head := a
+	lite.UseRef(crate, lite.Write, &head, (*graphNode).UseSelf)
+	crate.WriteU8(42)
+	if crate.WriteIndex() > 20 {
+		t.Errorf("UseRef(Write) - FAIL: cyclic list took %d bytes", crate.WriteIndex())
+	}
+
+	var peeked *graphNode
+	lite.UseRef(crate, lite.Peek, &peeked, (*graphNode).UseSelf)
+	slice := lite.UseRef(crate, lite.Slice, &peeked, (*graphNode).UseSelf)
+	if crate.ReadIndex() != 0 || peeked == nil || peeked.Name != "a" || uint64(len(slice)) != crate.WriteIndex()-1 {
+		t.Errorf("UseRef(Peek/Slice) - FAIL: index %d, slice length %d", crate.ReadIndex(), len(slice))
+	}
+	var got *graphNode
+	lite.UseRef(crate, lite.Read, &got, (*graphNode).UseSelf)
+	if got == peeked || got.Name != "a" || got.Next.Name != "b" || got.Next.Next.Name != "c" {
+		t.Errorf("UseRef(Read) - FAIL: list not restored")
+	}
+	if got.Next.Next.Next != got || got.Prev != got.Next.Next || got.Next.Prev != got {
+		t.Errorf("UseRef(Read) - FAIL: cycle not restored as shared pointers")
+	}
+	if crate.ReadU8() != 42 {
+		t.Errorf("UseRef(Read) - FAIL: did not consume whole crate")
+	}
+
+	crate.Reset()
+	shared := &graphNode{Name: "shared"}
+	dag := graphDAG{Left: shared, Right: shared}
+	crate.WriteSelfSerializer(&dag)
+	crate.WriteSelfSerializer(&dag)
+	var first, second graphDAG
+	crate.ReadSelfSerializer(&first)
+	crate.ReadSelfSerializer(&second)
+	if first.Left != first.Right || first.Left != second.Left || first.Absent != nil || first.Left.Name != "shared" {
+		t.Errorf("UseRef(Read) - FAIL: shared node duplicated")
+	}
+
+	crate.ResetGraph()
+	crate.ResetReadIndex()
+	crate.DiscardSelfSerializer(&first)
+	if crate.ReadsLeft() == 0 {
+		t.Errorf("UseRef(Discard) - FAIL: second write was not a back-reference")
+	}
+	crate.DiscardSelfSerializer(&first)
+	if crate.ReadsLeft() != 0 {
+		t.Errorf("UseRef(Discard) - FAIL: %d bytes left", crate.ReadsLeft())
+	}
+
+	crate.Reset()
+	crate.WriteUVarint(5)
+	if catchPanic[error](func() { lite.UseRef(crate, lite.Read, &got, (*graphNode).UseSelf) }) == nil {
+		t.Errorf("UseRef(Read) - FAIL: unknown id did not panic")
+	}
+	if catchPanic[error](func() { lite.UseRef(crate, lite.UseMode(255), &got, (*graphNode).UseSelf) }) == nil {
+		t.Errorf("UseRef - FAIL: invalid mode did not panic")
+	}
+}
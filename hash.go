@@ -0,0 +1,57 @@
+package litecrate
+
+import "hash"
+
+const (
+	fnvOffset64 = 14695981039346656037
+	fnvPrime64  = 1099511628211
+)
+
+/**************
+	HASHING
+***************/
+
+// Write the crate's written data to h and return h.Sum(nil), for content-addressed storage
+// with a hash of the caller's choice (such as sha256.New()). h is not reset first
+func (c *Crate) Hash(h hash.Hash) (sum []byte) {
+	h.Write(c.Data())
+	return h.Sum(nil)
+}
+
+// Returns the 64-bit FNV-1a hash of the crate's written data, for dedup and cache keys.
+// The result only depends on the data, so it is stable across processes and package versions
+func (c *Crate) Sum64() uint64 {
+	return fnv64(c.Data())
+}
+
+func fnv64(data []byte) uint64 {
+	sum := uint64(fnvOffset64)
+	for _, b := range data {
+		sum ^= uint64(b)
+		sum *= fnvPrime64
+	}
+	return sum
+}
+
+// Encode val into a pooled scratch crate with the given option flags plus FlagSortedMaps
+// and return its Hash(h), without keeping the encoded bytes. Sorting maps makes the
+// hash canonical: equal values always hash the same, whatever order their maps iterate in
+func HashSelfSerializer(val SelfSerializer, flags uint8, h hash.Hash) (sum []byte) {
+	crate := scratchCrates.Get(defaultCrateSize, canonicalFlags(flags))
+	defer putScratch(crate)
+	crate.WriteSelfSerializer(val)
+	return crate.Hash(h)
+}
+
+// Encode val like HashSelfSerializer() and return its Sum64()
+func Sum64SelfSerializer(val SelfSerializer, flags uint8) uint64 {
+	crate := scratchCrates.Get(defaultCrateSize, canonicalFlags(flags))
+	defer putScratch(crate)
+	crate.WriteSelfSerializer(val)
+	return crate.Sum64()
+}
+
+// Flags scratch crates use to encode values for hashing: the ones that change the encoding, with maps sorted
+func canonicalFlags(flags uint8) uint8 {
+	return flags&FlagTaggedFields | FlagSortedMaps
+}
@@ -0,0 +1,65 @@
+package litecrate_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"hash/fnv"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+type hashedScores struct {
+	Scores map[string]uint16
+}
+
+func (s *hashedScores) UseSelf(crate *lite.Crate, mode lite.UseMode) {
+	lite.UseMap(crate, mode, &s.Scores, crate.UseStringWithCounter, crate.UseU16)
+}
+
+func TestCrateHash(t *testing.T) {
+	crate := lite.NewCrate(16, lite.FlagAutoDouble)
+	crate.WriteStringWithCounter("content addressed")
+	crate.WriteU64(42)
+	reference := fnv.New64a()
+	reference.Write(crate.Data())
+	if crate.Sum64() != reference.Sum64() {
+		t.Errorf("Sum64() - FAIL: %x != FNV-1a %x", crate.Sum64(), reference.Sum64())
+	}
+	want := sha256.Sum256(crate.Data())
+	if sum := crate.Hash(sha256.New()); !bytes.Equal(sum, want[:]) {
+		t.Errorf("Hash() - FAIL: %x != %x", sum, want)
+	}
+	crate.ReadStringWithCounter()
+	if sum := crate.Hash(sha256.New()); !bytes.Equal(sum, want[:]) {
+		t.Error("Hash() - FAIL: reading changed the hash")
+	}
+	if empty := lite.NewCrate(8, 0); empty.Sum64() != fnv.New64a().Sum64() {
+		t.Error("Sum64() - FAIL: empty crate did not hash to FNV-1a offset basis")
+	}
+}
+
+func TestHashSelfSerializer(t *testing.T) {
+	first := &hashedScores{Scores: sortedMapInput(50)}
+	sum := lite.Sum64SelfSerializer(first, lite.FlagDefault)
+	digest := lite.HashSelfSerializer(first, lite.FlagDefault, sha256.New())
+	for i := 0; i < 10; i += 1 {
+		// A new map with the same entries iterates in a different order
+		val := &hashedScores{Scores: sortedMapInput(50)}
+		if lite.Sum64SelfSerializer(val, lite.FlagDefault) != sum {
+			t.Fatal("Sum64SelfSerializer() - FAIL: equal values hashed differently")
+		}
+		if !bytes.Equal(lite.HashSelfSerializer(val, lite.FlagDefault, sha256.New()), digest) {
+			t.Fatal("HashSelfSerializer() - FAIL: equal values hashed differently")
+		}
+	}
+	sorted := lite.NewCrate(64, lite.FlagSortedMaps)
+	sorted.WriteSelfSerializer(first)
+	if sorted.Sum64() != sum {
+		t.Error("Sum64SelfSerializer() - FAIL: did not match Sum64() of crate with sorted maps")
+	}
+	first.Scores["aA"] += 1
+	if lite.Sum64SelfSerializer(first, lite.FlagDefault) == sum {
+		t.Error("Sum64SelfSerializer() - FAIL: changed value hashed the same")
+	}
+}
@@ -0,0 +1,164 @@
+package litecrate
+
+import "errors"
+
+const (
+	crateIndexMagic  = "LCIX" // Last 4 bytes of every crate whose index was written by CrateIndex.Finish()
+	crateIndexFooter = 12     // Bytes after the index: its offset (U64), magic
+)
+
+// Returned by OpenCrateIndex() when the crate does not end with a record index
+var ErrNoCrateIndex = errors.New("LiteCrate: crate does not end with a record index")
+
+// Returned by OpenCrateIndex() when the crate's record index is truncated or points outside the crate
+var ErrCorruptCrateIndex = errors.New("LiteCrate: crate record index is corrupt")
+
+/**************
+	CRATE INDEX
+***************/
+
+// A table of contents for a crate holding many records, so a reader can jump to the record it needs
+// without decoding the ones before it.
+//
+// The writer calls MarkRecord() before writing each record and Finish() after the last, which writes
+// the index at the end of the crate: a UVarint record count, each record's key (string with counter),
+// offset and length (UVarints), then the index's offset (U64) and the magic number "LCIX".
+// The reader opens the index with OpenCrateIndex() and finds records with LookupRecord() or SeekRecord()
+type CrateIndex struct {
+	crate    *Crate
+	records  map[string]indexEntry
+	keys     []string
+	open     bool   // whether the last record marked is still being written
+	finished bool   // whether the index has been written (or was read from the crate)
+	end      uint64 // offset the index was written at
+}
+
+type indexEntry struct {
+	offset uint64
+	length uint64
+}
+
+// Returns an empty index for records written to crate
+func NewCrateIndex(crate *Crate) *CrateIndex {
+	return &CrateIndex{crate: crate, records: make(map[string]indexEntry)}
+}
+
+// Start a record named key at the crate's write index, ending the previous record if still open.
+// Panics if key was already marked or the index is finished
+func (x *CrateIndex) MarkRecord(key string) {
+	if x.finished {
+		panic("LiteCrate: cannot mark record " + key + " after the index is finished")
+	}
+	if _, ok := x.records[key]; ok {
+		panic("LiteCrate: record " + key + " is already marked")
+	}
+	x.EndRecord()
+	x.records[key] = indexEntry{offset: x.crate.write}
+	x.keys = append(x.keys, key)
+	x.open = true
+}
+
+// End the record being written at the crate's write index, so data written after it up to the next
+// MarkRecord() belongs to no record. Does nothing if no record is open
+func (x *CrateIndex) EndRecord() {
+	if !x.open {
+		return
+	}
+	key := x.keys[len(x.keys)-1]
+	entry := x.records[key]
+	entry.length = x.crate.write - entry.offset
+	x.records[key] = entry
+	x.open = false
+}
+
+// End the record being written and write the index to the end of the crate.
+// Records can no longer be marked, but can be looked up
+func (x *CrateIndex) Finish() (bytesWritten uint64) {
+	if x.finished {
+		panic("LiteCrate: CrateIndex.Finish() called twice")
+	}
+	x.EndRecord()
+	x.finished, x.end = true, x.crate.write
+	x.crate.WriteUVarint(uint64(len(x.keys)))
+	for _, key := range x.keys {
+		entry := x.records[key]
+		x.crate.WriteStringWithCounter(key)
+		x.crate.WriteUVarint(entry.offset)
+		x.crate.WriteUVarint(entry.length)
+	}
+	x.crate.WriteU64(x.end)
+	x.crate.WriteString(crateIndexMagic)
+	return x.crate.write - x.end
+}
+
+// Read the index at the end of the crate's written data, written by CrateIndex.Finish().
+// Returns ErrNoCrateIndex if the crate does not end with an index, and ErrCorruptCrateIndex if it is
+// truncated, repeats a key, or lists a record outside the data before it
+func OpenCrateIndex(crate *Crate) (index *CrateIndex, err error) {
+	data := crate.Data()
+	size := len64(data)
+	if size < crateIndexFooter || string(data[size-4:]) != crateIndexMagic {
+		return nil, ErrNoCrateIndex
+	}
+	end := OpenCrate(data[size-crateIndexFooter:], FlagStatic).ReadU64()
+	if end > size-crateIndexFooter {
+		return nil, ErrCorruptCrateIndex
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			index, err = nil, ErrCorruptCrateIndex
+		}
+	}()
+	table := OpenCrate(data[end:size-crateIndexFooter], FlagStatic)
+	count, _ := table.ReadUVarint()
+	if count > table.ReadsLeft()/3 {
+		return nil, ErrCorruptCrateIndex
+	}
+	index = &CrateIndex{crate: crate, records: make(map[string]indexEntry, count), finished: true, end: end}
+	for i := uint64(0); i < count; i += 1 {
+		key := table.ReadStringWithCounter()
+		offset, _ := table.ReadUVarint()
+		length, _ := table.ReadUVarint()
+		if _, ok := index.records[key]; ok || offset > end || length > end-offset {
+			return nil, ErrCorruptCrateIndex
+		}
+		index.records[key] = indexEntry{offset: offset, length: length}
+		index.keys = append(index.keys, key)
+	}
+	if table.ReadsLeft() > 0 {
+		return nil, ErrCorruptCrateIndex
+	}
+	return index, nil
+}
+
+// Returns where the record named key lies in the crate. A record still being written has length 0
+func (x *CrateIndex) LookupRecord(key string) (offset uint64, length uint64, ok bool) {
+	entry, ok := x.records[key]
+	return entry.offset, entry.length, ok
+}
+
+// Move the crate's read index to the start of the record named key (see SeekRead()) and return its length,
+// which can be passed to PushReadLimit() to keep reads inside the record. ok is false if there is no such record
+func (x *CrateIndex) SeekRecord(key string) (length uint64, ok bool) {
+	entry, ok := x.records[key]
+	if ok {
+		x.crate.SeekRead(entry.offset)
+	}
+	return entry.length, ok
+}
+
+// Returns the keys of every record, in the order they were marked
+func (x *CrateIndex) Keys() []string {
+	return x.keys
+}
+
+// Returns the number of records
+func (x *CrateIndex) Len() int {
+	return len(x.keys)
+}
+
+// Returns the offset the index was written at, which is where the data before it ends,
+// or 0 if the index is not finished
+func (x *CrateIndex) Offset() uint64 {
+	return x.end
+}
@@ -0,0 +1,90 @@
+package litecrate_test
+
+import (
+	"errors"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+func TestCrateIndex(t *testing.T) {
+	crate := lite.NewCrate(16, lite.FlagAutoDouble)
+	index := lite.NewCrateIndex(crate)
+	index.MarkRecord("alpha")
+	crate.WriteStringWithCounter("first record")
+	index.MarkRecord("beta")
+	crate.WriteU32(0xBEEF)
+	crate.WriteU32(0xCAFE)
+	index.EndRecord()
+	crate.WriteU8(0xFF)
+	index.MarkRecord("gamma")
+	if catchPanic[error](func() { index.MarkRecord("beta") }) == nil {
+		t.Error("MarkRecord() - FAIL: marked key twice")
+	}
+	crate.WriteVarint(-5)
+	end := crate.WriteIndex()
+	index.Finish()
+	if index.Offset() != end {
+		t.Errorf("Finish() - FAIL: index offset %d, expected %d", index.Offset(), end)
+	}
+	if catchPanic[error](func() { index.MarkRecord("delta") }) == nil {
+		t.Error("MarkRecord() - FAIL: marked record after Finish()")
+	}
+
+	read, err := lite.OpenCrateIndex(lite.OpenCrate(crate.Data(), lite.FlagStatic))
+	if err != nil {
+		t.Fatalf("OpenCrateIndex() - FAIL: %v", err)
+	}
+	if read.Len() != 3 || read.Keys()[0] != "alpha" || read.Keys()[2] != "gamma" || read.Offset() != end {
+		t.Errorf("OpenCrateIndex() - FAIL: read keys %v ending at %d", read.Keys(), read.Offset())
+	}
+	for _, key := range index.Keys() {
+		offset, length, _ := index.LookupRecord(key)
+		readOffset, readLength, ok := read.LookupRecord(key)
+		if !ok || readOffset != offset || readLength != length {
+			t.Errorf("LookupRecord(%s) - FAIL: read (%d, %d), wrote (%d, %d)", key, readOffset, readLength, offset, length)
+		}
+	}
+	if _, length, _ := read.LookupRecord("beta"); length != 8 {
+		t.Errorf("EndRecord() - FAIL: beta has length %d, expected 8", length)
+	}
+	if _, _, ok := read.LookupRecord("delta"); ok {
+		t.Error("LookupRecord() - FAIL: found unmarked record")
+	}
+
+	reader := lite.OpenCrate(crate.Data(), lite.FlagStatic)
+	read, _ = lite.OpenCrateIndex(reader)
+	if length, ok := read.SeekRecord("gamma"); !ok || length != 1 {
+		t.Errorf("SeekRecord() - FAIL: gamma has length %d", length)
+	}
+	if val, _ := reader.ReadVarint(); val != -5 {
+		t.Errorf("SeekRecord() - FAIL: read %d from gamma", val)
+	}
+	length, _ := read.SeekRecord("beta")
+	reader.PushReadLimit(length)
+	if reader.ReadU32() != 0xBEEF || reader.ReadU32() != 0xCAFE || reader.PopReadLimit() != 0 {
+		t.Error("SeekRecord() - FAIL: did not read beta")
+	}
+}
+
+func TestCrateIndexErrors(t *testing.T) {
+	if _, err := lite.OpenCrateIndex(lite.OpenCrate([]byte("no index here"), 0)); err != lite.ErrNoCrateIndex {
+		t.Errorf("OpenCrateIndex() - FAIL: got %v from crate without index", err)
+	}
+	crate := lite.NewCrate(16, lite.FlagAutoDouble)
+	index := lite.NewCrateIndex(crate)
+	index.MarkRecord("only")
+	crate.WriteU16(1)
+	index.Finish()
+	data := crate.Data()
+	damaged := map[string][]byte{
+		"record past data": append(append([]byte{}, data[:8]...), append([]byte{50}, data[9:]...)...),
+		"index offset":     append(append([]byte{}, data[:len(data)-12]...), 0xFF, 0, 0, 0, 0, 0, 0, 0, 'L', 'C', 'I', 'X'),
+		"truncated table":  append(append([]byte{}, data[:len(data)-13]...), data[len(data)-12:]...),
+	}
+	for name, data := range damaged {
+		if _, err := lite.OpenCrateIndex(lite.OpenCrate(data, 0)); !errors.Is(err, lite.ErrCorruptCrateIndex) {
+			t.Errorf("OpenCrateIndex() - FAIL: got %v from %s", err, name)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package litecrate
+
+// A table of strings that interned strings refer to by index, see UseWithStringTable()
+type StringTable struct {
+	strings []string
+	index   map[string]uint64
+}
+
+// Create a new, empty StringTable
+func NewStringTable() *StringTable {
+	return &StringTable{}
+}
+
+// Returns the index of str in the table, adding it if it is not already there
+func (t *StringTable) Intern(str string) uint64 {
+	if idx, ok := t.index[str]; ok {
+		return idx
+	}
+	if t.index == nil {
+		t.index = make(map[string]uint64)
+	}
+	idx := len64(t.strings)
+	t.strings = append(t.strings, str)
+	t.index[str] = idx
+	return idx
+}
+
+// Returns the string at idx, panics if there is none
+func (t *StringTable) Lookup(idx uint64) string {
+	if idx >= len64(t.strings) {
+		panic("LiteCrate: interned string " + intStr(idx) + " is not in string table of length " + intStr(len(t.strings)))
+	}
+	return t.strings[idx]
+}
+
+// Returns the number of strings in the table
+func (t *StringTable) Len() int {
+	if t == nil {
+		return 0
+	}
+	return len(t.strings)
+}
+
+// Remove every string added after the first n, so strings interned by a trial write can be taken out again
+func (t *StringTable) truncate(n int) {
+	if t == nil {
+		return
+	}
+	for i, str := range t.strings[n:] {
+		delete(t.index, str)
+		t.strings[n+i] = ""
+	}
+	t.strings = t.strings[:n]
+}
+
+// Use the table's strings according to mode, as a counter followed by each string with its counter
+func (t *StringTable) UseSelf(crate *Crate, mode UseMode) {
+	UseSlice(crate, mode, &t.strings, crate.UseStringWithCounter)
+	if mode == Read {
+		t.index = make(map[string]uint64, len(t.strings))
+		for i, str := range t.strings {
+			t.index[str] = uint64(i)
+		}
+	}
+}
+
+/**************
+	INTERNED
+***************/
+
+// Set the table used by interned strings, or nil to stop using one.
+// UseWithStringTable() sets and restores the table itself
+func (c *Crate) SetStringTable(table *StringTable) {
+	c.strings = table
+}
+
+// Returns the table used by interned strings, or nil if there is none
+func (c *Crate) StringTable() *StringTable {
+	return c.strings
+}
+
+func (c *Crate) stringTable(name string) *StringTable {
+	if c.strings == nil {
+		panic("LiteCrate: " + name + "() requires a string table (see UseWithStringTable())")
+	}
+	return c.strings
+}
+
+// Discard next unread interned string in crate
+func (c *Crate) DiscardInternedString() {
+	c.DiscardUVarint()
+}
+
+// Return byte slice the next unread interned string's index occupies
+func (c *Crate) SliceInternedString() (slice []byte) {
+	return c.SliceUVarint()
+}
+
+// Write string to crate as its index in the crate's string table, adding it to the table if needed
+func (c *Crate) WriteInternedString(val string) {
+	c.WriteUVarint(c.stringTable("WriteInternedString").Intern(val))
+}
+
+// Read next interned string from crate, looking it up in the crate's string table
+func (c *Crate) ReadInternedString() (val string) {
+	table := c.stringTable("ReadInternedString")
+	idx, _ := c.ReadUVarint()
+	return table.Lookup(idx)
+}
+
+// Read next interned string from crate without advancing read index
+func (c *Crate) PeekInternedString() (val string) {
+	table := c.stringTable("PeekInternedString")
+	idx, _ := c.PeekUVarint()
+	return table.Lookup(idx)
+}
+
+// Use the string pointed to by val according to mode (interned):
+// Write = 'write val into crate', Read = 'read from crate into val',
+// Peek = 'read from crate into val without advancing index'
+// Slice = 'Return the slice the next unread val's index occupies without altering val'
+func (c *Crate) UseInternedString(val *string, mode UseMode) (sliceModeData []byte) {
+	switch mode {
+	case Write:
+		c.WriteInternedString(*val)
+	case Read:
+		*val = c.ReadInternedString()
+	case Peek:
+		*val = c.PeekInternedString()
+	case Discard:
+		c.DiscardInternedString()
+	case Slice:
+		sliceModeData = c.SliceInternedString()
+	default:
+		c.useCustomMode(val, mode, "UseInternedString")
+	}
+	return sliceModeData
+}
+
+// Use val with a new string table according to mode. The interned strings inside val are
+// collected in the table while writing, and the table is written in a header before val,
+// so each distinct string is written only once (logs and telemetry with repeated keys shrink dramatically).
+//
+// Write = 'write table and val into crate', Read = 'read table and val from crate into val',
+// Peek = 'read table and val from crate into val without advancing index'
+// Slice = 'Return the slice the table and val occupy without altering val'
+func (c *Crate) UseWithStringTable(val SelfSerializer, mode UseMode) (sliceModeData []byte) {
+	outer := c.strings
+	defer func() { c.strings = outer }()
+	table := NewStringTable()
+	c.strings = table
+	switch mode {
+	case Write:
+		start := c.beginLength()
+		c.WriteSelfSerializer(val)
+		header := NewCrate(16, FlagAutoDouble)
+		header.WriteSelfSerializer(table)
+		c.insertBytes(start, header.Data())
+	case Read:
+		c.ReadSelfSerializer(table)
+		c.ReadSelfSerializer(val)
+	case Peek:
+		idx := c.read
+		c.ReadSelfSerializer(table)
+		c.ReadSelfSerializer(val)
+		c.read = idx
+	case Discard, Slice:
+		idx := c.read
+		c.ReadSelfSerializer(table)
+		c.DiscardSelfSerializer(val)
+		if mode == Slice {
+			end := c.read
+			c.read = idx
+			return c.data[idx:end:end]
+		}
+	default:
+		c.useCustomMode(val, mode, "UseWithStringTable")
+		c.enterDepth()
+		defer c.leaveDepth()
+		val.UseSelf(c, mode)
+	}
+	return nil
+}
+
+// Moves everything written since start forward to make room for, then writes, data at start
+func (c *Crate) insertBytes(start uint64, data []byte) {
+	if c.makeRoom(start, len64(data)) {
+		copy(c.data[start:], data)
+	}
+}
@@ -0,0 +1,92 @@
+package litecrate_test
+
+import (
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+type logEntry struct {
+	Level   string
+	Service string
+	Message string
+}
+
+func (e *logEntry) UseSelf(crate *lite.Crate, mode lite.UseMode) {
+	crate.UseInternedString(&e.Level, mode)
+	crate.UseInternedString(&e.Service, mode)
+	crate.UseStringWithCounter(&e.Message, mode)
+}
+
+type logBatch struct {
+	Entries []logEntry
+}
+
+func (b *logBatch) UseSelf(crate *lite.Crate, mode lite.UseMode) {
+	lite.UseSlice(crate, mode, &b.Entries, func(entry *logEntry, mode lite.UseMode) []byte {
+		return crate.UseSelfSerializer(entry, mode)
+	})
+}
+
+func TestStringTable(t *testing.T) {
+	batch := logBatch{}
+	for i := 0; i < 100; i += 1 {
+		level := "INFO"
+		if i%10 == 0 {
+			level = "ERROR"
+		}
+		batch.Entries = append(batch.Entries, logEntry{Level: level, Service: "authentication-service", Message: "ok"})
+	}
+	crate := lite.NewCrate(16, lite.FlagAutoDouble)
+	crate.WriteU8(7)
+	crate.UseWithStringTable(&batch, lite.Write)
+	crate.WriteU8(8)
+	plain := lite.NewCrate(16, lite.FlagAutoDouble)
+	for i := range batch.Entries {
+		plain.WriteStringWithCounter(batch.Entries[i].Level)
+		plain.WriteStringWithCounter(batch.Entries[i].Service)
+		plain.WriteStringWithCounter(batch.Entries[i].Message)
+	}
+	if crate.WriteIndex() > 600 || crate.WriteIndex()*5 > plain.WriteIndex() {
+		t.Errorf("UseWithStringTable(Write) - FAIL: %d bytes, %d without interning", crate.WriteIndex(), plain.WriteIndex())
+	}
+	if crate.StringTable() != nil {
+		t.Errorf("UseWithStringTable(Write) - FAIL: table left set on crate")
+	}
+
+	crate.ReadU8()
+	var got logBatch
+	crate.UseWithStringTable(&got, lite.Peek)
+	if crate.ReadIndex() != 1 || len(got.Entries) != 100 {
+		t.Errorf("UseWithStringTable(Peek) - FAIL: index %d, %d entries", crate.ReadIndex(), len(got.Entries))
+	}
+	slice := crate.UseWithStringTable(&got, lite.Slice)
+	crate.UseWithStringTable(&got, lite.Read)
+	if uint64(len(slice)) != crate.ReadIndex()-1 || crate.ReadU8() != 8 {
+		t.Errorf("UseWithStringTable(Slice) - FAIL: %d bytes", len(slice))
+	}
+	for i := range got.Entries {
+		if got.Entries[i] != batch.Entries[i] {
+			t.Fatalf("UseWithStringTable(Read) - FAIL: entry %d: %+v != %+v", i, got.Entries[i], batch.Entries[i])
+		}
+	}
+
+	table := lite.NewStringTable()
+	if table.Intern("a") != 0 || table.Intern("b") != 1 || table.Intern("a") != 0 || table.Len() != 2 || table.Lookup(1) != "b" {
+		t.Errorf("StringTable - FAIL: unexpected indexes")
+	}
+	crate.Reset()
+	crate.SetStringTable(table)
+	crate.WriteInternedString("c")
+	if crate.PeekInternedString() != "c" || len(crate.SliceInternedString()) != 1 || crate.ReadInternedString() != "c" {
+		t.Errorf("WriteInternedString() - FAIL: did not use table set with SetStringTable()")
+	}
+	crate.WriteUVarint(10)
+	if catchPanic[error](func() { crate.ReadInternedString() }) == nil {
+		t.Errorf("ReadInternedString() - FAIL: index outside table did not panic")
+	}
+	crate.SetStringTable(nil)
+	if catchPanic[error](func() { crate.WriteInternedString("a") }) == nil {
+		t.Errorf("WriteInternedString() - FAIL: no panic without table")
+	}
+}
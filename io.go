@@ -0,0 +1,209 @@
+package litecrate
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Smallest amount of space ReadFrom() will grow the crate by before each read from its source
+const minReadFromGrow = 512
+
+// Implements io.Reader.
+// Copies unread bytes into p and advances the read index,
+// returns io.EOF if there are no unread bytes left
+func (c *Crate) Read(p []byte) (n int, err error) {
+	end := c.readEnd()
+	if c.read >= end {
+		if len(p) == 0 {
+			return 0, nil
+		}
+		return 0, io.EOF
+	}
+	n = copy(p, c.data[c.read:end])
+	c.read += uint64(n)
+	return n, nil
+}
+
+// Implements io.Writer.
+// Writes p to the crate, growing it if flagged for AutoGrow.
+// Unlike WriteBytes(), does not panic when it cannot grow: as much of p as fits is written
+// and io.ErrShortWrite is returned
+func (c *Crate) Write(p []byte) (n int, err error) {
+	length := len64(p)
+	if !c.WillAutoGrow() && length > c.SpaceLeft() {
+		n = copy(c.data[c.write:], p)
+		c.write += uint64(n)
+		return n, io.ErrShortWrite
+	}
+	c.WriteBytes(p)
+	return len(p), nil
+}
+
+// Implements io.ReaderFrom.
+// Reads from r into the crate until io.EOF, growing it if flagged for AutoGrow.
+// Returns io.ErrShortBuffer if the crate fills up and cannot grow before r is exhausted
+func (c *Crate) ReadFrom(r io.Reader) (n int64, err error) {
+	for {
+		if c.SpaceLeft() == 0 {
+			if !c.WillAutoGrow() {
+				return n, io.ErrShortBuffer
+			}
+			c.Grow(minReadFromGrow)
+		}
+		m, err := r.Read(c.data[c.write:])
+		if m < 0 {
+			panic("LiteCrate: io.Reader returned negative count from Read()")
+		}
+		c.write += uint64(m)
+		n += int64(m)
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+	}
+}
+
+// Implements io.WriterTo.
+// Writes all unread bytes to w and advances the read index by the number of bytes written
+func (c *Crate) WriteTo(w io.Writer) (n int64, err error) {
+	unread := c.data[c.read:c.readEnd()]
+	if len(unread) == 0 {
+		return 0, nil
+	}
+	m, err := w.Write(unread)
+	if m > len(unread) {
+		panic("LiteCrate: io.Writer returned invalid count from Write()")
+	}
+	c.read += uint64(m)
+	n = int64(m)
+	if err == nil && m != len(unread) {
+		err = io.ErrShortWrite
+	}
+	return n, err
+}
+
+// Writes text formatted by fmt.Fprintf() to the crate (without a length counter) and returns its size.
+// Unlike Write(), a crate that cannot grow panics the same as WriteString() instead of
+// writing part of the text, so it can be used inside TryWrite()
+func (c *Crate) Printf(format string, args ...any) (bytesWritten uint64) {
+	start := c.write
+	fmt.Fprintf((*printfWriter)(c), format, args...)
+	return c.write - start
+}
+
+// Writes to its crate with WriteBytes(), letting its panics escape fmt.Fprintf()
+type printfWriter Crate
+
+func (w *printfWriter) Write(p []byte) (n int, err error) {
+	(*Crate)(w).WriteBytes(p)
+	return len(p), nil
+}
+
+// Reads the next length-or-nil counter and returns a reader limited to the payload that follows it
+// (anything written with a ____WithCounter() method, including nested crates), so large payloads
+// can be streamed out with io.Copy() instead of being copied out by ReadBytesWithCounter().
+// Reading from the returned reader advances the crate's read index through the payload.
+// Panics if the crate does not hold the whole payload
+func (c *Crate) CounterReader() *io.LimitedReader {
+	length, _, _ := c.ReadLengthOrNil()
+	c.CheckRead(length)
+	return &io.LimitedReader{R: c, N: int64(length)}
+}
+
+/**************
+	BLOB STREAM
+***************/
+
+// Writes a blob of unknown length into a crate as a series of length-prefixed chunks,
+// see BeginBlobStream()
+type BlobStreamWriter struct {
+	crate  *Crate
+	closed bool
+}
+
+// Start writing a blob whose length is not known in advance (compressed streams, transcoded media...),
+// so it can be embedded without buffering it all first. Each Write() to the returned writer
+// becomes one chunk (a UVarint length followed by the bytes), and Close() writes the zero length
+// chunk that ends the blob. Nothing else may be written to the crate until the writer is closed.
+// Read the blob back with BlobStreamReader()
+func (c *Crate) BeginBlobStream() *BlobStreamWriter {
+	return &BlobStreamWriter{crate: c}
+}
+
+// Implements io.Writer.
+// Writes p as one chunk, empty writes write nothing
+func (w *BlobStreamWriter) Write(p []byte) (n int, err error) {
+	if w.closed {
+		return 0, errors.New("LiteCrate: write to closed blob stream")
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	w.crate.WriteUVarint(len64(p))
+	w.crate.WriteBytes(p)
+	return len(p), nil
+}
+
+// Implements io.Closer.
+// Ends the blob, closing more than once does nothing
+func (w *BlobStreamWriter) Close() error {
+	if !w.closed {
+		w.crate.WriteUVarint(0)
+		w.closed = true
+	}
+	return nil
+}
+
+// Reads a blob written with BeginBlobStream(), see BlobStreamReader()
+type BlobStreamReader struct {
+	crate *Crate
+	left  uint64
+	done  bool
+}
+
+// Returns a reader over the chunked blob starting at the read index, which advances
+// through the blob as it is read. The reader returns io.EOF after the last chunk,
+// or io.ErrUnexpectedEOF if the crate ends before the blob does
+func (c *Crate) BlobStreamReader() *BlobStreamReader {
+	return &BlobStreamReader{crate: c}
+}
+
+// Implements io.Reader
+func (r *BlobStreamReader) Read(p []byte) (n int, err error) {
+	c := r.crate
+	for r.left == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if !c.hasUVarint() {
+			return 0, io.ErrUnexpectedEOF
+		}
+		r.left, _ = c.ReadUVarint()
+		r.done = r.left == 0
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if c.ReadsLeft() == 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if uint64(len(p)) > r.left {
+		p = p[:r.left]
+	}
+	n, _ = c.Read(p)
+	r.left -= uint64(n)
+	return n, nil
+}
+
+// Returns whether a whole UVarint can be read at the read index
+func (c *Crate) hasUVarint() bool {
+	for i, end := c.read, c.readEnd(); i < end && i < c.read+9; i += 1 {
+		if c.data[i]&continueMask != continueMask || i == c.read+8 {
+			return true
+		}
+	}
+	return false
+}
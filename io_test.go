@@ -0,0 +1,134 @@
+package litecrate_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+var _ io.Reader = (*lite.Crate)(nil)
+var _ io.Writer = (*lite.Crate)(nil)
+var _ io.ReaderFrom = (*lite.Crate)(nil)
+var _ io.WriterTo = (*lite.Crate)(nil)
+
+func TestCrateIO(t *testing.T) {
+	source := bytes.Repeat([]byte("LiteCrate"), 200)
+	crate := lite.NewCrate(4, lite.FlagAutoDouble)
+	n, err := crate.ReadFrom(bytes.NewReader(source))
+	if err != nil || n != int64(len(source)) || !bytes.Equal(crate.Data(), source) {
+		t.Fatalf("ReadFrom - FAIL: n = %d, err = %v", n, err)
+	}
+	head := make([]byte, 9)
+	if m, err := io.ReadFull(crate, head); err != nil || m != 9 || string(head) != "LiteCrate" {
+		t.Errorf("Read - FAIL: %q, err = %v", head, err)
+	}
+	sink := bytes.Buffer{}
+	n, err = crate.WriteTo(&sink)
+	if err != nil || n != int64(len(source)-9) || !bytes.Equal(sink.Bytes(), source[9:]) {
+		t.Errorf("WriteTo - FAIL: n = %d, err = %v", n, err)
+	}
+	if m, err := crate.Read(head); m != 0 || err != io.EOF {
+		t.Errorf("Read - FAIL: expected io.EOF, got n = %d, err = %v", m, err)
+	}
+	static := lite.NewCrate(4, lite.FlagStatic)
+	if m, err := static.Write([]byte{1, 2, 3, 4, 5}); m != 4 || err != io.ErrShortWrite {
+		t.Errorf("Write - FAIL: expected short write, got n = %d, err = %v", m, err)
+	}
+	static.Reset()
+	if _, err := static.ReadFrom(bytes.NewReader(source)); err != io.ErrShortBuffer {
+		t.Errorf("ReadFrom - FAIL: expected io.ErrShortBuffer, got %v", err)
+	}
+	copied := lite.NewCrate(0, lite.FlagAutoDouble)
+	if _, err := io.Copy(copied, lite.OpenCrate(source, lite.FlagStatic)); err != nil || !bytes.Equal(copied.Data(), source) {
+		t.Errorf("io.Copy - FAIL: err = %v", err)
+	}
+}
+
+func TestCounterReader(t *testing.T) {
+	blob := bytes.Repeat([]byte("blob"), 1000)
+	crate := lite.NewCrate(16, lite.FlagAutoDouble)
+	crate.WriteBytesWithCounter(blob)
+	crate.WriteBytesWithCounter(nil)
+	crate.WriteStringWithCounter("tail")
+	sink := bytes.Buffer{}
+	if n, err := io.Copy(&sink, crate.CounterReader()); err != nil || n != int64(len(blob)) || !bytes.Equal(sink.Bytes(), blob) {
+		t.Errorf("CounterReader - FAIL: copied %d bytes, err = %v", n, err)
+	}
+	if n, err := io.Copy(&sink, crate.CounterReader()); err != nil || n != 0 {
+		t.Errorf("CounterReader - FAIL: nil payload copied %d bytes, err = %v", n, err)
+	}
+	if crate.ReadStringWithCounter() != "tail" {
+		t.Errorf("CounterReader - FAIL: read index not left after payload")
+	}
+	crate.Reset()
+	crate.WriteLength(10)
+	crate.WriteU8(1)
+	if catchPanic[error](func() { crate.CounterReader() }) == nil {
+		t.Errorf("CounterReader - FAIL: truncated payload did not panic")
+	}
+}
+
+func TestPrintf(t *testing.T) {
+	crate := lite.NewCrate(4, lite.FlagAutoDouble)
+	crate.WriteU8(0xFF)
+	n := crate.Printf("GET %s HTTP/1.1\r\nContent-Length: %d\r\n\r\n", "/index", 12)
+	crate.WriteU32(7)
+	header := "GET /index HTTP/1.1\r\nContent-Length: 12\r\n\r\n"
+	if n != uint64(len(header)) || string(crate.Data()[1:1+n]) != header || crate.WriteIndex() != 1+n+4 {
+		t.Errorf("Printf() - FAIL: wrote %d bytes: %q", n, crate.Data())
+	}
+	if n := crate.Printf(""); n != 0 {
+		t.Errorf("Printf() - FAIL: empty format wrote %d bytes", n)
+	}
+
+	static := lite.NewCrate(8, lite.FlagAutoDouble|lite.FlagNoGrow)
+	if err := static.TryWrite(func() { static.Printf("%08d", 1234) }); err != nil || string(static.Data()) != "00001234" {
+		t.Errorf("Printf() - FAIL: %q, err = %v", static.Data(), err)
+	}
+	static.Reset()
+	if err := static.TryWrite(func() { static.Printf("%d-%d", 12345, 67890) }); err != lite.ErrNoGrow || static.WriteIndex() != 0 {
+		t.Errorf("Printf() - FAIL: overflow returned %v and wrote %d bytes", err, static.WriteIndex())
+	}
+}
+
+var _ io.WriteCloser = (*lite.BlobStreamWriter)(nil)
+var _ io.Reader = (*lite.BlobStreamReader)(nil)
+
+func TestBlobStream(t *testing.T) {
+	source := bytes.Repeat([]byte("streamed "), 5000)
+	crate := lite.NewCrate(16, lite.FlagAutoDouble)
+	crate.WriteStringWithCounter("head")
+	stream := crate.BeginBlobStream()
+	if n, err := io.CopyBuffer(stream, bytes.NewReader(source), make([]byte, 1000)); err != nil || n != int64(len(source)) {
+		t.Fatalf("BlobStreamWriter - FAIL: copied %d bytes, err = %v", n, err)
+	}
+	stream.Close()
+	stream.Close()
+	if _, err := stream.Write([]byte{1}); err == nil {
+		t.Errorf("BlobStreamWriter - FAIL: write after close did not fail")
+	}
+	crate.WriteStringWithCounter("tail")
+	crate.BeginBlobStream().Close()
+
+	crate.DiscardStringWithCounter()
+	sink := bytes.Buffer{}
+	if n, err := io.Copy(&sink, crate.BlobStreamReader()); err != nil || !bytes.Equal(sink.Bytes(), source) {
+		t.Errorf("BlobStreamReader - FAIL: read %d bytes, err = %v", n, err)
+	}
+	if crate.ReadStringWithCounter() != "tail" {
+		t.Errorf("BlobStreamReader - FAIL: read index not left after blob")
+	}
+	if n, err := io.Copy(&sink, crate.BlobStreamReader()); err != nil || n != 0 || crate.ReadsLeft() != 0 {
+		t.Errorf("BlobStreamReader - FAIL: empty blob read %d bytes, err = %v", n, err)
+	}
+
+	for _, cut := range []uint64{6, 7, 8, 500} {
+		truncated := lite.OpenCrate(crate.Data()[:cut], lite.FlagStatic)
+		truncated.DiscardStringWithCounter()
+		if _, err := io.Copy(io.Discard, truncated.BlobStreamReader()); err != io.ErrUnexpectedEOF {
+			t.Errorf("BlobStreamReader - FAIL: blob cut at %d returned %v", cut, err)
+		}
+	}
+}
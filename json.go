@@ -0,0 +1,320 @@
+//go:build !litecrate_core && !litecrate_notools
+
+package litecrate
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+)
+
+/**************
+	JSON
+***************/
+
+// Read the next value described by schema from the crate (advancing the read index past it)
+// and return it as a JSON object with one member per schema field, in schema order.
+//
+// Integers and floats are JSON numbers (NaN and infinities are the strings "NaN", "+Inf" and "-Inf"),
+// complex numbers are [real, imaginary] arrays, strings are JSON strings, bytes are base64 strings,
+// slices are arrays, structs are objects, and maps are objects if their key is KindString,
+// otherwise arrays of [key, value] arrays. Nil bytes, slices and maps are null.
+// If the data does not match schema the read index is left where it was and an error is returned
+func (c *Crate) ToJSON(schema *Schema) (data []byte, err error) {
+	start := c.read
+	defer func() {
+		if r := recover(); r != nil {
+			c.read = start
+			data, err = nil, jsonError(r)
+		}
+	}()
+	return appendJSONFields(nil, c, schema.Fields), nil
+}
+
+// Write the JSON object in data to the crate as the value described by schema, the reverse of ToJSON().
+// Every schema field must be present in the object and no others. If data does not match schema
+// the write index is left where it was and an error is returned
+func (c *Crate) FromJSON(schema *Schema, data []byte) (err error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var val any
+	if err := decoder.Decode(&val); err != nil {
+		return err
+	}
+	c.BeginWrite()
+	defer func() {
+		if r := recover(); r != nil {
+			c.RollbackWrite()
+			err = jsonError(r)
+			return
+		}
+		c.CommitWrite()
+	}()
+	writeJSONFields(c, schema.Fields, val, schema.Name)
+	return nil
+}
+
+func jsonError(r any) error {
+	switch r := r.(type) {
+	case string:
+		return errors.New(r)
+	case error:
+		return r
+	}
+	panic(r)
+}
+
+func appendJSONFields(out []byte, crate *Crate, fields []Field) []byte {
+	out = append(out, '{')
+	for i := range fields {
+		if i > 0 {
+			out = append(out, ',')
+		}
+		out = appendJSONString(out, fields[i].Name)
+		out = append(out, ':')
+		out = fields[i].appendJSON(out, crate)
+	}
+	return append(out, '}')
+}
+
+func (f *Field) appendJSON(out []byte, crate *Crate) []byte {
+	switch f.Kind {
+	case KindBool:
+		return strconv.AppendBool(out, crate.ReadBool())
+	case KindU8, KindU16, KindU24, KindU32, KindU40, KindU48, KindU56, KindU64, KindUVarint:
+		return strconv.AppendUint(out, readKindUint(crate, f.Kind), 10)
+	case KindI8, KindI16, KindI24, KindI32, KindI40, KindI48, KindI56, KindI64, KindVarint:
+		return strconv.AppendInt(out, readKindInt(crate, f.Kind), 10)
+	case KindF32:
+		return appendJSONFloat(out, float64(crate.ReadF32()), 32)
+	case KindF64:
+		return appendJSONFloat(out, crate.ReadF64(), 64)
+	case KindC64:
+		val := crate.ReadC64()
+		out = appendJSONFloat(append(out, '['), float64(real(val)), 32)
+		return append(appendJSONFloat(append(out, ','), float64(imag(val)), 32), ']')
+	case KindC128:
+		val := crate.ReadC128()
+		out = appendJSONFloat(append(out, '['), real(val), 64)
+		return append(appendJSONFloat(append(out, ','), imag(val), 64), ']')
+	case KindString:
+		return appendJSONString(out, crate.ReadStringWithCounter())
+	case KindBytes:
+		val := crate.ReadBytesWithCounter()
+		if val == nil {
+			return append(out, "null"...)
+		}
+		return appendJSONString(out, base64.StdEncoding.EncodeToString(val))
+	case KindSlice, KindMap:
+		length, isNil, _ := crate.ReadLengthOrNil()
+		if isNil {
+			return append(out, "null"...)
+		}
+		f.checkContainer()
+		if f.Kind == KindMap && f.Key.Kind == KindString {
+			out = append(out, '{')
+			for i := uint64(0); i < length; i += 1 {
+				if i > 0 {
+					out = append(out, ',')
+				}
+				out = appendJSONString(out, crate.ReadStringWithCounter())
+				out = f.Elem.appendJSON(append(out, ':'), crate)
+			}
+			return append(out, '}')
+		}
+		out = append(out, '[')
+		for i := uint64(0); i < length; i += 1 {
+			if i > 0 {
+				out = append(out, ',')
+			}
+			if f.Kind == KindMap {
+				out = f.Key.appendJSON(append(out, '['), crate)
+				out = append(f.Elem.appendJSON(append(out, ','), crate), ']')
+			} else {
+				out = f.Elem.appendJSON(out, crate)
+			}
+		}
+		return append(out, ']')
+	case KindStruct:
+		return appendJSONFields(out, crate, f.Fields)
+	}
+	panic("LiteCrate: field " + f.Name + " has unknown kind " + f.Kind.String())
+}
+
+func appendJSONString(out []byte, val string) []byte {
+	quoted, _ := json.Marshal(val)
+	return append(out, quoted...)
+}
+
+func appendJSONFloat(out []byte, val float64, bits int) []byte {
+	switch {
+	case math.IsNaN(val):
+		return append(out, `"NaN"`...)
+	case math.IsInf(val, 1):
+		return append(out, `"+Inf"`...)
+	case math.IsInf(val, -1):
+		return append(out, `"-Inf"`...)
+	}
+	return strconv.AppendFloat(out, val, 'g', -1, bits)
+}
+
+func writeJSONFields(crate *Crate, fields []Field, val any, name string) {
+	object, ok := val.(map[string]any)
+	if !ok {
+		panic("LiteCrate: JSON for " + name + " must be an object")
+	}
+	if len(object) > len(fields) {
+		for key := range object {
+			if !hasField(fields, key) {
+				panic("LiteCrate: JSON for " + name + " has unknown field " + strconv.Quote(key))
+			}
+		}
+	}
+	for i := range fields {
+		member, ok := object[fields[i].Name]
+		if !ok {
+			panic("LiteCrate: JSON for " + name + " is missing field " + strconv.Quote(fields[i].Name))
+		}
+		fields[i].writeJSON(crate, member)
+	}
+}
+
+func hasField(fields []Field, name string) bool {
+	for i := range fields {
+		if fields[i].Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Field) writeJSON(crate *Crate, val any) {
+	switch f.Kind {
+	case KindBool:
+		b, ok := val.(bool)
+		if !ok {
+			f.jsonTypePanic("a bool")
+		}
+		crate.WriteBool(b)
+	case KindU8, KindU16, KindU24, KindU32, KindU40, KindU48, KindU56, KindU64, KindUVarint:
+		num, _ := val.(json.Number)
+		u, err := strconv.ParseUint(string(num), 10, kindBits(f.Kind))
+		if err != nil {
+			f.jsonTypePanic("an unsigned integer that fits in " + f.Kind.String())
+		}
+		writeKindUint(crate, f.Kind, u)
+	case KindI8, KindI16, KindI24, KindI32, KindI40, KindI48, KindI56, KindI64, KindVarint:
+		num, _ := val.(json.Number)
+		i, err := strconv.ParseInt(string(num), 10, kindBits(f.Kind))
+		if err != nil {
+			f.jsonTypePanic("an integer that fits in " + f.Kind.String())
+		}
+		writeKindInt(crate, f.Kind, i)
+	case KindF32:
+		crate.WriteF32(float32(f.jsonFloat(val, 32)))
+	case KindF64:
+		crate.WriteF64(f.jsonFloat(val, 64))
+	case KindC64, KindC128:
+		pair, ok := val.([]any)
+		if !ok || len(pair) != 2 {
+			f.jsonTypePanic("a [real, imaginary] array")
+		}
+		if f.Kind == KindC64 {
+			crate.WriteC64(complex(float32(f.jsonFloat(pair[0], 32)), float32(f.jsonFloat(pair[1], 32))))
+		} else {
+			crate.WriteC128(complex(f.jsonFloat(pair[0], 64), f.jsonFloat(pair[1], 64)))
+		}
+	case KindString:
+		str, ok := val.(string)
+		if !ok {
+			f.jsonTypePanic("a string")
+		}
+		crate.WriteStringWithCounter(str)
+	case KindBytes:
+		if val == nil {
+			crate.WriteBytesWithCounter(nil)
+			return
+		}
+		str, _ := val.(string)
+		decoded, err := base64.StdEncoding.DecodeString(str)
+		if err != nil || decoded == nil {
+			f.jsonTypePanic("a base64 string")
+		}
+		crate.WriteBytesWithCounter(decoded)
+	case KindSlice, KindMap:
+		if val == nil {
+			crate.WriteLengthOrNil(0, true)
+			return
+		}
+		f.checkContainer()
+		if object, ok := val.(map[string]any); ok && f.Kind == KindMap && f.Key.Kind == KindString {
+			keys := make([]string, 0, len(object))
+			for key := range object {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			crate.WriteLengthOrNil(uint64(len(keys)), false)
+			for _, key := range keys {
+				crate.WriteStringWithCounter(key)
+				f.Elem.writeJSON(crate, object[key])
+			}
+			return
+		}
+		array, ok := val.([]any)
+		if !ok {
+			f.jsonTypePanic("an array")
+		}
+		crate.WriteLengthOrNil(uint64(len(array)), false)
+		for _, elem := range array {
+			if f.Kind == KindMap {
+				pair, ok := elem.([]any)
+				if !ok || len(pair) != 2 {
+					f.jsonTypePanic("an array of [key, value] arrays")
+				}
+				f.Key.writeJSON(crate, pair[0])
+				f.Elem.writeJSON(crate, pair[1])
+			} else {
+				f.Elem.writeJSON(crate, elem)
+			}
+		}
+	case KindStruct:
+		writeJSONFields(crate, f.Fields, val, f.Name)
+	default:
+		panic("LiteCrate: field " + f.Name + " has unknown kind " + f.Kind.String())
+	}
+}
+
+func (f *Field) jsonFloat(val any, bits int) float64 {
+	var str string
+	switch val := val.(type) {
+	case json.Number:
+		str = string(val)
+	case string:
+		if val != "NaN" && val != "+Inf" && val != "-Inf" {
+			f.jsonTypePanic("a number")
+		}
+		str = val
+	default:
+		f.jsonTypePanic("a number")
+	}
+	num, err := strconv.ParseFloat(str, bits)
+	if err != nil {
+		f.jsonTypePanic("a number that fits in " + f.Kind.String())
+	}
+	return num
+}
+
+func (f *Field) jsonTypePanic(expected string) {
+	panic("LiteCrate: JSON for field " + f.Name + " must be " + expected)
+}
+
+// Panics if a slice or map field is missing the fields describing its contents
+func (f *Field) checkContainer() {
+	if f.Elem == nil || (f.Kind == KindMap && f.Key == nil) {
+		panic("LiteCrate: " + f.Kind.String() + " field " + f.Name + " has no Key or Elem field")
+	}
+}
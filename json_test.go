@@ -0,0 +1,113 @@
+//go:build !litecrate_core && !litecrate_notools
+
+package litecrate_test
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+var jsonSchema = &lite.Schema{Name: "Order", Fields: []lite.Field{
+	{Name: "id", Kind: lite.KindU40},
+	{Name: "delta", Kind: lite.KindI24},
+	{Name: "paid", Kind: lite.KindBool},
+	{Name: "price", Kind: lite.KindF64},
+	{Name: "ratio", Kind: lite.KindF32},
+	{Name: "phase", Kind: lite.KindC128},
+	{Name: "note", Kind: lite.KindString},
+	{Name: "blob", Kind: lite.KindBytes},
+	{Name: "tags", Kind: lite.KindSlice, Elem: &lite.Field{Name: "tag", Kind: lite.KindString}},
+	{Name: "stock", Kind: lite.KindMap, Key: &lite.Field{Name: "sku", Kind: lite.KindString}, Elem: &lite.Field{Name: "count", Kind: lite.KindVarint}},
+	{Name: "lines", Kind: lite.KindMap, Key: &lite.Field{Name: "line", Kind: lite.KindU8}, Elem: &lite.Field{Name: "qty", Kind: lite.KindUVarint}},
+	{Name: "owner", Kind: lite.KindStruct, Fields: []lite.Field{
+		{Name: "name", Kind: lite.KindString},
+		{Name: "age", Kind: lite.KindI8},
+	}},
+}}
+
+func writeJSONOrder(crate *lite.Crate) {
+	crate.WriteU40(1 << 39)
+	crate.WriteI24(-42)
+	crate.WriteBool(true)
+	crate.WriteF64(math.Inf(-1))
+	crate.WriteF32(0.5)
+	crate.WriteC128(complex(1.5, -2))
+	crate.WriteStringWithCounter("a \"quoted\" note")
+	crate.WriteBytesWithCounter(nil)
+	crate.WriteLengthOrNil(2, false)
+	crate.WriteStringWithCounter("new")
+	crate.WriteStringWithCounter("sale")
+	crate.WriteLengthOrNil(2, false)
+	crate.WriteStringWithCounter("a1")
+	crate.WriteVarint(-3)
+	crate.WriteStringWithCounter("b2")
+	crate.WriteVarint(7)
+	crate.WriteLengthOrNil(1, false)
+	crate.WriteU8(9)
+	crate.WriteUVarint(300)
+	crate.WriteStringWithCounter("Ann")
+	crate.WriteI8(-1)
+}
+
+func TestToJSON(t *testing.T) {
+	crate := lite.NewCrate(64, lite.FlagAutoDouble)
+	writeJSONOrder(crate)
+	crate.WriteU8(42)
+	data, err := crate.ToJSON(jsonSchema)
+	expected := `{"id":549755813888,"delta":-42,"paid":true,"price":"-Inf","ratio":0.5,"phase":[1.5,-2],` +
+		`"note":"a \"quoted\" note","blob":null,"tags":["new","sale"],"stock":{"a1":-3,"b2":7},` +
+		`"lines":[[9,300]],"owner":{"name":"Ann","age":-1}}`
+	if err != nil || string(data) != expected {
+		t.Fatalf("ToJSON() - FAIL:\n%s\n%v", data, err)
+	}
+	if crate.ReadU8() != 42 {
+		t.Errorf("ToJSON() - FAIL: read index not left after value")
+	}
+
+	other := lite.NewCrate(64, lite.FlagAutoDouble)
+	if err := other.FromJSON(jsonSchema, data); err != nil {
+		t.Fatalf("FromJSON() - FAIL: %v", err)
+	}
+	if !bytes.Equal(other.Data(), crate.Data()[:crate.WriteIndex()-1]) {
+		t.Errorf("FromJSON() - FAIL: round trip mismatch\n%v\n%v", other.Data(), crate.Data())
+	}
+
+	short := lite.OpenCrate(crate.Data()[:10], lite.FlagStatic)
+	if _, err := short.ToJSON(jsonSchema); err == nil || short.ReadIndex() != 0 {
+		t.Errorf("ToJSON(short) - FAIL: %v, read index %d", err, short.ReadIndex())
+	}
+}
+
+func TestFromJSON(t *testing.T) {
+	schema := &lite.Schema{Name: "Small", Fields: []lite.Field{
+		{Name: "n", Kind: lite.KindU8},
+		{Name: "b", Kind: lite.KindBytes},
+		{Name: "f", Kind: lite.KindF32},
+	}}
+	crate := lite.NewCrate(8, lite.FlagAutoDouble)
+	if err := crate.FromJSON(schema, []byte(`{"n":255,"b":"AQID","f":"NaN"}`)); err != nil {
+		t.Fatalf("FromJSON() - FAIL: %v", err)
+	}
+	if crate.ReadU8() != 255 || !bytes.Equal(crate.ReadBytesWithCounter(), []byte{1, 2, 3}) || !math.IsNaN(float64(crate.ReadF32())) {
+		t.Errorf("FromJSON() - FAIL: wrote %v", crate.Data())
+	}
+	written := crate.WriteIndex()
+	for _, bad := range []string{
+		`{"n":256,"b":null,"f":0}`,
+		`{"n":-1,"b":null,"f":0}`,
+		`{"n":1.5,"b":null,"f":0}`,
+		`{"n":1,"b":"!!","f":0}`,
+		`{"n":1,"b":null,"f":1e39}`,
+		`{"n":1,"b":null}`,
+		`{"n":1,"b":null,"f":0,"x":0}`,
+		`[1,2,3]`,
+		`{"n":1`,
+	} {
+		if err := crate.FromJSON(schema, []byte(bad)); err == nil || crate.WriteIndex() != written {
+			t.Errorf("FromJSON(%s) - FAIL: %v, write index %d", bad, err, crate.WriteIndex())
+		}
+	}
+}
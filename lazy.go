@@ -0,0 +1,163 @@
+package litecrate
+
+import (
+	"errors"
+	"sync"
+)
+
+/**************
+	LAZY
+***************/
+
+// A value that is only decoded the first time Get() is called.
+// Useful for large fields (images, embedded documents) that most readers never look at.
+//
+// The zero value holds the zero value of T. All methods are safe to call concurrently
+type Lazy[T any] struct {
+	mutex   sync.Mutex
+	raw     []byte
+	value   T
+	decoded bool
+	err     error
+	use     func(crate *Crate, val *T, mode UseMode)
+	from    Crate // Holds the flags, blob resolver, depth and decoding limits of the crate the value was read from
+}
+
+// Create a Lazy that already holds val
+func NewLazy[T any](val T) *Lazy[T] {
+	return &Lazy[T]{value: val, decoded: true}
+}
+
+// Returns the value, decoding it first if this is the first call since it was read.
+// Panics with the error if the recorded bytes cannot be decoded (see GetErr())
+func (l *Lazy[T]) Get() T {
+	val, err := l.GetErr()
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
+
+// Returns the value, decoding it first if this is the first call since it was read,
+// or the error that decoding it failed with (returned again by every later call).
+// The value is decoded with the flags, blob resolver and decoding limits of the crate it was read from,
+// and counts towards that crate's SetMaxDepth() limit as if it were still nested where it was read
+func (l *Lazy[T]) GetErr() (val T, err error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if !l.decoded {
+		if l.raw != nil {
+			l.err = l.decode()
+		}
+		l.decoded = true
+	}
+	return l.value, l.err
+}
+
+// Decode the recorded bytes into value, in a crate with the settings of the crate they were read from
+func (l *Lazy[T]) decode() (err error) {
+	crate := l.from
+	crate.data, crate.write = l.raw, len64(l.raw)
+	defer func() {
+		switch r := recover().(type) {
+		case nil:
+			err = crate.Err()
+		case string:
+			err = errors.New(r)
+		case error:
+			err = r
+		default:
+			panic(r)
+		}
+	}()
+	l.use(&crate, &l.value, Read)
+	return nil
+}
+
+// Replaces the value, discarding any undecoded bytes
+func (l *Lazy[T]) Set(val T) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.value = val
+	l.raw = nil
+	l.err = nil
+	l.decoded = true
+}
+
+// Returns whether the value has been decoded (or was never read from a crate)
+func (l *Lazy[T]) IsDecoded() bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.decoded || l.raw == nil
+}
+
+// Returns the encoded bytes of the value as read from a crate (not including counter),
+// or nil if the value was Set() or never read
+func (l *Lazy[T]) Raw() []byte {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.raw
+}
+
+// Helper func for selectively reading/writing a Lazy value, dependant on mode.
+// The value is written with a preceding length counter so readers can record its bytes
+// without decoding them. useValue() is stored by Read/Peek and called by Get() to decode the recorded bytes
+// from a separate crate. Values that were read but never decoded are written back without being re-encoded.
+//
+// Example:
+//
+//	type document struct {
+//		Title string
+//		Image Lazy[[]byte]
+//	}
+//
+//	func (d *document) UseSelf(crate *Crate, mode UseMode) {
+//		crate.UseStringWithCounter(&d.Title, mode)
+//		UseLazy(crate, mode, &d.Image, func(crate *Crate, val *[]byte, mode UseMode) {
+//			crate.UseBytesWithCounter(val, mode)
+//		})
+//	}
+func UseLazy[T any](crate *Crate, mode UseMode, lazy *Lazy[T], useValue func(crate *Crate, val *T, mode UseMode)) (sliceModeData []byte) {
+	switch mode {
+	case Write:
+		lazy.mutex.Lock()
+		defer lazy.mutex.Unlock()
+		if lazy.raw != nil {
+			crate.WriteLength(len64(lazy.raw))
+			crate.WriteBytes(lazy.raw)
+			return nil
+		}
+		start := crate.beginLength()
+		useValue(crate, &lazy.value, Write)
+		crate.insertLength(start)
+	case Read, Peek:
+		idx := crate.read
+		length, _ := crate.ReadLength()
+		raw := crate.ReadBytes(length)
+		if mode == Peek {
+			crate.read = idx
+		}
+		lazy.mutex.Lock()
+		defer lazy.mutex.Unlock()
+		var zero T
+		lazy.raw = raw
+		lazy.value = zero
+		lazy.decoded = false
+		lazy.err = nil
+		lazy.use = useValue
+		lazy.from = Crate{
+			flags:    crate.flags | FlagNoGrow,
+			resolver: crate.resolver,
+			depth:    crate.depth,
+			maxDepth: crate.maxDepth,
+			maxAlloc: crate.maxAlloc,
+		}
+	case Discard:
+		crate.DiscardBytesWithCounter()
+	case Slice:
+		sliceModeData = crate.SliceBytesWithCounter()
+	default:
+		crate.useCustomMode(lazy, mode, "UseLazy")
+	}
+	return sliceModeData
+}
@@ -0,0 +1,135 @@
+package litecrate_test
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+type lazyDocument struct {
+	Title string
+	Pages lite.Lazy[[]string]
+}
+
+func (d *lazyDocument) UseSelf(crate *lite.Crate, mode lite.UseMode) {
+	crate.UseStringWithCounter(&d.Title, mode)
+	lite.UseLazy(crate, mode, &d.Pages, func(crate *lite.Crate, val *[]string, mode lite.UseMode) {
+		lite.UseSlice(crate, mode, val, crate.UseStringWithCounter)
+	})
+}
+
+func TestLazy(t *testing.T) {
+	pages := []string{"one", "two", "three"}
+	doc := lazyDocument{Title: "book"}
+	doc.Pages.Set(pages)
+	crate := lite.NewCrate(16, lite.FlagAutoDouble)
+	crate.WriteSelfSerializer(&doc)
+	crate.WriteU8(42)
+	written := crate.DataCopy()
+
+	var peeked lazyDocument
+	crate.PeekSelfSerializer(&peeked)
+	if crate.ReadIndex() != 0 || peeked.Title != "book" || peeked.Pages.IsDecoded() {
+		t.Errorf("UseLazy(Peek) - FAIL: index %d, title %q, decoded %t", crate.ReadIndex(), peeked.Title, peeked.Pages.IsDecoded())
+	}
+	crate.DiscardStringWithCounter()
+	var pagesOnly lite.Lazy[[]string]
+	useNothing := func(crate *lite.Crate, val *[]string, mode lite.UseMode) {}
+	lite.UseLazy(crate, lite.Peek, &pagesOnly, useNothing)
+	if crate.ReadIndex() != 5 || pagesOnly.IsDecoded() {
+		t.Errorf("UseLazy(Peek) - FAIL: index %d != 5 or value decoded", crate.ReadIndex())
+	}
+	slice := lite.UseLazy(crate, lite.Slice, &pagesOnly, useNothing)
+	lite.UseLazy(crate, lite.Discard, &pagesOnly, useNothing)
+	if !bytes.Equal(slice, pagesOnly.Raw()) || crate.ReadU8() != 42 {
+		t.Errorf("UseLazy(Slice/Discard) - FAIL: slice %v != %v or index %d wrong", slice, pagesOnly.Raw(), crate.ReadIndex())
+	}
+
+	var read lazyDocument
+	crate.Reset()
+	crate.WriteBytes(written)
+	crate.ReadSelfSerializer(&read)
+	if read.Pages.IsDecoded() || len(read.Pages.Raw()) == 0 {
+		t.Errorf("UseLazy(Read) - FAIL: value decoded before Get()")
+	}
+	reencoded := lite.NewCrate(16, lite.FlagAutoDouble)
+	reencoded.WriteSelfSerializer(&read)
+	if !bytes.Equal(reencoded.Data(), written[:len(written)-1]) {
+		t.Errorf("UseLazy(Write) - FAIL: undecoded value not written back unchanged: %v != %v", reencoded.Data(), written[:len(written)-1])
+	}
+
+	var wg sync.WaitGroup
+	results := make([][]string, 8)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = read.Pages.Get()
+		}(i)
+	}
+	wg.Wait()
+	for i, got := range results {
+		if len(got) != len(pages) || got[0] != pages[0] || got[2] != pages[2] {
+			t.Errorf("Lazy.Get() - FAIL: goroutine %d got %v != %v", i, got, pages)
+		}
+	}
+	if !read.Pages.IsDecoded() {
+		t.Errorf("Lazy.IsDecoded() - FAIL: false after Get()")
+	}
+
+	read.Pages.Set([]string{"new"})
+	if read.Pages.Raw() != nil || read.Pages.Get()[0] != "new" {
+		t.Errorf("Lazy.Set() - FAIL: raw bytes kept or value not replaced")
+	}
+
+	var empty lazyDocument
+	crate.Reset()
+	crate.WriteSelfSerializer(&empty)
+	crate.ReadSelfSerializer(&read)
+	if read.Pages.Get() != nil {
+		t.Errorf("Lazy zero value - FAIL: %v != nil", read.Pages.Get())
+	}
+	if lite.NewLazy(7).Get() != 7 {
+		t.Errorf("NewLazy() - FAIL: value not held")
+	}
+	if catchPanic[error](func() { lite.UseLazy(crate, lite.UseMode(255), &read.Pages, nil) }) == nil {
+		t.Errorf("UseLazy - FAIL: invalid mode did not panic")
+	}
+}
+
+func TestLazyLimits(t *testing.T) {
+	doc := lazyDocument{Title: "book"}
+	doc.Pages.Set([]string{"one", "two", "three"})
+	data := lite.NewCrate(16, lite.FlagAutoDouble)
+	data.WriteSelfSerializer(&doc)
+
+	var read lazyDocument
+	crate := lite.OpenCrate(data.Data(), lite.FlagStatic)
+	crate.SetMaxReadAlloc(32)
+	crate.ReadSelfSerializer(&read)
+	var allocErr *lite.AllocError
+	if _, err := read.Pages.GetErr(); !errors.As(err, &allocErr) {
+		t.Errorf("Lazy.GetErr() - FAIL: got %v, expected *AllocError from the crate it was read from", err)
+	}
+	if catchPanic[error](func() { read.Pages.Get() }) == nil {
+		t.Errorf("Lazy.Get() - FAIL: did not panic with the decoding error")
+	}
+
+	// The title is read at depth 1, so the pages slice inside the lazy value is at depth 2
+	crate = lite.OpenCrate(data.Data(), lite.FlagStatic)
+	crate.SetMaxDepth(1)
+	crate.ReadSelfSerializer(&read)
+	var depthErr *lite.DepthError
+	if _, err := read.Pages.GetErr(); !errors.As(err, &depthErr) {
+		t.Errorf("Lazy.GetErr() - FAIL: got %v, expected *DepthError from the crate it was read from", err)
+	}
+	crate.SetMaxDepth(2)
+	crate.ResetReadIndex()
+	crate.ReadSelfSerializer(&read)
+	if pages, err := read.Pages.GetErr(); err != nil || len(pages) != 3 {
+		t.Errorf("Lazy.GetErr() - FAIL: got %v, %v within the crate's limits", pages, err)
+	}
+}
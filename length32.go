@@ -0,0 +1,100 @@
+package litecrate
+
+// Largest length WriteLength32() can write: a length-or-nil of at most 4 bytes holds 28 bits, and 0 is nil
+const MaxLength32 = 1<<28 - 2
+
+/**************
+	LENGTH-OR-NIL 32
+***************/
+
+// A length-or-nil limited to 1-4 bytes, for protocols whose decoders cannot budget for a 9 byte counter.
+// The wire format is the same as a length-or-nil, so a Length32 can be read with ReadLengthOrNil(),
+// and a length-or-nil of 4 bytes or fewer can be read with ReadLength32()
+
+// Returns the size of the length32 at the read index, panicking if it is longer than 4 bytes
+func (c *Crate) findLength32Bytes() (n uint64) {
+	n = 1
+	end := c.readEnd()
+	for c.read+n <= end && c.data[c.read+n-1]&continueMask == continueMask {
+		if n == 4 {
+			panic("LiteCrate: length-or-nil at read index " + intStr(c.read) + " is longer than 4 bytes")
+		}
+		n += 1
+	}
+	return n
+}
+
+// Discard next 1-4 unread bytes in crate,
+// dependant on length or nil (UVarint where 0 = nil, 1 = 0, 2 = 1...)
+func (c *Crate) DiscardLength32() (bytesDiscarded uint64) {
+	bytesDiscarded = c.findLength32Bytes()
+	c.DiscardN(bytesDiscarded)
+	return bytesDiscarded
+}
+
+// Return byte slice the next unread 1-4 byte length or nil occupies
+// (UVarint where 0 = nil, 1 = 0, 2 = 1...)
+func (c *Crate) SliceLength32() (slice []byte) {
+	n := c.findLength32Bytes()
+	c.CheckRead(n)
+	return c.data[c.read : c.read+n : c.read+n]
+}
+
+// Write length or nil (UVarint where 0 = nil, 1 = 0, 2 = 1...) to crate.
+// Uses 1-4 bytes dependant on length
+//
+// Panics if length is greater than MaxLength32 (268435454)
+func (c *Crate) WriteLength32(length uint64, isNil bool) (bytesWritten uint64) {
+	if length > MaxLength32 && !isNil {
+		panic("LiteCrate: length " + intStr(length) + " does not fit in 4 bytes (max length: " + intStr(MaxLength32) + ")")
+	}
+	return c.WriteLengthOrNil(length, isNil)
+}
+
+// Read next 1-4 bytes from crate as length or nil (UVarint where 0 = nil, 1 = 0, 2 = 1...).
+// Panics if the length-or-nil is longer than 4 bytes
+func (c *Crate) ReadLength32() (length uint64, isNil bool, bytesRead uint64) {
+	bytesRead = c.findLength32Bytes()
+	c.CheckRead(bytesRead)
+	for i := uint64(0); i < bytesRead; i += 1 {
+		length |= uint64(c.data[c.read+i]&countMask) << (i * countShift)
+	}
+	c.read += bytesRead
+	isNil = length == 0
+	if !isNil {
+		length -= 1
+	}
+	return length, isNil, bytesRead
+}
+
+// Read next 1-4 bytes from crate as length or nil (UVarint where 0 = nil, 1 = 0, 2 = 1...)
+// without advancing read index
+func (c *Crate) PeekLength32() (length uint64, isNil bool, bytesRead uint64) {
+	idx := c.read
+	length, isNil, bytesRead = c.ReadLength32()
+	c.read = idx
+	return length, isNil, bytesRead
+}
+
+// Use the length pointed to and writeNil/readNil (in Write/Read mode)
+// as a 1-4 byte UVarint where 0 = nil, 1 = 0, 2 = 1..., according to mode:
+// Write = 'write length or nil into crate', Read = 'read from crate into length and return readNil if nil',
+// Peek = 'read from crate into length and return readNil if nil, without advancing index'
+// Slice = 'Return the slice the next unread length-or-nil occupies without altering length'
+func (c *Crate) UseLength32(length *uint64, writeNil bool, mode UseMode) (readNil bool, bytesUsed uint64, sliceModeData []byte) {
+	switch mode {
+	case Write:
+		bytesUsed = c.WriteLength32(*length, writeNil)
+	case Read:
+		*length, readNil, bytesUsed = c.ReadLength32()
+	case Peek:
+		*length, readNil, bytesUsed = c.PeekLength32()
+	case Discard:
+		bytesUsed = c.DiscardLength32()
+	case Slice:
+		sliceModeData = c.SliceLength32()
+	default:
+		c.useCustomMode(length, mode, "UseLength32")
+	}
+	return readNil, bytesUsed, sliceModeData
+}
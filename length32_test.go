@@ -0,0 +1,74 @@
+package litecrate_test
+
+import (
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+func TestLength32(t *testing.T) {
+	crate := lite.NewCrate(8, lite.FlagAutoDouble)
+	values := []struct {
+		length uint64
+		isNil  bool
+		size   uint64
+	}{
+		{0, true, 1},
+		{0, false, 1},
+		{126, false, 1},
+		{127, false, 2},
+		{16382, false, 2},
+		{16383, false, 3},
+		{lite.MaxLength32, false, 4},
+	}
+	for _, test := range values {
+		crate.Reset()
+		length := test.length
+		_, written, _ := crate.UseLength32(&length, test.isNil, lite.Write)
+		if written != test.size || crate.WriteIndex() != test.size {
+			t.Errorf("WriteLength32(%d) - FAIL: wrote %d bytes, expected %d", test.length, written, test.size)
+		}
+		var peeked, read uint64
+		_, peekedBytes, _ := crate.UseLength32(&peeked, false, lite.Peek)
+		_, _, slice := crate.UseLength32(&read, false, lite.Slice)
+		readNil, readBytes, _ := crate.UseLength32(&read, false, lite.Read)
+		if read != test.length || peeked != test.length || readNil != test.isNil {
+			t.Errorf("ReadLength32(%d) - FAIL: read %d (nil %v), peeked %d", test.length, read, readNil, peeked)
+		}
+		if peekedBytes != test.size || readBytes != test.size || uint64(len(slice)) != test.size || crate.ReadsLeft() != 0 {
+			t.Errorf("ReadLength32(%d) - FAIL: used %d/%d bytes, slice %d bytes, expected %d", test.length, peekedBytes, readBytes, len(slice), test.size)
+		}
+		reread := lite.OpenCrate(crate.Data(), lite.FlagStatic)
+		if orNil, isNil, _ := reread.ReadLengthOrNil(); orNil != test.length || isNil != test.isNil {
+			t.Errorf("ReadLengthOrNil() - FAIL: read Length32 %d as %d", test.length, orNil)
+		}
+		reread = lite.OpenCrate(crate.Data(), lite.FlagStatic)
+		if reread.DiscardLength32() != test.size || reread.ReadsLeft() != 0 {
+			t.Errorf("DiscardLength32(%d) - FAIL: did not discard %d bytes", test.length, test.size)
+		}
+	}
+}
+
+func TestLength32Overflow(t *testing.T) {
+	crate := lite.NewCrate(16, lite.FlagAutoDouble)
+	if catchPanic[error](func() { crate.WriteLength32(lite.MaxLength32+1, false) }) == nil {
+		t.Error("WriteLength32() - FAIL: wrote length past MaxLength32")
+	}
+	if crate.WriteIndex() != 0 {
+		t.Error("WriteLength32() - FAIL: overflowing length was partly written")
+	}
+	crate.WriteLength32(1<<40, true)
+	crate.Reset()
+	crate.WriteLengthOrNil(lite.MaxLength32+1, false)
+	if catchPanic[error](func() { crate.ReadLength32() }) == nil {
+		t.Error("ReadLength32() - FAIL: read 5 byte length-or-nil")
+	}
+	if catchPanic[error](func() { crate.DiscardLength32() }) == nil || crate.ReadIndex() != 0 {
+		t.Error("DiscardLength32() - FAIL: discarded 5 byte length-or-nil")
+	}
+	crate.Reset()
+	crate.WriteU8(0x80)
+	if catchPanic[error](func() { crate.ReadLength32() }) == nil {
+		t.Error("ReadLength32() - FAIL: read truncated length-or-nil")
+	}
+}
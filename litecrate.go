@@ -1,6 +1,7 @@
 package litecrate
 
 import (
+	"errors"
 	"unsafe"
 )
 
@@ -15,6 +16,11 @@ const (
 	FlagManualExact  uint8 = FlagManualGrow | FlagGrowExact  // Only grow buffer to exact length when Grow() is called explicitly, panic if a write would exceed capacity
 	FlagDefault      uint8 = FlagAutoDouble                  // Automatically grow buffer by double+n when a write would exceed capacity
 	FlagStatic       uint8 = FlagManualExact                 // Only grow buffer to exact length when Grow() is called explicitly, panic if a write would exceed capacity
+	FlagTaggedFields uint8 = 4                               // UseField() writes a field ID and length before each value, allowing fields to be read in any order
+	FlagNoGrow       uint8 = 8                               // Never grow buffer, even if flagged for AutoGrow: a write that would exceed capacity panics with ErrNoGrow, which TryWrite() returns as an error
+	FlagSortedMaps   uint8 = 16                              // UseMap() and UseAny() write map entries sorted by the bytes of their encoded keys, so equal maps always produce identical bytes
+	FlagTrace        uint8 = 32                              // Record the offset, length and calling method of every read and write in a log returned by Trace(), for debugging
+	FlagNoPanic      uint8 = 64                              // A read or write that would panic in CheckRead() or CheckWrite() instead fails the crate, making later reads and writes no-ops, and its error is returned by Err()
 )
 
 // Determines how the Use____() functions handle the variables passed to them
@@ -44,10 +50,33 @@ type SelfSerializer interface {
 // A Crate is a data buffer with a separate read and write index
 // and options for how it should grow when needed.
 type Crate struct {
-	data  []byte
-	write uint64
-	read  uint64
-	flags uint8
+	data     []byte
+	write    uint64
+	read     uint64
+	flags    uint8
+	key      string
+	keyOK    bool
+	group    fieldGroup
+	resolver BlobResolver
+	dedup    map[string]uint64
+	graph    graphState
+	depth    uint64
+	maxDepth uint64
+	maxAlloc uint64
+	visitor  Visitor
+	writeTx  []txState
+	readTx   []txState
+	sections []uint64
+	grows    uint64
+	strings  *StringTable
+	trace    []TraceEntry
+	limits   []uint64
+	sorter   *mapSorter
+	alloc    Allocator
+	watch    *watchState
+	validate bool
+	failed   *failure
+	gen      uint64
 }
 
 // Just in case you want to pack Crates inside other Crates...
@@ -82,11 +111,23 @@ func OpenCrate(data []byte, flags uint8) *Crate {
 // Grows buffer if crate was flagged with 'FlagAutoGrow' (default).
 // Panics if not flagged for AutoGrow and 'size' would exceed capacity
 func (c *Crate) CheckWrite(size uint64) {
+	if c.failed != nil {
+		c.useSink(size, true)
+		return
+	}
+	if c.flags&FlagTrace == FlagTrace {
+		c.recordTrace(true, c.write, size)
+	}
 	sum := c.write + size
 	l64 := len64(c.data)
 	if sum > l64 {
 		if !c.WillAutoGrow() {
-			panic("LiteCrate: AutoGrow set to false and cannot write " + intStr(size) + " more bytes (written bytes: " + intStr(c.write) + ", max bytes: " + intStr(l64) + ", space left: " + intStr(l64-c.write) + ")")
+			if c.flags&FlagNoGrow == FlagNoGrow {
+				c.failCheck(ErrNoGrow, size, true)
+				return
+			}
+			c.failCheck("LiteCrate: AutoGrow set to false and cannot write " + intStr(size) + " more bytes (written bytes: " + intStr(c.write) + ", max bytes: " + intStr(l64) + ", space left: " + intStr(l64-c.write) + ")", size, true)
+			return
 		}
 		diff := sum - l64
 		c.Grow(int(diff))
@@ -94,19 +135,48 @@ func (c *Crate) CheckWrite(size uint64) {
 	_ = c.data[sum-1]
 }
 
+// Matches (with errors.Is()) the error CheckRead() panics with when a read needs more bytes than are left
+// in the crate, which StreamDecoder recognizes as 'wait for more data'
+var ErrReadPastEnd = errors.New("LiteCrate: read past end of crate")
+
+// Panicked by reads that need more bytes than are left, describing what could not be read
+type readPastEndError string
+
+func (e readPastEndError) Error() string {
+	return "LiteCrate: cannot read " + string(e)
+}
+
+func (e readPastEndError) Unwrap() error {
+	return ErrReadPastEnd
+}
+
 // Check whether a read of 'size' bytes will succeed.
 // Panics if 'size' would cause the read index to exceed the write index
 func (c *Crate) CheckRead(size uint64) {
+	if c.failed != nil {
+		c.useSink(size, false)
+		return
+	}
+	if c.flags&FlagTrace == FlagTrace {
+		c.recordTrace(false, c.read, size)
+	}
 	sum := c.read + size
-	if sum > c.write {
-		panic("LiteCrate: cannot read " + intStr(size) + " more bytes (read index: " + intStr(c.read) + ", write index: " + intStr(c.write) + ", unread bytes left in crate: " + intStr(c.write-c.read) + ")")
+	if end := c.readEnd(); sum > end {
+		c.failCheck(readPastEndError(intStr(size) + " more bytes (read index: " + intStr(c.read) + ", write index: " + intStr(c.write) + ", unread bytes left in crate: " + intStr(end-c.read) + ")"), size, false)
+		return
 	}
 	_ = c.data[sum-1]
 }
 
 // Returns whether AutoGrow is set on Crate (default)
 func (c *Crate) WillAutoGrow() bool {
-	return c.flags&FlagManualGrow == 0
+	return c.flags&(FlagManualGrow|FlagNoGrow) == 0
+}
+
+// Returns how many times the crate's buffer has been reallocated to grow it,
+// so latency-sensitive users can check that warmed up crates stop allocating
+func (c *Crate) GrowCount() uint64 {
+	return c.grows
 }
 
 // Returns whether GrowMode is Double (default))
@@ -114,6 +184,21 @@ func (c *Crate) WillDoubleOnAllocate() bool {
 	return c.flags&FlagGrowExact == 0
 }
 
+// Returns whether FlagTaggedFields is set on Crate
+func (c *Crate) WillTagFields() bool {
+	return c.flags&FlagTaggedFields == FlagTaggedFields
+}
+
+// Returns whether FlagSortedMaps is set on Crate
+func (c *Crate) WillSortMaps() bool {
+	return c.flags&FlagSortedMaps == FlagSortedMaps
+}
+
+// Returns whether FlagTrace is set on Crate
+func (c *Crate) WillTrace() bool {
+	return c.flags&FlagTrace == FlagTrace
+}
+
 // Returns the length of the crate's written byte slice
 func (c *Crate) Len() int {
 	return int(c.write)
@@ -141,6 +226,9 @@ func (c *Crate) Grow(n int) {
 		l64 := len64(c.data)
 		if c.write > l64 {
 			c.write = l64
+			c.keyOK = false
+			c.dedup = nil
+			c.gen += 1
 		}
 		if c.read > c.write {
 			c.read = c.write
@@ -155,21 +243,41 @@ func (c *Crate) Grow(n int) {
 		default:
 			alloc = make([]byte, len(c.data)+n)
 		}
+		grown := uint64(len(alloc) - cap(c.data))
 		copy(alloc, c.data)
 		c.data = alloc
+		c.grows += 1
+		c.gen += 1
+		c.checkGrowth(grown)
 	}
 }
 
 // Returns a slice of the crate's written data
+// (if the crate has failed under FlagNoPanic, the data written before it failed)
 func (c *Crate) Data() []byte {
+	if c.failed != nil {
+		return c.failed.data[:c.failed.write]
+	}
 	b := c.data[:c.write]
 	return b
 }
 
+// Replaces the crate's contents with data, reusing its buffer if data fits
+func (c *Crate) replaceData(data []byte) {
+	c.Reset()
+	if len64(data) > len64(c.data) {
+		c.data = data
+		c.write = len64(data)
+		return
+	}
+	c.WriteBytes(data)
+}
+
 // Returns a COPY of the crate's written data
 func (c *Crate) DataCopy() []byte {
-	bytes := make([]byte, c.write)
-	copy(bytes, c.data[:c.write])
+	data := c.Data()
+	bytes := make([]byte, len(data))
+	copy(bytes, data)
 	return bytes
 }
 
@@ -210,11 +318,36 @@ func (c *Crate) Clone() *Crate {
 	return crate
 }
 
+// Returns the crate's written data as an immutable string, suitable for use as a map key.
+// The string is copied only once and cached until the written data changes through the crate's methods.
+// Modifying the slice returned by Data() WILL NOT invalidate the cached key
+func (c *Crate) Key() string {
+	data := c.Data()
+	if !c.keyOK || len(c.key) != len(data) {
+		c.key = string(data)
+		c.keyOK = true
+	}
+	return c.key
+}
+
 // Reverts crate to a "like-new" state without re-allocating underlying array.
 // Useful if recycling large pre-allocated crates
 func (c *Crate) Reset() {
+	if c.failed != nil {
+		c.data = c.failed.data
+		c.failed = nil
+	}
 	c.write = 0
 	c.read = 0
+	c.keyOK = false
+	c.dedup = nil
+	c.gen += 1
+	c.graph = graphState{}
+	c.depth = 0
+	c.writeTx = c.writeTx[:0]
+	c.readTx = c.readTx[:0]
+	c.sections = c.sections[:0]
+	c.limits = c.limits[:0]
 }
 
 // Reverts crate to a "like-new" state without re-allocating underlying array,
@@ -246,6 +379,9 @@ func (c *Crate) WriteIndex() uint64 {
 // if not it will panic
 func (c *Crate) SetWriteIndex(index uint64) {
 	c.write = 0
+	c.keyOK = false
+	c.dedup = nil
+	c.gen += 1
 	c.CheckWrite(index)
 	c.write = index
 }
@@ -271,7 +407,7 @@ func (c *Crate) SpaceLeft() uint64 {
 
 // Returns the number of bytes left for the Crate to read from
 func (c *Crate) ReadsLeft() uint64 {
-	return c.write - c.read
+	return c.readEnd() - c.read
 }
 
 // Set option flags for Crate
@@ -279,12 +415,17 @@ func (c *Crate) SetFlags(flags uint8) {
 	c.flags = flags
 }
 
-// Advance read index n bytes without using them
+// Advance read index n bytes without using them, stopping at the write index
+// (or panicking, inside ValidateSelfSerializer())
 func (c *Crate) DiscardN(n uint64) {
-	c.read += n
-	if c.read > c.write {
-		c.read = c.write
+	if end := c.readEnd(); n > end-c.read {
+		if c.validate {
+			c.CheckRead(n)
+		}
+		c.read = end
+		return
 	}
+	c.read += n
 }
 
 /**************
@@ -366,7 +507,7 @@ func (c *Crate) UseBool(val *bool, mode UseMode) (sliceModeData []byte) {
 	case Slice:
 		sliceModeData = c.SliceBool()
 	default:
-		panic("LiteCrate: Invalid mode passed to UseBool()")
+		c.useCustomMode(val, mode, "UseBool")
 	}
 	return sliceModeData
 }
@@ -424,7 +565,7 @@ func (c *Crate) UseU8(val *uint8, mode UseMode) (sliceModeData []byte) {
 	case Slice:
 		sliceModeData = c.SliceU8()
 	default:
-		panic("LiteCrate: Invalid mode passed to UseU8()/UseByte()")
+		c.useCustomMode(val, mode, "UseU8")
 	}
 	return sliceModeData
 }
@@ -516,7 +657,7 @@ func (c *Crate) UseI8(val *int8, mode UseMode) (sliceModeData []byte) {
 	case Slice:
 		sliceModeData = c.SliceI8()
 	default:
-		panic("LiteCrate: Invalid mode passed to UseI8()")
+		c.useCustomMode(val, mode, "UseI8")
 	}
 	return sliceModeData
 }
@@ -577,7 +718,7 @@ func (c *Crate) UseU16(val *uint16, mode UseMode) (sliceModeData []byte) {
 	case Slice:
 		sliceModeData = c.SliceU16()
 	default:
-		panic("LiteCrate: Invalid mode passed to UseU16()")
+		c.useCustomMode(val, mode, "UseU16")
 	}
 	return sliceModeData
 }
@@ -632,7 +773,7 @@ func (c *Crate) UseI16(val *int16, mode UseMode) (sliceModeData []byte) {
 	case Slice:
 		sliceModeData = c.SliceI16()
 	default:
-		panic("LiteCrate: Invalid mode passed to UseI16()")
+		c.useCustomMode(val, mode, "UseI16")
 	}
 	return sliceModeData
 }
@@ -698,7 +839,7 @@ func (c *Crate) UseU24(val *uint32, mode UseMode) (sliceModeData []byte) {
 	case Slice:
 		sliceModeData = c.SliceU24()
 	default:
-		panic("LiteCrate: Invalid mode passed to UseU24()")
+		c.useCustomMode(val, mode, "UseU24")
 	}
 	return sliceModeData
 }
@@ -759,7 +900,7 @@ func (c *Crate) UseI24(val *int32, mode UseMode) (sliceModeData []byte) {
 	case Slice:
 		sliceModeData = c.SliceI24()
 	default:
-		panic("LiteCrate: Invalid mode passed to UseI24()")
+		c.useCustomMode(val, mode, "UseI24")
 	}
 	return sliceModeData
 }
@@ -824,7 +965,7 @@ func (c *Crate) UseU32(val *uint32, mode UseMode) (sliceModeData []byte) {
 	case Slice:
 		sliceModeData = c.SliceU32()
 	default:
-		panic("LiteCrate: Invalid mode passed to UseU32()")
+		c.useCustomMode(val, mode, "UseU32")
 	}
 	return sliceModeData
 }
@@ -879,7 +1020,7 @@ func (c *Crate) UseI32(val *int32, mode UseMode) (sliceModeData []byte) {
 	case Slice:
 		sliceModeData = c.SliceI32()
 	default:
-		panic("LiteCrate: Invalid mode passed to UseI32()/UseRune()")
+		c.useCustomMode(val, mode, "UseI32")
 	}
 	return sliceModeData
 }
@@ -983,7 +1124,7 @@ func (c *Crate) UseU40(val *uint64, mode UseMode) (sliceModeData []byte) {
 	case Slice:
 		sliceModeData = c.SliceU40()
 	default:
-		panic("LiteCrate: Invalid mode passed to UseU40()")
+		c.useCustomMode(val, mode, "UseU40")
 	}
 	return sliceModeData
 }
@@ -1044,7 +1185,7 @@ func (c *Crate) UseI40(val *int64, mode UseMode) (sliceModeData []byte) {
 	case Slice:
 		sliceModeData = c.SliceI40()
 	default:
-		panic("LiteCrate: Invalid mode passed to UseI40()")
+		c.useCustomMode(val, mode, "UseI40")
 	}
 	return sliceModeData
 }
@@ -1116,7 +1257,7 @@ func (c *Crate) UseU48(val *uint64, mode UseMode) (sliceModeData []byte) {
 	case Slice:
 		sliceModeData = c.SliceU48()
 	default:
-		panic("LiteCrate: Invalid mode passed to UseU48()")
+		c.useCustomMode(val, mode, "UseU48")
 	}
 	return sliceModeData
 }
@@ -1177,7 +1318,7 @@ func (c *Crate) UseI48(val *int64, mode UseMode) (sliceModeData []byte) {
 	case Slice:
 		sliceModeData = c.SliceI48()
 	default:
-		panic("LiteCrate: Invalid mode passed to UseI48()")
+		c.useCustomMode(val, mode, "UseI48")
 	}
 	return sliceModeData
 }
@@ -1251,7 +1392,7 @@ func (c *Crate) UseU56(val *uint64, mode UseMode) (sliceModeData []byte) {
 	case Slice:
 		sliceModeData = c.SliceU56()
 	default:
-		panic("LiteCrate: Invalid mode passed to UseU56()")
+		c.useCustomMode(val, mode, "UseU56")
 	}
 	return sliceModeData
 }
@@ -1312,7 +1453,7 @@ func (c *Crate) UseI56(val *int64, mode UseMode) (sliceModeData []byte) {
 	case Slice:
 		sliceModeData = c.SliceI56()
 	default:
-		panic("LiteCrate: Invalid mode passed to UseI56()")
+		c.useCustomMode(val, mode, "UseI56")
 	}
 	return sliceModeData
 }
@@ -1385,7 +1526,7 @@ func (c *Crate) UseU64(val *uint64, mode UseMode) (sliceModeData []byte) {
 	case Slice:
 		sliceModeData = c.SliceU64()
 	default:
-		panic("LiteCrate: Invalid mode passed to UseU64()")
+		c.useCustomMode(val, mode, "UseU64")
 	}
 	return sliceModeData
 }
@@ -1441,7 +1582,7 @@ func (c *Crate) UseI64(val *int64, mode UseMode) (sliceModeData []byte) {
 	case Slice:
 		sliceModeData = c.SliceI64()
 	default:
-		panic("LiteCrate: Invalid mode passed to UseI64()")
+		c.useCustomMode(val, mode, "UseI64")
 	}
 	return sliceModeData
 }
@@ -1495,7 +1636,7 @@ func (c *Crate) UseInt(val *int, mode UseMode) (sliceModeData []byte) {
 	case Slice:
 		sliceModeData = c.SliceInt()
 	default:
-		panic("LiteCrate: Invalid mode passed to UseInt()")
+		c.useCustomMode(val, mode, "UseInt")
 	}
 	return sliceModeData
 }
@@ -1549,7 +1690,7 @@ func (c *Crate) UseUint(val *uint, mode UseMode) (sliceModeData []byte) {
 	case Slice:
 		sliceModeData = c.SliceUint()
 	default:
-		panic("LiteCrate: Invalid mode passed to UseUint()")
+		c.useCustomMode(val, mode, "UseUint")
 	}
 	return sliceModeData
 }
@@ -1603,7 +1744,7 @@ func (c *Crate) UseUintPtr(val *uintptr, mode UseMode) (sliceModeData []byte) {
 	case Slice:
 		sliceModeData = c.SliceUintPtr()
 	default:
-		panic("LiteCrate: Invalid mode passed to UseUintPtr()")
+		c.useCustomMode(val, mode, "UseUintPtr")
 	}
 	return sliceModeData
 }
@@ -1659,7 +1800,7 @@ func (c *Crate) UseF32(val *float32, mode UseMode) (sliceModeData []byte) {
 	case Slice:
 		sliceModeData = c.SliceF32()
 	default:
-		panic("LiteCrate: Invalid mode passed to UseF32()")
+		c.useCustomMode(val, mode, "UseF32")
 	}
 	return sliceModeData
 }
@@ -1715,7 +1856,7 @@ func (c *Crate) UseF64(val *float64, mode UseMode) (sliceModeData []byte) {
 	case Slice:
 		sliceModeData = c.SliceF64()
 	default:
-		panic("LiteCrate: Invalid mode passed to UseF64()")
+		c.useCustomMode(val, mode, "UseF64")
 	}
 	return sliceModeData
 }
@@ -1774,7 +1915,7 @@ func (c *Crate) UseC64(val *complex64, mode UseMode) (sliceModeData []byte) {
 	case Slice:
 		sliceModeData = c.SliceC64()
 	default:
-		panic("LiteCrate: Invalid mode passed to UseC64()")
+		c.useCustomMode(val, mode, "UseC64")
 	}
 	return sliceModeData
 }
@@ -1833,7 +1974,7 @@ func (c *Crate) UseC128(val *complex128, mode UseMode) (sliceModeData []byte) {
 	case Slice:
 		sliceModeData = c.SliceC128()
 	default:
-		panic("LiteCrate: Invalid mode passed to UseC128()")
+		c.useCustomMode(val, mode, "UseC128")
 	}
 	return sliceModeData
 }
@@ -1872,10 +2013,10 @@ func (c *Crate) SliceUVarint() (slice []byte) {
 func (c *Crate) WriteUVarint(val uint64) (bytesWritten uint64) {
 	longer := false
 	longerBit := uint8(0)
-	for val > 0 || bytesWritten == 0 {
+	c.CheckWrite(findUVarintBytesFromValue(val))
+	for (val > 0 || bytesWritten == 0) && bytesWritten < 9 {
 		longer = val > countMask && bytesWritten < 8
 		longerBit = *(*uint8)(unsafe.Pointer(&longer)) << countShift
-		c.CheckWrite(1)
 		c.data[c.write] = byte(val)&countMasks[bytesWritten] | longerBit
 		c.write += 1
 		bytesWritten += 1
@@ -1886,9 +2027,15 @@ func (c *Crate) WriteUVarint(val uint64) (bytesWritten uint64) {
 
 // Read next 1-9 bytes from crate as msb uvarint encoded uint64
 func (c *Crate) ReadUVarint() (val uint64, bytesRead uint64) {
+	// Check the whole varint at once, so it is one read in a trace
+	n := uint64(1)
+	end := c.readEnd()
+	for n < 9 && c.read+n <= end && c.data[c.read+n-1]&continueMask == continueMask {
+		n += 1
+	}
+	c.CheckRead(n)
 	longer := true
 	for ; longer && bytesRead < 9; bytesRead += 1 {
-		c.CheckRead(1)
 		longer = c.data[c.read]&continueMask == continueMask
 		val |= uint64(c.data[c.read]&countMasks[bytesRead]) << (bytesRead * countShift)
 		c.read += 1
@@ -1922,7 +2069,7 @@ func (c *Crate) UseUVarint(val *uint64, mode UseMode) (bytesUsed uint64, sliceMo
 	case Slice:
 		sliceModeData = c.SliceUVarint()
 	default:
-		panic("LiteCrate: Invalid mode passed to UseUVarint()")
+		c.useCustomMode(val, mode, "UseUVarint")
 	}
 	return bytesUsed, sliceModeData
 }
@@ -1986,7 +2133,7 @@ func (c *Crate) UseVarint(val *int64, mode UseMode) (bytesUsed uint64, sliceMode
 	case Slice:
 		sliceModeData = c.SliceVarint()
 	default:
-		panic("LiteCrate: Invalid mode passed to UseVarint()")
+		c.useCustomMode(val, mode, "UseVarint")
 	}
 	return bytesUsed, sliceModeData
 }
@@ -2061,11 +2208,71 @@ func (c *Crate) UseLengthOrNil(length *uint64, writeNil bool, mode UseMode) (rea
 	case Slice:
 		sliceModeData = c.SliceLengthOrNil()
 	default:
-		panic("LiteCrate: Invalid mode passed to UseLengthOrNil()")
+		c.useCustomMode(length, mode, "UseLengthOrNil")
 	}
 	return readNil, bytesUsed, sliceModeData
 }
 
+/**************
+	LENGTH
+***************/
+
+// Discard next 1-9 unread bytes in crate,
+// dependant on length (same wire format as length-or-nil)
+func (c *Crate) DiscardLength() (bytesDiscarded uint64) {
+	return c.DiscardLengthOrNil()
+}
+
+// Return byte slice the next unread length occupies
+// (same wire format as length-or-nil)
+func (c *Crate) SliceLength() (slice []byte) {
+	return c.SliceLengthOrNil()
+}
+
+// Write length to crate as a length-or-nil that is never nil.
+// Uses 1-9 bytes dependant on length
+//
+// The maximum length that can be written is 18446744073709551614 (WILL NOT check value for correctness)
+func (c *Crate) WriteLength(length uint64) (bytesWritten uint64) {
+	return c.WriteLengthOrNil(length, false)
+}
+
+// Read next 1-9 bytes from crate as length-or-nil, where nil is read as length 0
+func (c *Crate) ReadLength() (length uint64, bytesRead uint64) {
+	length, _, bytesRead = c.ReadLengthOrNil()
+	return length, bytesRead
+}
+
+// Read next 1-9 bytes from crate as length-or-nil, where nil is read as length 0,
+// without advancing read index
+func (c *Crate) PeekLength() (length uint64, bytesRead uint64) {
+	length, _, bytesRead = c.PeekLengthOrNil()
+	return length, bytesRead
+}
+
+// Use the length pointed to by length as a length-or-nil that is never nil, according to mode:
+// Write = 'write length into crate', Read = 'read from crate into length (nil reads as 0)',
+// Peek = 'read from crate into length without advancing index'
+// Discard = 'skip the next unread length without altering length'
+// Slice = 'Return the slice the next unread length occupies without altering length'
+func (c *Crate) UseLength(length *uint64, mode UseMode) (bytesUsed uint64, sliceModeData []byte) {
+	switch mode {
+	case Write:
+		bytesUsed = c.WriteLength(*length)
+	case Read:
+		*length, bytesUsed = c.ReadLength()
+	case Peek:
+		*length, bytesUsed = c.PeekLength()
+	case Discard:
+		bytesUsed = c.DiscardLength()
+	case Slice:
+		sliceModeData = c.SliceLength()
+	default:
+		c.useCustomMode(length, mode, "UseLength")
+	}
+	return bytesUsed, sliceModeData
+}
+
 /**************
 	STRING
 ***************/
@@ -2078,6 +2285,9 @@ func (c *Crate) DiscardString(length uint64) {
 // Return byte slice the next unread string of specified length occupies
 func (c *Crate) SliceString(length uint64) (slice []byte) {
 	c.CheckRead(length)
+	if c.failed != nil {
+		return nil
+	}
 	return c.data[c.read : c.read+length : c.read+length]
 }
 
@@ -2097,6 +2307,9 @@ func (c *Crate) SliceStringWithCounter() (slice []byte) {
 func (c *Crate) WriteString(val string) {
 	length := len64str(val)
 	c.CheckWrite(length)
+	if c.failed != nil {
+		return
+	}
 	bytes := make([]byte, length)
 	(*sliceInternals)(unsafe.Pointer(&bytes)).data = (*stringInternals)(unsafe.Pointer(&val)).data
 	copy(c.data[c.write:c.write+length], bytes)
@@ -2115,7 +2328,11 @@ func (c *Crate) ReadString(length uint64) (val string) {
 	if length == 0 {
 		return val
 	}
+	c.checkAlloc(length, 1)
 	c.CheckRead(length)
+	if c.failed != nil {
+		return val
+	}
 	bytes := make([]byte, length)
 	copy(bytes, c.data[c.read:c.read+length])
 	targetPtr := (*stringInternals)(unsafe.Pointer(&val))
@@ -2148,6 +2365,52 @@ func (c *Crate) PeekStringWithCounter() (val string) {
 	return val
 }
 
+// Read next string of specified byte length from crate WITHOUT COPYING it.
+// The returned string shares memory with the crate's buffer: it is only valid
+// until the crate is written over, Reset() or grown, and must be copied
+// if it needs to outlive that. Useful for high-throughput parsers that only need transient access
+func (c *Crate) ReadStringZeroCopy(length uint64) (val string) {
+	if length == 0 {
+		return val
+	}
+	c.CheckRead(length)
+	if c.failed != nil {
+		return val
+	}
+	bytes := c.data[c.read : c.read+length]
+	targetPtr := (*stringInternals)(unsafe.Pointer(&val))
+	targetPtr.data = (*sliceInternals)(unsafe.Pointer(&bytes)).data
+	targetPtr.length = len(bytes)
+	c.read += length
+	return val
+}
+
+// Read next string with preceding length-or-nil counter from crate WITHOUT COPYING it
+// (see ReadStringZeroCopy() for when the returned string stops being valid)
+func (c *Crate) ReadStringWithCounterZeroCopy() (val string) {
+	length, _, _ := c.ReadLengthOrNil()
+	val = c.ReadStringZeroCopy(length)
+	return val
+}
+
+// Read next string of specified byte length from crate WITHOUT COPYING it and without advancing read index
+// (see ReadStringZeroCopy() for when the returned string stops being valid)
+func (c *Crate) PeekStringZeroCopy(length uint64) (val string) {
+	idx := c.read
+	val = c.ReadStringZeroCopy(length)
+	c.read = idx
+	return val
+}
+
+// Read next string with preceding length-or-nil counter from crate WITHOUT COPYING it and without advancing read index
+// (see ReadStringZeroCopy() for when the returned string stops being valid)
+func (c *Crate) PeekStringWithCounterZeroCopy() (val string) {
+	idx := c.read
+	val = c.ReadStringWithCounterZeroCopy()
+	c.read = idx
+	return val
+}
+
 // Use the string pointed to by val according to mode (with specified read length):
 // Write = 'write val into crate', Read = 'read from crate into val',
 // Peek = 'read from crate into val without advancing index'
@@ -2165,7 +2428,7 @@ func (c *Crate) UseString(val *string, readLength uint64, mode UseMode) (sliceMo
 	case Slice:
 		sliceModeData = c.SliceString(readLength)
 	default:
-		panic("LiteCrate: Invalid mode passed to UseString()")
+		c.useCustomMode(val, mode, "UseString")
 	}
 	return sliceModeData
 }
@@ -2187,7 +2450,7 @@ func (c *Crate) UseStringWithCounter(val *string, mode UseMode) (sliceModeData [
 	case Slice:
 		sliceModeData = c.SliceStringWithCounter()
 	default:
-		panic("LiteCrate: Invalid mode passed to UseStringWithCounter()")
+		c.useCustomMode(val, mode, "UseStringWithCounter")
 	}
 	return sliceModeData
 }
@@ -2204,6 +2467,9 @@ func (c *Crate) DiscardBytes(length uint64) {
 // Return the next unread byte slice of specified length
 func (c *Crate) SliceBytes(length uint64) (slice []byte) {
 	c.CheckRead(length)
+	if c.failed != nil {
+		return nil
+	}
 	return c.data[c.read : c.read+length : c.read+length]
 }
 
@@ -2226,6 +2492,9 @@ func (c *Crate) WriteBytes(val []byte) {
 		return
 	}
 	c.CheckWrite(length)
+	if c.failed != nil {
+		return
+	}
 	copy(c.data[c.write:c.write+length], val)
 	c.write += length
 }
@@ -2240,8 +2509,12 @@ func (c *Crate) WriteBytesWithCounter(val []byte) {
 
 // Read next bytes slice of specified length from crate
 func (c *Crate) ReadBytes(length uint64) (val []byte) {
+	c.checkAlloc(length, 1)
 	c.CheckRead(length)
-	val = make([]byte, length)
+	if c.failed != nil {
+		return nil
+	}
+	val = makeSlice[byte](c, length)
 	copy(val, c.data[c.read:c.read+length])
 	c.read += length
 	return val
@@ -2290,7 +2563,7 @@ func (c *Crate) UseBytes(val *[]byte, readLength uint64, mode UseMode) (sliceMod
 	case Slice:
 		sliceModeData = c.SliceBytes(readLength)
 	default:
-		panic("LiteCrate: Invalid mode passed to UseBytes()")
+		c.useCustomMode(val, mode, "UseBytes")
 	}
 	return sliceModeData
 }
@@ -2312,7 +2585,7 @@ func (c *Crate) UseBytesWithCounter(val *[]byte, mode UseMode) (sliceModeData []
 	case Slice:
 		sliceModeData = c.SliceBytesWithCounter()
 	default:
-		panic("LiteCrate: Invalid mode passed to UseBytesWithCounter()")
+		c.useCustomMode(val, mode, "UseBytesWithCounter")
 	}
 	return sliceModeData
 }
@@ -2323,30 +2596,37 @@ func (c *Crate) UseBytesWithCounter(val *[]byte, mode UseMode) (sliceModeData []
 
 // Write SelfSerializer to crate
 func (c *Crate) WriteSelfSerializer(val SelfSerializer) {
+	c.enterDepth()
+	defer c.leaveDepth()
 	val.UseSelf(c, Write)
 }
 
 // Read next SelfSerializer from crate
 func (c *Crate) ReadSelfSerializer(val SelfSerializer) {
+	c.enterDepth()
+	defer c.leaveDepth()
 	val.UseSelf(c, Read)
 }
 
 // Read next SelfSerializer from crate without advancing read index
 func (c *Crate) PeekSelfSerializer(val SelfSerializer) {
 	indexBefore := c.read
-	val.UseSelf(c, Read)
+	c.ReadSelfSerializer(val)
 	c.read = indexBefore
 }
 
 // Discard next SelfSerializer in crate
 func (c *Crate) DiscardSelfSerializer(val SelfSerializer) {
+	c.enterDepth()
+	defer c.leaveDepth()
 	val.UseSelf(c, Discard)
 }
 
-// Return byte slice the next unread SelfSerializer occupies
+// Return byte slice the next unread SelfSerializer occupies, finding its end in Discard mode
+// (so val is not altered, and sections written with UseSection() are skipped without decoding)
 func (c *Crate) SliceSelfAcecessor(val SelfSerializer) (slice []byte) {
 	indexBefore := c.read
-	val.UseSelf(c, Read)
+	c.DiscardSelfSerializer(val)
 	length := c.read - indexBefore
 	c.read = indexBefore
 	return c.data[indexBefore : indexBefore+length : indexBefore+length]
@@ -2369,7 +2649,10 @@ func (c *Crate) UseSelfSerializer(val SelfSerializer, mode UseMode) (sliceModeDa
 	case Slice:
 		sliceModeData = c.SliceSelfAcecessor(val)
 	default:
-		panic("LiteCrate: Invalid mode passed to UseSelfSerializer()")
+		c.useCustomMode(val, mode, "UseSelfSerializer")
+		c.enterDepth()
+		defer c.leaveDepth()
+		val.UseSelf(c, mode)
 	}
 	return sliceModeData
 }
@@ -2386,44 +2669,111 @@ type UseFunc[T any] func(val *T, mode UseMode) (sliceModeData []byte)
 // custom function for more complex cases, or one of the predefined Use____() functions,
 // assuming its signature matches the slice element type.
 //
+// When reading, the existing slice's backing array is reused if it has enough capacity,
+// so decoding into the same slice repeatedly does not allocate
+//
+// Slices of fixed width numbers are much faster to use with the bulk Use____Slice() methods
+// (UseF64Slice(), UseI32Slice()...), which encode them identically
+//
 // Example:
 //	var myFloat64Slice = []float64{...}
 //	var myCrate = NewCrate(1000, FlagAutoDouble)
 //
 //	UseSlice(myCrate, Write, &myFloat64Slice, myCrate.UseF64)
 func UseSlice[T any](crate *Crate, mode UseMode, slice *[]T, useElementFunc UseFunc[T]) (sliceModeData []byte) {
+	return useSlice(crate, mode, slice, useElementFunc, false)
+}
+
+// Same as UseSlice(), except that Read and Peek append the elements read to the end of *slice
+// (reusing its spare capacity) instead of replacing its contents. A nil slice that was written
+// appends nothing
+//
+// Example:
+//	var batch []float64
+//	for myCrate.ReadsLeft() > 0 {
+//		UseSliceAppend(myCrate, Read, &batch, myCrate.UseF64)
+//	}
+func UseSliceAppend[T any](crate *Crate, mode UseMode, slice *[]T, useElementFunc UseFunc[T]) (sliceModeData []byte) {
+	return useSlice(crate, mode, slice, useElementFunc, true)
+}
+
+func useSlice[T any](crate *Crate, mode UseMode, slice *[]T, useElementFunc UseFunc[T], appendRead bool) (sliceModeData []byte) {
+	if mode >= firstCustomMode {
+		crate.useCustomMode(slice, mode, "UseSlice")
+		at := elementPath{active: true}
+		defer at.catch()
+		for i := range *slice {
+			at.index = uint64(i)
+			useElementFunc(&(*slice)[i], mode)
+		}
+		return nil
+	}
 	length := len64(*slice)
 	writeNil := *slice == nil
-	readNil, _, _ := crate.UseLengthOrNil(&length, writeNil, mode)
+	idx := crate.read
+	var readNil bool
+	if mode == Write {
+		crate.WriteLengthOrNil(length, writeNil)
+	} else {
+		length, readNil, _ = crate.ReadLengthOrNil()
+	}
+	crate.enterDepth()
+	defer crate.leaveDepth()
+	var at elementPath
+	defer at.catch()
 	switch mode {
 	case Read, Peek:
+		if mode == Peek {
+			defer func() { crate.read = idx }()
+		}
 		if readNil {
-			*slice = nil
+			if !appendRead {
+				*slice = nil
+			}
 			return nil
 		}
-		if *slice == nil {
-			*slice = make([]T, length)
+		base := uint64(0)
+		if appendRead {
+			base = len64(*slice)
 		}
-		for i := uint64(0); i < length; i += 1 {
-			var elem T
-			useElementFunc(&elem, mode)
-			(*slice)[i] = elem
+		if *slice == nil || cap64(*slice) < base+length {
+			var zero T
+			crate.checkAlloc(base+length, uint64(unsafe.Sizeof(zero)))
+			grown := makeSlice[T](crate, base+length)
+			copy(grown, *slice)
+			*slice = grown
 		}
+		*slice = (*slice)[:base+length]
+		var zero T
+		at.active = true
+		for i := base; i < base+length; i += 1 {
+			at.index = i
+			(*slice)[i] = zero
+			useElementFunc(&(*slice)[i], Read)
+		}
+		at.active = false
 	case Write:
 		if writeNil {
 			return nil
 		}
+		at.active = true
 		for i := uint64(0); i < length; i += 1 {
+			at.index = i
 			useElementFunc(&(*slice)[i], mode)
 		}
+		at.active = false
 	case Slice, Discard:
 		start := crate.read
+		at.active = true
 		for i := uint64(0); i < length; i += 1 {
-			useElementFunc(nil, Discard)
+			at.index = i
+			var elem T
+			useElementFunc(&elem, Discard)
 		}
+		at.active = false
 		end := crate.read
 		if mode == Slice {
-			crate.read = start
+			crate.read = idx
 			return crate.data[start:end:end]
 		}
 	default:
@@ -2432,6 +2782,86 @@ func UseSlice[T any](crate *Crate, mode UseMode, slice *[]T, useElementFunc UseF
 	return nil
 }
 
+// Helper func for selectively reading/writing a slice of any type that is terminated by the
+// sentinel byte instead of preceded by a counter, as used by some existing binary formats.
+// The elements are used with useElementFunc() in a loop, and the sentinel is written after the last one.
+// When reading, the loop stops at the first element that would begin with the sentinel byte,
+// so no element may be encoded starting with it (writing one panics), and nil and empty slices
+// are written the same way. The existing slice's backing array is reused when reading.
+//
+// Example:
+//	var myIDs = []uint8{...} // ids are never 0
+//	var myCrate = NewCrate(1000, FlagAutoDouble)
+//
+//	UseUntilSentinel(myCrate, Write, 0, &myIDs, myCrate.UseU8)
+func UseUntilSentinel[T any](crate *Crate, mode UseMode, sentinel byte, slice *[]T, useElementFunc UseFunc[T]) (sliceModeData []byte) {
+	if mode >= firstCustomMode {
+		crate.useCustomMode(slice, mode, "UseUntilSentinel")
+		at := elementPath{active: true}
+		defer at.catch()
+		for i := range *slice {
+			at.index = uint64(i)
+			useElementFunc(&(*slice)[i], mode)
+		}
+		return nil
+	}
+	crate.enterDepth()
+	defer crate.leaveDepth()
+	idx := crate.read
+	var at elementPath
+	defer at.catch()
+	switch mode {
+	case Write:
+		for i := range *slice {
+			start := crate.write
+			at.index, at.active = uint64(i), true
+			useElementFunc(&(*slice)[i], Write)
+			at.active = false
+			if crate.write > start && crate.data[start] == sentinel {
+				panic("LiteCrate: element " + intStr(i) + " begins with sentinel byte " + intStr(sentinel))
+			}
+		}
+		crate.WriteU8(sentinel)
+	case Read, Peek:
+		*slice = (*slice)[:0]
+		var zero T
+		for crate.PeekU8() != sentinel {
+			*slice = append(*slice, zero)
+			start := crate.read
+			at.index, at.active = uint64(len(*slice)-1), true
+			useElementFunc(&(*slice)[len(*slice)-1], Read)
+			at.active = false
+			if crate.read == start {
+				panic("LiteCrate: element " + intStr(len(*slice)-1) + " before sentinel byte used no bytes")
+			}
+		}
+		crate.DiscardU8()
+		if mode == Peek {
+			crate.read = idx
+		}
+	case Discard, Slice:
+		for i := uint64(0); crate.PeekU8() != sentinel; i += 1 {
+			var elem T
+			start := crate.read
+			at.index, at.active = i, true
+			useElementFunc(&elem, Discard)
+			at.active = false
+			if crate.read == start {
+				panic("LiteCrate: element before sentinel byte used no bytes")
+			}
+		}
+		crate.DiscardU8()
+		if mode == Slice {
+			end := crate.read
+			crate.read = idx
+			return crate.data[idx:end:end]
+		}
+	default:
+		panic("LiteCrate: invalid mode passed to UseUntilSentinel()")
+	}
+	return nil
+}
+
 // Helper func for selectively reading/writing a map of any type, dependant on mode.
 // Automatically reads/writes a length-or-nil counter, then uses useKeyFunc() and useValFunc() in a loop
 // to write each key-value pair adjacent to each other (key first, value second). useKeyFunc() and useValFunc() can be
@@ -2444,42 +2874,94 @@ func UseSlice[T any](crate *Crate, mode UseMode, slice *[]T, useElementFunc UseF
 //
 //	UseMap(myCrate, Write, &myStringIntMap, myCrate.UseStringWithCounter, myCrate.UseInt)
 func UseMap[K comparable, V any](crate *Crate, mode UseMode, Map *map[K]V, useKeyFunc UseFunc[K], useValFunc UseFunc[V]) (sliceModeData []byte) {
+	if mode >= firstCustomMode {
+		crate.useCustomMode(Map, mode, "UseMap")
+		var keyCopy K
+		at := elementPath{key: &keyCopy, keyOK: true, active: true}
+		defer at.catch()
+		for key, val := range *Map {
+			keyCopy = key
+			useKeyFunc(&keyCopy, mode)
+			useValFunc(&val, mode)
+			(*Map)[key] = val
+			at.index += 1
+		}
+		return nil
+	}
 	mapLen := len64map(*Map)
 	writeNil := *Map == nil
-	readNil, _, _ := crate.UseLengthOrNil(&mapLen, writeNil, mode)
+	idx := crate.read
+	// The counter is used directly rather than through UseLengthOrNil(), which would move mapLen to the heap
+	var readNil bool
+	if mode == Write {
+		crate.WriteLengthOrNil(mapLen, writeNil)
+	} else {
+		mapLen, readNil, _ = crate.ReadLengthOrNil()
+	}
+	crate.enterDepth()
+	defer crate.leaveDepth()
+	var at elementPath
+	defer at.catch()
 	switch mode {
 	case Read, Peek:
+		if mode == Peek {
+			defer func() { crate.read = idx }()
+		}
 		if readNil {
 			*Map = nil
 			return nil
 		}
+		// key and val are declared once and reset each entry, as their addresses escape
+		// through useKeyFunc and useValFunc and would otherwise be allocated per entry
+		var key, zeroKey K
+		var val, zeroVal V
 		if *Map == nil {
-			*Map = make(map[K]V, mapLen)
+			crate.checkAlloc(mapLen, uint64(unsafe.Sizeof(key)+unsafe.Sizeof(val)))
+			*Map = makeMap[K, V](crate, mapLen)
 		}
+		at.key, at.active = &key, true
 		for i := uint64(0); i < mapLen; i += 1 {
-			var key K
-			var val V
-			useKeyFunc(&key, mode)
-			useValFunc(&val, mode)
+			key, val = zeroKey, zeroVal
+			at.index, at.keyOK = i, false
+			useKeyFunc(&key, Read)
+			at.keyOK = true
+			useValFunc(&val, Read)
 			(*Map)[key] = val
 		}
+		at.active = false
 	case Write:
 		if writeNil {
 			return nil
 		}
-		for key, val := range *Map {
+		if crate.WillSortMaps() {
+			writeSortedMap(crate, *Map, useKeyFunc, useValFunc, &at)
+			return nil
+		}
+		var key K
+		var val V
+		at.key, at.keyOK, at.active = &key, true, true
+		for k, v := range *Map {
+			key, val = k, v
 			useKeyFunc(&key, mode)
 			useValFunc(&val, mode)
+			at.index += 1
 		}
+		at.active = false
 	case Slice, Discard:
 		start := crate.read
+		var key K
+		var val V
+		at.key, at.active = &key, true
 		for i := uint64(0); i < mapLen; i += 1 {
-			useKeyFunc(nil, Discard)
-			useValFunc(nil, Discard)
+			at.index, at.keyOK = i, false
+			useKeyFunc(&key, Discard)
+			at.keyOK = true
+			useValFunc(&val, Discard)
 		}
+		at.active = false
 		end := crate.read
 		if mode == Slice {
-			crate.read = start
+			crate.read = idx
 			return crate.data[start:end:end]
 		}
 	default:
@@ -2488,6 +2970,57 @@ func UseMap[K comparable, V any](crate *Crate, mode UseMode, Map *map[K]V, useKe
 	return nil
 }
 
+// Use the value *val points to according to mode, preceded by a 1 byte flag
+// recording whether *val is nil (the same layout UseAny() uses for pointers),
+// so optional fields like *uint64 or *string round-trip nil.
+// When reading, *val is set to nil or to a newly allocated value.
+//
+// Write = 'write *val into crate', Read = 'read from crate into *val',
+// Peek = 'read from crate into *val without advancing index'
+// Slice = 'Return the slice the next unread value occupies (including flag) without altering val'
+//
+// Example:
+//
+//	UseOptional(myCrate, Write, &myStringPointer, myCrate.UseStringWithCounter)
+func UseOptional[T any](crate *Crate, mode UseMode, val **T, useValueFunc UseFunc[T]) (sliceModeData []byte) {
+	switch mode {
+	case Write:
+		crate.WriteBool(*val != nil)
+		if *val != nil {
+			useValueFunc(*val, Write)
+		}
+	case Read, Peek:
+		idx := crate.read
+		if crate.ReadBool() {
+			value := new(T)
+			useValueFunc(value, Read)
+			*val = value
+		} else {
+			*val = nil
+		}
+		if mode == Peek {
+			crate.read = idx
+		}
+	case Discard, Slice:
+		idx := crate.read
+		if crate.ReadBool() {
+			var value T
+			useValueFunc(&value, Discard)
+		}
+		if mode == Slice {
+			end := crate.read
+			crate.read = idx
+			return crate.data[idx:end:end]
+		}
+	default:
+		crate.useCustomMode(val, mode, "UseOptional")
+		if *val != nil {
+			useValueFunc(*val, mode)
+		}
+	}
+	return nil
+}
+
 /**************
 	INTERNAL
 ***************/
@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"encoding/gob"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"reflect"
 	"testing"
 	"unsafe"
@@ -15,6 +17,25 @@ import (
 var smallCrate = lite.NewCrate(64, lite.FlagManualExact)
 var largeCrate = lite.NewCrate(100, lite.FlagAutoDouble)
 
+// Runs fn and returns what it panicked with as an E, found with errors.As() as panics inside slice and map
+// elements arrive wrapped in a *PathError (panics that are not errors are formatted into one).
+// Returns the zero E if fn did not panic with an E, so catchPanic[error](fn) != nil reports whether it panicked at all
+func catchPanic[E error](fn func()) (err E) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		caught, ok := r.(error)
+		if !ok {
+			caught = fmt.Errorf("%v", r)
+		}
+		errors.As(caught, &err)
+	}()
+	fn()
+	return err
+}
+
 type person struct {
 	Age      uint8
 	Name     string
@@ -1333,6 +1354,52 @@ func FuzzLengthOrNil(f *testing.F) {
 	})
 }
 
+func FuzzLength(f *testing.F) {
+	f.Add(uint64(10), uint64(1000))
+	smallCrate.FullClear()
+	f.Fuzz(func(t *testing.T, a uint64, b uint64) {
+		smallCrate.Reset()
+		a = (a % 18446744073709551615)
+		b = (b % 18446744073709551615)
+		var c, d, cBytes, dBytes uint64
+		bytesA, bytesB := findUVarintBytesFromValue(a+1), findUVarintBytesFromValue(b+1)
+		bytesTotal := bytesA + bytesB + 1
+		smallCrate.UseLength(&a, lite.Write)
+		smallCrate.UseLength(&b, lite.Write)
+		smallCrate.WriteLengthOrNil(0, true)
+		smallCrate.UseLength(&c, lite.Peek)
+		if c != a {
+			t.Errorf("PeekLength - FAIL: %d != %d", c, a)
+		}
+		if smallCrate.ReadIndex() != 0 {
+			t.Error("PeekLength - FAIL: index was increased")
+		}
+		smallCrate.UseLength(nil, lite.Discard)
+		if smallCrate.ReadIndex() != bytesA {
+			t.Error("DiscardLength - FAIL: index != ", bytesA)
+		}
+		if smallCrate.WriteIndex() != bytesTotal {
+			t.Error("WriteLength - FAIL: index != ", bytesTotal)
+		}
+		_, slice := smallCrate.UseLength(&b, lite.Slice)
+		if uint64(len(slice)) != bytesB || uint64(cap(slice)) != bytesB {
+			t.Error("SliceLength - FAIL: len != ", bytesB, " and/or cap != ", bytesB)
+		}
+		recvCrate := lite.OpenCrate(smallCrate.Data(), lite.FlagManualExact)
+		c, cBytes = recvCrate.ReadLength()
+		d, dBytes = recvCrate.ReadLength()
+		if a != c || b != d {
+			t.Errorf("Read/Write Length - FAIL (value): %d != %d and/or %d != %d", a, c, b, d)
+		}
+		if bytesA != cBytes || bytesB != dBytes {
+			t.Errorf("Read/Write Length - FAIL (bytes): %d != %d and/or %d != %d", bytesA, cBytes, bytesB, dBytes)
+		}
+		if e, _ := recvCrate.ReadLength(); e != 0 {
+			t.Errorf("ReadLength - FAIL: nil read as %d != 0", e)
+		}
+	})
+}
+
 func FuzzString(f *testing.F) {
 	f.Add("HelloWorld", "FooBar")
 	largeCrate.FullClear()
@@ -1361,6 +1428,35 @@ func FuzzString(f *testing.F) {
 	})
 }
 
+func FuzzStringZeroCopy(f *testing.F) {
+	f.Add("HelloWorld", "FooBar")
+	largeCrate.FullClear()
+	f.Fuzz(func(t *testing.T, a string, b string) {
+		largeCrate.Reset()
+		largeCrate.WriteStringWithCounter(a)
+		largeCrate.WriteString(b)
+		c := largeCrate.PeekStringWithCounterZeroCopy()
+		if c != a || largeCrate.ReadIndex() != 0 {
+			t.Errorf("PeekStringWithCounterZeroCopy - FAIL: %s != %s or index was increased", c, a)
+		}
+		c = largeCrate.ReadStringWithCounterZeroCopy()
+		d := largeCrate.PeekStringZeroCopy(uint64(len(b)))
+		if c != a || d != b || largeCrate.ReadsLeft() != uint64(len(b)) {
+			t.Errorf("Read/Peek StringZeroCopy - FAIL: \n%s != \n%s \nand/or \n%s != \n%s", a, c, b, d)
+		}
+		d = largeCrate.ReadStringZeroCopy(uint64(len(b)))
+		if d != b || largeCrate.ReadsLeft() != 0 {
+			t.Errorf("ReadStringZeroCopy - FAIL: %s != %s or %d bytes left", d, b, largeCrate.ReadsLeft())
+		}
+		if len(b) > 0 {
+			largeCrate.Data()[largeCrate.WriteIndex()-1] ^= 0xFF
+			if d[len(d)-1] == b[len(b)-1] {
+				t.Error("ReadStringZeroCopy - FAIL: string does not share memory with crate")
+			}
+		}
+	})
+}
+
 func FuzzBytes(f *testing.F) {
 	f.Add([]byte{1, 2, 3, 4, 5}, []byte{6, 7, 8, 9, 10, 11, 12, 13})
 	largeCrate.FullClear()
@@ -1467,3 +1563,341 @@ func FuzzSelfSerializer(f *testing.F) {
 		}
 	})
 }
+
+func TestKey(t *testing.T) {
+	crate := lite.NewCrate(8, lite.FlagAutoDouble)
+	crate.WriteU16(1000)
+	keyA := crate.Key()
+	if keyA != string(crate.Data()) {
+		t.Errorf("Key - FAIL: %v != %v", []byte(keyA), crate.Data())
+	}
+	if keyB := crate.Key(); keyB != keyA {
+		t.Errorf("Key - FAIL: cached key changed %v != %v", []byte(keyB), []byte(keyA))
+	}
+	crate.WriteU8(7)
+	if keyC := crate.Key(); keyC != string(crate.Data()) {
+		t.Errorf("Key - FAIL: key not refreshed after write %v != %v", []byte(keyC), crate.Data())
+	}
+	crate.SetWriteIndex(1)
+	crate.WriteU16(2000)
+	if keyD := crate.Key(); keyD != string(crate.Data()) {
+		t.Errorf("Key - FAIL: key not refreshed after SetWriteIndex %v != %v", []byte(keyD), crate.Data())
+	}
+	crate.Reset()
+	if keyE := crate.Key(); keyE != "" {
+		t.Errorf("Key - FAIL: key not empty after Reset %v", []byte(keyE))
+	}
+}
+
+type diffRecord struct {
+	U8      uint8
+	I8      int8
+	U16     uint16
+	I16     int16
+	U24     uint32
+	I24     int32
+	I40     int64
+	U48     uint64
+	I56     int64
+	U64     uint64
+	Varint  int64
+	UVarint uint64
+	F64     float64
+	Name    string
+	Blob    []byte
+	Values  []int64
+	Lookup  map[string]int32
+}
+
+func (r *diffRecord) UseSelf(crate *lite.Crate, mode lite.UseMode) {
+	crate.UseU8(&r.U8, mode)
+	crate.UseI8(&r.I8, mode)
+	crate.UseU16(&r.U16, mode)
+	crate.UseI16(&r.I16, mode)
+	crate.UseU24(&r.U24, mode)
+	crate.UseI24(&r.I24, mode)
+	crate.UseI40(&r.I40, mode)
+	crate.UseU48(&r.U48, mode)
+	crate.UseI56(&r.I56, mode)
+	crate.UseU64(&r.U64, mode)
+	crate.UseVarint(&r.Varint, mode)
+	crate.UseUVarint(&r.UVarint, mode)
+	crate.UseF64(&r.F64, mode)
+	crate.UseStringWithCounter(&r.Name, mode)
+	crate.UseBytesWithCounter(&r.Blob, mode)
+	lite.UseSlice(crate, mode, &r.Values, func(val *int64, mode lite.UseMode) []byte {
+		_, slice := crate.UseVarint(val, mode)
+		return slice
+	})
+	lite.UseMap(crate, mode, &r.Lookup, crate.UseStringWithCounter, crate.UseI24)
+}
+
+// gob does not distinguish nil from empty slices/maps and NaN != NaN, so both are flattened
+// before comparison. Every other F64 is also compared by its bits, as reflect.DeepEqual() treats -0 as 0
+func (r diffRecord) normalized() diffRecord {
+	if len(r.Blob) == 0 {
+		r.Blob = nil
+	}
+	if len(r.Values) == 0 {
+		r.Values = nil
+	}
+	if len(r.Lookup) == 0 {
+		r.Lookup = nil
+	}
+	if math.IsNaN(r.F64) {
+		r.F64 = 0
+	}
+	return r
+}
+
+func FuzzDifferentialGob(f *testing.F) {
+	f.Add(uint8(1), int8(-1), uint16(2), int16(-2), uint32(16777215), int32(-8388608), int64(-549755813888), uint64(281474976710655),
+		int64(-36028797018963968), uint64(18446744073709551615), int64(-9223372036854775808), uint64(300), float64(-1.5), "Derek", []byte{1, 2, 3}, uint8(3), int32(-5))
+	f.Add(uint8(0), int8(0), uint16(0), int16(0), uint32(0), int32(0), int64(0), uint64(0),
+		int64(0), uint64(0), int64(0), uint64(0), math.Copysign(0, -1), "", []byte{}, uint8(0), int32(0))
+	f.Fuzz(func(t *testing.T, u8 uint8, i8 int8, u16 uint16, i16 int16, u24 uint32, i24 int32, i40 int64, u48 uint64,
+		i56 int64, u64 uint64, varint int64, uvarint uint64, f64 float64, name string, blob []byte, count uint8, lookup int32) {
+		count = count % 16
+		recordA := diffRecord{
+			U8: u8, I8: i8, U16: u16, I16: i16,
+			U24:     u24 & 16777215,
+			I24:     (i24 << 8) >> 8,
+			I40:     (i40 << 24) >> 24,
+			U48:     u48 & 281474976710655,
+			I56:     (i56 << 8) >> 8,
+			U64:     u64,
+			Varint:  varint,
+			UVarint: uvarint,
+			F64:     f64,
+			Name:    name,
+			Blob:    blob,
+			Values:  make([]int64, count),
+			Lookup:  make(map[string]int32, count),
+		}
+		for i := uint8(0); i < count; i += 1 {
+			recordA.Values[i] = varint - int64(i)*i40
+			recordA.Lookup[name+fmt.Sprint(i)] = ((lookup - int32(i)) << 8) >> 8
+		}
+		crate := lite.NewCrate(10, lite.FlagAutoDouble)
+		crate.WriteSelfSerializer(&recordA)
+		recordB := diffRecord{}
+		lite.OpenCrate(crate.Data(), lite.FlagManualExact).ReadSelfSerializer(&recordB)
+		buf := bytes.Buffer{}
+		if err := gob.NewEncoder(&buf).Encode(recordA); err != nil {
+			t.Fatalf("Gob Encode - FAIL: %s", err)
+		}
+		recordC := diffRecord{}
+		if err := gob.NewDecoder(&buf).Decode(&recordC); err != nil {
+			t.Fatalf("Gob Decode - FAIL: %s", err)
+		}
+		if math.Float64bits(recordA.F64) != math.Float64bits(recordB.F64) {
+			t.Errorf("Differential F64 - FAIL: %x != %x", math.Float64bits(recordA.F64), math.Float64bits(recordB.F64))
+		}
+		// gob omits fields equal to their zero value, so -0 arrives as 0
+		gobZero := recordB.F64 == 0 && recordC.F64 == 0
+		if !math.IsNaN(recordC.F64) && !gobZero && math.Float64bits(recordB.F64) != math.Float64bits(recordC.F64) {
+			t.Errorf("Differential Gob F64 - FAIL: %x != %x", math.Float64bits(recordB.F64), math.Float64bits(recordC.F64))
+		}
+		normA, normB, normC := recordA.normalized(), recordB.normalized(), recordC.normalized()
+		if !reflect.DeepEqual(normA, normB) {
+			t.Errorf("Differential LiteCrate - FAIL: \n%#v != \n%#v", normA, normB)
+		}
+		if !reflect.DeepEqual(normB, normC) {
+			t.Errorf("Differential Gob - FAIL: \n%#v != \n%#v", normB, normC)
+		}
+	})
+}
+
+func TestSliceMapDiscard(t *testing.T) {
+	crate := lite.NewCrate(64, lite.FlagAutoDouble)
+	values := []int64{1, -2, 3}
+	lookup := map[string]complex128{"Mom": complex(1, 2), "Dad": complex(3, 4)}
+	children := []person{{Name: "Chris"}, {Name: "Baby"}}
+	lite.UseSlice(crate, lite.Write, &values, crate.UseI64)
+	lite.UseMap(crate, lite.Write, &lookup, crate.UseStringWithCounter, crate.UseC128)
+	lite.UseSlice(crate, lite.Write, &children, func(child *person, mode lite.UseMode) []byte {
+		return crate.UseSelfSerializer(child, mode)
+	})
+	var emptyValues []int64
+	var emptyLookup map[string]complex128
+	var emptyChildren []person
+	slice := lite.UseSlice(crate, lite.Slice, &emptyValues, crate.UseI64)
+	if len(slice) != 24 || crate.ReadIndex() != 0 {
+		t.Errorf("UseSlice(Slice) - FAIL: len %d != 24 or index %d != 0", len(slice), crate.ReadIndex())
+	}
+	lite.UseSlice(crate, lite.Discard, &emptyValues, crate.UseI64)
+	slice = lite.UseMap(crate, lite.Slice, &emptyLookup, crate.UseStringWithCounter, crate.UseC128)
+	if len(slice) != 40 {
+		t.Errorf("UseMap(Slice) - FAIL: len %d != 40", len(slice))
+	}
+	lite.UseMap(crate, lite.Discard, &emptyLookup, crate.UseStringWithCounter, crate.UseC128)
+	lite.UseSlice(crate, lite.Discard, &emptyChildren, func(child *person, mode lite.UseMode) []byte {
+		return crate.UseSelfSerializer(child, mode)
+	})
+	if crate.ReadsLeft() != 0 {
+		t.Errorf("UseSlice/UseMap(Discard) - FAIL: %d bytes left unread", crate.ReadsLeft())
+	}
+}
+
+func TestSliceReuse(t *testing.T) {
+	crate := lite.NewCrate(64, lite.FlagAutoDouble)
+	first, second, empty := []uint16{1, 2, 3}, []uint16{4, 5}, []uint16{}
+	var none []uint16
+	for _, values := range [][]uint16{first, second, none, empty} {
+		lite.UseSlice(crate, lite.Write, &values, crate.UseU16)
+	}
+
+	buffer := make([]uint16, 1, 8)
+	lite.UseSlice(crate, lite.Peek, &buffer, crate.UseU16)
+	if len(buffer) != 3 || buffer[2] != 3 || crate.ReadIndex() != 0 {
+		t.Errorf("UseSlice(Peek) - FAIL: peeked %v, index %d", buffer, crate.ReadIndex())
+	}
+	backing := &buffer[:1][0]
+	lite.UseSlice(crate, lite.Read, &buffer, crate.UseU16)
+	lite.UseSlice(crate, lite.Read, &buffer, crate.UseU16)
+	if len(buffer) != 2 || buffer[0] != 4 || buffer[1] != 5 || &buffer[0] != backing {
+		t.Errorf("UseSlice(Read) - FAIL: read %v without reusing backing array", buffer)
+	}
+	useU16 := crate.UseU16
+	allocs := testing.AllocsPerRun(100, func() {
+		crate.ResetReadIndex()
+		lite.UseSlice(crate, lite.Read, &buffer, useU16)
+	})
+	if allocs != 0 {
+		t.Errorf("UseSlice(Read) - FAIL: %v allocations reading into existing slice", allocs)
+	}
+
+	crate.ResetReadIndex()
+	var appended []uint16
+	for i := 0; i < 4; i += 1 {
+		lite.UseSliceAppend(crate, lite.Read, &appended, crate.UseU16)
+	}
+	if len(appended) != 5 || appended[2] != 3 || appended[4] != 5 || crate.ReadsLeft() != 0 {
+		t.Errorf("UseSliceAppend(Read) - FAIL: appended %v", appended)
+	}
+	crate.ResetReadIndex()
+	lite.UseSliceAppend(crate, lite.Peek, &appended, crate.UseU16)
+	if len(appended) != 8 || appended[7] != 3 || crate.ReadIndex() != 0 {
+		t.Errorf("UseSliceAppend(Peek) - FAIL: appended %v", appended)
+	}
+	lite.UseSlice(crate, lite.Discard, &buffer, crate.UseU16)
+	lite.UseSlice(crate, lite.Discard, &buffer, crate.UseU16)
+	lite.UseSlice(crate, lite.Read, &buffer, crate.UseU16)
+	lite.UseSlice(crate, lite.Read, &none, crate.UseU16)
+	if buffer != nil || none == nil || len(none) != 0 {
+		t.Errorf("UseSlice(Read) - FAIL: nil read as %v, empty read as %v", buffer, none)
+	}
+}
+
+func TestMapPeek(t *testing.T) {
+	crate := lite.NewCrate(64, lite.FlagAutoDouble|lite.FlagSortedMaps)
+	scores := map[string]uint16{"a": 1, "bb": 2, "ccc": 3}
+	var none map[string]uint16
+	lite.UseMap(crate, lite.Write, &scores, crate.UseStringWithCounter, crate.UseU16)
+	lite.UseMap(crate, lite.Write, &none, crate.UseStringWithCounter, crate.UseU16)
+	crate.WriteU8(42)
+
+	var peeked map[string]uint16
+	lite.UseMap(crate, lite.Peek, &peeked, crate.UseStringWithCounter, crate.UseU16)
+	if len(peeked) != 3 || peeked["bb"] != 2 || peeked["ccc"] != 3 || crate.ReadIndex() != 0 {
+		t.Errorf("UseMap(Peek) - FAIL: peeked %v, index %d", peeked, crate.ReadIndex())
+	}
+	var read map[string]uint16
+	lite.UseMap(crate, lite.Read, &read, crate.UseStringWithCounter, crate.UseU16)
+	index := crate.ReadIndex()
+	lite.UseMap(crate, lite.Peek, &peeked, crate.UseStringWithCounter, crate.UseU16)
+	if len(read) != 3 || peeked != nil || crate.ReadIndex() != index {
+		t.Errorf("UseMap(Peek) - FAIL: nil map peeked as %v, index %d != %d", peeked, crate.ReadIndex(), index)
+	}
+	lite.UseMap(crate, lite.Read, &read, crate.UseStringWithCounter, crate.UseU16)
+	if crate.ReadU8() != 42 {
+		t.Error("UseMap(Peek) - FAIL: maps did not read back after peeking")
+	}
+}
+
+func TestUseUntilSentinel(t *testing.T) {
+	crate := lite.NewCrate(16, lite.FlagAutoDouble)
+	names := []string{"alpha", "b", "gamma"}
+	var none []string
+	lite.UseUntilSentinel(crate, lite.Write, 0xFF, &names, crate.UseStringWithCounter)
+	lite.UseUntilSentinel(crate, lite.Write, 0xFF, &none, crate.UseStringWithCounter)
+	crate.WriteU8(42)
+	if crate.WriteIndex() != 6+2+6+1+1+1 || crate.Data()[14] != 0xFF {
+		t.Errorf("UseUntilSentinel(Write) - FAIL: wrote %v", crate.Data())
+	}
+	read := make([]string, 5, 10)
+	backing := &read[0]
+	lite.UseUntilSentinel(crate, lite.Peek, 0xFF, &read, crate.UseStringWithCounter)
+	if len(read) != 3 || read[2] != "gamma" || &read[0] != backing || crate.ReadIndex() != 0 {
+		t.Errorf("UseUntilSentinel(Peek) - FAIL: peeked %v, index %d", read, crate.ReadIndex())
+	}
+	if slice := lite.UseUntilSentinel(crate, lite.Slice, 0xFF, &read, crate.UseStringWithCounter); len(slice) != 15 || crate.ReadIndex() != 0 {
+		t.Errorf("UseUntilSentinel(Slice) - FAIL: len %d != 15", len(slice))
+	}
+	lite.UseUntilSentinel(crate, lite.Read, 0xFF, &read, crate.UseStringWithCounter)
+	lite.UseUntilSentinel(crate, lite.Read, 0xFF, &none, crate.UseStringWithCounter)
+	if len(read) != 3 || read[0] != "alpha" || len(none) != 0 || crate.ReadU8() != 42 {
+		t.Errorf("UseUntilSentinel(Read) - FAIL: read %v and %v", read, none)
+	}
+	crate.ResetReadIndex()
+	lite.UseUntilSentinel(crate, lite.Discard, 0xFF, &read, crate.UseStringWithCounter)
+	lite.UseUntilSentinel(crate, lite.Discard, 0xFF, &read, crate.UseStringWithCounter)
+	if crate.ReadU8() != 42 {
+		t.Errorf("UseUntilSentinel(Discard) - FAIL: did not skip sequences")
+	}
+
+	crate.Reset()
+	ids := []uint8{3, 0, 4}
+	if catchPanic[error](func() { lite.UseUntilSentinel(crate, lite.Write, 0, &ids, crate.UseU8) }) == nil {
+		t.Errorf("UseUntilSentinel(Write) - FAIL: element starting with sentinel did not panic")
+	}
+	crate.Reset()
+	crate.WriteU8(1)
+	var empty []lite.Empty
+	if catchPanic[error](func() {
+		lite.UseUntilSentinel(crate, lite.Read, 0, &empty, func(val *lite.Empty, mode lite.UseMode) []byte { return nil })
+	}) == nil {
+		t.Errorf("UseUntilSentinel(Read) - FAIL: element using no bytes did not panic")
+	}
+	crate.Reset()
+	crate.WriteU8(1)
+	if catchPanic[error](func() { lite.UseUntilSentinel(crate, lite.Read, 0, &ids, crate.UseU8) }) == nil {
+		t.Errorf("UseUntilSentinel(Read) - FAIL: missing sentinel did not panic")
+	}
+}
+
+func TestUseOptional(t *testing.T) {
+	crate := lite.NewCrate(16, lite.FlagAutoDouble)
+	count := uint16(300)
+	name := "Derek"
+	var missing *string
+	countPtr, namePtr := &count, &name
+	lite.UseOptional(crate, lite.Write, &countPtr, crate.UseU16)
+	lite.UseOptional(crate, lite.Write, &missing, crate.UseStringWithCounter)
+	lite.UseOptional(crate, lite.Write, &namePtr, crate.UseStringWithCounter)
+	if crate.WriteIndex() != 11 {
+		t.Errorf("UseOptional(Write) - FAIL: wrote %d bytes != 11", crate.WriteIndex())
+	}
+	var gotCount *uint16
+	gotName := &name
+	lite.UseOptional(crate, lite.Peek, &gotCount, crate.UseU16)
+	if crate.ReadIndex() != 0 || gotCount == nil || *gotCount != 300 {
+		t.Errorf("UseOptional(Peek) - FAIL: index %d, value %v", crate.ReadIndex(), gotCount)
+	}
+	slice := lite.UseOptional(crate, lite.Slice, &gotCount, crate.UseU16)
+	lite.UseOptional(crate, lite.Read, &gotCount, crate.UseU16)
+	if len(slice) != 3 || gotCount == countPtr || *gotCount != 300 {
+		t.Errorf("UseOptional(Read/Slice) - FAIL: slice %v, value %v", slice, gotCount)
+	}
+	lite.UseOptional(crate, lite.Read, &gotName, crate.UseStringWithCounter)
+	if gotName != nil {
+		t.Errorf("UseOptional(Read) - FAIL: nil pointer read as %q", *gotName)
+	}
+	lite.UseOptional(crate, lite.Discard, &gotName, crate.UseStringWithCounter)
+	if crate.ReadsLeft() != 0 || gotName != nil {
+		t.Errorf("UseOptional(Discard) - FAIL: %d bytes left", crate.ReadsLeft())
+	}
+	if catchPanic[error](func() { lite.UseOptional(crate, lite.UseMode(255), &gotName, crate.UseStringWithCounter) }) == nil {
+		t.Errorf("UseOptional - FAIL: invalid mode did not panic")
+	}
+}
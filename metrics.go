@@ -0,0 +1,97 @@
+package litecrate
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Bucket upper bounds used by a LatencyHistogram with no Buckets set
+var DefaultLatencyBuckets = []time.Duration{
+	time.Microsecond, 5 * time.Microsecond, 10 * time.Microsecond, 50 * time.Microsecond,
+	100 * time.Microsecond, 500 * time.Microsecond, time.Millisecond, 5 * time.Millisecond,
+	10 * time.Millisecond, 50 * time.Millisecond, 100 * time.Millisecond, time.Second,
+}
+
+/**************
+	METRICS
+***************/
+
+// A LatencyHistogram counts how long operations take in buckets, and can be set on a Framer
+// to time its frames, or with SetSealLatency() to time encryption. The zero value is ready to use, and all methods are safe to call concurrently
+type LatencyHistogram struct {
+	Buckets []time.Duration // Ascending bucket upper bounds, nil = DefaultLatencyBuckets. Must not be changed after the first Observe()
+	mutex   sync.Mutex
+	counts  []uint64
+	sum     time.Duration
+	max     time.Duration
+}
+
+// A copy of a LatencyHistogram's counters
+type LatencySnapshot struct {
+	Buckets []time.Duration // Bucket upper bounds
+	Counts  []uint64        // Counts[i] = operations that took <= Buckets[i] (and longer than Buckets[i-1]), the last count is for operations longer than every bucket
+	Count   uint64          // Total operations observed
+	Sum     time.Duration   // Total time of all operations
+	Max     time.Duration   // Longest operation
+}
+
+// Count an operation that took d
+func (h *LatencyHistogram) Observe(d time.Duration) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if h.Buckets == nil {
+		h.Buckets = DefaultLatencyBuckets
+	}
+	if h.counts == nil {
+		h.counts = make([]uint64, len(h.Buckets)+1)
+	}
+	h.counts[sort.Search(len(h.Buckets), func(i int) bool { return d <= h.Buckets[i] })] += 1
+	h.sum += d
+	if d > h.max {
+		h.max = d
+	}
+}
+
+// Count an operation that began at start and has just ended.
+// Does nothing if h is nil, so optional histograms can be timed with
+//
+//	defer h.ObserveSince(time.Now())
+func (h *LatencyHistogram) ObserveSince(start time.Time) {
+	if h != nil {
+		h.Observe(time.Since(start))
+	}
+}
+
+// Returns a copy of the histogram's counters
+func (h *LatencyHistogram) Snapshot() LatencySnapshot {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	snap := LatencySnapshot{Buckets: h.Buckets, Sum: h.sum, Max: h.max}
+	if snap.Buckets == nil {
+		snap.Buckets = DefaultLatencyBuckets
+	}
+	snap.Counts = make([]uint64, len(snap.Buckets)+1)
+	for i, n := range h.counts {
+		snap.Counts[i] = n
+		snap.Count += n
+	}
+	return snap
+}
+
+// Returns the upper bound of the bucket holding the q quantile (0.9 for the 90th percentile),
+// or Max if it is beyond every bucket
+func (s LatencySnapshot) Quantile(q float64) time.Duration {
+	want := uint64(q*float64(s.Count) + 0.5)
+	if want == 0 {
+		want = 1
+	}
+	seen := uint64(0)
+	for i, n := range s.Counts {
+		seen += n
+		if seen >= want && i < len(s.Buckets) {
+			return s.Buckets[i]
+		}
+	}
+	return s.Max
+}
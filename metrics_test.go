@@ -0,0 +1,55 @@
+package litecrate_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+func TestLatencyHistogram(t *testing.T) {
+	hist := lite.LatencyHistogram{Buckets: []time.Duration{time.Millisecond, 10 * time.Millisecond}}
+	if snap := hist.Snapshot(); snap.Count != 0 || len(snap.Counts) != 3 || snap.Quantile(0.5) != 0 {
+		t.Errorf("LatencyHistogram.Snapshot() - FAIL: empty histogram %+v", snap)
+	}
+	for i := 0; i < 80; i += 1 {
+		hist.Observe(500 * time.Microsecond)
+	}
+	for i := 0; i < 15; i += 1 {
+		hist.Observe(time.Millisecond + 1)
+	}
+	for i := 0; i < 5; i += 1 {
+		hist.Observe(time.Second)
+	}
+	snap := hist.Snapshot()
+	if snap.Count != 100 || snap.Counts[0] != 80 || snap.Counts[1] != 15 || snap.Counts[2] != 5 || snap.Max != time.Second {
+		t.Errorf("LatencyHistogram.Observe() - FAIL: %+v", snap)
+	}
+	if snap.Quantile(0.5) != time.Millisecond || snap.Quantile(0.9) != 10*time.Millisecond || snap.Quantile(0.99) != time.Second {
+		t.Errorf("LatencySnapshot.Quantile() - FAIL: p50 %v, p90 %v, p99 %v", snap.Quantile(0.5), snap.Quantile(0.9), snap.Quantile(0.99))
+	}
+	var missing *lite.LatencyHistogram
+	missing.ObserveSince(time.Now())
+}
+
+func TestFramerLatency(t *testing.T) {
+	framer := lite.Framer{WriteLatency: &lite.LatencyHistogram{}, ReadLatency: &lite.LatencyHistogram{}}
+	conn := bytes.Buffer{}
+	crate := lite.NewCrate(16, lite.FlagAutoDouble)
+	crate.WriteStringWithCounter("timed")
+	for i := 0; i < 3; i += 1 {
+		if err := framer.WriteFrame(&conn, crate); err != nil {
+			t.Fatalf("WriteFrame() - FAIL: %v", err)
+		}
+	}
+	for i := 0; i < 3; i += 1 {
+		if _, err := framer.ReadFrame(&conn); err != nil {
+			t.Fatalf("ReadFrame() - FAIL: %v", err)
+		}
+	}
+	framer.ReadFrame(&conn)
+	if writes, reads := framer.WriteLatency.Snapshot(), framer.ReadLatency.Snapshot(); writes.Count != 3 || reads.Count != 3 {
+		t.Errorf("Framer latency - FAIL: %d writes, %d reads timed", writes.Count, reads.Count)
+	}
+}
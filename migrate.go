@@ -0,0 +1,139 @@
+//go:build !litecrate_core && !litecrate_notools
+
+package litecrate
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+)
+
+// Prefix Migrator writes before every native record when Marker is nil.
+// No JSON document can start with a zero byte and no gob stream starts with an empty message
+var DefaultMigrationMarker = []byte{0x00, 'L', 'C'}
+
+// Returned by Migrator.Read() when a record is neither a native crate nor matched by any registered format
+var ErrUnknownFormat = errors.New("LiteCrate: record is not a crate or any registered format")
+
+// A Converter decodes one record stored in an older external format into val
+type Converter func(record []byte, val SelfSerializer) error
+
+/**************
+	MIGRATOR
+***************/
+
+// Reads records that are either native crates or one of several older formats (JSON, gob...),
+// so an existing datastore can be moved onto crates one record at a time instead of all at once.
+// Native records are the val's crate encoding prefixed with Marker; older formats are
+// recognised by the detect function given to Register() and decoded by their Converter.
+//
+// ReadRepair() writes every old record it decodes back in native form, so records that are
+// read often are migrated first and the old formats can be dropped once Read() stops seeing them
+type Migrator struct {
+	Marker  []byte // Prefix identifying native records, nil = DefaultMigrationMarker
+	Flags   uint8  // Flags for the crates records are read from and written to
+	formats []migrationFormat
+}
+
+type migrationFormat struct {
+	name    string
+	detect  func(record []byte) bool
+	convert Converter
+}
+
+// Register an older format. Formats are checked in the order they were registered,
+// and the first whose detect function returns true decodes the record.
+// A nil detect matches every record, so it only makes sense for the last format registered
+func (m *Migrator) Register(name string, detect func(record []byte) bool, convert Converter) {
+	m.formats = append(m.formats, migrationFormat{name: name, detect: detect, convert: convert})
+}
+
+// Encode val as a native record
+func (m *Migrator) Write(val SelfSerializer) []byte {
+	marker := m.marker()
+	crate := NewCrate(len64(marker)+64, m.Flags|FlagAutoDouble)
+	crate.WriteBytes(marker)
+	crate.WriteSelfSerializer(val)
+	return crate.Data()
+}
+
+// Decode record into val, returning the name of the format it was stored in ("" for native records).
+// Panics while reading a native record are returned as errors
+func (m *Migrator) Read(record []byte, val SelfSerializer) (format string, err error) {
+	marker := m.marker()
+	if bytes.HasPrefix(record, marker) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = jsonError(r)
+			}
+		}()
+		crate := OpenCrate(record[len(marker):], m.Flags|FlagStatic)
+		crate.ReadSelfSerializer(val)
+		return "", nil
+	}
+	for _, f := range m.formats {
+		if f.detect == nil || f.detect(record) {
+			return f.name, f.convert(record, val)
+		}
+	}
+	return "", ErrUnknownFormat
+}
+
+// Decode record into val like Read(), and if it was stored in an older format
+// pass its native encoding to writeBack so the caller can replace the stored copy.
+// An error from writeBack is returned, but val is still decoded
+func (m *Migrator) ReadRepair(record []byte, val SelfSerializer, writeBack func(native []byte) error) (format string, err error) {
+	format, err = m.Read(record, val)
+	if err != nil || format == "" {
+		return format, err
+	}
+	return format, writeBack(m.Write(val))
+}
+
+func (m *Migrator) marker() []byte {
+	if m.Marker == nil {
+		return DefaultMigrationMarker
+	}
+	return m.Marker
+}
+
+// Detects JSON objects and arrays: records whose first non-space byte is '{' or '['
+func DetectJSON(record []byte) bool {
+	record = bytes.TrimLeft(record, " \t\r\n")
+	return len(record) > 0 && (record[0] == '{' || record[0] == '[')
+}
+
+// Returns a Converter that decodes JSON records with encoding/json directly into val,
+// which must be a pointer to a type encoding/json can unmarshal the old records into
+func JSONConverter() Converter {
+	return func(record []byte, val SelfSerializer) error {
+		return json.Unmarshal(record, val)
+	}
+}
+
+// Returns a Converter that decodes JSON records written by Crate.ToJSON() with schema,
+// by rebuilding the crate with FromJSON() and reading val from it
+func SchemaConverter(schema *Schema, flags uint8) Converter {
+	return func(record []byte, val SelfSerializer) (err error) {
+		crate := NewCrate(64, flags|FlagAutoDouble)
+		if err := crate.FromJSON(schema, record); err != nil {
+			return err
+		}
+		defer func() {
+			if r := recover(); r != nil {
+				err = jsonError(r)
+			}
+		}()
+		crate.ReadSelfSerializer(val)
+		return nil
+	}
+}
+
+// Returns a Converter that decodes gob records with encoding/gob directly into val,
+// which must be a pointer to a type encoding/gob can decode the old records into
+func GobConverter() Converter {
+	return func(record []byte, val SelfSerializer) error {
+		return gob.NewDecoder(bytes.NewReader(record)).Decode(val)
+	}
+}
@@ -0,0 +1,95 @@
+//go:build !litecrate_core && !litecrate_notools
+
+package litecrate_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+type migrateUser struct {
+	Name string `json:"name"`
+	Age  uint8  `json:"age"`
+}
+
+func (u *migrateUser) UseSelf(crate *lite.Crate, mode lite.UseMode) {
+	crate.UseStringWithCounter(&u.Name, mode)
+	crate.UseU8(&u.Age, mode)
+}
+
+func TestMigrator(t *testing.T) {
+	var m lite.Migrator
+	m.Register("json", lite.DetectJSON, lite.JSONConverter())
+	m.Register("gob", nil, lite.GobConverter())
+
+	want := migrateUser{Name: "gabe", Age: 30}
+	var gobRecord bytes.Buffer
+	if err := gob.NewEncoder(&gobRecord).Encode(want); err != nil {
+		t.Fatal(err)
+	}
+	native := m.Write(&want)
+	if !bytes.HasPrefix(native, lite.DefaultMigrationMarker) {
+		t.Errorf("Migrator.Write() - FAIL: missing marker %v", native)
+	}
+	for _, test := range []struct {
+		record []byte
+		format string
+	}{
+		{native, ""},
+		{[]byte(` {"name":"gabe","age":30}`), "json"},
+		{gobRecord.Bytes(), "gob"},
+	} {
+		var got migrateUser
+		var written []byte
+		format, err := m.ReadRepair(test.record, &got, func(record []byte) error {
+			written = record
+			return nil
+		})
+		if err != nil || format != test.format || got != want {
+			t.Errorf("Migrator.ReadRepair(%s) - FAIL: format %q, %+v, %v", test.format, format, got, err)
+		}
+		if test.format == "" && written != nil {
+			t.Errorf("Migrator.ReadRepair() - FAIL: native record was written back")
+		}
+		if test.format != "" && !bytes.Equal(written, native) {
+			t.Errorf("Migrator.ReadRepair(%s) - FAIL: wrote back %v, expected %v", test.format, written, native)
+		}
+	}
+
+	var got migrateUser
+	failed := errors.New("write failed")
+	if _, err := m.ReadRepair([]byte(`{"name":"x"}`), &got, func([]byte) error { return failed }); err != failed || got.Name != "x" {
+		t.Errorf("Migrator.ReadRepair() - FAIL: write back error %v, %+v", err, got)
+	}
+	if _, err := m.Read(native[:len(native)-1], &got); err == nil {
+		t.Errorf("Migrator.Read() - FAIL: truncated native record did not error")
+	}
+	var strict lite.Migrator
+	if _, err := strict.Read([]byte("plain text"), &got); err != lite.ErrUnknownFormat {
+		t.Errorf("Migrator.Read() - FAIL: unknown format returned %v", err)
+	}
+	custom := lite.Migrator{Marker: []byte("v2:")}
+	if record := custom.Write(&want); string(record[:3]) != "v2:" {
+		t.Errorf("Migrator.Write() - FAIL: custom marker not used %v", record)
+	}
+}
+
+func TestSchemaConverter(t *testing.T) {
+	schema := &lite.Schema{Name: "User", Fields: []lite.Field{
+		{Name: "name", Kind: lite.KindString},
+		{Name: "age", Kind: lite.KindU8},
+	}}
+	var m lite.Migrator
+	m.Register("schema", lite.DetectJSON, lite.SchemaConverter(schema, 0))
+	var got migrateUser
+	if format, err := m.Read([]byte(`{"name":"ann","age":7}`), &got); err != nil || format != "schema" || got != (migrateUser{Name: "ann", Age: 7}) {
+		t.Errorf("SchemaConverter() - FAIL: format %q, %+v, %v", format, got, err)
+	}
+	if _, err := m.Read([]byte(`{"name":"ann","age":700}`), &got); err == nil {
+		t.Errorf("SchemaConverter() - FAIL: out of range field did not error")
+	}
+}
@@ -0,0 +1,110 @@
+package litecrate
+
+import (
+	"sync"
+)
+
+// Handles a custom UseMode registered with RegisterMode().
+//
+// Every Use____() method passed a custom mode calls its handler with val holding a pointer to
+// the value being used (*uint8, *string, *[]byte, *time.Time, *BlobRef...) and name holding the name of
+// the method ("UseU8"), so handlers can inspect or modify values by type switching on val.
+// SelfSerializers, VersionedSerializers, slices, maps, UseRef() and UseOptional() pointers,
+// UseUnion(), UseIf(), UseField(), UseFieldGroup() and UseSection() are also passed to the handler (as SelfSerializer,
+// VersionedSerializer, *[]T, *map[K]V, **T, the *uint16 tag, the *bool condition, the *uint16 field id and nil),
+// then the mode is passed on to the values inside them
+// (changes to map keys are ignored, and each UseRef() object is only visited once until Reset() or ResetGraph()),
+// so a custom mode visits a whole tree of values without reading or writing any data.
+// Lazy values and UseAny() values are passed to the handler without visiting the values inside them.
+//
+// Example:
+//
+//	var Redact = RegisterMode("Redact", func(crate *Crate, val any, name string) {
+//		if str, ok := val.(*string); ok {
+//			*str = "[REDACTED]"
+//		}
+//	})
+//
+//	crate.UseSelfSerializer(&myRecord, Redact)
+type ModeHandler func(crate *Crate, val any, name string)
+
+// First UseMode value handed out by RegisterMode()
+const firstCustomMode = Slice + 1
+
+var customModes struct {
+	sync.RWMutex
+	names    [256]string
+	handlers [256]ModeHandler
+	next     UseMode
+}
+
+/**************
+	MODES
+***************/
+
+// Register a custom UseMode that calls handler for every value it is used with, and return it.
+// Usually called while initializing package level variables. At most 251 modes can be registered
+func RegisterMode(name string, handler ModeHandler) UseMode {
+	if handler == nil {
+		panic("LiteCrate: RegisterMode() requires a handler")
+	}
+	customModes.Lock()
+	defer customModes.Unlock()
+	if customModes.next == 0 {
+		customModes.next = firstCustomMode
+	}
+	mode := customModes.next
+	if customModes.handlers[mode] != nil {
+		panic("LiteCrate: cannot register more than " + intStr(256-int(firstCustomMode)) + " custom modes")
+	}
+	customModes.names[mode] = name
+	customModes.handlers[mode] = handler
+	if mode < 255 {
+		customModes.next += 1
+	}
+	return mode
+}
+
+// Returns whether mode is one of the built in modes (Write, Read, Peek, Discard, Slice)
+// or was registered with RegisterMode()
+func (mode UseMode) IsValid() bool {
+	return mode < firstCustomMode || modeHandler(mode) != nil
+}
+
+// Returns the name of a built in or registered mode
+func (mode UseMode) String() string {
+	switch mode {
+	case Write:
+		return "Write"
+	case Read:
+		return "Read"
+	case Peek:
+		return "Peek"
+	case Discard:
+		return "Discard"
+	case Slice:
+		return "Slice"
+	}
+	customModes.RLock()
+	defer customModes.RUnlock()
+	if customModes.handlers[mode] == nil {
+		return "UseMode(" + intStr(mode) + ")"
+	}
+	return customModes.names[mode]
+}
+
+func modeHandler(mode UseMode) ModeHandler {
+	customModes.RLock()
+	defer customModes.RUnlock()
+	return customModes.handlers[mode]
+}
+
+// Called by Use____() methods for modes other than the built in ones,
+// panics if mode was not registered
+func (c *Crate) useCustomMode(val any, mode UseMode, name string) {
+	handler := modeHandler(mode)
+	if handler == nil {
+		panic("LiteCrate: Invalid mode passed to " + name + "()")
+	}
+	handler(c, val, name)
+}
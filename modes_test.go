@@ -0,0 +1,72 @@
+package litecrate_test
+
+import (
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+var redactMode = lite.RegisterMode("Redact", func(crate *lite.Crate, val any, name string) {
+	if str, ok := val.(*string); ok {
+		*str = "[REDACTED]"
+	}
+})
+
+var visitCounts = make(map[string]int)
+
+var countMode = lite.RegisterMode("Count", func(crate *lite.Crate, val any, name string) {
+	visitCounts[name] += 1
+})
+
+type modeRecord struct {
+	Name    string
+	Age     uint8
+	Aliases []string
+	Notes   map[uint8]string
+	Friend  *modeRecord
+}
+
+func (r *modeRecord) UseSelf(crate *lite.Crate, mode lite.UseMode) {
+	crate.UseStringWithCounter(&r.Name, mode)
+	crate.UseU8(&r.Age, mode)
+	lite.UseSlice(crate, mode, &r.Aliases, crate.UseStringWithCounter)
+	lite.UseMap(crate, mode, &r.Notes, crate.UseU8, crate.UseStringWithCounter)
+	lite.UseRef(crate, mode, &r.Friend, (*modeRecord).UseSelf)
+}
+
+func TestCustomModes(t *testing.T) {
+	a := &modeRecord{Name: "a", Age: 30, Aliases: []string{"x", "y"}, Notes: map[uint8]string{1: "n"}}
+	b := &modeRecord{Name: "b", Friend: a}
+	a.Friend = b
+	crate := lite.NewCrate(16, lite.FlagAutoDouble)
+
+	crate.UseSelfSerializer(a, countMode)
+	want := map[string]int{"UseSelfSerializer": 1, "UseStringWithCounter": 9, "UseU8": 5, "UseSlice": 3, "UseMap": 3, "UseRef": 3}
+	for name, count := range want {
+		if visitCounts[name] != count {
+			t.Errorf("Count mode - FAIL: %s visited %d times, expected %d", name, visitCounts[name], count)
+		}
+	}
+	if crate.WriteIndex() != 0 || crate.ReadIndex() != 0 {
+		t.Errorf("Count mode - FAIL: custom mode moved indexes")
+	}
+
+	crate.Reset()
+	crate.UseSelfSerializer(a, redactMode)
+	if a.Name != "[REDACTED]" || b.Name != "[REDACTED]" || a.Aliases[1] != "[REDACTED]" || a.Notes[1] != "[REDACTED]" || a.Age != 30 {
+		t.Errorf("Redact mode - FAIL: %+v, %+v", a, b)
+	}
+
+	if redactMode.String() != "Redact" || lite.Peek.String() != "Peek" || lite.UseMode(200).String() != "UseMode(200)" {
+		t.Errorf("UseMode.String() - FAIL: %q, %q, %q", redactMode.String(), lite.Peek.String(), lite.UseMode(200).String())
+	}
+	if !countMode.IsValid() || !lite.Slice.IsValid() || lite.UseMode(200).IsValid() {
+		t.Errorf("UseMode.IsValid() - FAIL")
+	}
+	if catchPanic[error](func() { crate.UseSelfSerializer(a, lite.UseMode(200)) }) == nil {
+		t.Errorf("UseSelfSerializer - FAIL: unregistered mode did not panic")
+	}
+	if catchPanic[error](func() { lite.RegisterMode("nil", nil) }) == nil {
+		t.Errorf("RegisterMode() - FAIL: nil handler did not panic")
+	}
+}
@@ -0,0 +1,92 @@
+//go:build !litecrate_core && !litecrate_nocompress
+
+package litecrate
+
+import (
+	"math"
+	"sync"
+)
+
+// Mean deviations of the compression ratio MTUEstimator leaves room for when Deviations is 0
+const DefaultMTUDeviations = 2
+
+const (
+	mtuRatioWeight     = 0.125 // weight of each observation in the smoothed ratio (as RFC 6298 smooths round trip times)
+	mtuDeviationWeight = 0.25  // weight of each observation in the smoothed mean deviation of the ratio
+)
+
+/**************
+	MTU
+***************/
+
+// An MTUEstimator tracks how well recent payloads compressed so that datagram builders can tell,
+// before encoding, how many uncompressed bytes will fit in one datagram once compressed and wrapped in headers.
+//
+// Like TCP's round trip time estimate, it keeps a smoothed compression ratio and its smoothed mean deviation,
+// and budgets for the ratio plus Deviations mean deviations, so payloads that compress a little worse than usual still fit.
+// Until the first observation the ratio is assumed to be 1.
+// The zero value is ready to use, and all methods are safe to call concurrently
+type MTUEstimator struct {
+	MTU        int     // Bytes available in one datagram, 0 = DefaultCoalesceSize
+	Overhead   int     // Bytes each datagram adds around its compressed payload (frame headers, sealing nonce and tag...)
+	Deviations float64 // Mean deviations of the ratio to leave room for, 0 = DefaultMTUDeviations
+	mutex      sync.Mutex
+	samples    uint64
+	ratio      float64
+	deviation  float64
+}
+
+// Record that a payload of uncompressed bytes compressed to compressed bytes
+func (e *MTUEstimator) Observe(uncompressed int, compressed int) {
+	if uncompressed <= 0 {
+		return
+	}
+	ratio := float64(compressed) / float64(uncompressed)
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.samples == 0 {
+		e.ratio, e.deviation = ratio, ratio/2
+	} else {
+		e.deviation += mtuDeviationWeight * (math.Abs(ratio-e.ratio) - e.deviation)
+		e.ratio += mtuRatioWeight * (ratio - e.ratio)
+	}
+	e.samples += 1
+}
+
+// Compress the crate with comp (as Crate.Compress()) and record how well it compressed
+func (e *MTUEstimator) Compress(crate *Crate, comp Compressor) error {
+	uncompressed := crate.write
+	if err := crate.Compress(comp); err != nil {
+		return err
+	}
+	e.Observe(int(uncompressed), int(crate.write))
+	return nil
+}
+
+// Returns the smoothed compression ratio (compressed size / uncompressed size)
+// and its smoothed mean deviation
+func (e *MTUEstimator) Ratio() (ratio float64, deviation float64) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.samples == 0 {
+		return 1, 0
+	}
+	return e.ratio, e.deviation
+}
+
+// Returns how many uncompressed payload bytes are expected to fit in one datagram
+func (e *MTUEstimator) PayloadBudget() int {
+	mtu, deviations := e.MTU, e.Deviations
+	if mtu == 0 {
+		mtu = DefaultCoalesceSize
+	}
+	if deviations == 0 {
+		deviations = DefaultMTUDeviations
+	}
+	space := mtu - e.Overhead
+	if space <= 0 {
+		return 0
+	}
+	ratio, deviation := e.Ratio()
+	return int(float64(space) / (ratio + deviations*deviation))
+}
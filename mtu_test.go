@@ -0,0 +1,58 @@
+//go:build !litecrate_core && !litecrate_nocompress
+
+package litecrate_test
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+func TestMTUEstimator(t *testing.T) {
+	var estimator lite.MTUEstimator
+	if ratio, deviation := estimator.Ratio(); ratio != 1 || deviation != 0 || estimator.PayloadBudget() != lite.DefaultCoalesceSize {
+		t.Errorf("MTUEstimator(unobserved) - FAIL: ratio %v, budget %d", ratio, estimator.PayloadBudget())
+	}
+	estimator.MTU, estimator.Overhead = 1200, 200
+	for i := 0; i < 50; i += 1 {
+		estimator.Observe(1000, 500)
+	}
+	ratio, deviation := estimator.Ratio()
+	if ratio != 0.5 || deviation > 0.01 {
+		t.Errorf("MTUEstimator.Ratio(steady) - FAIL: %v, %v", ratio, deviation)
+	}
+	if budget := estimator.PayloadBudget(); budget > 2000 || budget < 1900 {
+		t.Errorf("MTUEstimator.PayloadBudget(steady) - FAIL: %d", budget)
+	}
+
+	// Nearly every payload budgeted from a varying ratio should still fit once compressed
+	noisy := lite.MTUEstimator{MTU: 1200, Overhead: 28}
+	random := rand.New(rand.NewSource(1))
+	misses := 0
+	for i := 0; i < 200; i += 1 {
+		ratio := 0.4 + random.Float64()*0.1
+		budget := noisy.PayloadBudget()
+		if i >= 20 && int(float64(budget)*ratio)+28 > 1200 {
+			misses += 1
+		}
+		noisy.Observe(budget, int(float64(budget)*ratio))
+	}
+	if misses > 18 {
+		t.Errorf("MTUEstimator.PayloadBudget(noisy) - FAIL: %d of 180 payloads did not fit", misses)
+	}
+
+	crate := lite.NewCrate(4096, lite.FlagDefault)
+	crate.WriteBytes(bytes.Repeat([]byte("abcd"), 1000))
+	compressing := lite.MTUEstimator{}
+	if err := compressing.Compress(crate, lite.Gzip); err != nil {
+		t.Fatalf("MTUEstimator.Compress() - FAIL: %v", err)
+	}
+	if ratio, _ := compressing.Ratio(); ratio != float64(crate.WriteIndex())/4000 {
+		t.Errorf("MTUEstimator.Compress() - FAIL: ratio %v not observed", ratio)
+	}
+	if budget := (&lite.MTUEstimator{MTU: 10, Overhead: 20}).PayloadBudget(); budget != 0 {
+		t.Errorf("MTUEstimator.PayloadBudget(overhead > MTU) - FAIL: %d", budget)
+	}
+}
@@ -0,0 +1,97 @@
+package litecrate
+
+/**************
+	NESTED CRATES
+***************/
+
+// Crates are embedded as their written data preceded by a length-or-nil counter (nil for a nil crate),
+// so an envelope can carry a payload crate without decoding it. Unlike Crate.UseSelf(), the inner crate's
+// indexes and flags are not written, and the payload is read back into a crate with a read index of 0
+
+// Discard next unread nested crate in crate
+func (c *Crate) DiscardCrate() {
+	c.DiscardBytesWithCounter()
+}
+
+// Return byte slice the next unread nested crate's data occupies (not including counter)
+func (c *Crate) SliceCrate() (slice []byte) {
+	length, _, n := c.PeekLengthOrNil()
+	c.CheckRead(n + length)
+	if c.failed != nil {
+		return nil
+	}
+	return c.data[c.read+n : c.read+n+length : c.read+n+length]
+}
+
+// Write inner's written data to crate with preceding length-or-nil counter,
+// or a nil counter if inner is nil. inner's indexes are left unchanged
+func (c *Crate) WriteCrate(inner *Crate) {
+	if inner == nil {
+		c.WriteLengthOrNil(0, true)
+		return
+	}
+	c.WriteBytesWithCounter(inner.Data())
+}
+
+// Read next nested crate into a new crate flagged with FlagDefault holding a copy of its data,
+// or nil if a nil crate was written
+func (c *Crate) ReadCrate() (inner *Crate) {
+	data := c.ReadBytesWithCounter()
+	if data == nil {
+		return nil
+	}
+	return OpenCrate(data, FlagDefault)
+}
+
+// Read next nested crate from crate without advancing read index
+func (c *Crate) PeekCrate() (inner *Crate) {
+	idx := c.read
+	inner = c.ReadCrate()
+	c.read = idx
+	return inner
+}
+
+// Read next nested crate into inner, replacing its contents and resetting it (its flags are kept,
+// and its buffer is reused if the data fits). A nil crate is read as an empty one
+func (c *Crate) ReadCrateInto(inner *Crate) {
+	length, _, _ := c.ReadLengthOrNil()
+	if length > len64(inner.data) {
+		inner.replaceData(c.ReadBytes(length))
+		return
+	}
+	c.CheckRead(length)
+	if c.failed != nil {
+		return
+	}
+	inner.replaceData(c.data[c.read : c.read+length])
+	c.read += length
+}
+
+// Use the nested crate pointed to by val according to mode:
+// Write = 'write val's data into crate', Read = 'read from crate into val' (into the existing crate if *val is not nil,
+// see ReadCrateInto(), or else a new one, see ReadCrate()),
+// Peek = 'read from crate into val without advancing index'
+// Slice = 'Return the slice the next unread nested crate's data occupies without altering val'
+func (c *Crate) UseCrate(val **Crate, mode UseMode) (sliceModeData []byte) {
+	switch mode {
+	case Write:
+		c.WriteCrate(*val)
+	case Read, Peek:
+		idx := c.read
+		if *val == nil {
+			*val = c.ReadCrate()
+		} else {
+			c.ReadCrateInto(*val)
+		}
+		if mode == Peek {
+			c.read = idx
+		}
+	case Discard:
+		c.DiscardCrate()
+	case Slice:
+		sliceModeData = c.SliceCrate()
+	default:
+		c.useCustomMode(val, mode, "UseCrate")
+	}
+	return sliceModeData
+}
@@ -0,0 +1,80 @@
+package litecrate_test
+
+import (
+	"bytes"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+func TestNestedCrate(t *testing.T) {
+	payload := lite.NewCrate(16, lite.FlagAutoDouble)
+	payload.WriteStringWithCounter("inner message")
+	payload.WriteU32(7)
+	payload.ReadStringWithCounter()
+
+	envelope := lite.NewCrate(8, lite.FlagAutoDouble)
+	envelope.WriteU16(3)
+	envelope.WriteCrate(payload)
+	envelope.WriteCrate(nil)
+	envelope.WriteU8(9)
+	if payload.ReadIndex() == 0 || payload.ReadU32() != 7 {
+		t.Error("WriteCrate() - FAIL: altered inner crate's indexes")
+	}
+
+	if envelope.ReadU16() != 3 {
+		t.Fatal("ReadCrate() - FAIL: header did not read back")
+	}
+	if slice := envelope.SliceCrate(); !bytes.Equal(slice, payload.Data()) {
+		t.Errorf("SliceCrate() - FAIL: %v != %v", slice, payload.Data())
+	}
+	peeked := envelope.PeekCrate()
+	inner := envelope.ReadCrate()
+	if !bytes.Equal(inner.Data(), payload.Data()) || !bytes.Equal(peeked.Data(), payload.Data()) {
+		t.Fatalf("ReadCrate() - FAIL: %v != %v", inner.Data(), payload.Data())
+	}
+	if inner.ReadIndex() != 0 || inner.ReadStringWithCounter() != "inner message" || inner.ReadU32() != 7 {
+		t.Error("ReadCrate() - FAIL: inner crate did not read from the start")
+	}
+	if envelope.ReadCrate() != nil {
+		t.Error("ReadCrate() - FAIL: nil crate did not read back as nil")
+	}
+	if envelope.ReadU8() != 9 || envelope.ReadsLeft() != 0 {
+		t.Error("ReadCrate() - FAIL: did not read whole nested crate")
+	}
+
+	envelope.ResetReadIndex()
+	envelope.DiscardU16()
+	envelope.DiscardCrate()
+	envelope.DiscardCrate()
+	if envelope.ReadU8() != 9 {
+		t.Error("DiscardCrate() - FAIL: did not skip nested crates")
+	}
+
+	// Reading into an existing crate reuses it without aliasing the envelope
+	envelope.ResetReadIndex()
+	envelope.DiscardU16()
+	existing := lite.NewCrate(4, lite.FlagAutoDouble)
+	existing.WriteU32(1)
+	envelope.UseCrate(&existing, lite.Read)
+	if !bytes.Equal(existing.Data(), payload.Data()) || existing.ReadIndex() != 0 {
+		t.Fatal("UseCrate() - FAIL: did not replace existing crate")
+	}
+	existing.Data()[0] ^= 1
+	envelope.ResetReadIndex()
+	envelope.DiscardU16()
+	if !bytes.Equal(envelope.PeekCrate().Data(), payload.Data()) {
+		t.Error("UseCrate() - FAIL: existing crate aliased envelope's data")
+	}
+	var missing *lite.Crate
+	envelope.UseCrate(&missing, lite.Peek)
+	if missing == nil || !bytes.Equal(missing.Data(), payload.Data()) {
+		t.Error("UseCrate() - FAIL: did not read into nil crate")
+	}
+
+	truncated := lite.OpenCrate(envelope.Data()[:6], lite.FlagStatic)
+	truncated.DiscardU16()
+	if catchPanic[error](func() { truncated.SliceCrate() }) == nil || catchPanic[error](func() { truncated.ReadCrate() }) == nil {
+		t.Error("ReadCrate() - FAIL: read past end of data")
+	}
+}
@@ -0,0 +1,232 @@
+//go:build !litecrate_core && !litecrate_nonet
+
+package litecrate
+
+import (
+	"net"
+	"net/netip"
+)
+
+/**************
+	NETIP ADDR
+***************/
+
+// Addresses are written as a tag byte holding the number of address bytes that follow:
+// 0 for the zero netip.Addr, 4 for IPv4 and 16 for IPv6
+
+func addrTagSize(tag uint8) uint64 {
+	switch tag {
+	case 0, 4, 16:
+		return uint64(tag)
+	}
+	panic("LiteCrate: unknown address family tag " + intStr(tag))
+}
+
+// Discard next unread netip.Addr in crate
+func (c *Crate) DiscardNetIP() {
+	c.DiscardN(1 + addrTagSize(c.PeekU8()))
+}
+
+// Return byte slice the next unread netip.Addr occupies
+func (c *Crate) SliceNetIP() (slice []byte) {
+	return c.SliceBytes(1 + addrTagSize(c.PeekU8()))
+}
+
+// Write netip.Addr to crate as a family tag followed by 4 (IPv4) or 16 (IPv6) bytes.
+// IPv4-mapped IPv6 addresses stay IPv6. The IPv6 zone is not written
+func (c *Crate) WriteNetIP(val netip.Addr) {
+	switch {
+	case val.Is4():
+		bytes := val.As4()
+		c.CheckWrite(5)
+		c.data[c.write] = 4
+		copy(c.data[c.write+1:], bytes[:])
+		c.write += 5
+	case val.Is6():
+		bytes := val.As16()
+		c.CheckWrite(17)
+		c.data[c.write] = 16
+		copy(c.data[c.write+1:], bytes[:])
+		c.write += 17
+	default:
+		c.WriteU8(0)
+	}
+}
+
+// Read next netip.Addr from crate
+func (c *Crate) ReadNetIP() (val netip.Addr) {
+	val = c.PeekNetIP()
+	c.read += 1 + uint64(val.BitLen()/8)
+	return val
+}
+
+// Read next netip.Addr from crate without advancing read index
+func (c *Crate) PeekNetIP() (val netip.Addr) {
+	size := addrTagSize(c.PeekU8())
+	c.CheckRead(1 + size)
+	switch size {
+	case 4:
+		return netip.AddrFrom4(*(*[4]byte)(c.data[c.read+1 : c.read+5]))
+	case 16:
+		return netip.AddrFrom16(*(*[16]byte)(c.data[c.read+1 : c.read+17]))
+	}
+	return netip.Addr{}
+}
+
+// Use the netip.Addr pointed to by val according to mode:
+// Write = 'write val into crate', Read = 'read from crate into val',
+// Peek = 'read from crate into val without advancing index'
+// Slice = 'Return the slice the next unread val occupies without altering val'
+func (c *Crate) UseNetIP(val *netip.Addr, mode UseMode) (sliceModeData []byte) {
+	switch mode {
+	case Write:
+		c.WriteNetIP(*val)
+	case Read:
+		*val = c.ReadNetIP()
+	case Peek:
+		*val = c.PeekNetIP()
+	case Discard:
+		c.DiscardNetIP()
+	case Slice:
+		sliceModeData = c.SliceNetIP()
+	default:
+		c.useCustomMode(val, mode, "UseNetIP")
+	}
+	return sliceModeData
+}
+
+/**************
+	NETIP ADDR PORT
+***************/
+
+// Discard next unread netip.AddrPort in crate
+func (c *Crate) DiscardNetAddr() {
+	c.DiscardN(3 + addrTagSize(c.PeekU8()))
+}
+
+// Return byte slice the next unread netip.AddrPort occupies
+func (c *Crate) SliceNetAddr() (slice []byte) {
+	return c.SliceBytes(3 + addrTagSize(c.PeekU8()))
+}
+
+// Write netip.AddrPort to crate as its address (see WriteNetIP) followed by the port as a U16
+func (c *Crate) WriteNetAddr(val netip.AddrPort) {
+	c.WriteNetIP(val.Addr())
+	c.WriteU16(val.Port())
+}
+
+// Read next netip.AddrPort from crate
+func (c *Crate) ReadNetAddr() (val netip.AddrPort) {
+	c.CheckRead(3 + addrTagSize(c.PeekU8()))
+	addr := c.ReadNetIP()
+	return netip.AddrPortFrom(addr, c.ReadU16())
+}
+
+// Read next netip.AddrPort from crate without advancing read index
+func (c *Crate) PeekNetAddr() (val netip.AddrPort) {
+	idx := c.read
+	val = c.ReadNetAddr()
+	c.read = idx
+	return val
+}
+
+// Use the netip.AddrPort pointed to by val according to mode:
+// Write = 'write val into crate', Read = 'read from crate into val',
+// Peek = 'read from crate into val without advancing index'
+// Slice = 'Return the slice the next unread val occupies without altering val'
+func (c *Crate) UseNetAddr(val *netip.AddrPort, mode UseMode) (sliceModeData []byte) {
+	switch mode {
+	case Write:
+		c.WriteNetAddr(*val)
+	case Read:
+		*val = c.ReadNetAddr()
+	case Peek:
+		*val = c.PeekNetAddr()
+	case Discard:
+		c.DiscardNetAddr()
+	case Slice:
+		sliceModeData = c.SliceNetAddr()
+	default:
+		c.useCustomMode(val, mode, "UseNetAddr")
+	}
+	return sliceModeData
+}
+
+/**************
+	HARDWARE ADDR
+***************/
+
+// Hardware addresses use the same kind of tag as netip.Addr: 0 for nil,
+// 6 for EUI-48 (MAC), 8 for EUI-64 and 20 for IP over InfiniBand link-layer addresses
+
+func hardwareTagSize(tag uint8) uint64 {
+	switch tag {
+	case 0, 6, 8, 20:
+		return uint64(tag)
+	}
+	panic("LiteCrate: unknown hardware address tag " + intStr(tag))
+}
+
+// Discard next unread net.HardwareAddr in crate
+func (c *Crate) DiscardHardwareAddr() {
+	c.DiscardN(1 + hardwareTagSize(c.PeekU8()))
+}
+
+// Return byte slice the next unread net.HardwareAddr occupies
+func (c *Crate) SliceHardwareAddr() (slice []byte) {
+	return c.SliceBytes(1 + hardwareTagSize(c.PeekU8()))
+}
+
+// Write net.HardwareAddr to crate as a tag followed by its 6, 8 or 20 bytes.
+// Panics for any other length
+func (c *Crate) WriteHardwareAddr(val net.HardwareAddr) {
+	size := uint64(len(val))
+	if size != 0 && size != 6 && size != 8 && size != 20 {
+		panic("LiteCrate: hardware address has " + intStr(size) + " bytes, must have 0, 6, 8 or 20")
+	}
+	c.CheckWrite(1 + size)
+	c.data[c.write] = uint8(size)
+	copy(c.data[c.write+1:], val)
+	c.write += 1 + size
+}
+
+// Read next net.HardwareAddr from crate (nil if it was written as nil or empty)
+func (c *Crate) ReadHardwareAddr() (val net.HardwareAddr) {
+	val = c.PeekHardwareAddr()
+	c.read += 1 + uint64(len(val))
+	return val
+}
+
+// Read next net.HardwareAddr from crate without advancing read index
+func (c *Crate) PeekHardwareAddr() (val net.HardwareAddr) {
+	size := hardwareTagSize(c.PeekU8())
+	c.CheckRead(1 + size)
+	if size == 0 {
+		return nil
+	}
+	val = make(net.HardwareAddr, size)
+	copy(val, c.data[c.read+1:])
+	return val
+}
+
+// Use the net.HardwareAddr pointed to by val according to mode:
+// Write = 'write val into crate', Read = 'read from crate into val',
+// Peek = 'read from crate into val without advancing index'
+// Slice = 'Return the slice the next unread val occupies without altering val'
+func (c *Crate) UseHardwareAddr(val *net.HardwareAddr, mode UseMode) (sliceModeData []byte) {
+	switch mode {
+	case Write:
+		c.WriteHardwareAddr(*val)
+	case Read:
+		*val = c.ReadHardwareAddr()
+	case Peek:
+		*val = c.PeekHardwareAddr()
+	case Discard:
+		c.DiscardHardwareAddr()
+	case Slice:
+		sliceModeData = c.SliceHardwareAddr()
+	default:
+		c.useCustomMode(val, mode, "UseHardwareAddr")
+	}
+	return sliceModeData
+}
@@ -0,0 +1,133 @@
+//go:build !litecrate_core && !litecrate_nonet
+
+package litecrate_test
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+func TestNetIP(t *testing.T) {
+	crate := lite.NewCrate(16, lite.FlagAutoDouble)
+	values := []struct {
+		addr string
+		size uint64
+	}{
+		{"", 1},
+		{"192.168.1.20", 5},
+		{"::1", 17},
+		{"::ffff:10.0.0.1", 17},
+		{"2001:db8::68", 17},
+	}
+	for _, test := range values {
+		var val netip.Addr
+		if test.addr != "" {
+			val = netip.MustParseAddr(test.addr)
+		}
+		crate.Reset()
+		crate.UseNetIP(&val, lite.Write)
+		if crate.WriteIndex() != test.size {
+			t.Errorf("WriteNetIP(%v) - FAIL: wrote %d bytes, expected %d", val, crate.WriteIndex(), test.size)
+		}
+		var peeked, read netip.Addr
+		crate.UseNetIP(&peeked, lite.Peek)
+		slice := crate.UseNetIP(&read, lite.Slice)
+		crate.UseNetIP(&read, lite.Read)
+		if read != val || peeked != val || uint64(len(slice)) != test.size || crate.ReadsLeft() != 0 {
+			t.Errorf("ReadNetIP(%v) - FAIL: read %v, peeked %v", val, read, peeked)
+		}
+		crate.ResetReadIndex()
+		crate.UseNetIP(&read, lite.Discard)
+		if crate.ReadsLeft() != 0 {
+			t.Errorf("DiscardNetIP(%v) - FAIL: %d bytes left", val, crate.ReadsLeft())
+		}
+	}
+
+	crate.Reset()
+	crate.WriteNetIP(netip.MustParseAddr("fe80::1%eth0"))
+	if got := crate.ReadNetIP(); got != netip.MustParseAddr("fe80::1") {
+		t.Errorf("ReadNetIP() - FAIL: zone not dropped: %v", got)
+	}
+	crate.Reset()
+	crate.WriteU8(5)
+	if catchPanic[error](func() { crate.ReadNetIP() }) == nil {
+		t.Errorf("ReadNetIP() - FAIL: unknown family tag did not panic")
+	}
+	crate.Reset()
+	crate.WriteU8(16)
+	crate.WriteU64(0)
+	if catchPanic[error](func() { crate.ReadNetIP() }) == nil || crate.ReadIndex() != 0 {
+		t.Errorf("ReadNetIP() - FAIL: truncated address did not panic")
+	}
+	var val netip.Addr
+	if catchPanic[error](func() { crate.UseNetIP(&val, lite.UseMode(255)) }) == nil {
+		t.Errorf("UseNetIP - FAIL: invalid mode did not panic")
+	}
+}
+
+func TestNetAddr(t *testing.T) {
+	crate := lite.NewCrate(16, lite.FlagAutoDouble)
+	for _, val := range []netip.AddrPort{{}, netip.MustParseAddrPort("10.1.2.3:8080"), netip.MustParseAddrPort("[2001:db8::1]:443")} {
+		crate.Reset()
+		crate.UseNetAddr(&val, lite.Write)
+		var peeked, read netip.AddrPort
+		crate.UseNetAddr(&peeked, lite.Peek)
+		slice := crate.UseNetAddr(&read, lite.Slice)
+		crate.UseNetAddr(&read, lite.Read)
+		if read != val || peeked != val || uint64(len(slice)) != crate.WriteIndex() || crate.ReadsLeft() != 0 {
+			t.Errorf("ReadNetAddr(%v) - FAIL: read %v, peeked %v", val, read, peeked)
+		}
+		crate.ResetReadIndex()
+		crate.UseNetAddr(&read, lite.Discard)
+		if crate.ReadsLeft() != 0 {
+			t.Errorf("DiscardNetAddr(%v) - FAIL: %d bytes left", val, crate.ReadsLeft())
+		}
+	}
+	crate.Reset()
+	crate.WriteNetIP(netip.MustParseAddr("10.1.2.3"))
+	crate.WriteU8(1)
+	if catchPanic[error](func() { crate.ReadNetAddr() }) == nil || crate.ReadIndex() != 0 {
+		t.Errorf("ReadNetAddr() - FAIL: truncated port did not panic before reading address")
+	}
+}
+
+func TestHardwareAddr(t *testing.T) {
+	crate := lite.NewCrate(16, lite.FlagAutoDouble)
+	for _, text := range []string{"", "00:00:5e:00:53:01", "02:00:5e:10:00:00:00:01", "00:00:00:00:fe:80:00:00:00:00:00:00:02:00:5e:10:00:00:00:01"} {
+		var val net.HardwareAddr
+		if text != "" {
+			val, _ = net.ParseMAC(text)
+		}
+		crate.Reset()
+		crate.UseHardwareAddr(&val, lite.Write)
+		if crate.WriteIndex() != uint64(1+len(val)) {
+			t.Errorf("WriteHardwareAddr(%v) - FAIL: wrote %d bytes", val, crate.WriteIndex())
+		}
+		var peeked, read net.HardwareAddr
+		crate.UseHardwareAddr(&peeked, lite.Peek)
+		slice := crate.UseHardwareAddr(&read, lite.Slice)
+		crate.UseHardwareAddr(&read, lite.Read)
+		if read.String() != val.String() || peeked.String() != val.String() || len(slice) != 1+len(val) || crate.ReadsLeft() != 0 {
+			t.Errorf("ReadHardwareAddr(%v) - FAIL: read %v, peeked %v", val, read, peeked)
+		}
+		crate.ResetReadIndex()
+		crate.UseHardwareAddr(&read, lite.Discard)
+		if crate.ReadsLeft() != 0 {
+			t.Errorf("DiscardHardwareAddr(%v) - FAIL: %d bytes left", val, crate.ReadsLeft())
+		}
+	}
+	crate.Reset()
+	if catchPanic[error](func() { crate.WriteHardwareAddr(make(net.HardwareAddr, 7)) }) == nil || crate.WriteIndex() != 0 {
+		t.Errorf("WriteHardwareAddr() - FAIL: 7 byte address did not panic")
+	}
+	if catchPanic[error](func() { crate.WriteHardwareAddr(make(net.HardwareAddr, 262)) }) == nil {
+		t.Errorf("WriteHardwareAddr() - FAIL: 262 byte address did not panic")
+	}
+	crate.WriteU8(7)
+	if catchPanic[error](func() { crate.ReadHardwareAddr() }) == nil {
+		t.Errorf("ReadHardwareAddr() - FAIL: unknown tag did not panic")
+	}
+}
@@ -0,0 +1,92 @@
+package litecrate
+
+import "errors"
+
+// Largest scratch space a crate failed under FlagNoPanic keeps for the fixed size reads and writes
+// it turns into no-ops. Strings, byte slices and other variable size values skip their copy
+// once the crate has failed, so a larger read or write (such as one sized by a corrupt counter) gets no scratch space
+const maxSinkSize = 1 << 20
+
+// Why and where a crate flagged with FlagNoPanic failed
+type failure struct {
+	err   error
+	data  []byte // the crate's buffer, set aside while reads and writes go to sink
+	write uint64
+	read  uint64
+	sink  []byte
+}
+
+/**************
+	NO PANIC
+***************/
+
+// Returns the error that failed the crate if it is flagged with FlagNoPanic, or nil.
+// A failed crate stays failed until Reset() or ClearErr(): its reads see zeroed bytes (so numbers
+// read as 0, counters as nil and strings and byte slices as empty) and its writes are dropped,
+// so a whole value can be read or written and the error checked once.
+// Data() (and Key(), DataCopy(), Hash() and Sum64()) use the data written before the failure, while the indexes
+// describe scratch space, and methods that transform the whole crate (Sign(), Seal(), Compress() and the like) refuse to.
+//
+// Example:
+//
+//	crate := OpenCrate(frame, FlagStatic|FlagNoPanic)
+//	crate.ReadSelfSerializer(&msg)
+//	if err := crate.Err(); err != nil {
+//		return err
+//	}
+func (c *Crate) Err() error {
+	if c.failed == nil {
+		return nil
+	}
+	return c.failed.err
+}
+
+// Un-fail a crate that failed under FlagNoPanic, returning its data and indexes to where they were
+// when it failed (the bytes of a partly written value before the failure are kept, see RollbackWrite()).
+// Returns the error it had failed with, or nil
+func (c *Crate) ClearErr() error {
+	f := c.failed
+	if f == nil {
+		return nil
+	}
+	c.data, c.write, c.read = f.data, f.write, f.read
+	c.failed = nil
+	return f.err
+}
+
+// Called by CheckRead() and CheckWrite() when a read or write of size bytes cannot succeed:
+// panics with r, or if the crate is flagged with FlagNoPanic fails it with r as its error
+func (c *Crate) failCheck(r any, size uint64, write bool) {
+	if c.flags&FlagNoPanic == 0 {
+		panic(r)
+	}
+	err, ok := r.(error)
+	if !ok {
+		err = errors.New(r.(string))
+	}
+	c.failed = &failure{err: err, data: c.data, write: c.write, read: c.read}
+	c.useSink(size, write)
+}
+
+// Point a failed crate at size zeroed bytes of scratch space, so the read or write being checked
+// runs without touching the crate's data. Over maxSinkSize the crate is pointed at no bytes at all,
+// and the caller must check c.failed and skip its copy
+func (c *Crate) useSink(size uint64, write bool) {
+	f := c.failed
+	if size > maxSinkSize {
+		c.data, c.read, c.write = f.sink[:0], 0, 0
+		return
+	}
+	if len64(f.sink) < size {
+		f.sink = make([]byte, size)
+	} else {
+		sink := f.sink[:size]
+		for i := range sink {
+			sink[i] = 0
+		}
+	}
+	c.data, c.read, c.write = f.sink, 0, 0
+	if !write {
+		c.write = size
+	}
+}
@@ -0,0 +1,178 @@
+package litecrate_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"hash/fnv"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+func TestNoPanicRead(t *testing.T) {
+	order := &validatedOrder{ID: 7, Note: "gift", Items: []validatedItem{{"A-1", 2}}, Tags: map[string]uint16{"rush": 1}}
+	full := lite.NewCrate(32, lite.FlagAutoDouble)
+	full.WriteSelfSerializer(order)
+	data := full.Data()[:9]
+
+	crate := lite.OpenCrate(data, lite.FlagStatic|lite.FlagNoPanic)
+	var val validatedOrder
+	if catchPanic[error](func() { crate.ReadSelfSerializer(&val) }) != nil {
+		t.Fatal("FlagNoPanic - FAIL: reading truncated data panicked")
+	}
+	if crate.Err() == nil {
+		t.Fatal("Err() - FAIL: no error after reading truncated data")
+	}
+	// The data ends inside the first item's SKU, so it is read as empty and everything after it from zeroed bytes
+	if val.ID != 7 || val.Note != "gift" || len(val.Items) != 1 || val.Items[0] != (validatedItem{}) || val.Tags != nil {
+		t.Errorf("FlagNoPanic - FAIL: read %+v, expected fields before the failure only", val)
+	}
+	if crate.ReadU64() != 0 || crate.ReadStringWithCounter() != "" || crate.Err() == nil {
+		t.Error("FlagNoPanic - FAIL: failed crate did not read zero values")
+	}
+	if !bytes.Equal(crate.Data(), data) {
+		t.Error("Data() - FAIL: failed crate did not return data written before failure")
+	}
+	if catchPanic[error](func() { crate.ReadBytes(2 << 20) }) != nil {
+		t.Error("FlagNoPanic - FAIL: read larger than the scratch space panicked")
+	}
+
+	if crate.ClearErr() == nil || crate.Err() != nil || crate.ReadIndex() != 8 || crate.WriteIndex() != 9 {
+		t.Errorf("ClearErr() - FAIL: read index %d, write index %d after clearing", crate.ReadIndex(), crate.WriteIndex())
+	}
+	if crate.ClearErr() != nil {
+		t.Error("ClearErr() - FAIL: returned error from crate that had not failed")
+	}
+}
+
+func TestNoPanicOversizedCounter(t *testing.T) {
+	for _, length := range []uint64{5 << 20, 1 << 40} {
+		frame := lite.NewCrate(16, lite.FlagAutoDouble)
+		frame.WriteLengthOrNil(length, false)
+		frame.WriteString("short")
+
+		crate := lite.OpenCrate(frame.Data(), lite.FlagStatic|lite.FlagNoPanic)
+		var b []byte
+		if catchPanic[error](func() { b = crate.ReadBytesWithCounter() }) != nil {
+			t.Fatalf("ReadBytesWithCounter() - FAIL: counter of %d panicked under FlagNoPanic", length)
+		}
+		if b != nil || !errors.Is(crate.Err(), lite.ErrReadPastEnd) {
+			t.Errorf("ReadBytesWithCounter() - FAIL: read %d bytes with error %v from counter of %d", len(b), crate.Err(), length)
+		}
+
+		crate = lite.OpenCrate(frame.Data(), lite.FlagStatic|lite.FlagNoPanic)
+		var s string
+		if catchPanic[error](func() { s = crate.ReadStringWithCounter() }) != nil {
+			t.Fatalf("ReadStringWithCounter() - FAIL: counter of %d panicked under FlagNoPanic", length)
+		}
+		if s != "" || !errors.Is(crate.Err(), lite.ErrReadPastEnd) {
+			t.Errorf("ReadStringWithCounter() - FAIL: read %q with error %v from counter of %d", s, crate.Err(), length)
+		}
+		if crate.ReadU32() != 0 || crate.Err() == nil {
+			t.Error("FlagNoPanic - FAIL: crate failed by an oversized counter did not read zero values")
+		}
+	}
+
+	crate := lite.NewCrate(4, lite.FlagStatic|lite.FlagNoGrow|lite.FlagNoPanic)
+	if catchPanic[error](func() { crate.WriteBytes(make([]byte, 5<<20)) }) != nil {
+		t.Fatal("WriteBytes() - FAIL: write larger than the scratch space panicked under FlagNoPanic")
+	}
+	if !errors.Is(crate.Err(), lite.ErrNoGrow) || len(crate.Data()) != 0 {
+		t.Errorf("WriteBytes() - FAIL: got error %v and %d bytes after oversized write", crate.Err(), len(crate.Data()))
+	}
+}
+
+func TestNoPanicWrite(t *testing.T) {
+	crate := lite.NewCrate(9, lite.FlagStatic|lite.FlagNoGrow|lite.FlagNoPanic)
+	crate.WriteU32(0xDEADBEEF)
+	// The section's length and the string's counter fit, the string does not
+	crate.BeginSection()
+	crate.WriteStringWithCounter("does not fit")
+	crate.EndSection()
+	crate.WriteU64(9)
+	if !errors.Is(crate.Err(), lite.ErrNoGrow) {
+		t.Fatalf("Err() - FAIL: got %v, expected ErrNoGrow", crate.Err())
+	}
+	if !bytes.Equal(crate.Data(), []byte{0xEF, 0xBE, 0xAD, 0xDE, 0, 0, 0, 0, 13}) {
+		t.Errorf("FlagNoPanic - FAIL: failed crate holds %v", crate.Data())
+	}
+	crate.Reset()
+	if crate.Err() != nil {
+		t.Error("Reset() - FAIL: crate still failed")
+	}
+	crate.WriteU16(1)
+	if crate.Err() != nil || !bytes.Equal(crate.Data(), []byte{1, 0}) {
+		t.Errorf("Reset() - FAIL: wrote %v after reset", crate.Data())
+	}
+
+	static := lite.NewCrate(2, lite.FlagStatic)
+	if catchPanic[error](func() { static.WriteU32(1) }) == nil {
+		t.Error("CheckWrite() - FAIL: did not panic without FlagNoPanic")
+	}
+}
+
+func TestNoPanicStringTable(t *testing.T) {
+	batch := logBatch{Entries: []logEntry{{Level: "INFO", Service: "auth", Message: "ok"}}}
+	crate := lite.NewCrate(3, lite.FlagStatic|lite.FlagNoPanic)
+	crate.WriteU8(7)
+	if catchPanic[error](func() { crate.UseWithStringTable(&batch, lite.Write) }) != nil {
+		t.Fatal("UseWithStringTable(Write) - FAIL: failed write panicked")
+	}
+	if crate.Err() == nil || crate.Data()[0] != 7 {
+		t.Errorf("UseWithStringTable(Write) - FAIL: error %v, data %v", crate.Err(), crate.Data())
+	}
+
+	// The value fits but the table inserted before it does not
+	fits := lite.NewCrate(1, lite.FlagAutoDouble)
+	fits.UseWithStringTable(&batch, lite.Write)
+	crate = lite.NewCrate(fits.WriteIndex()-1, lite.FlagStatic|lite.FlagNoPanic)
+	if catchPanic[error](func() { crate.UseWithStringTable(&batch, lite.Write) }) != nil || crate.Err() == nil {
+		t.Errorf("UseWithStringTable(Write) - FAIL: failed table insert gave %v", crate.Err())
+	}
+}
+
+// Returns a crate that failed under FlagNoPanic after writing 7, with scratch bytes in its sink
+func failedCrate() *lite.Crate {
+	crate := lite.NewCrate(1, lite.FlagStatic|lite.FlagNoPanic)
+	crate.WriteU8(7)
+	crate.WriteU64(1)
+	return crate
+}
+
+func TestNoPanicData(t *testing.T) {
+	crate := failedCrate()
+	if crate.Err() == nil || !bytes.Equal(crate.Data(), []byte{7}) {
+		t.Fatalf("FlagNoPanic - FAIL: error %v, data %v", crate.Err(), crate.Data())
+	}
+	if crate.Key() != "\x07" {
+		t.Errorf("Key() - FAIL: %q from failed crate", crate.Key())
+	}
+	if !bytes.Equal(crate.DataCopy(), []byte{7}) {
+		t.Errorf("DataCopy() - FAIL: %v from failed crate", crate.DataCopy())
+	}
+	reference := fnv.New64a()
+	reference.Write([]byte{7})
+	if crate.Sum64() != reference.Sum64() {
+		t.Error("Sum64() - FAIL: hashed scratch space of failed crate")
+	}
+	want := sha256.Sum256([]byte{7})
+	if sum := crate.Hash(sha256.New()); !bytes.Equal(sum, want[:]) {
+		t.Error("Hash() - FAIL: hashed scratch space of failed crate")
+	}
+}
+
+func TestFrameNoPanic(t *testing.T) {
+	crate := failedCrate()
+	var conn bytes.Buffer
+	if err := (&lite.Framer{}).WriteFrame(&conn, crate); err != crate.Err() || conn.Len() != 0 {
+		t.Errorf("Framer.WriteFrame() - FAIL: got %v and wrote %v for failed crate", err, conn.Bytes())
+	}
+	coalescer := &lite.Coalescer{Conn: &conn}
+	if err := coalescer.WriteFrame(crate); err != crate.Err() {
+		t.Errorf("Coalescer.WriteFrame() - FAIL: got %v for failed crate", err)
+	}
+	if coalescer.Flush(); conn.Len() != 0 || coalescer.Stats().Frames != 0 {
+		t.Errorf("Coalescer.WriteFrame() - FAIL: wrote %v for failed crate", conn.Bytes())
+	}
+}
@@ -0,0 +1,66 @@
+package litecrate
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// Any integer or floating point type, including named types such as `type Celsius float32`
+type Number interface {
+	integer | ~float32 | ~float64
+}
+
+/**************
+	NUMBER
+***************/
+
+// Use the number pointed to by val according to mode with the method matching its type:
+// int8 uses UseI8(), uint16 uses UseU16(), float32 uses UseF32() and so on, while int, uint and uintptr
+// always use the 8 byte UseI64() or UseU64() so their encoding does not depend on the platform.
+// Named types use the method for their underlying type.
+//
+// Write = 'write val into crate', Read = 'read from crate into val',
+// Peek = 'read from crate into val without advancing index'
+// Slice = 'Return the slice the next unread val occupies without altering val'
+//
+// Example:
+//
+//	UseNumber(crate, mode, &player.Health) // uint16 health is written with UseU16()
+func UseNumber[T Number](crate *Crate, mode UseMode, val *T) (sliceModeData []byte) {
+	ptr := unsafe.Pointer(val)
+	switch reflect.TypeOf(val).Elem().Kind() {
+	case reflect.Uint8:
+		return crate.UseU8((*uint8)(ptr), mode)
+	case reflect.Int8:
+		return crate.UseI8((*int8)(ptr), mode)
+	case reflect.Uint16:
+		return crate.UseU16((*uint16)(ptr), mode)
+	case reflect.Int16:
+		return crate.UseI16((*int16)(ptr), mode)
+	case reflect.Uint32:
+		return crate.UseU32((*uint32)(ptr), mode)
+	case reflect.Int32:
+		return crate.UseI32((*int32)(ptr), mode)
+	case reflect.Float32:
+		return crate.UseF32((*float32)(ptr), mode)
+	case reflect.Float64:
+		return crate.UseF64((*float64)(ptr), mode)
+	case reflect.Int64:
+		return crate.UseI64((*int64)(ptr), mode)
+	case reflect.Uint64:
+		return crate.UseU64((*uint64)(ptr), mode)
+	case reflect.Int:
+		wide := int64(*val)
+		sliceModeData = crate.UseI64(&wide, mode)
+		if mode == Read || mode == Peek || mode >= firstCustomMode {
+			*val = T(wide)
+		}
+	default: // reflect.Uint, reflect.Uintptr
+		wide := uint64(*val)
+		sliceModeData = crate.UseU64(&wide, mode)
+		if mode == Read || mode == Peek || mode >= firstCustomMode {
+			*val = T(wide)
+		}
+	}
+	return sliceModeData
+}
@@ -0,0 +1,58 @@
+package litecrate_test
+
+import (
+	"math"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+type numberCelsius float32
+
+type numberID uint
+
+func numberRoundTrip[T lite.Number](t *testing.T, val T, size uint64) {
+	crate := lite.NewCrate(8, lite.FlagAutoDouble)
+	lite.UseNumber(crate, lite.Write, &val)
+	var peeked, read T
+	lite.UseNumber(crate, lite.Peek, &peeked)
+	slice := lite.UseNumber(crate, lite.Slice, &read)
+	lite.UseNumber(crate, lite.Read, &read)
+	if read != val || peeked != val || crate.WriteIndex() != size || uint64(len(slice)) != size {
+		t.Errorf("UseNumber(%T %v) - FAIL: read %v, peeked %v, wrote %d bytes (expected %d)", val, val, read, peeked, crate.WriteIndex(), size)
+	}
+	crate.ResetReadIndex()
+	lite.UseNumber(crate, lite.Discard, &read)
+	if crate.ReadsLeft() != 0 {
+		t.Errorf("UseNumber(%T) - FAIL: Discard left %d bytes", val, crate.ReadsLeft())
+	}
+}
+
+func TestUseNumber(t *testing.T) {
+	numberRoundTrip(t, uint8(200), 1)
+	numberRoundTrip(t, int8(-100), 1)
+	numberRoundTrip(t, uint16(60000), 2)
+	numberRoundTrip(t, int16(-30000), 2)
+	numberRoundTrip(t, uint32(4000000000), 4)
+	numberRoundTrip(t, int32(-2000000000), 4)
+	numberRoundTrip(t, uint64(math.MaxUint64), 8)
+	numberRoundTrip(t, int64(math.MinInt64), 8)
+	numberRoundTrip(t, int(-5), 8)
+	numberRoundTrip(t, uint(5), 8)
+	numberRoundTrip(t, uintptr(5), 8)
+	numberRoundTrip(t, float32(-1.5), 4)
+	numberRoundTrip(t, math.Pi, 8)
+	numberRoundTrip(t, numberCelsius(36.6), 4)
+	numberRoundTrip(t, numberID(123456789), 8)
+
+	crate := lite.NewCrate(8, lite.FlagAutoDouble)
+	var u16 uint16 = 0x0102
+	lite.UseNumber(crate, lite.Write, &u16)
+	if crate.ReadU16() != 0x0102 {
+		t.Errorf("UseNumber(uint16) - FAIL: not encoded as U16")
+	}
+	var id numberID
+	if catchPanic[error](func() { lite.UseNumber(crate, lite.UseMode(255), &id) }) == nil {
+		t.Errorf("UseNumber - FAIL: invalid mode did not panic")
+	}
+}
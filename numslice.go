@@ -0,0 +1,589 @@
+package litecrate
+
+import "unsafe"
+
+// Element types of the slices written and read in bulk by Write____Slice() and Read____Slice()
+type fixedNumber interface {
+	~int8 | ~uint16 | ~int16 | ~uint32 | ~int32 | ~uint64 | ~int64 | ~float32 | ~float64
+}
+
+// Whether this machine stores numbers little-endian (like crates do), so slices can be copied without swapping bytes
+var hostLittleEndian = func() bool {
+	one := uint16(1)
+	return *(*byte)(unsafe.Pointer(&one)) == 1
+}()
+
+// The slices written by these methods are encoded exactly like UseSlice() with the matching Use____() element function
+// (a length-or-nil counter followed by each element), but are copied in one block instead of element by element.
+// Slices of uint8 are UseBytesWithCounter()
+
+/**************
+	INT8 SLICE
+***************/
+
+// Discard next []int8 with preceding length-or-nil counter in crate
+func (c *Crate) DiscardI8Slice() {
+	discardNumberSlice[int8](c)
+}
+
+// Return byte slice the next unread []int8 with length-or-nil counter occupies (not including counter)
+func (c *Crate) SliceI8Slice() (slice []byte) {
+	return sliceNumberSlice[int8](c)
+}
+
+// Write []int8 to crate with preceding length-or-nil counter
+func (c *Crate) WriteI8Slice(val []int8) {
+	writeNumberSlice(c, val)
+}
+
+// Read next []int8 with preceding length-or-nil counter from crate
+func (c *Crate) ReadI8Slice() (val []int8) {
+	return readNumberSlice[int8](c)
+}
+
+// Read next []int8 with preceding length-or-nil counter from crate without advancing read index
+func (c *Crate) PeekI8Slice() (val []int8) {
+	idx := c.read
+	val = c.ReadI8Slice()
+	c.read = idx
+	return val
+}
+
+// Use the []int8 pointed to by val according to mode:
+// Write = 'write val into crate', Read = 'read from crate into val',
+// Peek = 'read from crate into val without advancing index'
+// Slice = 'Return the slice the next unread val occupies without altering val (not including counter)'
+func (c *Crate) UseI8Slice(val *[]int8, mode UseMode) (sliceModeData []byte) {
+	switch mode {
+	case Write:
+		c.WriteI8Slice(*val)
+	case Read:
+		*val = c.ReadI8Slice()
+	case Peek:
+		*val = c.PeekI8Slice()
+	case Discard:
+		c.DiscardI8Slice()
+	case Slice:
+		sliceModeData = c.SliceI8Slice()
+	default:
+		c.useCustomMode(val, mode, "UseI8Slice")
+	}
+	return sliceModeData
+}
+
+/**************
+	UINT16 SLICE
+***************/
+
+// Discard next []uint16 with preceding length-or-nil counter in crate
+func (c *Crate) DiscardU16Slice() {
+	discardNumberSlice[uint16](c)
+}
+
+// Return byte slice the next unread []uint16 with length-or-nil counter occupies (not including counter)
+func (c *Crate) SliceU16Slice() (slice []byte) {
+	return sliceNumberSlice[uint16](c)
+}
+
+// Write []uint16 to crate with preceding length-or-nil counter
+func (c *Crate) WriteU16Slice(val []uint16) {
+	writeNumberSlice(c, val)
+}
+
+// Read next []uint16 with preceding length-or-nil counter from crate
+func (c *Crate) ReadU16Slice() (val []uint16) {
+	return readNumberSlice[uint16](c)
+}
+
+// Read next []uint16 with preceding length-or-nil counter from crate without advancing read index
+func (c *Crate) PeekU16Slice() (val []uint16) {
+	idx := c.read
+	val = c.ReadU16Slice()
+	c.read = idx
+	return val
+}
+
+// Use the []uint16 pointed to by val according to mode:
+// Write = 'write val into crate', Read = 'read from crate into val',
+// Peek = 'read from crate into val without advancing index'
+// Slice = 'Return the slice the next unread val occupies without altering val (not including counter)'
+func (c *Crate) UseU16Slice(val *[]uint16, mode UseMode) (sliceModeData []byte) {
+	switch mode {
+	case Write:
+		c.WriteU16Slice(*val)
+	case Read:
+		*val = c.ReadU16Slice()
+	case Peek:
+		*val = c.PeekU16Slice()
+	case Discard:
+		c.DiscardU16Slice()
+	case Slice:
+		sliceModeData = c.SliceU16Slice()
+	default:
+		c.useCustomMode(val, mode, "UseU16Slice")
+	}
+	return sliceModeData
+}
+
+/**************
+	INT16 SLICE
+***************/
+
+// Discard next []int16 with preceding length-or-nil counter in crate
+func (c *Crate) DiscardI16Slice() {
+	discardNumberSlice[int16](c)
+}
+
+// Return byte slice the next unread []int16 with length-or-nil counter occupies (not including counter)
+func (c *Crate) SliceI16Slice() (slice []byte) {
+	return sliceNumberSlice[int16](c)
+}
+
+// Write []int16 to crate with preceding length-or-nil counter
+func (c *Crate) WriteI16Slice(val []int16) {
+	writeNumberSlice(c, val)
+}
+
+// Read next []int16 with preceding length-or-nil counter from crate
+func (c *Crate) ReadI16Slice() (val []int16) {
+	return readNumberSlice[int16](c)
+}
+
+// Read next []int16 with preceding length-or-nil counter from crate without advancing read index
+func (c *Crate) PeekI16Slice() (val []int16) {
+	idx := c.read
+	val = c.ReadI16Slice()
+	c.read = idx
+	return val
+}
+
+// Use the []int16 pointed to by val according to mode:
+// Write = 'write val into crate', Read = 'read from crate into val',
+// Peek = 'read from crate into val without advancing index'
+// Slice = 'Return the slice the next unread val occupies without altering val (not including counter)'
+func (c *Crate) UseI16Slice(val *[]int16, mode UseMode) (sliceModeData []byte) {
+	switch mode {
+	case Write:
+		c.WriteI16Slice(*val)
+	case Read:
+		*val = c.ReadI16Slice()
+	case Peek:
+		*val = c.PeekI16Slice()
+	case Discard:
+		c.DiscardI16Slice()
+	case Slice:
+		sliceModeData = c.SliceI16Slice()
+	default:
+		c.useCustomMode(val, mode, "UseI16Slice")
+	}
+	return sliceModeData
+}
+
+/**************
+	UINT32 SLICE
+***************/
+
+// Discard next []uint32 with preceding length-or-nil counter in crate
+func (c *Crate) DiscardU32Slice() {
+	discardNumberSlice[uint32](c)
+}
+
+// Return byte slice the next unread []uint32 with length-or-nil counter occupies (not including counter)
+func (c *Crate) SliceU32Slice() (slice []byte) {
+	return sliceNumberSlice[uint32](c)
+}
+
+// Write []uint32 to crate with preceding length-or-nil counter
+func (c *Crate) WriteU32Slice(val []uint32) {
+	writeNumberSlice(c, val)
+}
+
+// Read next []uint32 with preceding length-or-nil counter from crate
+func (c *Crate) ReadU32Slice() (val []uint32) {
+	return readNumberSlice[uint32](c)
+}
+
+// Read next []uint32 with preceding length-or-nil counter from crate without advancing read index
+func (c *Crate) PeekU32Slice() (val []uint32) {
+	idx := c.read
+	val = c.ReadU32Slice()
+	c.read = idx
+	return val
+}
+
+// Use the []uint32 pointed to by val according to mode:
+// Write = 'write val into crate', Read = 'read from crate into val',
+// Peek = 'read from crate into val without advancing index'
+// Slice = 'Return the slice the next unread val occupies without altering val (not including counter)'
+func (c *Crate) UseU32Slice(val *[]uint32, mode UseMode) (sliceModeData []byte) {
+	switch mode {
+	case Write:
+		c.WriteU32Slice(*val)
+	case Read:
+		*val = c.ReadU32Slice()
+	case Peek:
+		*val = c.PeekU32Slice()
+	case Discard:
+		c.DiscardU32Slice()
+	case Slice:
+		sliceModeData = c.SliceU32Slice()
+	default:
+		c.useCustomMode(val, mode, "UseU32Slice")
+	}
+	return sliceModeData
+}
+
+/**************
+	INT32 SLICE
+***************/
+
+// Discard next []int32 with preceding length-or-nil counter in crate
+func (c *Crate) DiscardI32Slice() {
+	discardNumberSlice[int32](c)
+}
+
+// Return byte slice the next unread []int32 with length-or-nil counter occupies (not including counter)
+func (c *Crate) SliceI32Slice() (slice []byte) {
+	return sliceNumberSlice[int32](c)
+}
+
+// Write []int32 to crate with preceding length-or-nil counter
+func (c *Crate) WriteI32Slice(val []int32) {
+	writeNumberSlice(c, val)
+}
+
+// Read next []int32 with preceding length-or-nil counter from crate
+func (c *Crate) ReadI32Slice() (val []int32) {
+	return readNumberSlice[int32](c)
+}
+
+// Read next []int32 with preceding length-or-nil counter from crate without advancing read index
+func (c *Crate) PeekI32Slice() (val []int32) {
+	idx := c.read
+	val = c.ReadI32Slice()
+	c.read = idx
+	return val
+}
+
+// Use the []int32 pointed to by val according to mode:
+// Write = 'write val into crate', Read = 'read from crate into val',
+// Peek = 'read from crate into val without advancing index'
+// Slice = 'Return the slice the next unread val occupies without altering val (not including counter)'
+func (c *Crate) UseI32Slice(val *[]int32, mode UseMode) (sliceModeData []byte) {
+	switch mode {
+	case Write:
+		c.WriteI32Slice(*val)
+	case Read:
+		*val = c.ReadI32Slice()
+	case Peek:
+		*val = c.PeekI32Slice()
+	case Discard:
+		c.DiscardI32Slice()
+	case Slice:
+		sliceModeData = c.SliceI32Slice()
+	default:
+		c.useCustomMode(val, mode, "UseI32Slice")
+	}
+	return sliceModeData
+}
+
+/**************
+	UINT64 SLICE
+***************/
+
+// Discard next []uint64 with preceding length-or-nil counter in crate
+func (c *Crate) DiscardU64Slice() {
+	discardNumberSlice[uint64](c)
+}
+
+// Return byte slice the next unread []uint64 with length-or-nil counter occupies (not including counter)
+func (c *Crate) SliceU64Slice() (slice []byte) {
+	return sliceNumberSlice[uint64](c)
+}
+
+// Write []uint64 to crate with preceding length-or-nil counter
+func (c *Crate) WriteU64Slice(val []uint64) {
+	writeNumberSlice(c, val)
+}
+
+// Read next []uint64 with preceding length-or-nil counter from crate
+func (c *Crate) ReadU64Slice() (val []uint64) {
+	return readNumberSlice[uint64](c)
+}
+
+// Read next []uint64 with preceding length-or-nil counter from crate without advancing read index
+func (c *Crate) PeekU64Slice() (val []uint64) {
+	idx := c.read
+	val = c.ReadU64Slice()
+	c.read = idx
+	return val
+}
+
+// Use the []uint64 pointed to by val according to mode:
+// Write = 'write val into crate', Read = 'read from crate into val',
+// Peek = 'read from crate into val without advancing index'
+// Slice = 'Return the slice the next unread val occupies without altering val (not including counter)'
+func (c *Crate) UseU64Slice(val *[]uint64, mode UseMode) (sliceModeData []byte) {
+	switch mode {
+	case Write:
+		c.WriteU64Slice(*val)
+	case Read:
+		*val = c.ReadU64Slice()
+	case Peek:
+		*val = c.PeekU64Slice()
+	case Discard:
+		c.DiscardU64Slice()
+	case Slice:
+		sliceModeData = c.SliceU64Slice()
+	default:
+		c.useCustomMode(val, mode, "UseU64Slice")
+	}
+	return sliceModeData
+}
+
+/**************
+	INT64 SLICE
+***************/
+
+// Discard next []int64 with preceding length-or-nil counter in crate
+func (c *Crate) DiscardI64Slice() {
+	discardNumberSlice[int64](c)
+}
+
+// Return byte slice the next unread []int64 with length-or-nil counter occupies (not including counter)
+func (c *Crate) SliceI64Slice() (slice []byte) {
+	return sliceNumberSlice[int64](c)
+}
+
+// Write []int64 to crate with preceding length-or-nil counter
+func (c *Crate) WriteI64Slice(val []int64) {
+	writeNumberSlice(c, val)
+}
+
+// Read next []int64 with preceding length-or-nil counter from crate
+func (c *Crate) ReadI64Slice() (val []int64) {
+	return readNumberSlice[int64](c)
+}
+
+// Read next []int64 with preceding length-or-nil counter from crate without advancing read index
+func (c *Crate) PeekI64Slice() (val []int64) {
+	idx := c.read
+	val = c.ReadI64Slice()
+	c.read = idx
+	return val
+}
+
+// Use the []int64 pointed to by val according to mode:
+// Write = 'write val into crate', Read = 'read from crate into val',
+// Peek = 'read from crate into val without advancing index'
+// Slice = 'Return the slice the next unread val occupies without altering val (not including counter)'
+func (c *Crate) UseI64Slice(val *[]int64, mode UseMode) (sliceModeData []byte) {
+	switch mode {
+	case Write:
+		c.WriteI64Slice(*val)
+	case Read:
+		*val = c.ReadI64Slice()
+	case Peek:
+		*val = c.PeekI64Slice()
+	case Discard:
+		c.DiscardI64Slice()
+	case Slice:
+		sliceModeData = c.SliceI64Slice()
+	default:
+		c.useCustomMode(val, mode, "UseI64Slice")
+	}
+	return sliceModeData
+}
+
+/**************
+	FLOAT32 SLICE
+***************/
+
+// Discard next []float32 with preceding length-or-nil counter in crate
+func (c *Crate) DiscardF32Slice() {
+	discardNumberSlice[float32](c)
+}
+
+// Return byte slice the next unread []float32 with length-or-nil counter occupies (not including counter)
+func (c *Crate) SliceF32Slice() (slice []byte) {
+	return sliceNumberSlice[float32](c)
+}
+
+// Write []float32 to crate with preceding length-or-nil counter
+func (c *Crate) WriteF32Slice(val []float32) {
+	writeNumberSlice(c, val)
+}
+
+// Read next []float32 with preceding length-or-nil counter from crate
+func (c *Crate) ReadF32Slice() (val []float32) {
+	return readNumberSlice[float32](c)
+}
+
+// Read next []float32 with preceding length-or-nil counter from crate without advancing read index
+func (c *Crate) PeekF32Slice() (val []float32) {
+	idx := c.read
+	val = c.ReadF32Slice()
+	c.read = idx
+	return val
+}
+
+// Use the []float32 pointed to by val according to mode:
+// Write = 'write val into crate', Read = 'read from crate into val',
+// Peek = 'read from crate into val without advancing index'
+// Slice = 'Return the slice the next unread val occupies without altering val (not including counter)'
+func (c *Crate) UseF32Slice(val *[]float32, mode UseMode) (sliceModeData []byte) {
+	switch mode {
+	case Write:
+		c.WriteF32Slice(*val)
+	case Read:
+		*val = c.ReadF32Slice()
+	case Peek:
+		*val = c.PeekF32Slice()
+	case Discard:
+		c.DiscardF32Slice()
+	case Slice:
+		sliceModeData = c.SliceF32Slice()
+	default:
+		c.useCustomMode(val, mode, "UseF32Slice")
+	}
+	return sliceModeData
+}
+
+/**************
+	FLOAT64 SLICE
+***************/
+
+// Discard next []float64 with preceding length-or-nil counter in crate
+func (c *Crate) DiscardF64Slice() {
+	discardNumberSlice[float64](c)
+}
+
+// Return byte slice the next unread []float64 with length-or-nil counter occupies (not including counter)
+func (c *Crate) SliceF64Slice() (slice []byte) {
+	return sliceNumberSlice[float64](c)
+}
+
+// Write []float64 to crate with preceding length-or-nil counter
+func (c *Crate) WriteF64Slice(val []float64) {
+	writeNumberSlice(c, val)
+}
+
+// Read next []float64 with preceding length-or-nil counter from crate
+func (c *Crate) ReadF64Slice() (val []float64) {
+	return readNumberSlice[float64](c)
+}
+
+// Read next []float64 with preceding length-or-nil counter from crate without advancing read index
+func (c *Crate) PeekF64Slice() (val []float64) {
+	idx := c.read
+	val = c.ReadF64Slice()
+	c.read = idx
+	return val
+}
+
+// Use the []float64 pointed to by val according to mode:
+// Write = 'write val into crate', Read = 'read from crate into val',
+// Peek = 'read from crate into val without advancing index'
+// Slice = 'Return the slice the next unread val occupies without altering val (not including counter)'
+func (c *Crate) UseF64Slice(val *[]float64, mode UseMode) (sliceModeData []byte) {
+	switch mode {
+	case Write:
+		c.WriteF64Slice(*val)
+	case Read:
+		*val = c.ReadF64Slice()
+	case Peek:
+		*val = c.PeekF64Slice()
+	case Discard:
+		c.DiscardF64Slice()
+	case Slice:
+		sliceModeData = c.SliceF64Slice()
+	default:
+		c.useCustomMode(val, mode, "UseF64Slice")
+	}
+	return sliceModeData
+}
+
+/**************
+	NUMBER SLICES
+***************/
+
+func writeNumberSlice[T fixedNumber](c *Crate, val []T) {
+	c.WriteLengthOrNil(len64(val), val == nil)
+	if len(val) == 0 {
+		return
+	}
+	size := len64(val) * uint64(unsafe.Sizeof(val[0]))
+	c.CheckWrite(size)
+	if c.failed != nil {
+		return
+	}
+	copyNumbers(c.data[c.write:c.write+size], unsafe.Slice((*byte)(unsafe.Pointer(&val[0])), size), uint64(unsafe.Sizeof(val[0])))
+	c.write += size
+}
+
+func readNumberSlice[T fixedNumber](c *Crate) (val []T) {
+	length, isNil, n := c.PeekLengthOrNil()
+	if isNil {
+		c.read += n
+		return nil
+	}
+	var zero T
+	width := uint64(unsafe.Sizeof(zero))
+	c.checkAlloc(length, width)
+	size := c.numberSliceSize(length, width, n)
+	if c.failed != nil {
+		return nil
+	}
+	c.read += n
+	val = makeSlice[T](c, length)
+	if length > 0 {
+		copyNumbers(unsafe.Slice((*byte)(unsafe.Pointer(&val[0])), size), c.data[c.read:c.read+size], width)
+	}
+	c.read += size
+	return val
+}
+
+func discardNumberSlice[T fixedNumber](c *Crate) {
+	length, _, _ := c.ReadLengthOrNil()
+	var zero T
+	if width := uint64(unsafe.Sizeof(zero)); length > c.ReadsLeft()/width {
+		c.DiscardN(c.ReadsLeft())
+	} else {
+		c.DiscardN(length * width)
+	}
+}
+
+func sliceNumberSlice[T fixedNumber](c *Crate) (slice []byte) {
+	length, _, n := c.PeekLengthOrNil()
+	var zero T
+	size := c.numberSliceSize(length, uint64(unsafe.Sizeof(zero)), n)
+	if c.failed != nil {
+		return nil
+	}
+	return c.data[c.read+n : c.read+n+size : c.read+n+size]
+}
+
+// Returns the byte size of length elements of width bytes, panicking (or failing a crate flagged with FlagNoPanic)
+// if they (and the n byte counter before them) are not all in the crate
+func (c *Crate) numberSliceSize(length uint64, width uint64, n uint64) (size uint64) {
+	if length > (c.ReadsLeft()-n)/width {
+		c.failCheck(readPastEndError(intStr(length)+" elements of "+intStr(width)+" bytes (unread bytes left in crate: "+intStr(c.ReadsLeft()-n)+")"), 0, false)
+		return 0
+	}
+	size = length * width
+	c.CheckRead(n + size)
+	return size
+}
+
+// Copies numbers of width bytes from src to dst, swapping their byte order if this machine is big-endian
+func copyNumbers(dst []byte, src []byte, width uint64) {
+	if hostLittleEndian || width == 1 {
+		copy(dst, src)
+		return
+	}
+	for i := uint64(0); i < len64(src); i += width {
+		for j := uint64(0); j < width; j += 1 {
+			dst[i+j] = src[i+width-1-j]
+		}
+	}
+}
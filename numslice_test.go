@@ -0,0 +1,112 @@
+package litecrate_test
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+// Checks that the bulk methods for one element type encode exactly like UseSlice() with its element function
+func checkNumberSlice[T any](t *testing.T, name string, val []T, useBulk func(crate *lite.Crate) lite.UseFunc[[]T], useElem func(crate *lite.Crate) lite.UseFunc[T]) {
+	t.Helper()
+	for _, test := range [][]T{val, {}, nil} {
+		bulk := lite.NewCrate(8, lite.FlagAutoDouble)
+		useBulk(bulk)(&test, lite.Write)
+		bulk.WriteU8(42)
+		loop := lite.NewCrate(8, lite.FlagAutoDouble)
+		lite.UseSlice(loop, lite.Write, &test, useElem(loop))
+		loop.WriteU8(42)
+		if !bytes.Equal(bulk.Data(), loop.Data()) {
+			t.Errorf("Write%sSlice() - FAIL: % x != UseSlice() % x", name, bulk.Data(), loop.Data())
+		}
+
+		var peeked, got []T
+		useBulk(bulk)(&peeked, lite.Peek)
+		slice := useBulk(bulk)(&got, lite.Slice)
+		start := bulk.ReadIndex()
+		useBulk(bulk)(&got, lite.Read)
+		if !reflect.DeepEqual(got, test) || !reflect.DeepEqual(peeked, test) {
+			t.Errorf("Read%sSlice() - FAIL: %v != %v (peeked %v)", name, got, test, peeked)
+		}
+		if !bytes.Equal(slice, loop.Data()[bulk.ReadIndex()-start-uint64(len(slice)):bulk.ReadIndex()]) {
+			t.Errorf("Slice%sSlice() - FAIL: % x", name, slice)
+		}
+		bulk.ResetReadIndex()
+		useBulk(bulk)(&got, lite.Discard)
+		if bulk.ReadU8() != 42 {
+			t.Errorf("Discard%sSlice() - FAIL: did not skip whole slice", name)
+		}
+	}
+
+	crate := lite.NewCrate(8, lite.FlagAutoDouble)
+	useBulk(crate)(&val, lite.Write)
+	short := lite.OpenCrate(crate.Data()[:crate.WriteIndex()-1], lite.FlagStatic)
+	var got []T
+	if catchPanic[error](func() { useBulk(short)(&got, lite.Read) }) == nil || catchPanic[error](func() { useBulk(short)(&got, lite.Slice) }) == nil {
+		t.Errorf("Read%sSlice() - FAIL: truncated slice did not panic", name)
+	}
+	short = lite.NewCrate(16, lite.FlagAutoDouble)
+	short.WriteLengthOrNil(1<<62, false)
+	if catchPanic[error](func() { useBulk(short)(&got, lite.Read) }) == nil {
+		t.Errorf("Read%sSlice() - FAIL: huge counter did not panic", name)
+	}
+	short.ResetReadIndex()
+	useBulk(short)(&got, lite.Discard)
+	if short.ReadsLeft() != 0 {
+		t.Errorf("Discard%sSlice() - FAIL: huge counter left %d bytes", name, short.ReadsLeft())
+	}
+	if catchPanic[error](func() { useBulk(crate)(&val, lite.UseMode(255)) }) == nil {
+		t.Errorf("Use%sSlice - FAIL: invalid mode did not panic", name)
+	}
+}
+
+func TestNumberSlices(t *testing.T) {
+	checkNumberSlice(t, "I8", []int8{-128, 0, 127}, func(c *lite.Crate) lite.UseFunc[[]int8] { return c.UseI8Slice }, func(c *lite.Crate) lite.UseFunc[int8] { return c.UseI8 })
+	checkNumberSlice(t, "U16", []uint16{1, 0xABCD, math.MaxUint16}, func(c *lite.Crate) lite.UseFunc[[]uint16] { return c.UseU16Slice }, func(c *lite.Crate) lite.UseFunc[uint16] { return c.UseU16 })
+	checkNumberSlice(t, "I16", []int16{-1, 300, math.MinInt16}, func(c *lite.Crate) lite.UseFunc[[]int16] { return c.UseI16Slice }, func(c *lite.Crate) lite.UseFunc[int16] { return c.UseI16 })
+	checkNumberSlice(t, "U32", []uint32{1, 0xDEADBEEF}, func(c *lite.Crate) lite.UseFunc[[]uint32] { return c.UseU32Slice }, func(c *lite.Crate) lite.UseFunc[uint32] { return c.UseU32 })
+	checkNumberSlice(t, "I32", []int32{-1, math.MaxInt32, math.MinInt32}, func(c *lite.Crate) lite.UseFunc[[]int32] { return c.UseI32Slice }, func(c *lite.Crate) lite.UseFunc[int32] { return c.UseI32 })
+	checkNumberSlice(t, "U64", []uint64{1, 0x0102030405060708, math.MaxUint64}, func(c *lite.Crate) lite.UseFunc[[]uint64] { return c.UseU64Slice }, func(c *lite.Crate) lite.UseFunc[uint64] { return c.UseU64 })
+	checkNumberSlice(t, "I64", []int64{-1, math.MinInt64}, func(c *lite.Crate) lite.UseFunc[[]int64] { return c.UseI64Slice }, func(c *lite.Crate) lite.UseFunc[int64] { return c.UseI64 })
+	checkNumberSlice(t, "F32", []float32{0.5, float32(math.Inf(-1)), -0}, func(c *lite.Crate) lite.UseFunc[[]float32] { return c.UseF32Slice }, func(c *lite.Crate) lite.UseFunc[float32] { return c.UseF32 })
+	checkNumberSlice(t, "F64", []float64{math.Pi, -1e300, math.SmallestNonzeroFloat64}, func(c *lite.Crate) lite.UseFunc[[]float64] { return c.UseF64Slice }, func(c *lite.Crate) lite.UseFunc[float64] { return c.UseF64 })
+
+	crate := lite.NewCrate(8, lite.FlagAutoDouble)
+	crate.WriteF64Slice(make([]float64, 100))
+	crate.SetMaxReadAlloc(799)
+	if catchPanic[error](func() { crate.ReadF64Slice() }) == nil {
+		t.Errorf("ReadF64Slice() - FAIL: MaxReadAlloc not enforced")
+	}
+}
+
+var benchFloats = func() []float64 {
+	floats := make([]float64, 4096)
+	for i := range floats {
+		floats[i] = float64(i) * 1.5
+	}
+	return floats
+}()
+
+func BenchmarkF64SliceBulk(b *testing.B) {
+	crate := lite.NewCrate(uint64(len(benchFloats))*8+8, lite.FlagAutoDouble)
+	b.SetBytes(int64(len(benchFloats)) * 8)
+	for i := 0; i < b.N; i++ {
+		crate.Reset()
+		crate.WriteF64Slice(benchFloats)
+		crate.ReadF64Slice()
+	}
+}
+
+func BenchmarkF64SliceLoop(b *testing.B) {
+	crate := lite.NewCrate(uint64(len(benchFloats))*8+8, lite.FlagAutoDouble)
+	b.SetBytes(int64(len(benchFloats)) * 8)
+	var floats []float64
+	for i := 0; i < b.N; i++ {
+		crate.Reset()
+		lite.UseSlice(crate, lite.Write, &benchFloats, crate.UseF64)
+		lite.UseSlice(crate, lite.Read, &floats, crate.UseF64)
+	}
+}
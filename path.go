@@ -0,0 +1,110 @@
+package litecrate
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Panicked (as *PathError) when using an element of a slice or map, or a field used with UseNamed(), fails,
+// recording where inside the value the failure happened so a bad element deep inside a large collection
+// can be found. Failures inside nested collections extend the same error's Path instead of wrapping it again
+type PathError struct {
+	Path string // Location of the failed value, such as `children[3].phone["Mom"]`
+	Err  error  // The original failure (panics with a string message are converted with errors.New())
+}
+
+func (e *PathError) Error() string {
+	return "LiteCrate: at " + e.Path + ": " + strings.TrimPrefix(e.Err.Error(), "LiteCrate: ")
+}
+
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
+
+/**************
+	PATHS
+***************/
+
+// Use a value (usually a struct field) according to mode, naming it in the path of any *PathError
+// panicked while using it. Nothing extra is written: the name only appears in error messages.
+//
+// Example:
+//
+//	func (p *Person) UseSelf(crate *Crate, mode UseMode) {
+//		crate.UseNamed("phone", func(mode UseMode) { UseMap(crate, mode, &p.Phone, crate.UseStringWithCounter, crate.UseStringWithCounter) }, mode)
+//	}
+func (c *Crate) UseNamed(name string, useValue func(mode UseMode), mode UseMode) {
+	at := elementPath{name: name, active: true}
+	defer at.catch()
+	useValue(mode)
+}
+
+// Tracks which element of a collection is being used, so a panic while using it can be
+// re-panicked as a *PathError naming the element
+type elementPath struct {
+	name   string // Field name, used instead of index when not empty
+	index  uint64 // Index of the slice element or map entry being used
+	key    any    // Pointer to the key of the map entry being used, nil for slices
+	keyOK  bool   // Whether *key holds the entry's key (false while the key itself is being read)
+	active bool   // Whether an element is being used, panics outside elements are left alone
+}
+
+// Must be deferred directly, as recover() only stops a panic when called by the deferred function itself
+func (p *elementPath) catch() {
+	if !p.active {
+		return
+	}
+	if r := recover(); r != nil {
+		panic(wrapPath(r, p.segment()))
+	}
+}
+
+func (p *elementPath) segment() string {
+	switch {
+	case p.name != "":
+		return p.name
+	case p.key == nil:
+		return "[" + intStr(p.index) + "]"
+	case !p.keyOK:
+		return "[#" + intStr(p.index) + "]"
+	}
+	key := reflect.ValueOf(p.key).Elem()
+	switch key.Kind() {
+	case reflect.String:
+		return "[" + strconv.Quote(key.String()) + "]"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "[" + strconv.FormatInt(key.Int(), 10) + "]"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return "[" + strconv.FormatUint(key.Uint(), 10) + "]"
+	case reflect.Bool:
+		return "[" + strconv.FormatBool(key.Bool()) + "]"
+	case reflect.Float32, reflect.Float64:
+		return "[" + strconv.FormatFloat(key.Float(), 'g', -1, 64) + "]"
+	}
+	// Other keys have no short literal form, so the entry's position is used instead
+	return "[#" + intStr(p.index) + "]"
+}
+
+// Returns r as a *PathError with segment prepended to its path, or r unchanged if it is neither a string nor an error
+func wrapPath(r any, segment string) any {
+	switch r := r.(type) {
+	case *PathError:
+		r.Path = joinPath(segment, r.Path)
+		return r
+	case error:
+		return &PathError{Path: segment, Err: r}
+	case string:
+		return &PathError{Path: segment, Err: errors.New(r)}
+	}
+	return r
+}
+
+// Joins path segments, separating field names from whatever comes before them with '.'
+func joinPath(outer string, inner string) string {
+	if strings.HasPrefix(inner, "[") {
+		return outer + inner
+	}
+	return outer + "." + inner
+}
@@ -0,0 +1,115 @@
+package litecrate_test
+
+import (
+	"errors"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+type pathChild struct {
+	Name  string
+	Phone map[string]uint8
+}
+
+func (c *pathChild) UseSelf(crate *lite.Crate, mode lite.UseMode) {
+	crate.UseNamed("name", func(mode lite.UseMode) { crate.UseStringWithCounter(&c.Name, mode) }, mode)
+	crate.UseNamed("phone", func(mode lite.UseMode) {
+		lite.UseMap(crate, mode, &c.Phone, crate.UseStringWithCounter, crate.UseU8)
+	}, mode)
+}
+
+type pathParent struct {
+	Children []pathChild
+}
+
+func (p *pathParent) UseSelf(crate *lite.Crate, mode lite.UseMode) {
+	crate.UseNamed("children", func(mode lite.UseMode) {
+		lite.UseSlice(crate, mode, &p.Children, func(child *pathChild, mode lite.UseMode) []byte {
+			return crate.UseSelfSerializer(child, mode)
+		})
+	}, mode)
+}
+
+func TestPathErrorNested(t *testing.T) {
+	parent := pathParent{Children: []pathChild{
+		{Name: "a", Phone: map[string]uint8{"Dad": 1}},
+		{Name: "b", Phone: map[string]uint8{"Mom": 2}},
+	}}
+	crate := lite.NewCrate(64, lite.FlagAutoDouble)
+	crate.WriteSelfSerializer(&parent)
+	// Drop the last byte, the value of "Mom"
+	truncated := lite.OpenCrate(crate.Data()[:crate.Len()-1], lite.FlagDefault)
+	var read pathParent
+	err := catchPanic[*lite.PathError](func() { truncated.ReadSelfSerializer(&read) })
+	if err == nil {
+		t.Fatalf("UseSlice() - FAIL: truncated crate did not panic with a *PathError")
+	}
+	if want := `children[1].phone["Mom"]`; err.Path != want {
+		t.Errorf("UseSlice() - FAIL: path %s, want %s", err.Path, want)
+	}
+	if want := "LiteCrate: at " + err.Path + ": " + err.Err.Error()[len("LiteCrate: "):]; err.Error() != want {
+		t.Errorf("PathError.Error() - FAIL: %q, want %q", err.Error(), want)
+	}
+}
+
+func TestPathErrorIndexes(t *testing.T) {
+	crate := lite.NewCrate(64, lite.FlagAutoDouble)
+	slice := [][]uint16{{1, 2}, {3, 4, 5}}
+	lite.UseSlice(crate, lite.Write, &slice, func(elem *[]uint16, mode lite.UseMode) []byte {
+		return lite.UseSlice(crate, mode, elem, crate.UseU16)
+	})
+	truncated := lite.OpenCrate(crate.Data()[:crate.Len()-1], lite.FlagDefault)
+	err := catchPanic[*lite.PathError](func() {
+		var read [][]uint16
+		lite.UseSlice(truncated, lite.Read, &read, func(elem *[]uint16, mode lite.UseMode) []byte {
+			return lite.UseSlice(truncated, mode, elem, truncated.UseU16)
+		})
+	})
+	if err == nil || err.Path != "[1][2]" {
+		t.Errorf("UseSlice() - FAIL: truncated nested slice panicked with %v, want path [1][2]", err)
+	}
+}
+
+func TestPathErrorMapKeys(t *testing.T) {
+	crate := lite.NewCrate(64, lite.FlagAutoDouble)
+	m := map[uint16]string{7: "seven"}
+	lite.UseMap(crate, lite.Write, &m, crate.UseU16, crate.UseStringWithCounter)
+	valueCut := lite.OpenCrate(crate.Data()[:crate.Len()-1], lite.FlagDefault)
+	if err := catchPanic[*lite.PathError](func() { lite.UseMap(valueCut, lite.Read, &m, valueCut.UseU16, valueCut.UseStringWithCounter) }); err == nil || err.Path != "[7]" {
+		t.Errorf("UseMap() - FAIL: truncated value panicked with %v, want path [7]", err)
+	}
+	// The key itself is cut short, so the entry can only be named by its position
+	keyCut := lite.OpenCrate(crate.Data()[:2], lite.FlagDefault)
+	if err := catchPanic[*lite.PathError](func() { lite.UseMap(keyCut, lite.Read, &m, keyCut.UseU16, keyCut.UseStringWithCounter) }); err == nil || err.Path != "[#0]" {
+		t.Errorf("UseMap() - FAIL: truncated key panicked with %v, want path [#0]", err)
+	}
+	sorted := lite.NewCrate(64, lite.FlagAutoDouble|lite.FlagSortedMaps)
+	err := catchPanic[*lite.PathError](func() {
+		lite.UseMap(sorted, lite.Write, &m, sorted.UseU16, func(val *string, mode lite.UseMode) []byte {
+			panic("LiteCrate: bad value")
+		})
+	})
+	if err == nil || err.Path != "[7]" || err.Err.Error() != "LiteCrate: bad value" {
+		t.Errorf("UseMap() - FAIL: sorted write panicked with %v, want path [7]", err)
+	}
+}
+
+func TestPathErrorUnwrap(t *testing.T) {
+	crate := lite.NewCrate(16, lite.FlagAutoDouble)
+	slice := [][]byte{make([]byte, 100)}
+	lite.UseSlice(crate, lite.Write, &slice, crate.UseBytesWithCounter)
+	crate.SetMaxReadAlloc(10)
+	err := catchPanic[*lite.PathError](func() { lite.UseSlice(crate, lite.Read, &slice, crate.UseBytesWithCounter) })
+	var allocErr *lite.AllocError
+	if err == nil || err.Path != "[0]" || !errors.As(err, &allocErr) {
+		t.Errorf("UseSlice() - FAIL: panicked with %v, want *AllocError at [0]", err)
+	}
+	// Panics before the first element are not wrapped
+	crate.ResetReadIndex()
+	crate.SetMaxReadAlloc(0)
+	crate.SetMaxDepth(0)
+	if err := catchPanic[*lite.PathError](func() { lite.UseSlice(crate, lite.UseMode(255), &slice, crate.UseBytesWithCounter) }); err != nil {
+		t.Errorf("UseSlice() - FAIL: invalid mode panicked with path %s", err.Path)
+	}
+}
@@ -0,0 +1,172 @@
+package litecrate
+
+import (
+	"math/bits"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	sizeHistogramWindow = 128 // how many recent final sizes each GetLabeled() label remembers
+	defaultCrateSize    = 64  // capacity of crates from GetLabeled() and NewCrateFor() when no better size is known
+)
+
+/**************
+	POOL
+***************/
+
+// A CratePool recycles crates so that high-throughput users
+// don't allocate a new crate (and buffer) for every message.
+// The zero value is ready to use, and all methods are safe to call concurrently.
+//
+// Crates retrieved from the pool must not be used after being returned with Put(),
+// and slices returned by their Data() or Slice methods are invalidated when they are returned
+type CratePool struct {
+	pool      sync.Pool
+	gets      uint64
+	hits      uint64
+	sizeMutex sync.Mutex
+	sizes     map[string]*sizeHistogram
+}
+
+// Counters describing how well a CratePool is recycling crates
+type CratePoolStats struct {
+	Gets uint64 // Total calls to Get()
+	Hits uint64 // Calls to Get() satisfied by a pooled crate without allocating a new buffer
+}
+
+// Returns Hits / Gets, or 0 if Get() has never been called
+func (s CratePoolStats) HitRate() float64 {
+	if s.Gets == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Gets)
+}
+
+// Create a new, empty CratePool
+func NewCratePool() *CratePool {
+	return &CratePool{}
+}
+
+// Get an empty crate with at least size bytes of capacity and the specified option flags,
+// recycling a crate from the pool if one is available
+func (p *CratePool) Get(size uint64, flags uint8) *Crate {
+	atomic.AddUint64(&p.gets, 1)
+	crate, _ := p.pool.Get().(*Crate)
+	if crate == nil {
+		return NewCrate(size, flags)
+	}
+	crate.flags = flags
+	if len64(crate.data) < size {
+		crate.data = make([]byte, size)
+		return crate
+	}
+	atomic.AddUint64(&p.hits, 1)
+	return crate
+}
+
+// Reset crate and return it to the pool for future Get() calls
+func (p *CratePool) Put(crate *Crate) {
+	crate.recycle()
+	p.pool.Put(crate)
+}
+
+// Returns a snapshot of the pool's hit rate counters
+func (p *CratePool) Stats() CratePoolStats {
+	return CratePoolStats{
+		Gets: atomic.LoadUint64(&p.gets),
+		Hits: atomic.LoadUint64(&p.hits),
+	}
+}
+
+// Get an empty crate for the call site identified by label, pre-sized to the 90th percentile of the
+// final sizes of the last 128 crates returned for label with PutLabeled(), so that in steady state
+// most crates never need to grow
+func (p *CratePool) GetLabeled(label string, flags uint8) *Crate {
+	size := uint64(defaultCrateSize)
+	p.sizeMutex.Lock()
+	if hist := p.sizes[label]; hist != nil {
+		size = hist.percentile(90)
+	}
+	p.sizeMutex.Unlock()
+	return p.Get(size, flags)
+}
+
+// Record the final size of crate (its write index) for label, then return it to the pool with Put()
+func (p *CratePool) PutLabeled(label string, crate *Crate) {
+	p.sizeMutex.Lock()
+	if p.sizes == nil {
+		p.sizes = make(map[string]*sizeHistogram)
+	}
+	hist := p.sizes[label]
+	if hist == nil {
+		hist = &sizeHistogram{}
+		p.sizes[label] = hist
+	}
+	hist.add(len64(crate.Data()))
+	p.sizeMutex.Unlock()
+	p.Put(crate)
+}
+
+// Returns the size GetLabeled() currently uses for each label that has been passed to PutLabeled()
+func (p *CratePool) LearnedSizes() map[string]uint64 {
+	p.sizeMutex.Lock()
+	defer p.sizeMutex.Unlock()
+	sizes := make(map[string]uint64, len(p.sizes))
+	for label, hist := range p.sizes {
+		sizes[label] = hist.percentile(90)
+	}
+	return sizes
+}
+
+// Counts the most recent sizes in power of two buckets: bucket n holds sizes
+// that need n bits, so its upper bound is (1<<n)-1
+type sizeHistogram struct {
+	recent  [sizeHistogramWindow]uint8
+	next    int
+	count   int
+	buckets [65]int
+}
+
+func (h *sizeHistogram) add(size uint64) {
+	if h.count == sizeHistogramWindow {
+		h.buckets[h.recent[h.next]] -= 1
+	} else {
+		h.count += 1
+	}
+	bucket := uint8(bits.Len64(size))
+	h.recent[h.next] = bucket
+	h.buckets[bucket] += 1
+	h.next = (h.next + 1) % sizeHistogramWindow
+}
+
+// Returns the upper bound of the bucket holding the pct percentile size
+func (h *sizeHistogram) percentile(pct int) uint64 {
+	want := (h.count*pct + 99) / 100
+	seen := 0
+	for bucket, n := range h.buckets {
+		seen += n
+		if seen >= want {
+			return 1<<bucket - 1
+		}
+	}
+	return 1<<64 - 1
+}
+
+// Reset() the crate and return everything else set on it (resolvers, limits, string tables, watchdogs,
+// trace entries...) to its zero value, so its next user gets a crate indistinguishable from a new one.
+// Only the buffer and scratch space are kept, so fields added to Crate are cleared without being listed here.
+// The generation is kept too, so SliceRefs from before the crate was recycled stay invalid
+func (c *Crate) recycle() {
+	c.Reset()
+	*c = Crate{
+		data:     c.data,
+		gen:      c.gen,
+		writeTx:  c.writeTx,
+		readTx:   c.readTx,
+		sections: c.sections,
+		limits:   c.limits,
+		trace:    c.trace[:0],
+		sorter:   c.sorter,
+	}
+}
@@ -0,0 +1,104 @@
+package litecrate_test
+
+import (
+	"sync"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+func TestCratePool(t *testing.T) {
+	pool := lite.NewCratePool()
+	if pool.Stats().HitRate() != 0 {
+		t.Errorf("CratePool.Stats() - FAIL: hit rate of unused pool != 0")
+	}
+	for i := 0; i < 100; i += 1 {
+		crate := pool.Get(64, lite.FlagStatic)
+		if crate.WriteIndex() != 0 || crate.ReadIndex() != 0 || crate.SpaceLeft() < 64 || crate.WillAutoGrow() {
+			t.Fatalf("CratePool.Get() - FAIL: crate not reset (write %d, read %d, space %d)", crate.WriteIndex(), crate.ReadIndex(), crate.SpaceLeft())
+		}
+		crate.WriteU64(uint64(i))
+		crate.ReadU8()
+		pool.Put(crate)
+	}
+	stats := pool.Stats()
+	if stats.Gets != 100 || stats.Hits == 0 || stats.Hits > 99 {
+		t.Errorf("CratePool.Stats() - FAIL: %d gets, %d hits", stats.Gets, stats.Hits)
+	}
+
+	crate := pool.Get(4096, lite.FlagAutoDouble)
+	if crate.SpaceLeft() < 4096 || !crate.WillAutoGrow() {
+		t.Errorf("CratePool.Get() - FAIL: larger request got %d bytes", crate.SpaceLeft())
+	}
+	pool.Put(crate)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g += 1 {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i += 1 {
+				crate := pool.Get(8, lite.FlagAutoDouble)
+				crate.WriteStringWithCounter("concurrent")
+				if crate.ReadStringWithCounter() != "concurrent" {
+					t.Errorf("CratePool - FAIL: crate shared between goroutines")
+				}
+				pool.Put(crate)
+			}
+		}(g)
+	}
+	wg.Wait()
+	if stats := pool.Stats(); stats.Gets != 901 || stats.HitRate() > 1 {
+		t.Errorf("CratePool.Stats() - FAIL: %d gets, hit rate %f", stats.Gets, stats.HitRate())
+	}
+}
+
+func TestCratePoolLabeled(t *testing.T) {
+	pool := lite.NewCratePool()
+	if crate := pool.GetLabeled("new", lite.FlagAutoDouble); crate.SpaceLeft() < 64 {
+		t.Errorf("CratePool.GetLabeled() - FAIL: unlearned label got %d bytes", crate.SpaceLeft())
+	}
+	for i := 0; i < 300; i += 1 {
+		crate := pool.GetLabeled("small", lite.FlagAutoDouble)
+		crate.WriteBytes(make([]byte, 100+i%20))
+		pool.PutLabeled("small", crate)
+		crate = pool.GetLabeled("large", lite.FlagAutoDouble)
+		size := 3000
+		if i%20 == 0 {
+			size = 100000
+		}
+		crate.WriteBytes(make([]byte, size))
+		pool.PutLabeled("large", crate)
+	}
+	sizes := pool.LearnedSizes()
+	if len(sizes) != 2 || sizes["small"] < 119 || sizes["small"] > 255 || sizes["large"] < 3000 || sizes["large"] > 4095 {
+		t.Errorf("CratePool.LearnedSizes() - FAIL: %v", sizes)
+	}
+	if crate := pool.GetLabeled("large", lite.FlagStatic); crate.SpaceLeft() < sizes["large"] {
+		t.Errorf("CratePool.GetLabeled() - FAIL: got %d bytes, learned %d", crate.SpaceLeft(), sizes["large"])
+	}
+	for i := 0; i < 128; i += 1 {
+		crate := pool.GetLabeled("small", lite.FlagAutoDouble)
+		crate.WriteBytes(make([]byte, 5000))
+		pool.PutLabeled("small", crate)
+	}
+	if size := pool.LearnedSizes()["small"]; size < 5000 {
+		t.Errorf("CratePool.LearnedSizes() - FAIL: old sizes not forgotten, learned %d", size)
+	}
+}
+
+func TestCratePoolRecycle(t *testing.T) {
+	pool := lite.NewCratePool()
+	crate := pool.Get(8, lite.FlagAutoDouble|lite.FlagTrace)
+	crate.SetStringTable(lite.NewStringTable())
+	crate.SetMaxDepth(1)
+	crate.WriteBytes(make([]byte, 64))
+	ref := crate.TrackSlice(crate.Data())
+	pool.Put(crate)
+	// The pool may drop crates at any time, so only check a crate it hands back
+	if again := pool.Get(8, lite.FlagAutoDouble|lite.FlagTrace); again == crate {
+		if len(crate.Trace()) != 0 || crate.StringTable() != nil || crate.MaxDepth() != 0 || crate.GrowCount() != 0 || ref.Valid() {
+			t.Errorf("CratePool.Put() - FAIL: recycled crate kept %d trace entries, string table %v, max depth %d", len(crate.Trace()), crate.StringTable(), crate.MaxDepth())
+		}
+	}
+}
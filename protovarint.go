@@ -0,0 +1,157 @@
+package litecrate
+
+// Most bytes a protobuf (LEB128) varint occupies
+const maxProtoVarintLen = 10
+
+/**************
+	UVARINT PROTO
+***************/
+
+// Returns how many bytes the protobuf varint at the start of the crate's unread data occupies,
+// panics if it is unterminated or longer than 10 bytes
+func (c *Crate) protoVarintLen() (n uint64) {
+	end := c.readEnd()
+	for n = 1; c.read+n <= end; n += 1 {
+		b := c.data[c.read+n-1]
+		if b&continueMask == 0 {
+			if n == maxProtoVarintLen && b > 1 {
+				panic("LiteCrate: protobuf varint overflows uint64")
+			}
+			return n
+		}
+		if n == maxProtoVarintLen {
+			panic("LiteCrate: protobuf varint longer than " + intStr(maxProtoVarintLen) + " bytes")
+		}
+	}
+	c.CheckRead(n)
+	return n
+}
+
+// Discard next 1-10 unread bytes in crate,
+// dependant on size of the UVarintProto
+func (c *Crate) DiscardUVarintProto() (bytesDiscarded uint64) {
+	n := c.protoVarintLen()
+	c.read += n
+	return n
+}
+
+// Return byte slice the next unread UVarintProto (uint64) occupies
+func (c *Crate) SliceUVarintProto() (slice []byte) {
+	n := c.protoVarintLen()
+	return c.data[c.read : c.read+n : c.read+n]
+}
+
+// Write uint64 to crate as a protobuf varint (LEB128): 7 bits per byte, least significant first,
+// with the top bit of every byte but the last set. Uses 1-10 bytes dependant on size of value,
+// unlike UVarint which stops at 9 bytes by using all 8 bits of the 9th byte
+func (c *Crate) WriteUVarintProto(val uint64) (bytesWritten uint64) {
+	n := uint64(1)
+	for v := val >> countShift; v > 0; v >>= countShift {
+		n += 1
+	}
+	c.CheckWrite(n)
+	for ; val > countMask; val >>= countShift {
+		c.data[c.write] = byte(val)&countMask | continueMask
+		c.write += 1
+	}
+	c.data[c.write] = byte(val)
+	c.write += 1
+	return n
+}
+
+// Read next 1-10 bytes from crate as a protobuf varint (LEB128) encoded uint64
+func (c *Crate) ReadUVarintProto() (val uint64, bytesRead uint64) {
+	bytesRead = c.protoVarintLen()
+	for i := uint64(0); i < bytesRead; i += 1 {
+		val |= uint64(c.data[c.read+i]&countMask) << (i * countShift)
+	}
+	c.read += bytesRead
+	return val, bytesRead
+}
+
+// Read next 1-10 bytes from crate as a protobuf varint (LEB128) encoded uint64
+// without advancing read index
+func (c *Crate) PeekUVarintProto() (val uint64, bytesRead uint64) {
+	idx := c.read
+	val, bytesRead = c.ReadUVarintProto()
+	c.read = idx
+	return val, bytesRead
+}
+
+// Use the uint64 pointed to by val as a protobuf varint (LEB128) according to mode:
+// Write = 'write val into crate', Read = 'read from crate into val',
+// Peek = 'read from crate into val without advancing index'
+// Slice = 'Return the slice the next unread val occupies without altering val'
+func (c *Crate) UseUVarintProto(val *uint64, mode UseMode) (bytesUsed uint64, sliceModeData []byte) {
+	switch mode {
+	case Write:
+		bytesUsed = c.WriteUVarintProto(*val)
+	case Read:
+		*val, bytesUsed = c.ReadUVarintProto()
+	case Peek:
+		*val, bytesUsed = c.PeekUVarintProto()
+	case Discard:
+		bytesUsed = c.DiscardUVarintProto()
+	case Slice:
+		sliceModeData = c.SliceUVarintProto()
+	default:
+		c.useCustomMode(val, mode, "UseUVarintProto")
+	}
+	return bytesUsed, sliceModeData
+}
+
+/**************
+	VARINT PROTO
+***************/
+
+// Discard next 1-10 unread bytes in crate,
+// dependant on size of the VarintProto
+func (c *Crate) DiscardVarintProto() (bytesDiscarded uint64) {
+	return c.DiscardUVarintProto()
+}
+
+// Return byte slice the next unread VarintProto (int64) occupies
+func (c *Crate) SliceVarintProto() (slice []byte) {
+	return c.SliceUVarintProto()
+}
+
+// Write int64 to crate as a zig-zag protobuf varint (the encoding of protobuf's sint64).
+// Uses 1-10 bytes dependant on size of value
+func (c *Crate) WriteVarintProto(val int64) (bytesWritten uint64) {
+	return c.WriteUVarintProto(zigZagEncode(val))
+}
+
+// Read next 1-10 bytes from crate as a zig-zag protobuf varint encoded int64
+func (c *Crate) ReadVarintProto() (val int64, bytesRead uint64) {
+	uVal, bytesRead := c.ReadUVarintProto()
+	return zigZagDecode(uVal), bytesRead
+}
+
+// Read next 1-10 bytes from crate as a zig-zag protobuf varint encoded int64
+// without advancing read index
+func (c *Crate) PeekVarintProto() (val int64, bytesRead uint64) {
+	uVal, bytesRead := c.PeekUVarintProto()
+	return zigZagDecode(uVal), bytesRead
+}
+
+// Use the int64 pointed to by val as a zig-zag protobuf varint according to mode:
+// Write = 'write val into crate', Read = 'read from crate into val',
+// Peek = 'read from crate into val without advancing index'
+// Slice = 'Return the slice the next unread val occupies without altering val'
+func (c *Crate) UseVarintProto(val *int64, mode UseMode) (bytesUsed uint64, sliceModeData []byte) {
+	switch mode {
+	case Write:
+		bytesUsed = c.WriteVarintProto(*val)
+	case Read:
+		*val, bytesUsed = c.ReadVarintProto()
+	case Peek:
+		*val, bytesUsed = c.PeekVarintProto()
+	case Discard:
+		bytesUsed = c.DiscardVarintProto()
+	case Slice:
+		sliceModeData = c.SliceVarintProto()
+	default:
+		c.useCustomMode(val, mode, "UseVarintProto")
+	}
+	return bytesUsed, sliceModeData
+}
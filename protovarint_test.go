@@ -0,0 +1,80 @@
+package litecrate_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"math/rand"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+func TestUVarintProto(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	values := []uint64{0, 1, 127, 128, 300, 1<<56 - 1, 1 << 56, 1 << 63, math.MaxUint64}
+	for i := 0; i < 1000; i += 1 {
+		values = append(values, random.Uint64()>>random.Intn(64))
+	}
+	for _, val := range values {
+		// encoding/binary's uvarint is the protobuf encoding
+		expected := make([]byte, binary.MaxVarintLen64)
+		expected = expected[:binary.PutUvarint(expected, val)]
+		crate := lite.NewCrate(4, lite.FlagDefault)
+		n := crate.WriteUVarintProto(val)
+		if !bytes.Equal(crate.Data(), expected) || n != uint64(len(expected)) {
+			t.Fatalf("WriteUVarintProto(%d) - FAIL: %x, expected %x", val, crate.Data(), expected)
+		}
+		crate.WriteU8(42)
+		if got, n := crate.PeekUVarintProto(); got != val || n != uint64(len(expected)) || crate.ReadIndex() != 0 {
+			t.Fatalf("PeekUVarintProto(%x) - FAIL: %d", expected, got)
+		}
+		if slice := crate.SliceUVarintProto(); !bytes.Equal(slice, expected) {
+			t.Fatalf("SliceUVarintProto(%x) - FAIL: %x", expected, slice)
+		}
+		var got uint64
+		crate.UseUVarintProto(&got, lite.Read)
+		if got != val || crate.ReadU8() != 42 {
+			t.Fatalf("ReadUVarintProto(%x) - FAIL: %d", expected, got)
+		}
+		crate.ResetReadIndex()
+		if crate.DiscardUVarintProto() != uint64(len(expected)) || crate.ReadU8() != 42 {
+			t.Fatalf("DiscardUVarintProto(%x) - FAIL", expected)
+		}
+	}
+	for _, bad := range [][]byte{
+		{0x80},
+		{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x02},
+		{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x01},
+	} {
+		if catchPanic[error](func() { lite.OpenCrate(bad, lite.FlagStatic).ReadUVarintProto() }) == nil {
+			t.Errorf("ReadUVarintProto(%x) - FAIL: did not panic", bad)
+		}
+	}
+	if catchPanic[error](func() { lite.NewCrate(1, lite.FlagStatic).UseUVarintProto(new(uint64), lite.UseMode(255)) }) == nil {
+		t.Errorf("UseUVarintProto() - FAIL: invalid mode did not panic")
+	}
+}
+
+func TestVarintProto(t *testing.T) {
+	for _, val := range []int64{0, -1, 1, -64, 64, math.MaxInt64, math.MinInt64} {
+		expected := make([]byte, binary.MaxVarintLen64)
+		expected = expected[:binary.PutVarint(expected, val)]
+		crate := lite.NewCrate(4, lite.FlagDefault)
+		crate.UseVarintProto(&val, lite.Write)
+		if !bytes.Equal(crate.Data(), expected) {
+			t.Fatalf("WriteVarintProto(%d) - FAIL: %x, expected %x", val, crate.Data(), expected)
+		}
+		var got int64
+		if crate.UseVarintProto(&got, lite.Peek); got != val || crate.ReadIndex() != 0 {
+			t.Fatalf("PeekVarintProto(%x) - FAIL: %d", expected, got)
+		}
+		if got, _ := crate.ReadVarintProto(); got != val || crate.ReadsLeft() != 0 {
+			t.Fatalf("ReadVarintProto(%x) - FAIL: %d", expected, got)
+		}
+		crate.ResetReadIndex()
+		if _, slice := crate.UseVarintProto(nil, lite.Slice); !bytes.Equal(slice, expected) {
+			t.Fatalf("SliceVarintProto(%x) - FAIL: %x", expected, slice)
+		}
+	}
+}
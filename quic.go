@@ -0,0 +1,100 @@
+//go:build !litecrate_core && !litecrate_nonet
+
+package litecrate
+
+import (
+	"context"
+	"io"
+)
+
+/**************
+	QUIC
+***************/
+
+// The parts of a QUIC connection QUICTransport uses. LiteCrate does not depend on any QUIC library,
+// so wrap the connection from yours (such as a quic-go Connection, whose streams already
+// satisfy io.ReadWriteCloser) in a small adapter with these methods
+type QUICConn interface {
+	OpenStream(ctx context.Context) (io.ReadWriteCloser, error)   // Open a new outgoing stream
+	AcceptStream(ctx context.Context) (io.ReadWriteCloser, error) // Wait for the next incoming stream
+	SendDatagram(data []byte) error                               // Send an unreliable datagram (RFC 9221)
+	ReceiveDatagram(ctx context.Context) ([]byte, error)          // Wait for the next datagram
+	Close() error                                                 // Close the connection
+}
+
+// A Transport over a QUIC connection, sending each crate on its own stream
+// (reliable, and one lost packet only delays that crate instead of every crate behind it as with TCP)
+// or, if Datagrams is set, as one unreliable datagram.
+//
+// Handshake options such as 0-RTT resumption belong to the QUIC library's own configuration
+// and are used by whatever established Conn
+type QUICTransport struct {
+	Conn         QUICConn        // The connection
+	Datagrams    bool            // Send and receive crates as datagrams instead of streams
+	MaxFrameSize uint64          // Crates longer than this are rejected by RecvCrate(), 0 = DefaultMaxFrameSize
+	Flags        uint8           // Flags for crates returned by RecvCrate()
+	Context      context.Context // If not nil, cancels opening, accepting and waiting, nil = context.Background()
+}
+
+// Send the crate's written data on a new stream (closed once written) or as a datagram,
+// returns the crate's error if it failed under FlagNoPanic
+func (t *QUICTransport) SendCrate(crate *Crate) error {
+	if err := crate.Err(); err != nil {
+		return err
+	}
+	if t.Datagrams {
+		return t.Conn.SendDatagram(crate.Data())
+	}
+	stream, err := t.Conn.OpenStream(t.context())
+	if err != nil {
+		return err
+	}
+	if _, err := stream.Write(crate.Data()); err != nil {
+		stream.Close()
+		return err
+	}
+	return stream.Close()
+}
+
+// Receive the next stream (read until the sender closes it) or datagram into a new crate.
+// Returns ErrFrameTooLarge if it is longer than MaxFrameSize
+func (t *QUICTransport) RecvCrate() (*Crate, error) {
+	maxSize := t.MaxFrameSize
+	if maxSize == 0 {
+		maxSize = DefaultMaxFrameSize
+	}
+	if t.Datagrams {
+		data, err := t.Conn.ReceiveDatagram(t.context())
+		if err != nil {
+			return nil, err
+		}
+		if len64(data) > maxSize {
+			return nil, ErrFrameTooLarge
+		}
+		return OpenCrate(data, t.Flags), nil
+	}
+	stream, err := t.Conn.AcceptStream(t.context())
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+	data, err := io.ReadAll(io.LimitReader(stream, int64(maxSize)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len64(data) > maxSize {
+		return nil, ErrFrameTooLarge
+	}
+	return OpenCrate(data, t.Flags), nil
+}
+
+func (t *QUICTransport) Close() error {
+	return t.Conn.Close()
+}
+
+func (t *QUICTransport) context() context.Context {
+	if t.Context == nil {
+		return context.Background()
+	}
+	return t.Context
+}
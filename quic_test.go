@@ -0,0 +1,95 @@
+//go:build !litecrate_core && !litecrate_nonet
+
+package litecrate_test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+// An in-memory stand-in for a QUIC connection: each stream is an io.Pipe() whose reading end is handed to the peer
+type fakeQUICConn struct {
+	streams   chan io.ReadWriteCloser
+	datagrams chan []byte
+	peer      *fakeQUICConn
+}
+
+func newFakeQUICPair() (*fakeQUICConn, *fakeQUICConn) {
+	a := &fakeQUICConn{streams: make(chan io.ReadWriteCloser, 4), datagrams: make(chan []byte, 4)}
+	b := &fakeQUICConn{streams: make(chan io.ReadWriteCloser, 4), datagrams: make(chan []byte, 4), peer: a}
+	a.peer = b
+	return a, b
+}
+
+func (c *fakeQUICConn) OpenStream(ctx context.Context) (io.ReadWriteCloser, error) {
+	reader, writer := io.Pipe()
+	c.peer.streams <- struct {
+		io.Reader
+		io.WriteCloser
+	}{reader, nopWriteCloser{}}
+	return struct {
+		io.Reader
+		io.WriteCloser
+	}{eofReader{}, writer}, nil
+}
+
+func (c *fakeQUICConn) AcceptStream(ctx context.Context) (io.ReadWriteCloser, error) {
+	select {
+	case stream := <-c.streams:
+		return stream, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *fakeQUICConn) SendDatagram(data []byte) error {
+	c.peer.datagrams <- append([]byte{}, data...)
+	return nil
+}
+
+func (c *fakeQUICConn) ReceiveDatagram(ctx context.Context) ([]byte, error) {
+	select {
+	case data := <-c.datagrams:
+		return data, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *fakeQUICConn) Close() error {
+	return nil
+}
+
+type nopWriteCloser struct{}
+
+func (nopWriteCloser) Write(p []byte) (int, error) { return len(p), nil }
+func (nopWriteCloser) Close() error                { return nil }
+
+type eofReader struct{}
+
+func (eofReader) Read(p []byte) (int, error) { return 0, io.EOF }
+
+func TestQUICTransport(t *testing.T) {
+	for _, datagrams := range []bool{false, true} {
+		connA, connB := newFakeQUICPair()
+		a := &lite.QUICTransport{Conn: connA, Datagrams: datagrams}
+		b := &lite.QUICTransport{Conn: connB, Datagrams: datagrams}
+		checkTransportPair(t, "QUICTransport", a, b)
+
+		b.MaxFrameSize = 3
+		go a.SendCrate(lite.OpenCrate([]byte{1, 2, 3, 4}, lite.FlagStatic))
+		if _, err := b.RecvCrate(); err != lite.ErrFrameTooLarge {
+			t.Errorf("QUICTransport.RecvCrate(too large, datagrams %v) - FAIL: %v", datagrams, err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		b.Context = ctx
+		if _, err := b.RecvCrate(); err != context.Canceled {
+			t.Errorf("QUICTransport.RecvCrate(canceled, datagrams %v) - FAIL: %v", datagrams, err)
+		}
+		a.Close()
+	}
+}
@@ -0,0 +1,318 @@
+//go:build !litecrate_core && !litecrate_notools
+
+package litecrate
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+const (
+	randomMaxElems = 4  // most elements GenerateRandom() puts in a slice or map
+	randomMaxRefs  = 16 // most UseRef() objects GenerateRandom() allocates for one value
+	randomMaxDepth = 4  // how deep GenerateRandom() fills UseAny() pointers
+)
+
+// Characters used for random strings, mostly ASCII with some multi-byte runes mixed in
+var randomRunes = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789 _-.,éüß日本語€")
+
+/**************
+	RANDOM
+***************/
+
+// Fill val with random values, by visiting every value its UseSelf() method uses (see Visit()).
+// Intended for load tests and synthesizing benchmark payloads.
+//
+// Integers stay inside the range of the method they are used with (UseU24() values are below 1<<24)
+// and are spread over every magnitude, so varints of every size are produced. Strings are usually short
+// with the occasional long one, slices and maps get up to 4 elements, and nil UseRef() pointers are
+// sometimes allocated and filled (at most 16 per call). UseAny() values are filled with reflection.
+// Lazy values and UseVersioned() versions are left as they are. Map values are filled in
+// map iteration order, so the same rng seed only repeats the same value if val contains no maps
+func GenerateRandom(val SelfSerializer, rng *rand.Rand) {
+	Visit(val, &randomVisitor{rng: rng, refs: randomMaxRefs})
+}
+
+// Returns a crate holding a random instance of the schema, see GenerateRandom()
+func (s *Schema) GenerateRandom(rng *rand.Rand) *Crate {
+	crate := NewCrate(64, FlagAutoDouble)
+	for i := range s.Fields {
+		s.Fields[i].writeRandom(crate, rng)
+	}
+	return crate
+}
+
+func (f *Field) writeRandom(crate *Crate, rng *rand.Rand) {
+	switch f.Kind {
+	case KindBool:
+		crate.WriteBool(rng.Intn(2) == 1)
+	case KindU8:
+		crate.WriteU8(uint8(randomUint(rng, 8)))
+	case KindI8:
+		crate.WriteI8(int8(randomInt(rng, 8)))
+	case KindU16:
+		crate.WriteU16(uint16(randomUint(rng, 16)))
+	case KindI16:
+		crate.WriteI16(int16(randomInt(rng, 16)))
+	case KindU24:
+		crate.WriteU24(uint32(randomUint(rng, 24)))
+	case KindI24:
+		crate.WriteI24(int32(randomInt(rng, 24)))
+	case KindU32:
+		crate.WriteU32(uint32(randomUint(rng, 32)))
+	case KindI32:
+		crate.WriteI32(int32(randomInt(rng, 32)))
+	case KindU40:
+		crate.WriteU40(randomUint(rng, 40))
+	case KindI40:
+		crate.WriteI40(randomInt(rng, 40))
+	case KindU48:
+		crate.WriteU48(randomUint(rng, 48))
+	case KindI48:
+		crate.WriteI48(randomInt(rng, 48))
+	case KindU56:
+		crate.WriteU56(randomUint(rng, 56))
+	case KindI56:
+		crate.WriteI56(randomInt(rng, 56))
+	case KindU64:
+		crate.WriteU64(randomUint(rng, 64))
+	case KindI64:
+		crate.WriteI64(randomInt(rng, 64))
+	case KindF32:
+		crate.WriteF32(float32(randomFloat(rng)))
+	case KindF64:
+		crate.WriteF64(randomFloat(rng))
+	case KindC64:
+		crate.WriteC64(complex(float32(randomFloat(rng)), float32(randomFloat(rng))))
+	case KindC128:
+		crate.WriteC128(complex(randomFloat(rng), randomFloat(rng)))
+	case KindUVarint:
+		crate.WriteUVarint(randomUint(rng, 64))
+	case KindVarint:
+		crate.WriteVarint(randomInt(rng, 64))
+	case KindString:
+		crate.WriteStringWithCounter(randomString(rng))
+	case KindBytes:
+		crate.WriteBytesWithCounter(randomBytes(rng))
+	case KindSlice, KindMap:
+		n := rng.Intn(randomMaxElems + 1)
+		crate.WriteLengthOrNil(uint64(n), false)
+		for i := 0; i < n; i += 1 {
+			if f.Kind == KindMap && f.Key != nil {
+				f.Key.writeRandom(crate, rng)
+			}
+			if f.Elem != nil {
+				f.Elem.writeRandom(crate, rng)
+			}
+		}
+	case KindStruct:
+		for i := range f.Fields {
+			f.Fields[i].writeRandom(crate, rng)
+		}
+	default:
+		panic("LiteCrate: cannot generate random value for field " + f.Name + " of kind " + f.Kind.String())
+	}
+}
+
+type randomVisitor struct {
+	rng  *rand.Rand
+	refs int
+}
+
+func (r *randomVisitor) Visit(val any, name string) {
+	rng := r.rng
+	switch name {
+	case "UseField", "UseVersioned", "UseLazy", "UseSelfSerializer", "UseFieldGroup":
+		return
+	case "UseSlice":
+		slice := reflect.ValueOf(val).Elem()
+		slice.Set(reflect.MakeSlice(slice.Type(), rng.Intn(randomMaxElems+1), randomMaxElems))
+		return
+	case "UseMap":
+		m := reflect.ValueOf(val).Elem()
+		m.Set(reflect.MakeMap(m.Type()))
+		for n := rng.Intn(randomMaxElems + 1); n > 0; n -= 1 {
+			key := reflect.New(m.Type().Key()).Elem()
+			randomReflect(rng, key, randomMaxDepth)
+			m.SetMapIndex(key, reflect.Zero(m.Type().Elem()))
+		}
+		return
+	case "UseRef":
+		ref := reflect.ValueOf(val).Elem()
+		if ref.IsNil() && r.refs > 0 && rng.Intn(2) == 1 {
+			r.refs -= 1
+			ref.Set(reflect.New(ref.Type().Elem()))
+		}
+		return
+	case "UseAny":
+		randomReflect(rng, reflect.ValueOf(val), randomMaxDepth)
+		return
+	}
+	switch val := val.(type) {
+	case *bool:
+		*val = rng.Intn(2) == 1
+	case *uint8:
+		*val = uint8(randomUint(rng, 8))
+	case *int8:
+		*val = int8(randomInt(rng, 8))
+	case *uint16:
+		*val = uint16(randomUint(rng, 16))
+	case *int16:
+		*val = int16(randomInt(rng, 16))
+	case *uint32:
+		*val = uint32(randomUint(rng, nameBits(name, 32)))
+	case *int32:
+		*val = int32(randomInt(rng, nameBits(name, 32)))
+	case *uint64:
+		*val = randomUint(rng, nameBits(name, 64))
+	case *int64:
+		*val = randomInt(rng, nameBits(name, 64))
+	case *uint:
+		*val = uint(randomUint(rng, nameBits(name, strconv.IntSize)))
+	case *int:
+		*val = int(randomInt(rng, nameBits(name, strconv.IntSize)))
+	case *uintptr:
+		*val = uintptr(randomUint(rng, strconv.IntSize))
+	case *float32:
+		*val = float32(randomFloat(rng))
+	case *float64:
+		*val = randomFloat(rng)
+	case *complex64:
+		*val = complex(float32(randomFloat(rng)), float32(randomFloat(rng)))
+	case *complex128:
+		*val = complex(randomFloat(rng), randomFloat(rng))
+	case *string:
+		*val = randomString(rng)
+	case *[]byte:
+		*val = randomBytes(rng)
+	case *[16]byte:
+		rng.Read(val[:])
+	case *[32]byte:
+		rng.Read(val[:])
+	case *time.Time:
+		*val = time.Unix(rng.Int63n(4102444800), rng.Int63n(int64(time.Second))).UTC()
+	case *time.Duration:
+		*val = time.Duration(randomInt(rng, 64))
+	case *BlobRef:
+		val.Offset = randomUint(rng, 32)
+		val.Length = randomUint(rng, 24)
+		val.Hash = nil
+		if rng.Intn(2) == 1 {
+			val.Hash = make([]byte, 32)
+			rng.Read(val.Hash)
+		}
+	}
+}
+
+// Returns the bit size in a method name like "UseU24" or "UseI40", or def if there is none
+func nameBits(name string, def int) int {
+	if len(name) > 4 {
+		if bits, err := strconv.Atoi(name[4:]); err == nil && bits < def {
+			return bits
+		}
+	}
+	return def
+}
+
+// Returns a random unsigned integer of up to bits bits, with its bit length chosen evenly
+// so small and large values are equally common
+func randomUint(rng *rand.Rand, bits int) uint64 {
+	n := rng.Intn(bits + 1)
+	if n == 0 {
+		return 0
+	}
+	return rng.Uint64()>>(64-n) | 1<<(n-1)
+}
+
+// Returns a random signed integer that fits in bits bits, see randomUint()
+func randomInt(rng *rand.Rand, bits int) int64 {
+	val := int64(randomUint(rng, bits-1))
+	if rng.Intn(2) == 1 {
+		return -val - 1
+	}
+	return val
+}
+
+func randomFloat(rng *rand.Rand) float64 {
+	switch rng.Intn(8) {
+	case 0:
+		return 0
+	case 1:
+		return math.Float64frombits(rng.Uint64() &^ (0x7FF << 52)) // subnormal
+	default:
+		return rng.NormFloat64() * math.Pow(10, float64(rng.Intn(13)-6))
+	}
+}
+
+// Returns a random length, usually below 16 and occasionally up to 255
+func randomLength(rng *rand.Rand) int {
+	if rng.Intn(10) == 0 {
+		return rng.Intn(256)
+	}
+	return rng.Intn(16)
+}
+
+func randomString(rng *rand.Rand) string {
+	runes := make([]rune, randomLength(rng))
+	for i := range runes {
+		runes[i] = randomRunes[rng.Intn(len(randomRunes))]
+	}
+	return string(runes)
+}
+
+func randomBytes(rng *rand.Rand) []byte {
+	val := make([]byte, randomLength(rng))
+	rng.Read(val)
+	return val
+}
+
+// Fill v with random values using reflection, allocating pointers only while depth > 0
+func randomReflect(rng *rand.Rand, v reflect.Value, depth int) {
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(rng.Intn(2) == 1)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(randomInt(rng, v.Type().Bits()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v.SetUint(randomUint(rng, v.Type().Bits()))
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(randomFloat(rng))
+	case reflect.Complex64, reflect.Complex128:
+		v.SetComplex(complex(randomFloat(rng), randomFloat(rng)))
+	case reflect.String:
+		v.SetString(randomString(rng))
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), rng.Intn(randomMaxElems+1), randomMaxElems))
+		fallthrough
+	case reflect.Array:
+		for i := 0; i < v.Len(); i += 1 {
+			randomReflect(rng, v.Index(i), depth)
+		}
+	case reflect.Map:
+		v.Set(reflect.MakeMap(v.Type()))
+		for n := rng.Intn(randomMaxElems + 1); n > 0; n -= 1 {
+			key := reflect.New(v.Type().Key()).Elem()
+			elem := reflect.New(v.Type().Elem()).Elem()
+			randomReflect(rng, key, depth)
+			randomReflect(rng, elem, depth)
+			v.SetMapIndex(key, elem)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i += 1 {
+			if v.Field(i).CanSet() {
+				randomReflect(rng, v.Field(i), depth)
+			}
+		}
+	case reflect.Pointer:
+		if v.IsNil() {
+			if depth <= 0 || !v.CanSet() || rng.Intn(2) == 0 {
+				return
+			}
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		randomReflect(rng, v.Elem(), depth-1)
+	}
+}
@@ -0,0 +1,100 @@
+//go:build !litecrate_core && !litecrate_notools
+
+package litecrate_test
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"unicode/utf8"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+type randomRecord struct {
+	ID     uint32
+	Offset int64
+	Count  uint64
+	Score  float64
+	Name   string
+	Data   []byte
+	Tags   []string
+	Counts map[string]uint16
+	Extra  struct {
+		A []int16
+		B map[uint8]*string
+	}
+	Next *randomRecord
+}
+
+func (r *randomRecord) UseSelf(crate *lite.Crate, mode lite.UseMode) {
+	crate.UseU24(&r.ID, mode)
+	crate.UseI40(&r.Offset, mode)
+	crate.UseUVarint(&r.Count, mode)
+	crate.UseF64(&r.Score, mode)
+	crate.UseStringWithCounter(&r.Name, mode)
+	crate.UseBytesWithCounter(&r.Data, mode)
+	lite.UseSlice(crate, mode, &r.Tags, crate.UseStringWithCounter)
+	lite.UseMap(crate, mode, &r.Counts, crate.UseStringWithCounter, crate.UseU16)
+	crate.UseAny(&r.Extra, mode)
+	lite.UseRef(crate, mode, &r.Next, (*randomRecord).UseSelf)
+}
+
+func TestGenerateRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	crate := lite.NewCrate(64, lite.FlagAutoDouble)
+	sizes := make(map[uint64]bool)
+	for i := 0; i < 500; i += 1 {
+		var val randomRecord
+		lite.GenerateRandom(&val, rng)
+		if val.ID >= 1<<24 || val.Offset >= 1<<39 || val.Offset < -1<<39 || !utf8.ValidString(val.Name) {
+			t.Fatalf("GenerateRandom() - FAIL: value out of range: %+v", val)
+		}
+		if len(val.Tags) > 4 || len(val.Counts) > 4 {
+			t.Fatalf("GenerateRandom() - FAIL: %d tags, %d counts", len(val.Tags), len(val.Counts))
+		}
+		sizes[crate.WriteUVarint(val.Count)] = true
+		crate.Reset()
+		crate.WriteSelfSerializer(&val)
+		var got randomRecord
+		crate.ReadSelfSerializer(&got)
+		if !reflect.DeepEqual(got, val) {
+			t.Fatalf("GenerateRandom() - FAIL: random value did not survive round trip:\n%+v\n%+v", val, got)
+		}
+	}
+	if len(sizes) < 8 {
+		t.Errorf("GenerateRandom() - FAIL: only %d different uvarint sizes generated", len(sizes))
+	}
+}
+
+func TestSchemaGenerateRandom(t *testing.T) {
+	schema := &lite.Schema{Name: "Random", Fields: []lite.Field{
+		{Name: "id", Kind: lite.KindU24},
+		{Name: "name", Kind: lite.KindString},
+		{Name: "scores", Kind: lite.KindMap, Key: &lite.Field{Kind: lite.KindString}, Elem: &lite.Field{Kind: lite.KindI40}},
+		{Name: "pos", Kind: lite.KindStruct, Fields: []lite.Field{{Name: "x", Kind: lite.KindF32}, {Name: "y", Kind: lite.KindVarint}}},
+	}}
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i += 1 {
+		crate := schema.GenerateRandom(rng)
+		if crate.ReadU24() >= 1<<24 || !utf8.ValidString(crate.ReadStringWithCounter()) {
+			t.Fatalf("Schema.GenerateRandom() - FAIL: invalid header fields")
+		}
+		length, _ := crate.ReadLength()
+		for j := uint64(0); j < length; j += 1 {
+			crate.DiscardStringWithCounter()
+			if val := crate.ReadI40(); val >= 1<<39 || val < -1<<39 {
+				t.Fatalf("Schema.GenerateRandom() - FAIL: I40 value %d out of range", val)
+			}
+		}
+		crate.ReadF32()
+		crate.ReadVarint()
+		if crate.ReadsLeft() != 0 {
+			t.Fatalf("Schema.GenerateRandom() - FAIL: %d bytes left", crate.ReadsLeft())
+		}
+	}
+	bad := &lite.Schema{Fields: []lite.Field{{Name: "bad", Kind: lite.FieldKind(200)}}}
+	if catchPanic[error](func() { bad.GenerateRandom(rng) }) == nil {
+		t.Errorf("Schema.GenerateRandom() - FAIL: unknown kind did not panic")
+	}
+}
@@ -0,0 +1,52 @@
+package litecrate
+
+/**************
+	READ LIMITS
+***************/
+
+// Limit reads to the next n unread bytes until the matching PopReadLimit(), so a nested
+// length-delimited section can be decoded defensively: any read past the end of the window panics
+// exactly as reading past the end of the crate does, instead of running on into the data after it.
+// Limits may be nested, and each must fit inside the one before it.
+//
+// While a limit is pushed reads act as if the window's end were the end of the written data, so ReadsLeft()
+// stops there. The write index and written data are unaffected, so Data(), WriteIndex() and writes work as usual
+func (c *Crate) PushReadLimit(n uint64) {
+	if left := c.readEnd() - c.read; n > left {
+		panic("LiteCrate: cannot limit reads to " + intStr(n) + " bytes, only " + intStr(left) + " unread bytes left in crate")
+	}
+	c.limits = append(c.limits, c.read+n)
+}
+
+// Remove the limit set by the most recent unmatched PushReadLimit(), returning how many bytes
+// of its window were left unread. The read index is not moved past them
+func (c *Crate) PopReadLimit() (unread uint64) {
+	last := len(c.limits) - 1
+	if last < 0 {
+		panic("LiteCrate: PopReadLimit() called without PushReadLimit()")
+	}
+	unread = c.readEnd() - c.read
+	c.restoreReadLimits(last)
+	return unread
+}
+
+// Returns how many read limits are currently pushed
+func (c *Crate) ReadLimits() int {
+	return len(c.limits)
+}
+
+// Pop read limits until only depth remain
+func (c *Crate) restoreReadLimits(depth int) {
+	if len(c.limits) > depth {
+		c.limits = c.limits[:depth]
+	}
+}
+
+// Returns the index reads stop at: the end of the innermost read limit, or else the write index
+// (always the write index of a failed crate, whose indexes describe its scratch space)
+func (c *Crate) readEnd() uint64 {
+	if last := len(c.limits) - 1; last >= 0 && c.failed == nil {
+		return c.limits[last]
+	}
+	return c.write
+}
@@ -0,0 +1,73 @@
+package litecrate_test
+
+import (
+	"bytes"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+func TestReadLimit(t *testing.T) {
+	crate := lite.NewCrate(16, lite.FlagAutoDouble)
+	crate.WriteU16(1)
+	crate.WriteU32(2)
+	crate.WriteU8(3)
+	crate.WriteU8(4)
+
+	crate.PushReadLimit(7)
+	if crate.ReadsLeft() != 7 || crate.WriteIndex() != 8 || !bytes.Equal(crate.Data(), []byte{1, 0, 2, 0, 0, 0, 3, 4}) {
+		t.Errorf("PushReadLimit() - FAIL: reads left %d, data %v", crate.ReadsLeft(), crate.Data())
+	}
+	crate.PushReadLimit(2)
+	if crate.ReadLimits() != 2 || crate.ReadU16() != 1 {
+		t.Errorf("PushReadLimit() - FAIL: nested window misread")
+	}
+	if catchPanic[error](func() { crate.ReadU8() }) == nil {
+		t.Errorf("PushReadLimit() - FAIL: read past window did not panic")
+	}
+	if unread := crate.PopReadLimit(); unread != 0 || crate.ReadU32() != 2 {
+		t.Errorf("PopReadLimit() - FAIL: %d unread", unread)
+	}
+	if catchPanic[error](func() { crate.PeekU16() }) == nil {
+		t.Errorf("PushReadLimit() - FAIL: peek past window did not panic")
+	}
+	// Writes go after the written data as usual, outside the window
+	crate.WriteU8(5)
+	if crate.WriteIndex() != 9 || crate.ReadsLeft() != 1 || crate.Key() != "\x01\x00\x02\x00\x00\x00\x03\x04\x05" {
+		t.Errorf("PushReadLimit() - FAIL: write while limited gave write index %d, %d reads left", crate.WriteIndex(), crate.ReadsLeft())
+	}
+	if catchPanic[error](func() { crate.PushReadLimit(2) }) == nil {
+		t.Errorf("PushReadLimit() - FAIL: window larger than the one outside it did not panic")
+	}
+	if unread := crate.PopReadLimit(); unread != 1 || crate.ReadLimits() != 0 || crate.ReadsLeft() != 3 {
+		t.Errorf("PopReadLimit() - FAIL: %d unread, %d reads left", unread, crate.ReadsLeft())
+	}
+	crate.SetReadIndex(6)
+	crate.PushReadLimit(1)
+	crate.DiscardN(2)
+	if crate.PopReadLimit() != 0 || crate.ReadU8() != 4 {
+		t.Errorf("DiscardN() - FAIL: discarded past the end of the window")
+	}
+	if catchPanic[error](func() { crate.PopReadLimit() }) == nil {
+		t.Errorf("PopReadLimit() - FAIL: no panic without PushReadLimit()")
+	}
+
+	crate.ResetReadIndex()
+	crate.PushReadLimit(1)
+	crate.Reset()
+	if crate.ReadLimits() != 0 {
+		t.Errorf("Reset() - FAIL: read limit not cleared")
+	}
+	crate.WriteU8(1)
+
+	// A section whose contents read too far fails at the section's end, and the crate is usable afterwards
+	crate.Reset()
+	crate.UseSection(func(mode lite.UseMode) { crate.UseU16(new(uint16), mode) }, lite.Write)
+	crate.WriteU16(9)
+	if catchPanic[error](func() { crate.UseSection(func(mode lite.UseMode) { crate.UseU32(new(uint32), mode) }, lite.Read) }) == nil {
+		t.Errorf("UseSection(Read) - FAIL: long read did not panic")
+	}
+	if crate.ReadLimits() != 0 || crate.WriteIndex() != 8 {
+		t.Errorf("UseSection(Read) - FAIL: read limit left pushed after panic")
+	}
+}
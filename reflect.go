@@ -0,0 +1,427 @@
+package litecrate
+
+import (
+	"reflect"
+)
+
+var selfSerializerType = reflect.TypeOf((*SelfSerializer)(nil)).Elem()
+
+/**************
+	REFLECTION
+***************/
+
+// Use any value according to mode by inspecting it with reflection,
+// for prototyping without hand-written UseSelf() methods.
+// val must be a non-nil pointer in every mode except Write.
+//
+// Produces the same bytes as the equivalent hand-written Use____() calls:
+//
+//	bool, (u)int8-64, float, complex = Use____() of the same width (int, uint and uintptr use 8 bytes)
+//	string, []byte = UseStringWithCounter(), UseBytesWithCounter()
+//	slice, map = UseSlice(), UseMap() (map entries are written in Go's random iteration order unless flagged with FlagSortedMaps)
+//	array = each element in order, without a counter
+//	struct = each exported field in order (unexported fields are skipped)
+//	pointer = a bool that is true if the pointer is not nil, followed by the value if it is not nil
+//	types whose pointer implements SelfSerializer = UseSelfSerializer()
+//	types registered with RegisterType() = their registered encoding (before any of the above)
+//
+// Write = 'write val into crate', Read = 'read from crate into val',
+// Peek = 'read from crate into val without advancing index'
+// Slice = 'Return the slice the next unread val occupies without altering val'
+func (c *Crate) UseAny(val any, mode UseMode) (sliceModeData []byte) {
+	rv := reflect.ValueOf(val)
+	switch mode {
+	case Write:
+		if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+			rv = rv.Elem()
+		}
+		if !rv.CanAddr() {
+			addressable := reflect.New(rv.Type()).Elem()
+			addressable.Set(rv)
+			rv = addressable
+		}
+		c.writeValue(rv)
+	case Read:
+		c.readValue(anyPointerElem(rv, "Read"))
+	case Peek:
+		elem := anyPointerElem(rv, "Peek")
+		idx := c.read
+		c.readValue(elem)
+		c.read = idx
+	case Discard:
+		c.discardType(anyPointerElem(rv, "Discard").Type())
+	case Slice:
+		start := c.read
+		c.discardType(anyPointerElem(rv, "Slice").Type())
+		end := c.read
+		c.read = start
+		return c.data[start:end:end]
+	default:
+		c.useCustomMode(val, mode, "UseAny")
+	}
+	return nil
+}
+
+func anyPointerElem(rv reflect.Value, mode string) reflect.Value {
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		panic("LiteCrate: UseAny() requires a non-nil pointer in " + mode + " mode")
+	}
+	return rv.Elem()
+}
+
+func isSelfSerializer(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(selfSerializerType)
+}
+
+func panicUnsupportedKind(t reflect.Type) {
+	panic("LiteCrate: UseAny() cannot use values of type " + t.String())
+}
+
+// What an anyTask does when popped off the work stack
+type anyOp uint8
+
+const (
+	opWrite             anyOp = iota // Write v
+	opRead                           // Read into v
+	opDiscard                        // Discard a value of type t
+	opWriteMapEntries                // Write the next entry of iter, then the rest
+	opReadMapEntries                 // Read entry i of n into map v, then the rest
+	opDiscardMapEntries              // Discard entry i of n of map type t, then the rest
+	opReadElems                      // Read element i of n into slice v, then the rest
+	opDiscardElems                   // Discard element i of n of slice or array type t, then the rest
+	opSetMapIndex                    // Store the fully read key and elem in map v
+)
+
+// A unit of work for the UseAny() engine, which keeps its own stack of pending tasks on the heap
+// instead of recursing, so deeply nested values (parse trees, long linked lists)
+// are limited by memory rather than goroutine stack size
+type anyTask struct {
+	op    anyOp
+	depth uint64
+	v     reflect.Value
+	t     reflect.Type
+	key   reflect.Value
+	elem  reflect.Value
+	iter  *reflect.MapIter
+	i, n  uint64
+}
+
+func (c *Crate) writeValue(v reflect.Value) {
+	c.runAnyTasks(anyTask{op: opWrite, v: v})
+}
+
+func (c *Crate) readValue(v reflect.Value) {
+	c.runAnyTasks(anyTask{op: opRead, v: v})
+}
+
+func (c *Crate) discardType(t reflect.Type) {
+	c.runAnyTasks(anyTask{op: opDiscard, t: t})
+}
+
+// Runs tasks until the work stack is empty. Nested values are one deeper than the task that pushed them,
+// so SetMaxDepth() limits them the same as nested SelfSerializers
+func (c *Crate) runAnyTasks(first anyTask) {
+	first.depth = c.depth + 1
+	stack := []anyTask{first}
+	for len(stack) > 0 {
+		task := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if c.maxDepth != 0 && task.depth > c.maxDepth {
+			c.depth = 0
+			panic(&DepthError{MaxDepth: c.maxDepth})
+		}
+		switch task.op {
+		case opWrite:
+			stack = c.writeTask(stack, task)
+		case opRead:
+			stack = c.readTask(stack, task)
+		case opDiscard:
+			stack = c.discardTask(stack, task)
+		case opWriteMapEntries:
+			if task.iter.Next() {
+				key := reflect.New(task.t.Key()).Elem()
+				elem := reflect.New(task.t.Elem()).Elem()
+				key.Set(task.iter.Key())
+				elem.Set(task.iter.Value())
+				stack = append(stack, task, task.child(opWrite, elem), task.child(opWrite, key))
+			}
+		case opReadMapEntries:
+			if task.i < task.n {
+				set := anyTask{op: opSetMapIndex, depth: task.depth, v: task.v}
+				set.key = reflect.New(task.t.Key()).Elem()
+				set.elem = reflect.New(task.t.Elem()).Elem()
+				next := task
+				next.i += 1
+				stack = append(stack, next, set, task.child(opRead, set.elem), task.child(opRead, set.key))
+			}
+		case opDiscardMapEntries:
+			if task.i < task.n {
+				next := task
+				next.i += 1
+				stack = append(stack, next, task.childType(task.t.Elem()), task.childType(task.t.Key()))
+			}
+		case opReadElems:
+			if task.i < task.n {
+				next := task
+				next.i += 1
+				stack = append(stack, next, task.child(opRead, task.v.Index(int(task.i))))
+			}
+		case opDiscardElems:
+			if task.i < task.n {
+				next := task
+				next.i += 1
+				stack = append(stack, next, task.childType(task.t.Elem()))
+			}
+		case opSetMapIndex:
+			task.v.SetMapIndex(task.key, task.elem)
+		}
+	}
+}
+
+// Returns a task using v nested one level deeper than task
+func (task anyTask) child(op anyOp, v reflect.Value) anyTask {
+	return anyTask{op: op, depth: task.depth + 1, v: v}
+}
+
+// Returns a task discarding a value of type t nested one level deeper than task
+func (task anyTask) childType(t reflect.Type) anyTask {
+	return anyTask{op: opDiscard, depth: task.depth + 1, t: t}
+}
+
+// Uses a SelfSerializer at the depth of the task that found it
+func (c *Crate) useSelfSerializerTask(task anyTask, val SelfSerializer, mode UseMode) {
+	depth := c.depth
+	defer func() { c.depth = depth }()
+	c.depth = task.depth - 1
+	c.UseSelfSerializer(val, mode)
+}
+
+// Uses a value of a type registered with RegisterType() at the depth of the task that found it
+func (c *Crate) useRegisteredTask(task anyTask, use func(crate *Crate, val any, mode UseMode), val any, mode UseMode) {
+	depth := c.depth
+	defer func() { c.depth = depth }()
+	c.depth = task.depth - 1
+	use(c, val, mode)
+}
+
+func (c *Crate) writeTask(stack []anyTask, task anyTask) []anyTask {
+	v := task.v
+	t := v.Type()
+	if use, ok := LookupType(t); ok {
+		c.useRegisteredTask(task, use, v.Addr().Interface(), Write)
+		return stack
+	}
+	if isSelfSerializer(t) {
+		c.useSelfSerializerTask(task, v.Addr().Interface().(SelfSerializer), Write)
+		return stack
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		c.WriteBool(v.Bool())
+	case reflect.Int8:
+		c.WriteI8(int8(v.Int()))
+	case reflect.Int16:
+		c.WriteI16(int16(v.Int()))
+	case reflect.Int32:
+		c.WriteI32(int32(v.Int()))
+	case reflect.Int64, reflect.Int:
+		c.WriteI64(v.Int())
+	case reflect.Uint8:
+		c.WriteU8(uint8(v.Uint()))
+	case reflect.Uint16:
+		c.WriteU16(uint16(v.Uint()))
+	case reflect.Uint32:
+		c.WriteU32(uint32(v.Uint()))
+	case reflect.Uint64, reflect.Uint, reflect.Uintptr:
+		c.WriteU64(v.Uint())
+	case reflect.Float32:
+		c.WriteF32(float32(v.Float()))
+	case reflect.Float64:
+		c.WriteF64(v.Float())
+	case reflect.Complex64:
+		c.WriteC64(complex64(v.Complex()))
+	case reflect.Complex128:
+		c.WriteC128(v.Complex())
+	case reflect.String:
+		c.WriteStringWithCounter(v.String())
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			c.WriteBytesWithCounter(v.Bytes())
+			return stack
+		}
+		c.WriteLengthOrNil(uint64(v.Len()), v.IsNil())
+		for i := v.Len() - 1; i >= 0; i -= 1 {
+			stack = append(stack, task.child(opWrite, v.Index(i)))
+		}
+	case reflect.Array:
+		for i := v.Len() - 1; i >= 0; i -= 1 {
+			stack = append(stack, task.child(opWrite, v.Index(i)))
+		}
+	case reflect.Map:
+		c.WriteLengthOrNil(uint64(v.Len()), v.IsNil())
+		if c.WillSortMaps() {
+			keys := c.sortedMapValueKeys(v)
+			for i := len(keys) - 1; i >= 0; i -= 1 {
+				elem := reflect.New(t.Elem()).Elem()
+				elem.Set(v.MapIndex(keys[i]))
+				stack = append(stack, task.child(opWrite, elem), task.child(opWrite, keys[i]))
+			}
+			return stack
+		}
+		stack = append(stack, anyTask{op: opWriteMapEntries, depth: task.depth, t: t, iter: v.MapRange()})
+	case reflect.Struct:
+		for i := v.NumField() - 1; i >= 0; i -= 1 {
+			if t.Field(i).IsExported() {
+				stack = append(stack, task.child(opWrite, v.Field(i)))
+			}
+		}
+	case reflect.Pointer:
+		c.WriteBool(!v.IsNil())
+		if !v.IsNil() {
+			stack = append(stack, task.child(opWrite, v.Elem()))
+		}
+	default:
+		panicUnsupportedKind(t)
+	}
+	return stack
+}
+
+func (c *Crate) readTask(stack []anyTask, task anyTask) []anyTask {
+	v := task.v
+	t := v.Type()
+	if use, ok := LookupType(t); ok {
+		c.useRegisteredTask(task, use, v.Addr().Interface(), Read)
+		return stack
+	}
+	if isSelfSerializer(t) {
+		c.useSelfSerializerTask(task, v.Addr().Interface().(SelfSerializer), Read)
+		return stack
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		v.SetBool(c.ReadBool())
+	case reflect.Int8:
+		v.SetInt(int64(c.ReadI8()))
+	case reflect.Int16:
+		v.SetInt(int64(c.ReadI16()))
+	case reflect.Int32:
+		v.SetInt(int64(c.ReadI32()))
+	case reflect.Int64, reflect.Int:
+		v.SetInt(c.ReadI64())
+	case reflect.Uint8:
+		v.SetUint(uint64(c.ReadU8()))
+	case reflect.Uint16:
+		v.SetUint(uint64(c.ReadU16()))
+	case reflect.Uint32:
+		v.SetUint(uint64(c.ReadU32()))
+	case reflect.Uint64, reflect.Uint, reflect.Uintptr:
+		v.SetUint(c.ReadU64())
+	case reflect.Float32:
+		v.SetFloat(float64(c.ReadF32()))
+	case reflect.Float64:
+		v.SetFloat(c.ReadF64())
+	case reflect.Complex64:
+		v.SetComplex(complex128(c.ReadC64()))
+	case reflect.Complex128:
+		v.SetComplex(c.ReadC128())
+	case reflect.String:
+		v.SetString(c.ReadStringWithCounter())
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			v.SetBytes(c.ReadBytesWithCounter())
+			return stack
+		}
+		length, isNil, _ := c.ReadLengthOrNil()
+		if isNil {
+			v.Set(reflect.Zero(t))
+			return stack
+		}
+		// The elements are read by one task in turn, so the stack does not grow with the counter
+		c.checkAlloc(length, uint64(t.Elem().Size()))
+		slice := reflect.MakeSlice(t, int(length), int(length))
+		v.Set(slice)
+		stack = append(stack, anyTask{op: opReadElems, depth: task.depth, v: slice, n: length})
+	case reflect.Array:
+		for i := v.Len() - 1; i >= 0; i -= 1 {
+			stack = append(stack, task.child(opRead, v.Index(i)))
+		}
+	case reflect.Map:
+		length, isNil, _ := c.ReadLengthOrNil()
+		if isNil {
+			v.Set(reflect.Zero(t))
+			return stack
+		}
+		c.checkAlloc(length, uint64(t.Key().Size()+t.Elem().Size()))
+		m := reflect.MakeMapWithSize(t, int(length))
+		v.Set(m)
+		stack = append(stack, anyTask{op: opReadMapEntries, depth: task.depth, v: m, t: t, n: length})
+	case reflect.Struct:
+		for i := v.NumField() - 1; i >= 0; i -= 1 {
+			if t.Field(i).IsExported() {
+				stack = append(stack, task.child(opRead, v.Field(i)))
+			}
+		}
+	case reflect.Pointer:
+		if !c.ReadBool() {
+			v.Set(reflect.Zero(t))
+			return stack
+		}
+		ptr := reflect.New(t.Elem())
+		v.Set(ptr)
+		stack = append(stack, task.child(opRead, ptr.Elem()))
+	default:
+		panicUnsupportedKind(t)
+	}
+	return stack
+}
+
+func (c *Crate) discardTask(stack []anyTask, task anyTask) []anyTask {
+	t := task.t
+	if use, ok := LookupType(t); ok {
+		c.useRegisteredTask(task, use, reflect.New(t).Interface(), Discard)
+		return stack
+	}
+	if isSelfSerializer(t) {
+		c.useSelfSerializerTask(task, reflect.New(t).Interface().(SelfSerializer), Discard)
+		return stack
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int8, reflect.Uint8:
+		c.DiscardN(1)
+	case reflect.Int16, reflect.Uint16:
+		c.DiscardN(2)
+	case reflect.Int32, reflect.Uint32, reflect.Float32:
+		c.DiscardN(4)
+	case reflect.Int64, reflect.Int, reflect.Uint64, reflect.Uint, reflect.Uintptr, reflect.Float64, reflect.Complex64:
+		c.DiscardN(8)
+	case reflect.Complex128:
+		c.DiscardN(16)
+	case reflect.String:
+		c.DiscardStringWithCounter()
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			c.DiscardBytesWithCounter()
+			return stack
+		}
+		length, _, _ := c.ReadLengthOrNil()
+		stack = append(stack, anyTask{op: opDiscardElems, depth: task.depth, t: t, n: length})
+	case reflect.Array:
+		stack = append(stack, anyTask{op: opDiscardElems, depth: task.depth, t: t, n: uint64(t.Len())})
+	case reflect.Map:
+		length, _, _ := c.ReadLengthOrNil()
+		stack = append(stack, anyTask{op: opDiscardMapEntries, depth: task.depth, t: t, n: length})
+	case reflect.Struct:
+		for i := t.NumField() - 1; i >= 0; i -= 1 {
+			if t.Field(i).IsExported() {
+				stack = append(stack, task.childType(t.Field(i).Type))
+			}
+		}
+	case reflect.Pointer:
+		if c.ReadBool() {
+			stack = append(stack, task.childType(t.Elem()))
+		}
+	default:
+		panicUnsupportedKind(t)
+	}
+	return stack
+}
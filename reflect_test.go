@@ -0,0 +1,133 @@
+package litecrate_test
+
+import (
+	"bytes"
+	"reflect"
+	"runtime/debug"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+type anyRecord struct {
+	Age     uint8
+	Name    string
+	Mood    int64
+	Ratio   float32
+	Tags    []string
+	Blob    []byte
+	Scores  map[string]float64
+	Grid    [3]int16
+	Child   *anyRecord
+	Owner   *person
+	private int
+}
+
+func (r *anyRecord) useByHand(crate *lite.Crate, mode lite.UseMode) {
+	crate.UseU8(&r.Age, mode)
+	crate.UseStringWithCounter(&r.Name, mode)
+	crate.UseI64(&r.Mood, mode)
+	crate.UseF32(&r.Ratio, mode)
+	lite.UseSlice(crate, mode, &r.Tags, crate.UseStringWithCounter)
+	crate.UseBytesWithCounter(&r.Blob, mode)
+	lite.UseMap(crate, mode, &r.Scores, crate.UseStringWithCounter, crate.UseF64)
+	for i := range r.Grid {
+		crate.UseI16(&r.Grid[i], mode)
+	}
+	hasChild := r.Child != nil
+	crate.UseBool(&hasChild, mode)
+	if hasChild {
+		r.Child.useByHand(crate, mode)
+	}
+	hasOwner := r.Owner != nil
+	crate.UseBool(&hasOwner, mode)
+	if hasOwner {
+		crate.UseSelfSerializer(r.Owner, mode)
+	}
+}
+
+func TestUseAny(t *testing.T) {
+	owner := benchPerson
+	owner.Phone = map[string]complex128{"Mom": complex(1, 2)}
+	owner.Children = nil
+	record := anyRecord{
+		Age: 39, Name: "Derek", Mood: -2, Ratio: 0.5,
+		Tags:   []string{"a", "bc"},
+		Blob:   nil,
+		Scores: map[string]float64{"x": 1.5},
+		Grid:   [3]int16{-1, 0, 1},
+		Child:  &anyRecord{Name: "Chris", Tags: []string{}},
+		Owner:  &owner,
+	}
+	byHand := lite.NewCrate(8, lite.FlagAutoDouble)
+	record.useByHand(byHand, lite.Write)
+	crate := lite.NewCrate(8, lite.FlagAutoDouble)
+	crate.UseAny(record, lite.Write)
+	if !bytes.Equal(crate.Data(), byHand.Data()) {
+		t.Fatalf("Write Any - FAIL: reflection and hand-written bytes differ \n%v \n%v", crate.Data(), byHand.Data())
+	}
+	crate.WriteU8(99)
+	peeked := anyRecord{}
+	crate.UseAny(&peeked, lite.Peek)
+	if crate.ReadIndex() != 0 {
+		t.Error("Peek Any - FAIL: index was increased")
+	}
+	slice := crate.UseAny(&peeked, lite.Slice)
+	if len(slice) != byHand.Len() {
+		t.Errorf("Slice Any - FAIL: len %d != %d", len(slice), byHand.Len())
+	}
+	crate.UseAny(&peeked, lite.Discard)
+	if crate.ReadU8() != 99 {
+		t.Error("Discard Any - FAIL: did not discard whole value")
+	}
+	if !reflect.DeepEqual(record, peeked) {
+		t.Errorf("Read/Write Any - FAIL: \n%#v != \n%#v", record, peeked)
+	}
+	if catchPanic[error](func() { crate.UseAny(record, lite.Read) }) == nil {
+		t.Error("Read Any - FAIL: non-pointer did not panic")
+	}
+	if catchPanic[error](func() { crate.UseAny(func() {}, lite.Write) }) == nil {
+		t.Error("Write Any - FAIL: func did not panic")
+	}
+}
+
+type anyListNode struct {
+	Val  int32
+	Next *anyListNode
+}
+
+func TestUseAnyDeep(t *testing.T) {
+	const depth = 200000
+	var head *anyListNode
+	for i := int32(0); i < depth; i += 1 {
+		head = &anyListNode{Val: i, Next: head}
+	}
+	// A recursive engine needs far more than 1MB of stack for a list this long
+	defer debug.SetMaxStack(debug.SetMaxStack(1 << 20))
+	crate := lite.NewCrate(1024, lite.FlagAutoDouble)
+	crate.UseAny(head, lite.Write)
+	crate.WriteU8(42)
+
+	slice := crate.UseAny(&anyListNode{}, lite.Slice)
+	if uint64(len(slice)) != crate.WriteIndex()-1 {
+		t.Errorf("UseAny(Slice) - FAIL: deep list slice length %d != %d", len(slice), crate.WriteIndex()-1)
+	}
+	var got anyListNode
+	crate.UseAny(&got, lite.Read)
+	count := int32(0)
+	for node := &got; node != nil; node = node.Next {
+		if node.Val != depth-1-count {
+			t.Fatalf("UseAny(Read) - FAIL: node %d has value %d", count, node.Val)
+		}
+		count += 1
+	}
+	if count != depth || crate.ReadU8() != 42 {
+		t.Errorf("UseAny(Read) - FAIL: read %d of %d nodes", count, depth)
+	}
+
+	crate.SetMaxDepth(depth)
+	crate.ResetReadIndex()
+	if catchPanic[error](func() { crate.UseAny(&got, lite.Discard) }) == nil {
+		t.Errorf("UseAny(Discard) - FAIL: deep list did not exceed max depth")
+	}
+}
@@ -0,0 +1,47 @@
+package litecrate
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Registered encodings by reflect.Type, holding a func(crate *Crate, val any, mode UseMode)
+var typeRegistry sync.Map
+
+/**************
+	REGISTRY
+***************/
+
+// Register how values of type T are used, so UseAny() and StructPlans use it for every T they meet
+// (as a value, a struct field, a slice element...) instead of their own layout. Intended for
+// third-party types that cannot be given a UseSelf() method, such as uuid.UUID or decimal.Decimal.
+// A registered encoding takes priority over a UseSelf() method T may have.
+//
+// use must handle every mode the same way a Use____() method does, and is passed a non-nil pointer
+// in every mode. Registering T again replaces its encoding. Register types before building any
+// StructPlan that contains them, as a plan only sees the registry as it was when it was built
+//
+// Example:
+//
+//	RegisterType(func(crate *Crate, val *uuid.UUID, mode UseMode) {
+//		crate.UseUUID((*[16]byte)(val), mode)
+//	})
+func RegisterType[T any](use func(crate *Crate, val *T, mode UseMode)) {
+	typeRegistry.Store(reflect.TypeOf((*T)(nil)).Elem(), func(crate *Crate, val any, mode UseMode) {
+		use(crate, val.(*T), mode)
+	})
+}
+
+// Remove the encoding registered for type T, if any
+func UnregisterType[T any]() {
+	typeRegistry.Delete(reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// Returns the encoding registered for t. The returned func must be passed a non-nil pointer to a t
+func LookupType(t reflect.Type) (use func(crate *Crate, val any, mode UseMode), ok bool) {
+	registered, ok := typeRegistry.Load(t)
+	if !ok {
+		return nil, false
+	}
+	return registered.(func(crate *Crate, val any, mode UseMode)), true
+}
@@ -0,0 +1,86 @@
+package litecrate_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+// Stored as tenths of a degree in 2 bytes instead of a float64
+type celsius float64
+
+func useCelsius(crate *lite.Crate, val *celsius, mode lite.UseMode) {
+	tenths := int16(*val * 10)
+	crate.UseI16(&tenths, mode)
+	if mode == lite.Read || mode == lite.Peek {
+		*val = celsius(tenths) / 10
+	}
+}
+
+type weatherReport struct {
+	Station string
+	Temp    celsius
+	History []celsius
+	ByHour  map[uint8]celsius
+	Peak    *celsius
+}
+
+func TestRegisterType(t *testing.T) {
+	lite.RegisterType(useCelsius)
+	defer lite.UnregisterType[celsius]()
+	if _, ok := lite.LookupType(reflect.TypeOf(celsius(0))); !ok {
+		t.Fatalf("LookupType() - FAIL: registered type not found")
+	}
+
+	peak := celsius(30.5)
+	report := weatherReport{Station: "north", Temp: 21.5, History: []celsius{19, 20.5}, ByHour: map[uint8]celsius{6: -1.5}, Peak: &peak}
+	crate := lite.NewCrate(16, lite.FlagAutoDouble)
+	crate.UseAny(&report, lite.Write)
+	expected := lite.NewCrate(16, lite.FlagAutoDouble)
+	expected.WriteStringWithCounter("north")
+	expected.WriteI16(215)
+	lite.UseSlice(expected, lite.Write, &report.History, func(val *celsius, mode lite.UseMode) []byte {
+		useCelsius(expected, val, mode)
+		return nil
+	})
+	expected.WriteLengthOrNil(1, false)
+	expected.WriteU8(6)
+	expected.WriteI16(-15)
+	expected.WriteBool(true)
+	expected.WriteI16(305)
+	if !bytes.Equal(crate.Data(), expected.Data()) {
+		t.Fatalf("UseAny() - FAIL: registered type not used: %v != %v", crate.Data(), expected.Data())
+	}
+	var read weatherReport
+	slice := crate.UseAny(&read, lite.Slice)
+	crate.UseAny(&read, lite.Read)
+	if !reflect.DeepEqual(read, report) || len(slice) != len(expected.Data()) || crate.ReadsLeft() != 0 {
+		t.Errorf("UseAny() - FAIL: read %+v", read)
+	}
+
+	plan, err := lite.BuildSerializer(reflect.TypeOf(report))
+	if err != nil {
+		t.Fatalf("BuildSerializer() - FAIL: %v", err)
+	}
+	planned := lite.NewCrate(16, lite.FlagAutoDouble)
+	plan.Use(planned, &report, lite.Write)
+	if !bytes.Equal(planned.Data(), expected.Data()) {
+		t.Errorf("StructPlan.Use() - FAIL: registered type not used: %v", planned.Data())
+	}
+	type tagged struct {
+		Temp celsius `crate:"F32"`
+	}
+	if _, err := lite.BuildSerializer(reflect.TypeOf(tagged{})); err == nil {
+		t.Errorf("BuildSerializer() - FAIL: registered type tagged with a kind")
+	}
+
+	lite.UnregisterType[celsius]()
+	crate.Reset()
+	temp := celsius(1)
+	crate.UseAny(&temp, lite.Write)
+	if crate.WriteIndex() != 8 {
+		t.Errorf("UnregisterType() - FAIL: registered encoding still used")
+	}
+}
@@ -0,0 +1,157 @@
+package litecrate
+
+// Position inside a slice or map being read element by element with a SliceReader or MapReader,
+// from which reading can resume later (in another scheduling slice, or after a restart).
+// A checkpoint is itself a SelfSerializer, so it can be stored in a crate
+type DecodeCheckpoint struct {
+	Offset uint64 // Read index of the next unread element
+	Index  uint64 // Index of the next unread element
+	Length uint64 // Number of elements in the collection
+}
+
+func (cp *DecodeCheckpoint) UseSelf(crate *Crate, mode UseMode) {
+	crate.UseUVarint(&cp.Offset, mode)
+	crate.UseUVarint(&cp.Index, mode)
+	crate.UseUVarint(&cp.Length, mode)
+}
+
+// Whether every element has been read
+func (cp DecodeCheckpoint) Done() bool {
+	return cp.Index >= cp.Length
+}
+
+// Set the crate's read index to the checkpoint, panicking if it does not fit the crate
+func (c *Crate) resumeAt(cp DecodeCheckpoint, method string) {
+	if cp.Offset > c.write || cp.Index > cp.Length {
+		panic("LiteCrate: " + method + "() checkpoint (offset " + intStr(cp.Offset) + ", element " + intStr(cp.Index) + " of " + intStr(cp.Length) + ") does not fit crate with write index " + intStr(c.write))
+	}
+	c.read = cp.Offset
+}
+
+/**************
+	SLICE READER
+***************/
+
+// Reads the elements of a slice written by UseSlice() (or a Use____Slice() method) one at a time,
+// so a huge slice can be processed in pieces without holding it all in memory.
+// Checkpoint() records how far it has read, and ResumeSliceReader() continues from there.
+//
+// Example:
+//
+//	reader := NewSliceReader(myCrate, myCrate.UseF64)
+//	var val float64
+//	for reader.Next(&val) {
+//		...
+//	}
+type SliceReader[T any] struct {
+	crate *Crate
+	use   UseFunc[T]
+	cp    DecodeCheckpoint
+	isNil bool
+}
+
+// Read the length-or-nil counter of the slice at the crate's read index and return a reader for its elements
+func NewSliceReader[T any](crate *Crate, useElementFunc UseFunc[T]) *SliceReader[T] {
+	length, isNil, _ := crate.ReadLengthOrNil()
+	return &SliceReader[T]{crate: crate, use: useElementFunc, cp: DecodeCheckpoint{Offset: crate.read, Length: length}, isNil: isNil}
+}
+
+// Return a reader that continues reading a slice from a checkpoint returned by Checkpoint()
+// of an earlier reader over the same crate data. Panics if the checkpoint does not fit the crate
+func ResumeSliceReader[T any](crate *Crate, checkpoint DecodeCheckpoint, useElementFunc UseFunc[T]) *SliceReader[T] {
+	crate.resumeAt(checkpoint, "ResumeSliceReader")
+	return &SliceReader[T]{crate: crate, use: useElementFunc, cp: checkpoint}
+}
+
+// Read the next element into *elem, returning false (without touching *elem) once every element has been read,
+// at which point the crate's read index is just after the slice. A failure while reading the element
+// panics with a *PathError naming the element's index
+func (r *SliceReader[T]) Next(elem *T) bool {
+	if r.cp.Done() {
+		return false
+	}
+	at := elementPath{index: r.cp.Index, active: true}
+	defer at.catch()
+	var zero T
+	*elem = zero
+	r.use(elem, Read)
+	r.cp.Index += 1
+	r.cp.Offset = r.crate.read
+	return true
+}
+
+// Returns the position of the next element, to pass to ResumeSliceReader() later
+func (r *SliceReader[T]) Checkpoint() DecodeCheckpoint {
+	return r.cp
+}
+
+// Returns the number of elements left to read
+func (r *SliceReader[T]) Remaining() uint64 {
+	return r.cp.Length - r.cp.Index
+}
+
+// Whether the slice was written as nil (always false for resumed readers)
+func (r *SliceReader[T]) IsNil() bool {
+	return r.isNil
+}
+
+/**************
+	MAP READER
+***************/
+
+// Reads the entries of a map written by UseMap() one at a time, the map equivalent of SliceReader
+type MapReader[K comparable, V any] struct {
+	crate  *Crate
+	useKey UseFunc[K]
+	useVal UseFunc[V]
+	cp     DecodeCheckpoint
+	isNil  bool
+}
+
+// Read the length-or-nil counter of the map at the crate's read index and return a reader for its entries
+func NewMapReader[K comparable, V any](crate *Crate, useKeyFunc UseFunc[K], useValFunc UseFunc[V]) *MapReader[K, V] {
+	length, isNil, _ := crate.ReadLengthOrNil()
+	return &MapReader[K, V]{crate: crate, useKey: useKeyFunc, useVal: useValFunc, cp: DecodeCheckpoint{Offset: crate.read, Length: length}, isNil: isNil}
+}
+
+// Return a reader that continues reading a map from a checkpoint returned by Checkpoint()
+// of an earlier reader over the same crate data. Panics if the checkpoint does not fit the crate
+func ResumeMapReader[K comparable, V any](crate *Crate, checkpoint DecodeCheckpoint, useKeyFunc UseFunc[K], useValFunc UseFunc[V]) *MapReader[K, V] {
+	crate.resumeAt(checkpoint, "ResumeMapReader")
+	return &MapReader[K, V]{crate: crate, useKey: useKeyFunc, useVal: useValFunc, cp: checkpoint}
+}
+
+// Read the next entry into *key and *val, returning false (without touching them) once every entry has been read,
+// at which point the crate's read index is just after the map. A failure while reading the entry
+// panics with a *PathError naming its key (or its position, if the key could not be read)
+func (r *MapReader[K, V]) Next(key *K, val *V) bool {
+	if r.cp.Done() {
+		return false
+	}
+	at := elementPath{index: r.cp.Index, key: key, active: true}
+	defer at.catch()
+	var zeroKey K
+	var zeroVal V
+	*key, *val = zeroKey, zeroVal
+	r.useKey(key, Read)
+	at.keyOK = true
+	r.useVal(val, Read)
+	r.cp.Index += 1
+	r.cp.Offset = r.crate.read
+	return true
+}
+
+// Returns the position of the next entry, to pass to ResumeMapReader() later
+func (r *MapReader[K, V]) Checkpoint() DecodeCheckpoint {
+	return r.cp
+}
+
+// Returns the number of entries left to read
+func (r *MapReader[K, V]) Remaining() uint64 {
+	return r.cp.Length - r.cp.Index
+}
+
+// Whether the map was written as nil (always false for resumed readers)
+func (r *MapReader[K, V]) IsNil() bool {
+	return r.isNil
+}
@@ -0,0 +1,93 @@
+package litecrate_test
+
+import (
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+func TestSliceReader(t *testing.T) {
+	crate := lite.NewCrate(64, lite.FlagAutoDouble)
+	slice := []string{"a", "bb", "ccc", "dddd", "eeeee"}
+	lite.UseSlice(crate, lite.Write, &slice, crate.UseStringWithCounter)
+	crate.WriteU8(99)
+
+	reader := lite.NewSliceReader(crate, crate.UseStringWithCounter)
+	var elem string
+	for i := 0; i < 2; i += 1 {
+		if !reader.Next(&elem) || elem != slice[i] {
+			t.Fatalf("SliceReader.Next() - FAIL: element %d = %q, want %q", i, elem, slice[i])
+		}
+	}
+	checkpoint := reader.Checkpoint()
+	if checkpoint.Index != 2 || checkpoint.Length != 5 || reader.Remaining() != 3 {
+		t.Errorf("SliceReader.Checkpoint() - FAIL: %+v, remaining %d", checkpoint, reader.Remaining())
+	}
+
+	// Store the checkpoint and resume from it in a fresh crate over the same data
+	stored := lite.NewCrate(8, lite.FlagAutoDouble)
+	stored.WriteSelfSerializer(&checkpoint)
+	var loaded lite.DecodeCheckpoint
+	stored.ReadSelfSerializer(&loaded)
+	if loaded != checkpoint {
+		t.Fatalf("DecodeCheckpoint.UseSelf() - FAIL: %+v != %+v", loaded, checkpoint)
+	}
+	again := lite.OpenCrate(crate.Data(), lite.FlagDefault)
+	resumed := lite.ResumeSliceReader(again, loaded, again.UseStringWithCounter)
+	var rest []string
+	for resumed.Next(&elem) {
+		rest = append(rest, elem)
+	}
+	if len(rest) != 3 || rest[0] != "ccc" || rest[2] != "eeeee" || !resumed.Checkpoint().Done() {
+		t.Errorf("ResumeSliceReader() - FAIL: read %q", rest)
+	}
+	if again.ReadU8() != 99 {
+		t.Errorf("SliceReader.Next() - FAIL: read index not left after slice")
+	}
+	if catchPanic[error](func() { lite.ResumeSliceReader(again, lite.DecodeCheckpoint{Offset: 1000}, again.UseU8) }) == nil {
+		t.Errorf("ResumeSliceReader() - FAIL: did not panic with checkpoint past write index")
+	}
+
+	var nilSlice []uint16
+	crate.Reset()
+	lite.UseSlice(crate, lite.Write, &nilSlice, crate.UseU16)
+	if reader := lite.NewSliceReader(crate, crate.UseU16); !reader.IsNil() || reader.Remaining() != 0 {
+		t.Errorf("NewSliceReader(nil) - FAIL: IsNil %v, remaining %d", reader.IsNil(), reader.Remaining())
+	}
+}
+
+func TestSliceReaderPath(t *testing.T) {
+	crate := lite.NewCrate(64, lite.FlagAutoDouble)
+	slice := []uint32{1, 2, 3}
+	crate.UseU32Slice(&slice, lite.Write)
+	truncated := lite.OpenCrate(crate.Data()[:crate.Len()-1], lite.FlagDefault)
+	reader := lite.NewSliceReader(truncated, truncated.UseU32)
+	var elem uint32
+	reader.Next(&elem)
+	reader.Next(&elem)
+	if err := catchPanic[*lite.PathError](func() { reader.Next(&elem) }); err == nil || err.Path != "[2]" {
+		t.Errorf("SliceReader.Next() - FAIL: truncated element panicked with %v, want path [2]", err)
+	}
+}
+
+func TestMapReader(t *testing.T) {
+	crate := lite.NewCrate(64, lite.FlagAutoDouble|lite.FlagSortedMaps)
+	m := map[string]uint16{"a": 1, "b": 2, "c": 3}
+	lite.UseMap(crate, lite.Write, &m, crate.UseStringWithCounter, crate.UseU16)
+
+	reader := lite.NewMapReader(crate, crate.UseStringWithCounter, crate.UseU16)
+	var key string
+	var val uint16
+	if !reader.Next(&key, &val) || key != "a" || val != 1 {
+		t.Fatalf("MapReader.Next() - FAIL: (%q, %d)", key, val)
+	}
+	again := lite.OpenCrate(crate.Data(), lite.FlagDefault)
+	resumed := lite.ResumeMapReader(again, reader.Checkpoint(), again.UseStringWithCounter, again.UseU16)
+	read := map[string]uint16{}
+	for resumed.Next(&key, &val) {
+		read[key] = val
+	}
+	if len(read) != 2 || read["b"] != 2 || read["c"] != 3 || resumed.Remaining() != 0 {
+		t.Errorf("ResumeMapReader() - FAIL: read %v", read)
+	}
+}
@@ -0,0 +1,199 @@
+package litecrate
+
+import (
+	"errors"
+	"io"
+)
+
+// Returned by RingCrate.WriteSelfSerializer() when the encoded value is larger than the free space
+var ErrRingFull = errors.New("LiteCrate: value does not fit in free space of RingCrate")
+
+/**************
+	RING CRATE
+***************/
+
+// A RingCrate is a fixed size buffer for long-lived streams: its write index wraps around to the start
+// and its read index chases it, so memory use stays the same however much data passes through.
+// Bytes are added with Write() or Fill() and taken with Read() or WriteTo(), and whole SelfSerializers
+// are added with WriteSelfSerializer() and taken with ReadSelfSerializer(), which (like StreamDecoder.Next())
+// reports an incomplete value instead of panicking.
+//
+// Values are decoded straight from the ring: when the unread bytes wrap past the end they are first moved
+// back to the start, which happens at most once per lap of the ring. A RingCrate is not safe for use by multiple goroutines
+type RingCrate struct {
+	data    []byte
+	head    uint64 // Index of the first unread byte
+	count   uint64 // Number of unread bytes
+	view    Crate  // Reads values from the unread bytes
+	scratch *Crate // Encodes values before they are copied in
+}
+
+// Returns a new RingCrate holding at most capacity bytes
+func NewRingCrate(capacity uint64) *RingCrate {
+	if capacity == 0 {
+		panic("LiteCrate: RingCrate capacity must be greater than 0")
+	}
+	return &RingCrate{data: make([]byte, capacity)}
+}
+
+// Returns the most bytes the ring can hold
+func (r *RingCrate) Cap() uint64 {
+	return len64(r.data)
+}
+
+// Returns the number of unread bytes
+func (r *RingCrate) Available() uint64 {
+	return r.count
+}
+
+// Returns the number of bytes that can be written before the ring is full
+func (r *RingCrate) Free() uint64 {
+	return len64(r.data) - r.count
+}
+
+// Discard every unread byte
+func (r *RingCrate) Reset() {
+	r.head, r.count = 0, 0
+}
+
+// Implements io.Writer.
+// Writes as much of p as fits, returning io.ErrShortWrite if it did not all fit
+func (r *RingCrate) Write(p []byte) (n int, err error) {
+	for n < len(p) && r.Free() > 0 {
+		m := copy(r.freeSpace(), p[n:])
+		r.count += uint64(m)
+		n += m
+	}
+	if n < len(p) {
+		return n, io.ErrShortWrite
+	}
+	return n, nil
+}
+
+// Implements io.Reader.
+// Copies unread bytes into p, returning io.EOF if there are none
+func (r *RingCrate) Read(p []byte) (n int, err error) {
+	if r.count == 0 {
+		if len(p) == 0 {
+			return 0, nil
+		}
+		return 0, io.EOF
+	}
+	for n < len(p) && r.count > 0 {
+		m := copy(p[n:], r.unread())
+		r.consume(uint64(m))
+		n += m
+	}
+	return n, nil
+}
+
+// Read from src once into the free space, returning io.ErrShortBuffer if the ring is full.
+// Returns whatever src.Read() returns, so may return data and io.EOF together
+func (r *RingCrate) Fill(src io.Reader) (n int, err error) {
+	if r.Free() == 0 {
+		return 0, io.ErrShortBuffer
+	}
+	space := r.freeSpace()
+	n, err = src.Read(space)
+	if n < 0 || n > len(space) {
+		panic("LiteCrate: io.Reader returned invalid count from Read()")
+	}
+	r.count += uint64(n)
+	return n, err
+}
+
+// Implements io.WriterTo.
+// Writes every unread byte to w, advancing the read index by the number of bytes written
+func (r *RingCrate) WriteTo(w io.Writer) (n int64, err error) {
+	for r.count > 0 {
+		unread := r.unread()
+		m, err := w.Write(unread)
+		if m < 0 || m > len(unread) {
+			panic("LiteCrate: io.Writer returned invalid count from Write()")
+		}
+		r.consume(uint64(m))
+		n += int64(m)
+		if err != nil {
+			return n, err
+		}
+		if m != len(unread) {
+			return n, io.ErrShortWrite
+		}
+	}
+	return n, nil
+}
+
+// Encode val and add it to the ring, or return ErrRingFull (writing nothing) if it does not fit
+func (r *RingCrate) WriteSelfSerializer(val SelfSerializer) error {
+	if r.scratch == nil {
+		r.scratch = NewCrate(64, FlagAutoDouble)
+	}
+	r.scratch.Reset()
+	r.scratch.WriteSelfSerializer(val)
+	if r.scratch.write > r.Free() {
+		return ErrRingFull
+	}
+	r.Write(r.scratch.Data())
+	return nil
+}
+
+// Decode the next value from the ring into val.
+// Returns false and a nil error if the ring does not hold a whole value yet (val may have been partially filled),
+// or ErrFrameTooLarge if the ring is full and still does not, since the value can never fit.
+// Any other panic while decoding is returned as an error and leaves the value unread
+func (r *RingCrate) ReadSelfSerializer(val SelfSerializer) (ok bool, err error) {
+	if r.count == 0 {
+		return false, nil
+	}
+	if r.head+r.count > len64(r.data) {
+		r.straighten()
+	}
+	r.view.data = r.data[r.head : r.head+r.count : r.head+r.count]
+	r.view.read, r.view.write, r.view.flags = 0, r.count, FlagStatic
+	ok, err = decodeMessage(&r.view, val, r.Cap()-1)
+	if ok {
+		r.consume(r.view.read)
+	}
+	r.view.data = nil
+	return ok, err
+}
+
+// Returns the unread bytes up to the end of the buffer
+func (r *RingCrate) unread() []byte {
+	end := r.head + r.count
+	if end > len64(r.data) {
+		end = len64(r.data)
+	}
+	return r.data[r.head:end]
+}
+
+// Returns the free space from the write index up to the end of the buffer (or the read index)
+func (r *RingCrate) freeSpace() []byte {
+	start := (r.head + r.count) % len64(r.data)
+	if start < r.head {
+		return r.data[start:r.head]
+	}
+	return r.data[start:]
+}
+
+func (r *RingCrate) consume(n uint64) {
+	r.head = (r.head + n) % len64(r.data)
+	r.count -= n
+	if r.count == 0 {
+		r.head = 0
+	}
+}
+
+// Move the unread bytes to the start of the buffer, in place
+func (r *RingCrate) straighten() {
+	reverseBytes(r.data[:r.head])
+	reverseBytes(r.data[r.head:])
+	reverseBytes(r.data)
+	r.head = 0
+}
+
+func reverseBytes(data []byte) {
+	for i, j := 0, len(data)-1; i < j; i, j = i+1, j-1 {
+		data[i], data[j] = data[j], data[i]
+	}
+}
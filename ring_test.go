@@ -0,0 +1,133 @@
+package litecrate_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+func TestRingCrateBytes(t *testing.T) {
+	ring := lite.NewRingCrate(8)
+	if ring.Cap() != 8 || ring.Free() != 8 || ring.Available() != 0 {
+		t.Fatalf("NewRingCrate() - FAIL: cap %d, free %d, available %d", ring.Cap(), ring.Free(), ring.Available())
+	}
+	var sent, received []byte
+	buf := make([]byte, 5)
+	for i := 0; i < 50; i += 1 {
+		chunk := []byte{byte(i), byte(i + 1), byte(i + 2)}
+		if n, err := ring.Write(chunk); err != nil || n != 3 {
+			t.Fatalf("RingCrate.Write() - FAIL: wrote %d, %v", n, err)
+		}
+		sent = append(sent, chunk...)
+		n, _ := ring.Read(buf[:i%5+1])
+		received = append(received, buf[:n]...)
+		if ring.Available()+ring.Free() != 8 {
+			t.Fatalf("RingCrate.Available() - FAIL: %d available + %d free != 8", ring.Available(), ring.Free())
+		}
+		if ring.Free() < 3 {
+			var out bytes.Buffer
+			ring.WriteTo(&out)
+			received = append(received, out.Bytes()...)
+		}
+	}
+	ring.WriteTo(&sliceWriter{&received})
+	if !bytes.Equal(sent, received) {
+		t.Errorf("RingCrate.Read() - FAIL: received bytes differ from sent")
+	}
+	if n, err := ring.Read(buf); n != 0 || err != io.EOF {
+		t.Errorf("RingCrate.Read(empty) - FAIL: %d, %v", n, err)
+	}
+	if n, err := ring.Write(make([]byte, 10)); n != 8 || err != io.ErrShortWrite || ring.Free() != 0 {
+		t.Errorf("RingCrate.Write(overflow) - FAIL: wrote %d, %v", n, err)
+	}
+	if _, err := ring.Fill(bytes.NewReader([]byte{1})); err != io.ErrShortBuffer {
+		t.Errorf("RingCrate.Fill(full) - FAIL: %v", err)
+	}
+	ring.Reset()
+	if n, _ := ring.Fill(bytes.NewReader([]byte{1, 2, 3})); n != 3 || ring.Available() != 3 {
+		t.Errorf("RingCrate.Fill() - FAIL: read %d", n)
+	}
+}
+
+type sliceWriter struct {
+	out *[]byte
+}
+
+func (w *sliceWriter) Write(p []byte) (int, error) {
+	*w.out = append(*w.out, p...)
+	return len(p), nil
+}
+
+func TestRingCrateValues(t *testing.T) {
+	ring := lite.NewRingCrate(64)
+	var sent, got []streamMessage
+	for i := 0; i < 200; i += 1 {
+		msg := streamMessage{ID: uint32(i), Body: "body", Tags: make([]string, i%3)}
+		if err := ring.WriteSelfSerializer(&msg); err == lite.ErrRingFull {
+			// Drain a value to make room, then try again
+			var read streamMessage
+			if ok, err := ring.ReadSelfSerializer(&read); !ok || err != nil {
+				t.Fatalf("RingCrate.ReadSelfSerializer() - FAIL: %v, %v", ok, err)
+			}
+			got = append(got, read)
+			i -= 1
+			continue
+		} else if err != nil {
+			t.Fatalf("RingCrate.WriteSelfSerializer() - FAIL: %v", err)
+		}
+		sent = append(sent, msg)
+	}
+	for {
+		var read streamMessage
+		ok, err := ring.ReadSelfSerializer(&read)
+		if err != nil {
+			t.Fatalf("RingCrate.ReadSelfSerializer() - FAIL: %v", err)
+		}
+		if !ok {
+			break
+		}
+		got = append(got, read)
+	}
+	if len(got) != len(sent) || ring.Available() != 0 {
+		t.Fatalf("RingCrate.ReadSelfSerializer() - FAIL: read %d of %d values, %d bytes left", len(got), len(sent), ring.Available())
+	}
+	for i := range sent {
+		if got[i].ID != sent[i].ID || got[i].Body != sent[i].Body || len(got[i].Tags) != len(sent[i].Tags) {
+			t.Fatalf("RingCrate.ReadSelfSerializer() - FAIL: value %d = %+v, want %+v", i, got[i], sent[i])
+		}
+	}
+}
+
+func TestRingCrateIncomplete(t *testing.T) {
+	crate := lite.NewCrate(32, lite.FlagAutoDouble)
+	msg := streamMessage{ID: 7, Body: "split across writes"}
+	crate.WriteSelfSerializer(&msg)
+	data := crate.Data()
+
+	// Leave the read index near the end of the ring, so the value wraps around
+	ring := lite.NewRingCrate(64)
+	ring.Write(make([]byte, 60))
+	ring.Read(make([]byte, 50))
+	ring.Write(data[:4])
+	ring.Read(make([]byte, 10))
+	var read streamMessage
+	if ok, err := ring.ReadSelfSerializer(&read); ok || err != nil || ring.Available() != 4 {
+		t.Fatalf("RingCrate.ReadSelfSerializer(partial) - FAIL: %v, %v", ok, err)
+	}
+	ring.Write(data[4:])
+	if ok, err := ring.ReadSelfSerializer(&read); !ok || err != nil || read.Body != msg.Body || ring.Available() != 0 {
+		t.Errorf("RingCrate.ReadSelfSerializer(wrapped) - FAIL: %v, %v, %+v", ok, err, read)
+	}
+
+	// A value larger than the ring can never be read
+	small := lite.NewRingCrate(8)
+	small.Write(data[:8])
+	if ok, err := small.ReadSelfSerializer(&read); ok || err != lite.ErrFrameTooLarge {
+		t.Errorf("RingCrate.ReadSelfSerializer(too large) - FAIL: %v, %v", ok, err)
+	}
+	if err := small.WriteSelfSerializer(&msg); err != lite.ErrRingFull || small.Available() != 8 {
+		t.Errorf("RingCrate.WriteSelfSerializer(too large) - FAIL: %v", err)
+	}
+}
@@ -0,0 +1,319 @@
+package litecrate
+
+import (
+	"errors"
+	"unicode/utf8"
+)
+
+// Identifies how a single Field is laid out in a crate
+type FieldKind uint8
+
+const (
+	KindBool    FieldKind = 0  // 1 byte bool
+	KindU8      FieldKind = 1  // 1 byte uint8
+	KindI8      FieldKind = 2  // 1 byte int8
+	KindU16     FieldKind = 3  // 2 byte uint16
+	KindI16     FieldKind = 4  // 2 byte int16
+	KindU24     FieldKind = 5  // 3 byte uint32
+	KindI24     FieldKind = 6  // 3 byte int32
+	KindU32     FieldKind = 7  // 4 byte uint32
+	KindI32     FieldKind = 8  // 4 byte int32
+	KindU40     FieldKind = 9  // 5 byte uint64
+	KindI40     FieldKind = 10 // 5 byte int64
+	KindU48     FieldKind = 11 // 6 byte uint64
+	KindI48     FieldKind = 12 // 6 byte int64
+	KindU56     FieldKind = 13 // 7 byte uint64
+	KindI56     FieldKind = 14 // 7 byte int64
+	KindU64     FieldKind = 15 // 8 byte uint64
+	KindI64     FieldKind = 16 // 8 byte int64
+	KindF32     FieldKind = 17 // 4 byte float32
+	KindF64     FieldKind = 18 // 8 byte float64
+	KindC64     FieldKind = 19 // 8 byte complex64
+	KindC128    FieldKind = 20 // 16 byte complex128
+	KindUVarint FieldKind = 21 // 1-9 byte msb uvarint
+	KindVarint  FieldKind = 22 // 1-9 byte msb zig-zag varint
+	KindString  FieldKind = 23 // string with length-or-nil counter
+	KindBytes   FieldKind = 24 // []byte with length-or-nil counter
+	KindSlice   FieldKind = 25 // length-or-nil counter followed by Elem fields
+	KindMap     FieldKind = 26 // length-or-nil counter followed by Key/Elem field pairs
+	KindStruct  FieldKind = 27 // Fields written inline, one after the other
+)
+
+var kindNames = [...]string{
+	"Bool", "U8", "I8", "U16", "I16", "U24", "I24", "U32", "I32", "U40", "I40", "U48", "I48", "U56", "I56",
+	"U64", "I64", "F32", "F64", "C64", "C128", "UVarint", "Varint", "String", "Bytes", "Slice", "Map", "Struct",
+}
+
+// Returns the name of the kind, matching the suffix of its Use____() method where one exists
+func (k FieldKind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return "Kind(" + intStr(k) + ")"
+}
+
+// Encodes the kind as its name, so schemas stored as JSON are readable
+func (k FieldKind) MarshalText() ([]byte, error) {
+	if int(k) >= len(kindNames) {
+		return nil, errors.New("LiteCrate: unknown field kind " + intStr(k))
+	}
+	return []byte(kindNames[k]), nil
+}
+
+// Decodes a kind from the name returned by String()
+func (k *FieldKind) UnmarshalText(text []byte) error {
+	for i, name := range kindNames {
+		if name == string(text) {
+			*k = FieldKind(i)
+			return nil
+		}
+	}
+	return errors.New("LiteCrate: unknown field kind " + string(text))
+}
+
+// Describes a single value in a crate.
+//
+// Key is only used by KindMap, Elem is used by KindSlice and KindMap,
+// Fields is used by KindStruct (and by KindBytes when the bytes are believed to hold a nested frame)
+type Field struct {
+	Name   string
+	Kind   FieldKind
+	Key    *Field  `json:",omitempty"`
+	Elem   *Field  `json:",omitempty"`
+	Fields []Field `json:",omitempty"`
+}
+
+// Describes the layout of a crate as an ordered list of fields
+type Schema struct {
+	Name   string
+	Fields []Field
+}
+
+// Returns a human readable, indented listing of the schema
+func (s *Schema) String() string {
+	out := "schema " + s.Name + "\n"
+	for i := range s.Fields {
+		out += s.Fields[i].describe("\t")
+	}
+	return out
+}
+
+func (f *Field) describe(indent string) string {
+	out := indent + f.Name + " " + f.Kind.String() + "\n"
+	if f.Key != nil {
+		out += f.Key.describe(indent + "\t")
+	}
+	if f.Elem != nil {
+		out += f.Elem.describe(indent + "\t")
+	}
+	for i := range f.Fields {
+		out += f.Fields[i].describe(indent + "\t")
+	}
+	return out
+}
+
+// Returns the fixed width in bytes of kind, or 0 if its width depends on its value
+func kindWidth(kind FieldKind) uint64 {
+	switch {
+	case kind == KindBool:
+		return 1
+	case kind <= KindI64:
+		return uint64(kind+1) / 2
+	case kind == KindF32:
+		return 4
+	case kind == KindF64, kind == KindC64:
+		return 8
+	case kind == KindC128:
+		return 16
+	}
+	return 0
+}
+
+// Returns the width of an integer kind in bits (64 for varints)
+func kindBits(kind FieldKind) int {
+	if kind == KindUVarint || kind == KindVarint {
+		return 64
+	}
+	return int((kind+1)/2) * 8
+}
+
+func readKindUint(crate *Crate, kind FieldKind) (val uint64) {
+	switch kind {
+	case KindU8:
+		return uint64(crate.ReadU8())
+	case KindU16:
+		return uint64(crate.ReadU16())
+	case KindU24:
+		return uint64(crate.ReadU24())
+	case KindU32:
+		return uint64(crate.ReadU32())
+	case KindU40:
+		return crate.ReadU40()
+	case KindU48:
+		return crate.ReadU48()
+	case KindU56:
+		return crate.ReadU56()
+	case KindU64:
+		return crate.ReadU64()
+	}
+	val, _ = crate.ReadUVarint()
+	return val
+}
+
+func readKindInt(crate *Crate, kind FieldKind) (val int64) {
+	switch kind {
+	case KindI8:
+		return int64(crate.ReadI8())
+	case KindI16:
+		return int64(crate.ReadI16())
+	case KindI24:
+		return int64(crate.ReadI24())
+	case KindI32:
+		return int64(crate.ReadI32())
+	case KindI40:
+		return crate.ReadI40()
+	case KindI48:
+		return crate.ReadI48()
+	case KindI56:
+		return crate.ReadI56()
+	case KindI64:
+		return crate.ReadI64()
+	}
+	val, _ = crate.ReadVarint()
+	return val
+}
+
+func writeKindUint(crate *Crate, kind FieldKind, val uint64) {
+	switch kind {
+	case KindU8:
+		crate.WriteU8(uint8(val))
+	case KindU16:
+		crate.WriteU16(uint16(val))
+	case KindU24:
+		crate.WriteU24(uint32(val))
+	case KindU32:
+		crate.WriteU32(uint32(val))
+	case KindU40:
+		crate.WriteU40(val)
+	case KindU48:
+		crate.WriteU48(val)
+	case KindU56:
+		crate.WriteU56(val)
+	case KindU64:
+		crate.WriteU64(val)
+	default:
+		crate.WriteUVarint(val)
+	}
+}
+
+func writeKindInt(crate *Crate, kind FieldKind, val int64) {
+	switch kind {
+	case KindI8:
+		crate.WriteI8(int8(val))
+	case KindI16:
+		crate.WriteI16(int16(val))
+	case KindI24:
+		crate.WriteI24(int32(val))
+	case KindI32:
+		crate.WriteI32(int32(val))
+	case KindI40:
+		crate.WriteI40(val)
+	case KindI48:
+		crate.WriteI48(val)
+	case KindI56:
+		crate.WriteI56(val)
+	case KindI64:
+		crate.WriteI64(val)
+	default:
+		crate.WriteVarint(val)
+	}
+}
+
+/**************
+	INFERENCE
+***************/
+
+const (
+	inferMaxDepth     = 8 // how many nested frames InferSchema will look inside
+	inferMinStringLen = 2 // shortest run of text InferSchema will call a string
+)
+
+// Heuristically guess the schema of an unknown crate's data.
+//
+// Counter-prefixed runs of printable UTF-8 are reported as KindString,
+// other counter-prefixed runs as KindBytes (with a best-guess of their contents in Fields
+// if they appear to hold a nested frame), multi-byte uvarints as KindUVarint,
+// and anything else as KindU8. The result is only a starting point: fixed-width
+// values and varints are indistinguishable on the wire and WILL be misidentified.
+func InferSchema(data []byte) (*Schema, error) {
+	if len(data) == 0 {
+		return nil, errors.New("LiteCrate: cannot infer schema from empty data")
+	}
+	return &Schema{Name: "Inferred", Fields: inferFields(data, 0)}, nil
+}
+
+func inferFields(data []byte, depth int) (fields []Field) {
+	for i := uint64(0); i < len64(data); {
+		name := "field" + intStr(len(fields))
+		value, n, ok := inferUVarint(data[i:])
+		if ok && value > 1 {
+			length := value - 1
+			if end := i + n + length; end > i+n && end <= len64(data) {
+				payload := data[i+n : end]
+				switch {
+				case length >= inferMinStringLen && isPrintable(payload):
+					fields = append(fields, Field{Name: name, Kind: KindString})
+					i = end
+					continue
+				case length > n:
+					field := Field{Name: name, Kind: KindBytes}
+					if depth < inferMaxDepth && isFramed(payload) {
+						field.Fields = inferFields(payload, depth+1)
+					}
+					fields = append(fields, field)
+					i = end
+					continue
+				}
+			}
+		}
+		if ok && n > 1 {
+			fields = append(fields, Field{Name: name, Kind: KindUVarint})
+			i += n
+			continue
+		}
+		fields = append(fields, Field{Name: name, Kind: KindU8})
+		i += 1
+	}
+	return fields
+}
+
+// Reads a uvarint without panicking, ok is false if data ends before the uvarint does
+func inferUVarint(data []byte) (value uint64, n uint64, ok bool) {
+	longer := true
+	for ; longer && n < 9; n += 1 {
+		if n >= len64(data) {
+			return 0, n, false
+		}
+		longer = data[n]&continueMask == continueMask
+		value |= uint64(data[n]&countMasks[n]) << (n * countShift)
+	}
+	return value, n, true
+}
+
+// Whether data begins with a counter-prefixed run that fits inside it
+func isFramed(data []byte) bool {
+	value, n, ok := inferUVarint(data)
+	return ok && value > 1 && value-1 <= len64(data)-n
+}
+
+func isPrintable(data []byte) bool {
+	if !utf8.Valid(data) {
+		return false
+	}
+	for _, r := range string(data) {
+		if (r < ' ' && r != '\t' && r != '\n' && r != '\r') || r == utf8.RuneError || r == 0x7F {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,41 @@
+package litecrate_test
+
+import (
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+func TestInferSchema(t *testing.T) {
+	crate := lite.NewCrate(64, lite.FlagAutoDouble)
+	crate.WriteStringWithCounter("Derek")
+	crate.WriteUVarint(300)
+	crate.WriteBytesWithCounter([]byte{0, 1, 2, 3, 4, 5})
+	crate.WriteU8(7)
+	schema, err := lite.InferSchema(crate.Data())
+	if err != nil {
+		t.Fatalf("InferSchema - FAIL: %s", err)
+	}
+	want := []lite.FieldKind{lite.KindString, lite.KindUVarint, lite.KindBytes, lite.KindU8}
+	if len(schema.Fields) != len(want) {
+		t.Fatalf("InferSchema - FAIL: %d fields != %d\n%s", len(schema.Fields), len(want), schema)
+	}
+	for i, kind := range want {
+		if schema.Fields[i].Kind != kind {
+			t.Errorf("InferSchema - FAIL: field %d %s != %s", i, schema.Fields[i].Kind, kind)
+		}
+	}
+	if _, err := lite.InferSchema(nil); err == nil {
+		t.Error("InferSchema - FAIL: no error for empty data")
+	}
+}
+
+func FuzzInferSchema(f *testing.F) {
+	f.Add([]byte{6, 'H', 'e', 'l', 'l', 'o', 255, 255, 1})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		schema, err := lite.InferSchema(data)
+		if len(data) > 0 && (err != nil || len(schema.Fields) == 0) {
+			t.Errorf("InferSchema - FAIL: no fields inferred from %v", data)
+		}
+	})
+}
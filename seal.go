@@ -0,0 +1,181 @@
+//go:build !litecrate_core && !litecrate_nocrypto
+
+package litecrate
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	ErrOpenFailed = errors.New("LiteCrate: sealed data failed authentication") // Open() was given the wrong key or header, or tampered data
+	errSealShort  = errors.New("LiteCrate: sealed data is shorter than its nonce and tag")
+)
+
+// Histograms set with SetSealLatency(), each holds a *LatencyHistogram
+var sealLatency, openLatency atomic.Value
+
+// Time every seal (SealWithNonce(), Seal() and writing a SealedCrate) with seal, and every open
+// (Open() and reading a SealedCrate) with open, as Framer times its frames. nil stops timing.
+// Only the encryption is timed, not generating a random nonce
+func SetSealLatency(seal *LatencyHistogram, open *LatencyHistogram) {
+	sealLatency.Store(seal)
+	openLatency.Store(open)
+}
+
+// Returns the histogram stored in v, or nil
+func loadLatency(v *atomic.Value) *LatencyHistogram {
+	h, _ := v.Load().(*LatencyHistogram)
+	return h
+}
+
+// Returns an AES-GCM cipher.AEAD for Seal() and Open(), using AES-128, AES-192 or AES-256
+// depending on whether key is 16, 24 or 32 bytes long.
+//
+// Any other cipher.AEAD can be used in its place, for example ChaCha20-Poly1305 from
+// golang.org/x/crypto/chacha20poly1305 on platforms without AES hardware
+func NewAESGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+/**************
+	ENCRYPTION
+***************/
+
+// Encrypt the crate's written data in place, leaving the first headerLen bytes in plaintext as an
+// authenticated header (so tampering with either is detected by Open()), and resetting the read index.
+//
+// The sealed crate holds header, a random nonce from crypto/rand, then the ciphertext and tag,
+// so it grows by aead.NonceSize()+aead.Overhead() bytes (regardless of flags, like Compress()).
+// Random nonces are safe for about 2^32 messages per key with 12 byte nonces; use SealWithNonce()
+// to supply a counter or other nonce sequence instead.
+// Returns the crate's error without sealing if it has failed under FlagNoPanic
+func (c *Crate) Seal(aead cipher.AEAD, headerLen uint64) error {
+	if c.failed != nil {
+		return c.failed.err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	c.SealWithNonce(aead, headerLen, nonce)
+	return nil
+}
+
+// Seal() with the given nonce, which must be aead.NonceSize() bytes long and must never
+// be reused with the same key. Does nothing if the crate has failed under FlagNoPanic
+func (c *Crate) SealWithNonce(aead cipher.AEAD, headerLen uint64, nonce []byte) {
+	if c.failed != nil {
+		return
+	}
+	defer loadLatency(&sealLatency).ObserveSince(time.Now())
+	if headerLen > c.write {
+		panic("LiteCrate: cannot seal with header of " + intStr(headerLen) + " bytes (written bytes: " + intStr(c.write) + ")")
+	}
+	nonceLen := len64(nonce)
+	need := c.write + nonceLen + uint64(aead.Overhead())
+	if need > len64(c.data) {
+		c.Grow(int(need - len64(c.data)))
+	}
+	body := headerLen + nonceLen
+	copy(c.data[body:], c.data[headerLen:c.write])
+	copy(c.data[headerLen:body], nonce)
+	sealed := aead.Seal(c.data[body:body], nonce, c.data[body:c.write+nonceLen], c.data[:headerLen])
+	write := body + len64(sealed)
+	c.Reset()
+	c.write = write
+}
+
+// Decrypt data sealed by Seal() in place, leaving the header followed by the original
+// data and resetting the read index.
+//
+// Returns ErrOpenFailed and resets the crate if aead's key is wrong or the header or ciphertext
+// have been modified, as the partially decrypted contents cannot be trusted.
+// Returns the crate's error without opening if it has failed under FlagNoPanic
+func (c *Crate) Open(aead cipher.AEAD, headerLen uint64) error {
+	if c.failed != nil {
+		return c.failed.err
+	}
+	defer loadLatency(&openLatency).ObserveSince(time.Now())
+	nonceLen := uint64(aead.NonceSize())
+	body := headerLen + nonceLen
+	if body+uint64(aead.Overhead()) > c.write {
+		return errSealShort
+	}
+	ciphertext := c.data[body:c.write]
+	opened, err := aead.Open(ciphertext[:0], c.data[headerLen:body], ciphertext, c.data[:headerLen])
+	if err != nil {
+		c.Reset()
+		return ErrOpenFailed
+	}
+	copy(c.data[headerLen:], opened)
+	write := headerLen + len64(opened)
+	c.Reset()
+	c.write = write
+	return nil
+}
+
+// A SelfSerializer that stores Crate's written data sealed with AEAD (preceded by a
+// length-or-nil counter), with Header as additional authenticated data that is not stored,
+// for embedding encrypted crates inside other crates. Crate is left unchanged when written.
+//
+// When read, Crate is replaced with a new crate holding the opened data (flagged with FlagDefault),
+// or if Crate is not nil, its contents are replaced instead. Panics with ErrOpenFailed
+// if authentication fails, or if generating a nonce fails
+type SealedCrate struct {
+	Crate  *Crate
+	AEAD   cipher.AEAD
+	Header []byte
+}
+
+func (sc *SealedCrate) UseSelf(crate *Crate, mode UseMode) {
+	switch mode {
+	case Write:
+		nonceLen := sc.AEAD.NonceSize()
+		sealed := make([]byte, nonceLen, nonceLen+int(sc.Crate.write)+sc.AEAD.Overhead())
+		if _, err := io.ReadFull(rand.Reader, sealed); err != nil {
+			panic("LiteCrate: SealedCrate failed to generate nonce: " + err.Error())
+		}
+		start := time.Now()
+		sealed = sc.AEAD.Seal(sealed, sealed, sc.Crate.Data(), sc.Header)
+		loadLatency(&sealLatency).ObserveSince(start)
+		crate.WriteBytesWithCounter(sealed)
+	case Read, Peek:
+		idx := crate.read
+		sealed := crate.SliceBytesWithCounter()
+		nonceLen := sc.AEAD.NonceSize()
+		if len(sealed) < nonceLen+sc.AEAD.Overhead() {
+			panic(errSealShort)
+		}
+		start := time.Now()
+		opened, err := sc.AEAD.Open(nil, sealed[:nonceLen], sealed[nonceLen:], sc.Header)
+		loadLatency(&openLatency).ObserveSince(start)
+		if err != nil {
+			panic(ErrOpenFailed)
+		}
+		crate.DiscardBytesWithCounter()
+		if mode == Peek {
+			crate.read = idx
+		}
+		if sc.Crate == nil {
+			sc.Crate = OpenCrate(opened, FlagDefault)
+			return
+		}
+		sc.Crate.replaceData(opened)
+	case Discard:
+		crate.DiscardBytesWithCounter()
+	case Slice:
+		// Nothing to use, the caller measures the slice using Read
+	default:
+		crate.useCustomMode(sc, mode, "SealedCrate.UseSelf")
+	}
+}
@@ -0,0 +1,141 @@
+//go:build !litecrate_core && !litecrate_nocrypto && !litecrate_nocompress
+
+package litecrate_test
+
+import (
+	"bytes"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+func TestSeal(t *testing.T) {
+	aead, err := lite.NewAESGCM(bytes.Repeat([]byte{7}, 32))
+	if err != nil {
+		t.Fatalf("NewAESGCM() - FAIL: %v", err)
+	}
+	if _, err := lite.NewAESGCM([]byte{1, 2, 3}); err == nil {
+		t.Errorf("NewAESGCM() - FAIL: invalid key size did not error")
+	}
+	overhead := uint64(aead.NonceSize() + aead.Overhead())
+	for _, input := range compressInputs() {
+		for _, headerLen := range []uint64{0, 2} {
+			crate := lite.NewCrate(uint64(len(input))+2, lite.FlagStatic)
+			crate.WriteU16(0xABCD)
+			crate.WriteBytes(input)
+			crate.Grow(-int(crate.SpaceLeft()))
+			plain := append([]byte{}, crate.Data()...)
+			if err := crate.Seal(aead, headerLen); err != nil {
+				t.Fatalf("Seal() - FAIL: %v", err)
+			}
+			if crate.WriteIndex() != uint64(len(plain))+overhead || crate.ReadIndex() != 0 || !bytes.Equal(crate.Data()[:headerLen], plain[:headerLen]) {
+				t.Errorf("Seal(%d bytes) - FAIL: sealed to %d bytes", len(plain), crate.WriteIndex())
+			}
+			if len(input) > 16 && bytes.Contains(crate.Data(), input[:16]) {
+				t.Errorf("Seal(%d bytes) - FAIL: plaintext visible in sealed data", len(plain))
+			}
+			sealed := append([]byte{}, crate.Data()...)
+			if err := crate.Open(aead, headerLen); err != nil || !bytes.Equal(crate.Data(), plain) || crate.ReadIndex() != 0 {
+				t.Errorf("Open(%d bytes) - FAIL: %v", len(plain), err)
+			}
+			for _, flip := range []int{0, len(sealed) - 1} {
+				tampered := lite.OpenCrate(append([]byte{}, sealed...), lite.FlagStatic)
+				tampered.Data()[flip] ^= 1
+				if err := tampered.Open(aead, 2); err != lite.ErrOpenFailed || tampered.WriteIndex() != 0 {
+					t.Errorf("Open(tampered byte %d) - FAIL: %v", flip, err)
+				}
+			}
+		}
+	}
+	crate := lite.OpenCrate([]byte{1, 2, 3}, lite.FlagStatic)
+	if err := crate.Open(aead, 0); err == nil || err == lite.ErrOpenFailed {
+		t.Errorf("Open(short) - FAIL: %v", err)
+	}
+	if catchPanic[error](func() { crate.Seal(aead, 4) }) == nil {
+		t.Errorf("Seal() - FAIL: header longer than data did not panic")
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	first := lite.OpenCrate([]byte("same message"), lite.FlagStatic)
+	second := lite.OpenCrate([]byte("same message"), lite.FlagStatic)
+	first.SealWithNonce(aead, 0, nonce)
+	second.Seal(aead, 0)
+	if !bytes.Equal(first.Data()[:len(nonce)], nonce) || bytes.Equal(first.Data(), second.Data()) {
+		t.Errorf("SealWithNonce() - FAIL: nonce not used or random nonce repeated")
+	}
+}
+
+func TestSealedCrate(t *testing.T) {
+	aead, _ := lite.NewAESGCM(bytes.Repeat([]byte{7}, 16))
+	header := []byte("session 42")
+	inner := lite.NewCrate(8, lite.FlagAutoDouble)
+	inner.WriteStringWithCounter("secret")
+	outer := lite.NewCrate(8, lite.FlagAutoDouble)
+	outer.WriteSelfSerializer(&lite.SealedCrate{Crate: inner, AEAD: aead, Header: header})
+	outer.WriteU8(42)
+	if bytes.Contains(outer.Data(), []byte("secret")) || inner.ReadIndex() != 0 {
+		t.Errorf("SealedCrate(Write) - FAIL: plaintext visible or inner crate modified")
+	}
+	peeked := lite.SealedCrate{AEAD: aead, Header: header}
+	outer.PeekSelfSerializer(&peeked)
+	if outer.ReadIndex() != 0 || !bytes.Equal(peeked.Crate.Data(), inner.Data()) {
+		t.Errorf("SealedCrate(Peek) - FAIL: index %d or data mismatch", outer.ReadIndex())
+	}
+	existing := lite.NewCrate(4, lite.FlagStatic)
+	read := lite.SealedCrate{Crate: existing, AEAD: aead, Header: header}
+	outer.ReadSelfSerializer(&read)
+	if read.Crate != existing || existing.ReadStringWithCounter() != "secret" || outer.ReadU8() != 42 {
+		t.Errorf("SealedCrate(Read) - FAIL: existing crate not refilled")
+	}
+	outer.ResetReadIndex()
+	outer.DiscardSelfSerializer(&read)
+	if outer.ReadU8() != 42 {
+		t.Errorf("SealedCrate(Discard) - FAIL: did not skip sealed data")
+	}
+	outer.ResetReadIndex()
+	wrong := lite.SealedCrate{AEAD: aead, Header: []byte("session 43")}
+	if catchPanic[error](func() { outer.ReadSelfSerializer(&wrong) }) == nil {
+		t.Errorf("SealedCrate(Read) - FAIL: wrong header did not panic")
+	}
+	if catchPanic[error](func() { outer.UseSelfSerializer(&read, lite.UseMode(255)) }) == nil {
+		t.Errorf("SealedCrate.UseSelf - FAIL: invalid mode did not panic")
+	}
+}
+
+func TestSealNoPanic(t *testing.T) {
+	aead, _ := lite.NewAESGCM(bytes.Repeat([]byte{7}, 16))
+	crate := failedCrate()
+	if err := crate.Seal(aead, 0); err != crate.Err() {
+		t.Errorf("Seal() - FAIL: failed crate gave %v", err)
+	}
+	crate.SealWithNonce(aead, 0, make([]byte, aead.NonceSize()))
+	if err := crate.Open(aead, 0); err != crate.Err() {
+		t.Errorf("Open() - FAIL: failed crate gave %v", err)
+	}
+	if crate.ClearErr(); !bytes.Equal(crate.Data(), []byte{7}) {
+		t.Errorf("Seal() - FAIL: failed crate sealed into %v", crate.Data())
+	}
+}
+
+func TestSealLatency(t *testing.T) {
+	aead, _ := lite.NewAESGCM(bytes.Repeat([]byte{7}, 16))
+	seals, opens := &lite.LatencyHistogram{}, &lite.LatencyHistogram{}
+	lite.SetSealLatency(seals, opens)
+	defer lite.SetSealLatency(nil, nil)
+	crate := lite.NewCrate(16, lite.FlagAutoDouble)
+	crate.WriteStringWithCounter("timed")
+	crate.Seal(aead, 0)
+	crate.Open(aead, 0)
+	crate.SealWithNonce(aead, 0, make([]byte, aead.NonceSize()))
+	outer := lite.NewCrate(16, lite.FlagAutoDouble)
+	outer.WriteSelfSerializer(&lite.SealedCrate{Crate: crate, AEAD: aead})
+	outer.ReadSelfSerializer(&lite.SealedCrate{AEAD: aead})
+	if s, o := seals.Snapshot(), opens.Snapshot(); s.Count != 3 || o.Count != 2 {
+		t.Errorf("SetSealLatency() - FAIL: %d seals, %d opens timed", s.Count, o.Count)
+	}
+	lite.SetSealLatency(nil, nil)
+	crate.Seal(aead, 0)
+	if seals.Snapshot().Count != 3 {
+		t.Error("SetSealLatency() - FAIL: timed seal after being unset")
+	}
+}
@@ -0,0 +1,109 @@
+package litecrate
+
+// Bytes BeginSection() reserves for the section's length, enough for sections of up to MaxLength32 bytes
+const sectionLengthBytes = 4
+
+/**************
+	SECTIONS
+***************/
+
+// Begin a length-delimited section: everything written until the matching EndSection()
+// is preceded by its byte length, so readers can skip or slice the section without decoding it
+// (like protobuf embedded messages). Sections may be nested.
+//
+// The length is written in 4 bytes reserved here and filled in by EndSection(), so the section's
+// contents never move and offsets taken inside it (SliceRefs, CrateIndex records) stay correct
+func (c *Crate) BeginSection() {
+	c.beginLength()
+	c.skipWrite(sectionLengthBytes, false)
+	c.sections = append(c.sections, c.write)
+}
+
+// End the section begun by the most recent unmatched BeginSection(), writing its length before it
+// as a length counter padded to 4 bytes (read by ReadLength() like any other). A section longer than
+// MaxLength32 bytes has the rest of its counter inserted before it instead, moving its contents
+func (c *Crate) EndSection() {
+	last := len(c.sections) - 1
+	if last < 0 {
+		panic("LiteCrate: EndSection() called without BeginSection()")
+	}
+	contents := c.sections[last]
+	c.sections = c.sections[:last]
+	if c.failed != nil {
+		return
+	}
+	start := contents - sectionLengthBytes
+	length := c.write - contents
+	if length > MaxLength32 {
+		if !c.makeRoom(contents, findUVarintBytesFromValue(length+1)-sectionLengthBytes) {
+			return
+		}
+		end := c.write
+		c.write = start
+		c.WriteLength(length)
+		c.write = end
+		return
+	}
+	val := length + 1
+	for i := uint64(0); i < sectionLengthBytes; i += 1 {
+		c.data[start+i] = byte(val)&countMask | continueMask
+		val = val >> countShift
+	}
+	c.data[start+sectionLengthBytes-1] &= countMask
+	c.keyOK = false
+}
+
+// Skip the next section without decoding it
+func (c *Crate) DiscardSection() {
+	length, _ := c.ReadLength()
+	c.DiscardN(length)
+}
+
+// Returns the contents of the next section (not including its length) without decoding it
+// or advancing the read index
+func (c *Crate) SliceSection() (slice []byte) {
+	length, n := c.PeekLength()
+	c.CheckRead(n + length)
+	if c.failed != nil {
+		return nil
+	}
+	return c.data[c.read+n : c.read+n+length : c.read+n+length]
+}
+
+// Use a section whose contents are used by useContents according to mode.
+// Wrapping a type's UseSelf() in UseSection() lets Discard and Slice modes skip it without decoding it.
+// Panics if useContents reads a different number of bytes than the section holds,
+// as soon as it tries to read past the section's end (see PushReadLimit())
+//
+// Write = 'write the section into crate', Read = 'read the section',
+// Peek = 'read the section without advancing index'
+// Slice = 'Return the slice the section's contents occupy (not including length)'
+func (c *Crate) UseSection(useContents func(mode UseMode), mode UseMode) (sliceModeData []byte) {
+	switch mode {
+	case Write:
+		c.BeginSection()
+		useContents(Write)
+		c.EndSection()
+	case Read, Peek:
+		idx := c.read
+		length, _ := c.ReadLength()
+		limits := len(c.limits)
+		c.PushReadLimit(length)
+		defer c.restoreReadLimits(limits)
+		useContents(Read)
+		if unread := c.PopReadLimit(); unread != 0 {
+			panic("LiteCrate: section used " + intStr(length-unread) + " bytes but was written with " + intStr(length))
+		}
+		if mode == Peek {
+			c.read = idx
+		}
+	case Discard:
+		c.DiscardSection()
+	case Slice:
+		return c.SliceSection()
+	default:
+		c.useCustomMode(nil, mode, "UseSection")
+		useContents(mode)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package litecrate_test
+
+import (
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+type sectionRecord struct {
+	Name   string
+	Inner  person
+	Scores []uint16
+}
+
+func (r *sectionRecord) UseSelf(crate *lite.Crate, mode lite.UseMode) {
+	crate.UseSection(func(mode lite.UseMode) {
+		crate.UseStringWithCounter(&r.Name, mode)
+		crate.UseSection(func(mode lite.UseMode) { crate.UseSelfSerializer(&r.Inner, mode) }, mode)
+		lite.UseSlice(crate, mode, &r.Scores, crate.UseU16)
+	}, mode)
+}
+
+func TestSection(t *testing.T) {
+	crate := lite.NewCrate(16, lite.FlagAutoDouble)
+	rec := sectionRecord{Name: "outer", Inner: person{Name: "Derek", Age: 30}, Scores: []uint16{1, 2, 3}}
+	crate.WriteSelfSerializer(&rec)
+	crate.WriteU8(42)
+	var got sectionRecord
+	slice := crate.SliceSelfAcecessor(&got)
+	if uint64(len(slice)) != crate.WriteIndex()-1 || crate.ReadIndex() != 0 || got.Name != "" {
+		t.Errorf("UseSection(Slice) - FAIL: slice %d bytes, index %d, decoded %q", len(slice), crate.ReadIndex(), got.Name)
+	}
+	crate.PeekSelfSerializer(&got)
+	if got.Name != "outer" || crate.ReadIndex() != 0 {
+		t.Errorf("UseSection(Peek) - FAIL: %+v", got)
+	}
+	crate.ReadSelfSerializer(&got)
+	if got.Inner.Name != "Derek" || got.Inner.Age != 30 || len(got.Scores) != 3 || crate.ReadU8() != 42 {
+		t.Errorf("UseSection(Read) - FAIL: %+v", got)
+	}
+	crate.ResetReadIndex()
+	crate.DiscardSection()
+	if crate.ReadU8() != 42 {
+		t.Errorf("DiscardSection() - FAIL: did not skip section")
+	}
+
+	crate.Reset()
+	crate.BeginSection()
+	crate.BeginSection()
+	crate.EndSection()
+	crate.WriteU32(7)
+	crate.EndSection()
+	if len(crate.SliceSection()) != 8 || crate.WriteIndex() != 12 {
+		t.Errorf("BeginSection/EndSection() - FAIL: % x", crate.Data())
+	}
+	if catchPanic[error](func() { crate.EndSection() }) == nil {
+		t.Errorf("EndSection() - FAIL: no panic without BeginSection()")
+	}
+
+	crate.Reset()
+	crate.BeginSection()
+	crate.WriteU32(7)
+	crate.EndSection()
+	if catchPanic[error](func() { crate.UseSection(func(mode lite.UseMode) { crate.UseU8(new(uint8), mode) }, lite.Read) }) == nil {
+		t.Errorf("UseSection(Read) - FAIL: short read did not panic")
+	}
+}
+
+func TestSectionBackpatch(t *testing.T) {
+	crate := lite.NewCrate(64, lite.FlagManualExact)
+	crate.BeginSection()
+	crate.WriteU8(1)
+	crate.BeginSection()
+	crate.WriteStringWithCounter("inner")
+	ref := crate.TrackSlice(crate.Data()[crate.WriteIndex()-5:])
+	crate.EndSection()
+	crate.EndSection()
+	if !ref.Valid() || string(ref.Bytes()) != "inner" {
+		t.Error("EndSection() - FAIL: moved the section's contents")
+	}
+	// The lengths are padded to 4 bytes, which every length reader accepts
+	if length, n := crate.ReadLength(); length != 11 || n != 4 {
+		t.Errorf("EndSection() - FAIL: outer length %d in %d bytes", length, n)
+	}
+	crate.ReadU8()
+	if length, _, n := crate.ReadLength32(); length != 6 || n != 4 || crate.ReadStringWithCounter() != "inner" {
+		t.Errorf("EndSection() - FAIL: inner length %d in %d bytes", length, n)
+	}
+}
@@ -0,0 +1,94 @@
+package litecrate
+
+/**************
+	SEEK
+***************/
+
+// Move the read index to offset, backwards or forwards, for random access to fields at known offsets.
+// Panics if offset is past the write index (or the end of a pushed read limit)
+func (c *Crate) SeekRead(offset uint64) {
+	if end := c.readEnd(); offset > end {
+		panic("LiteCrate: cannot seek read index to " + intStr(offset) + ", past write index or read limit " + intStr(end))
+	}
+	c.read = offset
+}
+
+// Move the write index to offset, for formats whose fields are written out of order
+// (such as a header patched once its body is written).
+//
+// Moving forwards grows the buffer as a write would, and if zeroFill is set zeroes the skipped
+// bytes, otherwise they keep whatever the buffer held. Moving backwards leaves the bytes after offset
+// in the buffer, so seeking forwards again with zeroFill false restores them. Moving backwards also moves
+// the read index back to offset if it was past it, and forgets any deduplicated data written since.
+// Panics if the crate cannot grow to offset, or if a read limit is pushed
+func (c *Crate) SeekWrite(offset uint64, zeroFill bool) {
+	if len(c.limits) > 0 {
+		panic("LiteCrate: cannot seek write index while a read limit is pushed")
+	}
+	if offset < c.write {
+		c.keyOK = false
+		c.dedup = nil
+		c.gen += 1
+		c.write = offset
+		if c.read > offset {
+			c.read = offset
+		}
+		return
+	}
+	c.skipWrite(offset-c.write, zeroFill)
+}
+
+/**************
+	ALIGN
+***************/
+
+// Returns how many bytes index must advance to reach a multiple of n. Panics if n is 0
+func alignPadding(index uint64, n uint64) uint64 {
+	if n == 0 {
+		panic("LiteCrate: cannot align to a multiple of 0")
+	}
+	if rem := index % n; rem != 0 {
+		return n - rem
+	}
+	return 0
+}
+
+// Advance the read index to the next multiple of n (counted from the start of the crate),
+// skipping any padding before an aligned field. Returns how many bytes were skipped.
+// Panics if n is 0 or if the padding runs past the write index
+func (c *Crate) AlignRead(n uint64) (bytesSkipped uint64) {
+	bytesSkipped = alignPadding(c.read, n)
+	if bytesSkipped > 0 {
+		c.CheckRead(bytesSkipped)
+		c.read += bytesSkipped
+	}
+	return bytesSkipped
+}
+
+// Advance the write index to the next multiple of n (counted from the start of the crate),
+// padding before an aligned field. The padding is zeroed if zeroFill is set, otherwise it keeps
+// whatever the buffer held. Returns how many bytes were padded.
+// Panics if n is 0 or if the crate cannot grow to fit the padding
+func (c *Crate) AlignWrite(n uint64, zeroFill bool) (bytesPadded uint64) {
+	bytesPadded = alignPadding(c.write, n)
+	c.skipWrite(bytesPadded, zeroFill)
+	return bytesPadded
+}
+
+// Advance the write index n bytes, zeroing them if zeroFill is set
+func (c *Crate) skipWrite(n uint64, zeroFill bool) {
+	if n == 0 {
+		return
+	}
+	c.CheckWrite(n)
+	if c.failed != nil {
+		return
+	}
+	if zeroFill {
+		gap := c.data[c.write : c.write+n]
+		for i := range gap {
+			gap[i] = 0
+		}
+	}
+	c.write += n
+}
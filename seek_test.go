@@ -0,0 +1,87 @@
+package litecrate_test
+
+import (
+	"bytes"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+func TestSeek(t *testing.T) {
+	crate := lite.NewCrate(8, lite.FlagAutoDouble)
+	crate.WriteU16(0)
+	crate.WriteString("body")
+	end := crate.WriteIndex()
+	crate.SeekWrite(0, false)
+	crate.WriteU16(uint16(end))
+	crate.SeekWrite(end, false)
+	if !bytes.Equal(crate.Data(), []byte{6, 0, 'b', 'o', 'd', 'y'}) {
+		t.Errorf("SeekWrite() - FAIL: patched data is %v", crate.Data())
+	}
+
+	crate.SeekRead(2)
+	if crate.ReadU8() != 'b' {
+		t.Error("SeekRead() - FAIL: did not read from offset 2")
+	}
+	crate.SeekRead(0)
+	if crate.ReadU16() != 6 {
+		t.Error("SeekRead() - FAIL: did not read from offset 0")
+	}
+	if catchPanic[error](func() { crate.SeekRead(end + 1) }) == nil || crate.ReadIndex() != 2 {
+		t.Error("SeekRead() - FAIL: seeked past write index")
+	}
+
+	crate.SeekRead(end)
+	crate.SeekWrite(4, false)
+	if crate.ReadIndex() != 4 {
+		t.Errorf("SeekWrite() - FAIL: read index %d left past write index 4", crate.ReadIndex())
+	}
+	crate.SeekWrite(12, true)
+	if !bytes.Equal(crate.Data(), []byte{6, 0, 'b', 'o', 0, 0, 0, 0, 0, 0, 0, 0}) {
+		t.Errorf("SeekWrite() - FAIL: zero filled data is %v", crate.Data())
+	}
+
+	static := lite.NewCrate(4, lite.FlagStatic)
+	if catchPanic[error](func() { static.SeekWrite(5, true) }) == nil || static.WriteIndex() != 0 {
+		t.Error("SeekWrite() - FAIL: seeked past end of static crate")
+	}
+	static.WriteU8(1)
+	static.PushReadLimit(1)
+	if catchPanic[error](func() { static.SeekWrite(0, false) }) == nil {
+		t.Error("SeekWrite() - FAIL: seeked while a read limit was pushed")
+	}
+}
+
+func TestAlign(t *testing.T) {
+	crate := lite.NewCrate(4, lite.FlagAutoDouble)
+	crate.WriteBytes([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF})
+	crate.Reset()
+	crate.WriteU8(1)
+	if padded := crate.AlignWrite(4, true); padded != 3 {
+		t.Errorf("AlignWrite() - FAIL: padded %d bytes, expected 3", padded)
+	}
+	crate.WriteU32(2)
+	if padded := crate.AlignWrite(4, true); padded != 0 {
+		t.Errorf("AlignWrite() - FAIL: padded %d bytes at aligned index, expected 0", padded)
+	}
+	crate.WriteU8(3)
+	crate.AlignWrite(2, false)
+	if !bytes.Equal(crate.Data()[:9], []byte{1, 0, 0, 0, 2, 0, 0, 0, 3}) || crate.WriteIndex() != 10 {
+		t.Errorf("AlignWrite() - FAIL: data is %v", crate.Data())
+	}
+
+	crate.ReadU8()
+	if skipped := crate.AlignRead(4); skipped != 3 || crate.ReadU32() != 2 {
+		t.Errorf("AlignRead() - FAIL: skipped %d bytes, expected 3", skipped)
+	}
+	crate.ReadU8()
+	if crate.AlignRead(2) != 1 || crate.ReadsLeft() != 0 {
+		t.Error("AlignRead() - FAIL: did not skip to end of padding")
+	}
+	if catchPanic[error](func() { crate.AlignRead(8) }) == nil || crate.ReadIndex() != 10 {
+		t.Error("AlignRead() - FAIL: skipped past write index")
+	}
+	if catchPanic[error](func() { crate.AlignWrite(0, true) }) == nil {
+		t.Error("AlignWrite() - FAIL: aligned to 0")
+	}
+}
@@ -0,0 +1,103 @@
+package litecrate
+
+/**************
+	SEQUENCE/ACK
+***************/
+
+// Discard next unread sequence number and ack bitfield in crate
+func (c *Crate) DiscardSeqAck() {
+	c.DiscardN(6)
+}
+
+// Return byte slice the next unread sequence number and ack bitfield occupy
+func (c *Crate) SliceSeqAck() (slice []byte) {
+	return c.SliceBytes(6)
+}
+
+// Write a packet header for a reliability layer to crate as 6 bytes: the U16 sequence number
+// of this packet followed by a U32 bitfield acknowledging received packets (see SeqRecord())
+func (c *Crate) WriteSeqAck(seq uint16, ackBits uint32) {
+	c.WriteU16(seq)
+	c.WriteU32(ackBits)
+}
+
+// Read next 6 bytes from crate as a sequence number and ack bitfield
+func (c *Crate) ReadSeqAck() (seq uint16, ackBits uint32) {
+	return c.ReadU16(), c.ReadU32()
+}
+
+// Read next 6 bytes from crate as a sequence number and ack bitfield without advancing read index
+func (c *Crate) PeekSeqAck() (seq uint16, ackBits uint32) {
+	idx := c.read
+	seq, ackBits = c.ReadSeqAck()
+	c.read = idx
+	return seq, ackBits
+}
+
+// Use the sequence number and ack bitfield pointed to by seq and ackBits according to mode:
+// Write = 'write seq and ackBits into crate', Read = 'read from crate into seq and ackBits',
+// Peek = 'read from crate into seq and ackBits without advancing index'
+// Slice = 'Return the slice the next unread seq and ackBits occupy without altering them'
+func (c *Crate) UseSeqAck(seq *uint16, ackBits *uint32, mode UseMode) (sliceModeData []byte) {
+	switch mode {
+	case Write:
+		c.WriteSeqAck(*seq, *ackBits)
+	case Read:
+		*seq, *ackBits = c.ReadSeqAck()
+	case Peek:
+		*seq, *ackBits = c.PeekSeqAck()
+	case Discard:
+		c.DiscardSeqAck()
+	case Slice:
+		sliceModeData = c.SliceSeqAck()
+	default:
+		c.useCustomMode(seq, mode, "UseSeqAck")
+		c.useCustomMode(ackBits, mode, "UseSeqAck")
+	}
+	return sliceModeData
+}
+
+// Returns whether sequence number a is more recent than b, treating the numbers as wrapping
+// around from 65535 to 0 (so 0 is more recent than 65535, as long as fewer than 32768 packets are in flight)
+func SeqGreater(a uint16, b uint16) bool {
+	return SeqDiff(a, b) > 0
+}
+
+// Returns how many packets sequence number a is after b (negative if a is before b),
+// treating the numbers as wrapping around from 65535 to 0
+func SeqDiff(a uint16, b uint16) int16 {
+	return int16(a - b)
+}
+
+// Record that the packet numbered seq was received, updating *latest (the most recent sequence number received)
+// and *ackBits, where bit n is set if packet latest-1-n was also received. Packets more than 32
+// older than *latest cannot be recorded and are ignored.
+//
+// Send *latest and *ackBits back to the sender (often as the seq and ackBits in UseSeqAck() of the
+// reverse stream's header), which checks each of its unacknowledged packets with SeqAcked()
+func SeqRecord(latest *uint16, ackBits *uint32, seq uint16) {
+	diff := SeqDiff(seq, *latest)
+	switch {
+	case diff > 0:
+		if diff > 32 {
+			*ackBits = 0
+		} else {
+			*ackBits = *ackBits<<diff | 1<<(diff-1)
+		}
+		*latest = seq
+	case diff < 0 && diff >= -32:
+		*ackBits |= 1 << (-diff - 1)
+	}
+}
+
+// Returns whether the packet numbered seq is acknowledged by latest and ackBits (as recorded by SeqRecord())
+func SeqAcked(latest uint16, ackBits uint32, seq uint16) bool {
+	diff := SeqDiff(latest, seq)
+	switch {
+	case diff == 0:
+		return true
+	case diff > 0 && diff <= 32:
+		return ackBits&(1<<(diff-1)) != 0
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package litecrate_test
+
+import (
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+func TestUseSeqAck(t *testing.T) {
+	crate := lite.NewCrate(8, lite.FlagAutoDouble)
+	seq, ackBits := uint16(65535), uint32(0xDEADBEEF)
+	crate.UseSeqAck(&seq, &ackBits, lite.Write)
+	crate.WriteU8(42)
+	var peekedSeq, readSeq uint16
+	var peekedBits, readBits uint32
+	crate.UseSeqAck(&peekedSeq, &peekedBits, lite.Peek)
+	slice := crate.UseSeqAck(&readSeq, &readBits, lite.Slice)
+	crate.UseSeqAck(&readSeq, &readBits, lite.Read)
+	if readSeq != seq || readBits != ackBits || peekedSeq != seq || peekedBits != ackBits || len(slice) != 6 || crate.ReadU8() != 42 {
+		t.Errorf("UseSeqAck(Read) - FAIL: read %d/%x, peeked %d/%x", readSeq, readBits, peekedSeq, peekedBits)
+	}
+	crate.ResetReadIndex()
+	crate.UseSeqAck(&readSeq, &readBits, lite.Discard)
+	if crate.ReadU8() != 42 {
+		t.Errorf("UseSeqAck(Discard) - FAIL: did not skip header")
+	}
+	if catchPanic[error](func() { crate.UseSeqAck(&readSeq, &readBits, lite.UseMode(255)) }) == nil {
+		t.Errorf("UseSeqAck - FAIL: invalid mode did not panic")
+	}
+}
+
+func TestSeqCompare(t *testing.T) {
+	cases := []struct {
+		a, b uint16
+		diff int16
+	}{
+		{1, 0, 1},
+		{0, 65535, 1},
+		{65535, 0, -1},
+		{100, 100, 0},
+		{10, 65500, 46},
+		{32767, 0, 32767},
+	}
+	for _, tc := range cases {
+		if lite.SeqDiff(tc.a, tc.b) != tc.diff || lite.SeqGreater(tc.a, tc.b) != (tc.diff > 0) {
+			t.Errorf("SeqDiff(%d, %d) - FAIL: %d != %d", tc.a, tc.b, lite.SeqDiff(tc.a, tc.b), tc.diff)
+		}
+	}
+}
+
+func TestSeqRecord(t *testing.T) {
+	latest, ackBits := uint16(65530), uint32(0)
+	received := []uint16{65531, 65533, 2, 65534, 1, 65000}
+	for _, seq := range received {
+		lite.SeqRecord(&latest, &ackBits, seq)
+	}
+	if latest != 2 {
+		t.Errorf("SeqRecord() - FAIL: latest %d != 2", latest)
+	}
+	for seq, acked := range map[uint16]bool{65530: true, 65531: true, 65532: false, 65533: true, 65534: true, 65535: false, 0: false, 1: true, 2: true, 3: false, 65000: false} {
+		if lite.SeqAcked(latest, ackBits, seq) != acked {
+			t.Errorf("SeqAcked(%d) - FAIL: expected %v (latest %d, bits %b)", seq, acked, latest, ackBits)
+		}
+	}
+	lite.SeqRecord(&latest, &ackBits, 22)
+	if latest != 22 || !lite.SeqAcked(latest, ackBits, 2) || !lite.SeqAcked(latest, ackBits, 1) || lite.SeqAcked(latest, ackBits, 65532) {
+		t.Errorf("SeqRecord() - FAIL: jump of 20 left latest %d, bits %b", latest, ackBits)
+	}
+	lite.SeqRecord(&latest, &ackBits, 100)
+	if ackBits != 0 {
+		t.Errorf("SeqRecord() - FAIL: jump past window left bits %b", ackBits)
+	}
+}
@@ -0,0 +1,59 @@
+//go:build !litecrate_core && !litecrate_nocrypto
+
+package litecrate
+
+import (
+	"crypto/ed25519"
+	"errors"
+)
+
+var (
+	ErrBadSignature = errors.New("LiteCrate: signature does not match data") // Verify() was given the wrong key, or tampered data
+	errSignShort    = errors.New("LiteCrate: signed data is shorter than its signature")
+)
+
+/**************
+	SIGNATURES
+***************/
+
+// Append an Ed25519 signature of the crate's written data, so it can be handed out as a signed token
+// or stored as a tamper-evident record and checked with Verify(). The crate grows by
+// ed25519.SignatureSize bytes (regardless of flags, like Seal()). Does nothing if the crate has failed
+// under FlagNoPanic, as there is no complete data to sign. Panics if priv is not a valid key
+func (c *Crate) Sign(priv ed25519.PrivateKey) {
+	if c.failed != nil {
+		return
+	}
+	signature := ed25519.Sign(priv, c.data[:c.write])
+	need := c.write + ed25519.SignatureSize
+	if need > len64(c.data) {
+		c.Grow(int(need - len64(c.data)))
+	}
+	copy(c.data[c.write:], signature)
+	c.keyOK = false
+	c.write = need
+}
+
+// Check the signature Sign() appended to the crate's written data, and if it matches
+// remove it, leaving the data that was signed. Returns ErrBadSignature and leaves the crate
+// unchanged if pub is not the signer's key or the data or signature have been modified,
+// or returns its error if the crate has failed under FlagNoPanic
+func (c *Crate) Verify(pub ed25519.PublicKey) error {
+	if c.failed != nil {
+		return c.failed.err
+	}
+	if c.write < ed25519.SignatureSize {
+		return errSignShort
+	}
+	signed := c.write - ed25519.SignatureSize
+	if len(pub) != ed25519.PublicKeySize || !ed25519.Verify(pub, c.data[:signed], c.data[signed:c.write]) {
+		return ErrBadSignature
+	}
+	c.keyOK = false
+	c.gen += 1
+	c.write = signed
+	if c.read > signed {
+		c.read = signed
+	}
+	return nil
+}
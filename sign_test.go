@@ -0,0 +1,72 @@
+//go:build !litecrate_core && !litecrate_nocrypto
+
+package litecrate_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+func TestSign(t *testing.T) {
+	priv := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{3}, ed25519.SeedSize))
+	pub := priv.Public().(ed25519.PublicKey)
+	other := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{4}, ed25519.SeedSize)).Public().(ed25519.PublicKey)
+
+	crate := lite.NewCrate(23, lite.FlagStatic)
+	crate.WriteStringWithCounter("user=42;role=admin")
+	crate.WriteU32(1700000000)
+	original := append([]byte{}, crate.Data()...)
+	crate.Sign(priv)
+	if crate.WriteIndex() != uint64(len(original))+ed25519.SignatureSize {
+		t.Fatalf("Sign() - FAIL: wrote %d bytes, expected %d", crate.WriteIndex(), len(original)+ed25519.SignatureSize)
+	}
+	token := append([]byte{}, crate.Data()...)
+
+	if err := lite.OpenCrate(append([]byte{}, token...), 0).Verify(other); err != lite.ErrBadSignature {
+		t.Errorf("Verify() - FAIL: wrong key gave %v", err)
+	}
+	for _, i := range []int{0, len(original) - 1, len(token) - 1} {
+		tampered := append([]byte{}, token...)
+		tampered[i] ^= 1
+		crate := lite.OpenCrate(tampered, 0)
+		if err := crate.Verify(pub); err != lite.ErrBadSignature || crate.WriteIndex() != uint64(len(token)) {
+			t.Errorf("Verify() - FAIL: byte %d tampered gave %v", i, err)
+		}
+	}
+	if err := lite.OpenCrate(token[:10], 0).Verify(pub); err == nil {
+		t.Error("Verify() - FAIL: accepted data shorter than a signature")
+	}
+
+	received := lite.OpenCrate(token, 0)
+	ref := received.TrackSlice(received.Data())
+	if err := received.Verify(pub); err != nil {
+		t.Fatalf("Verify() - FAIL: %v", err)
+	}
+	if ref.Valid() {
+		t.Error("Verify() - FAIL: SliceRef valid after signature was removed")
+	}
+	if !bytes.Equal(received.Data(), original) {
+		t.Error("Verify() - FAIL: did not leave the signed data")
+	}
+	if received.ReadStringWithCounter() != "user=42;role=admin" || received.ReadU32() != 1700000000 || received.ReadsLeft() != 0 {
+		t.Error("Verify() - FAIL: signed data did not read back")
+	}
+}
+
+func TestSignNoPanic(t *testing.T) {
+	priv := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{3}, ed25519.SeedSize))
+	crate := failedCrate()
+	crate.Sign(priv)
+	if !bytes.Equal(crate.Data(), []byte{7}) {
+		t.Errorf("Sign() - FAIL: signed failed crate into %v", crate.Data())
+	}
+	if err := crate.Verify(priv.Public().(ed25519.PublicKey)); err != crate.Err() {
+		t.Errorf("Verify() - FAIL: failed crate gave %v", err)
+	}
+	if crate.ClearErr(); crate.WriteIndex() != 1 {
+		t.Errorf("Sign() - FAIL: write index %d after clearing", crate.WriteIndex())
+	}
+}
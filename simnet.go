@@ -0,0 +1,206 @@
+//go:build !litecrate_core && !litecrate_nonet
+
+package litecrate
+
+import (
+	"io"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Conditions a SimNetwork applies to every message sent across it, in both directions
+type SimConditions struct {
+	Loss      float64       // Chance (0 to 1) that a message is dropped
+	Duplicate float64       // Chance that a message that is not dropped arrives twice, each copy with its own delay
+	Reorder   float64       // Chance that a message is held back for an extra Latency + Jitter, so later messages overtake it
+	Latency   time.Duration // Delay before every message arrives
+	Jitter    time.Duration // Up to this much extra random delay per message, messages whose delays overlap may arrive out of order
+}
+
+// Counts of what a SimNetwork has done to the messages sent across it
+type SimStats struct {
+	Sent       uint64 // Messages passed to SendCrate()
+	Dropped    uint64 // Messages lost
+	Duplicated uint64 // Extra copies delivered
+	Reordered  uint64 // Messages held back by Reorder
+	Received   uint64 // Messages returned by RecvCrate() or TryRecvCrate()
+}
+
+/**************
+	SIM NETWORK
+***************/
+
+// An in-memory link between two SimTransports that drops, delays, duplicates and reorders messages
+// according to Conditions, for testing reliability layers without a real network.
+//
+// Time on the link is simulated: it only moves when Advance() is called or when RecvCrate()
+// waits for the next message to arrive, so every run with the same seed and the same sequence of calls
+// delivers the same messages in the same order at the same simulated times
+type SimNetwork struct {
+	Conditions SimConditions
+	mutex      sync.Mutex
+	arrived    *sync.Cond // signalled when a message is sent or the network closes
+	rng        *rand.Rand
+	now        time.Duration
+	stats      SimStats
+	closed     bool
+	ends       [2]*SimTransport
+}
+
+// One end of a SimNetwork
+type SimTransport struct {
+	Flags   uint8 // Flags for crates returned by RecvCrate()
+	network *SimNetwork
+	side    int
+	queue   []simMessage // messages in flight to this end, in order of arrival
+}
+
+type simMessage struct {
+	arrival time.Duration
+	data    []byte
+}
+
+// Returns a simulated link with its two ends: crates sent on one are received by the other.
+// seed drives every random choice the link makes
+func NewSimNetwork(seed int64, conditions SimConditions) (network *SimNetwork, a *SimTransport, b *SimTransport) {
+	network = &SimNetwork{Conditions: conditions, rng: rand.New(rand.NewSource(seed))}
+	network.arrived = sync.NewCond(&network.mutex)
+	a = &SimTransport{network: network, side: 0}
+	b = &SimTransport{network: network, side: 1}
+	network.ends = [2]*SimTransport{a, b}
+	return network, a, b
+}
+
+// Returns how much simulated time has passed since the network was made
+func (n *SimNetwork) Now() time.Duration {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	return n.now
+}
+
+// Move simulated time forward by d, letting messages due by then be received
+func (n *SimNetwork) Advance(d time.Duration) {
+	if d < 0 {
+		panic("LiteCrate: cannot advance simulated time by negative duration " + intStr(int64(d)))
+	}
+	n.mutex.Lock()
+	n.now += d
+	n.mutex.Unlock()
+}
+
+// Returns how many messages are in flight to either end
+func (n *SimNetwork) InFlight() int {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	return len(n.ends[0].queue) + len(n.ends[1].queue)
+}
+
+// Returns counts of what the network has done so far
+func (n *SimNetwork) Stats() SimStats {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	return n.stats
+}
+
+// Must hold mutex
+func (n *SimNetwork) delay() time.Duration {
+	delay := n.Conditions.Latency
+	if n.Conditions.Jitter > 0 {
+		delay += time.Duration(n.rng.Int63n(int64(n.Conditions.Jitter) + 1))
+	}
+	if n.Conditions.Reorder > 0 && n.rng.Float64() < n.Conditions.Reorder {
+		delay += n.Conditions.Latency + n.Conditions.Jitter
+		n.stats.Reordered += 1
+	}
+	return delay
+}
+
+// Queue data to arrive at this end, after any messages arriving at the same time. Must hold mutex
+func (t *SimTransport) deliver(data []byte, arrival time.Duration) {
+	message := simMessage{arrival: arrival, data: data}
+	i := sort.Search(len(t.queue), func(i int) bool { return t.queue[i].arrival > arrival })
+	t.queue = append(t.queue, simMessage{})
+	copy(t.queue[i+1:], t.queue[i:])
+	t.queue[i] = message
+}
+
+// Send a copy of the crate's written data to the other end, subject to the network's conditions.
+// Never blocks. Returns io.ErrClosedPipe if the network is closed
+func (t *SimTransport) SendCrate(crate *Crate) error {
+	n := t.network
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	if n.closed {
+		return io.ErrClosedPipe
+	}
+	n.stats.Sent += 1
+	if n.Conditions.Loss > 0 && n.rng.Float64() < n.Conditions.Loss {
+		n.stats.Dropped += 1
+		return nil
+	}
+	data := append([]byte{}, crate.Data()...)
+	peer := n.ends[1-t.side]
+	peer.deliver(data, n.now+n.delay())
+	if n.Conditions.Duplicate > 0 && n.rng.Float64() < n.Conditions.Duplicate {
+		peer.deliver(append([]byte{}, data...), n.now+n.delay())
+		n.stats.Duplicated += 1
+	}
+	n.arrived.Broadcast()
+	return nil
+}
+
+// Receive the next message to arrive. If none has arrived but one is in flight,
+// simulated time jumps forward to its arrival. If nothing is in flight, blocks until
+// a message is sent. Returns io.EOF once the network is closed
+func (t *SimTransport) RecvCrate() (*Crate, error) {
+	n := t.network
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	for !n.closed && len(t.queue) == 0 {
+		n.arrived.Wait()
+	}
+	if n.closed {
+		return nil, io.EOF
+	}
+	if t.queue[0].arrival > n.now {
+		n.now = t.queue[0].arrival
+	}
+	return t.pop(), nil
+}
+
+// Receive the next message that has arrived without moving simulated time or blocking,
+// ok is false if none has. Returns io.EOF if the network is closed
+func (t *SimTransport) TryRecvCrate() (crate *Crate, ok bool, err error) {
+	n := t.network
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	if n.closed {
+		return nil, false, io.EOF
+	}
+	if len(t.queue) == 0 || t.queue[0].arrival > n.now {
+		return nil, false, nil
+	}
+	return t.pop(), true, nil
+}
+
+// Must hold mutex
+func (t *SimTransport) pop() *Crate {
+	message := t.queue[0]
+	t.queue[0] = simMessage{}
+	t.queue = t.queue[1:]
+	t.network.stats.Received += 1
+	return OpenCrate(message.data, t.Flags)
+}
+
+// Close the network, dropping any messages in flight. Closing either end closes both
+func (t *SimTransport) Close() error {
+	n := t.network
+	n.mutex.Lock()
+	n.closed = true
+	n.ends[0].queue, n.ends[1].queue = nil, nil
+	n.mutex.Unlock()
+	n.arrived.Broadcast()
+	return nil
+}
@@ -0,0 +1,92 @@
+package litecrate
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Implemented by types that know roughly how many bytes they encode to,
+// usually emitted by code generators
+type SizeHinter interface {
+	EncodedSizeHint() uint64
+}
+
+var typeSizes struct {
+	sync.RWMutex
+	sizes map[reflect.Type]uint64
+}
+
+/**************
+	SIZE HINTS
+***************/
+
+// Register the initial crate size used by NewCrateFor() for values of type T (or *T),
+// overriding T's EncodedSizeHint() method if it has one
+func RegisterTypeSize[T any](size uint64) {
+	typeSizes.Lock()
+	defer typeSizes.Unlock()
+	if typeSizes.sizes == nil {
+		typeSizes.sizes = make(map[reflect.Type]uint64)
+	}
+	typeSizes.sizes[reflect.TypeOf((*T)(nil)).Elem()] = size
+}
+
+// Returns the size registered for val's type with RegisterTypeSize(),
+// or else the result of val's EncodedSizeHint() method, or else 64
+func SizeHint(val any) uint64 {
+	t := reflect.TypeOf(val)
+	typeSizes.RLock()
+	size, ok := typeSizes.sizes[t]
+	if !ok && t != nil && t.Kind() == reflect.Pointer {
+		size, ok = typeSizes.sizes[t.Elem()]
+	}
+	typeSizes.RUnlock()
+	if ok {
+		return size
+	}
+	if hinter, ok := val.(SizeHinter); ok {
+		return hinter.EncodedSizeHint()
+	}
+	return defaultCrateSize
+}
+
+// Create a new crate sized to hold val (see SizeHint()) with the specified option flags
+func NewCrateFor(val any, flags uint8) *Crate {
+	return NewCrate(SizeHint(val), flags)
+}
+
+// Get a crate from the pool sized to hold val (see SizeHint()) with the specified option flags
+func (p *CratePool) GetFor(val any, flags uint8) *Crate {
+	return p.Get(SizeHint(val), flags)
+}
+
+/**************
+	EXACT SIZES
+***************/
+
+// Scratch crates SizeOf() and HashSelfSerializer() encode values into
+var scratchCrates CratePool
+
+// Largest scratch crate returned to scratchCrates, so measuring or hashing one huge value
+// does not pin its buffer in the pool for good
+const maxScratchSize = 64 << 10
+
+// Return crate to scratchCrates, unless it grew past maxScratchSize (it is then left to the garbage collector)
+func putScratch(crate *Crate) {
+	if len64(crate.data) <= maxScratchSize {
+		scratchCrates.Put(crate)
+	}
+}
+
+// Returns exactly how many bytes val encodes to in a crate with the given option flags, so the crate
+// it is written to can be created with NewCrate(SizeOf(val, flags), flags) and never needs to grow.
+// Only FlagTaggedFields and FlagSortedMaps change the size, the growth and trace flags are ignored.
+//
+// val is measured by writing it to a pooled scratch crate, so costs about as much as writing it.
+// The scratch crate has no string table or blob resolver, so values that use them may measure differently
+func SizeOf(val SelfSerializer, flags uint8) uint64 {
+	crate := scratchCrates.Get(defaultCrateSize, flags&(FlagTaggedFields|FlagSortedMaps))
+	defer putScratch(crate)
+	crate.WriteSelfSerializer(val)
+	return crate.write
+}
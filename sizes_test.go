@@ -0,0 +1,60 @@
+package litecrate_test
+
+import (
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+type hintedMessage struct {
+	Body string
+}
+
+func (m *hintedMessage) EncodedSizeHint() uint64 {
+	return 200
+}
+
+type registeredMessage struct {
+	Body string
+}
+
+func TestSizeHint(t *testing.T) {
+	if size := lite.SizeHint(&registeredMessage{}); size != 64 {
+		t.Errorf("SizeHint() - FAIL: unknown type hinted %d != 64", size)
+	}
+	if size := lite.SizeHint(&hintedMessage{}); size != 200 {
+		t.Errorf("SizeHint() - FAIL: EncodedSizeHint() type hinted %d != 200", size)
+	}
+	lite.RegisterTypeSize[registeredMessage](1000)
+	lite.RegisterTypeSize[*hintedMessage](300)
+	if size := lite.SizeHint(&registeredMessage{}); size != 1000 {
+		t.Errorf("SizeHint() - FAIL: registered type hinted %d != 1000", size)
+	}
+	if size := lite.SizeHint(&hintedMessage{}); size != 300 {
+		t.Errorf("SizeHint() - FAIL: registered size did not override EncodedSizeHint(), hinted %d", size)
+	}
+	if crate := lite.NewCrateFor(registeredMessage{}, lite.FlagStatic); crate.SpaceLeft() != 1000 {
+		t.Errorf("NewCrateFor() - FAIL: %d bytes != 1000", crate.SpaceLeft())
+	}
+	if crate := lite.NewCratePool().GetFor(&registeredMessage{}, lite.FlagStatic); crate.SpaceLeft() < 1000 {
+		t.Errorf("CratePool.GetFor() - FAIL: %d bytes < 1000", crate.SpaceLeft())
+	}
+	if size := lite.SizeHint(nil); size != 64 {
+		t.Errorf("SizeHint() - FAIL: nil hinted %d != 64", size)
+	}
+}
+
+func TestSizeOf(t *testing.T) {
+	account := &accountV2{ID: 300, Name: "ledger", Balance: -70000, Friends: []accountV1{{1, "a"}, {2, "bb"}, {1 << 40, "ccc"}}}
+	for _, flags := range []uint8{lite.FlagDefault, lite.FlagTaggedFields, lite.FlagStatic | lite.FlagTrace} {
+		size := lite.SizeOf(account, flags)
+		crate := lite.NewCrate(size, flags|lite.FlagNoGrow)
+		crate.WriteSelfSerializer(account)
+		if crate.WriteIndex() != size || crate.SpaceLeft() != 0 || crate.GrowCount() != 0 {
+			t.Errorf("SizeOf() - FAIL: measured %d bytes with flags %d, wrote %d", size, flags, crate.WriteIndex())
+		}
+	}
+	if lite.SizeOf(account, lite.FlagTaggedFields) <= lite.SizeOf(account, lite.FlagDefault) {
+		t.Error("SizeOf() - FAIL: tagged fields did not add to size")
+	}
+}
@@ -0,0 +1,57 @@
+package litecrate
+
+/**************
+	SLICE REFS
+***************/
+
+// A slice of a crate's buffer (as returned by Slice mode, Data() or SliceBytes()) that knows whether
+// it still shows what the crate holds. Holding a plain slice across writes is only safe while the
+// buffer stays where it is: once the crate grows into a new array, is Reset(), or has its write index
+// moved back over the slice's bytes, the slice silently shows stale or soon to be overwritten data.
+// A SliceRef detects this by remembering the crate's Generation() when it was made
+type SliceRef struct {
+	crate *Crate
+	gen   uint64
+	data  []byte
+}
+
+// Returns a count that changes whenever slices of the crate's buffer may stop showing its data:
+// when the buffer is reallocated by Grow(), on Reset(), and when the write index moves backwards
+// (SetWriteIndex(), SeekWrite(), RollbackWrite(), Verify(), shrinking with Grow()) or bytes are inserted before
+// already written ones (a string table by UseWithStringTable(), a length by EndSection() for sections over MaxLength32 bytes)
+func (c *Crate) Generation() uint64 {
+	return c.gen
+}
+
+// Wrap slice, which must have just been taken from the crate's buffer, so its validity can be checked later.
+//
+// Example:
+//
+//	name := crate.TrackSlice(crate.SliceStringWithCounter())
+//	crate.WriteBytes(more)
+//	if name.Valid() {
+//		use(name.Bytes())
+//	}
+func (c *Crate) TrackSlice(slice []byte) SliceRef {
+	return SliceRef{crate: c, gen: c.gen, data: slice}
+}
+
+// Returns whether the slice still shows the crate's data, because nothing that could move or
+// overwrite it has happened since it was tracked. Always false for the zero SliceRef
+func (r SliceRef) Valid() bool {
+	return r.crate != nil && r.crate.gen == r.gen
+}
+
+// Returns the slice. Panics if it is no longer Valid(), rather than returning bytes the crate
+// has moved or may overwrite
+func (r SliceRef) Bytes() []byte {
+	if !r.Valid() {
+		panic("LiteCrate: SliceRef used after its crate's buffer was reallocated, reset or rewound")
+	}
+	return r.data
+}
+
+// Returns the slice whether or not it is still valid
+func (r SliceRef) Unchecked() []byte {
+	return r.data
+}
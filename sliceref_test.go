@@ -0,0 +1,75 @@
+package litecrate_test
+
+import (
+	"bytes"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+// Tracks the bytes its ID was written to
+type trackedRecord struct {
+	ID  uint32
+	ref lite.SliceRef
+}
+
+func (r *trackedRecord) UseSelf(crate *lite.Crate, mode lite.UseMode) {
+	start := crate.WriteIndex()
+	crate.UseU32(&r.ID, mode)
+	if mode == lite.Write {
+		r.ref = crate.TrackSlice(crate.Data()[start:])
+	}
+}
+
+func TestSliceRef(t *testing.T) {
+	crate := lite.NewCrate(16, lite.FlagManualExact)
+	crate.WriteStringWithCounter("abc")
+	name := crate.TrackSlice(crate.SliceStringWithCounter())
+	crate.WriteU32(7)
+	if !name.Valid() || !bytes.Equal(name.Bytes(), []byte("abc")) {
+		t.Error("SliceRef - FAIL: invalid after write that fit in the buffer")
+	}
+	crate.SeekWrite(crate.WriteIndex()+1, true)
+	if !name.Valid() {
+		t.Error("SliceRef - FAIL: invalid after seeking forward")
+	}
+	generation := crate.Generation()
+	crate.Grow(8)
+	if name.Valid() || crate.Generation() == generation {
+		t.Error("SliceRef - FAIL: valid after buffer was reallocated")
+	}
+	if catchPanic[error](func() { name.Bytes() }) == nil || !bytes.Equal(name.Unchecked(), []byte("abc")) {
+		t.Error("SliceRef.Bytes() - FAIL: did not panic once invalid")
+	}
+
+	rewinds := map[string]func(crate *lite.Crate){
+		"Reset()":         func(crate *lite.Crate) { crate.Reset() },
+		"SeekWrite()":     func(crate *lite.Crate) { crate.SeekWrite(1, false) },
+		"SetWriteIndex()": func(crate *lite.Crate) { crate.SetWriteIndex(2) },
+		"RollbackWrite()": func(crate *lite.Crate) {
+			crate.BeginWrite()
+			crate.WriteU8(1)
+			crate.RollbackWrite()
+		},
+	}
+	for name, rewind := range rewinds {
+		crate := lite.NewCrate(16, lite.FlagAutoDouble)
+		crate.WriteU32(1)
+		ref := crate.TrackSlice(crate.Data())
+		rewind(crate)
+		if ref.Valid() {
+			t.Errorf("SliceRef - FAIL: valid after %s", name)
+		}
+	}
+
+	// The string table is inserted before the record once it is written, moving its bytes
+	inserted := lite.NewCrate(64, lite.FlagManualExact)
+	record := trackedRecord{ID: 9}
+	inserted.UseWithStringTable(&record, lite.Write)
+	if record.ref.Valid() {
+		t.Error("SliceRef - FAIL: valid after UseWithStringTable() inserted its table")
+	}
+	if (lite.SliceRef{}).Valid() {
+		t.Error("SliceRef - FAIL: zero SliceRef is valid")
+	}
+}
@@ -0,0 +1,160 @@
+//go:build !litecrate_core && !litecrate_nocompress
+
+package litecrate
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// Snappy block format: a standard (LEB128) uvarint of the decompressed length, followed by
+// elements whose tag byte's low 2 bits select a literal (0) or a copy with a 1 (1), 2 (2),
+// or 4 (3) byte offset back into the decompressed output.
+// See https://github.com/google/snappy/blob/main/format_description.txt
+const (
+	snappyTagLiteral = 0
+	snappyTagCopy1   = 1
+	snappyTagCopy2   = 2
+	snappyTagCopy4   = 3
+
+	snappyHashBits   = 14
+	snappyMaxOffset  = 1<<16 - 1
+	snappyMaxExpand  = 22 // No element decompresses to more than 22x its own size
+	snappyMaxDecoded = 1<<32 - 1
+)
+
+var errCorruptSnappy = errors.New("LiteCrate: corrupt snappy data")
+
+// Compresses with the snappy block format (not the framed stream format),
+// which is fast but compresses less than gzip or zlib
+type SnappyCompressor struct{}
+
+func (SnappyCompressor) Compress(src []byte) (compressed []byte, err error) {
+	if len64(src) > snappyMaxDecoded {
+		return nil, errors.New("LiteCrate: data too large for snappy block format")
+	}
+	dst := make([]byte, binary.MaxVarintLen64, binary.MaxVarintLen64+len(src)+len(src)/6+8)
+	dst = dst[:binary.PutUvarint(dst, uint64(len(src)))]
+	var table [1 << snappyHashBits]int
+	literalStart := 0
+	for i := 0; i+4 <= len(src); {
+		val := binary.LittleEndian.Uint32(src[i:])
+		hash := (val * 0x1e35a7bd) >> (32 - snappyHashBits)
+		candidate := table[hash] - 1
+		table[hash] = i + 1
+		if candidate < 0 || i-candidate > snappyMaxOffset || binary.LittleEndian.Uint32(src[candidate:]) != val {
+			i += 1
+			continue
+		}
+		length := 4
+		for i+length < len(src) && src[candidate+length] == src[i+length] {
+			length += 1
+		}
+		dst = snappyAppendLiteral(dst, src[literalStart:i])
+		dst = snappyAppendCopy(dst, i-candidate, length)
+		i += length
+		literalStart = i
+	}
+	return snappyAppendLiteral(dst, src[literalStart:]), nil
+}
+
+func (SnappyCompressor) Decompress(src []byte) (decompressed []byte, err error) {
+	length, n := binary.Uvarint(src)
+	if n <= 0 || length > snappyMaxDecoded || length > len64(src)*snappyMaxExpand {
+		return nil, errCorruptSnappy
+	}
+	dst := make([]byte, 0, length)
+	for s := n; s < len(src); {
+		tag := src[s]
+		var elemLen, offset, headerLen int
+		switch tag & 3 {
+		case snappyTagLiteral:
+			elemLen = int(tag >> 2)
+			headerLen = 1
+			if elemLen >= 60 {
+				headerLen += elemLen - 59
+				if s+headerLen > len(src) {
+					return nil, errCorruptSnappy
+				}
+				elemLen = 0
+				for i := 1; i < headerLen; i += 1 {
+					elemLen |= int(src[s+i]) << (8 * (i - 1))
+				}
+			}
+			elemLen += 1
+			s += headerLen
+			if elemLen <= 0 || elemLen > len(src)-s || uint64(len(dst)+elemLen) > length {
+				return nil, errCorruptSnappy
+			}
+			dst = append(dst, src[s:s+elemLen]...)
+			s += elemLen
+			continue
+		case snappyTagCopy1:
+			if s+2 > len(src) {
+				return nil, errCorruptSnappy
+			}
+			elemLen = int(tag>>2&7) + 4
+			offset = int(tag>>5)<<8 | int(src[s+1])
+			s += 2
+		case snappyTagCopy2:
+			if s+3 > len(src) {
+				return nil, errCorruptSnappy
+			}
+			elemLen = int(tag>>2) + 1
+			offset = int(binary.LittleEndian.Uint16(src[s+1:]))
+			s += 3
+		case snappyTagCopy4:
+			if s+5 > len(src) {
+				return nil, errCorruptSnappy
+			}
+			elemLen = int(tag>>2) + 1
+			offset = int(binary.LittleEndian.Uint32(src[s+1:]))
+			s += 5
+		}
+		if offset <= 0 || offset > len(dst) || uint64(len(dst)+elemLen) > length {
+			return nil, errCorruptSnappy
+		}
+		for i := 0; i < elemLen; i += 1 {
+			dst = append(dst, dst[len(dst)-offset])
+		}
+	}
+	if len64(dst) != length {
+		return nil, errCorruptSnappy
+	}
+	return dst, nil
+}
+
+func snappyAppendLiteral(dst []byte, literal []byte) []byte {
+	n := len(literal) - 1
+	switch {
+	case n < 0:
+		return dst
+	case n < 60:
+		dst = append(dst, byte(n)<<2|snappyTagLiteral)
+	case n < 1<<8:
+		dst = append(dst, 60<<2|snappyTagLiteral, byte(n))
+	case n < 1<<16:
+		dst = append(dst, 61<<2|snappyTagLiteral, byte(n), byte(n>>8))
+	case n < 1<<24:
+		dst = append(dst, 62<<2|snappyTagLiteral, byte(n), byte(n>>8), byte(n>>16))
+	default:
+		dst = append(dst, 63<<2|snappyTagLiteral, byte(n), byte(n>>8), byte(n>>16), byte(n>>24))
+	}
+	return append(dst, literal...)
+}
+
+// Appends copies of at most 64 bytes each, never leaving a remainder under 4 bytes
+func snappyAppendCopy(dst []byte, offset int, length int) []byte {
+	for length >= 68 {
+		dst = append(dst, 63<<2|snappyTagCopy2, byte(offset), byte(offset>>8))
+		length -= 64
+	}
+	if length > 64 {
+		dst = append(dst, 59<<2|snappyTagCopy2, byte(offset), byte(offset>>8))
+		length -= 60
+	}
+	if length >= 12 || offset >= 2048 {
+		return append(dst, byte(length-1)<<2|snappyTagCopy2, byte(offset), byte(offset>>8))
+	}
+	return append(dst, byte(offset>>8)<<5|byte(length-4)<<2|snappyTagCopy1, byte(offset))
+}
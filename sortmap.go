@@ -0,0 +1,130 @@
+package litecrate
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+)
+
+// Reusable scratch space for writing maps with FlagSortedMaps, kept by the crate between writes
+// so that sorting keys does not allocate once the crate has written a map of the same size
+type mapSorter struct {
+	keys    any    // *[]K holding the keys of the last map sorted, reused if the next has the same key type
+	encoded []byte // Every key's encoding, one after the other
+	ends    []int  // End of each key's encoding in encoded
+	order   []int  // Indexes of the keys, sorted by their encoding
+}
+
+func (s *mapSorter) Len() int {
+	return len(s.order)
+}
+
+func (s *mapSorter) Less(i, j int) bool {
+	return bytes.Compare(s.encoding(s.order[i]), s.encoding(s.order[j])) < 0
+}
+
+func (s *mapSorter) Swap(i, j int) {
+	s.order[i], s.order[j] = s.order[j], s.order[i]
+}
+
+func (s *mapSorter) encoding(i int) []byte {
+	start := 0
+	if i > 0 {
+		start = s.ends[i-1]
+	}
+	return s.encoded[start:s.ends[i]]
+}
+
+// Write the entries of m in order of the bytes useKeyFunc writes for their keys. Each key is first written
+// to scratch space with appendEncoding(), to find its encoding. Interned keys new to the string table are
+// then removed from it again, to be added in the order they are written. at is kept pointing at the entry being written
+func writeSortedMap[K comparable, V any](crate *Crate, m map[K]V, useKeyFunc UseFunc[K], useValFunc UseFunc[V], at *elementPath) {
+	// The sorter is taken from the crate while in use, so maps nested inside the values get their own
+	s := crate.sorter
+	crate.sorter = nil
+	if s == nil {
+		s = new(mapSorter)
+	}
+	keys, ok := s.keys.(*[]K)
+	if !ok {
+		keys = new([]K)
+		s.keys = keys
+	}
+	*keys, s.encoded, s.ends, s.order = (*keys)[:0], s.encoded[:0], s.ends[:0], s.order[:0]
+	var key K
+	var val V
+	interned := crate.strings.Len()
+	for k := range m {
+		key = k
+		*keys = append(*keys, k)
+		s.encoded = crate.appendEncoding(s.encoded, func() { useKeyFunc(&key, Write) })
+		s.ends = append(s.ends, len(s.encoded))
+		s.order = append(s.order, len(s.order))
+	}
+	crate.strings.truncate(interned)
+	sort.Sort(s)
+	at.key, at.keyOK, at.active = &key, true, true
+	for n, i := range s.order {
+		key, val = (*keys)[i], m[(*keys)[i]]
+		at.index = uint64(n)
+		useKeyFunc(&key, Write)
+		useValFunc(&val, Write)
+	}
+	at.active = false
+	// Let the keys be collected while the scratch space waits for the next map
+	var zero K
+	for i := range *keys {
+		(*keys)[i] = zero
+	}
+	crate.sorter = s
+}
+
+// Returns the keys of map v (as addressable values) sorted by the bytes UseAny() writes for them,
+// leaving the string table as it was (see writeSortedMap())
+func (c *Crate) sortedMapValueKeys(v reflect.Value) []reflect.Value {
+	keys := make([]reflect.Value, 0, v.Len())
+	encoded := make([][]byte, 0, v.Len())
+	interned := c.strings.Len()
+	defer c.strings.truncate(interned)
+	for iter := v.MapRange(); iter.Next(); {
+		key := reflect.New(v.Type().Key()).Elem()
+		key.Set(iter.Key())
+		keys = append(keys, key)
+		encoded = append(encoded, c.appendEncoding(nil, func() { c.writeValue(key) }))
+	}
+	sort.Sort(byEncoding[reflect.Value]{keys, encoded})
+	return keys
+}
+
+// Runs write with the crate swapped for a bare crate that appends to buf, returning what it appended to.
+// Only the string table and the flags that change the encoding are kept, so the crate's data, indexes,
+// generation, deduplicated bytes and the rest are left as they were
+func (c *Crate) appendEncoding(buf []byte, write func()) (encoded []byte) {
+	saved := *c
+	*c = Crate{data: buf[:cap(buf)], write: len64(buf), flags: saved.flags & (FlagTaggedFields | FlagSortedMaps), strings: saved.strings}
+	defer func() {
+		encoded = c.data[:c.write]
+		*c = saved
+	}()
+	write()
+	return encoded
+}
+
+// Sorts keys by their encoded bytes
+type byEncoding[K any] struct {
+	keys    []K
+	encoded [][]byte
+}
+
+func (b byEncoding[K]) Len() int {
+	return len(b.keys)
+}
+
+func (b byEncoding[K]) Less(i, j int) bool {
+	return bytes.Compare(b.encoded[i], b.encoded[j]) < 0
+}
+
+func (b byEncoding[K]) Swap(i, j int) {
+	b.keys[i], b.keys[j] = b.keys[j], b.keys[i]
+	b.encoded[i], b.encoded[j] = b.encoded[j], b.encoded[i]
+}
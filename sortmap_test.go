@@ -0,0 +1,154 @@
+package litecrate_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+func sortedMapInput(n int) map[string]uint16 {
+	m := make(map[string]uint16, n)
+	for i := 0; i < n; i += 1 {
+		m[string(rune('a'+i%26))+string(rune('A'+i/26))] = uint16(i)
+	}
+	return m
+}
+
+func TestSortedMaps(t *testing.T) {
+	var first []byte
+	for i := 0; i < 20; i += 1 {
+		crate := lite.NewCrate(8, lite.FlagAutoDouble|lite.FlagSortedMaps)
+		m := sortedMapInput(100)
+		lite.UseMap(crate, lite.Write, &m, crate.UseStringWithCounter, crate.UseU16)
+		if first == nil {
+			first = crate.Data()
+			continue
+		}
+		if !bytes.Equal(first, crate.Data()) {
+			t.Fatalf("UseMap(FlagSortedMaps) - FAIL: identical maps wrote different bytes")
+		}
+	}
+	crate := lite.OpenCrate(first, lite.FlagStatic)
+	if length, _, _ := crate.ReadLengthOrNil(); length != 100 {
+		t.Fatalf("UseMap(FlagSortedMaps) - FAIL: wrote %d entries", length)
+	}
+	var last []byte
+	for crate.ReadsLeft() > 0 {
+		key := crate.SliceStringWithCounter()
+		encoded := crate.Data()[crate.ReadIndex() : crate.ReadIndex()+uint64(len(key))+1]
+		if last != nil && bytes.Compare(last, encoded) >= 0 {
+			t.Errorf("UseMap(FlagSortedMaps) - FAIL: key %q not after %q", encoded, last)
+		}
+		last = encoded
+		crate.DiscardStringWithCounter()
+		crate.DiscardU16()
+	}
+
+	var read map[string]uint16
+	crate.ResetReadIndex()
+	lite.UseMap(crate, lite.Read, &read, crate.UseStringWithCounter, crate.UseU16)
+	if !reflect.DeepEqual(read, sortedMapInput(100)) {
+		t.Errorf("UseMap(FlagSortedMaps) - FAIL: sorted map did not round trip")
+	}
+
+	dedup := lite.NewCrate(8, lite.FlagAutoDouble|lite.FlagSortedMaps)
+	blobs := map[uint8][]byte{1: []byte("shared"), 2: []byte("shared"), 3: []byte("other")}
+	lite.UseMap(dedup, lite.Write, &blobs, dedup.UseU8, dedup.UseBytesDedup)
+	var readBlobs map[uint8][]byte
+	lite.UseMap(dedup, lite.Read, &readBlobs, dedup.UseU8, dedup.UseBytesDedup)
+	if !reflect.DeepEqual(readBlobs, blobs) || dedup.ReadsLeft() != 0 {
+		t.Errorf("UseMap(FlagSortedMaps) - FAIL: deduplicated values read as %q", readBlobs)
+	}
+}
+
+type sortedAnyRecord struct {
+	Counts map[int32]string
+	Nested map[string]map[uint8]bool
+}
+
+func TestSortedMapsAny(t *testing.T) {
+	record := sortedAnyRecord{
+		Counts: map[int32]string{-1: "neg", 0: "zero", 256: "big", 7: "seven", 1: "one"},
+		Nested: map[string]map[uint8]bool{"x": {1: true, 9: false, 4: true}, "a": {}, "m": nil},
+	}
+	var first []byte
+	for i := 0; i < 20; i += 1 {
+		crate := lite.NewCrate(8, lite.FlagAutoDouble|lite.FlagSortedMaps)
+		crate.UseAny(&record, lite.Write)
+		if first == nil {
+			first = crate.Data()
+		} else if !bytes.Equal(first, crate.Data()) {
+			t.Fatalf("UseAny(FlagSortedMaps) - FAIL: identical maps wrote different bytes")
+		}
+	}
+	handWritten := lite.NewCrate(8, lite.FlagAutoDouble|lite.FlagSortedMaps)
+	lite.UseMap(handWritten, lite.Write, &record.Counts, handWritten.UseI32, handWritten.UseStringWithCounter)
+	if !bytes.HasPrefix(first, handWritten.Data()) {
+		t.Errorf("UseAny(FlagSortedMaps) - FAIL: map order differs from UseMap()")
+	}
+	var read sortedAnyRecord
+	lite.OpenCrate(first, lite.FlagStatic).UseAny(&read, lite.Read)
+	if !reflect.DeepEqual(read, record) {
+		t.Errorf("UseAny(FlagSortedMaps) - FAIL: read %+v", read)
+	}
+}
+
+func TestMapAllocations(t *testing.T) {
+	small, large := make(map[uint32]uint64), make(map[uint32]uint64)
+	for i := uint32(0); i < 1000; i += 1 {
+		if i < 10 {
+			small[i] = uint64(i)
+		}
+		large[i] = uint64(i)
+	}
+	for _, flags := range []uint8{lite.FlagAutoDouble, lite.FlagAutoDouble | lite.FlagSortedMaps} {
+		crate := lite.NewCrate(16000, flags)
+		write := func(m map[uint32]uint64) func() {
+			return func() {
+				crate.Reset()
+				lite.UseMap(crate, lite.Write, &m, crate.UseU32, crate.UseU64)
+			}
+		}
+		write(large)()
+		if smallAllocs, largeAllocs := testing.AllocsPerRun(10, write(small)), testing.AllocsPerRun(10, write(large)); largeAllocs > smallAllocs {
+			t.Errorf("UseMap(Write) - FAIL: flags %d allocated per entry: %v allocs for 10 entries, %v for 1000", flags, smallAllocs, largeAllocs)
+		}
+		got := make(map[uint32]uint64, len(large))
+		read := func() {
+			crate.ResetReadIndex()
+			for key := range got {
+				delete(got, key)
+			}
+			lite.UseMap(crate, lite.Read, &got, crate.UseU32, crate.UseU64)
+		}
+		if allocs := testing.AllocsPerRun(10, read); allocs > 2 {
+			t.Errorf("UseMap(Read) - FAIL: flags %d made %v allocs reading 1000 entries", flags, allocs)
+		}
+	}
+}
+
+func TestSortedMapsKeepState(t *testing.T) {
+	crate := lite.NewCrate(256, lite.FlagAutoDouble|lite.FlagSortedMaps)
+	crate.WriteU16(7)
+	ref := crate.TrackSlice(crate.Data())
+	m := sortedMapInput(10)
+	lite.UseMap(crate, lite.Write, &m, crate.UseStringWithCounter, crate.UseU16)
+	if !ref.Valid() {
+		t.Errorf("UseMap(FlagSortedMaps) - FAIL: writing a sorted map invalidated a SliceRef")
+	}
+
+	// Only the key written before the value that panics is left in the table, not every key sorted
+	table := lite.NewStringTable()
+	table.Intern("m")
+	crate.SetStringTable(table)
+	interned := map[string]bool{"z": true, "a": false}
+	failValue := func(val *bool, mode lite.UseMode) (sliceModeData []byte) {
+		panic("value failed")
+	}
+	catchPanic[error](func() { lite.UseMap(crate, lite.Write, &interned, crate.UseInternedString, failValue) })
+	if table.Len() != 2 {
+		t.Errorf("UseMap(FlagSortedMaps) - FAIL: string table holds %d strings after a failed write", table.Len())
+	}
+}
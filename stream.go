@@ -0,0 +1,90 @@
+package litecrate
+
+import "errors"
+
+/**************
+	STREAM
+***************/
+
+// A StreamDecoder decodes SelfSerializers from data that arrives in pieces (such as packets
+// from a network connection), where a message may be split across several pieces.
+// Data is added with Feed() and messages are taken out with Next(), which reports when
+// a message is incomplete instead of panicking. The zero value is ready to use.
+//
+// Each message is decoded independently: UseRef() ids and UseBytesDedup() back-references
+// do not reach across messages. A StreamDecoder is not safe for use by multiple goroutines
+type StreamDecoder struct {
+	MaxMessageSize uint64 // Incomplete messages buffered beyond this size are reported as ErrFrameTooLarge, 0 = DefaultMaxFrameSize
+	crate          Crate
+}
+
+// Add data received from the stream. data is copied, so its buffer may be reused
+func (d *StreamDecoder) Feed(data []byte) {
+	if d.crate.read > 0 {
+		d.crate.write = uint64(copy(d.crate.data, d.crate.data[d.crate.read:d.crate.write]))
+		d.crate.read = 0
+		d.crate.gen += 1
+	}
+	d.crate.WriteBytes(data)
+}
+
+// Returns the number of bytes fed that have not been decoded yet
+func (d *StreamDecoder) Buffered() uint64 {
+	return d.crate.ReadsLeft()
+}
+
+// Decode the next message into val.
+// Returns false and a nil error if the data fed so far does not hold a whole message
+// (val may have been partially filled and should not be used), in which case Next() should
+// be called again with the same val after more data is fed. Any other panic while decoding is
+// returned as an error and leaves the undecodable message buffered
+func (d *StreamDecoder) Next(val SelfSerializer) (ok bool, err error) {
+	if d.crate.ReadsLeft() == 0 {
+		return false, nil
+	}
+	return decodeMessage(&d.crate, val, d.maxMessageSize())
+}
+
+// Decode val from crate, reporting running out of data as ok = false with a nil error (or ErrFrameTooLarge
+// if more than maxSize bytes are already waiting) and any other panic as an error.
+// The read index is left where it was unless val was decoded
+func decodeMessage(crate *Crate, val SelfSerializer, maxSize uint64) (ok bool, err error) {
+	start := crate.read
+	defer func() {
+		crate.group = fieldGroup{}
+		crate.dedup = nil
+		crate.ResetGraph()
+		r := recover()
+		if r == nil {
+			return
+		}
+		crate.read = start
+		crate.depth = 0
+		ok = false
+		switch r := r.(type) {
+		case string:
+			err = errors.New(r)
+		case error:
+			err = r
+		default:
+			panic(r)
+		}
+		// Running out of data (even inside a collection element, wrapped in a *PathError)
+		// just means the message has not fully arrived
+		if errors.Is(err, ErrReadPastEnd) {
+			err = nil
+			if crate.ReadsLeft() > maxSize {
+				err = ErrFrameTooLarge
+			}
+		}
+	}()
+	crate.ReadSelfSerializer(val)
+	return true, nil
+}
+
+func (d *StreamDecoder) maxMessageSize() uint64 {
+	if d.MaxMessageSize == 0 {
+		return DefaultMaxFrameSize
+	}
+	return d.MaxMessageSize
+}
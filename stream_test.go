@@ -0,0 +1,109 @@
+package litecrate_test
+
+import (
+	"errors"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+type streamMessage struct {
+	ID   uint32
+	Body string
+	Tags []string
+}
+
+func (m *streamMessage) UseSelf(crate *lite.Crate, mode lite.UseMode) {
+	crate.UseU32(&m.ID, mode)
+	crate.UseStringWithCounter(&m.Body, mode)
+	lite.UseSlice(crate, mode, &m.Tags, crate.UseStringWithCounter)
+}
+
+type refMessage struct {
+	Node **graphNode
+}
+
+func (m *refMessage) UseSelf(crate *lite.Crate, mode lite.UseMode) {
+	lite.UseRef(crate, mode, m.Node, (*graphNode).UseSelf)
+}
+
+func TestStreamDecoder(t *testing.T) {
+	crate := lite.NewCrate(16, lite.FlagAutoDouble)
+	var sent []streamMessage
+	for i := 0; i < 20; i += 1 {
+		msg := streamMessage{ID: uint32(i), Body: "message body", Tags: make([]string, i%4)}
+		crate.WriteSelfSerializer(&msg)
+		sent = append(sent, msg)
+	}
+	data := crate.Data()
+	for _, size := range []int{1, 3, 7, 64, len(data)} {
+		var decoder lite.StreamDecoder
+		var got []streamMessage
+		for i := 0; i < len(data); i += size {
+			end := i + size
+			if end > len(data) {
+				end = len(data)
+			}
+			decoder.Feed(data[i:end])
+			for {
+				var msg streamMessage
+				ok, err := decoder.Next(&msg)
+				if err != nil {
+					t.Fatalf("StreamDecoder.Next() - FAIL: %v", err)
+				}
+				if !ok {
+					break
+				}
+				got = append(got, msg)
+			}
+		}
+		if len(got) != len(sent) || decoder.Buffered() != 0 {
+			t.Fatalf("StreamDecoder - FAIL: %d byte pieces decoded %d of %d messages, %d bytes left", size, len(got), len(sent), decoder.Buffered())
+		}
+		for i := range got {
+			if got[i].ID != sent[i].ID || got[i].Body != sent[i].Body || len(got[i].Tags) != len(sent[i].Tags) {
+				t.Errorf("StreamDecoder - FAIL: message %d: %+v != %+v", i, got[i], sent[i])
+			}
+		}
+	}
+
+	decoder := lite.StreamDecoder{MaxMessageSize: 8}
+	crate.Reset()
+	crate.WriteU32(1)
+	crate.WriteLength(100)
+	decoder.Feed(crate.Data())
+	decoder.Feed(make([]byte, 10))
+	var msg streamMessage
+	if ok, err := decoder.Next(&msg); ok || err != lite.ErrFrameTooLarge {
+		t.Errorf("StreamDecoder.Next() - FAIL: oversized message returned %v, %v", ok, err)
+	}
+
+	decoder = lite.StreamDecoder{}
+	decoder.Feed([]byte{5})
+	var ref *graphNode
+	if ok, err := decoder.Next(&refMessage{&ref}); ok || err == nil || decoder.Buffered() != 1 {
+		t.Errorf("StreamDecoder.Next() - FAIL: invalid message returned %v, %v", ok, err)
+	}
+}
+
+func TestErrReadPastEnd(t *testing.T) {
+	short := lite.OpenCrate([]byte{1, 2}, lite.FlagStatic)
+	err := catchPanic[error](func() { short.ReadU32() })
+	if !errors.Is(err, lite.ErrReadPastEnd) {
+		t.Errorf("CheckRead() - FAIL: panicked with %v", err)
+	}
+	// Running out of data inside a slice element panics with a *PathError wrapping it
+	full := lite.NewCrate(16, lite.FlagAutoDouble)
+	full.WriteSelfSerializer(&streamMessage{ID: 1, Body: "body", Tags: []string{"abc"}})
+	truncated := lite.OpenCrate(full.Data()[:full.WriteIndex()-1], lite.FlagStatic)
+	err = catchPanic[error](func() { truncated.ReadSelfSerializer(&streamMessage{}) })
+	var pathErr *lite.PathError
+	if !errors.As(err, &pathErr) || !errors.Is(err, lite.ErrReadPastEnd) {
+		t.Errorf("CheckRead() - FAIL: element read panicked with %v", err)
+	}
+	failed := lite.OpenCrate([]byte{1}, lite.FlagStatic|lite.FlagNoPanic)
+	failed.ReadU16()
+	if !errors.Is(failed.Err(), lite.ErrReadPastEnd) {
+		t.Errorf("Err() - FAIL: %v", failed.Err())
+	}
+}
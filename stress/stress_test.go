@@ -0,0 +1,160 @@
+// Package stress hammers the parts of litecrate that are documented as safe for
+// concurrent use, checking invariants along the way. Run it with the race detector:
+//
+//	go test -race ./stress
+//
+// and raise -count for a longer soak
+package stress_test
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"sync"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+const (
+	goroutines = 16
+	iterations = 500
+)
+
+func hammer(t *testing.T, work func(g int, i int)) {
+	t.Helper()
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g += 1 {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i += 1 {
+				work(g, i)
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+// Every crate handed out must be empty, large enough and owned by one goroutine only
+func TestStressCratePool(t *testing.T) {
+	pool := lite.NewCratePool()
+	var mutex sync.Mutex
+	inUse := make(map[*lite.Crate]bool)
+	hammer(t, func(g int, i int) {
+		size := uint64(8 + (g*i)%512)
+		crate := pool.Get(size, lite.FlagStatic)
+		mutex.Lock()
+		if inUse[crate] {
+			t.Errorf("CratePool - FAIL: crate handed to two goroutines")
+		}
+		inUse[crate] = true
+		mutex.Unlock()
+		if crate.WriteIndex() != 0 || crate.ReadIndex() != 0 || crate.SpaceLeft() < size {
+			t.Errorf("CratePool - FAIL: dirty or small crate (write %d, read %d, space %d < %d)", crate.WriteIndex(), crate.ReadIndex(), crate.SpaceLeft(), size)
+		}
+		for n := uint64(0); n < size/8; n += 1 {
+			crate.WriteU64(uint64(g))
+		}
+		for n := uint64(0); n < size/8; n += 1 {
+			if crate.ReadU64() != uint64(g) {
+				t.Errorf("CratePool - FAIL: crate written by another goroutine")
+			}
+		}
+		mutex.Lock()
+		delete(inUse, crate)
+		mutex.Unlock()
+		pool.Put(crate)
+	})
+	if stats := pool.Stats(); stats.Gets != goroutines*iterations || stats.Hits > stats.Gets {
+		t.Errorf("CratePool - FAIL: %d gets, %d hits", stats.Gets, stats.Hits)
+	}
+}
+
+// Frames from concurrent writers must arrive whole, in per-writer order, to concurrent readers
+func TestStressFramer(t *testing.T) {
+	client, server := net.Pipe()
+	framer := lite.Framer{Pool: lite.NewCratePool()}
+	var readers sync.WaitGroup
+	var mutex sync.Mutex
+	received := make(map[int][]int)
+	for r := 0; r < 4; r += 1 {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for {
+				crate, err := framer.ReadFrame(server)
+				if err == io.EOF {
+					return
+				}
+				if err != nil {
+					t.Errorf("Framer.ReadFrame() - FAIL: %v", err)
+					return
+				}
+				g, i := int(crate.ReadU16()), int(crate.ReadU16())
+				payload := crate.ReadBytesWithCounter()
+				if !bytes.Equal(payload, bytes.Repeat([]byte{byte(g)}, i%300)) || crate.ReadsLeft() != 0 {
+					t.Errorf("Framer - FAIL: frame %d from writer %d corrupted", i, g)
+				}
+				mutex.Lock()
+				received[g] = append(received[g], i)
+				mutex.Unlock()
+				framer.Pool.Put(crate)
+			}
+		}()
+	}
+	hammer(t, func(g int, i int) {
+		crate := lite.NewCrate(8, lite.FlagAutoDouble)
+		crate.WriteU16(uint16(g))
+		crate.WriteU16(uint16(i))
+		crate.WriteBytesWithCounter(bytes.Repeat([]byte{byte(g)}, i%300))
+		if err := framer.WriteFrame(client, crate); err != nil {
+			t.Errorf("Framer.WriteFrame() - FAIL: %v", err)
+		}
+	})
+	client.Close()
+	readers.Wait()
+	for g := 0; g < goroutines; g += 1 {
+		if len(received[g]) != iterations {
+			t.Errorf("Framer - FAIL: received %d of %d frames from writer %d", len(received[g]), iterations, g)
+		}
+	}
+}
+
+// Every goroutine must see the same fully decoded value, decoded exactly once
+func TestStressLazy(t *testing.T) {
+	source := lite.NewCrate(8, lite.FlagAutoDouble)
+	want := make([]uint32, 1000)
+	for i := range want {
+		want[i] = uint32(i * i)
+	}
+	var decodes int
+	useValue := func(crate *lite.Crate, val *[]uint32, mode lite.UseMode) {
+		if mode == lite.Read {
+			decodes += 1
+		}
+		lite.UseSlice(crate, mode, val, crate.UseU32)
+	}
+	lite.UseLazy(source, lite.Write, lite.NewLazy(want), useValue)
+	for round := 0; round < 20; round += 1 {
+		var lazy lite.Lazy[[]uint32]
+		source.ResetReadIndex()
+		lite.UseLazy(source, lite.Read, &lazy, useValue)
+		decodes = 0
+		var wg sync.WaitGroup
+		for g := 0; g < goroutines; g += 1 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				got := lazy.Get()
+				if len(got) != len(want) || got[999] != want[999] {
+					t.Errorf("Lazy.Get() - FAIL: partially decoded value")
+				}
+			}()
+		}
+		wg.Wait()
+		if decodes != 1 {
+			t.Errorf("Lazy.Get() - FAIL: decoded %d times", decodes)
+		}
+	}
+}
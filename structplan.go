@@ -0,0 +1,403 @@
+package litecrate
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+/**************
+	STRUCT PLAN
+***************/
+
+// A compiled plan for using values of one struct type, built once by BuildSerializer()
+// from the type's `crate:""` struct tags so that each use runs through a flat list of field offsets
+// instead of inspecting the value with reflection like UseAny() does.
+//
+// Typically used to implement UseSelf():
+//
+//	var orderPlan, _ = BuildSerializer(reflect.TypeOf(Order{}))
+//
+//	func (o *Order) UseSelf(crate *Crate, mode UseMode) {
+//		orderPlan.Use(crate, o, mode)
+//	}
+type StructPlan struct {
+	ptrType reflect.Type
+	ops     []planOp
+}
+
+// How one field is used
+type planOp struct {
+	offset  uintptr
+	size    uintptr      // size of the Go field in bytes
+	kind    FieldKind    // how the field is laid out in the crate
+	signed  bool         // whether kind is a signed integer kind
+	anyType reflect.Type // if not nil the field is used with UseAny() (or UseSelfSerializer()) instead of kind
+	name    string
+}
+
+// A field found while building a plan, before it is put in order
+type planField struct {
+	op       planOp
+	typ      reflect.Type
+	order    int
+	hasOrder bool
+}
+
+// Build a StructPlan for the struct type t (or a pointer to it). Exported fields are used
+// in declaration order, nested structs field by field, and unexported fields are skipped.
+// Each field's `crate:""` tag is "-" to skip the field, or a comma separated list of:
+//
+//	<Kind>    the FieldKind name to lay the field out as (see Schema): U24, I40, UVarint, Varint, F32...
+//	order=N   position of the field, if any field in a struct sets it every field in that struct must
+//
+// Integer kinds must have the field's signedness and be no wider than it (UVarint and Varint suit any width),
+// floats can be F32 or F64, complex numbers C64 or C128, and string and []byte fields String and Bytes.
+// Fields without a kind are laid out the same as UseAny() would, and types other than booleans, numbers,
+// strings, []byte and structs (slices, maps, pointers, SelfSerializers) are used with UseAny() on that field alone,
+// as are types registered with RegisterType() (which cannot be tagged with a kind).
+// Returns an error describing the first field that cannot be used as tagged
+func BuildSerializer(t reflect.Type) (plan *StructPlan, err error) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, errors.New("LiteCrate: BuildSerializer() requires a struct type, not " + t.String())
+	}
+	plan = &StructPlan{ptrType: reflect.PointerTo(t)}
+	if err := plan.addStruct(t, 0, ""); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+func (p *StructPlan) addStruct(t reflect.Type, offset uintptr, prefix string) error {
+	fields := make([]planField, 0, t.NumField())
+	ordered := 0
+	for i := 0; i < t.NumField(); i += 1 {
+		sf := t.Field(i)
+		tag, tagged := sf.Tag.Lookup("crate")
+		if tag == "-" {
+			continue
+		}
+		name := prefix + sf.Name
+		if !sf.IsExported() {
+			if tagged {
+				return errors.New("LiteCrate: BuildSerializer(): unexported field " + name + " is tagged")
+			}
+			continue
+		}
+		field := planField{op: planOp{offset: offset + sf.Offset, size: sf.Type.Size(), name: name}, typ: sf.Type}
+		kind, hasKind := FieldKind(0), false
+		for _, option := range strings.Split(tag, ",") {
+			switch {
+			case option == "":
+			case strings.HasPrefix(option, "order="):
+				order, err := strconv.Atoi(option[len("order="):])
+				if err != nil {
+					return errors.New("LiteCrate: BuildSerializer(): field " + name + " has invalid order " + strconv.Quote(option))
+				}
+				field.order, field.hasOrder = order, true
+				ordered += 1
+			default:
+				if err := kind.UnmarshalText([]byte(option)); err != nil {
+					return errors.New("LiteCrate: BuildSerializer(): field " + name + " has unknown kind " + strconv.Quote(option))
+				}
+				hasKind = true
+			}
+		}
+		if err := field.op.setKind(sf.Type, kind, hasKind); err != nil {
+			return err
+		}
+		fields = append(fields, field)
+	}
+	if ordered > 0 {
+		if ordered != len(fields) {
+			return errors.New("LiteCrate: BuildSerializer(): some fields of " + t.String() + " have an order and some do not")
+		}
+		sort.SliceStable(fields, func(i, j int) bool { return fields[i].order < fields[j].order })
+		for i := 1; i < len(fields); i += 1 {
+			if fields[i].order == fields[i-1].order {
+				return errors.New("LiteCrate: BuildSerializer(): fields " + fields[i-1].op.name + " and " + fields[i].op.name + " have the same order")
+			}
+		}
+	}
+	for _, field := range fields {
+		if field.op.anyType == nil && field.op.kind == KindStruct {
+			if err := p.addStruct(field.typ, field.op.offset, field.op.name+"."); err != nil {
+				return err
+			}
+			continue
+		}
+		p.ops = append(p.ops, field.op)
+	}
+	return nil
+}
+
+// Choose how a field of type t is used, checking kind suits it if the field was tagged with one
+func (op *planOp) setKind(t reflect.Type, kind FieldKind, hasKind bool) error {
+	var allowed func(kind FieldKind) bool
+	var natural FieldKind
+	bits := int(t.Size()) * 8
+	switch t.Kind() {
+	case reflect.Bool:
+		natural, allowed = KindBool, func(k FieldKind) bool { return k == KindBool }
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint, reflect.Uintptr:
+		natural = FieldKind(bits/8*2 - 1)
+		allowed = func(k FieldKind) bool {
+			return k == KindUVarint || (k >= KindU8 && k <= KindU64 && k%2 == 1 && kindBits(k) <= bits)
+		}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		natural = FieldKind(bits / 8 * 2)
+		allowed = func(k FieldKind) bool {
+			return k == KindVarint || (k >= KindI8 && k <= KindI64 && k%2 == 0 && kindBits(k) <= bits)
+		}
+	case reflect.Float32, reflect.Float64:
+		natural = KindF32 + FieldKind(bits/64)
+		allowed = func(k FieldKind) bool { return k == KindF32 || k == KindF64 }
+	case reflect.Complex64, reflect.Complex128:
+		natural = KindC64 + FieldKind(bits/128)
+		allowed = func(k FieldKind) bool { return k == KindC64 || k == KindC128 }
+	case reflect.String:
+		natural, allowed = KindString, func(k FieldKind) bool { return k == KindString }
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 && !isSelfSerializer(t) {
+			natural, allowed = KindBytes, func(k FieldKind) bool { return k == KindBytes }
+		}
+	case reflect.Struct:
+		if !isSelfSerializer(t) {
+			natural, allowed = KindStruct, func(k FieldKind) bool { return false }
+		}
+	}
+	if _, registered := LookupType(t); registered || allowed == nil || isSelfSerializer(t) {
+		if hasKind {
+			return errors.New("LiteCrate: BuildSerializer(): field " + op.name + " of type " + t.String() + " cannot be tagged with a kind")
+		}
+		op.anyType = t
+		return nil
+	}
+	if !hasKind {
+		kind = natural
+	} else if !allowed(kind) {
+		return errors.New("LiteCrate: BuildSerializer(): field " + op.name + " of type " + t.String() + " cannot be used as " + kind.String())
+	}
+	op.kind = kind
+	op.signed = kind == KindVarint || (kind >= KindI8 && kind <= KindI64 && kind%2 == 0)
+	return nil
+}
+
+// Use the struct val points to according to the plan and mode. val must be a non-nil pointer
+// to the plan's struct type in every mode.
+//
+// Write = 'write val into crate', Read = 'read from crate into val',
+// Peek = 'read from crate into val without advancing index'
+// Slice = 'Return the slice the next unread val occupies without altering val'
+func (p *StructPlan) Use(crate *Crate, val any, mode UseMode) (sliceModeData []byte) {
+	if reflect.TypeOf(val) != p.ptrType {
+		panic("LiteCrate: StructPlan for " + p.ptrType.String() + " cannot use a value of type " + reflect.TypeOf(val).String())
+	}
+	base := reflect.ValueOf(val).UnsafePointer()
+	if base == nil {
+		panic("LiteCrate: StructPlan.Use() requires a non-nil pointer")
+	}
+	switch mode {
+	case Write:
+		for i := range p.ops {
+			p.ops[i].write(crate, base)
+		}
+	case Read:
+		for i := range p.ops {
+			p.ops[i].read(crate, base)
+		}
+	case Peek:
+		idx := crate.read
+		for i := range p.ops {
+			p.ops[i].read(crate, base)
+		}
+		crate.read = idx
+	case Discard:
+		for i := range p.ops {
+			p.ops[i].discard(crate, base)
+		}
+	case Slice:
+		start := crate.read
+		for i := range p.ops {
+			p.ops[i].discard(crate, base)
+		}
+		end := crate.read
+		crate.read = start
+		return crate.data[start:end:end]
+	default:
+		crate.useCustomMode(val, mode, "StructPlan.Use")
+	}
+	return nil
+}
+
+// Returns a UseFunc using the plan with crate, for passing to UseSlice(), UseMap() and the like.
+// When reading into a nil *val a new struct is allocated for it
+func (p *StructPlan) UseFunc(crate *Crate) UseFunc[any] {
+	return func(val *any, mode UseMode) (sliceModeData []byte) {
+		if *val == nil {
+			*val = reflect.New(p.ptrType.Elem()).Interface()
+		}
+		return p.Use(crate, *val, mode)
+	}
+}
+
+func (op *planOp) write(c *Crate, base unsafe.Pointer) {
+	field := unsafe.Add(base, op.offset)
+	if op.anyType != nil {
+		c.useAnyField(op.anyType, field, Write)
+		return
+	}
+	switch op.kind {
+	case KindBool:
+		c.WriteBool(*(*bool)(field))
+	case KindF32:
+		c.WriteF32(float32(loadFloat(field, op.size)))
+	case KindF64:
+		c.WriteF64(loadFloat(field, op.size))
+	case KindC64:
+		c.WriteC64(complex64(loadComplex(field, op.size)))
+	case KindC128:
+		c.WriteC128(loadComplex(field, op.size))
+	case KindString:
+		c.WriteStringWithCounter(*(*string)(field))
+	case KindBytes:
+		c.WriteBytesWithCounter(*(*[]byte)(field))
+	default:
+		if op.signed {
+			writeKindInt(c, op.kind, int64(loadUint(field, op.size, true)))
+		} else {
+			writeKindUint(c, op.kind, loadUint(field, op.size, false))
+		}
+	}
+}
+
+func (op *planOp) read(c *Crate, base unsafe.Pointer) {
+	field := unsafe.Add(base, op.offset)
+	if op.anyType != nil {
+		c.useAnyField(op.anyType, field, Read)
+		return
+	}
+	switch op.kind {
+	case KindBool:
+		*(*bool)(field) = c.ReadBool()
+	case KindF32:
+		storeFloat(field, op.size, float64(c.ReadF32()))
+	case KindF64:
+		storeFloat(field, op.size, c.ReadF64())
+	case KindC64:
+		storeComplex(field, op.size, complex128(c.ReadC64()))
+	case KindC128:
+		storeComplex(field, op.size, c.ReadC128())
+	case KindString:
+		*(*string)(field) = c.ReadStringWithCounter()
+	case KindBytes:
+		*(*[]byte)(field) = c.ReadBytesWithCounter()
+	default:
+		var val uint64
+		if op.signed {
+			val = uint64(readKindInt(c, op.kind))
+		} else {
+			val = readKindUint(c, op.kind)
+		}
+		storeUint(field, op.size, val)
+		if loadUint(field, op.size, op.signed) != val {
+			panic("LiteCrate: " + op.kind.String() + " value does not fit " + intStr(op.size*8) + " bit field " + op.name)
+		}
+	}
+}
+
+func (op *planOp) discard(c *Crate, base unsafe.Pointer) {
+	switch {
+	case op.anyType != nil:
+		c.useAnyField(op.anyType, unsafe.Add(base, op.offset), Discard)
+	case kindWidth(op.kind) > 0:
+		c.SliceBytes(kindWidth(op.kind))
+		c.read += kindWidth(op.kind)
+	case op.kind == KindUVarint, op.kind == KindVarint:
+		c.DiscardUVarint()
+	default:
+		c.DiscardStringWithCounter()
+	}
+}
+
+// Uses a field the plan could not compile with its registered encoding, UseSelfSerializer() or UseAny()
+func (c *Crate) useAnyField(t reflect.Type, field unsafe.Pointer, mode UseMode) {
+	val := reflect.NewAt(t, field).Interface()
+	if use, ok := LookupType(t); ok {
+		use(c, val, mode)
+		return
+	}
+	if self, ok := val.(SelfSerializer); ok {
+		c.UseSelfSerializer(self, mode)
+		return
+	}
+	c.UseAny(val, mode)
+}
+
+// Loads an integer field of size bytes, sign extending it if signed
+func loadUint(field unsafe.Pointer, size uintptr, signed bool) uint64 {
+	switch {
+	case size == 1 && signed:
+		return uint64(*(*int8)(field))
+	case size == 1:
+		return uint64(*(*uint8)(field))
+	case size == 2 && signed:
+		return uint64(*(*int16)(field))
+	case size == 2:
+		return uint64(*(*uint16)(field))
+	case size == 4 && signed:
+		return uint64(*(*int32)(field))
+	case size == 4:
+		return uint64(*(*uint32)(field))
+	}
+	return *(*uint64)(field)
+}
+
+func storeUint(field unsafe.Pointer, size uintptr, val uint64) {
+	switch size {
+	case 1:
+		*(*uint8)(field) = uint8(val)
+	case 2:
+		*(*uint16)(field) = uint16(val)
+	case 4:
+		*(*uint32)(field) = uint32(val)
+	default:
+		*(*uint64)(field) = val
+	}
+}
+
+func loadFloat(field unsafe.Pointer, size uintptr) float64 {
+	if size == 4 {
+		return float64(*(*float32)(field))
+	}
+	return *(*float64)(field)
+}
+
+func storeFloat(field unsafe.Pointer, size uintptr, val float64) {
+	if size == 4 {
+		*(*float32)(field) = float32(val)
+		return
+	}
+	*(*float64)(field) = val
+}
+
+func loadComplex(field unsafe.Pointer, size uintptr) complex128 {
+	if size == 8 {
+		return complex128(*(*complex64)(field))
+	}
+	return *(*complex128)(field)
+}
+
+func storeComplex(field unsafe.Pointer, size uintptr, val complex128) {
+	if size == 8 {
+		*(*complex64)(field) = complex64(val)
+		return
+	}
+	*(*complex128)(field) = val
+}
@@ -0,0 +1,166 @@
+package litecrate_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+type plannedItem struct {
+	SKU   uint32 `crate:"U24"`
+	Count int64  `crate:"Varint"`
+}
+
+type plannedOrder struct {
+	ID      uint64      `crate:"UVarint,order=2"`
+	Region  int16       `crate:"order=1"`
+	Price   float64     `crate:"F32,order=3"`
+	Note    string      `crate:"order=4"`
+	Item    plannedItem `crate:"order=5"`
+	Where   tracedPoint `crate:"order=6"`
+	Tags    []string    `crate:"order=7"`
+	Skipped int         `crate:"-"`
+	hidden  int
+}
+
+func (o *plannedOrder) UseHandWritten(crate *lite.Crate, mode lite.UseMode) {
+	crate.UseI16(&o.Region, mode)
+	crate.UseUVarint(&o.ID, mode)
+	price := float32(o.Price)
+	crate.UseF32(&price, mode)
+	o.Price = float64(price)
+	crate.UseStringWithCounter(&o.Note, mode)
+	crate.UseU24(&o.Item.SKU, mode)
+	crate.UseVarint(&o.Item.Count, mode)
+	crate.UseSelfSerializer(&o.Where, mode)
+	lite.UseSlice(crate, mode, &o.Tags, crate.UseStringWithCounter)
+}
+
+func TestStructPlan(t *testing.T) {
+	plan, err := lite.BuildSerializer(reflect.TypeOf(plannedOrder{}))
+	if err != nil {
+		t.Fatalf("BuildSerializer() - FAIL: %v", err)
+	}
+	order := plannedOrder{ID: 300, Region: -2, Price: 1.5, Note: "rush", Item: plannedItem{SKU: 70000, Count: -9},
+		Where: tracedPoint{X: 3, Y: 4}, Tags: []string{"a", "b"}, Skipped: 5, hidden: 6}
+	planned := lite.NewCrate(16, lite.FlagAutoDouble)
+	plan.Use(planned, &order, lite.Write)
+	expected := lite.NewCrate(16, lite.FlagAutoDouble)
+	order.UseHandWritten(expected, lite.Write)
+	if !bytes.Equal(planned.Data(), expected.Data()) {
+		t.Fatalf("StructPlan.Use(Write) - FAIL: %v != %v", planned.Data(), expected.Data())
+	}
+	var peeked, read plannedOrder
+	plan.Use(planned, &peeked, lite.Peek)
+	slice := plan.Use(planned, &read, lite.Slice)
+	plan.Use(planned, &read, lite.Read)
+	order.Skipped, order.hidden = 0, 0
+	if !reflect.DeepEqual(read, order) || !reflect.DeepEqual(peeked, order) || !bytes.Equal(slice, expected.Data()) || planned.ReadsLeft() != 0 {
+		t.Errorf("StructPlan.Use(Read) - FAIL: %+v", read)
+	}
+	planned.ResetReadIndex()
+	plan.Use(planned, &read, lite.Discard)
+	if planned.ReadsLeft() != 0 {
+		t.Errorf("StructPlan.Use(Discard) - FAIL: %d bytes left", planned.ReadsLeft())
+	}
+
+	var orders []any
+	planned.Reset()
+	lite.UseSlice(planned, lite.Write, &[]any{&order, &order}, plan.UseFunc(planned))
+	lite.UseSlice(planned, lite.Read, &orders, plan.UseFunc(planned))
+	if len(orders) != 2 || !reflect.DeepEqual(orders[1], &order) {
+		t.Errorf("StructPlan.UseFunc() - FAIL: read %v", orders)
+	}
+
+	if catchPanic[error](func() { plan.Use(planned, order, lite.Write) }) == nil || catchPanic[error](func() { plan.Use(planned, (*plannedOrder)(nil), lite.Read) }) == nil {
+		t.Errorf("StructPlan.Use() - FAIL: no panic for value that is not a non-nil *plannedOrder")
+	}
+}
+
+type plainRecord struct {
+	A bool
+	B uint
+	C int8
+	D float32
+	E complex128
+	F []byte
+	G map[string]int32
+	H *uint16
+}
+
+func TestStructPlanMatchesUseAny(t *testing.T) {
+	plan, err := lite.BuildSerializer(reflect.TypeOf(&plainRecord{}))
+	if err != nil {
+		t.Fatalf("BuildSerializer() - FAIL: %v", err)
+	}
+	h := uint16(9)
+	val := plainRecord{A: true, B: 1 << 40, C: -3, D: 2.5, E: 1 + 2i, F: []byte{1, 2}, G: map[string]int32{"x": 1}, H: &h}
+	planned, reflected := lite.NewCrate(16, lite.FlagAutoDouble), lite.NewCrate(16, lite.FlagAutoDouble)
+	plan.Use(planned, &val, lite.Write)
+	reflected.UseAny(&val, lite.Write)
+	if !bytes.Equal(planned.Data(), reflected.Data()) {
+		t.Errorf("StructPlan.Use() - FAIL: untagged struct %v != UseAny() %v", planned.Data(), reflected.Data())
+	}
+
+	type narrow struct {
+		N int16 `crate:"Varint"`
+	}
+	plan, _ = lite.BuildSerializer(reflect.TypeOf(narrow{}))
+	planned.Reset()
+	planned.WriteVarint(1 << 20)
+	if catchPanic[error](func() { plan.Use(planned, &narrow{}, lite.Read) }) == nil {
+		t.Errorf("StructPlan.Use() - FAIL: varint too large for field did not panic")
+	}
+
+	invalid := []any{
+		0,
+		struct {
+			A uint16 `crate:"U32"`
+		}{},
+		struct {
+			A uint16 `crate:"I16"`
+		}{},
+		struct {
+			A string `crate:"Bytes"`
+		}{},
+		struct {
+			A []int `crate:"Bytes"`
+		}{},
+		struct {
+			A int `crate:"Number"`
+		}{},
+		struct {
+			A int `crate:"order=x"`
+		}{},
+		struct {
+			A int `crate:"order=1"`
+			B int
+		}{},
+		struct {
+			A int `crate:"order=1"`
+			B int `crate:"order=1"`
+		}{},
+		struct {
+			a int `crate:"U8"`
+		}{},
+	}
+	for _, val := range invalid {
+		if _, err := lite.BuildSerializer(reflect.TypeOf(val)); err == nil {
+			t.Errorf("BuildSerializer(%T) - FAIL: no error", val)
+		}
+	}
+}
+
+func BenchmarkStructPlan(b *testing.B) {
+	plan, _ := lite.BuildSerializer(reflect.TypeOf(plannedItem{}))
+	crate := lite.NewCrate(64, lite.FlagDefault)
+	item := plannedItem{SKU: 70000, Count: -9}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i += 1 {
+		crate.Reset()
+		plan.Use(crate, &item, lite.Write)
+		plan.Use(crate, &item, lite.Read)
+	}
+}
@@ -5,6 +5,22 @@ echo "+-------------+"
 go test -coverprofile cover.out 
 go tool cover -html=cover.out -o=cover.html
 echo "+----------------+"
+echo "|   BUILD TAGS   |"
+echo "+----------------+"
+for tag in litecrate_nonet litecrate_nocompress litecrate_nocrypto litecrate_notools litecrate_noos litecrate_core
+do
+	echo "--- $tag"
+	go build -tags $tag ./... && go test -tags $tag -run '^$' ./...
+done
+echo "+------------+"
+echo "|   STRESS   |"
+echo "+------------+"
+go test -race -count=5 ./stress
+echo "+--------------+"
+echo "|   EXAMPLES   |"
+echo "+--------------+"
+go test -race ./examples/...
+echo "+----------------+"
 echo "|   BENCHMARKS   |"
 echo "+----------------+"
 go test -bench=.
@@ -67,9 +83,21 @@ echo "--- FuzzVarint"
 go test -fuzz=FuzzVarint -fuzztime 5s -cover
 echo "--- FuzzLengthOrNil"
 go test -fuzz=FuzzLengthOrNil -fuzztime 20s -cover
+echo "--- FuzzLength"
+go test -fuzz=FuzzLength$ -fuzztime 20s -cover
 echo "--- FuzzString"
 go test -fuzz=FuzzString -fuzztime 30s -cover
+echo "--- FuzzStringZeroCopy"
+go test -fuzz=FuzzStringZeroCopy -fuzztime 20s -cover
 echo "--- FuzzBytes"
 go test -fuzz=FuzzBytes -fuzztime 20s -cover
 echo "--- FuzzSelfSerializer"
 go test -fuzz=FuzzSelfSerializer -fuzztime 5m -cover
+echo "--- FuzzInferSchema"
+go test -fuzz=FuzzInferSchema -fuzztime 20s -cover
+echo "--- FuzzDifferentialGob"
+go test -fuzz=FuzzDifferentialGob -fuzztime 1m -cover
+echo "--- FuzzSnappy"
+go test -fuzz=FuzzSnappy -fuzztime 20s -cover
+echo "--- FuzzPatch"
+go test -fuzz=FuzzPatch -fuzztime 20s -cover
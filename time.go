@@ -0,0 +1,379 @@
+package litecrate
+
+import (
+	"time"
+)
+
+const (
+	maxUnixSecondsU32 = 1<<32 - 1 // last second UnixSecondsU32 can hold: 2106-02-07 06:28:15 UTC
+	maxUnixSecondsU40 = 1<<40 - 1 // last second UnixSecondsU40 can hold: in the year 36812
+	maxUnixMillisU48  = 1<<48 - 1 // last millisecond UnixMillisU48 can hold: in the year 10889
+)
+
+/**************
+	TIME
+***************/
+
+// Discard next unread time.Time in crate
+func (c *Crate) DiscardTime() {
+	c.DiscardN(12)
+}
+
+// Return byte slice the next unread time.Time occupies
+func (c *Crate) SliceTime() (slice []byte) {
+	return c.SliceBytes(12)
+}
+
+// Write time.Time to crate as 12 bytes: I64 seconds since the Unix epoch followed by U32 nanoseconds.
+// The time zone is not written, times are always read as UTC
+func (c *Crate) WriteTime(val time.Time) {
+	c.WriteI64(val.Unix())
+	c.WriteU32(uint32(val.Nanosecond()))
+}
+
+// Read next 12 bytes from crate as time.Time (in UTC)
+func (c *Crate) ReadTime() (val time.Time) {
+	sec := c.ReadI64()
+	nsec := c.ReadU32()
+	if nsec >= uint32(time.Second) {
+		panic("LiteCrate: time has " + intStr(nsec) + " nanoseconds, must be less than one second")
+	}
+	return time.Unix(sec, int64(nsec)).UTC()
+}
+
+// Read next 12 bytes from crate as time.Time (in UTC) without advancing read index
+func (c *Crate) PeekTime() (val time.Time) {
+	idx := c.read
+	val = c.ReadTime()
+	c.read = idx
+	return val
+}
+
+// Use the time.Time pointed to by val according to mode:
+// Write = 'write val into crate', Read = 'read from crate into val',
+// Peek = 'read from crate into val without advancing index'
+// Slice = 'Return the slice the next unread val occupies without altering val'
+func (c *Crate) UseTime(val *time.Time, mode UseMode) (sliceModeData []byte) {
+	switch mode {
+	case Write:
+		c.WriteTime(*val)
+	case Read:
+		*val = c.ReadTime()
+	case Peek:
+		*val = c.PeekTime()
+	case Discard:
+		c.DiscardTime()
+	case Slice:
+		sliceModeData = c.SliceTime()
+	default:
+		c.useCustomMode(val, mode, "UseTime")
+	}
+	return sliceModeData
+}
+
+/**************
+	TIME DELTA
+***************/
+
+// Discard next unread time.Time delta in crate
+func (c *Crate) DiscardTimeDelta() (bytesDiscarded uint64) {
+	bytesDiscarded = c.DiscardVarint()
+	bytesDiscarded += c.DiscardUVarint()
+	return bytesDiscarded
+}
+
+// Return byte slice the next unread time.Time delta occupies
+func (c *Crate) SliceTimeDelta() (slice []byte) {
+	start := c.read
+	c.DiscardTimeDelta()
+	end := c.read
+	c.read = start
+	return c.data[start:end:end]
+}
+
+// Write time.Time to crate as its difference from base: a Varint of whole seconds
+// followed by a UVarint of nanoseconds (0 to 999,999,999). Times close to base (such as a series of
+// timestamps each encoded relative to the one before it) take as little as 2 bytes.
+// The time zone is not written, times are always read as UTC
+func (c *Crate) WriteTimeDelta(val time.Time, base time.Time) (bytesWritten uint64) {
+	sec := val.Unix() - base.Unix()
+	nsec := int64(val.Nanosecond()) - int64(base.Nanosecond())
+	if nsec < 0 {
+		sec -= 1
+		nsec += int64(time.Second)
+	}
+	bytesWritten = c.WriteVarint(sec)
+	bytesWritten += c.WriteUVarint(uint64(nsec))
+	return bytesWritten
+}
+
+// Read next time.Time delta from crate and add it to base (returns time in UTC)
+func (c *Crate) ReadTimeDelta(base time.Time) (val time.Time, bytesRead uint64) {
+	sec, secBytes := c.ReadVarint()
+	nsec, nsecBytes := c.ReadUVarint()
+	if nsec >= uint64(time.Second) {
+		panic("LiteCrate: time delta has " + intStr(nsec) + " nanoseconds, must be less than one second")
+	}
+	return time.Unix(base.Unix()+sec, int64(base.Nanosecond())+int64(nsec)).UTC(), secBytes + nsecBytes
+}
+
+// Read next time.Time delta from crate and add it to base (returns time in UTC)
+// without advancing read index
+func (c *Crate) PeekTimeDelta(base time.Time) (val time.Time, bytesRead uint64) {
+	idx := c.read
+	val, bytesRead = c.ReadTimeDelta(base)
+	c.read = idx
+	return val, bytesRead
+}
+
+// Use the time.Time pointed to by val according to mode (as a delta from base):
+// Write = 'write val into crate', Read = 'read from crate into val',
+// Peek = 'read from crate into val without advancing index'
+// Slice = 'Return the slice the next unread val occupies without altering val'
+func (c *Crate) UseTimeDelta(val *time.Time, base time.Time, mode UseMode) (bytesUsed uint64, sliceModeData []byte) {
+	switch mode {
+	case Write:
+		bytesUsed = c.WriteTimeDelta(*val, base)
+	case Read:
+		*val, bytesUsed = c.ReadTimeDelta(base)
+	case Peek:
+		*val, bytesUsed = c.PeekTimeDelta(base)
+	case Discard:
+		bytesUsed = c.DiscardTimeDelta()
+	case Slice:
+		sliceModeData = c.SliceTimeDelta()
+	default:
+		c.useCustomMode(val, mode, "UseTimeDelta")
+	}
+	return bytesUsed, sliceModeData
+}
+
+/**************
+	DURATION
+***************/
+
+// Discard next unread time.Duration in crate
+func (c *Crate) DiscardDuration() (bytesDiscarded uint64) {
+	return c.DiscardVarint()
+}
+
+// Return byte slice the next unread time.Duration occupies
+func (c *Crate) SliceDuration() (slice []byte) {
+	return c.SliceVarint()
+}
+
+// Write time.Duration to crate as a Varint of nanoseconds
+func (c *Crate) WriteDuration(val time.Duration) (bytesWritten uint64) {
+	return c.WriteVarint(int64(val))
+}
+
+// Read next Varint from crate as time.Duration
+func (c *Crate) ReadDuration() (val time.Duration, bytesRead uint64) {
+	nsec, bytesRead := c.ReadVarint()
+	return time.Duration(nsec), bytesRead
+}
+
+// Read next Varint from crate as time.Duration without advancing read index
+func (c *Crate) PeekDuration() (val time.Duration, bytesRead uint64) {
+	nsec, bytesRead := c.PeekVarint()
+	return time.Duration(nsec), bytesRead
+}
+
+// Use the time.Duration pointed to by val according to mode:
+// Write = 'write val into crate', Read = 'read from crate into val',
+// Peek = 'read from crate into val without advancing index'
+// Slice = 'Return the slice the next unread val occupies without altering val'
+func (c *Crate) UseDuration(val *time.Duration, mode UseMode) (bytesUsed uint64, sliceModeData []byte) {
+	switch mode {
+	case Write:
+		bytesUsed = c.WriteDuration(*val)
+	case Read:
+		*val, bytesUsed = c.ReadDuration()
+	case Peek:
+		*val, bytesUsed = c.PeekDuration()
+	case Discard:
+		bytesUsed = c.DiscardDuration()
+	case Slice:
+		sliceModeData = c.SliceDuration()
+	default:
+		c.useCustomMode(val, mode, "UseDuration")
+	}
+	return bytesUsed, sliceModeData
+}
+
+/**************
+	UNIX SECONDS U32
+***************/
+
+// Discard next unread UnixSecondsU32 time.Time in crate
+func (c *Crate) DiscardUnixSecondsU32() {
+	c.DiscardN(4)
+}
+
+// Return byte slice the next unread UnixSecondsU32 time.Time occupies
+func (c *Crate) SliceUnixSecondsU32() (slice []byte) {
+	return c.SliceBytes(4)
+}
+
+// Write time.Time to crate as 4 bytes: a U32 of whole seconds since the Unix epoch, truncating anything smaller than a second.
+// Times from 1970 through 2106-02-07 06:28:15 UTC can be written, earlier or later times panic. The time zone is not written, times are always read as UTC
+func (c *Crate) WriteUnixSecondsU32(val time.Time) {
+	unix := val.Unix()
+	if unix < 0 || unix > maxUnixSecondsU32 {
+		panic("LiteCrate: time " + val.UTC().Format(time.RFC3339) + " out of range for UnixSecondsU32")
+	}
+	c.WriteU32(uint32(unix))
+}
+
+// Read next 4 bytes from crate as UnixSecondsU32 time.Time (in UTC)
+func (c *Crate) ReadUnixSecondsU32() (val time.Time) {
+	return time.Unix(int64(c.ReadU32()), 0).UTC()
+}
+
+// Read next 4 bytes from crate as UnixSecondsU32 time.Time (in UTC) without advancing read index
+func (c *Crate) PeekUnixSecondsU32() (val time.Time) {
+	idx := c.read
+	val = c.ReadUnixSecondsU32()
+	c.read = idx
+	return val
+}
+
+// Use the time.Time pointed to by val according to mode (as UnixSecondsU32):
+// Write = 'write val into crate', Read = 'read from crate into val',
+// Peek = 'read from crate into val without advancing index'
+// Slice = 'Return the slice the next unread val occupies without altering val'
+func (c *Crate) UseUnixSecondsU32(val *time.Time, mode UseMode) (sliceModeData []byte) {
+	switch mode {
+	case Write:
+		c.WriteUnixSecondsU32(*val)
+	case Read:
+		*val = c.ReadUnixSecondsU32()
+	case Peek:
+		*val = c.PeekUnixSecondsU32()
+	case Discard:
+		c.DiscardUnixSecondsU32()
+	case Slice:
+		sliceModeData = c.SliceUnixSecondsU32()
+	default:
+		c.useCustomMode(val, mode, "UseUnixSecondsU32")
+	}
+	return sliceModeData
+}
+
+/**************
+	UNIX SECONDS U40
+***************/
+
+// Discard next unread UnixSecondsU40 time.Time in crate
+func (c *Crate) DiscardUnixSecondsU40() {
+	c.DiscardN(5)
+}
+
+// Return byte slice the next unread UnixSecondsU40 time.Time occupies
+func (c *Crate) SliceUnixSecondsU40() (slice []byte) {
+	return c.SliceBytes(5)
+}
+
+// Write time.Time to crate as 5 bytes: a U40 of whole seconds since the Unix epoch, truncating anything smaller than a second.
+// Times from 1970 through the year 36812 can be written, earlier or later times panic. The time zone is not written, times are always read as UTC
+func (c *Crate) WriteUnixSecondsU40(val time.Time) {
+	unix := val.Unix()
+	if unix < 0 || unix > maxUnixSecondsU40 {
+		panic("LiteCrate: time " + val.UTC().Format(time.RFC3339) + " out of range for UnixSecondsU40")
+	}
+	c.WriteU40(uint64(unix))
+}
+
+// Read next 5 bytes from crate as UnixSecondsU40 time.Time (in UTC)
+func (c *Crate) ReadUnixSecondsU40() (val time.Time) {
+	return time.Unix(int64(c.ReadU40()), 0).UTC()
+}
+
+// Read next 5 bytes from crate as UnixSecondsU40 time.Time (in UTC) without advancing read index
+func (c *Crate) PeekUnixSecondsU40() (val time.Time) {
+	idx := c.read
+	val = c.ReadUnixSecondsU40()
+	c.read = idx
+	return val
+}
+
+// Use the time.Time pointed to by val according to mode (as UnixSecondsU40):
+// Write = 'write val into crate', Read = 'read from crate into val',
+// Peek = 'read from crate into val without advancing index'
+// Slice = 'Return the slice the next unread val occupies without altering val'
+func (c *Crate) UseUnixSecondsU40(val *time.Time, mode UseMode) (sliceModeData []byte) {
+	switch mode {
+	case Write:
+		c.WriteUnixSecondsU40(*val)
+	case Read:
+		*val = c.ReadUnixSecondsU40()
+	case Peek:
+		*val = c.PeekUnixSecondsU40()
+	case Discard:
+		c.DiscardUnixSecondsU40()
+	case Slice:
+		sliceModeData = c.SliceUnixSecondsU40()
+	default:
+		c.useCustomMode(val, mode, "UseUnixSecondsU40")
+	}
+	return sliceModeData
+}
+
+/**************
+	UNIX MILLIS U48
+***************/
+
+// Discard next unread UnixMillisU48 time.Time in crate
+func (c *Crate) DiscardUnixMillisU48() {
+	c.DiscardN(6)
+}
+
+// Return byte slice the next unread UnixMillisU48 time.Time occupies
+func (c *Crate) SliceUnixMillisU48() (slice []byte) {
+	return c.SliceBytes(6)
+}
+
+// Write time.Time to crate as 6 bytes: a U48 of whole milliseconds since the Unix epoch, truncating anything smaller than a millisecond.
+// Times from 1970 through the year 10889 can be written, earlier or later times panic. The time zone is not written, times are always read as UTC
+func (c *Crate) WriteUnixMillisU48(val time.Time) {
+	unix := val.UnixMilli()
+	if unix < 0 || unix > maxUnixMillisU48 {
+		panic("LiteCrate: time " + val.UTC().Format(time.RFC3339) + " out of range for UnixMillisU48")
+	}
+	c.WriteU48(uint64(unix))
+}
+
+// Read next 6 bytes from crate as UnixMillisU48 time.Time (in UTC)
+func (c *Crate) ReadUnixMillisU48() (val time.Time) {
+	return time.UnixMilli(int64(c.ReadU48())).UTC()
+}
+
+// Read next 6 bytes from crate as UnixMillisU48 time.Time (in UTC) without advancing read index
+func (c *Crate) PeekUnixMillisU48() (val time.Time) {
+	idx := c.read
+	val = c.ReadUnixMillisU48()
+	c.read = idx
+	return val
+}
+
+// Use the time.Time pointed to by val according to mode (as UnixMillisU48):
+// Write = 'write val into crate', Read = 'read from crate into val',
+// Peek = 'read from crate into val without advancing index'
+// Slice = 'Return the slice the next unread val occupies without altering val'
+func (c *Crate) UseUnixMillisU48(val *time.Time, mode UseMode) (sliceModeData []byte) {
+	switch mode {
+	case Write:
+		c.WriteUnixMillisU48(*val)
+	case Read:
+		*val = c.ReadUnixMillisU48()
+	case Peek:
+		*val = c.PeekUnixMillisU48()
+	case Discard:
+		c.DiscardUnixMillisU48()
+	case Slice:
+		sliceModeData = c.SliceUnixMillisU48()
+	default:
+		c.useCustomMode(val, mode, "UseUnixMillisU48")
+	}
+	return sliceModeData
+}
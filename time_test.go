@@ -0,0 +1,136 @@
+package litecrate_test
+
+import (
+	"testing"
+	"time"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+var timeValues = []time.Time{
+	{},
+	time.Unix(0, 0),
+	time.Unix(-1, 999999999),
+	time.Date(2022, 6, 15, 12, 30, 45, 123456789, time.UTC),
+	time.Date(1850, 1, 1, 0, 0, 0, 1, time.FixedZone("test", 3600)),
+	time.Date(9999, 12, 31, 23, 59, 59, 999999999, time.UTC),
+}
+
+func TestTime(t *testing.T) {
+	crate := lite.NewCrate(16, lite.FlagAutoDouble)
+	for _, val := range timeValues {
+		crate.Reset()
+		crate.UseTime(&val, lite.Write)
+		if crate.WriteIndex() != 12 {
+			t.Errorf("WriteTime(%v) - FAIL: wrote %d bytes", val, crate.WriteIndex())
+		}
+		var peeked, read time.Time
+		crate.UseTime(&peeked, lite.Peek)
+		slice := crate.UseTime(&read, lite.Slice)
+		crate.UseTime(&read, lite.Read)
+		if !read.Equal(val) || !peeked.Equal(val) || read.Location() != time.UTC || len(slice) != 12 {
+			t.Errorf("ReadTime(%v) - FAIL: read %v, peeked %v", val, read, peeked)
+		}
+	}
+	crate.Reset()
+	crate.WriteI64(0)
+	crate.WriteU32(uint32(time.Second))
+	if catchPanic[error](func() { crate.ReadTime() }) == nil {
+		t.Errorf("ReadTime() - FAIL: nanoseconds over one second did not panic")
+	}
+	var val time.Time
+	if catchPanic[error](func() { crate.UseTime(&val, lite.UseMode(255)) }) == nil {
+		t.Errorf("UseTime - FAIL: invalid mode did not panic")
+	}
+}
+
+func TestTimeDelta(t *testing.T) {
+	crate := lite.NewCrate(16, lite.FlagAutoDouble)
+	base := time.Date(2022, 6, 15, 12, 0, 0, 500000000, time.UTC)
+	for _, val := range append(timeValues, base, base.Add(time.Millisecond), base.Add(-time.Nanosecond), base.Add(-90*time.Minute)) {
+		crate.Reset()
+		n, _ := crate.UseTimeDelta(&val, base, lite.Write)
+		var peeked, read time.Time
+		crate.UseTimeDelta(&peeked, base, lite.Peek)
+		_, slice := crate.UseTimeDelta(&read, base, lite.Slice)
+		m, _ := crate.UseTimeDelta(&read, base, lite.Read)
+		if !read.Equal(val) || !peeked.Equal(val) || n != m || uint64(len(slice)) != n || crate.ReadsLeft() != 0 {
+			t.Errorf("ReadTimeDelta(%v) - FAIL: read %v, peeked %v, %d/%d bytes", val, read, peeked, n, m)
+		}
+		crate.ResetReadIndex()
+		if d, _ := crate.UseTimeDelta(&read, base, lite.Discard); d != n {
+			t.Errorf("DiscardTimeDelta(%v) - FAIL: discarded %d of %d bytes", val, d, n)
+		}
+	}
+	crate.Reset()
+	if n := crate.WriteTimeDelta(base.Add(time.Second), base); n != 2 {
+		t.Errorf("WriteTimeDelta() - FAIL: one second delta took %d bytes", n)
+	}
+	crate.Reset()
+	crate.WriteVarint(0)
+	crate.WriteUVarint(uint64(time.Second))
+	if catchPanic[error](func() { crate.ReadTimeDelta(base) }) == nil {
+		t.Errorf("ReadTimeDelta() - FAIL: nanoseconds over one second did not panic")
+	}
+}
+
+func TestDuration(t *testing.T) {
+	crate := lite.NewCrate(16, lite.FlagAutoDouble)
+	for _, val := range []time.Duration{0, time.Nanosecond, -time.Hour, 1<<63 - 1, -1 << 63} {
+		crate.Reset()
+		n, _ := crate.UseDuration(&val, lite.Write)
+		var peeked, read time.Duration
+		crate.UseDuration(&peeked, lite.Peek)
+		_, slice := crate.UseDuration(&read, lite.Slice)
+		m, _ := crate.UseDuration(&read, lite.Read)
+		if read != val || peeked != val || n != m || uint64(len(slice)) != n {
+			t.Errorf("ReadDuration(%v) - FAIL: read %v, peeked %v", val, read, peeked)
+		}
+	}
+}
+
+func TestUnixCompact(t *testing.T) {
+	crate := lite.NewCrate(16, lite.FlagAutoDouble)
+	type compactUse func(val *time.Time, mode lite.UseMode) []byte
+	cases := []struct {
+		name  string
+		use   compactUse
+		size  uint64
+		unit  time.Duration
+		first time.Time
+		last  time.Time
+	}{
+		{"UnixSecondsU32", crate.UseUnixSecondsU32, 4, time.Second, time.Unix(0, 0), time.Date(2106, 2, 7, 6, 28, 15, 0, time.UTC)},
+		{"UnixSecondsU40", crate.UseUnixSecondsU40, 5, time.Second, time.Unix(0, 0), time.Date(36812, 2, 20, 0, 36, 15, 0, time.UTC)},
+		{"UnixMillisU48", crate.UseUnixMillisU48, 6, time.Millisecond, time.Unix(0, 0), time.Date(10889, 8, 2, 5, 31, 50, 655000000, time.UTC)},
+	}
+	now := time.Date(2022, 6, 15, 12, 30, 45, 123456789, time.FixedZone("test", 3600))
+	for _, tc := range cases {
+		for _, val := range []time.Time{tc.first, tc.last, now} {
+			crate.Reset()
+			tc.use(&val, lite.Write)
+			var peeked, read time.Time
+			tc.use(&peeked, lite.Peek)
+			slice := tc.use(&read, lite.Slice)
+			tc.use(&read, lite.Read)
+			expected := val.Truncate(tc.unit)
+			if !read.Equal(expected) || !peeked.Equal(expected) || read.Location() != time.UTC || uint64(len(slice)) != tc.size || crate.WriteIndex() != tc.size {
+				t.Errorf("Use%s(%v) - FAIL: read %v, peeked %v, wrote %d bytes", tc.name, val, read, peeked, crate.WriteIndex())
+			}
+			crate.ResetReadIndex()
+			tc.use(&read, lite.Discard)
+			if crate.ReadsLeft() != 0 {
+				t.Errorf("Use%s(Discard) - FAIL: %d bytes left", tc.name, crate.ReadsLeft())
+			}
+		}
+		for _, val := range []time.Time{tc.first.Add(-tc.unit), tc.last.Add(tc.unit)} {
+			if catchPanic[error](func() { tc.use(&val, lite.Write) }) == nil {
+				t.Errorf("Use%s(%v) - FAIL: out of range time did not panic", tc.name, val)
+			}
+		}
+		var val time.Time
+		if catchPanic[error](func() { tc.use(&val, lite.UseMode(255)) }) == nil {
+			t.Errorf("Use%s - FAIL: invalid mode did not panic", tc.name)
+		}
+	}
+}
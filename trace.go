@@ -0,0 +1,120 @@
+package litecrate
+
+import (
+	"io"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// Most stack frames searched for the method that caused a traced read or write
+const traceStackDepth = 32
+
+const hexDigits = "0123456789abcdef"
+
+// Prefix of the names of every function in this package, as reported by runtime.Frame
+var tracePackage = reflect.TypeOf(Crate{}).PkgPath() + "."
+
+// One read or write recorded by a crate flagged with FlagTrace
+type TraceEntry struct {
+	Write  bool   // Whether bytes were written (otherwise they were read, peeked, sliced or discarded)
+	Offset uint64 // Index of the first byte
+	Length uint64 // Number of bytes
+	Method string // The method called from outside LiteCrate that caused it ("WriteU16", "UseStringWithCounter"...)
+	Caller string // File name and line of the call to Method ("point.go:14")
+	Bytes  []byte // The bytes in the crate at Offset when Trace() was called (shorter than Length if they were not all there)
+	site   uintptr
+}
+
+// Returns the entry as one line, such as:
+//
+//	write @12 +3 WriteU24 point.go:14 [01 02 03]
+func (e TraceEntry) String() string {
+	out := []byte("read  @")
+	if e.Write {
+		out = []byte("write @")
+	}
+	out = append(out, intStr(e.Offset)...)
+	out = append(out, " +"...)
+	out = append(out, intStr(e.Length)...)
+	out = append(out, ' ')
+	out = append(out, e.Method...)
+	if e.Caller != "" {
+		out = append(out, ' ')
+		out = append(out, e.Caller...)
+	}
+	out = append(out, " ["...)
+	for i, b := range e.Bytes {
+		if i > 0 {
+			out = append(out, ' ')
+		}
+		out = append(out, hexDigits[b>>4], hexDigits[b&0xF])
+	}
+	return string(append(out, ']'))
+}
+
+/**************
+	TRACE
+***************/
+
+// Returns every read and write recorded since the crate was flagged with FlagTrace
+// (or since ClearTrace()), in the order they happened.
+// Multi-part values appear as several entries: WriteStringWithCounter() records its counter then its bytes.
+//
+// Bytes holds what is in the crate now, which differs from what was read or written
+// if those bytes have since been overwritten or rolled back
+func (c *Crate) Trace() []TraceEntry {
+	entries := make([]TraceEntry, len(c.trace))
+	copy(entries, c.trace)
+	for i := range entries {
+		start, end := entries[i].Offset, entries[i].Offset+entries[i].Length
+		if end > c.write {
+			end = c.write
+		}
+		if start < end {
+			entries[i].Bytes = c.data[start:end:end]
+		}
+	}
+	return entries
+}
+
+// Write every entry of Trace() to w, one per line
+func (c *Crate) DumpTrace(w io.Writer) error {
+	for _, entry := range c.Trace() {
+		if _, err := io.WriteString(w, entry.String()+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Discard every recorded read and write
+func (c *Crate) ClearTrace() {
+	c.trace = c.trace[:0]
+}
+
+func (c *Crate) recordTrace(write bool, offset uint64, length uint64) {
+	var pcs [traceStackDepth]uintptr
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs[:])])
+	entry := TraceEntry{Write: write, Offset: offset, Length: length}
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, tracePackage) {
+			if frame.Function != "" {
+				entry.Caller = filepath.Base(frame.File) + ":" + intStr(frame.Line)
+				entry.site = frame.PC
+			}
+			break
+		}
+		entry.Method = frame.Function
+		if !more {
+			break
+		}
+	}
+	entry.Method = strings.TrimPrefix(strings.TrimPrefix(entry.Method, tracePackage), "(*Crate).")
+	if i := strings.IndexByte(entry.Method, '['); i >= 0 {
+		entry.Method = entry.Method[:i]
+	}
+	c.trace = append(c.trace, entry)
+}
@@ -0,0 +1,67 @@
+package litecrate_test
+
+import (
+	"strings"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+type tracedPoint struct {
+	X, Y int16
+}
+
+func (p *tracedPoint) UseSelf(crate *lite.Crate, mode lite.UseMode) {
+	crate.UseI16(&p.X, mode)
+	crate.UseI16(&p.Y, mode)
+}
+
+func TestTrace(t *testing.T) {
+	crate := lite.NewCrate(4, lite.FlagAutoDouble|lite.FlagTrace)
+	if !crate.WillTrace() {
+		t.Fatalf("WillTrace() - FAIL: flag not set")
+	}
+	crate.WriteU24(0x030201)
+	crate.WriteStringWithCounter("hi")
+	crate.WriteUVarint(300)
+	crate.WriteSelfSerializer(&tracedPoint{X: 1, Y: -1})
+	crate.ReadU24()
+	crate.PeekStringWithCounter()
+	expected := []string{
+		"write @0 +3 WriteU24 trace_test.go:24 [01 02 03]",
+		"write @3 +1 WriteStringWithCounter trace_test.go:25 [03]",
+		"write @4 +2 WriteStringWithCounter trace_test.go:25 [68 69]",
+		"write @6 +2 WriteUVarint trace_test.go:26 [ac 02]",
+		"write @8 +2 UseI16 trace_test.go:15 [01 00]",
+		"write @10 +2 UseI16 trace_test.go:16 [ff ff]",
+		"read  @0 +3 ReadU24 trace_test.go:28 [01 02 03]",
+		"read  @3 +1 PeekStringWithCounter trace_test.go:29 [03]",
+		"read  @4 +2 PeekStringWithCounter trace_test.go:29 [68 69]",
+	}
+	var out strings.Builder
+	crate.DumpTrace(&out)
+	if out.String() != strings.Join(expected, "\n")+"\n" {
+		t.Errorf("Trace() - FAIL:\n%s", out.String())
+	}
+
+	crate.ClearTrace()
+	crate.ResetReadIndex()
+	crate.DiscardN(3)
+	crate.ReadU64()
+	crate.ReadU8()
+	if trace := crate.Trace(); len(trace) != 2 || trace[0].Method != "ReadU64" || trace[0].Offset != 3 || len(trace[0].Bytes) != 8 {
+		t.Errorf("Trace(after clear) - FAIL: %v", trace)
+	}
+	if catchPanic[error](func() { crate.ReadU8() }) == nil {
+		t.Fatalf("ReadU8() - FAIL: read past end did not panic")
+	}
+	if trace := crate.Trace(); len(trace) != 3 || trace[2].Offset != 12 || trace[2].Bytes != nil {
+		t.Errorf("Trace(failed read) - FAIL: %v", trace)
+	}
+
+	untraced := lite.NewCrate(4, lite.FlagAutoDouble)
+	untraced.WriteU32(1)
+	if len(untraced.Trace()) != 0 {
+		t.Errorf("Trace(unflagged) - FAIL: recorded %v", untraced.Trace())
+	}
+}
@@ -0,0 +1,103 @@
+package litecrate
+
+import (
+	"errors"
+)
+
+// Returned by TryWrite() when a crate flagged with FlagNoGrow runs out of space
+var ErrNoGrow = errors.New("LiteCrate: write exceeds capacity of crate flagged with FlagNoGrow")
+
+// Index (and number of UseRef() objects) saved by BeginWrite() or BeginRead()
+type txState struct {
+	index   uint64
+	objects int
+}
+
+/**************
+	TRANSACTIONS
+***************/
+
+// Save the write index so a record can be abandoned with RollbackWrite() if it fails part way through
+// (for example when a value fails validation mid-encode), instead of leaving a partial record in the crate.
+// Every BeginWrite() must be ended by CommitWrite() or RollbackWrite(), and transactions may be nested
+func (c *Crate) BeginWrite() {
+	c.writeTx = append(c.writeTx, txState{index: c.write, objects: len(c.graph.written)})
+}
+
+// Keep everything written since the matching BeginWrite()
+func (c *Crate) CommitWrite() {
+	c.popTx(&c.writeTx, "CommitWrite", "BeginWrite")
+}
+
+// Discard everything written since the matching BeginWrite(), returning the write index to where it was.
+// UseRef() objects and UseBytesDedup() payloads first written since then are forgotten
+func (c *Crate) RollbackWrite() {
+	tx := c.popTx(&c.writeTx, "RollbackWrite", "BeginWrite")
+	c.write = tx.index
+	c.keyOK = false
+	c.gen += 1
+	for val, pos := range c.dedup {
+		if pos >= tx.index {
+			delete(c.dedup, val)
+		}
+	}
+	for ptr, id := range c.graph.written {
+		if id >= uint64(tx.objects) {
+			delete(c.graph.written, ptr)
+		}
+	}
+}
+
+// Save the read index so a record can be re-read with RollbackRead() if it fails part way through.
+// Every BeginRead() must be ended by CommitRead() or RollbackRead(), and transactions may be nested
+func (c *Crate) BeginRead() {
+	c.readTx = append(c.readTx, txState{index: c.read, objects: len(c.graph.read)})
+}
+
+// Keep the read index where it is now
+func (c *Crate) CommitRead() {
+	c.popTx(&c.readTx, "CommitRead", "BeginRead")
+}
+
+// Return the read index to where it was at the matching BeginRead(),
+// forgetting UseRef() objects read since then
+func (c *Crate) RollbackRead() {
+	tx := c.popTx(&c.readTx, "RollbackRead", "BeginRead")
+	c.read = tx.index
+	if tx.objects < len(c.graph.read) {
+		c.graph.read = c.graph.read[:tx.objects]
+	}
+}
+
+func (c *Crate) popTx(stack *[]txState, name string, begin string) txState {
+	last := len(*stack) - 1
+	if last < 0 {
+		panic("LiteCrate: " + name + "() called without " + begin + "()")
+	}
+	tx := (*stack)[last]
+	*stack = (*stack)[:last]
+	return tx
+}
+
+// Call write, and if it runs out of space in a crate flagged with FlagNoGrow, roll back
+// everything it wrote and return ErrNoGrow instead of panicking. Other panics are not recovered
+func (c *Crate) TryWrite(write func()) (err error) {
+	txs, sections := len(c.writeTx), len(c.sections)
+	c.BeginWrite()
+	defer func() {
+		if r := recover(); r != nil {
+			// ErrNoGrow may arrive wrapped in a *PathError from inside a slice or map element
+			if err, ok := r.(error); !ok || !errors.Is(err, ErrNoGrow) {
+				c.writeTx = c.writeTx[:txs]
+				panic(r)
+			}
+			c.writeTx = c.writeTx[:txs+1]
+			c.sections = c.sections[:sections]
+			c.RollbackWrite()
+			err = ErrNoGrow
+		}
+	}()
+	write()
+	c.CommitWrite()
+	return nil
+}
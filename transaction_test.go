@@ -0,0 +1,116 @@
+package litecrate_test
+
+import (
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+func TestWriteTransaction(t *testing.T) {
+	crate := lite.NewCrate(16, lite.FlagAutoDouble)
+	crate.WriteStringWithCounter("kept")
+	key := crate.Key()
+	crate.BeginWrite()
+	crate.WriteBytesDedup([]byte("payload"))
+	node := &graphNode{Name: "node"}
+	lite.UseRef(crate, lite.Write, &node, (*graphNode).UseSelf)
+	crate.BeginWrite()
+	crate.WriteU32(7)
+	crate.CommitWrite()
+	crate.RollbackWrite()
+	if crate.WriteIndex() != 5 || crate.Key() != key {
+		t.Errorf("RollbackWrite() - FAIL: write index %d != 5", crate.WriteIndex())
+	}
+
+	crate.WriteBytesDedup([]byte("payload"))
+	lite.UseRef(crate, lite.Write, &node, (*graphNode).UseSelf)
+	crate.ReadStringWithCounter()
+	if got := crate.ReadBytesDedup(); string(got) != "payload" {
+		t.Errorf("RollbackWrite() - FAIL: dedup referenced rolled back bytes: %q", got)
+	}
+	var got *graphNode
+	lite.UseRef(crate, lite.Read, &got, (*graphNode).UseSelf)
+	if got == nil || got.Name != "node" || crate.ReadsLeft() != 0 {
+		t.Errorf("RollbackWrite() - FAIL: ref referenced rolled back object")
+	}
+	if crate.Key() == key {
+		t.Errorf("RollbackWrite() - FAIL: stale key after rewrite")
+	}
+	if catchPanic[error](func() { crate.CommitWrite() }) == nil || catchPanic[error](func() { crate.RollbackWrite() }) == nil {
+		t.Errorf("CommitWrite/RollbackWrite() - FAIL: no panic without BeginWrite()")
+	}
+}
+
+func TestReadTransaction(t *testing.T) {
+	crate := lite.NewCrate(16, lite.FlagAutoDouble)
+	node := &graphNode{Name: "node"}
+	crate.WriteU8(1)
+	lite.UseRef(crate, lite.Write, &node, (*graphNode).UseSelf)
+	lite.UseRef(crate, lite.Write, &node, (*graphNode).UseSelf)
+	crate.ReadU8()
+	crate.BeginRead()
+	var got *graphNode
+	lite.UseRef(crate, lite.Read, &got, (*graphNode).UseSelf)
+	crate.RollbackRead()
+	if crate.ReadIndex() != 1 {
+		t.Errorf("RollbackRead() - FAIL: read index %d != 1", crate.ReadIndex())
+	}
+	crate.BeginRead()
+	var first, second *graphNode
+	lite.UseRef(crate, lite.Read, &first, (*graphNode).UseSelf)
+	lite.UseRef(crate, lite.Read, &second, (*graphNode).UseSelf)
+	crate.CommitRead()
+	if first != second || first == got || crate.ReadsLeft() != 0 {
+		t.Errorf("RollbackRead() - FAIL: objects read during rolled back read were kept")
+	}
+	if catchPanic[error](func() { crate.RollbackRead() }) == nil {
+		t.Errorf("RollbackRead() - FAIL: no panic without BeginRead()")
+	}
+	crate.BeginRead()
+	crate.Reset()
+	if catchPanic[error](func() { crate.CommitRead() }) == nil {
+		t.Errorf("Reset() - FAIL: read transaction survived reset")
+	}
+}
+
+func TestNoGrow(t *testing.T) {
+	crate := lite.NewCrate(8, lite.FlagAutoDouble|lite.FlagNoGrow)
+	if crate.WillAutoGrow() {
+		t.Errorf("WillAutoGrow() - FAIL: true for crate flagged with FlagNoGrow")
+	}
+	if err := crate.TryWrite(func() { crate.WriteU32(1) }); err != nil || crate.WriteIndex() != 4 {
+		t.Errorf("TryWrite() - FAIL: err = %v, index %d", err, crate.WriteIndex())
+	}
+	err := crate.TryWrite(func() {
+		crate.WriteU16(2)
+		crate.BeginSection()
+		crate.WriteU32(3)
+		crate.EndSection()
+	})
+	if err != lite.ErrNoGrow || crate.WriteIndex() != 4 || crate.Cap() != 8 || crate.GrowCount() != 0 {
+		t.Errorf("TryWrite() - FAIL: err = %v, index %d, cap %d", err, crate.WriteIndex(), crate.Cap())
+	}
+	if catchPanic[error](func() { crate.WriteU64(4) }) == nil {
+		t.Errorf("FlagNoGrow - FAIL: overflow outside TryWrite() did not panic")
+	}
+	if catchPanic[error](func() { crate.TryWrite(func() { crate.ReadU64() }) }) == nil {
+		t.Errorf("TryWrite() - FAIL: recovered unrelated panic")
+	}
+	nums := []uint16{1, 2, 3}
+	if err := crate.TryWrite(func() { lite.UseSlice(crate, lite.Write, &nums, crate.UseU16) }); err != lite.ErrNoGrow || crate.WriteIndex() != 4 {
+		t.Errorf("TryWrite() - FAIL: overflow inside slice element returned %v, index %d", err, crate.WriteIndex())
+	}
+
+	crate = lite.NewCrate(1, lite.FlagAutoDouble)
+	for i := 0; i < 100; i += 1 {
+		crate.WriteU8(uint8(i))
+	}
+	grows := crate.GrowCount()
+	crate.Reset()
+	for i := 0; i < 100; i += 1 {
+		crate.WriteU8(uint8(i))
+	}
+	if grows == 0 || grows > 7 || crate.GrowCount() != grows {
+		t.Errorf("GrowCount() - FAIL: %d grows, then %d after reuse", grows, crate.GrowCount())
+	}
+}
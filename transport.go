@@ -0,0 +1,157 @@
+//go:build !litecrate_core && !litecrate_nonet
+
+package litecrate
+
+import (
+	"io"
+	"net"
+	"sync"
+)
+
+// Largest datagram DatagramTransport sends or receives when MaxSize is 0 (the largest UDP payload over IPv4)
+const DefaultDatagramSize = 65507
+
+/**************
+	TRANSPORT
+***************/
+
+// A Transport sends and receives whole crates over some connection, so that code built on top
+// of crates can be written once and run over TCP, UDP, WebSockets, in-memory pipes,
+// or any other link (QUIC streams, serial ports...) by implementing these three methods.
+//
+// SendCrate() sends the crate's written data as one message without altering the crate,
+// or returns the crate's error without sending anything if it failed under FlagNoPanic.
+// RecvCrate() returns the next message in a new crate, or io.EOF once the other end has closed.
+// Close() closes the underlying connection, after which both methods return errors
+type Transport interface {
+	SendCrate(crate *Crate) error
+	RecvCrate() (*Crate, error)
+	Close() error
+}
+
+// Sends crates over a reliable byte stream (TCP connection, unix socket, pipe) as frames written and read by Framer
+type StreamTransport struct {
+	Conn   io.ReadWriteCloser // The stream, such as a *net.TCPConn
+	Framer Framer             // Options for the frames sent and received, the zero value is ready to use
+}
+
+func (t *StreamTransport) SendCrate(crate *Crate) error {
+	return t.Framer.WriteFrame(t.Conn, crate)
+}
+
+func (t *StreamTransport) RecvCrate() (*Crate, error) {
+	return t.Framer.ReadFrame(t.Conn)
+}
+
+func (t *StreamTransport) Close() error {
+	return t.Conn.Close()
+}
+
+// Sends each crate as a single datagram over a connected packet socket, such as the *net.UDPConn
+// returned by net.DialUDP(). Like the socket itself it does not retry, reorder or deduplicate:
+// messages may be lost, duplicated or arrive out of order
+type DatagramTransport struct {
+	Conn       net.Conn // The connected packet socket
+	MaxSize    int      // Largest crate sent or received, 0 = DefaultDatagramSize
+	Flags      uint8    // Flags for crates returned by RecvCrate()
+	readMutex  sync.Mutex
+	readBuffer []byte
+}
+
+// Send the crate as one datagram, returns ErrFrameTooLarge if it is longer than MaxSize
+// or the crate's error if it failed under FlagNoPanic
+func (t *DatagramTransport) SendCrate(crate *Crate) error {
+	if err := crate.Err(); err != nil {
+		return err
+	}
+	if crate.write > uint64(t.maxSize()) {
+		return ErrFrameTooLarge
+	}
+	_, err := t.Conn.Write(crate.Data())
+	return err
+}
+
+// Receive the next datagram, returns ErrFrameTooLarge if it is longer than MaxSize
+func (t *DatagramTransport) RecvCrate() (*Crate, error) {
+	t.readMutex.Lock()
+	defer t.readMutex.Unlock()
+	maxSize := t.maxSize()
+	if len(t.readBuffer) != maxSize+1 {
+		t.readBuffer = make([]byte, maxSize+1)
+	}
+	n, err := t.Conn.Read(t.readBuffer)
+	if err != nil {
+		return nil, err
+	}
+	if n > maxSize {
+		return nil, ErrFrameTooLarge
+	}
+	crate := NewCrate(uint64(n), t.Flags)
+	crate.WriteBytes(t.readBuffer[:n])
+	return crate, nil
+}
+
+func (t *DatagramTransport) Close() error {
+	return t.Conn.Close()
+}
+
+func (t *DatagramTransport) maxSize() int {
+	if t.MaxSize == 0 {
+		return DefaultDatagramSize
+	}
+	return t.MaxSize
+}
+
+// One end of an in-memory connection made by NewPipeTransport()
+type PipeTransport struct {
+	Flags  uint8 // Flags for crates returned by RecvCrate()
+	send   chan<- []byte
+	recv   <-chan []byte
+	closed chan struct{}
+	once   *sync.Once
+}
+
+// Returns the two ends of an in-memory connection: crates sent on one are received by the other.
+// Like net.Pipe(), there is no buffering, so SendCrate() blocks until the other end calls RecvCrate().
+// Closing either end closes both
+func NewPipeTransport() (a *PipeTransport, b *PipeTransport) {
+	aToB, bToA := make(chan []byte), make(chan []byte)
+	closed, once := make(chan struct{}), new(sync.Once)
+	a = &PipeTransport{send: aToB, recv: bToA, closed: closed, once: once}
+	b = &PipeTransport{send: bToA, recv: aToB, closed: closed, once: once}
+	return a, b
+}
+
+// Send a copy of the crate's written data, returns io.ErrClosedPipe if the pipe is closed
+// or the crate's error if it failed under FlagNoPanic
+func (t *PipeTransport) SendCrate(crate *Crate) error {
+	if err := crate.Err(); err != nil {
+		return err
+	}
+	data := append([]byte{}, crate.Data()...)
+	select {
+	case <-t.closed:
+		return io.ErrClosedPipe
+	default:
+	}
+	select {
+	case t.send <- data:
+		return nil
+	case <-t.closed:
+		return io.ErrClosedPipe
+	}
+}
+
+func (t *PipeTransport) RecvCrate() (*Crate, error) {
+	select {
+	case data := <-t.recv:
+		return OpenCrate(data, t.Flags), nil
+	case <-t.closed:
+		return nil, io.EOF
+	}
+}
+
+func (t *PipeTransport) Close() error {
+	t.once.Do(func() { close(t.closed) })
+	return nil
+}
@@ -0,0 +1,178 @@
+//go:build !litecrate_core && !litecrate_nonet
+
+package litecrate_test
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"sort"
+	"testing"
+	"time"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+// Sends crates of several sizes each way over a connected pair of transports
+func checkTransportPair(t *testing.T, name string, a lite.Transport, b lite.Transport) {
+	for _, size := range []int{0, 1, 125, 300, 70000} {
+		for _, pair := range [][2]lite.Transport{{a, b}, {b, a}} {
+			if size > 60000 && name == "DatagramTransport" {
+				continue
+			}
+			sent := lite.OpenCrate(bytes.Repeat([]byte{byte(size)}, size), lite.FlagStatic)
+			errs := make(chan error, 1)
+			go func() { errs <- pair[0].SendCrate(sent) }()
+			received, err := pair[1].RecvCrate()
+			if err != nil || !bytes.Equal(received.Data(), sent.Data()) {
+				t.Fatalf("%s(%d bytes) - FAIL: %v", name, size, err)
+			}
+			if err := <-errs; err != nil {
+				t.Fatalf("%s.SendCrate(%d bytes) - FAIL: %v", name, size, err)
+			}
+		}
+	}
+}
+
+func TestStreamTransport(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("StreamTransport - SKIP: %v", err)
+	}
+	defer ln.Close()
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, _ := ln.Accept()
+		accepted <- conn
+	}()
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("StreamTransport - FAIL: %v", err)
+	}
+	client := &lite.StreamTransport{Conn: conn}
+	server := &lite.StreamTransport{Conn: <-accepted}
+	checkTransportPair(t, "StreamTransport", client, server)
+	client.Close()
+	if _, err := server.RecvCrate(); err != io.EOF {
+		t.Errorf("StreamTransport.RecvCrate(closed) - FAIL: %v", err)
+	}
+	server.Close()
+}
+
+func TestDatagramTransport(t *testing.T) {
+	serverConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Skipf("DatagramTransport - SKIP: %v", err)
+	}
+	clientConn, err := net.DialUDP("udp", nil, serverConn.LocalAddr().(*net.UDPAddr))
+	if err != nil {
+		t.Fatalf("DatagramTransport - FAIL: %v", err)
+	}
+	serverConn.Close()
+	serverConn, err = net.DialUDP("udp", serverConn.LocalAddr().(*net.UDPAddr), clientConn.LocalAddr().(*net.UDPAddr))
+	if err != nil {
+		t.Fatalf("DatagramTransport - FAIL: %v", err)
+	}
+	client := &lite.DatagramTransport{Conn: clientConn}
+	server := &lite.DatagramTransport{Conn: serverConn, MaxSize: 100000}
+	checkTransportPair(t, "DatagramTransport", client, server)
+
+	small := &lite.DatagramTransport{Conn: serverConn, MaxSize: 4}
+	if err := small.SendCrate(lite.OpenCrate(make([]byte, 5), lite.FlagStatic)); err != lite.ErrFrameTooLarge {
+		t.Errorf("DatagramTransport.SendCrate(too large) - FAIL: %v", err)
+	}
+	client.SendCrate(lite.OpenCrate(make([]byte, 5), lite.FlagStatic))
+	if _, err := small.RecvCrate(); err != lite.ErrFrameTooLarge {
+		t.Errorf("DatagramTransport.RecvCrate(too large) - FAIL: %v", err)
+	}
+	client.Close()
+	server.Close()
+}
+
+func TestPipeTransport(t *testing.T) {
+	a, b := lite.NewPipeTransport()
+	checkTransportPair(t, "PipeTransport", a, b)
+	sent := lite.OpenCrate([]byte{1, 2, 3}, lite.FlagStatic)
+	go a.SendCrate(sent)
+	received, _ := b.RecvCrate()
+	received.Data()[0] = 9
+	if sent.Data()[0] != 1 {
+		t.Errorf("PipeTransport - FAIL: received crate shares data with sent crate")
+	}
+	if err := a.SendCrate(failedCrate()); err == nil {
+		t.Errorf("PipeTransport.SendCrate(failed) - FAIL: sent failed crate")
+	}
+	b.Close()
+	if err := a.SendCrate(sent); err != io.ErrClosedPipe {
+		t.Errorf("PipeTransport.SendCrate(closed) - FAIL: %v", err)
+	}
+	if _, err := a.RecvCrate(); err != io.EOF {
+		t.Errorf("PipeTransport.RecvCrate(closed) - FAIL: %v", err)
+	}
+	if err := a.Close(); err != nil {
+		t.Errorf("PipeTransport.Close(twice) - FAIL: %v", err)
+	}
+}
+
+var _ lite.Transport = (*lite.SimTransport)(nil)
+
+func TestSimNetwork(t *testing.T) {
+	_, a, b := lite.NewSimNetwork(1, lite.SimConditions{})
+	checkTransportPair(t, "SimTransport", a, b)
+
+	network, a, b := lite.NewSimNetwork(1, lite.SimConditions{Latency: 10 * time.Millisecond})
+	a.SendCrate(lite.OpenCrate([]byte{1}, lite.FlagStatic))
+	if _, ok, _ := b.TryRecvCrate(); ok || network.InFlight() != 1 {
+		t.Errorf("SimTransport.TryRecvCrate() - FAIL: message arrived before its latency")
+	}
+	network.Advance(10 * time.Millisecond)
+	if got, ok, err := b.TryRecvCrate(); !ok || err != nil || got.Data()[0] != 1 {
+		t.Errorf("SimTransport.TryRecvCrate() - FAIL: message did not arrive after its latency")
+	}
+	a.SendCrate(lite.OpenCrate([]byte{2}, lite.FlagStatic))
+	if got, err := b.RecvCrate(); err != nil || got.Data()[0] != 2 || network.Now() != 20*time.Millisecond {
+		t.Errorf("SimTransport.RecvCrate() - FAIL: clock at %v after waiting for message", network.Now())
+	}
+
+	// Same seed and calls, same deliveries
+	conditions := lite.SimConditions{Loss: 0.2, Duplicate: 0.1, Reorder: 0.1, Latency: 5 * time.Millisecond, Jitter: 20 * time.Millisecond}
+	run := func() (received []byte, stats lite.SimStats) {
+		network, a, b := lite.NewSimNetwork(42, conditions)
+		for i := 0; i < 200; i += 1 {
+			a.SendCrate(lite.OpenCrate([]byte{byte(i)}, lite.FlagStatic))
+			network.Advance(time.Millisecond)
+			for {
+				crate, ok, _ := b.TryRecvCrate()
+				if !ok {
+					break
+				}
+				received = append(received, crate.Data()[0])
+			}
+		}
+		for network.InFlight() > 0 {
+			crate, _ := b.RecvCrate()
+			received = append(received, crate.Data()[0])
+		}
+		return received, network.Stats()
+	}
+	first, stats := run()
+	second, _ := run()
+	if !bytes.Equal(first, second) {
+		t.Errorf("SimNetwork - FAIL: same seed delivered different messages")
+	}
+	if stats.Sent != 200 || stats.Dropped == 0 || stats.Duplicated == 0 || stats.Reordered == 0 ||
+		stats.Received != stats.Sent-stats.Dropped+stats.Duplicated || uint64(len(first)) != stats.Received {
+		t.Errorf("SimNetwork - FAIL: stats %+v for %d messages received", stats, len(first))
+	}
+	if sort.SliceIsSorted(first, func(i, j int) bool { return first[i] < first[j] }) {
+		t.Errorf("SimNetwork - FAIL: jitter and reordering delivered every message in order")
+	}
+
+	a.Close()
+	if _, err := b.RecvCrate(); err != io.EOF {
+		t.Errorf("SimTransport.RecvCrate(closed) - FAIL: %v", err)
+	}
+	if err := b.SendCrate(lite.OpenCrate([]byte{1}, lite.FlagStatic)); err != io.ErrClosedPipe {
+		t.Errorf("SimTransport.SendCrate(closed) - FAIL: %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package litecrate
+
+/**************
+	UNION
+***************/
+
+// Use a tagged union (a value that may be one of several types) according to mode.
+//
+// The tag (2 bytes) is written first and selects which function in cases uses the value:
+// when writing *tag selects the case, when reading *tag is set to the tag that was read
+// before its case is called. Panics if the tag has no case.
+//
+// Write = 'write the tag and value into crate', Read = 'read the tag and value from crate',
+// Peek = 'read the tag and value without advancing index'
+// Slice = 'Return the slice the tag and value occupy'
+//
+// Example:
+//
+//	crate.UseUnion(&msg.Type, map[uint16]func(mode UseMode){
+//		MsgLogin: func(mode UseMode) { crate.UseSelfSerializer(&msg.Login, mode) },
+//		MsgChat:  func(mode UseMode) { crate.UseStringWithCounter(&msg.Chat, mode) },
+//	}, mode)
+func (c *Crate) UseUnion(tag *uint16, cases map[uint16]func(mode UseMode), mode UseMode) (sliceModeData []byte) {
+	switch mode {
+	case Write:
+		useCase := unionCase(cases, *tag)
+		c.WriteU16(*tag)
+		useCase(Write)
+	case Read:
+		*tag = c.ReadU16()
+		unionCase(cases, *tag)(Read)
+	case Peek:
+		idx := c.read
+		*tag = c.ReadU16()
+		unionCase(cases, *tag)(Read)
+		c.read = idx
+	case Discard:
+		unionCase(cases, c.ReadU16())(Discard)
+	case Slice:
+		idx := c.read
+		unionCase(cases, c.ReadU16())(Discard)
+		end := c.read
+		c.read = idx
+		return c.data[idx:end:end]
+	default:
+		c.useCustomMode(tag, mode, "UseUnion")
+		if useCase := cases[*tag]; useCase != nil {
+			useCase(mode)
+		}
+	}
+	return nil
+}
+
+func unionCase(cases map[uint16]func(mode UseMode), tag uint16) func(mode UseMode) {
+	useCase := cases[tag]
+	if useCase == nil {
+		panic("LiteCrate: no case for union tag " + intStr(tag))
+	}
+	return useCase
+}
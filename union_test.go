@@ -0,0 +1,75 @@
+package litecrate_test
+
+import (
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+const (
+	unionLogin uint16 = 1
+	unionChat  uint16 = 2
+	unionPing  uint16 = 3
+)
+
+type unionMessage struct {
+	Type  uint16
+	Login person
+	Chat  string
+}
+
+func (m *unionMessage) UseSelf(crate *lite.Crate, mode lite.UseMode) {
+	crate.UseUnion(&m.Type, m.cases(crate), mode)
+}
+
+func (m *unionMessage) cases(crate *lite.Crate) map[uint16]func(mode lite.UseMode) {
+	return map[uint16]func(mode lite.UseMode){
+		unionLogin: func(mode lite.UseMode) { crate.UseSelfSerializer(&m.Login, mode) },
+		unionChat:  func(mode lite.UseMode) { crate.UseStringWithCounter(&m.Chat, mode) },
+		unionPing:  func(mode lite.UseMode) {},
+	}
+}
+
+func TestUseUnion(t *testing.T) {
+	crate := lite.NewCrate(16, lite.FlagAutoDouble)
+	sent := []unionMessage{
+		{Type: unionChat, Chat: "hello"},
+		{Type: unionLogin, Login: person{Name: "Derek", Age: 30}},
+		{Type: unionPing, Chat: "not written"},
+	}
+	for i := range sent {
+		crate.WriteSelfSerializer(&sent[i])
+	}
+	var peeked unionMessage
+	crate.PeekSelfSerializer(&peeked)
+	if peeked.Type != unionChat || peeked.Chat != "hello" || crate.ReadIndex() != 0 {
+		t.Errorf("UseUnion(Peek) - FAIL: %+v", peeked)
+	}
+	if slice := crate.UseUnion(&peeked.Type, peeked.cases(crate), lite.Slice); len(slice) != 8 || crate.ReadIndex() != 0 {
+		t.Errorf("UseUnion(Slice) - FAIL: len %d != 8", len(slice))
+	}
+	for i := range sent {
+		var got unionMessage
+		crate.ReadSelfSerializer(&got)
+		if got.Type != sent[i].Type || got.Login.Name != sent[i].Login.Name || (got.Type == unionChat && got.Chat != sent[i].Chat) {
+			t.Errorf("UseUnion(Read) - FAIL: message %d: %+v != %+v", i, got, sent[i])
+		}
+	}
+	crate.ResetReadIndex()
+	for range sent {
+		crate.DiscardSelfSerializer(&peeked)
+	}
+	if crate.ReadsLeft() != 0 {
+		t.Errorf("UseUnion(Discard) - FAIL: %d bytes left", crate.ReadsLeft())
+	}
+
+	unknown := unionMessage{Type: 99}
+	if catchPanic[error](func() { crate.WriteSelfSerializer(&unknown) }) == nil {
+		t.Errorf("UseUnion(Write) - FAIL: unknown tag did not panic")
+	}
+	crate.Reset()
+	crate.WriteU16(99)
+	if catchPanic[error](func() { crate.ReadSelfSerializer(&unknown) }) == nil {
+		t.Errorf("UseUnion(Read) - FAIL: unknown tag did not panic")
+	}
+}
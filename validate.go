@@ -0,0 +1,51 @@
+package litecrate
+
+import "errors"
+
+/**************
+	VALIDATION
+***************/
+
+// Check that the next value in the crate is a well formed encoding of val's type, without decoding it:
+// val is walked in Discard mode, so every counter and length is read and checked against the data left,
+// but no strings, slices or maps are allocated and val is not altered. Servers can use it to cheaply reject
+// malformed frames before decoding them. Returns how many bytes the value occupies. The read index is not moved.
+//
+// Only the structure is checked, not the values (a bool byte of 7 passes), and sections written with
+// UseSection() are checked only to fit in the data, not decoded
+func (c *Crate) ValidateSelfSerializer(val SelfSerializer) (bytesUsed uint64, err error) {
+	start, validating := c.read, c.validate
+	c.validate = true
+	defer func() {
+		c.read, c.validate = start, validating
+		if r := recover(); r != nil {
+			bytesUsed, err = 0, validationError(r)
+		}
+	}()
+	c.DiscardSelfSerializer(val)
+	return c.read - start, nil
+}
+
+// Check that data holds exactly one well formed encoding of val's type (see ValidateSelfSerializer()),
+// returning an error if it is malformed or followed by more bytes
+func Validate(data []byte, val SelfSerializer) error {
+	crate := OpenCrate(data, FlagStatic)
+	bytesUsed, err := crate.ValidateSelfSerializer(val)
+	if err != nil {
+		return err
+	}
+	if left := len64(data) - bytesUsed; left > 0 {
+		return errors.New("LiteCrate: " + intStr(left) + " bytes left after value")
+	}
+	return nil
+}
+
+func validationError(r any) error {
+	switch r := r.(type) {
+	case string:
+		return errors.New(r)
+	case error:
+		return r
+	}
+	panic(r)
+}
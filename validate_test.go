@@ -0,0 +1,91 @@
+package litecrate_test
+
+import (
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+type validatedOrder struct {
+	ID    uint64
+	Note  string
+	Items []validatedItem
+	Tags  map[string]uint16
+}
+
+type validatedItem struct {
+	SKU   string
+	Count uint32
+}
+
+func (o *validatedOrder) UseSelf(crate *lite.Crate, mode lite.UseMode) {
+	crate.UseUVarint(&o.ID, mode)
+	crate.UseStringWithCounter(&o.Note, mode)
+	lite.UseSlice(crate, mode, &o.Items, func(item *validatedItem, mode lite.UseMode) []byte {
+		crate.UseStringWithCounter(&item.SKU, mode)
+		return crate.UseU32(&item.Count, mode)
+	})
+	lite.UseMap(crate, mode, &o.Tags, crate.UseStringWithCounter, crate.UseU16)
+}
+
+func TestValidate(t *testing.T) {
+	order := &validatedOrder{ID: 900, Note: "fragile", Items: []validatedItem{{"A-1", 2}, {"B-22", 1}}, Tags: map[string]uint16{"rush": 1}}
+	crate := lite.NewCrate(32, lite.FlagAutoDouble)
+	crate.WriteSelfSerializer(order)
+	data := crate.Data()
+
+	var val validatedOrder
+	if used, err := crate.ValidateSelfSerializer(&val); err != nil || used != uint64(len(data)) || crate.ReadIndex() != 0 {
+		t.Fatalf("ValidateSelfSerializer() - FAIL: used %d of %d bytes, error %v", used, len(data), err)
+	}
+	if val.Note != "" || val.Items != nil || val.Tags != nil {
+		t.Error("ValidateSelfSerializer() - FAIL: altered val")
+	}
+	if err := lite.Validate(data, &val); err != nil {
+		t.Errorf("Validate() - FAIL: %v", err)
+	}
+	if err := lite.Validate(append(append([]byte{}, data...), 0), &val); err == nil {
+		t.Error("Validate() - FAIL: accepted trailing byte")
+	}
+	for i := 0; i < len(data); i += 1 {
+		if err := lite.Validate(data[:i], &val); err == nil {
+			t.Errorf("Validate() - FAIL: accepted data truncated to %d bytes", i)
+		}
+	}
+
+	// Claim a huge number of items, which must fail as soon as the data runs out
+	inflated := lite.NewCrate(32, lite.FlagAutoDouble)
+	inflated.WriteUVarint(900)
+	inflated.WriteStringWithCounter("fragile")
+	inflated.WriteLengthOrNil(1<<40, false)
+	inflated.WriteStringWithCounter("A-1")
+	inflated.WriteU32(2)
+	if _, err := inflated.ValidateSelfSerializer(&val); err == nil {
+		t.Error("ValidateSelfSerializer() - FAIL: accepted inflated slice counter")
+	}
+
+	// Discarding outside validation still stops at the end of the data
+	truncated := lite.OpenCrate(data[:len(data)-1], lite.FlagStatic)
+	truncated.DiscardSelfSerializer(&val)
+	if truncated.ReadsLeft() != 0 {
+		t.Error("DiscardSelfSerializer() - FAIL: did not stop at end of data")
+	}
+}
+
+func TestValidateAllocs(t *testing.T) {
+	order := &validatedOrder{Note: "n", Items: []validatedItem{{"A", 1}, {"B", 2}, {"C", 3}}}
+	crate := lite.NewCrate(32, lite.FlagAutoDouble)
+	crate.WriteSelfSerializer(order)
+	var val validatedOrder
+	// UseSelf() itself allocates its closures, so compare against walking the value without validating it
+	discard := testing.AllocsPerRun(100, func() {
+		crate.DiscardSelfSerializer(&val)
+		crate.ResetReadIndex()
+	})
+	validate := testing.AllocsPerRun(100, func() {
+		crate.ValidateSelfSerializer(&val)
+	})
+	if validate > discard {
+		t.Errorf("ValidateSelfSerializer() - FAIL: %.0f allocations per validation, %.0f per discard", validate, discard)
+	}
+}
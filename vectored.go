@@ -0,0 +1,27 @@
+//go:build !litecrate_core && !litecrate_nonet
+
+package litecrate
+
+import (
+	"io"
+	"net"
+)
+
+// Writes each buffer to w in order as net.Buffers, which uses a single writev call
+// when w is a connection that supports vectored I/O
+func writeVectored(w io.Writer, buffers [][]byte) (n int64, err error) {
+	netBuffers := net.Buffers(buffers)
+	return netBuffers.WriteTo(w)
+}
+
+// Writes all unread bytes to conn and advances the read index by the number of bytes written,
+// the same as WriteTo(). Provided alongside ChainedCrate.WriteToConn() so either can be sent the same way
+func (c *Crate) WriteToConn(conn net.Conn) (n int64, err error) {
+	return c.WriteTo(conn)
+}
+
+// Writes every unread byte to conn with a single writev call where the connection supports it,
+// the same as WriteTo()
+func (c *ChainedCrate) WriteToConn(conn net.Conn) (n int64, err error) {
+	return c.WriteTo(conn)
+}
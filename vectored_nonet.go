@@ -0,0 +1,24 @@
+//go:build litecrate_core || litecrate_nonet
+
+package litecrate
+
+import "io"
+
+// Writes each buffer to w in order, one Write() call per non-empty buffer
+// (builds without package net cannot use vectored I/O)
+func writeVectored(w io.Writer, buffers [][]byte) (n int64, err error) {
+	for _, buf := range buffers {
+		if len(buf) == 0 {
+			continue
+		}
+		m, err := w.Write(buf)
+		n += int64(m)
+		if err != nil {
+			return n, err
+		}
+		if m != len(buf) {
+			return n, io.ErrShortWrite
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,53 @@
+//go:build !litecrate_core && !litecrate_nonet
+
+package litecrate_test
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+func TestWriteToConn(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("no loopback networking: %v", err)
+	}
+	defer listener.Close()
+	received := make(chan []byte)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			received <- nil
+			return
+		}
+		data, _ := io.ReadAll(conn)
+		conn.Close()
+		received <- data
+	}()
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("net.Dial() - FAIL: %v", err)
+	}
+
+	chain := lite.NewChainedCrate(16, lite.FlagDefault)
+	for i := 0; i < 20; i += 1 {
+		chain.WriteValue(func(crate *lite.Crate) { crate.WriteU64(uint64(i)) })
+	}
+	expected := append(chain.Coalesce(lite.FlagDefault).Data(), "tail"...)
+	n, err := chain.WriteToConn(conn)
+	if err != nil || n != int64(chain.Len()) || chain.ReadsLeft() != 0 {
+		t.Errorf("ChainedCrate.WriteToConn() - FAIL: wrote %d, %v, %d bytes left unread", n, err, chain.ReadsLeft())
+	}
+	crate := lite.OpenCrate([]byte("tail"), lite.FlagStatic)
+	if n, err := crate.WriteToConn(conn); err != nil || n != 4 || crate.ReadsLeft() != 0 {
+		t.Errorf("Crate.WriteToConn() - FAIL: wrote %d, %v", n, err)
+	}
+	conn.Close()
+	if data := <-received; !bytes.Equal(data, expected) {
+		t.Errorf("WriteToConn() - FAIL: received %d bytes, expected %d", len(data), len(expected))
+	}
+}
@@ -0,0 +1,102 @@
+package litecrate
+
+/**************
+	VersionedSerializer
+***************/
+
+// Implementers of VersionedSerializer know how to read/write every version of themselves
+// they have ever been encoded as, so crates written before fields were added or removed
+// can still be decoded. version is the version passed to WriteVersioned() when writing,
+// or the version found in the version header when reading.
+//
+// Example:
+//
+//	func (p *player) UseSelfVersion(crate *Crate, mode UseMode, version uint32) {
+//		crate.UseStringWithCounter(&p.Name, mode)
+//		if version < 3 {
+//			crate.UseDeprecated(0, 4, mode) // Score was removed in version 3
+//		}
+//		if version >= 2 {
+//			crate.UseU16(&p.Level, mode) // Level was added in version 2
+//		}
+//	}
+type VersionedSerializer interface {
+	UseSelfVersion(crate *Crate, mode UseMode, version uint32)
+}
+
+// Write version header (as UVarint) followed by VersionedSerializer encoded as that version to crate
+func (c *Crate) WriteVersioned(val VersionedSerializer, version uint32) {
+	c.WriteUVarint(uint64(version))
+	c.enterDepth()
+	defer c.leaveDepth()
+	val.UseSelfVersion(c, Write, version)
+}
+
+// Read next VersionedSerializer from crate, returning the version it was encoded as
+func (c *Crate) ReadVersioned(val VersionedSerializer) (version uint32) {
+	version = c.readVersionHeader()
+	c.enterDepth()
+	defer c.leaveDepth()
+	val.UseSelfVersion(c, Read, version)
+	return version
+}
+
+// Read next VersionedSerializer from crate without advancing read index,
+// returning the version it was encoded as
+func (c *Crate) PeekVersioned(val VersionedSerializer) (version uint32) {
+	indexBefore := c.read
+	version = c.ReadVersioned(val)
+	c.read = indexBefore
+	return version
+}
+
+// Discard next VersionedSerializer in crate, returning the version it was encoded as
+func (c *Crate) DiscardVersioned(val VersionedSerializer) (version uint32) {
+	version = c.readVersionHeader()
+	c.enterDepth()
+	defer c.leaveDepth()
+	val.UseSelfVersion(c, Discard, version)
+	return version
+}
+
+// Return byte slice the next unread VersionedSerializer occupies (including version header)
+func (c *Crate) SliceVersioned(val VersionedSerializer) (slice []byte) {
+	indexBefore := c.read
+	c.DiscardVersioned(val)
+	indexAfter := c.read
+	c.read = indexBefore
+	return c.data[indexBefore:indexAfter:indexAfter]
+}
+
+// Use VersionedSerializer according to mode.
+// Write = 'write val encoded as *version into crate', Read = 'read from crate into val and version',
+// Peek = 'read from crate into val and version without advancing index'
+// Slice = 'Return the slice the next unread val occupies without altering val'
+func (c *Crate) UseVersioned(val VersionedSerializer, version *uint32, mode UseMode) (sliceModeData []byte) {
+	switch mode {
+	case Write:
+		c.WriteVersioned(val, *version)
+	case Read:
+		*version = c.ReadVersioned(val)
+	case Peek:
+		*version = c.PeekVersioned(val)
+	case Discard:
+		*version = c.DiscardVersioned(val)
+	case Slice:
+		sliceModeData = c.SliceVersioned(val)
+	default:
+		c.useCustomMode(val, mode, "UseVersioned")
+		c.enterDepth()
+		defer c.leaveDepth()
+		val.UseSelfVersion(c, mode, *version)
+	}
+	return sliceModeData
+}
+
+func (c *Crate) readVersionHeader() uint32 {
+	version, _ := c.ReadUVarint()
+	if version > uint64(^uint32(0)) {
+		panic("LiteCrate: version header " + intStr(version) + " does not fit in uint32")
+	}
+	return uint32(version)
+}
@@ -0,0 +1,79 @@
+package litecrate_test
+
+import (
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+// Version 1 = Name, Score(u32); version 2 added Level(u16); version 3 removed Score
+type versionedPlayer struct {
+	Name  string
+	Score uint32
+	Level uint16
+}
+
+func (p *versionedPlayer) UseSelfVersion(crate *lite.Crate, mode lite.UseMode, version uint32) {
+	crate.UseStringWithCounter(&p.Name, mode)
+	if version < 3 {
+		crate.UseU32(&p.Score, mode)
+	}
+	if version >= 2 {
+		crate.UseU16(&p.Level, mode)
+	}
+}
+
+func TestVersioned(t *testing.T) {
+	crate := lite.NewCrate(16, lite.FlagAutoDouble)
+	player := versionedPlayer{Name: "gabe", Score: 100, Level: 7}
+	for version := uint32(1); version <= 3; version += 1 {
+		crate.WriteVersioned(&player, version)
+	}
+	crate.WriteU8(42)
+
+	var peeked versionedPlayer
+	if version := crate.PeekVersioned(&peeked); version != 1 || crate.ReadIndex() != 0 || peeked.Score != 100 {
+		t.Errorf("PeekVersioned() - FAIL: version %d, index %d, score %d", version, crate.ReadIndex(), peeked.Score)
+	}
+	expect := []versionedPlayer{
+		{Name: "gabe", Score: 100},
+		{Name: "gabe", Score: 100, Level: 7},
+		{Name: "gabe", Level: 7},
+	}
+	for i, want := range expect {
+		var got versionedPlayer
+		var version uint32
+		slice := crate.UseVersioned(&got, &version, lite.Slice)
+		start := crate.ReadIndex()
+		crate.UseVersioned(&got, &version, lite.Read)
+		if got != want || version != uint32(i+1) {
+			t.Errorf("ReadVersioned() - FAIL: version %d read as %d: %+v != %+v", i+1, version, got, want)
+		}
+		if uint64(len(slice)) != crate.ReadIndex()-start {
+			t.Errorf("SliceVersioned() - FAIL: length %d != %d", len(slice), crate.ReadIndex()-start)
+		}
+	}
+	if crate.ReadU8() != 42 {
+		t.Errorf("ReadVersioned() - FAIL: did not consume whole crate")
+	}
+
+	crate = lite.OpenCrate(crate.Data(), lite.FlagStatic)
+	var discarded versionedPlayer
+	for i := uint32(1); i <= 3; i += 1 {
+		if version := crate.DiscardVersioned(&discarded); version != i {
+			t.Errorf("DiscardVersioned() - FAIL: version %d != %d", version, i)
+		}
+	}
+	if crate.ReadU8() != 42 {
+		t.Errorf("DiscardVersioned() - FAIL: did not skip whole crate")
+	}
+
+	crate.Reset()
+	crate.WriteUVarint(1 << 32)
+	if catchPanic[error](func() { crate.ReadVersioned(&discarded) }) == nil {
+		t.Errorf("ReadVersioned() - FAIL: version header over uint32 did not panic")
+	}
+	if catchPanic[error](func() { crate.UseVersioned(&discarded, nil, lite.UseMode(255)) }) == nil {
+		t.Errorf("UseVersioned - FAIL: invalid mode did not panic")
+	}
+}
@@ -0,0 +1,640 @@
+package litecrate
+
+/**************
+	VIEWS
+***************/
+
+// A read-only view of a crate's data, with a read index of its own. Functions that only decode can take
+// a *CrateReader to promise at compile time that they never write, and a decoding goroutine can read
+// through one while an encoding goroutine keeps appending to the crate through a CrateWriter, as neither
+// touches the other's index.
+//
+// A reader sees the data written up to the moment it was made, so hand it to the decoding goroutine
+// after the writes it covers (over a channel, for example). Writes the writer makes later are never
+// seen, even though they share the buffer. SeekWrite() and Reset() on the crate overwrite bytes a reader
+// may still be reading, so must wait until readers made before them are done.
+//
+// Reader methods mirror the Crate methods of the same name. Values without one, such as slices and maps,
+// can be read through ReadSelfSerializer(). Any write the view's underlying crate is asked to make
+// (from inside a UseSelf(), for example) panics with ErrNoGrow
+type CrateReader struct {
+	crate Crate
+}
+
+// A write-only handle on a crate, for functions that only encode and for the encoding side
+// of a crate shared with CrateReaders (see CrateReader).
+//
+// Writer methods mirror the Crate methods of the same name. Values without one
+// can be written through WriteSelfSerializer()
+type CrateWriter struct {
+	crate *Crate
+}
+
+// Returns a read-only view of the crate's written data, starting at its read index.
+// The view keeps the crate's blob resolver and decoding limits
+func (c *Crate) Reader() *CrateReader {
+	return &CrateReader{crate: Crate{
+		data:     c.data[:c.write:c.write],
+		write:    c.write,
+		read:     c.read,
+		flags:    c.flags | FlagNoGrow,
+		resolver: c.resolver,
+		maxDepth: c.maxDepth,
+		maxAlloc: c.maxAlloc,
+	}}
+}
+
+// Returns a write-only handle on the crate. Writes through it move the crate's write index
+func (c *Crate) Writer() *CrateWriter {
+	return &CrateWriter{crate: c}
+}
+
+// Returns a read-only view of everything written to the crate so far, starting at the crate's read index
+func (w *CrateWriter) Reader() *CrateReader {
+	return w.crate.Reader()
+}
+
+// Returns the reader's read index
+func (r *CrateReader) ReadIndex() uint64 {
+	return r.crate.read
+}
+
+// Returns the number of bytes left for the reader to read
+func (r *CrateReader) ReadsLeft() uint64 {
+	return r.crate.ReadsLeft()
+}
+
+// Returns all the data the reader can see, read or not. It must not be modified
+func (r *CrateReader) Data() []byte {
+	return r.crate.data
+}
+
+// See Crate.SeekRead()
+func (r *CrateReader) SeekRead(offset uint64) {
+	r.crate.SeekRead(offset)
+}
+
+// See Crate.AlignRead()
+func (r *CrateReader) AlignRead(n uint64) (bytesSkipped uint64) {
+	return r.crate.AlignRead(n)
+}
+
+// Returns the writer's write index
+func (w *CrateWriter) WriteIndex() uint64 {
+	return w.crate.write
+}
+
+// Returns the number of bytes the writer can write before the crate must grow
+func (w *CrateWriter) SpaceLeft() uint64 {
+	return w.crate.SpaceLeft()
+}
+
+// See Crate.AlignWrite()
+func (w *CrateWriter) AlignWrite(n uint64, zeroFill bool) (bytesPadded uint64) {
+	return w.crate.AlignWrite(n, zeroFill)
+}
+
+// See Crate.DiscardBool()
+func (r *CrateReader) DiscardBool() {
+	r.crate.DiscardBool()
+}
+
+// See Crate.SliceBool()
+func (r *CrateReader) SliceBool() (slice []byte) {
+	return r.crate.SliceBool()
+}
+
+// See Crate.ReadBool()
+func (r *CrateReader) ReadBool() (val bool) {
+	return r.crate.ReadBool()
+}
+
+// See Crate.PeekBool()
+func (r *CrateReader) PeekBool() (val bool) {
+	return r.crate.PeekBool()
+}
+
+// See Crate.DiscardU8()
+func (r *CrateReader) DiscardU8() {
+	r.crate.DiscardU8()
+}
+
+// See Crate.SliceU8()
+func (r *CrateReader) SliceU8() (slice []byte) {
+	return r.crate.SliceU8()
+}
+
+// See Crate.ReadU8()
+func (r *CrateReader) ReadU8() (val uint8) {
+	return r.crate.ReadU8()
+}
+
+// See Crate.PeekU8()
+func (r *CrateReader) PeekU8() (val uint8) {
+	return r.crate.PeekU8()
+}
+
+// See Crate.DiscardI8()
+func (r *CrateReader) DiscardI8() {
+	r.crate.DiscardI8()
+}
+
+// See Crate.SliceI8()
+func (r *CrateReader) SliceI8() (slice []byte) {
+	return r.crate.SliceI8()
+}
+
+// See Crate.ReadI8()
+func (r *CrateReader) ReadI8() (val int8) {
+	return r.crate.ReadI8()
+}
+
+// See Crate.PeekI8()
+func (r *CrateReader) PeekI8() (val int8) {
+	return r.crate.PeekI8()
+}
+
+// See Crate.DiscardU16()
+func (r *CrateReader) DiscardU16() {
+	r.crate.DiscardU16()
+}
+
+// See Crate.SliceU16()
+func (r *CrateReader) SliceU16() (slice []byte) {
+	return r.crate.SliceU16()
+}
+
+// See Crate.ReadU16()
+func (r *CrateReader) ReadU16() (val uint16) {
+	return r.crate.ReadU16()
+}
+
+// See Crate.PeekU16()
+func (r *CrateReader) PeekU16() (val uint16) {
+	return r.crate.PeekU16()
+}
+
+// See Crate.DiscardI16()
+func (r *CrateReader) DiscardI16() {
+	r.crate.DiscardI16()
+}
+
+// See Crate.SliceI16()
+func (r *CrateReader) SliceI16() (slice []byte) {
+	return r.crate.SliceI16()
+}
+
+// See Crate.ReadI16()
+func (r *CrateReader) ReadI16() (val int16) {
+	return r.crate.ReadI16()
+}
+
+// See Crate.PeekI16()
+func (r *CrateReader) PeekI16() (val int16) {
+	return r.crate.PeekI16()
+}
+
+// See Crate.DiscardU32()
+func (r *CrateReader) DiscardU32() {
+	r.crate.DiscardU32()
+}
+
+// See Crate.SliceU32()
+func (r *CrateReader) SliceU32() (slice []byte) {
+	return r.crate.SliceU32()
+}
+
+// See Crate.ReadU32()
+func (r *CrateReader) ReadU32() (val uint32) {
+	return r.crate.ReadU32()
+}
+
+// See Crate.PeekU32()
+func (r *CrateReader) PeekU32() (val uint32) {
+	return r.crate.PeekU32()
+}
+
+// See Crate.DiscardI32()
+func (r *CrateReader) DiscardI32() {
+	r.crate.DiscardI32()
+}
+
+// See Crate.SliceI32()
+func (r *CrateReader) SliceI32() (slice []byte) {
+	return r.crate.SliceI32()
+}
+
+// See Crate.ReadI32()
+func (r *CrateReader) ReadI32() int32 {
+	return r.crate.ReadI32()
+}
+
+// See Crate.PeekI32()
+func (r *CrateReader) PeekI32() (val int32) {
+	return r.crate.PeekI32()
+}
+
+// See Crate.DiscardU64()
+func (r *CrateReader) DiscardU64() {
+	r.crate.DiscardU64()
+}
+
+// See Crate.SliceU64()
+func (r *CrateReader) SliceU64() (slice []byte) {
+	return r.crate.SliceU64()
+}
+
+// See Crate.ReadU64()
+func (r *CrateReader) ReadU64() (val uint64) {
+	return r.crate.ReadU64()
+}
+
+// See Crate.PeekU64()
+func (r *CrateReader) PeekU64() (val uint64) {
+	return r.crate.PeekU64()
+}
+
+// See Crate.DiscardI64()
+func (r *CrateReader) DiscardI64() {
+	r.crate.DiscardI64()
+}
+
+// See Crate.SliceI64()
+func (r *CrateReader) SliceI64() (slice []byte) {
+	return r.crate.SliceI64()
+}
+
+// See Crate.ReadI64()
+func (r *CrateReader) ReadI64() (val int64) {
+	return r.crate.ReadI64()
+}
+
+// See Crate.PeekI64()
+func (r *CrateReader) PeekI64() (val int64) {
+	return r.crate.PeekI64()
+}
+
+// See Crate.DiscardInt()
+func (r *CrateReader) DiscardInt() {
+	r.crate.DiscardInt()
+}
+
+// See Crate.SliceInt()
+func (r *CrateReader) SliceInt() (slice []byte) {
+	return r.crate.SliceInt()
+}
+
+// See Crate.ReadInt()
+func (r *CrateReader) ReadInt() (val int) {
+	return r.crate.ReadInt()
+}
+
+// See Crate.PeekInt()
+func (r *CrateReader) PeekInt() (val int) {
+	return r.crate.PeekInt()
+}
+
+// See Crate.DiscardUint()
+func (r *CrateReader) DiscardUint() {
+	r.crate.DiscardUint()
+}
+
+// See Crate.SliceUint()
+func (r *CrateReader) SliceUint() (slice []byte) {
+	return r.crate.SliceUint()
+}
+
+// See Crate.ReadUint()
+func (r *CrateReader) ReadUint() (val uint) {
+	return r.crate.ReadUint()
+}
+
+// See Crate.PeekUint()
+func (r *CrateReader) PeekUint() (val uint) {
+	return r.crate.PeekUint()
+}
+
+// See Crate.DiscardF32()
+func (r *CrateReader) DiscardF32() {
+	r.crate.DiscardF32()
+}
+
+// See Crate.SliceF32()
+func (r *CrateReader) SliceF32() (slice []byte) {
+	return r.crate.SliceF32()
+}
+
+// See Crate.ReadF32()
+func (r *CrateReader) ReadF32() (val float32) {
+	return r.crate.ReadF32()
+}
+
+// See Crate.PeekF32()
+func (r *CrateReader) PeekF32() (val float32) {
+	return r.crate.PeekF32()
+}
+
+// See Crate.DiscardF64()
+func (r *CrateReader) DiscardF64() {
+	r.crate.DiscardF64()
+}
+
+// See Crate.SliceF64()
+func (r *CrateReader) SliceF64() (slice []byte) {
+	return r.crate.SliceF64()
+}
+
+// See Crate.ReadF64()
+func (r *CrateReader) ReadF64() (val float64) {
+	return r.crate.ReadF64()
+}
+
+// See Crate.PeekF64()
+func (r *CrateReader) PeekF64() (val float64) {
+	return r.crate.PeekF64()
+}
+
+// See Crate.DiscardUVarint()
+func (r *CrateReader) DiscardUVarint() (bytesDiscarded uint64) {
+	return r.crate.DiscardUVarint()
+}
+
+// See Crate.SliceUVarint()
+func (r *CrateReader) SliceUVarint() (slice []byte) {
+	return r.crate.SliceUVarint()
+}
+
+// See Crate.ReadUVarint()
+func (r *CrateReader) ReadUVarint() (val uint64, bytesRead uint64) {
+	return r.crate.ReadUVarint()
+}
+
+// See Crate.PeekUVarint()
+func (r *CrateReader) PeekUVarint() (val uint64, bytesRead uint64) {
+	return r.crate.PeekUVarint()
+}
+
+// See Crate.DiscardVarint()
+func (r *CrateReader) DiscardVarint() (bytesDiscarded uint64) {
+	return r.crate.DiscardVarint()
+}
+
+// See Crate.SliceVarint()
+func (r *CrateReader) SliceVarint() (slice []byte) {
+	return r.crate.SliceVarint()
+}
+
+// See Crate.ReadVarint()
+func (r *CrateReader) ReadVarint() (val int64, bytesRead uint64) {
+	return r.crate.ReadVarint()
+}
+
+// See Crate.PeekVarint()
+func (r *CrateReader) PeekVarint() (val int64, bytesRead uint64) {
+	return r.crate.PeekVarint()
+}
+
+// See Crate.DiscardLengthOrNil()
+func (r *CrateReader) DiscardLengthOrNil() (bytesDiscarded uint64) {
+	return r.crate.DiscardLengthOrNil()
+}
+
+// See Crate.SliceLengthOrNil()
+func (r *CrateReader) SliceLengthOrNil() (slice []byte) {
+	return r.crate.SliceLengthOrNil()
+}
+
+// See Crate.ReadLengthOrNil()
+func (r *CrateReader) ReadLengthOrNil() (length uint64, isNil bool, bytesRead uint64) {
+	return r.crate.ReadLengthOrNil()
+}
+
+// See Crate.PeekLengthOrNil()
+func (r *CrateReader) PeekLengthOrNil() (length uint64, isNil bool, bytesRead uint64) {
+	return r.crate.PeekLengthOrNil()
+}
+
+// See Crate.DiscardLength()
+func (r *CrateReader) DiscardLength() (bytesDiscarded uint64) {
+	return r.crate.DiscardLength()
+}
+
+// See Crate.SliceLength()
+func (r *CrateReader) SliceLength() (slice []byte) {
+	return r.crate.SliceLength()
+}
+
+// See Crate.ReadLength()
+func (r *CrateReader) ReadLength() (length uint64, bytesRead uint64) {
+	return r.crate.ReadLength()
+}
+
+// See Crate.PeekLength()
+func (r *CrateReader) PeekLength() (length uint64, bytesRead uint64) {
+	return r.crate.PeekLength()
+}
+
+// See Crate.DiscardString()
+func (r *CrateReader) DiscardString(length uint64) {
+	r.crate.DiscardString(length)
+}
+
+// See Crate.SliceString()
+func (r *CrateReader) SliceString(length uint64) (slice []byte) {
+	return r.crate.SliceString(length)
+}
+
+// See Crate.ReadString()
+func (r *CrateReader) ReadString(length uint64) (val string) {
+	return r.crate.ReadString(length)
+}
+
+// See Crate.PeekString()
+func (r *CrateReader) PeekString(length uint64) (val string) {
+	return r.crate.PeekString(length)
+}
+
+// See Crate.DiscardStringWithCounter()
+func (r *CrateReader) DiscardStringWithCounter() {
+	r.crate.DiscardStringWithCounter()
+}
+
+// See Crate.SliceStringWithCounter()
+func (r *CrateReader) SliceStringWithCounter() (slice []byte) {
+	return r.crate.SliceStringWithCounter()
+}
+
+// See Crate.ReadStringWithCounter()
+func (r *CrateReader) ReadStringWithCounter() (val string) {
+	return r.crate.ReadStringWithCounter()
+}
+
+// See Crate.PeekStringWithCounter()
+func (r *CrateReader) PeekStringWithCounter() (val string) {
+	return r.crate.PeekStringWithCounter()
+}
+
+// See Crate.DiscardBytes()
+func (r *CrateReader) DiscardBytes(length uint64) {
+	r.crate.DiscardBytes(length)
+}
+
+// See Crate.SliceBytes()
+func (r *CrateReader) SliceBytes(length uint64) (slice []byte) {
+	return r.crate.SliceBytes(length)
+}
+
+// See Crate.ReadBytes()
+func (r *CrateReader) ReadBytes(length uint64) (val []byte) {
+	return r.crate.ReadBytes(length)
+}
+
+// See Crate.PeekBytes()
+func (r *CrateReader) PeekBytes(length uint64) (val []byte) {
+	return r.crate.PeekBytes(length)
+}
+
+// See Crate.DiscardBytesWithCounter()
+func (r *CrateReader) DiscardBytesWithCounter() {
+	r.crate.DiscardBytesWithCounter()
+}
+
+// See Crate.SliceBytesWithCounter()
+func (r *CrateReader) SliceBytesWithCounter() (slice []byte) {
+	return r.crate.SliceBytesWithCounter()
+}
+
+// See Crate.ReadBytesWithCounter()
+func (r *CrateReader) ReadBytesWithCounter() (val []byte) {
+	return r.crate.ReadBytesWithCounter()
+}
+
+// See Crate.PeekBytesWithCounter()
+func (r *CrateReader) PeekBytesWithCounter() (val []byte) {
+	return r.crate.PeekBytesWithCounter()
+}
+
+// See Crate.DiscardSelfSerializer()
+func (r *CrateReader) DiscardSelfSerializer(val SelfSerializer) {
+	r.crate.DiscardSelfSerializer(val)
+}
+
+// See Crate.ReadSelfSerializer()
+func (r *CrateReader) ReadSelfSerializer(val SelfSerializer) {
+	r.crate.ReadSelfSerializer(val)
+}
+
+// See Crate.PeekSelfSerializer()
+func (r *CrateReader) PeekSelfSerializer(val SelfSerializer) {
+	r.crate.PeekSelfSerializer(val)
+}
+
+// See Crate.WriteBool()
+func (w *CrateWriter) WriteBool(val bool) {
+	w.crate.WriteBool(val)
+}
+
+// See Crate.WriteU8()
+func (w *CrateWriter) WriteU8(val uint8) {
+	w.crate.WriteU8(val)
+}
+
+// See Crate.WriteI8()
+func (w *CrateWriter) WriteI8(val int8) {
+	w.crate.WriteI8(val)
+}
+
+// See Crate.WriteU16()
+func (w *CrateWriter) WriteU16(val uint16) {
+	w.crate.WriteU16(val)
+}
+
+// See Crate.WriteI16()
+func (w *CrateWriter) WriteI16(val int16) {
+	w.crate.WriteI16(val)
+}
+
+// See Crate.WriteU32()
+func (w *CrateWriter) WriteU32(val uint32) {
+	w.crate.WriteU32(val)
+}
+
+// See Crate.WriteI32()
+func (w *CrateWriter) WriteI32(val int32) {
+	w.crate.WriteI32(val)
+}
+
+// See Crate.WriteU64()
+func (w *CrateWriter) WriteU64(val uint64) {
+	w.crate.WriteU64(val)
+}
+
+// See Crate.WriteI64()
+func (w *CrateWriter) WriteI64(val int64) {
+	w.crate.WriteI64(val)
+}
+
+// See Crate.WriteInt()
+func (w *CrateWriter) WriteInt(val int) {
+	w.crate.WriteInt(val)
+}
+
+// See Crate.WriteUint()
+func (w *CrateWriter) WriteUint(val uint) {
+	w.crate.WriteUint(val)
+}
+
+// See Crate.WriteF32()
+func (w *CrateWriter) WriteF32(val float32) {
+	w.crate.WriteF32(val)
+}
+
+// See Crate.WriteF64()
+func (w *CrateWriter) WriteF64(val float64) {
+	w.crate.WriteF64(val)
+}
+
+// See Crate.WriteUVarint()
+func (w *CrateWriter) WriteUVarint(val uint64) (bytesWritten uint64) {
+	return w.crate.WriteUVarint(val)
+}
+
+// See Crate.WriteVarint()
+func (w *CrateWriter) WriteVarint(val int64) (bytesWritten uint64) {
+	return w.crate.WriteVarint(val)
+}
+
+// See Crate.WriteLengthOrNil()
+func (w *CrateWriter) WriteLengthOrNil(length uint64, isNil bool) (bytesWritten uint64) {
+	return w.crate.WriteLengthOrNil(length, isNil)
+}
+
+// See Crate.WriteLength()
+func (w *CrateWriter) WriteLength(length uint64) (bytesWritten uint64) {
+	return w.crate.WriteLength(length)
+}
+
+// See Crate.WriteString()
+func (w *CrateWriter) WriteString(val string) {
+	w.crate.WriteString(val)
+}
+
+// See Crate.WriteStringWithCounter()
+func (w *CrateWriter) WriteStringWithCounter(val string) {
+	w.crate.WriteStringWithCounter(val)
+}
+
+// See Crate.WriteBytes()
+func (w *CrateWriter) WriteBytes(val []byte) {
+	w.crate.WriteBytes(val)
+}
+
+// See Crate.WriteBytesWithCounter()
+func (w *CrateWriter) WriteBytesWithCounter(val []byte) {
+	w.crate.WriteBytesWithCounter(val)
+}
+
+// See Crate.WriteSelfSerializer()
+func (w *CrateWriter) WriteSelfSerializer(val SelfSerializer) {
+	w.crate.WriteSelfSerializer(val)
+}
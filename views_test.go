@@ -0,0 +1,94 @@
+package litecrate_test
+
+import (
+	"errors"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+type viewPoint struct {
+	X, Y int32
+}
+
+func (p *viewPoint) UseSelf(crate *lite.Crate, mode lite.UseMode) {
+	crate.UseI32(&p.X, mode)
+	crate.UseI32(&p.Y, mode)
+}
+
+// Writes through the crate it is given even when reading, as a misbehaving UseSelf() might
+type viewWritingPoint struct{}
+
+func (p *viewWritingPoint) UseSelf(crate *lite.Crate, mode lite.UseMode) {
+	crate.WriteU8(1)
+}
+
+func encodePoint(w *lite.CrateWriter, p viewPoint) {
+	w.WriteSelfSerializer(&p)
+}
+
+func decodePoint(r *lite.CrateReader) (p viewPoint) {
+	r.ReadSelfSerializer(&p)
+	return p
+}
+
+func TestCrateViews(t *testing.T) {
+	crate := lite.NewCrate(4, lite.FlagAutoDouble)
+	w := crate.Writer()
+	w.WriteStringWithCounter("points")
+	encodePoint(w, viewPoint{1, 2})
+	r := w.Reader()
+	encodePoint(w, viewPoint{3, 4})
+	if r.ReadStringWithCounter() != "points" || decodePoint(r) != (viewPoint{1, 2}) {
+		t.Error("CrateReader - FAIL: did not read what was written before it was made")
+	}
+	if r.ReadsLeft() != 0 || crate.ReadIndex() != 0 {
+		t.Errorf("CrateReader - FAIL: %d bytes left, crate read index moved to %d", r.ReadsLeft(), crate.ReadIndex())
+	}
+	if crate.WriteIndex() != w.WriteIndex() || w.WriteIndex() != 23 {
+		t.Errorf("CrateWriter - FAIL: write index %d, crate write index %d", w.WriteIndex(), crate.WriteIndex())
+	}
+
+	crate.ReadStringWithCounter()
+	r = crate.Reader()
+	r.SeekRead(r.ReadIndex() + 8)
+	if p := decodePoint(r); p != (viewPoint{3, 4}) {
+		t.Errorf("Crate.Reader() - FAIL: read %v from crate's read index + 8", p)
+	}
+	if catchPanic[error](func() { r.ReadU8() }) == nil {
+		t.Error("CrateReader - FAIL: read past end of view")
+	}
+	r.SeekRead(0)
+	var writing viewWritingPoint
+	err := catchPanic[error](func() { r.ReadSelfSerializer(&writing) })
+	if !errors.Is(err, lite.ErrNoGrow) || crate.WriteIndex() != 23 {
+		t.Errorf("CrateReader - FAIL: write through view gave %v", err)
+	}
+}
+
+func TestCrateViewsConcurrent(t *testing.T) {
+	crate := lite.NewCrate(8, lite.FlagAutoDouble)
+	w := crate.Writer()
+	readers := make(chan *lite.CrateReader)
+	go func() {
+		defer close(readers)
+		start := uint64(0)
+		for i := int32(0); i < 100; i += 1 {
+			encodePoint(w, viewPoint{i, -i})
+			r := w.Reader()
+			r.SeekRead(start)
+			start = w.WriteIndex()
+			readers <- r
+		}
+	}()
+	count := int32(0)
+	for r := range readers {
+		if p := decodePoint(r); p != (viewPoint{count, -count}) || r.ReadsLeft() != 0 {
+			t.Fatalf("CrateReader - FAIL: read %v, expected point %d", p, count)
+		}
+		count += 1
+	}
+	if count != 100 {
+		t.Errorf("CrateReader - FAIL: read %d points, expected 100", count)
+	}
+}
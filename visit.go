@@ -0,0 +1,43 @@
+package litecrate
+
+// Receives every value walked by Visit().
+//
+// val and name are the same values a ModeHandler receives: a pointer to the value
+// (*uint8, *string, *[]T, **T...) and the name of the Use____() method it was passed to.
+// Field ids from UseField() are visited as *uint16 just before the value of the field.
+// Modifying a value through val modifies the value being visited
+type Visitor interface {
+	Visit(val any, name string)
+}
+
+// Adapts an ordinary function to the Visitor interface
+type VisitorFunc func(val any, name string)
+
+func (f VisitorFunc) Visit(val any, name string) {
+	f(val, name)
+}
+
+var visitMode = RegisterMode("Visit", func(crate *Crate, val any, name string) {
+	crate.visitor.Visit(val, name)
+})
+
+/**************
+	VISIT
+***************/
+
+// Walk every value val uses in its UseSelf() method and pass it to visitor, without encoding anything.
+// Useful for generic tooling (validators, documentation and random value generators)
+// that works on any SelfSerializer.
+//
+// Example:
+//
+//	var strings int
+//	Visit(&myRecord, VisitorFunc(func(val any, name string) {
+//		if _, ok := val.(*string); ok {
+//			strings += 1
+//		}
+//	}))
+func Visit(val SelfSerializer, visitor Visitor) {
+	crate := &Crate{visitor: visitor}
+	crate.UseSelfSerializer(val, visitMode)
+}
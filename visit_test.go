@@ -0,0 +1,59 @@
+package litecrate_test
+
+import (
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+type visitRecord struct {
+	Name  string
+	Email string
+	Tags  []string
+	Next  *visitRecord
+}
+
+func (r *visitRecord) UseSelf(crate *lite.Crate, mode lite.UseMode) {
+	crate.UseFieldGroup(func(mode lite.UseMode) {
+		crate.UseField(1, func(mode lite.UseMode) { crate.UseStringWithCounter(&r.Name, mode) }, mode)
+		crate.UseField(2, func(mode lite.UseMode) { crate.UseStringWithCounter(&r.Email, mode) }, mode)
+		crate.UseField(3, func(mode lite.UseMode) { lite.UseSlice(crate, mode, &r.Tags, crate.UseStringWithCounter) }, mode)
+		crate.UseField(4, func(mode lite.UseMode) { lite.UseRef(crate, mode, &r.Next, (*visitRecord).UseSelf) }, mode)
+	}, mode)
+}
+
+func TestVisit(t *testing.T) {
+	rec := &visitRecord{Name: "a", Tags: []string{"x", ""}, Next: &visitRecord{Name: "b", Email: "b@b"}}
+	var field uint16
+	var empty []uint16
+	lite.Visit(rec, lite.VisitorFunc(func(val any, name string) {
+		switch val := val.(type) {
+		case *uint16:
+			field = *val
+		case *string:
+			if *val == "" {
+				empty = append(empty, field)
+			}
+		}
+	}))
+	if len(empty) != 2 || empty[0] != 2 || empty[1] != 3 {
+		t.Errorf("Visit() - FAIL: empty strings found in fields %v, expected [2 3]", empty)
+	}
+
+	lite.Visit(rec, lite.VisitorFunc(func(val any, name string) {
+		if str, ok := val.(*string); ok && name == "UseStringWithCounter" {
+			*str += "!"
+		}
+	}))
+	if rec.Name != "a!" || rec.Tags[1] != "!" || rec.Next.Email != "b@b!" {
+		t.Errorf("Visit() - FAIL: visitor could not modify values: %+v", rec)
+	}
+
+	var names []string
+	lite.Visit(rec, lite.VisitorFunc(func(val any, name string) {
+		names = append(names, name)
+	}))
+	if len(names) != 21 || names[0] != "UseSelfSerializer" || names[1] != "UseFieldGroup" {
+		t.Errorf("Visit() - FAIL: visited %d values: %v", len(names), names)
+	}
+}
@@ -0,0 +1,106 @@
+package litecrate
+
+import (
+	"log"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+)
+
+// Window GrowthWatchdog measures growth rate over when Window is 0
+const DefaultWatchdogWindow = time.Second
+
+// Watchdog used by crates that have not been given one with SetWatchdog(), holds a *GrowthWatchdog
+var defaultWatchdog atomic.Value
+
+// Passed to GrowthWatchdog.OnAlert when a crate grows past one of the watchdog's limits
+type GrowthAlert struct {
+	Limit string // "MaxSize" or "MaxRate", the limit that was passed
+	Size  uint64 // Capacity of the crate's buffer after the grow
+	Grown uint64 // Bytes the buffer has grown by in the current window
+	Grows uint64 // The crate's GrowCount()
+	Stack []byte // Stack trace of the goroutine that grew the crate
+}
+
+/**************
+	WATCHDOG
+***************/
+
+// Reports crates whose buffers grow too large or too fast, to catch accidental unbounded
+// accumulation (such as a crate that is written to in a loop and never Reset()) before it
+// exhausts memory. Only grows that reallocate the buffer are checked, so a watchdog costs
+// nothing on writes that fit. A watchdog may be shared by any number of crates, and must not be
+// modified once in use
+type GrowthWatchdog struct {
+	MaxSize uint64                  // Alert each time a crate's buffer grows past this many bytes, 0 = no limit
+	MaxRate uint64                  // Alert when a crate's buffer grows by more than this many bytes within Window (once per window), 0 = no limit
+	Window  time.Duration           // Period MaxRate is measured over, 0 = DefaultWatchdogWindow
+	OnAlert func(alert GrowthAlert) // Called on the goroutine that grew the crate, nil = write the alert and stack to the standard logger
+}
+
+// A crate's growth in the current window, and the watchdog it reports to (nil for the default)
+type watchState struct {
+	dog     *GrowthWatchdog
+	start   time.Time
+	grown   uint64
+	alerted bool
+}
+
+// Watch the crate's growth with w instead of the default set with SetDefaultWatchdog(),
+// nil to go back to the default
+func (c *Crate) SetWatchdog(w *GrowthWatchdog) {
+	if w == nil {
+		c.watch = nil
+		return
+	}
+	c.watch = &watchState{dog: w}
+}
+
+// Watch the growth of every crate not given its own watchdog with SetWatchdog(), nil to stop
+func SetDefaultWatchdog(w *GrowthWatchdog) {
+	defaultWatchdog.Store(w)
+}
+
+// Called by Grow() after reallocating the buffer, grown bytes larger
+func (c *Crate) checkGrowth(grown uint64) {
+	w := c.watch
+	var dog *GrowthWatchdog
+	if w != nil && w.dog != nil {
+		dog = w.dog
+	} else {
+		if dog, _ = defaultWatchdog.Load().(*GrowthWatchdog); dog == nil {
+			return
+		}
+		if w == nil {
+			w = &watchState{}
+			c.watch = w
+		}
+	}
+	size := uint64(cap(c.data))
+	if dog.MaxRate > 0 {
+		window := dog.Window
+		if window == 0 {
+			window = DefaultWatchdogWindow
+		}
+		if now := time.Now(); now.Sub(w.start) > window {
+			w.start, w.grown, w.alerted = now, 0, false
+		}
+		w.grown += grown
+	}
+	if dog.MaxSize > 0 && size > dog.MaxSize {
+		dog.alert(GrowthAlert{Limit: "MaxSize", Size: size, Grown: w.grown, Grows: c.grows})
+	}
+	if dog.MaxRate > 0 && w.grown > dog.MaxRate && !w.alerted {
+		w.alerted = true
+		dog.alert(GrowthAlert{Limit: "MaxRate", Size: size, Grown: w.grown, Grows: c.grows})
+	}
+}
+
+func (w *GrowthWatchdog) alert(alert GrowthAlert) {
+	alert.Stack = debug.Stack()
+	if w.OnAlert != nil {
+		w.OnAlert(alert)
+		return
+	}
+	log.Printf("LiteCrate: crate grew past %s (size %d bytes, grew %d bytes this window, %d grows)\n%s", alert.Limit, alert.Size, alert.Grown, alert.Grows, alert.Stack)
+}
@@ -0,0 +1,63 @@
+package litecrate_test
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+func TestGrowthWatchdog(t *testing.T) {
+	var alerts []lite.GrowthAlert
+	dog := &lite.GrowthWatchdog{MaxSize: 1000, OnAlert: func(alert lite.GrowthAlert) { alerts = append(alerts, alert) }}
+	crate := lite.NewCrate(16, lite.FlagAutoDouble)
+	crate.SetWatchdog(dog)
+	crate.WriteBytes(make([]byte, 500))
+	if len(alerts) != 0 {
+		t.Fatalf("GrowthWatchdog - FAIL: alert below MaxSize: %+v", alerts[0])
+	}
+	crate.WriteBytes(make([]byte, 1000))
+	if len(alerts) != 1 || alerts[0].Limit != "MaxSize" || alerts[0].Size <= 1000 || alerts[0].Grows != crate.GrowCount() {
+		t.Fatalf("GrowthWatchdog - FAIL: alerts %+v", alerts)
+	}
+	if !strings.Contains(string(alerts[0].Stack), "TestGrowthWatchdog") {
+		t.Errorf("GrowthWatchdog - FAIL: stack does not include the caller:\n%s", alerts[0].Stack)
+	}
+	crate.Reset()
+	crate.WriteBytes(make([]byte, 1500))
+	if len(alerts) != 1 {
+		t.Errorf("GrowthWatchdog - FAIL: alert for write that did not reallocate")
+	}
+
+	alerts = nil
+	dog = &lite.GrowthWatchdog{MaxRate: 4000, Window: time.Hour, OnAlert: dog.OnAlert}
+	crate = lite.NewCrate(16, lite.FlagAutoDouble|lite.FlagGrowExact)
+	crate.SetWatchdog(dog)
+	for i := 0; i < 10; i += 1 {
+		crate.WriteBytes(make([]byte, 1000))
+	}
+	if len(alerts) != 1 || alerts[0].Limit != "MaxRate" || alerts[0].Grown <= 4000 || alerts[0].Grown > 5000 {
+		t.Errorf("GrowthWatchdog - FAIL: rate alerts %+v", alerts)
+	}
+
+	// The default watchdog logs when OnAlert is nil
+	var logged bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&logged)
+	lite.SetDefaultWatchdog(&lite.GrowthWatchdog{MaxSize: 100})
+	defer lite.SetDefaultWatchdog(nil)
+	crate = lite.NewCrate(16, lite.FlagAutoDouble)
+	crate.WriteBytes(make([]byte, 200))
+	if !strings.Contains(logged.String(), "LiteCrate: crate grew past MaxSize") {
+		t.Errorf("SetDefaultWatchdog() - FAIL: nothing logged: %q", logged.String())
+	}
+	logged.Reset()
+	crate.SetWatchdog(&lite.GrowthWatchdog{})
+	crate.WriteBytes(make([]byte, 1000))
+	if logged.Len() != 0 {
+		t.Errorf("SetWatchdog() - FAIL: default watchdog used instead of crate's own")
+	}
+}
@@ -0,0 +1,312 @@
+//go:build !litecrate_core && !litecrate_nonet
+
+package litecrate
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+const (
+	wsContinuation uint8 = 0
+	wsText         uint8 = 1
+	wsBinary       uint8 = 2
+	wsClose        uint8 = 8
+	wsPing         uint8 = 9
+	wsPong         uint8 = 10
+
+	wsFinal    = 0x80 // first header byte bit set on the last frame of a message
+	wsMasked   = 0x80 // second header byte bit set when the payload is masked
+	wsLength16 = 126  // second header byte length meaning 'length is the next 2 bytes'
+	wsLength64 = 127  // second header byte length meaning 'length is the next 8 bytes'
+
+	wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11" // appended to the client's key to make the server's accept key (RFC 6455)
+)
+
+var errWebSocketHandshake = errors.New("LiteCrate: invalid WebSocket handshake")
+
+/**************
+	WEBSOCKET
+***************/
+
+// A Transport that sends each crate as one binary WebSocket message (RFC 6455).
+// Make one with DialWebSocket() or UpgradeWebSocket(), or set Conn to a connection
+// that has already completed the WebSocket handshake.
+//
+// RecvCrate() answers pings and close messages from the other end (returning io.EOF after a close),
+// ignores pongs, and accepts text messages as well as binary ones
+type WebSocketTransport struct {
+	Conn         net.Conn // The connection after its HTTP upgrade
+	Client       bool     // Whether this is the client end, which must mask the frames it sends
+	MaxFrameSize uint64   // Messages longer than this are rejected by RecvCrate(), 0 = DefaultMaxFrameSize
+	Flags        uint8    // Flags for crates returned by RecvCrate()
+	reader       io.Reader
+	writeMutex   sync.Mutex
+	readMutex    sync.Mutex
+}
+
+// Connect to the WebSocket server at rawURL ("ws://..." or "wss://...") and complete the handshake
+func DialWebSocket(rawURL string) (*WebSocketTransport, error) {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	var conn net.Conn
+	switch target.Scheme {
+	case "ws":
+		conn, err = net.Dial("tcp", hostPort(target, "80"))
+	case "wss":
+		conn, err = tls.Dial("tcp", hostPort(target, "443"), &tls.Config{ServerName: target.Hostname()})
+	default:
+		return nil, errors.New("LiteCrate: WebSocket URL scheme must be ws or wss, got " + target.Scheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var nonce [16]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce[:])
+	request := "GET " + target.RequestURI() + " HTTP/1.1\r\nHost: " + target.Host +
+		"\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Key: " + key +
+		"\r\nSec-WebSocket-Version: 13\r\n\r\n"
+	if _, err := io.WriteString(conn, request); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, &http.Request{Method: http.MethodGet})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusSwitchingProtocols || response.Header.Get("Sec-WebSocket-Accept") != webSocketAccept(key) {
+		conn.Close()
+		return nil, errWebSocketHandshake
+	}
+	return &WebSocketTransport{Conn: conn, Client: true, reader: reader}, nil
+}
+
+// Complete the server side of a WebSocket handshake from inside an http.Handler, taking over its connection.
+// If r is not a WebSocket handshake a 400 response is written and an error returned
+func UpgradeWebSocket(w http.ResponseWriter, r *http.Request) (*WebSocketTransport, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if r.Method != http.MethodGet || key == "" || r.Header.Get("Sec-WebSocket-Version") != "13" ||
+		!headerHasToken(r.Header, "Connection", "upgrade") || !headerHasToken(r.Header, "Upgrade", "websocket") {
+		http.Error(w, errWebSocketHandshake.Error(), http.StatusBadRequest)
+		return nil, errWebSocketHandshake
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "LiteCrate: connection cannot be upgraded", http.StatusInternalServerError)
+		return nil, errors.New("LiteCrate: http.ResponseWriter does not implement http.Hijacker")
+	}
+	conn, buffered, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	response := "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: " +
+		webSocketAccept(key) + "\r\n\r\n"
+	if _, err := io.WriteString(conn, response); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &WebSocketTransport{Conn: conn, reader: buffered.Reader}, nil
+}
+
+// Send the crate's written data as one binary message, returns the crate's error if it failed under FlagNoPanic
+func (t *WebSocketTransport) SendCrate(crate *Crate) error {
+	if err := crate.Err(); err != nil {
+		return err
+	}
+	return t.writeFrame(wsBinary, crate.Data())
+}
+
+// Receive the next message into a new crate, reassembling fragmented messages.
+// Returns io.EOF after the other end closes the connection
+// and ErrFrameTooLarge (without reading the rest of the message) if it is longer than MaxFrameSize
+func (t *WebSocketTransport) RecvCrate() (*Crate, error) {
+	t.readMutex.Lock()
+	defer t.readMutex.Unlock()
+	maxSize := t.MaxFrameSize
+	if maxSize == 0 {
+		maxSize = DefaultMaxFrameSize
+	}
+	var message []byte
+	started := false
+	for {
+		final, opcode, payload, err := t.readFrame(maxSize - len64(message))
+		if err != nil {
+			return nil, err
+		}
+		switch opcode {
+		case wsPing:
+			if err := t.writeFrame(wsPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+		case wsPong:
+			continue
+		case wsClose:
+			if len(payload) > 2 {
+				payload = payload[:2]
+			}
+			t.writeFrame(wsClose, payload)
+			return nil, io.EOF
+		case wsText, wsBinary:
+			if started {
+				return nil, errors.New("LiteCrate: WebSocket message began before the previous one ended")
+			}
+			started = true
+		case wsContinuation:
+			if !started {
+				return nil, errors.New("LiteCrate: WebSocket continuation frame without a message")
+			}
+		default:
+			return nil, errors.New("LiteCrate: unknown WebSocket opcode " + intStr(opcode))
+		}
+		message = append(message, payload...)
+		if final {
+			if message == nil {
+				message = []byte{}
+			}
+			return OpenCrate(message, t.Flags), nil
+		}
+	}
+}
+
+// Send a close message (status 1000, normal closure) and close Conn
+func (t *WebSocketTransport) Close() error {
+	t.writeFrame(wsClose, []byte{0x03, 0xE8})
+	return t.Conn.Close()
+}
+
+func (t *WebSocketTransport) writeFrame(opcode uint8, payload []byte) error {
+	var header [14]byte
+	header[0] = wsFinal | opcode
+	n := 2
+	switch length := len64(payload); {
+	case length < wsLength16:
+		header[1] = uint8(length)
+	case length <= 0xFFFF:
+		header[1] = wsLength16
+		binary.BigEndian.PutUint16(header[2:], uint16(length))
+		n = 4
+	default:
+		header[1] = wsLength64
+		binary.BigEndian.PutUint64(header[2:], length)
+		n = 10
+	}
+	frame := header[:n]
+	if t.Client {
+		// Clients mask every frame, so the payload is copied rather than masked in place
+		header[1] |= wsMasked
+		if _, err := rand.Read(header[n : n+4]); err != nil {
+			return err
+		}
+		frame = make([]byte, n+4+len(payload))
+		copy(frame, header[:n+4])
+		masked := frame[n+4:]
+		copy(masked, payload)
+		wsMask(masked, header[n:n+4])
+		payload = nil
+	}
+	t.writeMutex.Lock()
+	defer t.writeMutex.Unlock()
+	if _, err := t.Conn.Write(frame); err != nil || len(payload) == 0 {
+		return err
+	}
+	_, err := t.Conn.Write(payload)
+	return err
+}
+
+func (t *WebSocketTransport) readFrame(maxSize uint64) (final bool, opcode uint8, payload []byte, err error) {
+	reader := t.reader
+	if reader == nil {
+		reader = t.Conn
+	}
+	var header [8]byte
+	if _, err = io.ReadFull(reader, header[:2]); err != nil {
+		return false, 0, nil, err
+	}
+	final, opcode = header[0]&wsFinal == wsFinal, header[0]&0x0F
+	masked := header[1]&wsMasked == wsMasked
+	length := uint64(header[1] &^ wsMasked)
+	switch length {
+	case wsLength16:
+		if _, err = io.ReadFull(reader, header[:2]); err != nil {
+			return false, 0, nil, unexpectedEOF(err)
+		}
+		length = uint64(binary.BigEndian.Uint16(header[:2]))
+	case wsLength64:
+		if _, err = io.ReadFull(reader, header[:8]); err != nil {
+			return false, 0, nil, unexpectedEOF(err)
+		}
+		length = binary.BigEndian.Uint64(header[:8])
+	}
+	if opcode < wsClose && length > maxSize {
+		return false, 0, nil, ErrFrameTooLarge
+	}
+	if opcode >= wsClose && (length > wsLength16-1 || !final) {
+		return false, 0, nil, errors.New("LiteCrate: WebSocket control frame is fragmented or too long")
+	}
+	var mask [4]byte
+	if masked {
+		if _, err = io.ReadFull(reader, mask[:]); err != nil {
+			return false, 0, nil, unexpectedEOF(err)
+		}
+	}
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(reader, payload); err != nil {
+		return false, 0, nil, unexpectedEOF(err)
+	}
+	if masked {
+		wsMask(payload, mask[:])
+	}
+	return final, opcode, payload, nil
+}
+
+// Masks or unmasks data in place
+func wsMask(data []byte, mask []byte) {
+	for i := range data {
+		data[i] ^= mask[i&3]
+	}
+}
+
+func webSocketAccept(key string) string {
+	sum := sha1.Sum([]byte(key + wsGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// Whether any comma separated value of the header equals token, ignoring case
+func headerHasToken(header http.Header, name string, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hostPort(target *url.URL, defaultPort string) string {
+	if target.Port() != "" {
+		return target.Host
+	}
+	return net.JoinHostPort(target.Hostname(), defaultPort)
+}
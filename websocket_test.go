@@ -0,0 +1,103 @@
+//go:build !litecrate_core && !litecrate_nonet
+
+package litecrate_test
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+func TestWebSocketTransport(t *testing.T) {
+	upgraded := make(chan *lite.WebSocketTransport, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		transport, err := lite.UpgradeWebSocket(w, r)
+		if err == nil {
+			upgraded <- transport
+		}
+	}))
+	defer server.Close()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	client, err := lite.DialWebSocket(wsURL + "/socket?room=1")
+	if err != nil {
+		t.Fatalf("DialWebSocket() - FAIL: %v", err)
+	}
+	serverEnd := <-upgraded
+	checkTransportPair(t, "WebSocketTransport", client, serverEnd)
+
+	client.Close()
+	if _, err := serverEnd.RecvCrate(); err != io.EOF {
+		t.Errorf("WebSocketTransport.RecvCrate(closed) - FAIL: %v", err)
+	}
+	serverEnd.Close()
+
+	client, err = lite.DialWebSocket(wsURL)
+	if err != nil {
+		t.Fatalf("DialWebSocket() - FAIL: %v", err)
+	}
+	serverEnd = <-upgraded
+	serverEnd.MaxFrameSize = 10
+	go client.SendCrate(lite.OpenCrate(make([]byte, 11), lite.FlagStatic))
+	if _, err := serverEnd.RecvCrate(); err != lite.ErrFrameTooLarge {
+		t.Errorf("WebSocketTransport.RecvCrate(too large) - FAIL: %v", err)
+	}
+	client.Conn.Close()
+	serverEnd.Close()
+
+	if _, err := lite.DialWebSocket("http://" + strings.TrimPrefix(server.URL, "http://")); err == nil {
+		t.Errorf("DialWebSocket(http://) - FAIL: wrong scheme did not error")
+	}
+	response, err := http.Get(server.URL)
+	if err != nil || response.StatusCode != http.StatusBadRequest {
+		t.Errorf("UpgradeWebSocket(plain request) - FAIL: %v", err)
+	}
+}
+
+// Checks fragmented messages, pings and close frames written by hand as another implementation would
+func TestWebSocketFrames(t *testing.T) {
+	local, remote := net.Pipe()
+	transport := &lite.WebSocketTransport{Conn: local}
+	go func() {
+		remote.Write([]byte{0x02, 0x02, 'a', 'b'})            // binary, not final
+		remote.Write([]byte{0x89, 0x01, 'p'})                 // ping between fragments
+		remote.Write([]byte{0x80, 0x81, 1, 2, 3, 4, 'c' ^ 1}) // masked final continuation
+		remote.Write([]byte{0x88, 0x02, 0x03, 0xE8})          // close
+	}()
+	pong := make([]byte, 3)
+	ponged := make(chan bool)
+	go func() {
+		io.ReadFull(remote, pong)
+		close(ponged)
+	}()
+	received, err := transport.RecvCrate()
+	if err != nil || string(received.Data()) != "abc" {
+		t.Fatalf("WebSocketTransport.RecvCrate(fragmented) - FAIL: %v", err)
+	}
+	<-ponged
+	if !bytes.Equal(pong, []byte{0x8A, 0x01, 'p'}) {
+		t.Errorf("WebSocketTransport.RecvCrate(ping) - FAIL: replied %v", pong)
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := transport.RecvCrate()
+		done <- err
+	}()
+	reply := make([]byte, 4)
+	io.ReadFull(remote, reply)
+	if err := <-done; err != io.EOF || !bytes.Equal(reply, []byte{0x88, 0x02, 0x03, 0xE8}) {
+		t.Errorf("WebSocketTransport.RecvCrate(close) - FAIL: %v, replied %v", err, reply)
+	}
+	go remote.Write([]byte{0x00, 0x00})
+	if _, err := transport.RecvCrate(); err == nil {
+		t.Errorf("WebSocketTransport.RecvCrate(stray continuation) - FAIL: did not error")
+	}
+	local.Close()
+	remote.Close()
+}
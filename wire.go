@@ -0,0 +1,65 @@
+package litecrate
+
+// Version of the wire format written by this package: how uvarints, length-or-nil counters
+// and fixed width values are encoded. It is only increased when the encoding of a value
+// that could already be written changes, never for new methods or types, so two peers
+// with the same WireVersion always agree on what every byte means
+const WireVersion uint8 = 1
+
+// Panicked by RequireWireVersion() (and returned by Framer.ReadFrame()) when data was written
+// with a wire version the reader's WirePolicy does not accept
+type WireVersionError struct {
+	Version uint8 // Version found in the data
+	Policy  WirePolicy
+}
+
+func (e *WireVersionError) Error() string {
+	oldest, newest := e.Policy.bounds()
+	return "LiteCrate: wire version " + intStr(e.Version) + " is not accepted (accepting " + intStr(oldest) + " to " + intStr(newest) + ")"
+}
+
+/**************
+	WIRE VERSION
+***************/
+
+// Which wire versions a reader accepts. The zero value accepts only this package's WireVersion,
+// which is the safe choice for a fleet upgraded all at once. During a rolling upgrade, readers
+// can accept the versions on both sides of it (Min: old, Max: new) for as long as writers of both are running,
+// provided they decode each version as written (see RequireWireVersion())
+type WirePolicy struct {
+	Min uint8 // Oldest version accepted, 0 = WireVersion
+	Max uint8 // Newest version accepted, 0 = WireVersion
+}
+
+// Returns whether data written with version can be read under the policy
+func (p WirePolicy) Accepts(version uint8) bool {
+	oldest, newest := p.bounds()
+	return version >= oldest && version <= newest
+}
+
+func (p WirePolicy) bounds() (oldest uint8, newest uint8) {
+	oldest, newest = p.Min, p.Max
+	if oldest == 0 {
+		oldest = WireVersion
+	}
+	if newest == 0 {
+		newest = WireVersion
+	}
+	return oldest, newest
+}
+
+// Write WireVersion to crate as a 1 byte header, to be checked by the reader with RequireWireVersion()
+func (c *Crate) WriteWireVersion() {
+	c.WriteU8(WireVersion)
+}
+
+// Read the 1 byte header written by WriteWireVersion(), returning the version it holds.
+// Panics with a *WireVersionError (without advancing the read index) if policy does not accept it
+func (c *Crate) RequireWireVersion(policy WirePolicy) (version uint8) {
+	version = c.PeekU8()
+	if !policy.Accepts(version) {
+		panic(&WireVersionError{Version: version, Policy: policy})
+	}
+	c.read += 1
+	return version
+}
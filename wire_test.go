@@ -0,0 +1,64 @@
+package litecrate_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	lite "github.com/gabe-lee/litecrate"
+)
+
+func TestWireVersion(t *testing.T) {
+	var exact lite.WirePolicy
+	if !exact.Accepts(lite.WireVersion) || exact.Accepts(lite.WireVersion+1) || exact.Accepts(lite.WireVersion-1) {
+		t.Errorf("WirePolicy.Accepts() - FAIL: zero policy must accept only WireVersion")
+	}
+	rolling := lite.WirePolicy{Max: lite.WireVersion + 1}
+	if !rolling.Accepts(lite.WireVersion+1) || rolling.Accepts(lite.WireVersion+2) {
+		t.Errorf("WirePolicy.Accepts() - FAIL: Max not honored")
+	}
+
+	crate := lite.NewCrate(4, lite.FlagAutoDouble)
+	crate.WriteWireVersion()
+	crate.WriteU8(lite.WireVersion + 1)
+	if version := crate.RequireWireVersion(exact); version != lite.WireVersion || crate.ReadIndex() != 1 {
+		t.Errorf("RequireWireVersion() - FAIL: version %d, index %d", version, crate.ReadIndex())
+	}
+	err := catchPanic[*lite.WireVersionError](func() { crate.RequireWireVersion(exact) })
+	if err == nil || err.Version != lite.WireVersion+1 || err.Error() == "" || crate.ReadIndex() != 1 {
+		t.Errorf("RequireWireVersion() - FAIL: newer version did not panic with *WireVersionError: %v", err)
+	}
+	if version := crate.RequireWireVersion(rolling); version != lite.WireVersion+1 {
+		t.Errorf("RequireWireVersion() - FAIL: rolling policy read %d", version)
+	}
+}
+
+func TestFramerWireHeader(t *testing.T) {
+	framer := lite.Framer{WireHeader: true}
+	var stream bytes.Buffer
+	crate := lite.NewCrate(4, lite.FlagAutoDouble)
+	crate.WriteU16(7)
+	framer.WriteFrame(&stream, crate)
+	if !bytes.Equal(stream.Bytes(), []byte{lite.WireVersion, 2, 7, 0}) {
+		t.Errorf("Framer.WriteFrame() - FAIL: % x", stream.Bytes())
+	}
+	if got, err := framer.ReadFrame(bytes.NewReader(stream.Bytes())); err != nil || got.ReadU16() != 7 {
+		t.Errorf("Framer.ReadFrame() - FAIL: %v", err)
+	}
+	newer := []byte{lite.WireVersion + 1, 2, 7, 0}
+	var versionErr *lite.WireVersionError
+	if _, err := framer.ReadFrame(bytes.NewReader(newer)); !errors.As(err, &versionErr) || versionErr.Version != lite.WireVersion+1 {
+		t.Errorf("Framer.ReadFrame() - FAIL: newer version returned %v", err)
+	}
+	framer.WirePolicy.Max = lite.WireVersion + 1
+	if _, err := framer.ReadFrame(bytes.NewReader(newer)); err != nil {
+		t.Errorf("Framer.ReadFrame() - FAIL: policy accepting newer version returned %v", err)
+	}
+	if _, err := framer.ReadFrame(bytes.NewReader(nil)); err != io.EOF {
+		t.Errorf("Framer.ReadFrame() - FAIL: %v != io.EOF at end of stream", err)
+	}
+	if _, err := framer.ReadFrame(bytes.NewReader([]byte{lite.WireVersion})); err != io.ErrUnexpectedEOF {
+		t.Errorf("Framer.ReadFrame() - FAIL: %v != io.ErrUnexpectedEOF after wire header", err)
+	}
+}